@@ -1,7 +1,17 @@
 package main
 
-import "os"
+import (
+	"os"
+
+	"project/internal/cli"
+)
 
 func isCliMode() bool {
 	return len(os.Args) > 1
 }
+
+// isMigrationCommand returns whether the CLI command manages the
+// migrations itself, so they must not be run on startup
+func isMigrationCommand() bool {
+	return isCliMode() && (os.Args[1] == cli.CommandMigrate || os.Args[1] == cli.CommandMakeMigration)
+}
@@ -68,3 +68,25 @@ func TestIsCliMode_VariousArguments_JobCommand(t *testing.T) {
 		t.Errorf("isCliMode() = %v, want true", result)
 	}
 }
+
+func TestIsMigrationCommand(t *testing.T) {
+	os.Args = []string{"server", "migrate", "status"}
+	if !isMigrationCommand() {
+		t.Errorf("isMigrationCommand() should return true for migrate")
+	}
+
+	os.Args = []string{"server", "make:migration", "table_posts_create"}
+	if !isMigrationCommand() {
+		t.Errorf("isMigrationCommand() should return true for make:migration")
+	}
+
+	os.Args = []string{"server", "task", "myTask"}
+	if isMigrationCommand() {
+		t.Errorf("isMigrationCommand() should return false for other commands")
+	}
+
+	os.Args = []string{"server"}
+	if isMigrationCommand() {
+		t.Errorf("isMigrationCommand() should return false without a command")
+	}
+}
@@ -28,7 +28,7 @@ import (
 // Business Logic:
 // 1. Initialize the environment
 // 2. Defer Closing the database
-// 3. Initialize the models
+// 3. Run the migrations, when enabled
 // 4. Register the task handlers
 // 5. Executes the command if provided
 // 6. Initialize the task queue
@@ -68,10 +68,13 @@ func main() {
 		}
 	}()
 
-	// Run all migrations (store-level + custom SQL)
-	if err := migrations.MigrateAll(app); err != nil {
-		fmt.Printf("Failed to run migrations: %v\n", err)
-		return
+	// Run all migrations (store-level + custom SQL), unless disabled
+	// or managed by the CLI command
+	if cfg.GetDatabaseAutoMigrate() && !isMigrationCommand() {
+		if err := migrations.MigrateAll(app); err != nil {
+			fmt.Printf("Failed to run migrations: %v\n", err)
+			return
+		}
 	}
 
 	tasks.RegisterTasks(app) // Register the task handlers
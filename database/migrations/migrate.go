@@ -14,34 +14,144 @@ import (
 // Store migrations and custom SQL migrations are combined and tracked
 // in a single migration_tracker table.
 func MigrateAll(app app.AppInterface) error {
+	m, err := newMigrator(app)
+	if err != nil {
+		return err
+	}
+
+	return m.Up(context.Background())
+}
+
+// MigrateDown rolls back the given number of the last run migrations,
+// newest first.
+func MigrateDown(app app.AppInterface, steps int) error {
+	if steps < 1 {
+		return errors.New("steps must be at least 1")
+	}
+
+	m, err := newMigrator(app)
+	if err != nil {
+		return err
+	}
+
+	return m.Down(context.Background(), steps)
+}
+
+// MigrateFresh rolls back all the run migrations, then runs them all
+// again. All the data in the migrated tables is lost, so it is refused
+// in production.
+func MigrateFresh(app app.AppInterface) error {
 	if app == nil {
 		return errors.New("app is nil")
 	}
 
+	if app.GetConfig() == nil || app.GetConfig().IsEnvProduction() {
+		return errors.New("migrate fresh is not allowed in production")
+	}
+
+	statuses, err := MigrationStatuses(app)
+	if err != nil {
+		return err
+	}
+
+	ran := 0
+	for _, status := range statuses {
+		if status.Ran {
+			ran++
+		}
+	}
+
+	if ran > 0 {
+		if err := MigrateDown(app, ran); err != nil {
+			return fmt.Errorf("failed to roll back migrations: %w", err)
+		}
+	}
+
+	return MigrateAll(app)
+}
+
+// MigrationStatus is the status of a registered migration
+type MigrationStatus struct {
+	Signature   string
+	Description string
+	Ran         bool
+	Batch       int
+}
+
+// MigrationStatuses returns the status of all the registered migrations,
+// in the order they run.
+func MigrationStatuses(app app.AppInterface) ([]MigrationStatus, error) {
+	m, err := newMigrator(app)
+	if err != nil {
+		return nil, err
+	}
+
+	trackerStatuses, err := m.Status(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration status: %w", err)
+	}
+
+	batches := map[string]int{}
+	for _, trackerStatus := range trackerStatuses {
+		if trackerStatus.Ran {
+			batches[trackerStatus.Signature] = trackerStatus.Batch
+		}
+	}
+
+	registered, err := registeredMigrations(app)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(registered))
+	for _, migration := range registered {
+		batch, ran := batches[migration.Signature()]
+		statuses = append(statuses, MigrationStatus{
+			Signature:   migration.Signature(),
+			Description: migration.Description(),
+			Ran:         ran,
+			Batch:       batch,
+		})
+	}
+
+	return statuses, nil
+}
+
+// newMigrator returns a migrator with all the registered migrations
+func newMigrator(app app.AppInterface) (*migrator.Migrator, error) {
+	if app == nil {
+		return nil, errors.New("app is nil")
+	}
+
 	neatDB := app.GetNeatDatabase()
 	if neatDB == nil {
-		return errors.New("neat database is nil")
+		return nil, errors.New("neat database is nil")
 	}
 
 	m := migrator.NewMigrator(neatDB)
 	m.SetTransactionsEnabled(false)
 
-	cfg := app.GetConfig()
+	registered, err := registeredMigrations(app)
+	if err != nil {
+		return nil, err
+	}
 
-	storeMigrations := getStoreMigrations(cfg, app)
-	if err := m.AddMigrations(storeMigrations); err != nil {
-		return fmt.Errorf("failed to add store migrations: %w", err)
+	if err := m.AddMigrations(registered); err != nil {
+		return nil, fmt.Errorf("failed to add migrations: %w", err)
 	}
 
+	return m, nil
+}
+
+// registeredMigrations returns the store migrations followed by the
+// custom SQL migrations
+func registeredMigrations(app app.AppInterface) ([]migrator.MigrationInterface, error) {
 	sqlMigrations, err := getSQLMigrations()
 	if err != nil {
-		return err
-	}
-	if err := m.AddMigrations(sqlMigrations); err != nil {
-		return fmt.Errorf("failed to add sql migrations: %w", err)
+		return nil, err
 	}
 
-	return m.Up(context.Background())
+	return append(getStoreMigrations(app.GetConfig(), app), sqlMigrations...), nil
 }
 
 // getStoreMigrations returns store migrations conditionally based on config.
@@ -0,0 +1,45 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"project/database/migrations"
+	"project/internal/config"
+	"project/internal/testutils"
+)
+
+func TestMigrateAll_NilApp(t *testing.T) {
+	if err := migrations.MigrateAll(nil); err == nil || err.Error() != "app is nil" {
+		t.Errorf("Expected error 'app is nil', got %v", err)
+	}
+}
+
+func TestMigrateDown_InvalidSteps(t *testing.T) {
+	if err := migrations.MigrateDown(nil, 0); err == nil || err.Error() != "steps must be at least 1" {
+		t.Errorf("Expected error 'steps must be at least 1', got %v", err)
+	}
+}
+
+func TestMigrateFresh_NotAllowedInProduction(t *testing.T) {
+	app := testutils.Setup()
+	app.GetConfig().SetAppEnv(config.APP_ENVIRONMENT_PRODUCTION)
+
+	if err := migrations.MigrateFresh(app); err == nil || err.Error() != "migrate fresh is not allowed in production" {
+		t.Errorf("Expected error 'migrate fresh is not allowed in production', got %v", err)
+	}
+}
+
+func TestMigrationStatuses_AllRanAfterMigrateAll(t *testing.T) {
+	app := testutils.Setup()
+
+	statuses, err := migrations.MigrationStatuses(app)
+	if err != nil {
+		t.Fatalf("MigrationStatuses failed: %v", err)
+	}
+
+	for _, status := range statuses {
+		if !status.Ran {
+			t.Errorf("Expected migration %s to have run", status.Signature)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Package apitoken implements the personal API tokens, which the users
+// create from their account to call the API.
+//
+// Only the SHA-256 hash of a token is stored, the token itself is shown to
+// the user once, when created. Each token has the scopes the user granted
+// to it, and may expire.
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TOKEN_PREFIX prefixes the personal API tokens, so they are easy to
+// recognise (e.g. by secret scanners), and told apart from other tokens
+const TOKEN_PREFIX = "pat_"
+
+// The statuses of a token, see Token.Status
+const (
+	TOKEN_STATUS_ACTIVE  = "active"
+	TOKEN_STATUS_EXPIRED = "expired"
+	TOKEN_STATUS_REVOKED = "revoked"
+)
+
+// The scopes which can be granted to a token, as "resource:access"
+const (
+	SCOPE_PROFILE_READ = "profile:read"
+	SCOPE_BLOG_READ    = "blog:read"
+	SCOPE_BLOG_WRITE   = "blog:write"
+	SCOPE_CMS_READ     = "cms:read"
+	SCOPE_CMS_WRITE    = "cms:write"
+	SCOPE_SHOP_READ    = "shop:read"
+	SCOPE_ORDERS_READ  = "orders:read"
+	SCOPE_TASKS_READ   = "tasks:read"
+	SCOPE_TASKS_WRITE  = "tasks:write"
+	SCOPE_USERS_READ   = "users:read"
+)
+
+// Scope describes a scope to the user granting it
+type Scope struct {
+	Key         string
+	Description string
+	// AdminOnly scopes can only be granted by administrators
+	AdminOnly bool
+}
+
+// Scopes returns the scopes which can be granted to a token
+func Scopes() []Scope {
+	return []Scope{
+		{Key: SCOPE_PROFILE_READ, Description: "Read your profile"},
+		{Key: SCOPE_ORDERS_READ, Description: "Read your orders"},
+		{Key: SCOPE_SHOP_READ, Description: "Read the shop products"},
+		{Key: SCOPE_BLOG_READ, Description: "Read the blog posts"},
+		{Key: SCOPE_BLOG_WRITE, Description: "Create and change the blog posts", AdminOnly: true},
+		{Key: SCOPE_CMS_READ, Description: "Read the website pages", AdminOnly: true},
+		{Key: SCOPE_CMS_WRITE, Description: "Create and change the website pages", AdminOnly: true},
+		{Key: SCOPE_TASKS_READ, Description: "Read the task queue", AdminOnly: true},
+		{Key: SCOPE_TASKS_WRITE, Description: "Add tasks to the queue", AdminOnly: true},
+		{Key: SCOPE_USERS_READ, Description: "Read the users", AdminOnly: true},
+	}
+}
+
+// ScopeFind returns the scope with the key, or nil if there is no such
+// scope
+func ScopeFind(key string) *Scope {
+	for _, scope := range Scopes() {
+		if scope.Key == key {
+			return &scope
+		}
+	}
+
+	return nil
+}
+
+// Token is a personal API token of a user
+type Token struct {
+	ID     string `json:"-"`
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	// Hash is the SHA-256 hash of the token, see Hash
+	Hash string `json:"hash"`
+	// Hint is the end of the token, to tell the tokens apart
+	Hint   string   `json:"hint"`
+	Scopes []string `json:"scopes"`
+
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is zero for the tokens which do not expire
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	RevokedAt  time.Time `json:"revoked_at"`
+}
+
+// IsExpired returns whether the token expired at the time
+func (t Token) IsExpired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// IsRevoked returns whether the token was revoked
+func (t Token) IsRevoked() bool {
+	return !t.RevokedAt.IsZero()
+}
+
+// IsActive returns whether the token can be used at the time
+func (t Token) IsActive(now time.Time) bool {
+	return !t.IsRevoked() && !t.IsExpired(now)
+}
+
+// Status returns the status of the token at the time
+func (t Token) Status(now time.Time) string {
+	if t.IsRevoked() {
+		return TOKEN_STATUS_REVOKED
+	}
+
+	if t.IsExpired(now) {
+		return TOKEN_STATUS_EXPIRED
+	}
+
+	return TOKEN_STATUS_ACTIVE
+}
+
+// HasScope returns whether the scope was granted to the token
+func (t Token) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// Generate returns a new random token, and its hash to store
+func Generate() (token string, hash string, err error) {
+	random := make([]byte, 32)
+
+	if _, err := rand.Read(random); err != nil {
+		return "", "", err
+	}
+
+	token = TOKEN_PREFIX + base64.RawURLEncoding.EncodeToString(random)
+
+	return token, Hash(token), nil
+}
+
+// Hash returns the hash of the token, as stored. The tokens are random,
+// so a fast hash is enough
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsToken returns whether the value looks like a personal API token
+func IsToken(value string) bool {
+	return strings.HasPrefix(value, TOKEN_PREFIX) && len(value) > len(TOKEN_PREFIX)
+}
@@ -0,0 +1,108 @@
+package apitoken
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerate(t *testing.T) {
+	token, hash, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(token, TOKEN_PREFIX) || !IsToken(token) {
+		t.Fatalf("expected the token to start with %s, got %s", TOKEN_PREFIX, token)
+	}
+
+	if hash != Hash(token) || hash == token {
+		t.Fatal("expected the hash of the token")
+	}
+
+	other, _, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if other == token {
+		t.Fatal("expected the tokens to be random")
+	}
+}
+
+func TestIsToken(t *testing.T) {
+	cases := map[string]bool{
+		"pat_abc":         true,
+		"pat_":            false,
+		"":                false,
+		"some-session-id": false,
+	}
+
+	for value, expected := range cases {
+		if IsToken(value) != expected {
+			t.Errorf("IsToken(%q) = %v, want %v", value, !expected, expected)
+		}
+	}
+}
+
+func TestToken_IsActive(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name   string
+		token  Token
+		active bool
+	}{
+		{"no expiry", Token{}, true},
+		{"expires later", Token{ExpiresAt: now.Add(time.Hour)}, true},
+		{"expired", Token{ExpiresAt: now}, false},
+		{"revoked", Token{RevokedAt: now.Add(-time.Hour)}, false},
+	}
+
+	for _, c := range cases {
+		if c.token.IsActive(now) != c.active {
+			t.Errorf("%s: IsActive() = %v, want %v", c.name, !c.active, c.active)
+		}
+	}
+}
+
+func TestToken_Status(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		token  Token
+		status string
+	}{
+		{Token{}, TOKEN_STATUS_ACTIVE},
+		{Token{ExpiresAt: now}, TOKEN_STATUS_EXPIRED},
+		{Token{ExpiresAt: now, RevokedAt: now}, TOKEN_STATUS_REVOKED},
+	}
+
+	for _, c := range cases {
+		if status := c.token.Status(now); status != c.status {
+			t.Errorf("Status() = %q, want %q", status, c.status)
+		}
+	}
+}
+
+func TestToken_HasScope(t *testing.T) {
+	token := Token{Scopes: []string{SCOPE_PROFILE_READ}}
+
+	if !token.HasScope(SCOPE_PROFILE_READ) {
+		t.Error("expected the granted scope")
+	}
+
+	if token.HasScope(SCOPE_ORDERS_READ) {
+		t.Error("expected the scope not to be granted")
+	}
+}
+
+func TestScopeFind(t *testing.T) {
+	if scope := ScopeFind(SCOPE_USERS_READ); scope == nil || !scope.AdminOnly {
+		t.Fatalf("expected the admin only users scope, got %v", scope)
+	}
+
+	if ScopeFind("unknown:read") != nil {
+		t.Fatal("expected no unknown scope")
+	}
+}
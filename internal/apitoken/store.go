@@ -0,0 +1,168 @@
+package apitoken
+
+import (
+	"encoding/json"
+	"errors"
+	"project/internal/customrecords"
+	"sort"
+	"time"
+
+	"github.com/dracory/customstore"
+)
+
+// RECORD_TYPE_API_TOKEN is the record type of the tokens in the custom
+// store
+const RECORD_TYPE_API_TOKEN = "api_token"
+
+// StoreInterface persists the tokens
+type StoreInterface interface {
+	TokenCreate(token *Token) error
+	TokenFindByHash(hash string) (*Token, error)
+	TokenFindByID(id string) (*Token, error)
+	TokenList() ([]Token, error)
+	TokenListByUserID(userID string) ([]Token, error)
+	TokenUpdate(token *Token) error
+}
+
+type store struct {
+	customStore customstore.StoreInterface
+}
+
+var _ StoreInterface = (*store)(nil)
+
+// NewStore creates a token store on top of the custom store
+func NewStore(customStore customstore.StoreInterface) (StoreInterface, error) {
+	if customStore == nil {
+		return nil, errors.New("custom store is required")
+	}
+
+	return &store{customStore: customStore}, nil
+}
+
+// TokenCreate saves the token, and sets its ID
+func (s *store) TokenCreate(token *Token) error {
+	if token == nil || token.UserID == "" || token.Name == "" || token.Hash == "" {
+		return errors.New("user ID, name and hash are required")
+	}
+
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now().UTC()
+	}
+
+	payload, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	record := customstore.NewRecord(RECORD_TYPE_API_TOKEN, customstore.WithPayload(string(payload)))
+
+	if err := s.customStore.RecordCreate(record); err != nil {
+		return err
+	}
+
+	token.ID = record.ID()
+
+	return nil
+}
+
+// TokenFindByHash returns the token with the hash, or nil if not found
+func (s *store) TokenFindByHash(hash string) (*Token, error) {
+	if hash == "" {
+		return nil, errors.New("hash is required")
+	}
+
+	tokens, err := s.tokenList(customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_API_TOKEN), map[string]string{"hash": hash})
+
+	if err != nil || len(tokens) == 0 {
+		return nil, err
+	}
+
+	return &tokens[0], nil
+}
+
+// TokenFindByID returns the token with the ID, or nil if not found
+func (s *store) TokenFindByID(id string) (*Token, error) {
+	if id == "" {
+		return nil, errors.New("token ID is required")
+	}
+
+	tokens, err := s.tokenList(customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_API_TOKEN).
+		SetID(id), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	return &tokens[0], nil
+}
+
+// TokenList returns the tokens of all the users, the newest first
+func (s *store) TokenList() ([]Token, error) {
+	return s.tokenList(customstore.NewRecordQuery().SetType(RECORD_TYPE_API_TOKEN), nil)
+}
+
+// TokenListByUserID returns the tokens of the user, the newest first
+func (s *store) TokenListByUserID(userID string) ([]Token, error) {
+	if userID == "" {
+		return nil, errors.New("user ID is required")
+	}
+
+	return s.tokenList(customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_API_TOKEN), map[string]string{"user_id": userID})
+}
+
+// TokenUpdate saves the changes to the token
+func (s *store) TokenUpdate(token *Token) error {
+	if token == nil || token.ID == "" {
+		return errors.New("token ID is required")
+	}
+
+	record, err := s.customStore.RecordFindByID(token.ID)
+	if err != nil {
+		return err
+	}
+
+	if record == nil {
+		return errors.New("token not found")
+	}
+
+	payload, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	record.SetPayload(string(payload))
+
+	return s.customStore.RecordUpdate(record)
+}
+
+func (s *store) tokenList(query customstore.RecordQueryInterface, fields map[string]string) ([]Token, error) {
+	records, err := customrecords.FindByPayload(s.customStore, query, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := []Token{}
+
+	for _, record := range records {
+		token := Token{}
+		if err := json.Unmarshal([]byte(record.Payload()), &token); err != nil {
+			return nil, err
+		}
+
+		token.ID = record.ID()
+		tokens = append(tokens, token)
+	}
+
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+
+	return tokens, nil
+}
@@ -0,0 +1,124 @@
+package apitoken
+
+import (
+	"testing"
+	"time"
+
+	"project/internal/testutils"
+)
+
+func newTestStore(t *testing.T) StoreInterface {
+	t.Helper()
+
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	store, err := NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func TestNewStore_RequiresCustomStore(t *testing.T) {
+	if _, err := NewStore(nil); err == nil {
+		t.Error("NewStore(nil) expected error")
+	}
+}
+
+func TestStore_TokenCreateAndFind(t *testing.T) {
+	store := newTestStore(t)
+
+	token := &Token{UserID: "user_1", Name: "CI", Hash: Hash("pat_one"), Scopes: []string{SCOPE_PROFILE_READ}}
+	if err := store.TokenCreate(token); err != nil {
+		t.Fatal(err)
+	}
+
+	if token.ID == "" || token.CreatedAt.IsZero() {
+		t.Fatalf("TokenCreate() must set the ID and the created at time, got %+v", token)
+	}
+
+	found, err := store.TokenFindByHash(Hash("pat_one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found == nil || found.ID != token.ID || !found.HasScope(SCOPE_PROFILE_READ) {
+		t.Fatalf("expected the token, got %+v", found)
+	}
+
+	notFound, err := store.TokenFindByHash(Hash("pat_two"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if notFound != nil {
+		t.Fatalf("expected no token for another hash, got %+v", notFound)
+	}
+
+	byID, err := store.TokenFindByID(token.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if byID == nil || byID.Name != "CI" {
+		t.Fatalf("expected the token by ID, got %+v", byID)
+	}
+}
+
+func TestStore_TokenListAndUpdate(t *testing.T) {
+	store := newTestStore(t)
+
+	for i, token := range []*Token{
+		{UserID: "user_1", Name: "One", Hash: Hash("pat_one")},
+		{UserID: "user_1", Name: "Two", Hash: Hash("pat_two")},
+		{UserID: "user_10", Name: "Three", Hash: Hash("pat_three")},
+	} {
+		token.CreatedAt = time.Date(2026, 1, i+1, 0, 0, 0, 0, time.UTC)
+		if err := store.TokenCreate(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := store.TokenList()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != 3 || all[0].Name != "Three" {
+		t.Fatalf("expected the 3 tokens, the newest first, got %+v", all)
+	}
+
+	userTokens, err := store.TokenListByUserID("user_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(userTokens) != 2 || userTokens[0].Name != "Two" {
+		t.Fatalf("expected the 2 tokens of user_1, the newest first, got %+v", userTokens)
+	}
+
+	token := userTokens[0]
+	token.RevokedAt = time.Now().UTC()
+
+	if err := store.TokenUpdate(&token); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := store.TokenFindByID(token.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated == nil || !updated.IsRevoked() {
+		t.Fatalf("expected the token to be revoked, got %+v", updated)
+	}
+}
+
+func TestStore_TokenCreateRequiresFields(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.TokenCreate(&Token{UserID: "user_1", Name: "CI"}); err == nil {
+		t.Fatal("expected an error without the hash")
+	}
+}
@@ -11,6 +11,9 @@ import (
 
 	"project/internal/cache"
 	"project/internal/config"
+	"project/internal/payment"
+	"project/internal/pricing"
+	"project/internal/searchindex"
 
 	"github.com/dracory/auditstore"
 	"github.com/dracory/blindindexstore"
@@ -59,6 +62,15 @@ type appImplementation struct {
 	memoryCache *ttlcache.Cache[string, any]
 	fileCache   cachego.Cache
 
+	// Payment gateway
+	paymentGateway payment.PaymentGatewayInterface
+
+	// Pricing calculator
+	pricingCalculator pricing.CalculatorInterface
+
+	// Search index
+	searchIndex searchindex.IndexInterface
+
 	// Database stores
 	auditStore          auditstore.StoreInterface
 	blogStore           blogstore.StoreInterface
@@ -158,6 +170,20 @@ func New(cfg config.ConfigInterface) (AppInterface, error) {
 		return nil, err
 	}
 
+	paymentGateway, err := paymentGatewayNew(cfg)
+	if err != nil {
+		return nil, err
+	}
+	app.SetPaymentGateway(paymentGateway)
+
+	pricingCalculator, err := pricingCalculatorNew(cfg)
+	if err != nil {
+		return nil, err
+	}
+	app.SetPricingCalculator(pricingCalculator)
+
+	app.SetSearchIndex(searchIndexNew(cfg, db, consoleLogger))
+
 	if app.GetLogStore() != nil {
 		app.SetLogger(slog.New(logstore.NewSlogHandler(app.GetLogStore())))
 	}
@@ -431,6 +457,30 @@ func (r *appImplementation) SetSqlFileStorage(s filesystem.StorageInterface) {
 	r.sqlFileStorage = s
 }
 
+// PaymentGateway
+func (r *appImplementation) GetPaymentGateway() payment.PaymentGatewayInterface {
+	return r.paymentGateway
+}
+func (r *appImplementation) SetPaymentGateway(g payment.PaymentGatewayInterface) {
+	r.paymentGateway = g
+}
+
+// PricingCalculator
+func (r *appImplementation) GetPricingCalculator() pricing.CalculatorInterface {
+	return r.pricingCalculator
+}
+func (r *appImplementation) SetPricingCalculator(c pricing.CalculatorInterface) {
+	r.pricingCalculator = c
+}
+
+// SearchIndex
+func (r *appImplementation) GetSearchIndex() searchindex.IndexInterface {
+	return r.searchIndex
+}
+func (r *appImplementation) SetSearchIndex(index searchindex.IndexInterface) {
+	r.searchIndex = index
+}
+
 // StatsStore
 func (r *appImplementation) GetStatsStore() statsstore.StoreInterface {
 	return r.statsStore
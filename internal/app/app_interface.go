@@ -5,6 +5,9 @@ import (
 	"log/slog"
 
 	"project/internal/config"
+	"project/internal/payment"
+	"project/internal/pricing"
+	"project/internal/searchindex"
 
 	"github.com/dracory/auditstore"
 	"github.com/dracory/blindindexstore"
@@ -64,6 +67,19 @@ type AppInterface interface {
 
 	GetDatabaseConnection(name string) *sql.DB
 
+	// Payment gateway (nil when no gateway is configured)
+	GetPaymentGateway() payment.PaymentGatewayInterface
+	SetPaymentGateway(g payment.PaymentGatewayInterface)
+
+	// Pricing calculator, adding the shipping and tax to the shop baskets.
+	// Replace it, or wrap it in a pricing.Pipeline, to plug in custom rules
+	GetPricingCalculator() pricing.CalculatorInterface
+	SetPricingCalculator(c pricing.CalculatorInterface)
+
+	// Search index of the pages, posts and products, see searchindex.Reindex
+	GetSearchIndex() searchindex.IndexInterface
+	SetSearchIndex(index searchindex.IndexInterface)
+
 	// ========================================================================
 	// == Stores (all specific data stores)
 	// ========================================================================
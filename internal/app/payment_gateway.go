@@ -0,0 +1,23 @@
+package app
+
+import (
+	"fmt"
+
+	"project/internal/config"
+	"project/internal/payment"
+)
+
+// paymentGatewayNew returns the payment gateway selected in the
+// configuration, or nil when no gateway is configured
+func paymentGatewayNew(cfg config.ConfigInterface) (payment.PaymentGatewayInterface, error) {
+	switch cfg.GetPaymentGateway() {
+	case "":
+		return nil, nil
+	case config.PAYMENT_GATEWAY_STRIPE:
+		return payment.NewStripeGateway(cfg.GetStripeKeyPrivate(), cfg.GetStripeWebhookSecret()), nil
+	case config.PAYMENT_GATEWAY_FAKE:
+		return payment.NewFakeGateway(payment.FAKE_WEBHOOK_SECRET), nil
+	}
+
+	return nil, fmt.Errorf("unknown payment gateway %q", cfg.GetPaymentGateway())
+}
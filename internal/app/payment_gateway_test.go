@@ -0,0 +1,57 @@
+package app_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"project/internal/app"
+	"project/internal/config"
+)
+
+func newPaymentTestConfig(gateway string) config.ConfigInterface {
+	cfg := config.New()
+	cfg.SetAppEnv("testing")
+	cfg.SetDatabaseDriver("sqlite")
+	cfg.SetDatabaseName(fmt.Sprintf("file:mp_test_%d?mode=memory&cache=shared", time.Now().UnixNano()))
+	cfg.SetPaymentGateway(gateway)
+	cfg.SetStripeKeyPrivate("sk_test_123")
+	return cfg
+}
+
+func TestPaymentGateway_NotConfigured(t *testing.T) {
+	application, err := app.New(newPaymentTestConfig(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = application.Close() }()
+
+	if application.GetPaymentGateway() != nil {
+		t.Error("expected no payment gateway")
+	}
+}
+
+func TestPaymentGateway_Selected(t *testing.T) {
+	for _, gateway := range []string{config.PAYMENT_GATEWAY_STRIPE, config.PAYMENT_GATEWAY_FAKE} {
+		application, err := app.New(newPaymentTestConfig(gateway))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if application.GetPaymentGateway() == nil {
+			t.Fatalf("expected the %s payment gateway", gateway)
+		}
+
+		if application.GetPaymentGateway().Name() != gateway {
+			t.Errorf("expected the %s payment gateway, got %s", gateway, application.GetPaymentGateway().Name())
+		}
+
+		_ = application.Close()
+	}
+}
+
+func TestPaymentGateway_Unknown(t *testing.T) {
+	if _, err := app.New(newPaymentTestConfig("unknown")); err == nil {
+		t.Fatal("expected an error for an unknown payment gateway")
+	}
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"fmt"
+
+	"project/internal/config"
+	"project/internal/pricing"
+)
+
+// pricingCalculatorNew returns the pricing pipeline configured in the
+// environment: shipping first, then the tax on the goods and the shipping
+func pricingCalculatorNew(cfg config.ConfigInterface) (pricing.CalculatorInterface, error) {
+	shippingMethods, err := pricing.ParseShippingMethods(cfg.GetShopShippingMethods(), cfg.GetPaymentCurrency())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.KEY_SHOP_SHIPPING_METHODS, err)
+	}
+
+	taxRates, err := pricing.ParseTaxRates(cfg.GetShopTaxRates())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.KEY_SHOP_TAX_RATES, err)
+	}
+
+	return pricing.NewPipeline(
+		pricing.NewShippingCalculator(shippingMethods),
+		pricing.NewTaxCalculator(taxRates, cfg.GetShopPricesIncludeTax()),
+	), nil
+}
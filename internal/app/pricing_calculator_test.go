@@ -0,0 +1,62 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/pricing"
+)
+
+func TestPricingCalculator_FromConfig(t *testing.T) {
+	cfg := newPaymentTestConfig("")
+	cfg.SetShopShippingMethods(`[{"code":"standard","name":"Standard","tiers":[{"price":"5.00"}]}]`)
+	cfg.SetShopTaxRates(`[{"country":"GB","rate":"20","label":"VAT"}]`)
+
+	application, err := app.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = application.Close() }()
+
+	quote := pricing.NewQuote("GBP", pricing.Address{Country: "GB"}, []pricing.Item{{Quantity: 1, UnitAmount: 1000}})
+
+	if err := application.GetPricingCalculator().Calculate(context.Background(), quote); err != nil {
+		t.Fatal(err)
+	}
+
+	if quote.Amount(pricing.LINE_TYPE_SHIPPING) != 500 || quote.Amount(pricing.LINE_TYPE_TAX) != 300 {
+		t.Errorf("unexpected lines %+v", quote.Lines)
+	}
+}
+
+func TestPricingCalculator_NotConfigured(t *testing.T) {
+	application, err := app.New(newPaymentTestConfig(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = application.Close() }()
+
+	if application.GetPricingCalculator() == nil {
+		t.Fatal("expected a pricing calculator")
+	}
+
+	quote := pricing.NewQuote("GBP", pricing.Address{Country: "GB"}, []pricing.Item{{Quantity: 1, UnitAmount: 1000}})
+
+	if err := application.GetPricingCalculator().Calculate(context.Background(), quote); err != nil {
+		t.Fatal(err)
+	}
+
+	if quote.Total() != 1000 {
+		t.Errorf("Total() = %d, want 1000", quote.Total())
+	}
+}
+
+func TestPricingCalculator_InvalidRules(t *testing.T) {
+	cfg := newPaymentTestConfig("")
+	cfg.SetShopTaxRates(`[{"country":"GB","rate":"twenty"}]`)
+
+	if _, err := app.New(cfg); err == nil {
+		t.Fatal("expected an error for invalid tax rates")
+	}
+}
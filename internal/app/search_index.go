@@ -0,0 +1,39 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"slices"
+	"sort"
+
+	"project/internal/config"
+	"project/internal/searchindex"
+)
+
+// searchIndexNew returns the full text search index of the database,
+// falling back to the in memory index when the database has none
+// (e.g. postgres, or SQLite built without FTS5)
+func searchIndexNew(cfg config.ConfigInterface, db *sql.DB, logger *slog.Logger) searchindex.IndexInterface {
+	language := cfg.GetTranslationLanguageDefault()
+
+	languages := []string{}
+	for code := range cfg.GetTranslationLanguageList() {
+		languages = append(languages, code)
+	}
+	sort.Strings(languages)
+
+	for _, code := range languages {
+		if !slices.Contains(searchindex.StemmedLanguages(), code) {
+			logger.Warn("At app > searchIndexNew", slog.String("reason", "no stemmer for the language, its words are indexed as they are"), slog.String("language", code))
+		}
+	}
+
+	index, err := searchindex.NewDatabaseIndex(context.Background(), db, cfg.GetDatabaseDriver(), language)
+	if err != nil {
+		logger.Warn("At app > searchIndexNew", slog.String("reason", "full text search not available, using the in memory index"), slog.String("error", err.Error()))
+		return searchindex.NewMemoryIndex(language)
+	}
+
+	return index
+}
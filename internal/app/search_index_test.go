@@ -0,0 +1,42 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/searchindex"
+)
+
+func TestSearchIndex_FromConfig(t *testing.T) {
+	application, err := app.New(newPaymentTestConfig(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = application.Close() }()
+
+	index := application.GetSearchIndex()
+	if index == nil {
+		t.Fatal("expected a search index")
+	}
+
+	ctx := context.Background()
+	err = index.Upsert(ctx, searchindex.Document{
+		ID:    searchindex.DocumentID(searchindex.DOCUMENT_TYPE_PAGE, "1"),
+		Type:  searchindex.DOCUMENT_TYPE_PAGE,
+		Title: "Contact",
+		Body:  "Write to our support team",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, total, err := index.Search(ctx, searchindex.Query{Text: "support"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 1 || len(results) != 1 || results[0].Title != "Contact" {
+		t.Errorf("expected the page, got %d results", total)
+	}
+}
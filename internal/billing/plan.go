@@ -0,0 +1,167 @@
+// Package billing defines the subscription plans, with the features and
+// limits each of them grants, and manages the subscriptions of the users.
+//
+// The plans are defined in code, so the features can be checked without
+// a database lookup, and are copied to the subscription store (see
+// PlanEnsure) when a user subscribes to them.
+package billing
+
+import (
+	"project/internal/payment"
+	"slices"
+)
+
+// IDs of the plans
+const (
+	PLAN_FREE     = "free"
+	PLAN_PRO      = "pro"
+	PLAN_BUSINESS = "business"
+)
+
+// Features granted by the plans, see HasFeature
+const (
+	FEATURE_DATA_EXPORT      = "data_export"
+	FEATURE_API_ACCESS       = "api_access"
+	FEATURE_PRIORITY_SUPPORT = "priority_support"
+)
+
+// Limits set by the plans, see Plan.Limit
+const (
+	LIMIT_PROJECTS     = "projects"
+	LIMIT_TEAM_MEMBERS = "team_members"
+)
+
+// UNLIMITED is the value of a limit without a maximum
+const UNLIMITED = -1
+
+// Plan is a subscription plan (tier)
+type Plan struct {
+	ID          string
+	Title       string
+	Description string
+	// Amount the price per interval, in minor units of the payment currency
+	Amount int64
+	// Interval one of the payment.INTERVAL_* constants
+	Interval string
+	// TrialDays the free days before the first payment, for first time subscribers
+	TrialDays int
+	// Rank orders the plans from the cheapest, to tell upgrades from downgrades
+	Rank     int
+	Features []string
+	Limits   map[string]int
+}
+
+// IsFree returns whether the plan costs nothing, so needs no payment
+func (plan Plan) IsFree() bool {
+	return plan.Amount <= 0
+}
+
+// HasFeature returns whether the plan grants the feature
+func (plan Plan) HasFeature(feature string) bool {
+	return slices.Contains(plan.Features, feature)
+}
+
+// Limit returns the value of the limit, UNLIMITED for no maximum,
+// and zero if the plan does not set the limit
+func (plan Plan) Limit(name string) int {
+	return plan.Limits[name]
+}
+
+// Plans returns the plans, from the cheapest to the most expensive
+func Plans() []Plan {
+	return []Plan{
+		{
+			ID:          PLAN_FREE,
+			Title:       "Free",
+			Description: "For trying things out",
+			Interval:    payment.INTERVAL_MONTH,
+			Rank:        0,
+			Features:    []string{},
+			Limits: map[string]int{
+				LIMIT_PROJECTS:     1,
+				LIMIT_TEAM_MEMBERS: 1,
+			},
+		},
+		{
+			ID:          PLAN_PRO,
+			Title:       "Pro",
+			Description: "For professionals",
+			Amount:      900,
+			Interval:    payment.INTERVAL_MONTH,
+			TrialDays:   14,
+			Rank:        1,
+			Features:    []string{FEATURE_DATA_EXPORT, FEATURE_API_ACCESS},
+			Limits: map[string]int{
+				LIMIT_PROJECTS:     10,
+				LIMIT_TEAM_MEMBERS: 3,
+			},
+		},
+		{
+			ID:          PLAN_BUSINESS,
+			Title:       "Business",
+			Description: "For growing teams",
+			Amount:      2900,
+			Interval:    payment.INTERVAL_MONTH,
+			TrialDays:   14,
+			Rank:        2,
+			Features:    []string{FEATURE_DATA_EXPORT, FEATURE_API_ACCESS, FEATURE_PRIORITY_SUPPORT},
+			Limits: map[string]int{
+				LIMIT_PROJECTS:     UNLIMITED,
+				LIMIT_TEAM_MEMBERS: 25,
+			},
+		},
+	}
+}
+
+// PlanFind returns the plan with the given ID
+func PlanFind(planID string) (Plan, bool) {
+	for _, plan := range Plans() {
+		if plan.ID == planID {
+			return plan, true
+		}
+	}
+
+	return Plan{}, false
+}
+
+// FreePlan returns the plan of the users without an active subscription
+func FreePlan() Plan {
+	plan, _ := PlanFind(PLAN_FREE)
+	return plan
+}
+
+// FeatureName returns the feature in words, e.g. "API access"
+func FeatureName(feature string) string {
+	switch feature {
+	case FEATURE_DATA_EXPORT:
+		return "Data export"
+	case FEATURE_API_ACCESS:
+		return "API access"
+	case FEATURE_PRIORITY_SUPPORT:
+		return "Priority support"
+	}
+
+	return feature
+}
+
+// LimitName returns the limit in words, e.g. "Team members"
+func LimitName(name string) string {
+	switch name {
+	case LIMIT_PROJECTS:
+		return "Projects"
+	case LIMIT_TEAM_MEMBERS:
+		return "Team members"
+	}
+
+	return name
+}
+
+// AllFeatures returns the features granted by any of the plans
+func AllFeatures() []string {
+	return []string{FEATURE_DATA_EXPORT, FEATURE_API_ACCESS, FEATURE_PRIORITY_SUPPORT}
+}
+
+// AllLimits returns the limits set by the plans
+func AllLimits() []string {
+	return []string{LIMIT_PROJECTS, LIMIT_TEAM_MEMBERS}
+}
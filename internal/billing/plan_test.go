@@ -0,0 +1,89 @@
+package billing
+
+import "testing"
+
+func TestPlans_OrderedByRank(t *testing.T) {
+	plans := Plans()
+
+	if len(plans) == 0 {
+		t.Fatal("expected at least one plan")
+	}
+
+	for i := 1; i < len(plans); i++ {
+		if plans[i].Rank <= plans[i-1].Rank {
+			t.Errorf("plan %q must rank above plan %q", plans[i].ID, plans[i-1].ID)
+		}
+	}
+}
+
+func TestPlanFind(t *testing.T) {
+	plan, found := PlanFind(PLAN_PRO)
+	if !found || plan.ID != PLAN_PRO {
+		t.Fatalf("expected plan %q, got %q", PLAN_PRO, plan.ID)
+	}
+
+	if _, found := PlanFind("unknown"); found {
+		t.Error("expected an unknown plan not to be found")
+	}
+}
+
+func TestFreePlan(t *testing.T) {
+	plan := FreePlan()
+
+	if plan.ID != PLAN_FREE || !plan.IsFree() {
+		t.Fatalf("expected the free plan, got %+v", plan)
+	}
+
+	if plan.TrialDays != 0 {
+		t.Error("the free plan needs no trial")
+	}
+}
+
+func TestPlan_HasFeature(t *testing.T) {
+	pro, _ := PlanFind(PLAN_PRO)
+	business, _ := PlanFind(PLAN_BUSINESS)
+
+	if FreePlan().HasFeature(FEATURE_API_ACCESS) {
+		t.Error("the free plan must not grant API access")
+	}
+
+	if !pro.HasFeature(FEATURE_API_ACCESS) {
+		t.Error("the pro plan must grant API access")
+	}
+
+	if pro.HasFeature(FEATURE_PRIORITY_SUPPORT) || !business.HasFeature(FEATURE_PRIORITY_SUPPORT) {
+		t.Error("only the business plan must grant priority support")
+	}
+}
+
+func TestPlan_Limit(t *testing.T) {
+	business, _ := PlanFind(PLAN_BUSINESS)
+
+	if FreePlan().Limit(LIMIT_PROJECTS) != 1 {
+		t.Errorf("expected 1 project on the free plan, got %d", FreePlan().Limit(LIMIT_PROJECTS))
+	}
+
+	if business.Limit(LIMIT_PROJECTS) != UNLIMITED {
+		t.Error("expected unlimited projects on the business plan")
+	}
+
+	if business.Limit("unknown") != 0 {
+		t.Error("expected zero for a limit the plan does not set")
+	}
+}
+
+func TestPlans_UseKnownFeaturesAndLimits(t *testing.T) {
+	for _, plan := range Plans() {
+		for _, feature := range plan.Features {
+			if FeatureName(feature) == feature {
+				t.Errorf("plan %q: feature %q has no name", plan.ID, feature)
+			}
+		}
+
+		for name := range plan.Limits {
+			if LimitName(name) == name {
+				t.Errorf("plan %q: limit %q has no name", plan.ID, name)
+			}
+		}
+	}
+}
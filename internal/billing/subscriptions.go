@@ -0,0 +1,205 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project/internal/payment"
+
+	"github.com/dracory/subscriptionstore"
+)
+
+// SUBSCRIPTION_STATUS_PENDING is the status of a subscription awaiting the
+// payment of its checkout session. It is set active by SubscriptionActivate
+const SUBSCRIPTION_STATUS_PENDING = "pending"
+
+// SubscriptionActiveFind returns the active subscription of the subscriber,
+// the most recent one if there are more, or nil if there is none
+func SubscriptionActiveFind(ctx context.Context, store subscriptionstore.StoreInterface, subscriberID string) (subscriptionstore.SubscriptionInterface, error) {
+	subscriptions, err := store.SubscriptionList(ctx, subscriptionstore.NewSubscriptionQuery().
+		SetSubscriberID(subscriberID).
+		SetStatus(subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var latest subscriptionstore.SubscriptionInterface
+	for _, subscription := range subscriptions {
+		if latest == nil || subscription.GetCreatedAt() > latest.GetCreatedAt() {
+			latest = subscription
+		}
+	}
+
+	return latest, nil
+}
+
+// SubscriberPlan returns the plan of the active subscription of the
+// subscriber, and the subscription. Subscribers without an active
+// subscription are on the free plan, with a nil subscription.
+//
+// Subscriptions to plans which are not defined in Plans (e.g. created
+// by hand) grant no features.
+func SubscriberPlan(ctx context.Context, store subscriptionstore.StoreInterface, subscriberID string) (Plan, subscriptionstore.SubscriptionInterface, error) {
+	subscription, err := SubscriptionActiveFind(ctx, store, subscriberID)
+
+	if err != nil {
+		return Plan{}, nil, err
+	}
+
+	if subscription == nil {
+		return FreePlan(), nil, nil
+	}
+
+	plan, found := PlanFind(subscription.GetPlanID())
+
+	if !found {
+		return Plan{ID: subscription.GetPlanID(), Title: subscription.GetPlanID()}, subscription, nil
+	}
+
+	return plan, subscription, nil
+}
+
+// HasFeature returns whether the plan of the subscriber grants the feature
+func HasFeature(ctx context.Context, store subscriptionstore.StoreInterface, subscriberID string, feature string) (bool, error) {
+	plan, _, err := SubscriberPlan(ctx, store, subscriberID)
+
+	if err != nil {
+		return false, err
+	}
+
+	return plan.HasFeature(feature), nil
+}
+
+// TrialEligible returns whether the subscriber can start a trial, which
+// is only offered to subscribers who never had a subscription before
+func TrialEligible(ctx context.Context, store subscriptionstore.StoreInterface, subscriberID string) (bool, error) {
+	subscriptions, err := store.SubscriptionList(ctx, subscriptionstore.NewSubscriptionQuery().
+		SetSubscriberID(subscriberID))
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, subscription := range subscriptions {
+		// Abandoned checkouts do not count
+		if subscription.GetStatus() != SUBSCRIPTION_STATUS_PENDING {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// PlanEnsure copies the plan to the subscription store, if not there yet
+func PlanEnsure(ctx context.Context, store subscriptionstore.StoreInterface, plan Plan, currency string) error {
+	existing, err := store.PlanFindByID(ctx, plan.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to find plan: %w", err)
+	}
+
+	if existing != nil {
+		return nil
+	}
+
+	interval := subscriptionstore.PLAN_INTERVAL_MONTHLY
+	if plan.Interval == payment.INTERVAL_YEAR {
+		interval = subscriptionstore.PLAN_INTERVAL_YEARLY
+	}
+
+	storePlan := subscriptionstore.NewPlan().
+		SetID(plan.ID).
+		SetTitle(plan.Title).
+		SetPrice(payment.FormatMinorUnits(plan.Amount, currency)).
+		SetInterval(interval).
+		SetCurrency(currency).
+		SetStatus(subscriptionstore.PLAN_STATUS_ACTIVE)
+
+	if err := store.PlanCreate(ctx, storePlan); err != nil {
+		return fmt.Errorf("failed to create plan: %w", err)
+	}
+
+	return nil
+}
+
+// SubscriptionPendingCreate creates the pending subscription of the
+// subscriber to the plan, to be paid for with a checkout session
+func SubscriptionPendingCreate(ctx context.Context, store subscriptionstore.StoreInterface, subscriberID string, plan Plan) (subscriptionstore.SubscriptionInterface, error) {
+	subscription := subscriptionstore.NewSubscription().
+		SetStatus(SUBSCRIPTION_STATUS_PENDING).
+		SetSubscriberID(subscriberID).
+		SetPlanID(plan.ID)
+
+	if err := store.SubscriptionCreate(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// SubscriptionActivate sets the subscription active, and cancels the other
+// active subscriptions of the subscriber, as changing plans (upgrade or
+// downgrade) creates a new subscription replacing the current one.
+//
+// It is safe to call again for an active subscription (e.g. on every
+// renewal), and after a failure, as the cancelled subscriptions are only
+// marked cancelled once the payment provider has cancelled them.
+func SubscriptionActivate(ctx context.Context, store subscriptionstore.StoreInterface, gateway payment.PaymentGatewayInterface, subscription subscriptionstore.SubscriptionInterface) error {
+	if subscription.GetStatus() != subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE {
+		subscription.SetStatus(subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE)
+
+		if err := store.SubscriptionUpdate(ctx, subscription); err != nil {
+			return fmt.Errorf("failed to activate subscription: %w", err)
+		}
+	}
+
+	activeSubscriptions, err := store.SubscriptionList(ctx, subscriptionstore.NewSubscriptionQuery().
+		SetSubscriberID(subscription.GetSubscriberID()).
+		SetStatus(subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE))
+
+	if err != nil {
+		return fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	for _, replaced := range activeSubscriptions {
+		if replaced.GetID() == subscription.GetID() {
+			continue
+		}
+
+		if err := SubscriptionCancel(ctx, store, gateway, replaced); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SubscriptionCancel cancels the subscription immediately, at the payment
+// provider first for the paid plans, so the subscriber is not charged again
+func SubscriptionCancel(ctx context.Context, store subscriptionstore.StoreInterface, gateway payment.PaymentGatewayInterface, subscription subscriptionstore.SubscriptionInterface) error {
+	if subscription.GetStatus() == subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED {
+		return nil
+	}
+
+	plan, found := PlanFind(subscription.GetPlanID())
+	isPaid := found && !plan.IsFree() && subscription.GetStatus() == subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE
+
+	if isPaid {
+		if gateway == nil {
+			return errors.New("payment gateway not configured")
+		}
+
+		if err := gateway.SubscriptionCancel(ctx, subscription.GetID()); err != nil {
+			return fmt.Errorf("failed to cancel subscription %s at the payment provider: %w", subscription.GetID(), err)
+		}
+	}
+
+	subscription.SetStatus(subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED)
+
+	if err := store.SubscriptionUpdate(ctx, subscription); err != nil {
+		return fmt.Errorf("failed to cancel subscription: %w", err)
+	}
+
+	return nil
+}
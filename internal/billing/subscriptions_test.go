@@ -0,0 +1,227 @@
+package billing
+
+import (
+	"context"
+	"testing"
+
+	"project/internal/payment"
+	"project/internal/testutils"
+
+	"github.com/dracory/subscriptionstore"
+	"github.com/dracory/test"
+)
+
+func newTestStore(t *testing.T) subscriptionstore.StoreInterface {
+	t.Helper()
+
+	app := testutils.Setup(testutils.WithSubscriptionStore(true))
+
+	if app.GetSubscriptionStore() == nil {
+		t.Fatal("subscription store should not be nil")
+	}
+
+	return app.GetSubscriptionStore()
+}
+
+// paidCheckout creates a paid subscription checkout session at the fake
+// gateway, so the subscription can be cancelled there
+func paidCheckout(t *testing.T, gateway *payment.FakeGateway, subscriptionID string) {
+	t.Helper()
+
+	_, err := gateway.CheckoutCreate(context.Background(), payment.CheckoutOptions{
+		Currency:       "GBP",
+		LineItems:      []payment.LineItem{{Name: "Plan", Quantity: 1, UnitAmount: 900}},
+		SubscriptionID: subscriptionID,
+		Interval:       payment.INTERVAL_MONTH,
+		SuccessURL:     "https://example.com/success",
+		CancelURL:      "https://example.com/cancel",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubscriberPlan_FreeWithoutSubscription(t *testing.T) {
+	store := newTestStore(t)
+
+	plan, subscription, err := SubscriberPlan(context.Background(), store, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.ID != PLAN_FREE || subscription != nil {
+		t.Fatalf("expected the free plan without subscription, got %q", plan.ID)
+	}
+}
+
+func TestHasFeature(t *testing.T) {
+	store := newTestStore(t)
+
+	if _, err := testutils.SeedSubscription(store, test.USER_01, PLAN_PRO); err != nil {
+		t.Fatal(err)
+	}
+
+	hasAPIAccess, err := HasFeature(context.Background(), store, test.USER_01, FEATURE_API_ACCESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !hasAPIAccess {
+		t.Error("expected the pro plan to grant API access")
+	}
+
+	hasSupport, err := HasFeature(context.Background(), store, test.USER_01, FEATURE_PRIORITY_SUPPORT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hasSupport {
+		t.Error("expected the pro plan not to grant priority support")
+	}
+}
+
+func TestHasFeature_UnknownPlanGrantsNothing(t *testing.T) {
+	store := newTestStore(t)
+
+	if _, err := testutils.SeedSubscription(store, test.USER_01, testutils.PLAN_01); err != nil {
+		t.Fatal(err)
+	}
+
+	hasFeature, err := HasFeature(context.Background(), store, test.USER_01, FEATURE_DATA_EXPORT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hasFeature {
+		t.Error("expected a plan outside the catalogue to grant no features")
+	}
+}
+
+func TestTrialEligible(t *testing.T) {
+	store := newTestStore(t)
+	pro, _ := PlanFind(PLAN_PRO)
+
+	if _, err := SubscriptionPendingCreate(context.Background(), store, test.USER_01, pro); err != nil {
+		t.Fatal(err)
+	}
+
+	eligible, err := TrialEligible(context.Background(), store, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !eligible {
+		t.Error("expected an abandoned checkout not to use the trial")
+	}
+
+	if _, err := testutils.SeedSubscription(store, test.USER_01, PLAN_PRO); err != nil {
+		t.Fatal(err)
+	}
+
+	eligible, err = TrialEligible(context.Background(), store, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if eligible {
+		t.Error("expected a former subscriber not to be eligible for a trial")
+	}
+}
+
+func TestPlanEnsure(t *testing.T) {
+	store := newTestStore(t)
+	pro, _ := PlanFind(PLAN_PRO)
+
+	for range 2 {
+		if err := PlanEnsure(context.Background(), store, pro, "GBP"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	storePlan, err := store.PlanFindByID(context.Background(), PLAN_PRO)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if storePlan == nil {
+		t.Fatal("expected the plan to be created")
+	}
+
+	if storePlan.GetPrice() != "9.00" {
+		t.Errorf("expected price 9.00, got %q", storePlan.GetPrice())
+	}
+}
+
+func TestSubscriptionActivate_ReplacesCurrentSubscription(t *testing.T) {
+	store := newTestStore(t)
+	gateway := payment.NewFakeGateway("secret")
+	ctx := context.Background()
+
+	current, err := testutils.SeedSubscription(store, test.USER_01, PLAN_PRO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paidCheckout(t, gateway, current.GetID())
+
+	business, _ := PlanFind(PLAN_BUSINESS)
+	upgrade, err := SubscriptionPendingCreate(ctx, store, test.USER_01, business)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SubscriptionActivate(ctx, store, gateway, upgrade); err != nil {
+		t.Fatal(err)
+	}
+
+	// Renewals activate the subscription again
+	if err := SubscriptionActivate(ctx, store, gateway, upgrade); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, subscription, err := SubscriberPlan(ctx, store, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.ID != PLAN_BUSINESS || subscription.GetID() != upgrade.GetID() {
+		t.Fatalf("expected the business subscription to be active, got plan %q", plan.ID)
+	}
+
+	replaced, err := store.SubscriptionFindByID(ctx, current.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if replaced.GetStatus() != subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED {
+		t.Errorf("expected the replaced subscription to be cancelled, got %q", replaced.GetStatus())
+	}
+
+	if !gateway.SubscriptionCancelled(current.GetID()) {
+		t.Error("expected the replaced subscription to be cancelled at the payment provider")
+	}
+}
+
+func TestSubscriptionCancel_ProviderFailureKeepsSubscription(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	subscription, err := testutils.SeedSubscription(store, test.USER_01, PLAN_PRO)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// No checkout session exists at the gateway for the subscription
+	if err := SubscriptionCancel(ctx, store, payment.NewFakeGateway("secret"), subscription); err == nil {
+		t.Fatal("expected an error when the provider fails")
+	}
+
+	active, err := SubscriptionActiveFind(ctx, store, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if active == nil {
+		t.Error("expected the subscription to stay active")
+	}
+}
@@ -17,7 +17,10 @@ const (
 	CommandJob         = "job"
 	CommandRoutes      = "routes"
 	CommandMaintenance = "maintenance"
+	CommandMigrate     = "migrate"
 	SubcommandList     = "list"
+
+	CommandMakeMigration = "make:migration"
 )
 
 // NewDispatcher creates a new CLI dispatcher with blueprint-specific commands registered.
@@ -29,6 +32,8 @@ func NewDispatcher() *cli.Dispatcher[app.AppInterface] {
 	dispatcher.RegisterCommand(CommandJob, "Execute a job with arguments", handleJobCommand)
 	dispatcher.RegisterCommand(CommandRoutes, "List all registered routes", handleRoutesCommand)
 	dispatcher.RegisterCommand(CommandMaintenance, "Manage maintenance mode", handleMaintenanceCommand)
+	dispatcher.RegisterCommand(CommandMigrate, "Run, roll back or list the database migrations", handleMigrateCommand)
+	dispatcher.RegisterCommand(CommandMakeMigration, "Scaffold a new database migration", handleMakeMigrationCommand)
 
 	return dispatcher
 }
@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"project/database/migrations"
+	"project/internal/app"
+)
+
+// Migrate subcommand constants
+const (
+	MigrateStatus = "status"
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateFresh  = "fresh"
+)
+
+// migrationsDir is the directory the migrations are scaffolded in,
+// relative to the root of the project
+const migrationsDir = "database/migrations"
+
+// migrationNameRegex matches the allowed migration names (snake case)
+var migrationNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// handleMigrateCommand handles the 'migrate' command.
+func handleMigrateCommand(app app.AppInterface, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing subcommand for '%s'. Use: %s status|up|down|fresh", CommandMigrate, CommandMigrate)
+	}
+
+	subcommand := args[0]
+	rest := args[1:]
+
+	switch subcommand {
+	case MigrateStatus:
+		return handleMigrateStatus(app)
+	case MigrateUp:
+		return handleMigrateUp(app)
+	case MigrateDown:
+		return handleMigrateDown(app, rest)
+	case MigrateFresh:
+		return handleMigrateFresh(app)
+	}
+
+	return fmt.Errorf("unknown %s subcommand '%s'. Use: status, up, down, fresh", CommandMigrate, subcommand)
+}
+
+func handleMigrateStatus(app app.AppInterface) error {
+	statuses, err := migrations.MigrationStatuses(app)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-4s  %-5s  %s\n", "Ran", "Batch", "Migration")
+	for _, status := range statuses {
+		ran := "No"
+		batch := "-"
+		if status.Ran {
+			ran = "Yes"
+			batch = strconv.Itoa(status.Batch)
+		}
+		fmt.Printf("%-4s  %-5s  %s\n", ran, batch, status.Signature)
+	}
+
+	return nil
+}
+
+func handleMigrateUp(app app.AppInterface) error {
+	if err := migrations.MigrateAll(app); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	fmt.Println("Migrations: up to date")
+	return nil
+}
+
+func handleMigrateDown(app app.AppInterface, args []string) error {
+	steps, err := parseMigrateDownSteps(args)
+	if err != nil {
+		return err
+	}
+
+	if err := migrations.MigrateDown(app, steps); err != nil {
+		return fmt.Errorf("failed to roll back migrations: %w", err)
+	}
+
+	fmt.Printf("Migrations: rolled back %d step(s)\n", steps)
+	return nil
+}
+
+func handleMigrateFresh(app app.AppInterface) error {
+	if err := migrations.MigrateFresh(app); err != nil {
+		return err
+	}
+
+	fmt.Println("Migrations: all rolled back and run again")
+	return nil
+}
+
+// parseMigrateDownSteps returns the number of steps to roll back,
+// set with --steps=N or --steps N, 1 by default
+func parseMigrateDownSteps(args []string) (int, error) {
+	value := "1"
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if strings.HasPrefix(arg, "--steps=") {
+			value = strings.TrimPrefix(arg, "--steps=")
+		} else if arg == "--steps" && i+1 < len(args) {
+			i++
+			value = args[i]
+		} else {
+			return 0, fmt.Errorf("unknown argument '%s'. Use: %s %s --steps=N", arg, CommandMigrate, MigrateDown)
+		}
+	}
+
+	steps, err := strconv.Atoi(value)
+	if err != nil || steps < 1 {
+		return 0, fmt.Errorf("invalid steps '%s', must be a number of at least 1", value)
+	}
+
+	return steps, nil
+}
+
+// handleMakeMigrationCommand handles the 'make:migration' command.
+func handleMakeMigrationCommand(app app.AppInterface, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migration name for '%s'. Use: %s table_posts_create", CommandMakeMigration, CommandMakeMigration)
+	}
+
+	filePath, err := makeMigration(migrationsDir, args[0], time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Migration created: %s\n", filePath)
+	fmt.Printf("  Test created: %s\n", strings.TrimSuffix(filePath, ".go")+"_test.go")
+	fmt.Println("  Register it in getSQLMigrations() in " + filepath.Join(migrationsDir, "migrate.go"))
+
+	return nil
+}
+
+// makeMigration scaffolds a migration and its test in the directory,
+// named after the date and the next sequence number of the day
+// (i.e. 2026_03_21_0001_table_posts_create.go), and returns the path
+// of the migration
+func makeMigration(dir string, name string, now time.Time) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if !migrationNameRegex.MatchString(name) {
+		return "", fmt.Errorf("invalid migration name '%s', use lower case letters, digits and underscores (i.e. table_posts_create)", name)
+	}
+
+	sequence, err := nextMigrationSequence(dir, now)
+	if err != nil {
+		return "", err
+	}
+
+	signature := fmt.Sprintf("%s_%04d_%s", now.Format("2006_01_02"), sequence, name)
+	typeName := migrationTypeName(name)
+	description := migrationDescription(name)
+
+	filePath := filepath.Join(dir, signature+".go")
+	testFilePath := filepath.Join(dir, signature+"_test.go")
+
+	if err := os.WriteFile(filePath, []byte(migrationFileContent(typeName, signature, description)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write migration '%s': %w", filePath, err)
+	}
+
+	if err := os.WriteFile(testFilePath, []byte(migrationTestFileContent(typeName, signature, description)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write migration test '%s': %w", testFilePath, err)
+	}
+
+	return filePath, nil
+}
+
+// nextMigrationSequence returns the sequence number following the last
+// migration of the day in the directory
+func nextMigrationSequence(dir string, now time.Time) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations directory '%s': %w", dir, err)
+	}
+
+	prefix := now.Format("2006_01_02") + "_"
+	last := 0
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+
+		rest := strings.TrimPrefix(entry.Name(), prefix)
+		if len(rest) < 4 {
+			continue
+		}
+
+		if sequence, err := strconv.Atoi(rest[:4]); err == nil && sequence > last {
+			last = sequence
+		}
+	}
+
+	return last + 1, nil
+}
+
+// migrationTypeName converts the snake case name to the name of the
+// migration type (i.e. table_posts_create to TablePostsCreate)
+func migrationTypeName(name string) string {
+	typeName := ""
+
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		typeName += strings.ToUpper(part[:1]) + part[1:]
+	}
+
+	return typeName
+}
+
+// migrationDescription converts the snake case name to a sentence
+// (i.e. table_posts_create to Table posts create)
+func migrationDescription(name string) string {
+	description := strings.Join(strings.Fields(strings.ReplaceAll(name, "_", " ")), " ")
+
+	return strings.ToUpper(description[:1]) + description[1:]
+}
+
+func migrationFileContent(typeName string, signature string, description string) string {
+	return `package migrations
+
+import (
+	contractsschema "github.com/dracory/neat/contracts/database/schema"
+	"github.com/dracory/neat/database/migrator"
+)
+
+var _ migrator.MigrationInterface = (*` + typeName + `)(nil)
+
+// ` + typeName + ` is a custom schema migration.
+// Register it in migrate.go getSQLMigrations().
+type ` + typeName + ` struct {
+	migrator.BaseMigration
+}
+
+func (m *` + typeName + `) Signature() string {
+	return "` + signature + `"
+}
+
+func (m *` + typeName + `) Description() string {
+	return "` + description + `"
+}
+
+func (m *` + typeName + `) Up() error {
+	// TODO: replace with the table of the migration
+	if m.GetSchema().HasTable("example") {
+		return nil
+	}
+
+	return m.GetSchema().Create("example", func(blueprint contractsschema.Blueprint) {
+		blueprint.ID()
+		blueprint.Timestamps()
+	})
+}
+
+func (m *` + typeName + `) Down() error {
+	return m.GetSchema().DropIfExists("example")
+}
+`
+}
+
+func migrationTestFileContent(typeName string, signature string, description string) string {
+	return `package migrations
+
+import "testing"
+
+func Test` + typeName + `_InterfaceMethods(t *testing.T) {
+	migration := &` + typeName + `{}
+
+	if migration.Signature() != "` + signature + `" {
+		t.Errorf("Expected signature '` + signature + `', got '%s'", migration.Signature())
+	}
+
+	if migration.Description() != "` + description + `" {
+		t.Errorf("Expected description '` + description + `', got '%s'", migration.Description())
+	}
+}
+`
+}
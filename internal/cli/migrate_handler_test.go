@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleMigrateCommand_InvalidSubcommand(t *testing.T) {
+	if err := handleMigrateCommand(nil, []string{}); err == nil {
+		t.Error("expected an error without a subcommand")
+	}
+
+	if err := handleMigrateCommand(nil, []string{"sideways"}); err == nil || !strings.Contains(err.Error(), "unknown migrate subcommand") {
+		t.Errorf("expected an unknown subcommand error, got %v", err)
+	}
+}
+
+func TestParseMigrateDownSteps(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    int
+		wantErr bool
+	}{
+		{args: []string{}, want: 1},
+		{args: []string{"--steps=3"}, want: 3},
+		{args: []string{"--steps", "2"}, want: 2},
+		{args: []string{"--steps=0"}, wantErr: true},
+		{args: []string{"--steps=abc"}, wantErr: true},
+		{args: []string{"--force"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		steps, err := parseMigrateDownSteps(test.args)
+
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseMigrateDownSteps(%v): expected an error", test.args)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseMigrateDownSteps(%v): unexpected error %v", test.args, err)
+		}
+		if steps != test.want {
+			t.Errorf("parseMigrateDownSteps(%v) = %d, want %d", test.args, steps, test.want)
+		}
+	}
+}
+
+func TestMakeMigration_CreatesTheMigrationAndItsTest(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 21, 10, 0, 0, 0, time.UTC)
+
+	// Existing migrations of the day, and of another day
+	for _, name := range []string{"2026_03_21_0001_store_audit_migrate.go", "2026_03_21_0002_store_blog_migrate.go", "2026_03_22_0007_table_custom_create.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package migrations\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filePath, err := makeMigration(dir, "table_posts_create", now)
+	if err != nil {
+		t.Fatalf("makeMigration failed: %v", err)
+	}
+
+	if filePath != filepath.Join(dir, "2026_03_21_0003_table_posts_create.go") {
+		t.Fatalf("unexpected file path %s", filePath)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read the migration: %v", err)
+	}
+
+	for _, expected := range []string{"type TablePostsCreate struct", `return "2026_03_21_0003_table_posts_create"`, `return "Table posts create"`} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("expected the migration to contain %q", expected)
+		}
+	}
+
+	testContent, err := os.ReadFile(filepath.Join(dir, "2026_03_21_0003_table_posts_create_test.go"))
+	if err != nil {
+		t.Fatalf("failed to read the migration test: %v", err)
+	}
+
+	if !strings.Contains(string(testContent), "func TestTablePostsCreate_InterfaceMethods(t *testing.T)") {
+		t.Error("expected the test of the migration")
+	}
+}
+
+func TestMakeMigration_InvalidName(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"", "TablePosts", "table-posts", "1_table", "../table"} {
+		if _, err := makeMigration(dir, name, time.Now()); err == nil {
+			t.Errorf("makeMigration(%q): expected an error", name)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected no files to be created, got %d", len(entries))
+	}
+}
+
+func TestMigrationTypeName(t *testing.T) {
+	if got := migrationTypeName("table_posts_create"); got != "TablePostsCreate" {
+		t.Errorf("migrationTypeName() = %q, want TablePostsCreate", got)
+	}
+
+	if got := migrationTypeName("add_2fa__to_users"); got != "Add2faToUsers" {
+		t.Errorf("migrationTypeName() = %q, want Add2faToUsers", got)
+	}
+}
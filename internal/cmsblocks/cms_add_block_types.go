@@ -39,9 +39,11 @@ func CmsAddBlockTypes(app app.AppInterface) {
 		// Register the Blog Post block type as a custom block
 		blogPostBlock := blogpost.NewBlogPostBlockType(app.GetBlogStore())
 		cmsstore.RegisterCustomBlockType(blogPostBlock)
+	}
 
-		// Register the Search block type as a custom block
-		searchBlock := search.NewSearchBlockType(app.GetCmsStore(), app.GetBlogStore())
+	// Register the Search block type as a custom block
+	if app.GetSearchIndex() != nil {
+		searchBlock := search.NewSearchBlockType(app.GetSearchIndex())
 		cmsstore.RegisterCustomBlockType(searchBlock)
 	}
 }
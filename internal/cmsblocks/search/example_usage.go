@@ -4,14 +4,15 @@ import (
 	"context"
 	"log"
 
-	"github.com/dracory/blogstore"
+	"project/internal/searchindex"
+
 	"github.com/dracory/cmsstore"
 )
 
 // ExampleUsage demonstrates how to use the Search block
-func ExampleUsage(cmsStore cmsstore.StoreInterface, blogStore blogstore.StoreInterface) {
-	// Create the Search block type
-	searchBlock := NewSearchBlockType(cmsStore, blogStore)
+func ExampleUsage(index searchindex.IndexInterface) {
+	// Create the Search block type, searching the index
+	searchBlock := NewSearchBlockType(index)
 
 	// Register the block type globally as a custom block
 	cmsstore.RegisterCustomBlockType(searchBlock)
@@ -21,7 +22,7 @@ func ExampleUsage(cmsStore cmsstore.StoreInterface, blogStore blogstore.StoreInt
 }
 
 // ExampleRendering demonstrates how to render the search block
-func ExampleRendering(cmsStore cmsstore.StoreInterface, blogStore blogstore.StoreInterface, blockID string) {
+func ExampleRendering(cmsStore cmsstore.StoreInterface, blockID string) {
 	// Get the block
 	block, err := cmsStore.BlockFindByID(context.Background(), blockID)
 	if err != nil {
@@ -55,6 +56,7 @@ func ExampleConfiguration(block cmsstore.BlockInterface) {
 	block.SetMeta("results_per_page", "10")
 
 	// Enable/disable content types in search
-	block.SetMeta("show_pages", "true") // Include CMS pages
-	block.SetMeta("show_posts", "true") // Include blog posts
+	block.SetMeta("show_pages", "true")    // Include CMS pages
+	block.SetMeta("show_posts", "true")    // Include blog posts
+	block.SetMeta("show_products", "true") // Include shop products
 }
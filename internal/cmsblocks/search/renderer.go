@@ -120,6 +120,9 @@ func renderResultItem(result SearchResult) hb.TagInterface {
 	case "post":
 		badgeColor = "#794FC6"
 		badgeText = "Blog Post"
+	case "product":
+		badgeColor = "#28a745"
+		badgeText = "Product"
 	default:
 		badgeColor = "#6c757d"
 		badgeText = "Content"
@@ -154,10 +157,10 @@ func renderResultItem(result SearchResult) hb.TagInterface {
 			Text(metaText)
 	}
 
-	// Summary
+	// Summary (the snippet is already escaped, with the matches in <mark>)
 	summary := hb.Paragraph().
 		Style("font-size: 16px; color: #333; line-height: 1.5; margin-bottom: 0;").
-		Text(escapeHTML(result.Summary))
+		HTML(result.Summary)
 
 	resultContent := []hb.TagInterface{badge, titleLink}
 	if metaInfo != nil {
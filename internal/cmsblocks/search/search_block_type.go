@@ -3,27 +3,26 @@ package search
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
-	"project/internal/links"
 	"strconv"
 	"strings"
 
-	"github.com/dracory/blogstore"
+	"project/internal/searchindex"
+
 	"github.com/dracory/cmsstore"
 	"github.com/dracory/form"
 )
 
 // SearchBlockType represents a block that provides search functionality
 type SearchBlockType struct {
-	cmsStore  cmsstore.StoreInterface
-	blogStore blogstore.StoreInterface
+	index searchindex.IndexInterface
 }
 
-// NewSearchBlockType creates a new Search block type
-func NewSearchBlockType(cmsStore cmsstore.StoreInterface, blogStore blogstore.StoreInterface) *SearchBlockType {
+// NewSearchBlockType creates a new Search block type, searching the index
+func NewSearchBlockType(index searchindex.IndexInterface) *SearchBlockType {
 	return &SearchBlockType{
-		cmsStore:  cmsStore,
-		blogStore: blogStore,
+		index: index,
 	}
 }
 
@@ -52,8 +51,7 @@ func (t *SearchBlockType) Render(ctx context.Context, block cmsstore.BlockInterf
 		placeholder = "Search..."
 	}
 
-	showPages := block.Meta("show_pages") != "false"
-	showPosts := block.Meta("show_posts") != "false"
+	types := searchTypes(block)
 
 	// Get search query from request
 	searchQuery := ""
@@ -71,8 +69,8 @@ func (t *SearchBlockType) Render(ctx context.Context, block cmsstore.BlockInterf
 	var results []SearchResult
 	totalResults := 0
 
-	if searchQuery != "" && (showPages || showPosts) {
-		results, totalResults = t.performSearch(ctx, searchQuery, showPages, showPosts, pageNum, resultsPerPage)
+	if searchQuery != "" && len(types) > 0 {
+		results, totalResults = t.performSearch(ctx, searchQuery, types, pageNum, resultsPerPage)
 	}
 
 	// Render
@@ -81,168 +79,61 @@ func (t *SearchBlockType) Render(ctx context.Context, block cmsstore.BlockInterf
 
 // SearchResult represents a single search result
 type SearchResult struct {
-	Title   string
+	Title string
+	// Summary the HTML snippet of the content, with the matches highlighted
 	Summary string
 	URL     string
-	Type    string // "page" or "post"
+	Type    string // "page", "post" or "product"
 	Date    string
 }
 
-// performSearch searches across pages and blog posts
-func (t *SearchBlockType) performSearch(ctx context.Context, query string, showPages, showPosts bool, pageNum, resultsPerPage int) ([]SearchResult, int) {
-	var allResults []SearchResult
-	searchLower := strings.ToLower(query)
-
-	// Search pages if enabled and cmsStore is available
-	if showPages && t.cmsStore != nil {
-		pages, err := t.cmsStore.PageList(ctx, cmsstore.PageQuery().
-			SetStatus(cmsstore.PAGE_STATUS_ACTIVE))
-
-		if err == nil {
-			for _, page := range pages {
-				if pageMatchesSearch(page, searchLower) {
-					summary := stripHTML(page.Content())
-					if len(summary) > 200 {
-						summary = summary[:200] + "..."
-					}
-
-					allResults = append(allResults, SearchResult{
-						Title:   page.Title(),
-						Summary: summary,
-						URL:     "/" + strings.TrimPrefix(page.Alias(), "/"),
-						Type:    "page",
-					})
-				}
-			}
-		}
-	}
-
-	// Search blog posts if enabled and blogStore is available
-	if showPosts && t.blogStore != nil {
-		postOpts := blogstore.PostQueryOptions{
-			Status:    blogstore.POST_STATUS_PUBLISHED,
-			SortOrder: "DESC",
-			OrderBy:   "published_at",
-		}
-
-		posts, err := t.blogStore.PostList(ctx, postOpts)
-		if err == nil {
-			for _, post := range posts {
-				if postMatchesSearch(post, searchLower) {
-					summary := post.GetSummary()
-					if summary == "" && post.GetContent() != "" {
-						summary = stripHTML(post.GetContent())
-						if len(summary) > 200 {
-							summary = summary[:200] + "..."
-						}
-					}
-
-					dateStr := ""
-					if post.GetPublishedAt() != "" {
-						dateStr = post.GetPublishedAtCarbon().Format("d M, Y")
-					}
-
-					allResults = append(allResults, SearchResult{
-						Title:   post.GetTitle(),
-						Summary: summary,
-						URL:     links.Website().BlogPost(post.GetID(), post.GetSlug()),
-						Type:    "post",
-						Date:    dateStr,
-					})
-				}
-			}
-		}
-	}
-
-	// Calculate total
-	total := len(allResults)
-
-	// Paginate results
-	offset := pageNum * resultsPerPage
-	if offset >= total {
-		return []SearchResult{}, total
-	}
-
-	end := offset + resultsPerPage
-	if end > total {
-		end = total
-	}
-
-	return allResults[offset:end], total
-}
-
-// pageMatchesSearch checks if a page matches the search query
-func pageMatchesSearch(page cmsstore.PageInterface, query string) bool {
-	if page == nil {
-		return false
-	}
-
-	// Check title
-	if strings.Contains(strings.ToLower(page.Title()), query) {
-		return true
+// performSearch searches the index for the content types
+func (t *SearchBlockType) performSearch(ctx context.Context, query string, types []string, pageNum, resultsPerPage int) ([]SearchResult, int) {
+	if t.index == nil {
+		return []SearchResult{}, 0
 	}
 
-	// Check content (HTML stripped)
-	contentLower := strings.ToLower(stripHTML(page.Content()))
-	if strings.Contains(contentLower, query) {
-		return true
-	}
+	found, total, err := t.index.Search(ctx, searchindex.Query{
+		Text:   query,
+		Types:  types,
+		Offset: pageNum * resultsPerPage,
+		Limit:  resultsPerPage,
+	})
 
-	// Check alias
-	if strings.Contains(strings.ToLower(page.Alias()), query) {
-		return true
+	if err != nil {
+		slog.Error("At SearchBlockType > performSearch", slog.String("error", err.Error()))
+		return []SearchResult{}, 0
 	}
 
-	// Check meta description
-	if strings.Contains(strings.ToLower(page.MetaDescription()), query) {
-		return true
+	results := make([]SearchResult, 0, len(found))
+	for _, result := range found {
+		results = append(results, SearchResult{
+			Title:   result.Title,
+			Summary: result.Snippet,
+			URL:     result.URL,
+			Type:    result.Type,
+			Date:    result.Date,
+		})
 	}
 
-	return false
+	return results, total
 }
 
-// postMatchesSearch checks if a blog post matches the search query
-func postMatchesSearch(post blogstore.PostInterface, query string) bool {
-	// Check title
-	if strings.Contains(strings.ToLower(post.GetTitle()), query) {
-		return true
-	}
+// searchTypes returns the content types selected in the block
+func searchTypes(block cmsstore.BlockInterface) []string {
+	types := []string{}
 
-	// Check content (HTML stripped)
-	contentLower := strings.ToLower(stripHTML(post.GetContent()))
-	if strings.Contains(contentLower, query) {
-		return true
+	if block.Meta("show_pages") != "false" {
+		types = append(types, searchindex.DOCUMENT_TYPE_PAGE)
 	}
-
-	// Check summary
-	if strings.Contains(strings.ToLower(post.GetSummary()), query) {
-		return true
+	if block.Meta("show_posts") != "false" {
+		types = append(types, searchindex.DOCUMENT_TYPE_POST)
 	}
-
-	// Check slug
-	if strings.Contains(strings.ToLower(post.GetSlug()), query) {
-		return true
+	if block.Meta("show_products") == "true" {
+		types = append(types, searchindex.DOCUMENT_TYPE_PRODUCT)
 	}
 
-	return false
-}
-
-// stripHTML removes HTML tags from a string
-func stripHTML(html string) string {
-	// Simple HTML tag removal
-	result := html
-	for {
-		start := strings.Index(result, "<")
-		if start == -1 {
-			break
-		}
-		end := strings.Index(result[start:], ">")
-		if end == -1 {
-			break
-		}
-		result = result[:start] + result[start+end+1:]
-	}
-	return strings.TrimSpace(result)
+	return types
 }
 
 // GetAdminFields returns form fields for editing block configuration
@@ -284,6 +175,17 @@ func (t *SearchBlockType) GetAdminFields(block cmsstore.BlockInterface, r *http.
 				{Value: "No", Key: "false"},
 			},
 		}),
+		form.NewField(form.FieldOptions{
+			Label: "Show Products",
+			Name:  "show_products",
+			Type:  form.FORM_FIELD_TYPE_SELECT,
+			Value: block.Meta("show_products"),
+			Help:  "Include shop products in search results (default: No)",
+			Options: []form.FieldOption{
+				{Value: "No", Key: "false"},
+				{Value: "Yes", Key: "true"},
+			},
+		}),
 	}
 
 	return fields
@@ -297,6 +199,7 @@ func (t *SearchBlockType) SaveAdminFields(r *http.Request, block cmsstore.BlockI
 	block.SetMeta("results_per_page", r.FormValue("results_per_page"))
 	block.SetMeta("show_pages", r.FormValue("show_pages"))
 	block.SetMeta("show_posts", r.FormValue("show_posts"))
+	block.SetMeta("show_products", r.FormValue("show_products"))
 
 	return nil
 }
@@ -308,15 +211,15 @@ func (t *SearchBlockType) Validate(block cmsstore.BlockInterface) error {
 
 // GetPreview returns a preview of the block
 func (t *SearchBlockType) GetPreview(block cmsstore.BlockInterface) string {
-	showPages := block.Meta("show_pages") != "false"
-	showPosts := block.Meta("show_posts") != "false"
+	labels := map[string]string{
+		searchindex.DOCUMENT_TYPE_PAGE:    "Pages",
+		searchindex.DOCUMENT_TYPE_POST:    "Blog Posts",
+		searchindex.DOCUMENT_TYPE_PRODUCT: "Products",
+	}
 
 	var types []string
-	if showPages {
-		types = append(types, "Pages")
-	}
-	if showPosts {
-		types = append(types, "Blog Posts")
+	for _, documentType := range searchTypes(block) {
+		types = append(types, labels[documentType])
 	}
 
 	if len(types) == 0 {
@@ -1,10 +1,12 @@
 package search
 
 import (
+	"context"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"project/internal/searchindex"
 	"project/internal/testutils"
 
 	"github.com/dracory/cmsstore"
@@ -12,12 +14,7 @@ import (
 
 // TestSearchBlockType_BasicProperties tests basic properties
 func TestSearchBlockType_BasicProperties(t *testing.T) {
-	app := testutils.Setup(
-		testutils.WithCmsStore(true),
-		testutils.WithBlogStore(true),
-	)
-
-	blockType := NewSearchBlockType(app.GetCmsStore(), app.GetBlogStore())
+	blockType := NewSearchBlockType(searchindex.NewMemoryIndex("en"))
 
 	if blockType.TypeKey() != "search" {
 		t.Errorf("Expected type key 'search', got '%s'", blockType.TypeKey())
@@ -30,13 +27,7 @@ func TestSearchBlockType_BasicProperties(t *testing.T) {
 
 // TestSearchBlockType_GetPreview tests preview
 func TestSearchBlockType_GetPreview(t *testing.T) {
-	app := testutils.Setup(
-		testutils.WithCmsStore(true),
-		testutils.WithBlogStore(true),
-	)
-
-	cmsStore := app.GetCmsStore()
-	blockType := NewSearchBlockType(cmsStore, app.GetBlogStore())
+	blockType := NewSearchBlockType(searchindex.NewMemoryIndex("en"))
 
 	// Create a real block for testing
 	block := cmsstore.NewBlock()
@@ -64,8 +55,16 @@ func TestSearchBlockType_GetPreview(t *testing.T) {
 		t.Errorf("Expected preview 'Search (Blog Posts)', got '%s'", preview)
 	}
 
+	// Test with products
+	block.SetMeta("show_products", "true")
+	preview = blockType.GetPreview(block)
+	if preview != "Search (Blog Posts, Products)" {
+		t.Errorf("Expected preview 'Search (Blog Posts, Products)', got '%s'", preview)
+	}
+
 	// Test with none selected
 	block.SetMeta("show_posts", "false")
+	block.SetMeta("show_products", "false")
 	preview = blockType.GetPreview(block)
 	if preview != "Search (no content types selected)" {
 		t.Errorf("Expected preview 'Search (no content types selected)', got '%s'", preview)
@@ -74,13 +73,7 @@ func TestSearchBlockType_GetPreview(t *testing.T) {
 
 // TestSearchBlockType_Validate tests validation
 func TestSearchBlockType_Validate(t *testing.T) {
-	app := testutils.Setup(
-		testutils.WithCmsStore(true),
-		testutils.WithBlogStore(true),
-	)
-
-	cmsStore := app.GetCmsStore()
-	blockType := NewSearchBlockType(cmsStore, app.GetBlogStore())
+	blockType := NewSearchBlockType(searchindex.NewMemoryIndex("en"))
 
 	block := cmsstore.NewBlock()
 	block.SetType("search")
@@ -93,13 +86,7 @@ func TestSearchBlockType_Validate(t *testing.T) {
 
 // TestSearchBlockType_AdminFields tests admin fields
 func TestSearchBlockType_AdminFields(t *testing.T) {
-	app := testutils.Setup(
-		testutils.WithCmsStore(true),
-		testutils.WithBlogStore(true),
-	)
-
-	cmsStore := app.GetCmsStore()
-	blockType := NewSearchBlockType(cmsStore, app.GetBlogStore())
+	blockType := NewSearchBlockType(searchindex.NewMemoryIndex("en"))
 
 	block := cmsstore.NewBlock()
 	block.SetType("search")
@@ -118,13 +105,7 @@ func TestSearchBlockType_AdminFields(t *testing.T) {
 
 // TestSearchBlockType_SaveAdminFields tests saving admin fields
 func TestSearchBlockType_SaveAdminFields(t *testing.T) {
-	app := testutils.Setup(
-		testutils.WithCmsStore(true),
-		testutils.WithBlogStore(true),
-	)
-
-	cmsStore := app.GetCmsStore()
-	blockType := NewSearchBlockType(cmsStore, app.GetBlogStore())
+	blockType := NewSearchBlockType(searchindex.NewMemoryIndex("en"))
 
 	block := cmsstore.NewBlock()
 	block.SetType("search")
@@ -135,6 +116,7 @@ func TestSearchBlockType_SaveAdminFields(t *testing.T) {
 		"results_per_page": {"15"},
 		"show_pages":       {"true"},
 		"show_posts":       {"false"},
+		"show_products":    {"true"},
 	}
 
 	err := blockType.SaveAdminFields(req, block)
@@ -154,17 +136,15 @@ func TestSearchBlockType_SaveAdminFields(t *testing.T) {
 	if block.Meta("show_posts") != "false" {
 		t.Errorf("Expected show_posts to be 'false', got '%s'", block.Meta("show_posts"))
 	}
+
+	if block.Meta("show_products") != "true" {
+		t.Errorf("Expected show_products to be 'true', got '%s'", block.Meta("show_products"))
+	}
 }
 
 // TestSearchBlockType_Render_EmptyQuery tests rendering without search query
 func TestSearchBlockType_Render_EmptyQuery(t *testing.T) {
-	app := testutils.Setup(
-		testutils.WithCmsStore(true),
-		testutils.WithBlogStore(true),
-	)
-
-	cmsStore := app.GetCmsStore()
-	blockType := NewSearchBlockType(cmsStore, app.GetBlogStore())
+	blockType := NewSearchBlockType(searchindex.NewMemoryIndex("en"))
 
 	block := cmsstore.NewBlock()
 	block.SetType("search")
@@ -191,13 +171,7 @@ func TestSearchBlockType_Render_EmptyQuery(t *testing.T) {
 
 // TestSearchBlockType_Render_WithQuery tests rendering with search query
 func TestSearchBlockType_Render_WithQuery(t *testing.T) {
-	app := testutils.Setup(
-		testutils.WithCmsStore(true),
-		testutils.WithBlogStore(true),
-	)
-
-	cmsStore := app.GetCmsStore()
-	blockType := NewSearchBlockType(cmsStore, app.GetBlogStore())
+	blockType := NewSearchBlockType(searchindex.NewMemoryIndex("en"))
 
 	block := cmsstore.NewBlock()
 	block.SetType("search")
@@ -224,24 +198,56 @@ func TestSearchBlockType_Render_WithQuery(t *testing.T) {
 	}
 }
 
-// TestStripHTML tests the HTML stripping function
-func TestStripHTML(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"<p>Hello World</p>", "Hello World"},
-		{"<div><p>Test</p></div>", "Test"},
-		{"Plain text", "Plain text"},
-		{"<a href='#'>Link</a> text", "Link text"},
-		{"", ""},
+// TestSearchBlockType_Render_RankedResults tests rendering the results from the index
+func TestSearchBlockType_Render_RankedResults(t *testing.T) {
+	index := searchindex.NewMemoryIndex("en")
+	err := index.Upsert(context.Background(),
+		searchindex.Document{ID: "page:1", Type: searchindex.DOCUMENT_TYPE_PAGE, Title: "About", Body: "We write about running.", URL: "/about"},
+		searchindex.Document{ID: "post:1", Type: searchindex.DOCUMENT_TYPE_POST, Title: "Running tips", Body: "Tips for runners who run <daily>.", URL: "/blog/post/1/running-tips", Date: "01 Jan, 2026"},
+		searchindex.Document{ID: "product:1", Type: searchindex.DOCUMENT_TYPE_PRODUCT, Title: "Running shoe", Body: "A shoe.", URL: "/shop/product/1/running-shoe"},
+	)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	for _, test := range tests {
-		result := stripHTML(test.input)
-		if result != test.expected {
-			t.Errorf("stripHTML(%q) = %q, expected %q", test.input, result, test.expected)
-		}
+	blockType := NewSearchBlockType(index)
+
+	block := cmsstore.NewBlock()
+	block.SetType("search")
+
+	req, _ := testutils.NewRequest("GET", "/search", testutils.NewRequestOptions{
+		QueryParams: map[string][]string{"q": {"run"}},
+	})
+	ctx := cmsstore.RequestToContext(req.Context(), req)
+
+	html, err := blockType.Render(ctx, block)
+	if err != nil {
+		t.Fatalf("Expected no error rendering, got: %v", err)
+	}
+
+	// the title match ranks first
+	if !strings.Contains(html, "Running tips") || strings.Index(html, "Running tips") > strings.Index(html, "/about") {
+		t.Error("Expected the post to be ranked before the page")
+	}
+
+	// the snippet is highlighted and escaped
+	if !strings.Contains(html, "<mark>run</mark> &lt;daily&gt;") {
+		t.Error("Expected the highlighted and escaped snippet")
+	}
+
+	// products are not searched by default
+	if strings.Contains(html, "Running shoe") {
+		t.Error("Expected no products without show_products")
+	}
+
+	block.SetMeta("show_products", "true")
+	html, err = blockType.Render(ctx, block)
+	if err != nil {
+		t.Fatalf("Expected no error rendering, got: %v", err)
+	}
+
+	if !strings.Contains(html, "Running shoe") {
+		t.Error("Expected the product with show_products")
 	}
 }
 
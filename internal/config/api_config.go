@@ -0,0 +1,30 @@
+package config
+
+import "strings"
+
+// apiConfig reads the REST API settings from environment variables
+func apiConfig(env *envValidator) apiSettings {
+	// CORS
+	//
+	// The origins (e.g. https://app.example.com) allowed to call the API
+	// from a browser, comma separated. "*" allows any origin, which is safe
+	// as the API is authenticated with tokens, not cookies.
+	// When empty, only the pages of the application itself can call it
+	corsAllowedOrigins := []string{}
+
+	for _, origin := range strings.Split(env.GetString(KEY_API_CORS_ALLOWED_ORIGINS), ",") {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+
+		if origin != "" {
+			corsAllowedOrigins = append(corsAllowedOrigins, origin)
+		}
+	}
+
+	return apiSettings{
+		corsAllowedOrigins: corsAllowedOrigins,
+	}
+}
+
+type apiSettings struct {
+	corsAllowedOrigins []string
+}
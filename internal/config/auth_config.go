@@ -66,17 +66,41 @@ func authConfig() authSettings {
 	// When false, authentication is handled exclusively by external providers.
 	passwordAuthEnabled := env.GetBool(KEY_AUTH_PASSWORD_AUTH_ENABLED)
 
+	// Magic Link Authentication
+	//
+	// Controls whether the users can log in with a single use link sent
+	// to their email, handled by the application instead of AuthKnight.
+	magicLinkEnabled := env.GetBool(KEY_AUTH_MAGIC_LINK_ENABLED)
+
+	// Admin Two-Factor Authentication
+	//
+	// Controls whether the administrators must enable two-factor
+	// authentication before they can access the admin panel.
+	adminTwoFactorRequired := env.GetBool(KEY_AUTH_ADMIN_2FA_REQUIRED)
+
+	// Session Country Lookup
+	//
+	// Controls whether the countries of the IP addresses of the sessions
+	// are looked up with ip2c.org, when the users list their sessions.
+	sessionCountryLookupEnabled := env.GetBoolOrDefault(KEY_AUTH_SESSION_COUNTRY_LOOKUP_ENABLED, true)
+
 	return authSettings{
-		registrationEnabled: registrationEnabled,
-		emailsAllowedAccess: emailsAllowedAccess,
-		csrfSecret:          csrfSecret,
-		passwordAuthEnabled: passwordAuthEnabled,
+		registrationEnabled:         registrationEnabled,
+		emailsAllowedAccess:         emailsAllowedAccess,
+		csrfSecret:                  csrfSecret,
+		passwordAuthEnabled:         passwordAuthEnabled,
+		magicLinkEnabled:            magicLinkEnabled,
+		adminTwoFactorRequired:      adminTwoFactorRequired,
+		sessionCountryLookupEnabled: sessionCountryLookupEnabled,
 	}
 }
 
 type authSettings struct {
-	registrationEnabled bool
-	emailsAllowedAccess []string
-	csrfSecret          string
-	passwordAuthEnabled bool
+	registrationEnabled         bool
+	emailsAllowedAccess         []string
+	csrfSecret                  string
+	passwordAuthEnabled         bool
+	magicLinkEnabled            bool
+	adminTwoFactorRequired      bool
+	sessionCountryLookupEnabled bool
 }
@@ -3,6 +3,7 @@
 package config
 
 import (
+	"project/internal/ratelimit"
 	"project/internal/resources"
 
 	baseCfg "github.com/dracory/base/config"
@@ -41,6 +42,7 @@ type configImplementation struct {
 	databaseMaxIdleConns           int
 	databaseConnMaxLifetimeSeconds int
 	databaseConnMaxIdleTimeSeconds int
+	databaseAutoMigrate            bool
 
 	// Email configuration
 	emailDriver      string
@@ -80,16 +82,64 @@ type configImplementation struct {
 	mediaSecret   string
 	mediaUrl      string
 
+	mediaCacheControl      string
+	mediaThumbCacheControl string
+	mediaPrivatePaths      []string
+	mediaSigningKey        string
+
+	// API configuration
+	apiCorsAllowedOrigins []string
+
+	// Content Security Policy configuration
+	cspScriptSources  []string
+	cspStyleSources   []string
+	cspConnectSources []string
+	cspFontSources    []string
+	cspImgSources     []string
+	cspReportOnly     bool
+
+	// Rate limit configuration
+	rateLimitStore    string
+	rateLimitPolicies map[string][]ratelimit.Policy
+
+	// OAuth configuration
+	oauthGoogleClientID        string
+	oauthGoogleClientSecret    string
+	oauthGitHubClientID        string
+	oauthGitHubClientSecret    string
+	oauthMicrosoftClientID     string
+	oauthMicrosoftClientSecret string
+	oauthMicrosoftTenant       string
+	oauthOIDCName              string
+	oauthOIDCIssuerURL         string
+	oauthOIDCClientID          string
+	oauthOIDCClientSecret      string
+
 	// Payment configuration
-	stripeKeyPrivate string
-	stripeKeyPublic  string
-	stripeUsed       bool
+	paymentGateway      string
+	paymentCurrency     string
+	stripeKeyPrivate    string
+	stripeKeyPublic     string
+	stripeWebhookSecret string
+	stripeUsed          bool
+
+	// Shop pricing configuration
+	shopTaxRates         string
+	shopShippingMethods  string
+	shopPricesIncludeTax bool
+
+	// Shop inventory configuration
+	shopStockTrackingEnabled    bool
+	shopStockReservationMinutes int
 
 	// Authentication
-	registrationEnabled bool
-	emailsAllowedAccess []string
-	csrfSecret          string
-	passwordAuthEnabled bool
+	registrationEnabled         bool
+	emailsAllowedAccess         []string
+	csrfSecret                  string
+	passwordAuthEnabled         bool
+	magicLinkEnabled            bool
+	adminTwoFactorRequired      bool
+	sessionCountryLookupEnabled bool
 
 	// i18n / Translation
 	translationLanguageDefault string
@@ -176,8 +226,14 @@ func NewFromEnv() (ConfigInterface, error) {
 	cfg.setDatabaseConfig(databaseConfig(v))
 	cfg.setMailConfig(emailConfig())
 	cfg.setAuthConfig(authConfig())
+	cfg.setMediaConfig(mediaConfig(v))
+	cfg.setAPIConfig(apiConfig(v))
+	cfg.setCSPConfig(cspConfig(v))
+	cfg.setRateLimitConfig(rateLimitConfig(v))
+	cfg.setOAuthConfig(oauthConfig(v))
 	cfg.setStoresConfig(storesConfig(v))
-	cfg.setStripeConfig(paymentConfig())
+	cfg.setStripeConfig(paymentConfig(v, cfg.IsEnvProduction()))
+	cfg.setShopConfig(shopConfig(v))
 	cfg.setLLMConfig(llmConfig(v))
 	cfg.setTranslationConfig(i18nConfig())
 
@@ -323,6 +379,9 @@ func (c *configImplementation) setAuthConfig(s authSettings) {
 	c.emailsAllowedAccess = s.emailsAllowedAccess
 	c.csrfSecret = s.csrfSecret
 	c.passwordAuthEnabled = s.passwordAuthEnabled
+	c.magicLinkEnabled = s.magicLinkEnabled
+	c.adminTwoFactorRequired = s.adminTwoFactorRequired
+	c.sessionCountryLookupEnabled = s.sessionCountryLookupEnabled
 }
 
 func (c *configImplementation) SetRegistrationEnabled(v bool) {
@@ -357,6 +416,30 @@ func (c *configImplementation) GetPasswordAuthEnabled() bool {
 	return c.passwordAuthEnabled
 }
 
+func (c *configImplementation) SetMagicLinkEnabled(v bool) {
+	c.magicLinkEnabled = v
+}
+
+func (c *configImplementation) GetMagicLinkEnabled() bool {
+	return c.magicLinkEnabled
+}
+
+func (c *configImplementation) SetAdminTwoFactorRequired(v bool) {
+	c.adminTwoFactorRequired = v
+}
+
+func (c *configImplementation) GetAdminTwoFactorRequired() bool {
+	return c.adminTwoFactorRequired
+}
+
+func (c *configImplementation) SetSessionCountryLookupEnabled(v bool) {
+	c.sessionCountryLookupEnabled = v
+}
+
+func (c *configImplementation) GetSessionCountryLookupEnabled() bool {
+	return c.sessionCountryLookupEnabled
+}
+
 // ============================================================================
 // Database Config Implementation
 // ============================================================================
@@ -379,6 +462,7 @@ func (c *configImplementation) setDatabaseConfig(s databaseSettings) {
 	c.databaseMaxIdleConns = int(s.maxIdleConns)
 	c.databaseConnMaxLifetimeSeconds = int(s.connMaxLifetime.Seconds())
 	c.databaseConnMaxIdleTimeSeconds = int(s.connMaxIdleTime.Seconds())
+	c.databaseAutoMigrate = s.autoMigrate
 }
 
 // defaultConnection returns the default database connection configuration.
@@ -572,6 +656,14 @@ func (c *configImplementation) GetDatabasePrefix() string {
 	return c.databasePrefix
 }
 
+func (c *configImplementation) SetDatabaseAutoMigrate(v bool) {
+	c.databaseAutoMigrate = v
+}
+
+func (c *configImplementation) GetDatabaseAutoMigrate() bool {
+	return c.databaseAutoMigrate
+}
+
 // ============================================================================
 // Email Config Implementation
 // ============================================================================
@@ -934,16 +1026,297 @@ func (c *configImplementation) GetMediaUrl() string {
 	return c.mediaUrl
 }
 
+func (c *configImplementation) setMediaConfig(s mediaSettings) {
+	c.mediaCacheControl = s.cacheControl
+	c.mediaThumbCacheControl = s.thumbCacheControl
+	c.mediaPrivatePaths = s.privatePaths
+	c.mediaSigningKey = s.signingKey
+}
+
+func (c *configImplementation) SetMediaCacheControl(v string) {
+	c.mediaCacheControl = v
+}
+
+func (c *configImplementation) GetMediaCacheControl() string {
+	return c.mediaCacheControl
+}
+
+func (c *configImplementation) SetMediaThumbCacheControl(v string) {
+	c.mediaThumbCacheControl = v
+}
+
+func (c *configImplementation) GetMediaThumbCacheControl() string {
+	return c.mediaThumbCacheControl
+}
+
+func (c *configImplementation) SetMediaPrivatePaths(v []string) {
+	c.mediaPrivatePaths = v
+}
+
+func (c *configImplementation) GetMediaPrivatePaths() []string {
+	return c.mediaPrivatePaths
+}
+
+func (c *configImplementation) SetMediaSigningKey(v string) {
+	c.mediaSigningKey = v
+}
+
+func (c *configImplementation) GetMediaSigningKey() string {
+	return c.mediaSigningKey
+}
+
+// ============================================================================
+// API Config Implementation
+// ============================================================================
+
+func (c *configImplementation) setAPIConfig(s apiSettings) {
+	c.apiCorsAllowedOrigins = s.corsAllowedOrigins
+}
+
+func (c *configImplementation) SetAPICorsAllowedOrigins(origins []string) {
+	c.apiCorsAllowedOrigins = origins
+}
+
+func (c *configImplementation) GetAPICorsAllowedOrigins() []string {
+	return c.apiCorsAllowedOrigins
+}
+
+// ============================================================================
+// CSP Config Implementation
+// ============================================================================
+
+func (c *configImplementation) setCSPConfig(s cspSettings) {
+	c.cspScriptSources = s.scriptSources
+	c.cspStyleSources = s.styleSources
+	c.cspConnectSources = s.connectSources
+	c.cspFontSources = s.fontSources
+	c.cspImgSources = s.imgSources
+	c.cspReportOnly = s.reportOnly
+}
+
+func (c *configImplementation) SetCSPScriptSources(sources []string) {
+	c.cspScriptSources = sources
+}
+
+func (c *configImplementation) GetCSPScriptSources() []string {
+	return c.cspScriptSources
+}
+
+func (c *configImplementation) SetCSPStyleSources(sources []string) {
+	c.cspStyleSources = sources
+}
+
+func (c *configImplementation) GetCSPStyleSources() []string {
+	return c.cspStyleSources
+}
+
+func (c *configImplementation) SetCSPConnectSources(sources []string) {
+	c.cspConnectSources = sources
+}
+
+func (c *configImplementation) GetCSPConnectSources() []string {
+	return c.cspConnectSources
+}
+
+func (c *configImplementation) SetCSPFontSources(sources []string) {
+	c.cspFontSources = sources
+}
+
+func (c *configImplementation) GetCSPFontSources() []string {
+	return c.cspFontSources
+}
+
+func (c *configImplementation) SetCSPImgSources(sources []string) {
+	c.cspImgSources = sources
+}
+
+func (c *configImplementation) GetCSPImgSources() []string {
+	return c.cspImgSources
+}
+
+func (c *configImplementation) SetCSPReportOnly(reportOnly bool) {
+	c.cspReportOnly = reportOnly
+}
+
+func (c *configImplementation) GetCSPReportOnly() bool {
+	return c.cspReportOnly
+}
+
+// ============================================================================
+// Rate Limit Config Implementation
+// ============================================================================
+
+func (c *configImplementation) setRateLimitConfig(s rateLimitSettings) {
+	c.rateLimitStore = s.store
+	c.rateLimitPolicies = s.policies
+}
+
+func (c *configImplementation) SetRateLimitStore(store string) {
+	c.rateLimitStore = store
+}
+
+func (c *configImplementation) GetRateLimitStore() string {
+	return c.rateLimitStore
+}
+
+func (c *configImplementation) SetRateLimitPolicies(policies map[string][]ratelimit.Policy) {
+	c.rateLimitPolicies = policies
+}
+
+func (c *configImplementation) GetRateLimitPolicies() map[string][]ratelimit.Policy {
+	return c.rateLimitPolicies
+}
+
+// ============================================================================
+// OAuth Config Implementation
+// ============================================================================
+
+func (c *configImplementation) setOAuthConfig(s oauthSettings) {
+	c.oauthGoogleClientID = s.googleClientID
+	c.oauthGoogleClientSecret = s.googleClientSecret
+	c.oauthGitHubClientID = s.githubClientID
+	c.oauthGitHubClientSecret = s.githubClientSecret
+	c.oauthMicrosoftClientID = s.microsoftClientID
+	c.oauthMicrosoftClientSecret = s.microsoftClientSecret
+	c.oauthMicrosoftTenant = s.microsoftTenant
+	c.oauthOIDCName = s.oidcName
+	c.oauthOIDCIssuerURL = s.oidcIssuerURL
+	c.oauthOIDCClientID = s.oidcClientID
+	c.oauthOIDCClientSecret = s.oidcClientSecret
+}
+
+func (c *configImplementation) SetOAuthGoogleClientID(v string) {
+	c.oauthGoogleClientID = v
+}
+
+func (c *configImplementation) GetOAuthGoogleClientID() string {
+	return c.oauthGoogleClientID
+}
+
+func (c *configImplementation) SetOAuthGoogleClientSecret(v string) {
+	c.oauthGoogleClientSecret = v
+}
+
+func (c *configImplementation) GetOAuthGoogleClientSecret() string {
+	return c.oauthGoogleClientSecret
+}
+
+func (c *configImplementation) SetOAuthGitHubClientID(v string) {
+	c.oauthGitHubClientID = v
+}
+
+func (c *configImplementation) GetOAuthGitHubClientID() string {
+	return c.oauthGitHubClientID
+}
+
+func (c *configImplementation) SetOAuthGitHubClientSecret(v string) {
+	c.oauthGitHubClientSecret = v
+}
+
+func (c *configImplementation) GetOAuthGitHubClientSecret() string {
+	return c.oauthGitHubClientSecret
+}
+
+func (c *configImplementation) SetOAuthMicrosoftClientID(v string) {
+	c.oauthMicrosoftClientID = v
+}
+
+func (c *configImplementation) GetOAuthMicrosoftClientID() string {
+	return c.oauthMicrosoftClientID
+}
+
+func (c *configImplementation) SetOAuthMicrosoftClientSecret(v string) {
+	c.oauthMicrosoftClientSecret = v
+}
+
+func (c *configImplementation) GetOAuthMicrosoftClientSecret() string {
+	return c.oauthMicrosoftClientSecret
+}
+
+func (c *configImplementation) SetOAuthMicrosoftTenant(v string) {
+	c.oauthMicrosoftTenant = v
+}
+
+// GetOAuthMicrosoftTenant returns the Microsoft tenant,
+// falling back to the default tenant when not configured
+func (c *configImplementation) GetOAuthMicrosoftTenant() string {
+	if c.oauthMicrosoftTenant == "" {
+		return OAUTH_MICROSOFT_TENANT_DEFAULT
+	}
+	return c.oauthMicrosoftTenant
+}
+
+func (c *configImplementation) SetOAuthOIDCName(v string) {
+	c.oauthOIDCName = v
+}
+
+// GetOAuthOIDCName returns the name of the OpenID Connect provider,
+// falling back to the default name when not configured
+func (c *configImplementation) GetOAuthOIDCName() string {
+	if c.oauthOIDCName == "" {
+		return OAUTH_OIDC_NAME_DEFAULT
+	}
+	return c.oauthOIDCName
+}
+
+func (c *configImplementation) SetOAuthOIDCIssuerURL(v string) {
+	c.oauthOIDCIssuerURL = v
+}
+
+func (c *configImplementation) GetOAuthOIDCIssuerURL() string {
+	return c.oauthOIDCIssuerURL
+}
+
+func (c *configImplementation) SetOAuthOIDCClientID(v string) {
+	c.oauthOIDCClientID = v
+}
+
+func (c *configImplementation) GetOAuthOIDCClientID() string {
+	return c.oauthOIDCClientID
+}
+
+func (c *configImplementation) SetOAuthOIDCClientSecret(v string) {
+	c.oauthOIDCClientSecret = v
+}
+
+func (c *configImplementation) GetOAuthOIDCClientSecret() string {
+	return c.oauthOIDCClientSecret
+}
+
 // ============================================================================
 // Payment Config Implementation
 // ============================================================================
 
 func (c *configImplementation) setStripeConfig(s paymentSettings) {
+	c.paymentGateway = s.gateway
+	c.paymentCurrency = s.currency
 	c.stripeKeyPrivate = s.keyPrivate
 	c.stripeKeyPublic = s.keyPublic
+	c.stripeWebhookSecret = s.webhookSecret
 	c.stripeUsed = s.used
 }
 
+func (c *configImplementation) SetPaymentGateway(v string) {
+	c.paymentGateway = v
+}
+
+func (c *configImplementation) GetPaymentGateway() string {
+	return c.paymentGateway
+}
+
+func (c *configImplementation) SetPaymentCurrency(v string) {
+	c.paymentCurrency = v
+}
+
+// GetPaymentCurrency returns the ISO 4217 currency code,
+// falling back to the default currency when not configured
+func (c *configImplementation) GetPaymentCurrency() string {
+	if c.paymentCurrency == "" {
+		return PAYMENT_CURRENCY_DEFAULT
+	}
+	return c.paymentCurrency
+}
+
 func (c *configImplementation) SetStripeKeyPrivate(v string) {
 	c.stripeKeyPrivate = v
 }
@@ -960,6 +1333,14 @@ func (c *configImplementation) GetStripeKeyPublic() string {
 	return c.stripeKeyPublic
 }
 
+func (c *configImplementation) SetStripeWebhookSecret(v string) {
+	c.stripeWebhookSecret = v
+}
+
+func (c *configImplementation) GetStripeWebhookSecret() string {
+	return c.stripeWebhookSecret
+}
+
 func (c *configImplementation) SetStripeUsed(v bool) {
 	c.stripeUsed = v
 }
@@ -968,6 +1349,63 @@ func (c *configImplementation) GetStripeUsed() bool {
 	return c.stripeUsed
 }
 
+// ============================================================================
+// Shop Config Implementation
+// ============================================================================
+
+func (c *configImplementation) setShopConfig(s shopSettings) {
+	c.shopTaxRates = s.taxRates
+	c.shopShippingMethods = s.shippingMethods
+	c.shopPricesIncludeTax = s.pricesIncludeTax
+	c.shopStockTrackingEnabled = s.stockTrackingEnabled
+	c.shopStockReservationMinutes = s.stockReservationMinutes
+}
+
+func (c *configImplementation) SetShopTaxRates(v string) {
+	c.shopTaxRates = v
+}
+
+func (c *configImplementation) GetShopTaxRates() string {
+	return c.shopTaxRates
+}
+
+func (c *configImplementation) SetShopShippingMethods(v string) {
+	c.shopShippingMethods = v
+}
+
+func (c *configImplementation) GetShopShippingMethods() string {
+	return c.shopShippingMethods
+}
+
+func (c *configImplementation) SetShopPricesIncludeTax(v bool) {
+	c.shopPricesIncludeTax = v
+}
+
+func (c *configImplementation) GetShopPricesIncludeTax() bool {
+	return c.shopPricesIncludeTax
+}
+
+func (c *configImplementation) SetShopStockTrackingEnabled(v bool) {
+	c.shopStockTrackingEnabled = v
+}
+
+func (c *configImplementation) GetShopStockTrackingEnabled() bool {
+	return c.shopStockTrackingEnabled
+}
+
+func (c *configImplementation) SetShopStockReservationMinutes(v int) {
+	c.shopStockReservationMinutes = v
+}
+
+// GetShopStockReservationMinutes returns how long the stock is reserved,
+// falling back to the default when not configured
+func (c *configImplementation) GetShopStockReservationMinutes() int {
+	if c.shopStockReservationMinutes <= 0 {
+		return SHOP_STOCK_RESERVATION_MINUTES_DEFAULT
+	}
+	return c.shopStockReservationMinutes
+}
+
 // ============================================================================
 // SEO Config Implementation
 // ============================================================================
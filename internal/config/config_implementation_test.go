@@ -4,7 +4,11 @@ package config
 
 import (
 	"os"
+	"project/internal/ratelimit"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dracory/env"
 )
@@ -199,6 +203,7 @@ func TestLoad_StripeConfiguration(t *testing.T) {
 	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
 	mustSetenv(t, KEY_STRIPE_KEY_PRIVATE, "sk_test_123")
 	mustSetenv(t, KEY_STRIPE_KEY_PUBLIC, "pk_test_123")
+	mustSetenv(t, KEY_STRIPE_WEBHOOK_SECRET, "whsec_test_123")
 	if cmsStoreUsed {
 		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
 	}
@@ -219,6 +224,435 @@ func TestLoad_StripeConfiguration(t *testing.T) {
 	if cfg.GetStripeKeyPrivate() != "sk_test_123" {
 		t.Errorf("expected private key=sk_test_123, got %s", cfg.GetStripeKeyPrivate())
 	}
+
+	if cfg.GetStripeWebhookSecret() != "whsec_test_123" {
+		t.Errorf("expected webhook secret=whsec_test_123, got %s", cfg.GetStripeWebhookSecret())
+	}
+
+	if cfg.GetPaymentGateway() != PAYMENT_GATEWAY_STRIPE {
+		t.Errorf("expected payment gateway=%s, got %s", PAYMENT_GATEWAY_STRIPE, cfg.GetPaymentGateway())
+	}
+
+	if cfg.GetPaymentCurrency() != PAYMENT_CURRENCY_DEFAULT {
+		t.Errorf("expected payment currency=%s, got %s", PAYMENT_CURRENCY_DEFAULT, cfg.GetPaymentCurrency())
+	}
+}
+
+func TestLoad_APIConfiguration(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_API_CORS_ALLOWED_ORIGINS, " https://app.example.com/, ,https://admin.example.com")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	cfg, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	origins := cfg.GetAPICorsAllowedOrigins()
+
+	if len(origins) != 2 || origins[0] != "https://app.example.com" || origins[1] != "https://admin.example.com" {
+		t.Errorf("expected the two trimmed origins, got %v", origins)
+	}
+}
+
+func TestLoad_MediaConfiguration(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_MEDIA_CACHE_CONTROL, " private, max-age=60 ")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	cfg, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	if got := cfg.GetMediaCacheControl(); got != "private, max-age=60" {
+		t.Errorf("expected the trimmed cache control, got %q", got)
+	}
+
+	if got := cfg.GetMediaThumbCacheControl(); got != MEDIA_THUMB_CACHE_CONTROL_DEFAULT {
+		t.Errorf("expected the default thumbnail cache control, got %q", got)
+	}
+
+	if got := cfg.GetMediaPrivatePaths(); len(got) != 1 || got[0] != MEDIA_PRIVATE_PATHS_DEFAULT {
+		t.Errorf("expected the default private folder, got %v", got)
+	}
+
+	if got := cfg.GetMediaSigningKey(); got != "" {
+		t.Errorf("expected no signing key, got %q", got)
+	}
+}
+
+func TestLoad_MediaPrivatePaths(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_MEDIA_PRIVATE_PATHS, " /invoices , ,/downloads")
+	mustSetenv(t, KEY_MEDIA_SIGNING_KEY, "test-signing-key")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	cfg, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	if !slices.Equal(cfg.GetMediaPrivatePaths(), []string{"/invoices", "/downloads"}) {
+		t.Errorf("expected the two trimmed private folders, got %v", cfg.GetMediaPrivatePaths())
+	}
+
+	if got := cfg.GetMediaSigningKey(); got != "test-signing-key" {
+		t.Errorf("expected the signing key, got %q", got)
+	}
+}
+
+func TestLoad_DatabaseAutoMigrate(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	cfg, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	if !cfg.GetDatabaseAutoMigrate() {
+		t.Error("expected the migrations to run on startup by default")
+	}
+
+	mustSetenv(t, KEY_DB_AUTO_MIGRATE, "false")
+
+	cfg, err = NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	if cfg.GetDatabaseAutoMigrate() {
+		t.Error("expected the migrations not to run on startup")
+	}
+}
+
+func TestLoad_CSPConfiguration(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_CSP_SCRIPT_SRC, " https://cdn.example.com , https://js.stripe.com,")
+	mustSetenv(t, KEY_CSP_REPORT_ONLY, "yes")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	cfg, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	scriptSources := cfg.GetCSPScriptSources()
+
+	if len(scriptSources) != 2 || scriptSources[0] != "https://cdn.example.com" || scriptSources[1] != "https://js.stripe.com" {
+		t.Errorf("expected the two trimmed script sources, got %v", scriptSources)
+	}
+
+	if !slices.Equal(cfg.GetCSPStyleSources(), cspDefaultStyleSources()) {
+		t.Errorf("expected the default style sources, got %v", cfg.GetCSPStyleSources())
+	}
+
+	if !cfg.GetCSPReportOnly() {
+		t.Error("expected the report only mode")
+	}
+}
+
+func TestLoad_RateLimitConfiguration(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_RATE_LIMIT_STORE, "memory")
+	mustSetenv(t, KEY_RATE_LIMIT_AUTH, "3/1m, 20/1h")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	cfg, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	if cfg.GetRateLimitStore() != ratelimit.STORE_MEMORY {
+		t.Errorf("expected the memory store, got %q", cfg.GetRateLimitStore())
+	}
+
+	policies := cfg.GetRateLimitPolicies()
+
+	if len(policies[ratelimit.GROUP_AUTH]) != 2 || policies[ratelimit.GROUP_AUTH][0] != (ratelimit.Policy{Limit: 3, Window: time.Minute}) {
+		t.Errorf("expected the two auth policies, got %v", policies[ratelimit.GROUP_AUTH])
+	}
+
+	if _, ok := policies[ratelimit.GROUP_GLOBAL]; ok {
+		t.Error("expected the global group to use the defaults")
+	}
+}
+
+func TestLoad_RateLimitConfigurationInvalid(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_RATE_LIMIT_GLOBAL, "120 per minute")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	if _, err := NewFromEnv(); err == nil || !strings.Contains(err.Error(), KEY_RATE_LIMIT_GLOBAL) {
+		t.Fatalf("expected an error for the invalid policy, got %v", err)
+	}
+}
+
+func TestLoad_OAuthConfiguration(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_OAUTH_GITHUB_CLIENT_ID, "github-id")
+	mustSetenv(t, KEY_OAUTH_GITHUB_CLIENT_SECRET, "github-secret")
+	mustSetenv(t, KEY_OAUTH_OIDC_ISSUER_URL, "https://sso.example.com/")
+	mustSetenv(t, KEY_OAUTH_OIDC_CLIENT_ID, "oidc-id")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	cfg, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	if cfg.GetOAuthGitHubClientID() != "github-id" || cfg.GetOAuthGitHubClientSecret() != "github-secret" {
+		t.Errorf("expected the GitHub client, got %q, %q", cfg.GetOAuthGitHubClientID(), cfg.GetOAuthGitHubClientSecret())
+	}
+
+	if cfg.GetOAuthGoogleClientID() != "" {
+		t.Errorf("expected Google to be disabled, got client ID %q", cfg.GetOAuthGoogleClientID())
+	}
+
+	if cfg.GetOAuthOIDCIssuerURL() != "https://sso.example.com" {
+		t.Errorf("expected the issuer URL without trailing slash, got %s", cfg.GetOAuthOIDCIssuerURL())
+	}
+
+	if cfg.GetOAuthOIDCName() != OAUTH_OIDC_NAME_DEFAULT {
+		t.Errorf("expected OIDC name=%s, got %s", OAUTH_OIDC_NAME_DEFAULT, cfg.GetOAuthOIDCName())
+	}
+
+	if cfg.GetOAuthMicrosoftTenant() != OAUTH_MICROSOFT_TENANT_DEFAULT {
+		t.Errorf("expected Microsoft tenant=%s, got %s", OAUTH_MICROSOFT_TENANT_DEFAULT, cfg.GetOAuthMicrosoftTenant())
+	}
+}
+
+func TestLoad_OAuthClientSecretRequired(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_OAUTH_GOOGLE_CLIENT_ID, "google-id")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	_, err := NewFromEnv()
+	if err == nil {
+		t.Fatal("expected an error when the Google client secret is missing")
+	}
+
+	verr, ok := err.(env.ValidationError)
+	if !ok {
+		t.Fatalf("expected env.ValidationError, got %T", err)
+	}
+
+	found := false
+	for _, e := range verr.Errors() {
+		if merr, ok := e.(env.MissingEnvError); ok && merr.Key == KEY_OAUTH_GOOGLE_CLIENT_SECRET {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Error("expected validation error for missing OAUTH_GOOGLE_CLIENT_SECRET")
+	}
+}
+
+func TestLoad_PaymentGatewayFake(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_PAYMENT_GATEWAY, "fake")
+	mustSetenv(t, KEY_PAYMENT_CURRENCY, "eur")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	cfg, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	if cfg.GetPaymentGateway() != PAYMENT_GATEWAY_FAKE {
+		t.Errorf("expected payment gateway=%s, got %s", PAYMENT_GATEWAY_FAKE, cfg.GetPaymentGateway())
+	}
+
+	if cfg.GetPaymentCurrency() != "EUR" {
+		t.Errorf("expected payment currency=EUR, got %s", cfg.GetPaymentCurrency())
+	}
+}
+
+func TestLoad_PaymentGatewayInvalid(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_PAYMENT_GATEWAY, "unknown")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	if _, err := NewFromEnv(); err == nil {
+		t.Fatal("NewFromEnv() should fail with an unknown payment gateway")
+	}
+}
+
+func TestLoad_ShopConfiguration(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_SHOP_TAX_RATES, `[{"country":"GB","rate":"20"}]`)
+	mustSetenv(t, KEY_SHOP_SHIPPING_METHODS, `[{"code":"standard","name":"Standard","tiers":[{"price":"3.99"}]}]`)
+	mustSetenv(t, KEY_SHOP_PRICES_INCLUDE_TAX, "true")
+	mustSetenv(t, KEY_SHOP_STOCK_TRACKING_ENABLED, "true")
+	mustSetenv(t, KEY_SHOP_STOCK_RESERVATION_MINUTES, "15")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	cfg, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	if cfg.GetShopTaxRates() != `[{"country":"GB","rate":"20"}]` {
+		t.Errorf("unexpected tax rates %s", cfg.GetShopTaxRates())
+	}
+
+	if cfg.GetShopShippingMethods() == "" {
+		t.Error("expected the shipping methods to be loaded")
+	}
+
+	if !cfg.GetShopPricesIncludeTax() {
+		t.Error("expected prices to include tax")
+	}
+
+	if !cfg.GetShopStockTrackingEnabled() {
+		t.Error("expected stock tracking to be enabled")
+	}
+
+	if cfg.GetShopStockReservationMinutes() != 15 {
+		t.Errorf("expected stock reservation minutes=15, got %d", cfg.GetShopStockReservationMinutes())
+	}
+}
+
+func TestLoad_ShopConfigurationInvalidJSON(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_SHOP_TAX_RATES, `[{"country":"GB"`)
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	if _, err := NewFromEnv(); err == nil {
+		t.Fatal("NewFromEnv() should fail with invalid tax rates JSON")
+	}
 }
 
 func TestLoad_MailConfiguration(t *testing.T) {
@@ -427,6 +861,91 @@ func TestAuthRegistrationEnabled(t *testing.T) {
 	}
 }
 
+func TestAuthMagicLinkEnabled(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_AUTH_MAGIC_LINK_ENABLED, "true")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	cfg, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	if !cfg.GetMagicLinkEnabled() {
+		t.Error("GetMagicLinkEnabled() = false, want true")
+	}
+}
+
+func TestAuthAdminTwoFactorRequired(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	mustSetenv(t, KEY_AUTH_ADMIN_2FA_REQUIRED, "true")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	cfg, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	if !cfg.GetAdminTwoFactorRequired() {
+		t.Error("GetAdminTwoFactorRequired() = false, want true")
+	}
+}
+
+func TestAuthSessionCountryLookupEnabled(t *testing.T) {
+	mustSetenv(t, KEY_APP_HOST, "localhost")
+	mustSetenv(t, KEY_APP_PORT, "8080")
+	mustSetenv(t, KEY_APP_ENVIRONMENT, "testing")
+	mustSetenv(t, KEY_DB_DRIVER, "sqlite")
+	mustSetenv(t, KEY_DB_DATABASE, ":memory:")
+	if cmsStoreUsed {
+		mustSetenv(t, KEY_CMS_STORE_TEMPLATE_ID, "test-template")
+	}
+	if vaultStoreUsed {
+		mustSetenv(t, KEY_VAULT_STORE_KEY, "test-vault-key")
+	}
+	defer cleanupEnv()
+
+	cfg, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	if !cfg.GetSessionCountryLookupEnabled() {
+		t.Error("GetSessionCountryLookupEnabled() = false, want true by default")
+	}
+
+	mustSetenv(t, KEY_AUTH_SESSION_COUNTRY_LOOKUP_ENABLED, "false")
+
+	cfg, err = NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv() failed: %v", err)
+	}
+
+	if cfg.GetSessionCountryLookupEnabled() {
+		t.Error("GetSessionCountryLookupEnabled() = true, want false")
+	}
+}
+
 func TestAppDebugMode(t *testing.T) {
 	mustSetenv(t, KEY_APP_HOST, "localhost")
 	mustSetenv(t, KEY_APP_PORT, "8080")
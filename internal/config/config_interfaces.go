@@ -2,6 +2,8 @@
 
 package config
 
+import "project/internal/ratelimit"
+
 // ============================================================================
 // Main Config Interface
 // ============================================================================
@@ -10,16 +12,21 @@ package config
 // It composes all domain-specific configuration interfaces.
 type ConfigInterface interface {
 	// App-specific settings
+	APIConfigInterface
 	AppConfigInterface
 	AuthConfigInterface
+	CSPConfigInterface
 	DatabaseConfigInterface
 	EmailConfigInterface
 	EncryptionConfigInterface
 	I18nConfigInterface
 	LLMConfigInterface
 	MediaConfigInterface
+	OAuthConfigInterface
 	PaymentConfigInterface
+	RateLimitConfigInterface
 	SEOConfigInterface
+	ShopConfigInterface
 
 	// CMS MCP
 	SetCmsMcpApiKey(string)
@@ -107,6 +114,15 @@ type AuthConfigInterface interface {
 
 	SetPasswordAuthEnabled(bool)
 	GetPasswordAuthEnabled() bool
+
+	SetMagicLinkEnabled(bool)
+	GetMagicLinkEnabled() bool
+
+	SetAdminTwoFactorRequired(bool)
+	GetAdminTwoFactorRequired() bool
+
+	SetSessionCountryLookupEnabled(bool)
+	GetSessionCountryLookupEnabled() bool
 }
 
 // ============================================================================
@@ -184,6 +200,9 @@ type DatabaseConfigInterface interface {
 	SetDatabasePrefix(string)
 	GetDatabasePrefix() string
 
+	SetDatabaseAutoMigrate(bool)
+	GetDatabaseAutoMigrate() bool
+
 	SetDatabaseDefaultConnection(string)
 	GetDatabaseDefaultConnection() string
 
@@ -326,6 +345,119 @@ type MediaConfigInterface interface {
 
 	SetMediaUrl(string)
 	GetMediaUrl() string
+
+	// SetMediaCacheControl sets the Cache-Control header of the files and
+	// media served by the application
+	SetMediaCacheControl(string)
+	GetMediaCacheControl() string
+
+	// SetMediaThumbCacheControl sets the Cache-Control header of the
+	// thumbnails
+	SetMediaThumbCacheControl(string)
+	GetMediaThumbCacheControl() string
+
+	// SetMediaPrivatePaths sets the folders of the private files (e.g.
+	// "/private"), only served with a signed URL
+	SetMediaPrivatePaths([]string)
+	GetMediaPrivatePaths() []string
+
+	// SetMediaSigningKey sets the key signing the URLs of the private
+	// files. Without it, the private files are not served
+	SetMediaSigningKey(string)
+	GetMediaSigningKey() string
+}
+
+// ============================================================================
+// API Config Interface
+// ============================================================================
+
+// APIConfigInterface defines the REST API configuration methods.
+type APIConfigInterface interface {
+	SetAPICorsAllowedOrigins([]string)
+	GetAPICorsAllowedOrigins() []string
+}
+
+// ============================================================================
+// CSP Config Interface
+// ============================================================================
+
+// CSPConfigInterface defines the Content Security Policy configuration
+// methods. The sources are allowed besides the application itself.
+type CSPConfigInterface interface {
+	SetCSPScriptSources([]string)
+	GetCSPScriptSources() []string
+
+	SetCSPStyleSources([]string)
+	GetCSPStyleSources() []string
+
+	SetCSPConnectSources([]string)
+	GetCSPConnectSources() []string
+
+	SetCSPFontSources([]string)
+	GetCSPFontSources() []string
+
+	SetCSPImgSources([]string)
+	GetCSPImgSources() []string
+
+	// SetCSPReportOnly sets whether the violations are only reported,
+	// not blocked
+	SetCSPReportOnly(bool)
+	GetCSPReportOnly() bool
+}
+
+// ============================================================================
+// Rate Limit Config Interface
+// ============================================================================
+
+// RateLimitConfigInterface defines the rate limiting configuration methods.
+type RateLimitConfigInterface interface {
+	SetRateLimitStore(string)
+	GetRateLimitStore() string
+
+	// SetRateLimitPolicies sets the policies of the groups of routes
+	// (see the ratelimit package). The groups not set use the defaults.
+	SetRateLimitPolicies(map[string][]ratelimit.Policy)
+	GetRateLimitPolicies() map[string][]ratelimit.Policy
+}
+
+// ============================================================================
+// OAuth Config Interface
+// ============================================================================
+
+// OAuthConfigInterface defines the social login provider configuration methods.
+type OAuthConfigInterface interface {
+	SetOAuthGoogleClientID(string)
+	GetOAuthGoogleClientID() string
+
+	SetOAuthGoogleClientSecret(string)
+	GetOAuthGoogleClientSecret() string
+
+	SetOAuthGitHubClientID(string)
+	GetOAuthGitHubClientID() string
+
+	SetOAuthGitHubClientSecret(string)
+	GetOAuthGitHubClientSecret() string
+
+	SetOAuthMicrosoftClientID(string)
+	GetOAuthMicrosoftClientID() string
+
+	SetOAuthMicrosoftClientSecret(string)
+	GetOAuthMicrosoftClientSecret() string
+
+	SetOAuthMicrosoftTenant(string)
+	GetOAuthMicrosoftTenant() string
+
+	SetOAuthOIDCName(string)
+	GetOAuthOIDCName() string
+
+	SetOAuthOIDCIssuerURL(string)
+	GetOAuthOIDCIssuerURL() string
+
+	SetOAuthOIDCClientID(string)
+	GetOAuthOIDCClientID() string
+
+	SetOAuthOIDCClientSecret(string)
+	GetOAuthOIDCClientSecret() string
 }
 
 // ============================================================================
@@ -334,16 +466,47 @@ type MediaConfigInterface interface {
 
 // PaymentConfigInterface defines payment provider configuration methods.
 type PaymentConfigInterface interface {
+	SetPaymentGateway(string)
+	GetPaymentGateway() string
+
+	SetPaymentCurrency(string)
+	GetPaymentCurrency() string
+
 	SetStripeKeyPrivate(string)
 	GetStripeKeyPrivate() string
 
 	SetStripeKeyPublic(string)
 	GetStripeKeyPublic() string
 
+	SetStripeWebhookSecret(string)
+	GetStripeWebhookSecret() string
+
 	SetStripeUsed(bool)
 	GetStripeUsed() bool
 }
 
+// ============================================================================
+// Shop Config Interface
+// ============================================================================
+
+// ShopConfigInterface defines the shop pricing (tax and shipping) and inventory configuration methods.
+type ShopConfigInterface interface {
+	SetShopTaxRates(string)
+	GetShopTaxRates() string
+
+	SetShopShippingMethods(string)
+	GetShopShippingMethods() string
+
+	SetShopPricesIncludeTax(bool)
+	GetShopPricesIncludeTax() bool
+
+	SetShopStockTrackingEnabled(bool)
+	GetShopStockTrackingEnabled() bool
+
+	SetShopStockReservationMinutes(int)
+	GetShopStockReservationMinutes() int
+}
+
 // ============================================================================
 // SEO Config Interface
 // ============================================================================
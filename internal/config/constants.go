@@ -15,8 +15,17 @@ type AuthenticatedSessionContextKey struct{}
 // APIAuthenticatedUserContextKey is a context key for API authenticated user.
 type APIAuthenticatedUserContextKey struct{}
 
-// APIAuthenticatedSessionContextKey is a context key for API authenticated session.
-type APIAuthenticatedSessionContextKey struct{}
+// APIAuthenticatedTokenContextKey is a context key for the personal API token
+// the API request is authenticated with.
+type APIAuthenticatedTokenContextKey struct{}
+
+// MCPAuthenticatedClientContextKey is a context key for the MCP client the
+// MCP request is authenticated with.
+type MCPAuthenticatedClientContextKey struct{}
+
+// CSPNonceContextKey is a context key for the Content Security Policy nonce
+// of the request.
+type CSPNonceContextKey struct{}
 
 // ============================================================================
 // == END: Types
@@ -78,6 +87,7 @@ const KEY_DB_MAX_OPEN_CONNS = "DB_MAX_OPEN_CONNS"
 const KEY_DB_MAX_IDLE_CONNS = "DB_MAX_IDLE_CONNS"
 const KEY_DB_CONN_MAX_LIFETIME_SECONDS = "DB_CONN_MAX_LIFETIME_SECONDS"
 const KEY_DB_CONN_MAX_IDLE_TIME_SECONDS = "DB_CONN_MAX_IDLE_TIME_SECONDS"
+const KEY_DB_AUTO_MIGRATE = "DB_AUTO_MIGRATE"
 
 // ============================================================================
 // == END: Database Configurations
@@ -107,6 +117,9 @@ const KEY_AUTH_REGISTRATION_ENABLED = "AUTH_REGISTRATION_ENABLED"
 const KEY_AUTH_EMAILS_ALLOWED_ACCESS = "AUTH_EMAILS_ALLOWED_ACCESS"
 const KEY_AUTH_CSRF_SECRET = "AUTH_CSRF_SECRET" // #nosec G101 -- this is an env var key name, not a credential
 const KEY_AUTH_PASSWORD_AUTH_ENABLED = "AUTH_PASSWORD_AUTH_ENABLED"
+const KEY_AUTH_MAGIC_LINK_ENABLED = "AUTH_MAGIC_LINK_ENABLED"
+const KEY_AUTH_ADMIN_2FA_REQUIRED = "AUTH_ADMIN_2FA_REQUIRED"
+const KEY_AUTH_SESSION_COUNTRY_LOOKUP_ENABLED = "AUTH_SESSION_COUNTRY_LOOKUP_ENABLED"
 
 // ============================================================================
 // == END: Auth Configurations
@@ -159,13 +172,167 @@ const KEY_VERTEX_AI_API_DEFAULT_MODEL = "VERTEX_AI_API_DEFAULT_MODEL"
 //
 // ============================================================================
 
+const KEY_PAYMENT_GATEWAY = "PAYMENT_GATEWAY"
+const KEY_PAYMENT_CURRENCY = "PAYMENT_CURRENCY"
 const KEY_STRIPE_KEY_PRIVATE = "STRIPE_KEY_PRIVATE"
 const KEY_STRIPE_KEY_PUBLIC = "STRIPE_KEY_PUBLIC"
+const KEY_STRIPE_WEBHOOK_SECRET = "STRIPE_WEBHOOK_SECRET"
+
+const PAYMENT_GATEWAY_STRIPE = "stripe"
+const PAYMENT_GATEWAY_FAKE = "fake"
+const PAYMENT_CURRENCY_DEFAULT = "GBP"
 
 // ============================================================================
 // == END: Payment Configurations
 // ============================================================================
 
+// ============================================================================
+// == START: Media Configurations
+// ============================================================================
+//
+// This is where you can configure how the files and media (/files, /file,
+// /media) and their thumbnails (/th) are served.
+//
+// ============================================================================
+
+const KEY_MEDIA_CACHE_CONTROL = "MEDIA_CACHE_CONTROL"
+const KEY_MEDIA_THUMB_CACHE_CONTROL = "MEDIA_THUMB_CACHE_CONTROL"
+const KEY_MEDIA_PRIVATE_PATHS = "MEDIA_PRIVATE_PATHS"
+const KEY_MEDIA_SIGNING_KEY = "MEDIA_SIGNING_KEY"
+
+// MEDIA_CACHE_CONTROL_DEFAULT is the Cache-Control header of the files and
+// media, revalidated with their ETag after a day
+const MEDIA_CACHE_CONTROL_DEFAULT = "public, max-age=86400"
+
+// MEDIA_THUMB_CACHE_CONTROL_DEFAULT is the Cache-Control header of the
+// thumbnails, cached for 7 days (for SEO)
+const MEDIA_THUMB_CACHE_CONTROL_DEFAULT = "max-age=604800"
+
+// MEDIA_PRIVATE_PATHS_DEFAULT is the folder of the private files, only
+// served with a signed URL
+const MEDIA_PRIVATE_PATHS_DEFAULT = "/private"
+
+// MEDIA_PRIVATE_CACHE_CONTROL is the Cache-Control header of the private
+// files, which must not be kept by the shared caches
+const MEDIA_PRIVATE_CACHE_CONTROL = "private, no-store"
+
+// ============================================================================
+// == END: Media Configurations
+// ============================================================================
+
+// ============================================================================
+// == START: API Configurations
+// ============================================================================
+//
+// This is where you can configure the REST API (/api/v1).
+//
+// ============================================================================
+
+const KEY_API_CORS_ALLOWED_ORIGINS = "API_CORS_ALLOWED_ORIGINS"
+
+// ============================================================================
+// == END: API Configurations
+// ============================================================================
+
+// ============================================================================
+// == START: Content Security Policy Configurations
+// ============================================================================
+//
+// This is where you can configure the sources allowed by the Content
+// Security Policy header.
+//
+// ============================================================================
+
+const KEY_CSP_SCRIPT_SRC = "CSP_SCRIPT_SRC"
+const KEY_CSP_STYLE_SRC = "CSP_STYLE_SRC"
+const KEY_CSP_CONNECT_SRC = "CSP_CONNECT_SRC"
+const KEY_CSP_FONT_SRC = "CSP_FONT_SRC"
+const KEY_CSP_IMG_SRC = "CSP_IMG_SRC"
+const KEY_CSP_REPORT_ONLY = "CSP_REPORT_ONLY"
+
+// ============================================================================
+// == END: Content Security Policy Configurations
+// ============================================================================
+
+// ============================================================================
+// == START: Rate Limit Configurations
+// ============================================================================
+//
+// This is where you can configure the rate limits of the groups of routes.
+//
+// ============================================================================
+
+const KEY_RATE_LIMIT_STORE = "RATE_LIMIT_STORE"
+const KEY_RATE_LIMIT_GLOBAL = "RATE_LIMIT_GLOBAL"
+const KEY_RATE_LIMIT_API = "RATE_LIMIT_API"
+const KEY_RATE_LIMIT_AUTH = "RATE_LIMIT_AUTH"
+const KEY_RATE_LIMIT_CONTACT = "RATE_LIMIT_CONTACT"
+const KEY_RATE_LIMIT_MCP = "RATE_LIMIT_MCP"
+const KEY_RATE_LIMIT_REGISTER = "RATE_LIMIT_REGISTER"
+const KEY_RATE_LIMIT_THUMBS = "RATE_LIMIT_THUMBS"
+
+// ============================================================================
+// == END: Rate Limit Configurations
+// ============================================================================
+
+// ============================================================================
+// == START: OAuth Configurations
+// ============================================================================
+//
+// This is where you can configure the social login providers.
+// A provider is offered on the login page when its client ID is set.
+//
+// ============================================================================
+
+const KEY_OAUTH_GOOGLE_CLIENT_ID = "OAUTH_GOOGLE_CLIENT_ID"
+const KEY_OAUTH_GOOGLE_CLIENT_SECRET = "OAUTH_GOOGLE_CLIENT_SECRET" // #nosec G101 -- this is an env var key name, not a credential
+const KEY_OAUTH_GITHUB_CLIENT_ID = "OAUTH_GITHUB_CLIENT_ID"
+const KEY_OAUTH_GITHUB_CLIENT_SECRET = "OAUTH_GITHUB_CLIENT_SECRET" // #nosec G101 -- this is an env var key name, not a credential
+const KEY_OAUTH_MICROSOFT_CLIENT_ID = "OAUTH_MICROSOFT_CLIENT_ID"
+const KEY_OAUTH_MICROSOFT_CLIENT_SECRET = "OAUTH_MICROSOFT_CLIENT_SECRET" // #nosec G101 -- this is an env var key name, not a credential
+const KEY_OAUTH_MICROSOFT_TENANT = "OAUTH_MICROSOFT_TENANT"
+const KEY_OAUTH_OIDC_NAME = "OAUTH_OIDC_NAME"
+const KEY_OAUTH_OIDC_ISSUER_URL = "OAUTH_OIDC_ISSUER_URL"
+const KEY_OAUTH_OIDC_CLIENT_ID = "OAUTH_OIDC_CLIENT_ID"
+const KEY_OAUTH_OIDC_CLIENT_SECRET = "OAUTH_OIDC_CLIENT_SECRET" // #nosec G101 -- this is an env var key name, not a credential
+
+const OAUTH_MICROSOFT_TENANT_DEFAULT = "common"
+const OAUTH_OIDC_NAME_DEFAULT = "Single Sign-On"
+
+// ============================================================================
+// == END: OAuth Configurations
+// ============================================================================
+
+// ============================================================================
+// == START: Shop Configurations
+// ============================================================================
+//
+// This is where you can configure the shop taxes, shipping and inventory.
+//
+// ============================================================================
+
+const KEY_SHOP_TAX_RATES = "SHOP_TAX_RATES"
+const KEY_SHOP_SHIPPING_METHODS = "SHOP_SHIPPING_METHODS"
+const KEY_SHOP_PRICES_INCLUDE_TAX = "SHOP_PRICES_INCLUDE_TAX"
+const KEY_SHOP_STOCK_TRACKING_ENABLED = "SHOP_STOCK_TRACKING_ENABLED"
+const KEY_SHOP_STOCK_RESERVATION_MINUTES = "SHOP_STOCK_RESERVATION_MINUTES"
+
+// SHOP_STOCK_RESERVATION_MINUTES_DEFAULT is how long the stock is reserved
+// for an order awaiting payment, when not configured.
+const SHOP_STOCK_RESERVATION_MINUTES_DEFAULT = 60
+
+// PRODUCT_META_WEIGHT is the metadata key for the weight of a product in grams,
+// used to price the shipping.
+const PRODUCT_META_WEIGHT = "weight"
+
+// PRODUCT_META_CATEGORY_ID is the metadata key for the ID of the category
+// the product is listed under in the storefront.
+const PRODUCT_META_CATEGORY_ID = "category_id"
+
+// ============================================================================
+// == END: Shop Configurations
+// ============================================================================
+
 // ============================================================================
 // == START: i18n Configurations
 // ============================================================================
@@ -209,6 +376,58 @@ const KEY_VAULT_STORE_KEY = "VAULT_STORE_KEY"
 // USER_META_CART is the metadata key for storing the user's shopping cart.
 const USER_META_CART = "cart"
 
+// USER_META_EMAIL_VERIFIED_AT is the metadata key for the time the user
+// verified their email address, required for the password login.
+const USER_META_EMAIL_VERIFIED_AT = "email_verified_at"
+
+// USER_META_TWO_FACTOR_SECRET is the metadata key for the vault token of
+// the TOTP secret of the user, set when two-factor authentication is on.
+const USER_META_TWO_FACTOR_SECRET = "two_factor_secret"
+
+// USER_META_TWO_FACTOR_RECOVERY_CODES is the metadata key for the bcrypt
+// hashes (JSON array) of the unused two-factor recovery codes of the user.
+const USER_META_TWO_FACTOR_RECOVERY_CODES = "two_factor_recovery_codes"
+
+// USER_META_TWO_FACTOR_LAST_STEP is the metadata key for the TOTP time
+// step of the last accepted code, for display only (see ext.UserTwoFactorVerify).
+const USER_META_TWO_FACTOR_LAST_STEP = "two_factor_last_step"
+
+// ORDER_META_PAYMENT_KEY is the metadata key linking an order to its payment code data.
+const ORDER_META_PAYMENT_KEY = "payment_key"
+
+// ORDER_META_PAYMENT_SESSION_ID is the metadata key for the payment provider checkout session.
+const ORDER_META_PAYMENT_SESSION_ID = "payment_session_id"
+
+// ORDER_META_DISCOUNT_ID is the metadata key for the discount applied to an order.
+const ORDER_META_DISCOUNT_ID = "discount_id"
+
+// ORDER_META_DISCOUNT_CODE is the metadata key for the discount code applied to an order.
+const ORDER_META_DISCOUNT_CODE = "discount_code"
+
+// ORDER_META_DISCOUNT_AMOUNT is the metadata key for the discount amount deducted from an order.
+const ORDER_META_DISCOUNT_AMOUNT = "discount_amount"
+
+// ORDER_META_SUBTOTAL is the metadata key for the order value before the discount.
+const ORDER_META_SUBTOTAL = "subtotal"
+
+// ORDER_META_SHIPPING_METHOD is the metadata key for the shipping method of an order.
+const ORDER_META_SHIPPING_METHOD = "shipping_method"
+
+// ORDER_META_SHIPPING_AMOUNT is the metadata key for the shipping charged for an order.
+const ORDER_META_SHIPPING_AMOUNT = "shipping_amount"
+
+// ORDER_META_SHIPPING_COUNTRY is the metadata key for the country an order is shipped to.
+const ORDER_META_SHIPPING_COUNTRY = "shipping_country"
+
+// ORDER_META_SHIPPING_REGION is the metadata key for the region an order is shipped to.
+const ORDER_META_SHIPPING_REGION = "shipping_region"
+
+// ORDER_META_TAX_AMOUNT is the metadata key for the tax of an order.
+const ORDER_META_TAX_AMOUNT = "tax_amount"
+
+// ORDER_META_PRICE_BREAKDOWN is the metadata key for the JSON price breakdown of an order.
+const ORDER_META_PRICE_BREAKDOWN = "price_breakdown"
+
 // ============================================================================
 // == END: Store Configurations
 // ============================================================================
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"strings"
+)
+
+// cspConfig reads the Content Security Policy settings from environment
+// variables
+func cspConfig(env *envValidator) cspSettings {
+	// CSP Sources
+	//
+	// The sources (e.g. https://cdn.jsdelivr.net) allowed besides the
+	// application itself ('self'), comma separated. When not set, the CDNs
+	// used by the application are allowed.
+	//
+	// The inline scripts are only allowed with the nonce of the request,
+	// so 'unsafe-inline' in CSP_SCRIPT_SRC has no effect.
+	// The inline styles are allowed by default ('unsafe-inline' in
+	// CSP_STYLE_SRC), as the bundled SweetAlert2 and TinyMCE inject
+	// theirs. Remove it to only allow the styles with the nonce.
+	scriptSources := cspSources(env.GetString(KEY_CSP_SCRIPT_SRC), cspDefaultScriptSources())
+	styleSources := cspSources(env.GetString(KEY_CSP_STYLE_SRC), cspDefaultStyleSources())
+	connectSources := cspSources(env.GetString(KEY_CSP_CONNECT_SRC), cspDefaultConnectSources())
+	fontSources := cspSources(env.GetString(KEY_CSP_FONT_SRC), cspDefaultFontSources())
+	imgSources := cspSources(env.GetString(KEY_CSP_IMG_SRC), cspDefaultImgSources())
+
+	// CSP Report Only
+	//
+	// When enabled, the violations are only reported, not blocked.
+	// Useful to try a stricter policy before enforcing it
+	reportOnly := env.GetBool(KEY_CSP_REPORT_ONLY)
+
+	return cspSettings{
+		scriptSources:  scriptSources,
+		styleSources:   styleSources,
+		connectSources: connectSources,
+		fontSources:    fontSources,
+		imgSources:     imgSources,
+		reportOnly:     reportOnly,
+	}
+}
+
+type cspSettings struct {
+	scriptSources  []string
+	styleSources   []string
+	connectSources []string
+	fontSources    []string
+	imgSources     []string
+	reportOnly     bool
+}
+
+// cspSources splits the comma separated sources, or returns the defaults
+// when none are set
+func cspSources(value string, defaults []string) []string {
+	sources := []string{}
+
+	for _, source := range strings.Split(value, ",") {
+		source = strings.TrimSpace(source)
+
+		if source != "" {
+			sources = append(sources, source)
+		}
+	}
+
+	if len(sources) == 0 {
+		return defaults
+	}
+
+	return sources
+}
+
+func cspDefaultScriptSources() []string {
+	return []string{
+		"https://cdn.jsdelivr.net",
+		"http://cdn.jsdelivr.net",
+		"https://unpkg.com",
+		"https://code.jquery.com",
+		"https://cdn.datatables.net",
+		"https://cdnjs.cloudflare.com",
+		"http://cdnjs.cloudflare.com",
+		"https://www.googletagmanager.com",
+		"https://www.statcounter.com",
+		"https://cdn.tiny.cloud",
+	}
+}
+
+func cspDefaultStyleSources() []string {
+	return []string{
+		"'unsafe-inline'",
+		"https://cdn.jsdelivr.net",
+		"https://maxcdn.bootstrapcdn.com",
+		"https://cdnjs.cloudflare.com",
+		"http://cdnjs.cloudflare.com",
+		"https://fonts.googleapis.com",
+		"https://unpkg.com",
+		"https://code.jquery.com",
+		"https://cdn.datatables.net",
+	}
+}
+
+func cspDefaultConnectSources() []string {
+	return []string{
+		"https://cdnjs.cloudflare.com",
+		"http://cdnjs.cloudflare.com",
+		"https://www.statcounter.com",
+	}
+}
+
+func cspDefaultFontSources() []string {
+	return []string{
+		"https://cdn.jsdelivr.net",
+		"https://fonts.googleapis.com",
+		"https://fonts.gstatic.com",
+		"https://cdnjs.cloudflare.com",
+		"http://cdnjs.cloudflare.com",
+		"https://maxcdn.bootstrapcdn.com",
+	}
+}
+
+func cspDefaultImgSources() []string {
+	return []string{
+		"data:",
+		"https://sfs.ams3.digitaloceanspaces.com",
+		"https://lesichkov.ams3.digitaloceanspaces.com",
+		"https://provedexpert.gitlab.io",
+		"https://picsum.photos",
+		"https://fastly.picsum.photos",
+	}
+}
@@ -129,6 +129,13 @@ func databaseConfig(env *envValidator) databaseSettings {
 		sslMode = ""
 	}
 
+	// Auto Migrate
+	//
+	// Controls whether the pending migrations are run on startup.
+	// When disabled, run them with the "migrate up" command instead.
+	// Default: true
+	autoMigrate := env.GetBoolOrDefault(KEY_DB_AUTO_MIGRATE, true)
+
 	if driver != driverSQLite && driver != driverTurso {
 		env.RequireWhen(true, KEY_DB_HOST, "required when `DB_DRIVER` is not sqlite or turso", host)
 		env.RequireWhen(true, KEY_DB_PORT, "required when `DB_DRIVER` is not sqlite or turso", port)
@@ -173,6 +180,7 @@ func databaseConfig(env *envValidator) databaseSettings {
 		timezone:          timezone,
 		dsn:               dsn,
 		prefix:            prefix,
+		autoMigrate:       autoMigrate,
 	}
 }
 
@@ -213,6 +221,7 @@ type databaseSettings struct {
 	timezone          string
 	dsn               string
 	prefix            string
+	autoMigrate       bool
 }
 
 // GetName returns the connection name.
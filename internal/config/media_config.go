@@ -0,0 +1,47 @@
+package config
+
+import "strings"
+
+// mediaConfig reads the settings of serving the files and media from
+// environment variables
+func mediaConfig(env *envValidator) mediaSettings {
+	// Cache-Control
+	//
+	// The Cache-Control header of the files and media (/files, /file,
+	// /media), and of their thumbnails (/th). The files are served with
+	// an ETag and a Last-Modified date, so the browsers revalidate them
+	// cheaply once expired (304 Not Modified)
+	cacheControl := strings.TrimSpace(env.GetStringOrDefault(KEY_MEDIA_CACHE_CONTROL, MEDIA_CACHE_CONTROL_DEFAULT))
+	thumbCacheControl := strings.TrimSpace(env.GetStringOrDefault(KEY_MEDIA_THUMB_CACHE_CONTROL, MEDIA_THUMB_CACHE_CONTROL_DEFAULT))
+
+	// Private Files
+	//
+	// The folders (e.g. /private,/invoices) whose files are only served
+	// with a URL signed with the signing key, comma separated. Without the
+	// signing key the private files are not served at all
+	privatePaths := []string{}
+
+	for _, privatePath := range strings.Split(env.GetStringOrDefault(KEY_MEDIA_PRIVATE_PATHS, MEDIA_PRIVATE_PATHS_DEFAULT), ",") {
+		privatePath = strings.TrimSpace(privatePath)
+
+		if privatePath != "" {
+			privatePaths = append(privatePaths, privatePath)
+		}
+	}
+
+	signingKey := env.GetString(KEY_MEDIA_SIGNING_KEY)
+
+	return mediaSettings{
+		cacheControl:      cacheControl,
+		thumbCacheControl: thumbCacheControl,
+		privatePaths:      privatePaths,
+		signingKey:        signingKey,
+	}
+}
+
+type mediaSettings struct {
+	cacheControl      string
+	thumbCacheControl string
+	privatePaths      []string
+	signingKey        string
+}
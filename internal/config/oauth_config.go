@@ -0,0 +1,85 @@
+package config
+
+import "strings"
+
+// oauthConfig reads the social login providers from environment variables.
+// A provider is enabled when its client ID is set.
+func oauthConfig(env *envValidator) oauthSettings {
+	// Google
+	//
+	// OAuth client of a Google Cloud project.
+	// Create it at: https://console.cloud.google.com/apis/credentials
+	// Authorized redirect URI: APP_URL + /auth/oauth/callback
+	googleClientID := env.GetString(KEY_OAUTH_GOOGLE_CLIENT_ID)
+	googleClientSecret := env.GetString(KEY_OAUTH_GOOGLE_CLIENT_SECRET)
+
+	// GitHub
+	//
+	// OAuth app of a GitHub account or organization.
+	// Create it at: https://github.com/settings/developers
+	// Authorization callback URL: APP_URL + /auth/oauth/callback
+	githubClientID := env.GetString(KEY_OAUTH_GITHUB_CLIENT_ID)
+	githubClientSecret := env.GetString(KEY_OAUTH_GITHUB_CLIENT_SECRET)
+
+	// Microsoft
+	//
+	// App registration in Microsoft Entra ID.
+	// Create it at: https://entra.microsoft.com (App registrations)
+	// Redirect URI (Web): APP_URL + /auth/oauth/callback
+	// The tenant is "common" for any Microsoft account, "consumers" for
+	// personal accounts only, or the ID of your organization's tenant
+	microsoftClientID := env.GetString(KEY_OAUTH_MICROSOFT_CLIENT_ID)
+	microsoftClientSecret := env.GetString(KEY_OAUTH_MICROSOFT_CLIENT_SECRET)
+	microsoftTenant := env.GetStringOrDefault(KEY_OAUTH_MICROSOFT_TENANT, OAUTH_MICROSOFT_TENANT_DEFAULT)
+
+	// Generic OpenID Connect
+	//
+	// Any OpenID Connect provider with discovery (e.g. Keycloak, Okta,
+	// Auth0, Authentik). The issuer URL is the URL the discovery document
+	// /.well-known/openid-configuration is served under.
+	// The name is shown on the login button
+	oidcName := env.GetStringOrDefault(KEY_OAUTH_OIDC_NAME, OAUTH_OIDC_NAME_DEFAULT)
+	oidcIssuerURL := strings.TrimSuffix(env.GetString(KEY_OAUTH_OIDC_ISSUER_URL), "/")
+	oidcClientID := env.GetString(KEY_OAUTH_OIDC_CLIENT_ID)
+	oidcClientSecret := env.GetString(KEY_OAUTH_OIDC_CLIENT_SECRET)
+
+	env.RequireWhen(googleClientID != "", KEY_OAUTH_GOOGLE_CLIENT_SECRET,
+		"required when `OAUTH_GOOGLE_CLIENT_ID` is set", googleClientSecret)
+
+	env.RequireWhen(githubClientID != "", KEY_OAUTH_GITHUB_CLIENT_SECRET,
+		"required when `OAUTH_GITHUB_CLIENT_ID` is set", githubClientSecret)
+
+	env.RequireWhen(microsoftClientID != "", KEY_OAUTH_MICROSOFT_CLIENT_SECRET,
+		"required when `OAUTH_MICROSOFT_CLIENT_ID` is set", microsoftClientSecret)
+
+	env.RequireWhen(oidcClientID != "", KEY_OAUTH_OIDC_ISSUER_URL,
+		"required when `OAUTH_OIDC_CLIENT_ID` is set", oidcIssuerURL)
+
+	return oauthSettings{
+		googleClientID:        googleClientID,
+		googleClientSecret:    googleClientSecret,
+		githubClientID:        githubClientID,
+		githubClientSecret:    githubClientSecret,
+		microsoftClientID:     microsoftClientID,
+		microsoftClientSecret: microsoftClientSecret,
+		microsoftTenant:       microsoftTenant,
+		oidcName:              oidcName,
+		oidcIssuerURL:         oidcIssuerURL,
+		oidcClientID:          oidcClientID,
+		oidcClientSecret:      oidcClientSecret,
+	}
+}
+
+type oauthSettings struct {
+	googleClientID        string
+	googleClientSecret    string
+	githubClientID        string
+	githubClientSecret    string
+	microsoftClientID     string
+	microsoftClientSecret string
+	microsoftTenant       string
+	oidcName              string
+	oidcIssuerURL         string
+	oidcClientID          string
+	oidcClientSecret      string
+}
@@ -1,10 +1,14 @@
 package config
 
-import "github.com/dracory/env"
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
 
-// paymentConfig reads Stripe payment configuration from environment variables.
+// paymentConfig reads the payment configuration from environment variables.
 // Stripe is automatically enabled when both keys are provided.
-func paymentConfig() paymentSettings {
+func paymentConfig(env *envValidator, isProduction bool) paymentSettings {
 	// Stripe Private Key
 	//
 	// Your Stripe secret key, used for server-side API calls.
@@ -19,15 +23,63 @@ func paymentConfig() paymentSettings {
 	// Example: pk_live_... (production) or pk_test_... (testing)
 	keyPublic := env.GetString(KEY_STRIPE_KEY_PUBLIC)
 
+	// Stripe Webhook Signing Secret
+	//
+	// The signing secret of your webhook endpoint, used to verify that
+	// the events sent to /api/internal/webhook really come from Stripe.
+	// Find it at: https://dashboard.stripe.com/webhooks
+	// Example: whsec_...
+	webhookSecret := env.GetString(KEY_STRIPE_WEBHOOK_SECRET)
+
+	stripeUsed := keyPrivate != "" && keyPublic != ""
+
+	// Payment Gateway
+	//
+	// The gateway used to take payments, one of:
+	// - stripe: Stripe Checkout (requires the Stripe keys)
+	// - fake: in-memory gateway accepting every payment, for local development
+	// Defaults to stripe when the Stripe keys are provided.
+	gateway := strings.ToLower(env.GetString(KEY_PAYMENT_GATEWAY))
+	if gateway == "" && stripeUsed {
+		gateway = PAYMENT_GATEWAY_STRIPE
+	}
+
+	// Payment Currency
+	//
+	// ISO 4217 code of the currency the prices are charged in.
+	// Example: GBP, EUR, USD
+	currency := strings.ToUpper(env.GetStringOrDefault(KEY_PAYMENT_CURRENCY, PAYMENT_CURRENCY_DEFAULT))
+
+	if gateway != "" && !slices.Contains([]string{PAYMENT_GATEWAY_STRIPE, PAYMENT_GATEWAY_FAKE}, gateway) {
+		env.Add(fmt.Errorf("%s must be one of %q or %q, got %q", KEY_PAYMENT_GATEWAY, PAYMENT_GATEWAY_STRIPE, PAYMENT_GATEWAY_FAKE, gateway))
+	}
+
+	if gateway == PAYMENT_GATEWAY_FAKE && isProduction {
+		env.Add(fmt.Errorf("%s %q cannot be used in production", KEY_PAYMENT_GATEWAY, PAYMENT_GATEWAY_FAKE))
+	}
+
+	env.RequireWhen(gateway == PAYMENT_GATEWAY_STRIPE, KEY_STRIPE_KEY_PRIVATE,
+		"required when `PAYMENT_GATEWAY` is stripe", keyPrivate)
+
+	if len(currency) != 3 {
+		env.Add(fmt.Errorf("%s must be a 3 letter ISO 4217 code, got %q", KEY_PAYMENT_CURRENCY, currency))
+	}
+
 	return paymentSettings{
-		keyPrivate: keyPrivate,
-		keyPublic:  keyPublic,
-		used:       keyPrivate != "" && keyPublic != "",
+		gateway:       gateway,
+		currency:      currency,
+		keyPrivate:    keyPrivate,
+		keyPublic:     keyPublic,
+		webhookSecret: webhookSecret,
+		used:          stripeUsed,
 	}
 }
 
 type paymentSettings struct {
-	keyPrivate string
-	keyPublic  string
-	used       bool
+	gateway       string
+	currency      string
+	keyPrivate    string
+	keyPublic     string
+	webhookSecret string
+	used          bool
 }
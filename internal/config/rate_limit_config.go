@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"project/internal/ratelimit"
+	"slices"
+	"strings"
+)
+
+// rateLimitConfig reads the rate limiting settings from environment
+// variables
+func rateLimitConfig(env *envValidator) rateLimitSettings {
+	// Rate Limit Store
+	//
+	// Where the requests are counted, one of:
+	// - cachestore: the cache store table, shared by all the instances
+	// - memory: the memory of the process, lost on restart
+	// Defaults to cachestore when the cache store is used.
+	store := strings.ToLower(env.GetString(KEY_RATE_LIMIT_STORE))
+	if store == "" {
+		store = ratelimit.STORE_MEMORY
+
+		if cacheStoreUsed {
+			store = ratelimit.STORE_CACHESTORE
+		}
+	}
+
+	if !slices.Contains([]string{ratelimit.STORE_CACHESTORE, ratelimit.STORE_MEMORY}, store) {
+		env.Add(fmt.Errorf("%s must be one of %q or %q, got %q", KEY_RATE_LIMIT_STORE, ratelimit.STORE_CACHESTORE, ratelimit.STORE_MEMORY, store))
+	}
+
+	if store == ratelimit.STORE_CACHESTORE && !cacheStoreUsed {
+		env.Add(fmt.Errorf("%s %q requires the cache store to be used", KEY_RATE_LIMIT_STORE, store))
+	}
+
+	// Rate Limit Policies
+	//
+	// The policies of a group of routes, as comma separated limit/window
+	// pairs. Example: 20/1s,120/1m,12000/1h
+	// The groups which are not set use the default policies.
+	groupKeys := map[string]string{
+		ratelimit.GROUP_GLOBAL:   KEY_RATE_LIMIT_GLOBAL,
+		ratelimit.GROUP_API:      KEY_RATE_LIMIT_API,
+		ratelimit.GROUP_AUTH:     KEY_RATE_LIMIT_AUTH,
+		ratelimit.GROUP_CONTACT:  KEY_RATE_LIMIT_CONTACT,
+		ratelimit.GROUP_MCP:      KEY_RATE_LIMIT_MCP,
+		ratelimit.GROUP_REGISTER: KEY_RATE_LIMIT_REGISTER,
+		ratelimit.GROUP_THUMBS:   KEY_RATE_LIMIT_THUMBS,
+	}
+
+	policies := map[string][]ratelimit.Policy{}
+
+	for group, key := range groupKeys {
+		value := env.GetString(key)
+
+		if value == "" {
+			continue
+		}
+
+		groupPolicies, err := ratelimit.ParsePolicies(value)
+		if err != nil {
+			env.Add(fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+
+		policies[group] = groupPolicies
+	}
+
+	return rateLimitSettings{
+		store:    store,
+		policies: policies,
+	}
+}
+
+type rateLimitSettings struct {
+	store    string
+	policies map[string][]ratelimit.Policy
+}
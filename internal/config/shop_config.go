@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// shopConfig reads the shop pricing and inventory configuration from
+// environment variables. The pricing rules are validated in detail when
+// the pricing calculator is built.
+func shopConfig(env *envValidator) shopSettings {
+	// Shop Tax Rates
+	//
+	// JSON list of tax rates per country, optionally per region.
+	// The region rate is used when defined, otherwise the country rate.
+	// No tax is added when the buyer's country has no rate.
+	// Example: [{"country":"GB","rate":"20","label":"VAT"},{"country":"US","region":"CA","rate":"7.25"}]
+	taxRates := strings.TrimSpace(env.GetString(KEY_SHOP_TAX_RATES))
+
+	// Shop Shipping Methods
+	//
+	// JSON list of shipping methods, each with price tiers by basket weight
+	// (grams, see the product "weight" meta) and / or minimum basket value.
+	// The first matching tier sets the price. Shipping is free when empty.
+	// Example: [{"code":"standard","name":"Standard","countries":["GB"],"tiers":[{"min_subtotal":"50.00","price":"0"},{"max_weight":2000,"price":"3.99"},{"price":"7.99"}]}]
+	shippingMethods := strings.TrimSpace(env.GetString(KEY_SHOP_SHIPPING_METHODS))
+
+	// Shop Prices Include Tax
+	//
+	// Set to true when the product prices already include the tax (e.g. VAT
+	// in the UK and EU). The tax is then shown, but not added to the total.
+	pricesIncludeTax := env.GetBool(KEY_SHOP_PRICES_INCLUDE_TAX)
+
+	// Shop Stock Tracking
+	//
+	// Set to true to track the stock of the products, which is their quantity
+	// in the shop store. Products out of stock cannot be added to the cart,
+	// and the ordered quantities are reserved while the buyer is paying.
+	// Requires the custom store, which holds the reservations.
+	stockTrackingEnabled := env.GetBool(KEY_SHOP_STOCK_TRACKING_ENABLED)
+
+	// Shop Stock Reservation Minutes
+	//
+	// How long the stock is reserved for an order awaiting payment. The
+	// reservations of unpaid orders are then released by a scheduled task.
+	// Default: 60
+	stockReservationMinutes := env.GetIntOrDefault(KEY_SHOP_STOCK_RESERVATION_MINUTES, SHOP_STOCK_RESERVATION_MINUTES_DEFAULT)
+
+	if taxRates != "" && !json.Valid([]byte(taxRates)) {
+		env.Add(fmt.Errorf("%s must be valid JSON", KEY_SHOP_TAX_RATES))
+	}
+
+	if shippingMethods != "" && !json.Valid([]byte(shippingMethods)) {
+		env.Add(fmt.Errorf("%s must be valid JSON", KEY_SHOP_SHIPPING_METHODS))
+	}
+
+	if stockReservationMinutes <= 0 {
+		env.Add(fmt.Errorf("%s must be a positive number of minutes", KEY_SHOP_STOCK_RESERVATION_MINUTES))
+	}
+
+	return shopSettings{
+		taxRates:                taxRates,
+		shippingMethods:         shippingMethods,
+		pricesIncludeTax:        pricesIncludeTax,
+		stockTrackingEnabled:    stockTrackingEnabled,
+		stockReservationMinutes: stockReservationMinutes,
+	}
+}
+
+type shopSettings struct {
+	taxRates                string
+	shippingMethods         string
+	pricesIncludeTax        bool
+	stockTrackingEnabled    bool
+	stockReservationMinutes int
+}
@@ -0,0 +1,173 @@
+package apitokens
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/apitoken"
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"strings"
+	"time"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dromara/carbon/v2"
+)
+
+// Actions supported by the API tokens controller
+const (
+	ACTION_REVOKE = "revoke"
+)
+
+// == CONTROLLER ===============================================================
+
+// apiTokensController lists the personal API tokens of all the users, and
+// lets the administrators revoke them
+type apiTokensController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR ==============================================================
+
+// NewAPITokensController creates a new API tokens controller
+func NewAPITokensController(app app.AppInterface) *apiTokensController {
+	return &apiTokensController{app: app}
+}
+
+// == PUBLIC METHODS ===========================================================
+
+// Handler lists the tokens, and processes the revoke form
+func (controller *apiTokensController) Handler(w http.ResponseWriter, r *http.Request) string {
+	listURL := links.Admin().APITokens()
+
+	if controller.app.GetCustomStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "API tokens require the custom store to be enabled", links.Admin().Home(), 10)
+	}
+
+	tokenStore, err := apitoken.NewStore(controller.app.GetCustomStore())
+	if err != nil {
+		controller.app.GetLogger().Error("At apiTokensController > Handler", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "API tokens are currently unavailable", links.Admin().Home(), 10)
+	}
+
+	if r.Method == http.MethodPost {
+		if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", listURL, 10)
+		}
+
+		if req.GetStringTrimmed(r, "action") == ACTION_REVOKE {
+			return controller.postRevoke(w, r, tokenStore)
+		}
+
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Unknown action", listURL, 10)
+	}
+
+	return controller.layout(r, controller.listView(tokenStore))
+}
+
+// == PRIVATE METHODS ==========================================================
+
+// postRevoke revokes a token, which stops working at once
+func (controller *apiTokensController) postRevoke(w http.ResponseWriter, r *http.Request, tokenStore apitoken.StoreInterface) string {
+	listURL := links.Admin().APITokens()
+
+	token, err := tokenStore.TokenFindByID(req.GetStringTrimmed(r, "token_id"))
+	if err != nil {
+		controller.app.GetLogger().Error("At apiTokensController > postRevoke > TokenFindByID", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "API token could not be loaded", listURL, 10)
+	}
+
+	if token == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "API token not found", listURL, 10)
+	}
+
+	if err := ext.APITokenRevoke(controller.app, token); err != nil {
+		controller.app.GetLogger().Error("At apiTokensController > postRevoke > APITokenRevoke", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "API token could not be revoked", listURL, 10)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "API token "+token.Name+" revoked", listURL, 5)
+}
+
+// layout wraps the content in the admin layout
+func (controller *apiTokensController) layout(r *http.Request, content hb.TagInterface) string {
+	title := "API Tokens"
+
+	breadcrumbs := layouts.Breadcrumbs([]layouts.Breadcrumb{
+		{Name: "Home", URL: links.Admin().Home()},
+		{Name: "API Tokens", URL: links.Admin().APITokens()},
+	})
+
+	return layouts.NewAdminLayout(controller.app, r, layouts.Options{
+		Title:   title,
+		Content: layouts.AdminPage(breadcrumbs, hb.Heading1().Text(title), content),
+	}).ToHTML()
+}
+
+// listView shows the tokens of all the users, newest first
+func (controller *apiTokensController) listView(tokenStore apitoken.StoreInterface) hb.TagInterface {
+	tokens, err := tokenStore.TokenList()
+	if err != nil {
+		controller.app.GetLogger().Error("At apiTokensController > listView", slog.String("error", err.Error()))
+		return hb.Div().Class("alert alert-danger").Text("API tokens could not be loaded")
+	}
+
+	if len(tokens) == 0 {
+		return hb.Div().Class("alert alert-info").Text("There are no API tokens yet")
+	}
+
+	csrfToken := csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret())
+	now := time.Now().UTC()
+
+	tbody := hb.TBody()
+
+	for _, token := range tokens {
+		status := token.Status(now)
+
+		formRevoke := hb.Form().
+			Class("d-inline").
+			Method(http.MethodPost).
+			Action(links.Admin().APITokens()).
+			Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value(ACTION_REVOKE)).
+			Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("token_id").Value(token.ID)).
+			Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrfToken)).
+			Child(hb.Button().Type(hb.TYPE_SUBMIT).Class("btn btn-sm btn-outline-danger").Text("Revoke"))
+
+		tbody.Child(hb.TR().
+			Child(hb.TD().Child(hb.Strong().Text(token.Name)).Child(hb.Div().Class("small text-muted font-monospace").Text("…" + token.Hint))).
+			Child(hb.TD().Class("font-monospace small").Text(token.UserID)).
+			Child(hb.TD().Text(strings.Join(token.Scopes, ", "))).
+			Child(hb.TD().Text(formatTime(token.CreatedAt, "-"))).
+			Child(hb.TD().Text(formatTime(token.ExpiresAt, "Never"))).
+			Child(hb.TD().Text(formatTime(token.LastUsedAt, "Never"))).
+			Child(hb.TD().Text(status)).
+			Child(hb.TD().Class("text-end").ChildIf(status == apitoken.TOKEN_STATUS_ACTIVE, formRevoke)))
+	}
+
+	return hb.Table().
+		Class("table table-striped align-middle").
+		Child(hb.Thead().Child(hb.TR().
+			Child(hb.TH().Text("Name")).
+			Child(hb.TH().Text("User")).
+			Child(hb.TH().Text("Scopes")).
+			Child(hb.TH().Text("Created")).
+			Child(hb.TH().Text("Expires")).
+			Child(hb.TH().Text("Last Used")).
+			Child(hb.TH().Text("Status")).
+			Child(hb.TH()))).
+		Child(tbody)
+}
+
+// formatTime formats the time of a token, or returns the text if the time
+// is not set
+func formatTime(t time.Time, zeroText string) string {
+	if t.IsZero() {
+		return zeroText
+	}
+
+	return carbon.CreateFromStdTime(t).ToDateTimeString(carbon.UTC)
+}
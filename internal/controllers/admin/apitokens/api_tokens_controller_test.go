@@ -0,0 +1,138 @@
+package apitokens
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"project/internal/apitoken"
+	"project/internal/app"
+	"project/internal/testutils"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/test"
+)
+
+func setupAPITokensApp(t *testing.T) (app.AppInterface, apitoken.StoreInterface) {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetCsrfSecret("test-csrf-secret")
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	tokenStore, err := apitoken.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app, tokenStore
+}
+
+func TestAPITokensController_RequiresCustomStore(t *testing.T) {
+	app := testutils.Setup(testutils.WithCacheStore(true))
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewAPITokensController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" {
+		t.Fatalf("expected an error flash message, got %+v", flashMessage)
+	}
+}
+
+func TestAPITokensController_List(t *testing.T) {
+	app, tokenStore := setupAPITokensApp(t)
+
+	token := apitoken.Token{UserID: test.USER_01, Name: "Deploy script", Hash: apitoken.Hash("token"), Hint: "abcd", Scopes: []string{apitoken.SCOPE_BLOG_READ}}
+	if err := tokenStore.TokenCreate(&token); err != nil {
+		t.Fatal(err)
+	}
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewAPITokensController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Deploy script", test.USER_01, apitoken.SCOPE_BLOG_READ, "abcd", "Revoke"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected the list to contain %q", expected)
+		}
+	}
+}
+
+func TestAPITokensController_Revoke(t *testing.T) {
+	app, tokenStore := setupAPITokensApp(t)
+
+	token := apitoken.Token{UserID: test.USER_01, Name: "Deploy script", Hash: apitoken.Hash("token")}
+	if err := tokenStore.TokenCreate(&token); err != nil {
+		t.Fatal(err)
+	}
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewAPITokensController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":     {ACTION_REVOKE},
+			"token_id":   {token.ID},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected a success flash message, got %+v", flashMessage)
+	}
+
+	revoked, err := tokenStore.TokenFindByID(token.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked == nil || !revoked.IsRevoked() {
+		t.Fatal("expected the token to be revoked")
+	}
+}
+
+func TestAPITokensController_InvalidCsrf(t *testing.T) {
+	app, tokenStore := setupAPITokensApp(t)
+
+	token := apitoken.Token{UserID: test.USER_01, Name: "Deploy script", Hash: apitoken.Hash("token")}
+	if err := tokenStore.TokenCreate(&token); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := test.CallStringEndpoint(http.MethodPost, NewAPITokensController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":     {ACTION_REVOKE},
+			"token_id":   {token.ID},
+			"csrf_token": {"invalid"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	notRevoked, err := tokenStore.TokenFindByID(token.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if notRevoked == nil || notRevoked.IsRevoked() {
+		t.Fatal("expected the token not to be revoked without a valid csrf token")
+	}
+}
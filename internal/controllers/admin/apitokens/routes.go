@@ -0,0 +1,25 @@
+package apitokens
+
+import (
+	"errors"
+	"project/internal/app"
+	"project/internal/links"
+
+	"github.com/dracory/rtr"
+)
+
+// Routes are the routes of the personal API tokens manager
+func Routes(app app.AppInterface) ([]rtr.RouteInterface, error) {
+	if app == nil {
+		return nil, errors.New("app cannot be nil")
+	}
+
+	apiTokens := rtr.NewRoute().
+		SetName("Admin > API Tokens").
+		SetPath(links.ADMIN_API_TOKENS).
+		SetHTMLHandler(NewAPITokensController(app).Handler)
+
+	return []rtr.RouteInterface{
+		apiTokens,
+	}, nil
+}
@@ -6,8 +6,11 @@ import (
 	"project/internal/controllers/admin/adapters"
 	"project/internal/helpers"
 	"project/internal/links"
+	"project/internal/searchindex"
+	"project/internal/tasks/search_reindex"
 
 	blogadmin "github.com/dracory/blogadmin"
+	"github.com/dracory/req"
 )
 
 // blogAdminController wraps the external github.com/dracory/blogadmin package
@@ -56,4 +59,15 @@ func (controller *blogAdminController) Handler(w http.ResponseWriter, r *http.Re
 	}
 
 	admin.Handle(w, r)
+
+	// The changes are posted, keep the search results in sync with them:
+	// only the post of the post_id, else (e.g. for a new post) all of them
+	if r.Method != http.MethodGet {
+		postIDs := []string{}
+		if postID := req.GetStringTrimmed(r, "post_id"); postID != "" {
+			postIDs = append(postIDs, postID)
+		}
+
+		search_reindex.Changed(r.Context(), controller.app, searchindex.DOCUMENT_TYPE_POST, postIDs...)
+	}
 }
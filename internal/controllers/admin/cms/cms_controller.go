@@ -5,11 +5,14 @@ import (
 	"project/internal/app"
 	"project/internal/layouts"
 	"project/internal/links"
+	"project/internal/searchindex"
+	"project/internal/tasks/search_reindex"
 
 	"github.com/dracory/base/webtheme"
 
 	adminCmsStore "github.com/dracory/cmsstore/admin"
 	"github.com/dracory/hb"
+	"github.com/dracory/req"
 )
 
 type cmsNewController struct {
@@ -62,4 +65,15 @@ func (controller *cmsNewController) Handler(w http.ResponseWriter, r *http.Reque
 	}
 
 	admin.Handle(w, r)
+
+	// The changes are posted, keep the search results in sync with them:
+	// only the page of the page_id, else (e.g. for a new page) all of them
+	if r.Method != http.MethodGet {
+		pageIDs := []string{}
+		if pageID := req.GetStringTrimmed(r, "page_id"); pageID != "" {
+			pageIDs = append(pageIDs, pageID)
+		}
+
+		search_reindex.Changed(r.Context(), controller.app, searchindex.DOCUMENT_TYPE_PAGE, pageIDs...)
+	}
 }
@@ -174,6 +174,30 @@ func (c *homeController) tiles() []hb.TagInterface {
 		"link":  links.Admin().Shop(map[string]string{}),
 	}
 
+	discountTile := map[string]string{
+		"title": "Discount Codes",
+		"icon":  "bi-percent",
+		"link":  links.Admin().ShopDiscounts(map[string]string{}),
+	}
+
+	apiTokensTile := map[string]string{
+		"title": "API Tokens",
+		"icon":  "bi-key",
+		"link":  links.Admin().APITokens(map[string]string{}),
+	}
+
+	lockoutsTile := map[string]string{
+		"title": "Lockouts",
+		"icon":  "bi-shield-lock",
+		"link":  links.Admin().Lockouts(map[string]string{}),
+	}
+
+	mcpClientsTile := map[string]string{
+		"title": "MCP Clients",
+		"icon":  "bi-robot",
+		"link":  links.Admin().MCPClients(map[string]string{}),
+	}
+
 	// faqTile := map[string]string{
 	// 	"title": "FAQ Manager",
 	// 	"icon":  "bi-question-circle",
@@ -234,6 +258,19 @@ func (c *homeController) tiles() []hb.TagInterface {
 		tiles = append(tiles, shopTile)
 	}
 
+	if c.app.GetConfig().GetShopStoreUsed() && c.app.GetConfig().GetCustomStoreUsed() {
+		tiles = append(tiles, discountTile)
+	}
+
+	if c.app.GetConfig().GetUserStoreUsed() && c.app.GetConfig().GetCustomStoreUsed() {
+		tiles = append(tiles, apiTokensTile)
+		tiles = append(tiles, lockoutsTile)
+	}
+
+	if c.app.GetConfig().GetCustomStoreUsed() {
+		tiles = append(tiles, mcpClientsTile)
+	}
+
 	if c.app.GetConfig().GetSqlFileStoreUsed() {
 		tiles = append(tiles, fileManagerTile)
 	}
@@ -0,0 +1,205 @@
+package lockouts
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"project/internal/lockout"
+	"strconv"
+	"time"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dromara/carbon/v2"
+)
+
+// Actions supported by the lockouts controller
+const (
+	ACTION_UNLOCK = "unlock"
+)
+
+// == CONTROLLER ===============================================================
+
+// lockoutsController lists the accounts and IP addresses locked out after
+// too many failed login attempts, and lets the administrators unlock them
+type lockoutsController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR ==============================================================
+
+// NewLockoutsController creates a new lockouts controller
+func NewLockoutsController(app app.AppInterface) *lockoutsController {
+	return &lockoutsController{app: app}
+}
+
+// == PUBLIC METHODS ===========================================================
+
+// Handler lists the lockouts, and processes the unlock form
+func (controller *lockoutsController) Handler(w http.ResponseWriter, r *http.Request) string {
+	listURL := links.Admin().Lockouts()
+
+	if controller.app.GetCustomStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Lockouts require the custom store to be enabled", links.Admin().Home(), 10)
+	}
+
+	attemptStore, err := lockout.NewStore(controller.app.GetCustomStore())
+	if err != nil {
+		controller.app.GetLogger().Error("At lockoutsController > Handler", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Lockouts are currently unavailable", links.Admin().Home(), 10)
+	}
+
+	if r.Method == http.MethodPost {
+		if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", listURL, 10)
+		}
+
+		if req.GetStringTrimmed(r, "action") == ACTION_UNLOCK {
+			return controller.postUnlock(w, r, attemptStore)
+		}
+
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Unknown action", listURL, 10)
+	}
+
+	return controller.layout(r, controller.listView(r, attemptStore))
+}
+
+// == PRIVATE METHODS ==========================================================
+
+// postUnlock unlocks an account or IP address, which can log in again at
+// once
+func (controller *lockoutsController) postUnlock(w http.ResponseWriter, r *http.Request, attemptStore lockout.StoreInterface) string {
+	listURL := links.Admin().Lockouts()
+
+	attempt, err := attemptStore.AttemptFindByID(req.GetStringTrimmed(r, "lockout_id"))
+	if err != nil {
+		controller.app.GetLogger().Error("At lockoutsController > postUnlock > AttemptFindByID", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Lockout could not be loaded", listURL, 10)
+	}
+
+	if attempt == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Lockout not found", listURL, 10)
+	}
+
+	if err := ext.AuthLockoutUnlock(r.Context(), controller.app, attempt, helpers.GetAuthUser(r)); err != nil {
+		controller.app.GetLogger().Error("At lockoutsController > postUnlock > AuthLockoutUnlock", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Lockout could not be lifted", listURL, 10)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "Unlocked "+attempt.Key, listURL, 5)
+}
+
+// layout wraps the content in the admin layout
+func (controller *lockoutsController) layout(r *http.Request, content hb.TagInterface) string {
+	title := "Lockouts"
+
+	breadcrumbs := layouts.Breadcrumbs([]layouts.Breadcrumb{
+		{Name: "Home", URL: links.Admin().Home()},
+		{Name: "Lockouts", URL: links.Admin().Lockouts()},
+	})
+
+	return layouts.NewAdminLayout(controller.app, r, layouts.Options{
+		Title:   title,
+		Content: layouts.AdminPage(breadcrumbs, hb.Heading1().Text(title), content),
+	}).ToHTML()
+}
+
+// listView shows the accounts and IP addresses which are locked out now,
+// the latest failure first
+func (controller *lockoutsController) listView(r *http.Request, attemptStore lockout.StoreInterface) hb.TagInterface {
+	attempts, err := attemptStore.AttemptList()
+	if err != nil {
+		controller.app.GetLogger().Error("At lockoutsController > listView", slog.String("error", err.Error()))
+		return hb.Div().Class("alert alert-danger").Text("Lockouts could not be loaded")
+	}
+
+	now := time.Now().UTC()
+	csrfToken := csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret())
+
+	tbody := hb.TBody()
+	locked := 0
+
+	for _, attempt := range attempts {
+		if !attempt.IsLocked(now) {
+			continue
+		}
+
+		locked++
+
+		formUnlock := hb.Form().
+			Class("d-inline").
+			Method(http.MethodPost).
+			Action(links.Admin().Lockouts()).
+			Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value(ACTION_UNLOCK)).
+			Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("lockout_id").Value(attempt.ID)).
+			Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrfToken)).
+			Child(hb.Button().Type(hb.TYPE_SUBMIT).Class("btn btn-sm btn-outline-primary").Text("Unlock"))
+
+		tbody.Child(hb.TR().
+			Child(hb.TD().Text(subjectName(attempt.Subject))).
+			Child(hb.TD().Child(controller.subjectView(r, attempt))).
+			Child(hb.TD().Text(formatTime(attempt.LastFailureAt))).
+			Child(hb.TD().Text(formatTime(attempt.LockedUntil))).
+			Child(hb.TD().Text(strconv.Itoa(attempt.Lockouts))).
+			Child(hb.TD().Class("text-end").Child(formUnlock)))
+	}
+
+	if locked == 0 {
+		return hb.Div().Class("alert alert-info").Text("There are no locked accounts or IP addresses")
+	}
+
+	return hb.Table().
+		Class("table table-striped align-middle").
+		Child(hb.Thead().Child(hb.TR().
+			Child(hb.TH().Text("Type")).
+			Child(hb.TH().Text("Account / IP Address")).
+			Child(hb.TH().Text("Last Failure")).
+			Child(hb.TH().Text("Locked Until")).
+			Child(hb.TH().Text("Lockouts")).
+			Child(hb.TH()))).
+		Child(tbody)
+}
+
+// subjectView shows the IP address, or the name and ID of the user
+func (controller *lockoutsController) subjectView(r *http.Request, attempt lockout.Attempt) hb.TagInterface {
+	if attempt.Subject != lockout.SUBJECT_ACCOUNT || controller.app.GetUserStore() == nil {
+		return hb.Span().Class("font-monospace").Text(attempt.Key)
+	}
+
+	user, err := controller.app.GetUserStore().UserFindByID(r.Context(), attempt.Key)
+	if err != nil {
+		controller.app.GetLogger().Error("At lockoutsController > subjectView > UserFindByID", slog.String("error", err.Error()))
+	}
+
+	name := "Deleted user"
+	if user != nil {
+		name = ext.DisplayNameFull(user)
+	}
+
+	return hb.Div().
+		Child(hb.Strong().Text(name)).
+		Child(hb.Div().Class("small text-muted font-monospace").Text(attempt.Key))
+}
+
+// subjectName returns the name of the subject shown to the administrators
+func subjectName(subject string) string {
+	if subject == lockout.SUBJECT_IP {
+		return "IP Address"
+	}
+
+	return "Account"
+}
+
+// formatTime formats the time, or returns "-" if the time is not set
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return carbon.CreateFromStdTime(t).ToDateTimeString(carbon.UTC)
+}
@@ -0,0 +1,167 @@
+package lockouts
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"project/internal/app"
+	"project/internal/lockout"
+	"project/internal/testutils"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/test"
+)
+
+func setupLockoutsApp(t *testing.T) (app.AppInterface, lockout.StoreInterface) {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetCsrfSecret("test-csrf-secret")
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	attemptStore, err := lockout.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app, attemptStore
+}
+
+// seedLockout saves an IP address locked out for an hour
+func seedLockout(t *testing.T, attemptStore lockout.StoreInterface, ip string) *lockout.Attempt {
+	t.Helper()
+
+	now := time.Now().UTC()
+	attempt := &lockout.Attempt{Subject: lockout.SUBJECT_IP, Key: ip, Lockouts: 1, LastFailureAt: now, LockedUntil: now.Add(time.Hour)}
+
+	if err := attemptStore.AttemptSave(attempt); err != nil {
+		t.Fatal(err)
+	}
+
+	return attempt
+}
+
+func TestLockoutsController_RequiresCustomStore(t *testing.T) {
+	app := testutils.Setup(testutils.WithCacheStore(true))
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewLockoutsController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" {
+		t.Fatalf("expected an error flash message, got %+v", flashMessage)
+	}
+}
+
+func TestLockoutsController_List(t *testing.T) {
+	app, attemptStore := setupLockoutsApp(t)
+
+	seedLockout(t, attemptStore, "10.0.0.1")
+
+	// Failures without a lockout are not listed
+	notLocked := &lockout.Attempt{Subject: lockout.SUBJECT_IP, Key: "10.0.0.2", Failures: 2, LastFailureAt: time.Now().UTC()}
+	if err := attemptStore.AttemptSave(notLocked); err != nil {
+		t.Fatal(err)
+	}
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewLockoutsController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"10.0.0.1", "IP Address", "Unlock"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected the list to contain %q", expected)
+		}
+	}
+
+	if strings.Contains(body, "10.0.0.2") {
+		t.Error("expected the IP address which is not locked not to be listed")
+	}
+}
+
+func TestLockoutsController_Empty(t *testing.T) {
+	app, _ := setupLockoutsApp(t)
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewLockoutsController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "There are no locked accounts or IP addresses") {
+		t.Fatal("expected the empty list message")
+	}
+}
+
+func TestLockoutsController_Unlock(t *testing.T) {
+	app, attemptStore := setupLockoutsApp(t)
+
+	attempt := seedLockout(t, attemptStore, "10.0.0.1")
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewLockoutsController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":     {ACTION_UNLOCK},
+			"lockout_id": {attempt.ID},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected a success flash message, got %+v", flashMessage)
+	}
+
+	unlocked, err := attemptStore.AttemptFindByID(attempt.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if unlocked == nil || unlocked.IsLocked(time.Now().UTC()) {
+		t.Fatal("expected the IP address to be unlocked")
+	}
+}
+
+func TestLockoutsController_InvalidCsrf(t *testing.T) {
+	app, attemptStore := setupLockoutsApp(t)
+
+	attempt := seedLockout(t, attemptStore, "10.0.0.1")
+
+	_, _, err := test.CallStringEndpoint(http.MethodPost, NewLockoutsController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":     {ACTION_UNLOCK},
+			"lockout_id": {attempt.ID},
+			"csrf_token": {"invalid"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stillLocked, err := attemptStore.AttemptFindByID(attempt.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stillLocked == nil || !stillLocked.IsLocked(time.Now().UTC()) {
+		t.Fatal("expected the IP address to stay locked without a valid csrf token")
+	}
+}
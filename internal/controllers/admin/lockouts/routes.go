@@ -0,0 +1,25 @@
+package lockouts
+
+import (
+	"errors"
+	"project/internal/app"
+	"project/internal/links"
+
+	"github.com/dracory/rtr"
+)
+
+// Routes are the routes of the lockouts manager
+func Routes(app app.AppInterface) ([]rtr.RouteInterface, error) {
+	if app == nil {
+		return nil, errors.New("app cannot be nil")
+	}
+
+	lockouts := rtr.NewRoute().
+		SetName("Admin > Lockouts").
+		SetPath(links.ADMIN_LOCKOUTS).
+		SetHTMLHandler(NewLockoutsController(app).Handler)
+
+	return []rtr.RouteInterface{
+		lockouts,
+	}, nil
+}
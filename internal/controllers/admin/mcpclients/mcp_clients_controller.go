@@ -0,0 +1,382 @@
+package mcpclients
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"project/internal/mcp"
+	"project/internal/mcp/providers"
+	"strings"
+	"time"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+)
+
+// Actions supported by the MCP clients controller
+const (
+	ACTION_CREATE = "create"
+	ACTION_REVOKE = "revoke"
+)
+
+// == CONTROLLER ===============================================================
+
+// mcpClientsController lets the administrators create the named keys of
+// the MCP clients, allowed the tools they select, and revoke them.
+//
+// The key is shown once, right after it is created, as only its hash is
+// stored.
+type mcpClientsController struct {
+	app app.AppInterface
+}
+
+type mcpClientsControllerData struct {
+	clients []mcp.Client
+
+	// providers are the tool providers, with their tools
+	providers []mcpClientsProvider
+
+	// createdKey is the key just created, shown once
+	createdKey string
+	formName   string
+	formTools  []string
+	formError  string
+}
+
+type mcpClientsProvider struct {
+	name  string
+	tools []mcp.Tool
+	// err is set when the tools of the provider could not be listed
+	err error
+}
+
+// == CONSTRUCTOR ==============================================================
+
+// NewMCPClientsController creates a new MCP clients controller
+func NewMCPClientsController(app app.AppInterface) *mcpClientsController {
+	return &mcpClientsController{app: app}
+}
+
+// == PUBLIC METHODS ===========================================================
+
+// Handler lists the clients, and processes the create and revoke forms
+func (controller *mcpClientsController) Handler(w http.ResponseWriter, r *http.Request) string {
+	listURL := links.Admin().MCPClients()
+
+	if controller.app.GetCustomStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "MCP clients require the custom store to be enabled", links.Admin().Home(), 10)
+	}
+
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.Admin().Home(), 10)
+	}
+
+	if r.Method == http.MethodPost {
+		if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", listURL, 10)
+		}
+
+		switch req.GetStringTrimmed(r, "action") {
+		case ACTION_CREATE:
+			return controller.postCreate(r, data)
+		case ACTION_REVOKE:
+			return controller.postRevoke(w, r, data)
+		}
+
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Unknown action", listURL, 10)
+	}
+
+	return controller.page(r, data)
+}
+
+// == PRIVATE METHODS ==========================================================
+
+func (controller *mcpClientsController) prepareData(r *http.Request) (data mcpClientsControllerData, errorMessage string) {
+	clientStore, err := mcp.NewStore(controller.app.GetCustomStore())
+
+	if err != nil {
+		controller.app.GetLogger().Error("At mcpClientsController > prepareData > NewStore", slog.String("error", err.Error()))
+		return data, "MCP clients are currently unavailable"
+	}
+
+	data.clients, err = clientStore.ClientList()
+
+	if err != nil {
+		controller.app.GetLogger().Error("At mcpClientsController > prepareData > ClientList", slog.String("error", err.Error()))
+		return data, "MCP clients could not be loaded"
+	}
+
+	for _, provider := range providers.Providers(controller.app) {
+		tools, err := provider.Tools(r.Context())
+
+		if err != nil {
+			controller.app.GetLogger().Warn("At mcpClientsController > prepareData > Tools", slog.String("provider", provider.Name()), slog.String("error", err.Error()))
+		}
+
+		data.providers = append(data.providers, mcpClientsProvider{name: provider.Name(), tools: tools, err: err})
+	}
+
+	return data, ""
+}
+
+// postCreate creates the client, and shows its key on the page once
+func (controller *mcpClientsController) postCreate(r *http.Request, data mcpClientsControllerData) string {
+	data.formName = req.GetStringTrimmed(r, "name")
+
+	// Only the tools (and patterns) offered on the form are kept
+	offered := controller.toolOptions(data)
+	data.formTools = lo.Filter(lo.Uniq(req.GetArray(r, "tools", []string{})), func(tool string, _ int) bool {
+		return lo.Contains(offered, tool)
+	})
+
+	createdBy := ""
+	if authUser := helpers.GetAuthUser(r); authUser != nil {
+		createdBy = authUser.GetID()
+	}
+
+	key, client, err := ext.MCPClientCreate(controller.app, data.formName, data.formTools, createdBy)
+
+	if err != nil {
+		data.formError = capitalize(err.Error()) + "."
+		return controller.page(r, data)
+	}
+
+	data.createdKey = key
+	data.clients = append([]mcp.Client{*client}, data.clients...)
+	data.formName = ""
+	data.formTools = nil
+
+	return controller.page(r, data)
+}
+
+// postRevoke revokes the client, which cannot call the tools anymore
+func (controller *mcpClientsController) postRevoke(w http.ResponseWriter, r *http.Request, data mcpClientsControllerData) string {
+	listURL := links.Admin().MCPClients()
+	clientID := req.GetStringTrimmed(r, "client_id")
+
+	client, found := lo.Find(data.clients, func(client mcp.Client) bool {
+		return client.ID == clientID
+	})
+
+	if !found {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Client not found", listURL, 10)
+	}
+
+	if err := ext.MCPClientRevoke(controller.app, &client); err != nil {
+		controller.app.GetLogger().Error("At mcpClientsController > postRevoke > MCPClientRevoke", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Client could not be revoked", listURL, 10)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "Revoked "+client.Name, listURL, 5)
+}
+
+// toolOptions returns the tools and patterns offered on the create form
+func (controller *mcpClientsController) toolOptions(data mcpClientsControllerData) []string {
+	options := []string{mcp.TOOLS_ALL}
+
+	for _, provider := range data.providers {
+		options = append(options, provider.name+"_"+mcp.TOOLS_ALL)
+
+		for _, tool := range provider.tools {
+			options = append(options, tool.Name)
+		}
+	}
+
+	return options
+}
+
+func (controller *mcpClientsController) page(r *http.Request, data mcpClientsControllerData) string {
+	title := "MCP Clients"
+
+	breadcrumbs := layouts.Breadcrumbs([]layouts.Breadcrumb{
+		{Name: "Home", URL: links.Admin().Home()},
+		{Name: "MCP Clients", URL: links.Admin().MCPClients()},
+	})
+
+	content := hb.Div().
+		Child(hb.Paragraph().
+			Class("text-muted").
+			Text("The MCP clients (e.g. AI assistants) call the tools at "+links.MCP+" with their key, in the header X-MCP-API-Key or Authorization: Bearer <key>. Each call is recorded in the audit log.")).
+		ChildIf(data.createdKey != "", controller.createdKeyCard(data.createdKey)).
+		Child(controller.createCard(data)).
+		Child(controller.clientsCard(data))
+
+	return layouts.NewAdminLayout(controller.app, r, layouts.Options{
+		Title:   title,
+		Content: layouts.AdminPage(breadcrumbs, hb.Heading1().Text(title), content),
+	}).ToHTML()
+}
+
+// createdKeyCard shows the key just created
+func (controller *mcpClientsController) createdKeyCard(key string) hb.TagInterface {
+	return hb.Div().
+		Class("card border-success mb-4").
+		Child(hb.Div().
+			Class("card-body").
+			Child(hb.Heading5().Class("card-title").Text("The key of the new client")).
+			Child(hb.Paragraph().Text("Copy the key now. For security it will not be shown again.")).
+			Child(hb.Input().
+				Type(hb.TYPE_TEXT).
+				Class("form-control font-monospace").
+				Attr("aria-label", "MCP client key").
+				ReadOnly(true).
+				Value(key)))
+}
+
+func (controller *mcpClientsController) createCard(data mcpClientsControllerData) hb.TagInterface {
+	tools := hb.Div().Class("mb-3").
+		Child(hb.Label().Class("form-label").Text("Tools")).
+		Child(controller.toolCheckbox(data, mcp.TOOLS_ALL, "All tools, including the ones added later"))
+
+	for _, provider := range data.providers {
+		group := hb.Div().Class("border rounded p-2 mt-2").
+			Child(controller.toolCheckbox(data, provider.name+"_"+mcp.TOOLS_ALL, "All "+provider.name+" tools"))
+
+		if provider.err != nil {
+			group.Child(hb.Div().Class("small text-danger ms-4").Text("The tools could not be listed"))
+		}
+
+		for _, tool := range provider.tools {
+			group.Child(hb.Div().Class("ms-4").Child(controller.toolCheckbox(data, tool.Name, tool.Description)))
+		}
+
+		tools.Child(group)
+	}
+
+	form := hb.Form().
+		Method(http.MethodPost).
+		Action(links.Admin().MCPClients()).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret()))).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value(ACTION_CREATE)).
+		ChildIf(data.formError != "", hb.Div().Class("alert alert-danger").Text(data.formError)).
+		Child(hb.Div().Class("mb-3").
+			Child(hb.Label().Class("form-label").For("name").Text("Name")).
+			Child(hb.Input().
+				Type(hb.TYPE_TEXT).
+				Class("form-control").
+				ID("name").
+				Name("name").
+				Placeholder("e.g. Content assistant").
+				Required(true).
+				Value(data.formName))).
+		Child(tools).
+		Child(hb.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn btn-primary").
+			Text("Create Client"))
+
+	return hb.Div().
+		Class("card mb-4").
+		Child(hb.Div().
+			Class("card-body").
+			Child(hb.Heading5().Class("card-title").Text("Create a client")).
+			Child(form))
+}
+
+// toolCheckbox is the checkbox of a tool, or of a pattern of tools
+func (controller *mcpClientsController) toolCheckbox(data mcpClientsControllerData, tool string, description string) hb.TagInterface {
+	id := "tool_" + strings.ReplaceAll(tool, "*", "all")
+
+	return hb.Div().
+		Class("form-check").
+		Child(hb.Input().
+			Type(hb.TYPE_CHECKBOX).
+			Class("form-check-input").
+			ID(id).
+			Name("tools").
+			Value(tool).
+			AttrIf(lo.Contains(data.formTools, tool), "checked", "checked")).
+		Child(hb.Label().
+			Class("form-check-label").
+			For(id).
+			Child(hb.Code().Text(tool)).
+			ChildIf(description != "", hb.Span().Class("text-muted").Text(" "+description)))
+}
+
+func (controller *mcpClientsController) clientsCard(data mcpClientsControllerData) hb.TagInterface {
+	body := hb.Div().Class("card-body").
+		Child(hb.Heading5().Class("card-title").Text("Clients"))
+
+	if len(data.clients) == 0 {
+		return hb.Div().
+			Class("card").
+			Child(body.Child(hb.Div().Class("alert alert-info mb-0").Text("There are no MCP clients yet")))
+	}
+
+	csrfToken := csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret())
+	rows := hb.TBody()
+
+	for _, client := range data.clients {
+		status := client.Status()
+
+		rows.Child(hb.TR().
+			Child(hb.TD().
+				Child(hb.Div().Text(client.Name)).
+				Child(hb.Small().Class("text-muted font-monospace").Text("…" + client.Hint))).
+			Child(hb.TD().Class("font-monospace small").Text(strings.Join(client.Tools, ", "))).
+			Child(hb.TD().Text(formatTime(client.CreatedAt, "-"))).
+			Child(hb.TD().Text(formatTime(client.LastUsedAt, "Never"))).
+			Child(hb.TD().Child(hb.Span().
+				Class(lo.Ternary(status == mcp.CLIENT_STATUS_ACTIVE, "badge bg-success", "badge bg-secondary")).
+				Text(status))).
+			Child(hb.TD().
+				Class("text-end").
+				ChildIf(status == mcp.CLIENT_STATUS_ACTIVE, controller.revokeForm(client, csrfToken))))
+	}
+
+	table := hb.Table().
+		Class("table table-striped align-middle mb-0").
+		Child(hb.Thead().Child(hb.TR().
+			Child(hb.TH().Text("Name")).
+			Child(hb.TH().Text("Tools")).
+			Child(hb.TH().Text("Created")).
+			Child(hb.TH().Text("Last Used")).
+			Child(hb.TH().Text("Status")).
+			Child(hb.TH()))).
+		Child(rows)
+
+	return hb.Div().
+		Class("card").
+		Child(body.Child(hb.Div().Class("table-responsive").Child(table)))
+}
+
+func (controller *mcpClientsController) revokeForm(client mcp.Client, csrfToken string) hb.TagInterface {
+	return hb.Form().
+		Class("d-inline").
+		Method(http.MethodPost).
+		Action(links.Admin().MCPClients()).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value(ACTION_REVOKE)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("client_id").Value(client.ID)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrfToken)).
+		Child(hb.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn btn-sm btn-outline-danger").
+			Text("Revoke"))
+}
+
+// formatTime formats the time, or returns the text if the time is not set
+func formatTime(t time.Time, zeroText string) string {
+	if t.IsZero() {
+		return zeroText
+	}
+
+	return carbon.CreateFromStdTime(t).ToDateTimeString(carbon.UTC)
+}
+
+// capitalize upper cases the first letter of the message
+func capitalize(message string) string {
+	if message == "" {
+		return message
+	}
+
+	return strings.ToUpper(message[:1]) + message[1:]
+}
@@ -0,0 +1,198 @@
+package mcpclients
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/mcp"
+	"project/internal/testutils"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/test"
+)
+
+func setupMCPClientsApp(t *testing.T) (app.AppInterface, mcp.StoreInterface) {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetSettingStoreUsed(true)
+	cfg.SetCsrfSecret("test-csrf-secret")
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	clientStore, err := mcp.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app, clientStore
+}
+
+func TestMCPClientsController_RequiresCustomStore(t *testing.T) {
+	app := testutils.Setup(testutils.WithCacheStore(true))
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewMCPClientsController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" {
+		t.Fatalf("expected an error flash message, got %+v", flashMessage)
+	}
+}
+
+func TestMCPClientsController_Empty(t *testing.T) {
+	app, _ := setupMCPClientsApp(t)
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewMCPClientsController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"There are no MCP clients yet", "Create Client", "settings_*", "settings_get"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected the page to contain %q", expected)
+		}
+	}
+}
+
+func TestMCPClientsController_Create(t *testing.T) {
+	app, clientStore := setupMCPClientsApp(t)
+
+	body, _, err := test.CallStringEndpoint(http.MethodPost, NewMCPClientsController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":     {ACTION_CREATE},
+			"name":       {"Content assistant"},
+			"tools":      {"settings_get", "unknown_tool"},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "The key of the new client") || !strings.Contains(body, mcp.KEY_PREFIX) {
+		t.Fatal("expected the key of the new client to be shown")
+	}
+
+	clients, err := clientStore.ClientList()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(clients) != 1 {
+		t.Fatalf("expected 1 client, got %d", len(clients))
+	}
+
+	// The tools not offered on the form are dropped
+	if len(clients[0].Tools) != 1 || clients[0].Tools[0] != "settings_get" {
+		t.Fatalf("expected the client to be allowed settings_get only, got %v", clients[0].Tools)
+	}
+}
+
+func TestMCPClientsController_CreateRequiresTools(t *testing.T) {
+	app, clientStore := setupMCPClientsApp(t)
+
+	body, _, err := test.CallStringEndpoint(http.MethodPost, NewMCPClientsController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":     {ACTION_CREATE},
+			"name":       {"Content assistant"},
+			"tools":      {"unknown_tool"},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "Please select at least one tool.") {
+		t.Fatal("expected the form error")
+	}
+
+	clients, err := clientStore.ClientList()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(clients) != 0 {
+		t.Fatalf("expected no client, got %d", len(clients))
+	}
+}
+
+func TestMCPClientsController_Revoke(t *testing.T) {
+	app, clientStore := setupMCPClientsApp(t)
+
+	key, client, err := ext.MCPClientCreate(app, "Content assistant", []string{mcp.TOOLS_ALL}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewMCPClientsController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":     {ACTION_REVOKE},
+			"client_id":  {client.ID},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected a success flash message, got %+v", flashMessage)
+	}
+
+	revoked, err := clientStore.ClientFindByID(client.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked == nil || !revoked.IsRevoked() {
+		t.Fatal("expected the client to be revoked")
+	}
+
+	if _, err := ext.MCPClientAuthenticate(app, key); err == nil {
+		t.Fatal("expected the key of the revoked client to be rejected")
+	}
+}
+
+func TestMCPClientsController_InvalidCsrf(t *testing.T) {
+	app, clientStore := setupMCPClientsApp(t)
+
+	_, _, err := test.CallStringEndpoint(http.MethodPost, NewMCPClientsController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":     {ACTION_CREATE},
+			"name":       {"Content assistant"},
+			"tools":      {mcp.TOOLS_ALL},
+			"csrf_token": {"invalid"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clients, err := clientStore.ClientList()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(clients) != 0 {
+		t.Fatal("expected no client to be created without a valid csrf token")
+	}
+}
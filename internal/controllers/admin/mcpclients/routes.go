@@ -0,0 +1,25 @@
+package mcpclients
+
+import (
+	"errors"
+	"project/internal/app"
+	"project/internal/links"
+
+	"github.com/dracory/rtr"
+)
+
+// Routes are the routes of the MCP clients manager
+func Routes(app app.AppInterface) ([]rtr.RouteInterface, error) {
+	if app == nil {
+		return nil, errors.New("app cannot be nil")
+	}
+
+	mcpClients := rtr.NewRoute().
+		SetName("Admin > MCP Clients").
+		SetPath(links.ADMIN_MCP_CLIENTS).
+		SetHTMLHandler(NewMCPClientsController(app).Handler)
+
+	return []rtr.RouteInterface{
+		mcpClients,
+	}, nil
+}
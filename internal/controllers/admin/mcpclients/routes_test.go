@@ -0,0 +1,31 @@
+package mcpclients
+
+import (
+	"testing"
+
+	"project/internal/links"
+	"project/internal/testutils"
+)
+
+func TestRoutes(t *testing.T) {
+	routes, err := Routes(testutils.Setup())
+	if err != nil {
+		t.Fatalf("Routes() returned error: %v", err)
+	}
+
+	if len(routes) != 1 || routes[0].GetPath() != links.ADMIN_MCP_CLIENTS {
+		t.Fatalf("expected the MCP clients route, got %d routes", len(routes))
+	}
+}
+
+func TestRoutesNilApp(t *testing.T) {
+	routes, err := Routes(nil)
+
+	if err == nil {
+		t.Error("Routes(nil) should return error")
+	}
+
+	if routes != nil {
+		t.Error("Routes(nil) should return nil routes")
+	}
+}
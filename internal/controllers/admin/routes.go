@@ -3,10 +3,13 @@ package admin
 import (
 	"net/http"
 	"project/internal/app"
+	adminAPITokens "project/internal/controllers/admin/apitokens"
 	adminBlog "project/internal/controllers/admin/blog"
 	adminCms "project/internal/controllers/admin/cms"
 	adminFiles "project/internal/controllers/admin/files"
+	adminLockouts "project/internal/controllers/admin/lockouts"
 	adminLogs "project/internal/controllers/admin/logs"
+	adminMCPClients "project/internal/controllers/admin/mcpclients"
 	adminMedia "project/internal/controllers/admin/media"
 	adminShop "project/internal/controllers/admin/shop"
 	adminStats "project/internal/controllers/admin/stats"
@@ -32,6 +35,21 @@ func Routes(app app.AppInterface) []rtr.RouteInterface {
 
 	adminRoutes := []rtr.RouteInterface{}
 
+	apiTokenRoutes, err := adminAPITokens.Routes(app)
+	if err == nil {
+		adminRoutes = append(adminRoutes, apiTokenRoutes...)
+	}
+
+	lockoutRoutes, err := adminLockouts.Routes(app)
+	if err == nil {
+		adminRoutes = append(adminRoutes, lockoutRoutes...)
+	}
+
+	mcpClientRoutes, err := adminMCPClients.Routes(app)
+	if err == nil {
+		adminRoutes = append(adminRoutes, mcpClientRoutes...)
+	}
+
 	blogController := adminBlog.NewBlogAdminController(app)
 	blog := rtr.NewRoute().
 		SetName("Admin > Blog").
@@ -69,6 +87,11 @@ func Routes(app app.AppInterface) []rtr.RouteInterface {
 		adminRoutes = append(adminRoutes, mediaRoutes...)
 	}
 
+	discountRoutes, err := adminShop.DiscountRoutes(app)
+	if err == nil {
+		adminRoutes = append(adminRoutes, discountRoutes...)
+	}
+
 	shopController := adminShop.NewShopAdminController(app)
 	shop := rtr.NewRoute().
 		SetName("Admin > Shop").
@@ -0,0 +1,346 @@
+package admin
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/discount"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"strconv"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+)
+
+// Actions supported by the discount controller
+const (
+	DISCOUNT_ACTION_CREATE = "create"
+	DISCOUNT_ACTION_DELETE = "delete"
+	DISCOUNT_ACTION_EDIT   = "edit"
+	DISCOUNT_ACTION_SAVE   = "save"
+)
+
+// == CONTROLLER ===============================================================
+
+// discountController manages the shop discount codes
+type discountController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR ==============================================================
+
+// NewDiscountController creates a new discount controller
+func NewDiscountController(app app.AppInterface) *discountController {
+	return &discountController{app: app}
+}
+
+// == PUBLIC METHODS ===========================================================
+
+// Handler lists the discount codes, and shows and processes
+// the create, edit and delete forms
+func (controller *discountController) Handler(w http.ResponseWriter, r *http.Request) string {
+	listURL := links.Admin().ShopDiscounts()
+
+	if controller.app.GetCustomStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Discount codes require the custom store to be enabled", links.Admin().Home(), 10)
+	}
+
+	discountStore, err := discount.NewStore(controller.app.GetCustomStore())
+	if err != nil {
+		controller.app.GetLogger().Error("At discountController > Handler", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Discount codes are currently unavailable", links.Admin().Home(), 10)
+	}
+
+	action := req.GetStringTrimmed(r, "action")
+
+	if r.Method == http.MethodPost {
+		if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", listURL, 10)
+		}
+
+		switch action {
+		case DISCOUNT_ACTION_SAVE:
+			return controller.postSave(w, r, discountStore)
+		case DISCOUNT_ACTION_DELETE:
+			return controller.postDelete(w, r, discountStore)
+		}
+
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Unknown action", listURL, 10)
+	}
+
+	switch action {
+	case DISCOUNT_ACTION_CREATE:
+		return controller.layout(r, "New Discount Code", controller.formView(discount.Discount{
+			Status: discount.STATUS_ACTIVE,
+			Type:   discount.TYPE_PERCENT,
+		}, ""))
+	case DISCOUNT_ACTION_EDIT:
+		existing, err := discountStore.DiscountFindByID(req.GetStringTrimmed(r, "discount_id"))
+		if err != nil {
+			controller.app.GetLogger().Error("At discountController > Handler > DiscountFindByID", slog.String("error", err.Error()))
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Discount code could not be loaded", listURL, 10)
+		}
+
+		if existing == nil {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Discount code not found", listURL, 10)
+		}
+
+		return controller.layout(r, "Edit Discount Code", controller.formView(*existing, ""))
+	}
+
+	return controller.layout(r, "Discount Codes", controller.listView(discountStore))
+}
+
+// == PRIVATE METHODS ==========================================================
+
+// postSave creates or updates a discount code. Validation errors
+// re-display the form with the submitted values
+func (controller *discountController) postSave(w http.ResponseWriter, r *http.Request, discountStore discount.StoreInterface) string {
+	listURL := links.Admin().ShopDiscounts()
+	discountID := req.GetStringTrimmed(r, "discount_id")
+
+	d := discount.Discount{}
+
+	if discountID != "" {
+		existing, err := discountStore.DiscountFindByID(discountID)
+		if err != nil {
+			controller.app.GetLogger().Error("At discountController > postSave > DiscountFindByID", slog.String("error", err.Error()))
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Discount code could not be loaded", listURL, 10)
+		}
+
+		if existing == nil {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Discount code not found", listURL, 10)
+		}
+
+		d = *existing
+	}
+
+	d.Code = discount.NormalizeCode(req.GetStringTrimmed(r, "code"))
+	d.Description = req.GetStringTrimmed(r, "description")
+	d.Status = req.GetStringTrimmed(r, "status")
+	d.Type = req.GetStringTrimmed(r, "type")
+	d.Value = req.GetStringTrimmed(r, "value")
+	d.StartsAt = req.GetStringTrimmed(r, "starts_at")
+	d.EndsAt = req.GetStringTrimmed(r, "ends_at")
+	d.MinimumSubtotal = req.GetStringTrimmed(r, "minimum_subtotal")
+
+	title := "New Discount Code"
+	if d.ID != "" {
+		title = "Edit Discount Code"
+	}
+
+	usageLimit, err := formInt(req.GetStringTrimmed(r, "usage_limit"))
+	if err != nil {
+		return controller.layout(r, title, controller.formView(d, "usage limit must be a whole number"))
+	}
+	d.UsageLimit = usageLimit
+
+	usageLimitPerUser, err := formInt(req.GetStringTrimmed(r, "usage_limit_per_user"))
+	if err != nil {
+		return controller.layout(r, title, controller.formView(d, "usage limit per customer must be a whole number"))
+	}
+	d.UsageLimitPerUser = usageLimitPerUser
+
+	if err := d.Validate(controller.app.GetConfig().GetPaymentCurrency()); err != nil {
+		return controller.layout(r, title, controller.formView(d, err.Error()))
+	}
+
+	if d.ID == "" {
+		err = discountStore.DiscountCreate(&d)
+	} else {
+		err = discountStore.DiscountUpdate(&d)
+	}
+
+	if err != nil {
+		controller.app.GetLogger().Error("At discountController > postSave", slog.String("error", err.Error()))
+		return controller.layout(r, title, controller.formView(d, err.Error()))
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "Discount code "+d.Code+" saved", listURL, 5)
+}
+
+// postDelete deletes a discount code. Redemptions are kept,
+// so paid orders still show which code was used
+func (controller *discountController) postDelete(w http.ResponseWriter, r *http.Request, discountStore discount.StoreInterface) string {
+	listURL := links.Admin().ShopDiscounts()
+	discountID := req.GetStringTrimmed(r, "discount_id")
+
+	if discountID == "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Discount code is required", listURL, 10)
+	}
+
+	if err := discountStore.DiscountDelete(discountID); err != nil {
+		controller.app.GetLogger().Error("At discountController > postDelete", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Discount code could not be deleted", listURL, 10)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "Discount code deleted", listURL, 5)
+}
+
+// layout wraps the content in the admin layout
+func (controller *discountController) layout(r *http.Request, title string, content hb.TagInterface) string {
+	breadcrumbs := layouts.Breadcrumbs([]layouts.Breadcrumb{
+		{Name: "Home", URL: links.Admin().Home()},
+		{Name: "Shop", URL: links.Admin().Shop()},
+		{Name: "Discount Codes", URL: links.Admin().ShopDiscounts()},
+	})
+
+	return layouts.NewAdminLayout(controller.app, r, layouts.Options{
+		Title:   title,
+		Content: layouts.AdminPage(breadcrumbs, hb.Heading1().Text(title), content),
+	}).ToHTML()
+}
+
+// listView shows the discount codes with their usage
+func (controller *discountController) listView(discountStore discount.StoreInterface) hb.TagInterface {
+	discounts, err := discountStore.DiscountList()
+	if err != nil {
+		controller.app.GetLogger().Error("At discountController > listView", slog.String("error", err.Error()))
+		return hb.Div().Class("alert alert-danger").Text("Discount codes could not be loaded")
+	}
+
+	buttonCreate := hb.Hyperlink().
+		Class("btn btn-primary mb-3").
+		Href(links.Admin().ShopDiscounts(map[string]string{"action": DISCOUNT_ACTION_CREATE})).
+		Child(hb.I().Class("bi bi-plus-circle me-2")).
+		Text("New Discount Code")
+
+	if len(discounts) == 0 {
+		return hb.Div().
+			Child(buttonCreate).
+			Child(hb.Div().Class("alert alert-info").Text("There are no discount codes yet"))
+	}
+
+	csrfToken := csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret())
+
+	tbody := hb.TBody()
+
+	for _, d := range discounts {
+		usageCount, err := discountStore.RedemptionCount(d.ID)
+		if err != nil {
+			controller.app.GetLogger().Error("At discountController > listView > RedemptionCount", slog.String("error", err.Error()))
+		}
+
+		usage := strconv.FormatInt(usageCount, 10)
+		if d.UsageLimit > 0 {
+			usage += " / " + strconv.Itoa(d.UsageLimit)
+		}
+
+		value := d.Value + "%"
+		if d.Type == discount.TYPE_FIXED {
+			value = d.Value
+		}
+
+		buttonEdit := hb.Hyperlink().
+			Class("btn btn-sm btn-outline-primary me-2").
+			Href(links.Admin().ShopDiscounts(map[string]string{"action": DISCOUNT_ACTION_EDIT, "discount_id": d.ID})).
+			Text("Edit")
+
+		formDelete := hb.Form().
+			Class("d-inline").
+			Method(http.MethodPost).
+			Action(links.Admin().ShopDiscounts()).
+			Attr("onsubmit", "return confirm('Delete discount code "+d.Code+"?');").
+			Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value(DISCOUNT_ACTION_DELETE)).
+			Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("discount_id").Value(d.ID)).
+			Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrfToken)).
+			Child(hb.Button().Type(hb.TYPE_SUBMIT).Class("btn btn-sm btn-outline-danger").Text("Delete"))
+
+		tbody.Child(hb.TR().
+			Child(hb.TD().Child(hb.Strong().Text(d.Code)).Child(hb.Div().Class("small text-muted").Text(d.Description))).
+			Child(hb.TD().Text(d.Status)).
+			Child(hb.TD().Text(value)).
+			Child(hb.TD().Text(d.StartsAt + " - " + d.EndsAt)).
+			Child(hb.TD().Text(usage)).
+			Child(hb.TD().Class("text-end").Child(buttonEdit).Child(formDelete)))
+	}
+
+	table := hb.Table().
+		Class("table table-striped align-middle").
+		Child(hb.Thead().Child(hb.TR().
+			Child(hb.TH().Text("Code")).
+			Child(hb.TH().Text("Status")).
+			Child(hb.TH().Text("Value")).
+			Child(hb.TH().Text("Valid")).
+			Child(hb.TH().Text("Used")).
+			Child(hb.TH()))).
+		Child(tbody)
+
+	return hb.Div().
+		Child(buttonCreate).
+		Child(table)
+}
+
+// formView shows the create / edit form
+func (controller *discountController) formView(d discount.Discount, errorMessage string) hb.TagInterface {
+	field := func(label, name, value, help string) hb.TagInterface {
+		return hb.Div().Class("mb-3").
+			Child(hb.Label().Class("form-label").For(name).Text(label)).
+			Child(hb.Input().Class("form-control").ID(name).Name(name).Value(value)).
+			ChildIf(help != "", hb.Div().Class("form-text").Text(help))
+	}
+
+	selectField := func(label, name, value string, options map[string]string, order []string) hb.TagInterface {
+		sel := hb.Select().Class("form-select").ID(name).Name(name)
+		for _, key := range order {
+			option := hb.Option().Value(key).Text(options[key])
+			if key == value {
+				option.Attr("selected", "selected")
+			}
+			sel.Child(option)
+		}
+
+		return hb.Div().Class("mb-3").
+			Child(hb.Label().Class("form-label").For(name).Text(label)).
+			Child(sel)
+	}
+
+	intValue := func(value int) string {
+		if value == 0 {
+			return ""
+		}
+		return strconv.Itoa(value)
+	}
+
+	currency := controller.app.GetConfig().GetPaymentCurrency()
+
+	return hb.Form().
+		Method(http.MethodPost).
+		Action(links.Admin().ShopDiscounts()).
+		Style("max-width:600px;").
+		ChildIf(errorMessage != "", hb.Div().Class("alert alert-danger").Text(errorMessage)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value(DISCOUNT_ACTION_SAVE)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("discount_id").Value(d.ID)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret()))).
+		Child(field("Code", "code", d.Code, "Letters, digits and dashes. Codes are not case sensitive")).
+		Child(field("Description", "description", d.Description, "Internal note, not shown to buyers")).
+		Child(selectField("Status", "status", d.Status, map[string]string{
+			discount.STATUS_ACTIVE:   "Active",
+			discount.STATUS_INACTIVE: "Inactive",
+		}, []string{discount.STATUS_ACTIVE, discount.STATUS_INACTIVE})).
+		Child(selectField("Type", "type", d.Type, map[string]string{
+			discount.TYPE_PERCENT: "Percentage",
+			discount.TYPE_FIXED:   "Fixed amount (" + currency + ")",
+		}, []string{discount.TYPE_PERCENT, discount.TYPE_FIXED})).
+		Child(field("Value", "value", d.Value, "A whole percentage (e.g. 10), or an amount (e.g. 5.00)")).
+		Child(field("Starts At", "starts_at", d.StartsAt, "Optional, UTC, format "+discount.DATETIME_FORMAT)).
+		Child(field("Ends At", "ends_at", d.EndsAt, "Optional, UTC, format "+discount.DATETIME_FORMAT)).
+		Child(field("Usage Limit", "usage_limit", intValue(d.UsageLimit), "Maximum number of paid orders. Empty for unlimited")).
+		Child(field("Usage Limit Per Customer", "usage_limit_per_user", intValue(d.UsageLimitPerUser), "Empty for unlimited")).
+		Child(field("Minimum Basket Value", "minimum_subtotal", d.MinimumSubtotal, "Optional, in "+currency)).
+		Child(hb.Div().
+			Child(hb.Button().Type(hb.TYPE_SUBMIT).Class("btn btn-success me-2").Text("Save")).
+			Child(hb.Hyperlink().Class("btn btn-secondary").Href(links.Admin().ShopDiscounts()).Text("Cancel")))
+}
+
+// formInt parses an optional whole number form value, empty means zero
+func formInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,202 @@
+package admin
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/discount"
+	"project/internal/testutils"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/test"
+)
+
+func setupDiscountApp(t *testing.T) (app.AppInterface, discount.StoreInterface) {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetCsrfSecret("test-csrf-secret")
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	discountStore, err := discount.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app, discountStore
+}
+
+func TestDiscountController_RequiresCustomStore(t *testing.T) {
+	app := testutils.Setup(testutils.WithCacheStore(true))
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewDiscountController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" {
+		t.Fatalf("expected an error flash message, got %+v", flashMessage)
+	}
+}
+
+func TestDiscountController_List(t *testing.T) {
+	app, discountStore := setupDiscountApp(t)
+
+	d := discount.Discount{Code: "SAVE10", Status: discount.STATUS_ACTIVE, Type: discount.TYPE_PERCENT, Value: "10", UsageLimit: 5}
+	if err := discountStore.DiscountCreate(&d); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := discountStore.RedemptionCreate(d.ID, "user_1", "order_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewDiscountController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Discount Codes", "SAVE10", "1 / 5"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected body to contain %q", expected)
+		}
+	}
+}
+
+func TestDiscountController_Create(t *testing.T) {
+	app, discountStore := setupDiscountApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewDiscountController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":               {DISCOUNT_ACTION_SAVE},
+			"csrf_token":           {csrf.TokenGenerate("test-csrf-secret")},
+			"code":                 {" summer-5 "},
+			"status":               {discount.STATUS_ACTIVE},
+			"type":                 {discount.TYPE_FIXED},
+			"value":                {"5.00"},
+			"usage_limit_per_user": {"1"},
+			"minimum_subtotal":     {"20.00"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected a success flash message, got %+v", flashMessage)
+	}
+
+	d, err := discountStore.DiscountFindByCode("SUMMER-5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d == nil || d.Value != "5.00" || d.UsageLimitPerUser != 1 || d.MinimumSubtotal != "20.00" {
+		t.Fatalf("expected the discount to be saved, got %+v", d)
+	}
+}
+
+func TestDiscountController_CreateInvalid(t *testing.T) {
+	app, discountStore := setupDiscountApp(t)
+
+	body, _, err := test.CallStringEndpoint(http.MethodPost, NewDiscountController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":     {DISCOUNT_ACTION_SAVE},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+			"code":       {"SAVE200"},
+			"status":     {discount.STATUS_ACTIVE},
+			"type":       {discount.TYPE_PERCENT},
+			"value":      {"200"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "percentage must be a whole number between 1 and 100") {
+		t.Error("expected the validation error to be shown")
+	}
+
+	list, err := discountStore.DiscountList()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 0 {
+		t.Errorf("expected no discount to be saved, got %d", len(list))
+	}
+}
+
+func TestDiscountController_InvalidCsrf(t *testing.T) {
+	app, discountStore := setupDiscountApp(t)
+
+	d := discount.Discount{Code: "SAVE10", Status: discount.STATUS_ACTIVE, Type: discount.TYPE_PERCENT, Value: "10"}
+	if err := discountStore.DiscountCreate(&d); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := test.CallStringEndpoint(http.MethodPost, NewDiscountController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":      {DISCOUNT_ACTION_DELETE},
+			"csrf_token":  {"invalid"},
+			"discount_id": {d.ID},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := discountStore.DiscountFindByID(d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found == nil {
+		t.Error("the discount must not be deleted without a valid CSRF token")
+	}
+}
+
+func TestDiscountController_Delete(t *testing.T) {
+	app, discountStore := setupDiscountApp(t)
+
+	d := discount.Discount{Code: "SAVE10", Status: discount.STATUS_ACTIVE, Type: discount.TYPE_PERCENT, Value: "10"}
+	if err := discountStore.DiscountCreate(&d); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := test.CallStringEndpoint(http.MethodPost, NewDiscountController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":      {DISCOUNT_ACTION_DELETE},
+			"csrf_token":  {csrf.TokenGenerate("test-csrf-secret")},
+			"discount_id": {d.ID},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := discountStore.DiscountFindByID(d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found != nil {
+		t.Error("expected the discount to be deleted")
+	}
+}
@@ -22,8 +22,27 @@ func ShopRoutes(app app.AppInterface) ([]rtr.RouteInterface, error) {
 		SetPath(links.ADMIN_SHOP + links.CATCHALL).
 		SetHandler(NewShopAdminController(app).Handler)
 
+	discountRoutes, err := DiscountRoutes(app)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(discountRoutes, shop, shopCatchAll), nil
+}
+
+// DiscountRoutes are the routes of the discount codes manager. They must be
+// registered before the shop catchall, which would otherwise handle them
+func DiscountRoutes(app app.AppInterface) ([]rtr.RouteInterface, error) {
+	if app == nil {
+		return nil, errors.New("app cannot be nil")
+	}
+
+	discounts := rtr.NewRoute().
+		SetName("Admin > Shop > Discounts").
+		SetPath(links.ADMIN_SHOP_DISCOUNTS).
+		SetHTMLHandler(NewDiscountController(app).Handler)
+
 	return []rtr.RouteInterface{
-		shop,
-		shopCatchAll,
+		discounts,
 	}, nil
 }
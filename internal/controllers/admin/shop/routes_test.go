@@ -3,6 +3,7 @@ package admin
 import (
 	"testing"
 
+	"project/internal/links"
 	"project/internal/testutils"
 )
 
@@ -58,3 +59,43 @@ func TestShopRoutesReturnsRoutes(t *testing.T) {
 		t.Errorf("Expected at least 2 routes (shopOrders, shopCatchAll), got %d", len(routes))
 	}
 }
+
+// TestDiscountRoutesNilRegistry verifies DiscountRoutes handles nil app
+func TestDiscountRoutesNilRegistry(t *testing.T) {
+	t.Parallel()
+	routes, err := DiscountRoutes(nil)
+
+	if err == nil {
+		t.Error("DiscountRoutes(nil) should return error")
+	}
+
+	if routes != nil {
+		t.Error("DiscountRoutes(nil) should return nil routes")
+	}
+}
+
+// TestShopRoutesDiscountsBeforeCatchAll verifies the discount routes are
+// not shadowed by the shop catchall
+func TestShopRoutesDiscountsBeforeCatchAll(t *testing.T) {
+	t.Parallel()
+	app := testutils.Setup()
+
+	routes, err := ShopRoutes(app)
+	if err != nil {
+		t.Fatalf("ShopRoutes() returned error: %v", err)
+	}
+
+	discountIndex, catchAllIndex := -1, -1
+	for i, route := range routes {
+		switch route.GetPath() {
+		case links.ADMIN_SHOP_DISCOUNTS:
+			discountIndex = i
+		case links.ADMIN_SHOP + links.CATCHALL:
+			catchAllIndex = i
+		}
+	}
+
+	if discountIndex == -1 || catchAllIndex == -1 || discountIndex > catchAllIndex {
+		t.Errorf("expected the discounts route before the catchall, got %d and %d", discountIndex, catchAllIndex)
+	}
+}
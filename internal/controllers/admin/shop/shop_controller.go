@@ -6,7 +6,10 @@ import (
 	"project/internal/controllers/admin/adapters"
 	"project/internal/helpers"
 	"project/internal/links"
+	"project/internal/searchindex"
+	"project/internal/tasks/search_reindex"
 
+	"github.com/dracory/req"
 	shopadmin "github.com/dracory/shopadmin"
 )
 
@@ -54,4 +57,15 @@ func (controller *shopAdminController) Handler(w http.ResponseWriter, r *http.Re
 	}
 
 	admin.Handle(w, r)
+
+	// The changes are posted, keep the search results in sync with them:
+	// only the product of the product_id, else (e.g. for a new product) all of them
+	if r.Method != http.MethodGet {
+		productIDs := []string{}
+		if productID := req.GetStringTrimmed(r, "product_id"); productID != "" {
+			productIDs = append(productIDs, productID)
+		}
+
+		search_reindex.Changed(r.Context(), controller.app, searchindex.DOCUMENT_TYPE_PRODUCT, productIDs...)
+	}
 }
@@ -0,0 +1,112 @@
+package cspreport
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"strings"
+)
+
+// maxReportBytes limits the size of the accepted violation reports
+const maxReportBytes = 16384
+
+// ignoredSourcePrefixes are the sources of the violations caused by
+// the browser extensions, not by the application
+var ignoredSourcePrefixes = []string{
+	"chrome-extension",
+	"moz-extension",
+	"safari-extension",
+	"safari-web-extension",
+}
+
+// == CONTROLLER ==============================================================
+
+// cspReportController receives the Content Security Policy violations
+// reported by the browsers (see the report-uri directive set by the
+// security headers middleware)
+type cspReportController struct {
+	app app.AppInterface
+}
+
+// cspReport is the violation report, as sent by the browsers
+type cspReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		BlockedURI         string `json:"blocked-uri"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		Disposition        string `json:"disposition"`
+	} `json:"csp-report"`
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewCSPReportController creates a new CSP violation report controller
+func NewCSPReportController(app app.AppInterface) *cspReportController {
+	return &cspReportController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+// Handler logs a Content Security Policy violation report.
+//
+// Business logic:
+//  1. Only POST requests are accepted, with a payload of up to 16KB
+//  2. Invalid reports are rejected with 400
+//  3. The violations caused by the browser extensions are ignored
+//  4. The violation is logged as a warning, and acknowledged with 204
+func (controller *cspReportController) Handler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxReportBytes))
+
+	if err != nil {
+		http.Error(w, "Payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	report := cspReport{}
+
+	if err := json.Unmarshal(payload, &report); err != nil || report.Report.ViolatedDirective == "" && report.Report.EffectiveDirective == "" {
+		http.Error(w, "Invalid report", http.StatusBadRequest)
+		return
+	}
+
+	if isIgnoredSource(report.Report.BlockedURI) || isIgnoredSource(report.Report.SourceFile) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	controller.app.GetLogger().Warn("CSP violation",
+		slog.String("document_uri", report.Report.DocumentURI),
+		slog.String("violated_directive", report.Report.ViolatedDirective),
+		slog.String("effective_directive", report.Report.EffectiveDirective),
+		slog.String("blocked_uri", report.Report.BlockedURI),
+		slog.String("source_file", report.Report.SourceFile),
+		slog.Int("line_number", report.Report.LineNumber),
+		slog.String("disposition", report.Report.Disposition),
+		slog.String("user_agent", r.UserAgent()),
+	)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// == PRIVATE METHODS =========================================================
+
+// isIgnoredSource returns whether the source is a browser extension
+func isIgnoredSource(source string) bool {
+	for _, prefix := range ignoredSourcePrefixes {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
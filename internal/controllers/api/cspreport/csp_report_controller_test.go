@@ -0,0 +1,96 @@
+package cspreport
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/testutils"
+)
+
+// setupCSPReportApp returns an app, which logs to the returned buffer
+func setupCSPReportApp(t *testing.T) (app.AppInterface, *bytes.Buffer) {
+	t.Helper()
+
+	app := testutils.Setup()
+	logs := &bytes.Buffer{}
+	app.SetLogger(slog.New(slog.NewTextHandler(logs, nil)))
+
+	return app, logs
+}
+
+// sendReport posts the report to the endpoint
+func sendReport(app app.AppInterface, method string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/internal/csp-report", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/csp-report")
+	rr := httptest.NewRecorder()
+
+	NewCSPReportController(app).Handler(rr, req)
+
+	return rr
+}
+
+func TestCSPReportController_LogsTheViolation(t *testing.T) {
+	app, logs := setupCSPReportApp(t)
+
+	rr := sendReport(app, http.MethodPost, `{"csp-report":{
+		"document-uri":"https://example.com/contact",
+		"violated-directive":"script-src-elem",
+		"effective-directive":"script-src-elem",
+		"blocked-uri":"inline",
+		"source-file":"https://example.com/contact",
+		"line-number":12
+	}}`)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rr.Code)
+	}
+
+	for _, expected := range []string{"CSP violation", "document_uri=https://example.com/contact", "effective_directive=script-src-elem", "blocked_uri=inline", "line_number=12"} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("expected the log to contain %q, got %q", expected, logs.String())
+		}
+	}
+}
+
+func TestCSPReportController_IgnoresTheBrowserExtensions(t *testing.T) {
+	app, logs := setupCSPReportApp(t)
+
+	rr := sendReport(app, http.MethodPost, `{"csp-report":{"violated-directive":"script-src","blocked-uri":"chrome-extension"}}`)
+
+	if rr.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rr.Code)
+	}
+
+	if logs.Len() != 0 {
+		t.Errorf("expected nothing to be logged, got %q", logs.String())
+	}
+}
+
+func TestCSPReportController_RejectsInvalidReports(t *testing.T) {
+	app, logs := setupCSPReportApp(t)
+
+	if rr := sendReport(app, http.MethodGet, ``); rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: expected status 405, got %d", rr.Code)
+	}
+
+	if rr := sendReport(app, http.MethodPost, `not json`); rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid JSON: expected status 400, got %d", rr.Code)
+	}
+
+	if rr := sendReport(app, http.MethodPost, `{"other":{}}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("missing report: expected status 400, got %d", rr.Code)
+	}
+
+	if rr := sendReport(app, http.MethodPost, `{"csp-report":{"violated-directive":"`+strings.Repeat("a", maxReportBytes)+`"}}`); rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("too large: expected status 413, got %d", rr.Code)
+	}
+
+	if logs.Len() != 0 {
+		t.Errorf("expected nothing to be logged, got %q", logs.String())
+	}
+}
@@ -0,0 +1,24 @@
+package cspreport
+
+import (
+	"project/internal/app"
+	"project/internal/links"
+
+	"github.com/dracory/rtr"
+)
+
+// Routes returns the Content Security Policy violation report routes
+func Routes(app app.AppInterface) []rtr.RouteInterface {
+	if app == nil || app.GetConfig() == nil {
+		return []rtr.RouteInterface{}
+	}
+
+	cspReportRoute := rtr.NewRoute().
+		SetName("API > Internal > CSP Report").
+		SetPath(links.API_INTERNAL_CSP_REPORT).
+		SetHandler(NewCSPReportController(app).Handler)
+
+	return []rtr.RouteInterface{
+		cspReportRoute,
+	}
+}
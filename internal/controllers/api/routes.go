@@ -0,0 +1,21 @@
+package api
+
+import (
+	"project/internal/app"
+	"project/internal/controllers/api/cspreport"
+	v1 "project/internal/controllers/api/v1"
+	"project/internal/controllers/api/webhook"
+
+	"github.com/dracory/rtr"
+)
+
+// Routes returns the API routes
+func Routes(app app.AppInterface) []rtr.RouteInterface {
+	routes := []rtr.RouteInterface{}
+
+	routes = append(routes, v1.Routes(app)...)
+	routes = append(routes, webhook.Routes(app)...)
+	routes = append(routes, cspreport.Routes(app)...)
+
+	return routes
+}
@@ -0,0 +1,302 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/apitoken"
+	"project/internal/app"
+	"project/internal/searchindex"
+	"project/internal/tasks/search_reindex"
+	"slices"
+	"strings"
+
+	"github.com/dracory/blogstore"
+	"github.com/dracory/rtr"
+)
+
+// postStatuses are the statuses a post can be created or updated with
+var postStatuses = []string{
+	blogstore.POST_STATUS_DRAFT,
+	blogstore.POST_STATUS_PUBLISHED,
+	blogstore.POST_STATUS_UNPUBLISHED,
+}
+
+// postInput is the JSON body of the create and update requests. The nil
+// fields are not changed by an update.
+type postInput struct {
+	Title   *string `json:"title"`
+	Slug    *string `json:"slug"`
+	Summary *string `json:"summary"`
+	Content *string `json:"content"`
+	Status  *string `json:"status"`
+}
+
+// == CONTROLLER ===============================================================
+
+// blogPostsController serves the blog posts. The users see the published
+// posts only, the administrators see all of them.
+type blogPostsController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR ==============================================================
+
+// NewBlogPostsController creates a new blog posts controller
+func NewBlogPostsController(app app.AppInterface) *blogPostsController {
+	return &blogPostsController{app: app}
+}
+
+func blogPostsEndpoints(app app.AppInterface) []Endpoint {
+	controller := NewBlogPostsController(app)
+
+	bodyParameters := []Parameter{
+		{Name: "title", In: PARAMETER_IN_BODY, Type: "string", Description: "The title"},
+		{Name: "slug", In: PARAMETER_IN_BODY, Type: "string", Description: "The slug used in the URL"},
+		{Name: "summary", In: PARAMETER_IN_BODY, Type: "string", Description: "The summary"},
+		{Name: "content", In: PARAMETER_IN_BODY, Type: "string", Description: "The content"},
+		{Name: "status", In: PARAMETER_IN_BODY, Type: "string", Description: "The status (default draft)", Enum: postStatuses},
+	}
+
+	return []Endpoint{
+		{
+			Method:      http.MethodGet,
+			Path:        "/blog/posts",
+			Tag:         "Blog",
+			Summary:     "List the blog posts",
+			Description: "Lists the posts, newest first. The users see the published posts only.",
+			Scope:       apitoken.SCOPE_BLOG_READ,
+			Parameters: append(paginationParameters(), Parameter{
+				Name:        "status",
+				In:          PARAMETER_IN_QUERY,
+				Type:        "string",
+				Description: "Only the posts with the status (administrators only)",
+				Enum:        postStatuses,
+			}),
+			Handler: controller.List,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "/blog/posts/:id",
+			Tag:        "Blog",
+			Summary:    "Get a blog post",
+			Scope:      apitoken.SCOPE_BLOG_READ,
+			Parameters: []Parameter{idParameter("The ID of the post")},
+			Handler:    controller.Get,
+		},
+		{
+			Method:     http.MethodPost,
+			Path:       "/blog/posts",
+			Tag:        "Blog",
+			Summary:    "Create a blog post",
+			Scope:      apitoken.SCOPE_BLOG_WRITE,
+			Parameters: requireParameter(bodyParameters, "title"),
+			Handler:    controller.Create,
+		},
+		{
+			Method:      http.MethodPatch,
+			Path:        "/blog/posts/:id",
+			Tag:         "Blog",
+			Summary:     "Update a blog post",
+			Description: "Updates the fields sent, and leaves the others unchanged.",
+			Scope:       apitoken.SCOPE_BLOG_WRITE,
+			Parameters:  append([]Parameter{idParameter("The ID of the post")}, bodyParameters...),
+			Handler:     controller.Update,
+		},
+	}
+}
+
+// == PUBLIC METHODS ===========================================================
+
+// List returns a page of the posts, newest first
+func (controller *blogPostsController) List(w http.ResponseWriter, r *http.Request) {
+	page, errorMessage := paginationFromRequest(r)
+	if errorMessage != "" {
+		respondError(w, http.StatusBadRequest, errorMessage)
+		return
+	}
+
+	options := blogstore.PostQueryOptions{
+		Status:    blogstore.POST_STATUS_PUBLISHED,
+		SortOrder: "DESC",
+		OrderBy:   "published_at",
+		Offset:    page.offset(),
+		Limit:     page.perPage,
+	}
+
+	if isAdministrator(r) {
+		options.Status = r.URL.Query().Get("status")
+		options.OrderBy = "created_at"
+	}
+
+	posts, err := controller.app.GetBlogStore().PostList(r.Context(), options)
+	if err != nil {
+		controller.app.GetLogger().Error("At blogPostsController > List > PostList", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Posts could not be loaded")
+		return
+	}
+
+	total, err := controller.app.GetBlogStore().PostCount(r.Context(), options)
+	if err != nil {
+		controller.app.GetLogger().Error("At blogPostsController > List > PostCount", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Posts could not be loaded")
+		return
+	}
+
+	items := make([]map[string]any, 0, len(posts))
+
+	for _, post := range posts {
+		items = append(items, postToMap(post))
+	}
+
+	respondList(w, items, page, total)
+}
+
+// Get returns a post
+func (controller *blogPostsController) Get(w http.ResponseWriter, r *http.Request) {
+	post := controller.findPost(w, r)
+
+	if post == nil {
+		return
+	}
+
+	respondItem(w, http.StatusOK, postToMap(post))
+}
+
+// Create creates a post, as a draft unless another status is sent
+func (controller *blogPostsController) Create(w http.ResponseWriter, r *http.Request) {
+	input := postInput{}
+
+	if errorMessage := decodeBody(w, r, &input); errorMessage != "" {
+		respondError(w, http.StatusBadRequest, errorMessage)
+		return
+	}
+
+	if input.Title == nil || strings.TrimSpace(*input.Title) == "" {
+		respondError(w, http.StatusUnprocessableEntity, "title is required")
+		return
+	}
+
+	if input.Status == nil {
+		status := blogstore.POST_STATUS_DRAFT
+		input.Status = &status
+	}
+
+	post := blogstore.NewPost()
+
+	if errorMessage := postApplyInput(post, input); errorMessage != "" {
+		respondError(w, http.StatusUnprocessableEntity, errorMessage)
+		return
+	}
+
+	if err := controller.app.GetBlogStore().PostCreate(r.Context(), post); err != nil {
+		controller.app.GetLogger().Error("At blogPostsController > Create > PostCreate", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Post could not be created")
+		return
+	}
+
+	search_reindex.Changed(r.Context(), controller.app, searchindex.DOCUMENT_TYPE_POST, post.GetID())
+
+	respondItem(w, http.StatusCreated, postToMap(post))
+}
+
+// Update updates the fields of a post which are sent
+func (controller *blogPostsController) Update(w http.ResponseWriter, r *http.Request) {
+	post := controller.findPost(w, r)
+
+	if post == nil {
+		return
+	}
+
+	input := postInput{}
+
+	if errorMessage := decodeBody(w, r, &input); errorMessage != "" {
+		respondError(w, http.StatusBadRequest, errorMessage)
+		return
+	}
+
+	if input.Title != nil && strings.TrimSpace(*input.Title) == "" {
+		respondError(w, http.StatusUnprocessableEntity, "title cannot be empty")
+		return
+	}
+
+	if errorMessage := postApplyInput(post, input); errorMessage != "" {
+		respondError(w, http.StatusUnprocessableEntity, errorMessage)
+		return
+	}
+
+	if err := controller.app.GetBlogStore().PostUpdate(r.Context(), post); err != nil {
+		controller.app.GetLogger().Error("At blogPostsController > Update > PostUpdate", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Post could not be updated")
+		return
+	}
+
+	search_reindex.Changed(r.Context(), controller.app, searchindex.DOCUMENT_TYPE_POST, post.GetID())
+
+	respondItem(w, http.StatusOK, postToMap(post))
+}
+
+// == PRIVATE METHODS ==========================================================
+
+// findPost finds the post of the path, or responds with an error and
+// returns nil. The users cannot see the posts which are not published.
+func (controller *blogPostsController) findPost(w http.ResponseWriter, r *http.Request) blogstore.PostInterface {
+	postID, _ := rtr.GetParam(r, "id")
+
+	post, err := controller.app.GetBlogStore().PostFindByID(r.Context(), postID)
+	if err != nil {
+		controller.app.GetLogger().Error("At blogPostsController > findPost > PostFindByID", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Post could not be loaded")
+		return nil
+	}
+
+	if post == nil || (post.GetStatus() != blogstore.POST_STATUS_PUBLISHED && !isAdministrator(r)) {
+		respondError(w, http.StatusNotFound, "Post not found")
+		return nil
+	}
+
+	return post
+}
+
+// postApplyInput sets the fields of the post which are sent, or returns an
+// error message
+func postApplyInput(post blogstore.PostInterface, input postInput) string {
+	if input.Status != nil && !slices.Contains(postStatuses, *input.Status) {
+		return "status must be one of " + strings.Join(postStatuses, ", ")
+	}
+
+	if input.Title != nil {
+		post.SetTitle(strings.TrimSpace(*input.Title))
+	}
+
+	if input.Slug != nil {
+		post.SetSlug(strings.TrimSpace(*input.Slug))
+	}
+
+	if input.Summary != nil {
+		post.SetSummary(*input.Summary)
+	}
+
+	if input.Content != nil {
+		post.SetContent(*input.Content)
+	}
+
+	if input.Status != nil {
+		post.SetStatus(*input.Status)
+	}
+
+	return ""
+}
+
+func postToMap(post blogstore.PostInterface) map[string]any {
+	return map[string]any{
+		"id":           post.GetID(),
+		"title":        post.GetTitle(),
+		"slug":         post.GetSlug(),
+		"summary":      post.GetSummary(),
+		"content":      post.GetContent(),
+		"status":       post.GetStatus(),
+		"published_at": post.GetPublishedAt(),
+		"created_at":   post.GetCreatedAt(),
+		"updated_at":   post.GetUpdatedAt(),
+	}
+}
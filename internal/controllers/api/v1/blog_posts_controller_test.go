@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"project/internal/apitoken"
+	"project/internal/app"
+	"project/internal/links"
+	"project/internal/testutils"
+
+	"github.com/dracory/blogstore"
+	"github.com/dracory/test"
+)
+
+func seedBlogPost(t *testing.T, app app.AppInterface, title, status string) blogstore.PostInterface {
+	t.Helper()
+
+	post := blogstore.NewPost()
+	post.SetTitle(title)
+	post.SetStatus(status)
+
+	if err := app.GetBlogStore().PostCreate(context.Background(), post); err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+
+	return post
+}
+
+func TestBlogPostsController_ListPublishedOnly(t *testing.T) {
+	app := setupAPIApp(t, testutils.WithBlogStore(true))
+	token := seedAPIToken(t, app, test.USER_01, apitoken.SCOPE_BLOG_READ)
+
+	seedBlogPost(t, app, "Published", blogstore.POST_STATUS_PUBLISHED)
+	draft := seedBlogPost(t, app, "Draft", blogstore.POST_STATUS_DRAFT)
+
+	res, response := callAPI(t, app, http.MethodGet, links.API_V1+"/blog/posts", token, "")
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.Code, res.Body.String())
+	}
+
+	items := responseData(t, response)["items"].([]any)
+	if len(items) != 1 || items[0].(map[string]any)["title"] != "Published" {
+		t.Fatalf("expected the published post only, got %v", items)
+	}
+
+	res, _ = callAPI(t, app, http.MethodGet, links.API_V1+"/blog/posts/"+draft.GetID(), token, "")
+
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected the draft not to be found, got %d", res.Code)
+	}
+}
+
+func TestBlogPostsController_ListAllForAdministrators(t *testing.T) {
+	app := setupAPIApp(t, testutils.WithBlogStore(true))
+	token := seedAPIToken(t, app, test.ADMIN_01, apitoken.SCOPE_BLOG_READ)
+
+	seedBlogPost(t, app, "Published", blogstore.POST_STATUS_PUBLISHED)
+	seedBlogPost(t, app, "Draft", blogstore.POST_STATUS_DRAFT)
+
+	_, response := callAPI(t, app, http.MethodGet, links.API_V1+"/blog/posts", token, "")
+	if items := responseData(t, response)["items"].([]any); len(items) != 2 {
+		t.Fatalf("expected all the posts, got %d", len(items))
+	}
+
+	_, response = callAPI(t, app, http.MethodGet, links.API_V1+"/blog/posts?status="+blogstore.POST_STATUS_DRAFT, token, "")
+	if items := responseData(t, response)["items"].([]any); len(items) != 1 {
+		t.Fatalf("expected the draft post, got %d", len(items))
+	}
+}
+
+func TestBlogPostsController_CreateAndUpdate(t *testing.T) {
+	app := setupAPIApp(t, testutils.WithBlogStore(true))
+	token := seedAPIToken(t, app, test.ADMIN_01, apitoken.SCOPE_BLOG_READ, apitoken.SCOPE_BLOG_WRITE)
+
+	res, response := callAPI(t, app, http.MethodPost, links.API_V1+"/blog/posts", token, `{"title":"Hello","content":"World"}`)
+
+	if res.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, res.Code, res.Body.String())
+	}
+
+	item := responseData(t, response)["item"].(map[string]any)
+	if item["title"] != "Hello" || item["status"] != blogstore.POST_STATUS_DRAFT {
+		t.Fatalf("expected a draft post, got %v", item)
+	}
+
+	postID := item["id"].(string)
+
+	res, response = callAPI(t, app, http.MethodPatch, links.API_V1+"/blog/posts/"+postID, token, `{"status":"`+blogstore.POST_STATUS_PUBLISHED+`"}`)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.Code, res.Body.String())
+	}
+
+	item = responseData(t, response)["item"].(map[string]any)
+	if item["title"] != "Hello" || item["status"] != blogstore.POST_STATUS_PUBLISHED {
+		t.Fatalf("expected the post to be published, unchanged otherwise, got %v", item)
+	}
+}
+
+func TestBlogPostsController_CreateValidation(t *testing.T) {
+	app := setupAPIApp(t, testutils.WithBlogStore(true))
+	token := seedAPIToken(t, app, test.ADMIN_01, apitoken.SCOPE_BLOG_WRITE)
+
+	cases := []struct {
+		body           string
+		expectedStatus int
+	}{
+		{`not json`, http.StatusBadRequest},
+		{`{"title":"Hello","unknown":1}`, http.StatusBadRequest},
+		{`{"content":"No title"}`, http.StatusUnprocessableEntity},
+		{`{"title":"Hello","status":"archived"}`, http.StatusUnprocessableEntity},
+	}
+
+	for _, c := range cases {
+		res, _ := callAPI(t, app, http.MethodPost, links.API_V1+"/blog/posts", token, c.body)
+
+		if res.Code != c.expectedStatus {
+			t.Errorf("%s: status = %d, want %d", c.body, res.Code, c.expectedStatus)
+		}
+	}
+}
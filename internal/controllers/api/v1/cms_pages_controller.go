@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/apitoken"
+	"project/internal/app"
+
+	"github.com/dracory/cmsstore"
+	"github.com/dracory/rtr"
+)
+
+// == CONTROLLER ===============================================================
+
+// cmsPagesController serves the pages of the CMS to the administrators
+type cmsPagesController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR ==============================================================
+
+// NewCmsPagesController creates a new CMS pages controller
+func NewCmsPagesController(app app.AppInterface) *cmsPagesController {
+	return &cmsPagesController{app: app}
+}
+
+func cmsPagesEndpoints(app app.AppInterface) []Endpoint {
+	controller := NewCmsPagesController(app)
+
+	return []Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    "/cms/pages",
+			Tag:     "CMS",
+			Summary: "List the CMS pages",
+			Scope:   apitoken.SCOPE_CMS_READ,
+			Parameters: append(paginationParameters(), Parameter{
+				Name:        "status",
+				In:          PARAMETER_IN_QUERY,
+				Type:        "string",
+				Description: "Only the pages with the status, e.g. " + cmsstore.PAGE_STATUS_ACTIVE,
+			}),
+			Handler: controller.List,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "/cms/pages/:id",
+			Tag:        "CMS",
+			Summary:    "Get a CMS page",
+			Scope:      apitoken.SCOPE_CMS_READ,
+			Parameters: []Parameter{idParameter("The ID of the page")},
+			Handler:    controller.Get,
+		},
+	}
+}
+
+// == PUBLIC METHODS ===========================================================
+
+// List returns a page of the CMS pages, last updated first
+func (controller *cmsPagesController) List(w http.ResponseWriter, r *http.Request) {
+	page, errorMessage := paginationFromRequest(r)
+	if errorMessage != "" {
+		respondError(w, http.StatusBadRequest, errorMessage)
+		return
+	}
+
+	query := cmsstore.PageQuery().
+		SetOrderBy(cmsstore.COLUMN_UPDATED_AT).
+		SetSortOrder("DESC").
+		SetOffset(page.offset()).
+		SetLimit(page.perPage)
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		query.SetStatus(status)
+	}
+
+	cmsPages, err := controller.app.GetCmsStore().PageList(r.Context(), query)
+	if err != nil {
+		controller.app.GetLogger().Error("At cmsPagesController > List > PageList", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Pages could not be loaded")
+		return
+	}
+
+	total, err := controller.app.GetCmsStore().PageCount(r.Context(), query)
+	if err != nil {
+		controller.app.GetLogger().Error("At cmsPagesController > List > PageCount", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Pages could not be loaded")
+		return
+	}
+
+	items := make([]map[string]any, 0, len(cmsPages))
+
+	for _, cmsPage := range cmsPages {
+		items = append(items, cmsPageToMap(cmsPage))
+	}
+
+	respondList(w, items, page, total)
+}
+
+// Get returns a CMS page
+func (controller *cmsPagesController) Get(w http.ResponseWriter, r *http.Request) {
+	pageID, _ := rtr.GetParam(r, "id")
+
+	cmsPage, err := controller.app.GetCmsStore().PageFindByID(r.Context(), pageID)
+	if err != nil {
+		controller.app.GetLogger().Error("At cmsPagesController > Get > PageFindByID", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Page could not be loaded")
+		return
+	}
+
+	if cmsPage == nil {
+		respondError(w, http.StatusNotFound, "Page not found")
+		return
+	}
+
+	respondItem(w, http.StatusOK, cmsPageToMap(cmsPage))
+}
+
+// == PRIVATE METHODS ==========================================================
+
+func cmsPageToMap(cmsPage cmsstore.PageInterface) map[string]any {
+	return map[string]any{
+		"id":         cmsPage.ID(),
+		"site_id":    cmsPage.SiteID(),
+		"title":      cmsPage.Title(),
+		"alias":      cmsPage.Alias(),
+		"content":    cmsPage.Content(),
+		"status":     cmsPage.Status(),
+		"created_at": cmsPage.CreatedAt(),
+		"updated_at": cmsPage.UpdatedAt(),
+	}
+}
@@ -0,0 +1,15 @@
+// Package v1 is the versioned JSON REST API, served under /api/v1.
+//
+// Conventions:
+//   - the clients authenticate with a personal API token, sent in the
+//     Authorization header ("Bearer <token>"), and each endpoint requires
+//     a scope of the token (see the apitoken package)
+//   - the responses use the github.com/dracory/api envelope
+//     ({"status": "success|error", "message": "...", "data": {...}}),
+//     with the HTTP status code matching the status
+//   - the lists are paginated with the "page" (from 1) and "per_page"
+//     query parameters, and return the "items" and the "pagination"
+//   - the endpoints are described once, in Endpoints, which both the
+//     router and the OpenAPI document (/api/v1/openapi.json) are built
+//     from, so the documentation cannot drift from the code
+package v1
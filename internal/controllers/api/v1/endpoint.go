@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"net/http"
+	"project/internal/apitoken"
+	"project/internal/app"
+	"project/internal/helpers"
+	"strings"
+)
+
+// Parameter locations
+const (
+	PARAMETER_IN_PATH  = "path"
+	PARAMETER_IN_QUERY = "query"
+	PARAMETER_IN_BODY  = "body"
+)
+
+// Parameter describes a path, query or JSON body parameter of an endpoint
+type Parameter struct {
+	Name        string
+	In          string
+	Type        string // string, integer, number or boolean
+	Description string
+	Enum        []string
+	Required    bool
+}
+
+// Endpoint describes an API endpoint: the router registers its handler,
+// and the OpenAPI document describes it, from this single definition
+type Endpoint struct {
+	Method      string
+	Path        string // relative to /api/v1, with :name path parameters
+	Tag         string
+	Summary     string
+	Description string
+	Scope       string
+	Parameters  []Parameter
+	Handler     http.HandlerFunc
+}
+
+// Endpoints returns the endpoints of the API, for the stores which are
+// enabled
+func Endpoints(app app.AppInterface) []Endpoint {
+	endpoints := []Endpoint{}
+
+	if app.GetUserStore() != nil {
+		endpoints = append(endpoints, meEndpoints(app)...)
+		endpoints = append(endpoints, usersEndpoints(app)...)
+	}
+
+	if app.GetBlogStore() != nil {
+		endpoints = append(endpoints, blogPostsEndpoints(app)...)
+	}
+
+	if app.GetCmsStore() != nil {
+		endpoints = append(endpoints, cmsPagesEndpoints(app)...)
+	}
+
+	if app.GetShopStore() != nil {
+		endpoints = append(endpoints, shopProductsEndpoints(app)...)
+		endpoints = append(endpoints, shopOrdersEndpoints(app)...)
+	}
+
+	if app.GetTaskStore() != nil {
+		endpoints = append(endpoints, tasksEndpoints(app)...)
+	}
+
+	return endpoints
+}
+
+// pathParameters returns the names of the path parameters, i.e. the
+// segments of the path starting with ":"
+func (endpoint Endpoint) pathParameters() []string {
+	names := []string{}
+
+	for _, segment := range strings.Split(endpoint.Path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			names = append(names, strings.TrimPrefix(segment, ":"))
+		}
+	}
+
+	return names
+}
+
+// == SHARED PARAMETERS ========================================================
+
+func paginationParameters() []Parameter {
+	return []Parameter{
+		{Name: "page", In: PARAMETER_IN_QUERY, Type: "integer", Description: "The page, from 1"},
+		{Name: "per_page", In: PARAMETER_IN_QUERY, Type: "integer", Description: "The number of items per page, up to 100 (default 20)"},
+	}
+}
+
+func idParameter(description string) Parameter {
+	return Parameter{Name: "id", In: PARAMETER_IN_PATH, Type: "string", Description: description, Required: true}
+}
+
+// isAdministrator returns whether the user of the API token is an
+// administrator, who can see all the items (e.g. the draft posts, or the
+// orders of the other customers)
+func isAdministrator(r *http.Request) bool {
+	user := helpers.GetAPIAuthUser(r)
+
+	return user != nil && (user.IsAdministrator() || user.IsSuperuser())
+}
+
+// scopeDescription returns the sentence documenting the scope of an endpoint
+func scopeDescription(scope string) string {
+	description := "Requires the `" + scope + "` scope"
+
+	if apiScope := apitoken.ScopeFind(scope); apiScope != nil && apiScope.AdminOnly {
+		description += ", which is for administrators only"
+	}
+
+	return description + "."
+}
+
+// requireParameter returns a copy of the parameters, with the parameter
+// of the name required
+func requireParameter(parameters []Parameter, name string) []Parameter {
+	required := make([]Parameter, len(parameters))
+	copy(required, parameters)
+
+	for i := range required {
+		if required[i].Name == name {
+			required[i].Required = true
+		}
+	}
+
+	return required
+}
@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+
+	"project/internal/apitoken"
+	"project/internal/testutils"
+)
+
+func TestEndpoints_FollowEnabledStores(t *testing.T) {
+	app := testutils.Setup(testutils.WithUserStore(true))
+
+	for _, endpoint := range Endpoints(app) {
+		if endpoint.Tag != "Profile" && endpoint.Tag != "Users" {
+			t.Errorf("unexpected endpoint %s %s without its store", endpoint.Method, endpoint.Path)
+		}
+	}
+}
+
+func TestEndpoints_AreValid(t *testing.T) {
+	app := setupAPIApp(t,
+		testutils.WithBlogStore(true),
+		testutils.WithShopStore(true),
+		testutils.WithTaskStore(true),
+	)
+
+	seen := map[string]bool{}
+
+	for _, endpoint := range Endpoints(app) {
+		key := endpoint.Method + " " + endpoint.Path
+
+		if seen[key] {
+			t.Errorf("%s is defined twice", key)
+		}
+		seen[key] = true
+
+		if apitoken.ScopeFind(endpoint.Scope) == nil {
+			t.Errorf("%s has the unknown scope %q", key, endpoint.Scope)
+		}
+
+		if endpoint.Handler == nil || endpoint.Summary == "" || endpoint.Tag == "" {
+			t.Errorf("%s needs a handler, a summary and a tag", key)
+		}
+
+		for _, name := range endpoint.pathParameters() {
+			found := false
+			for _, parameter := range endpoint.Parameters {
+				found = found || (parameter.Name == name && parameter.In == PARAMETER_IN_PATH)
+			}
+
+			if !found {
+				t.Errorf("%s does not document the path parameter %s", key, name)
+			}
+		}
+	}
+
+	if !seen[http.MethodPost+" /blog/posts"] || !seen[http.MethodGet+" /shop/orders/:id"] {
+		t.Error("expected the blog and shop endpoints")
+	}
+}
+
+func TestRequireParameter(t *testing.T) {
+	parameters := []Parameter{{Name: "title"}, {Name: "slug"}}
+
+	required := requireParameter(parameters, "title")
+
+	if !required[0].Required || required[1].Required {
+		t.Errorf("expected only the title to be required, got %+v", required)
+	}
+
+	if parameters[0].Required {
+		t.Error("expected the parameters not to be changed")
+	}
+}
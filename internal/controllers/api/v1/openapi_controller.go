@@ -0,0 +1,235 @@
+package v1
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/links"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// OPENAPI_VERSION is the version of the OpenAPI specification used
+const OPENAPI_VERSION = "3.0.3"
+
+// == CONTROLLER ===============================================================
+
+// openAPIController serves the OpenAPI document of the API, generated from
+// the endpoints, which the Swagger UI (/swagger) shows
+type openAPIController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR ==============================================================
+
+// NewOpenAPIController creates a new OpenAPI controller
+func NewOpenAPIController(app app.AppInterface) *openAPIController {
+	return &openAPIController{app: app}
+}
+
+// == PUBLIC METHODS ===========================================================
+
+// Handler writes the OpenAPI document as JSON
+func (controller *openAPIController) Handler(w http.ResponseWriter, r *http.Request) {
+	document, err := json.Marshal(OpenAPIDocument(controller.app, Endpoints(controller.app)))
+	if err != nil {
+		controller.app.GetLogger().Error("At openAPIController > Handler", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "The OpenAPI document could not be generated")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if _, err := w.Write(document); err != nil {
+		controller.app.GetLogger().Error("At openAPIController > Handler > Write", slog.String("error", err.Error()))
+	}
+}
+
+// OpenAPIDocument returns the OpenAPI document describing the endpoints
+func OpenAPIDocument(app app.AppInterface, endpoints []Endpoint) map[string]any {
+	paths := map[string]any{}
+	tags := []string{}
+
+	for _, endpoint := range endpoints {
+		path := openAPIPath(endpoint.Path)
+
+		if _, exists := paths[path]; !exists {
+			paths[path] = map[string]any{}
+		}
+
+		paths[path].(map[string]any)[strings.ToLower(endpoint.Method)] = openAPIOperation(endpoint)
+
+		if !slices.Contains(tags, endpoint.Tag) {
+			tags = append(tags, endpoint.Tag)
+		}
+	}
+
+	tagList := []map[string]any{}
+
+	for _, tag := range tags {
+		tagList = append(tagList, map[string]any{"name": tag})
+	}
+
+	return map[string]any{
+		"openapi": OPENAPI_VERSION,
+		"info": map[string]any{
+			"title":       app.GetConfig().GetAppName() + " API",
+			"version":     "1",
+			"description": "Authenticate with a personal API token, created on the API Tokens page of your account, sent as `Authorization: Bearer <token>`.",
+		},
+		"servers": []map[string]any{
+			{"url": strings.TrimRight(app.GetConfig().GetAppUrl(), "/") + links.API_V1},
+		},
+		"tags":  tagList,
+		"paths": paths,
+		"security": []map[string]any{
+			{"bearerAuth": []string{}},
+		},
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":   "http",
+					"scheme": "bearer",
+				},
+			},
+			"schemas": map[string]any{
+				"Response": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"status":  map[string]any{"type": "string", "enum": []string{"success", "error"}},
+						"message": map[string]any{"type": "string"},
+						"data":    map[string]any{"type": "object"},
+					},
+				},
+			},
+		},
+	}
+}
+
+// == PRIVATE METHODS ==========================================================
+
+// openAPIPath converts the :name path parameters to {name}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// openAPIOperation describes the endpoint
+func openAPIOperation(endpoint Endpoint) map[string]any {
+	description := scopeDescription(endpoint.Scope)
+
+	if endpoint.Description != "" {
+		description = endpoint.Description + "\n\n" + description
+	}
+
+	parameters := []map[string]any{}
+	bodyProperties := map[string]any{}
+	bodyRequired := []string{}
+
+	for _, parameter := range endpoint.Parameters {
+		schema := map[string]any{"type": parameter.Type}
+
+		if len(parameter.Enum) > 0 {
+			schema["enum"] = parameter.Enum
+		}
+
+		if parameter.In == PARAMETER_IN_BODY {
+			schema["description"] = parameter.Description
+			bodyProperties[parameter.Name] = schema
+
+			if parameter.Required {
+				bodyRequired = append(bodyRequired, parameter.Name)
+			}
+
+			continue
+		}
+
+		parameters = append(parameters, map[string]any{
+			"name":        parameter.Name,
+			"in":          parameter.In,
+			"description": parameter.Description,
+			"required":    parameter.Required || parameter.In == PARAMETER_IN_PATH,
+			"schema":      schema,
+		})
+	}
+
+	successStatus := http.StatusOK
+
+	if endpoint.Method == http.MethodPost {
+		successStatus = http.StatusCreated
+	}
+
+	responses := map[string]any{
+		strconv.Itoa(successStatus):           openAPIResponse("Success"),
+		strconv.Itoa(http.StatusBadRequest):   openAPIResponse("Invalid parameters"),
+		strconv.Itoa(http.StatusUnauthorized): openAPIResponse("Missing, invalid or expired token"),
+		strconv.Itoa(http.StatusForbidden):    openAPIResponse("The token does not have the " + endpoint.Scope + " scope"),
+	}
+
+	if len(endpoint.pathParameters()) > 0 {
+		responses[strconv.Itoa(http.StatusNotFound)] = openAPIResponse("Not found")
+	}
+
+	operation := map[string]any{
+		"operationId": openAPIOperationID(endpoint),
+		"tags":        []string{endpoint.Tag},
+		"summary":     endpoint.Summary,
+		"description": description,
+		"parameters":  parameters,
+		"responses":   responses,
+	}
+
+	if len(bodyProperties) > 0 {
+		schema := map[string]any{
+			"type":       "object",
+			"properties": bodyProperties,
+		}
+
+		if len(bodyRequired) > 0 {
+			schema["required"] = bodyRequired
+		}
+
+		operation["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": schema},
+			},
+		}
+
+		responses[strconv.Itoa(http.StatusUnprocessableEntity)] = openAPIResponse("Invalid fields")
+	}
+
+	return operation
+}
+
+// openAPIOperationID returns a unique ID for the endpoint, e.g.
+// getBlogPostsId for GET /blog/posts/:id
+func openAPIOperationID(endpoint Endpoint) string {
+	operationID := strings.ToLower(endpoint.Method)
+
+	for _, part := range strings.FieldsFunc(endpoint.Path, func(r rune) bool { return r == '/' || r == ':' || r == '_' }) {
+		operationID += strings.ToUpper(part[:1]) + part[1:]
+	}
+
+	return operationID
+}
+
+func openAPIResponse(description string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content": map[string]any{
+			"application/json": map[string]any{
+				"schema": map[string]any{"$ref": "#/components/schemas/Response"},
+			},
+		},
+	}
+}
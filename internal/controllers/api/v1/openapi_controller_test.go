@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"project/internal/apitoken"
+	"project/internal/links"
+	"project/internal/testutils"
+)
+
+func TestOpenAPIDocument_DescribesEndpoints(t *testing.T) {
+	app := setupAPIApp(t, testutils.WithBlogStore(true))
+
+	document := OpenAPIDocument(app, Endpoints(app))
+
+	paths := document["paths"].(map[string]any)
+
+	for _, endpoint := range Endpoints(app) {
+		operations, ok := paths[openAPIPath(endpoint.Path)].(map[string]any)
+		if !ok {
+			t.Fatalf("expected the path of %s %s", endpoint.Method, endpoint.Path)
+		}
+
+		if _, ok := operations[map[string]string{
+			http.MethodGet:   "get",
+			http.MethodPost:  "post",
+			http.MethodPatch: "patch",
+		}[endpoint.Method]]; !ok {
+			t.Errorf("expected the operation %s %s", endpoint.Method, endpoint.Path)
+		}
+	}
+
+	servers := document["servers"].([]map[string]any)
+	if servers[0]["url"] != strings.TrimRight(app.GetConfig().GetAppUrl(), "/")+links.API_V1 {
+		t.Errorf("unexpected server %v", servers[0]["url"])
+	}
+}
+
+func TestOpenAPIOperation(t *testing.T) {
+	operation := openAPIOperation(Endpoint{
+		Method:  http.MethodPost,
+		Path:    "/blog/posts/:id",
+		Tag:     "Blog",
+		Summary: "Update",
+		Scope:   apitoken.SCOPE_BLOG_WRITE,
+		Parameters: []Parameter{
+			idParameter("The ID"),
+			{Name: "title", In: PARAMETER_IN_BODY, Type: "string", Required: true},
+		},
+	})
+
+	if operation["operationId"] != "postBlogPostsId" {
+		t.Errorf("operationId = %v, want postBlogPostsId", operation["operationId"])
+	}
+
+	if parameters := operation["parameters"].([]map[string]any); len(parameters) != 1 || parameters[0]["in"] != PARAMETER_IN_PATH {
+		t.Errorf("expected only the path parameter, got %v", parameters)
+	}
+
+	if _, ok := operation["requestBody"]; !ok {
+		t.Error("expected the body parameters in the request body")
+	}
+
+	responses := operation["responses"].(map[string]any)
+	for _, status := range []string{"201", "401", "403", "404", "422"} {
+		if _, ok := responses[status]; !ok {
+			t.Errorf("expected the %s response", status)
+		}
+	}
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	if path := openAPIPath("/shop/orders/:id"); path != "/shop/orders/{id}" {
+		t.Errorf("openAPIPath = %q, want /shop/orders/{id}", path)
+	}
+}
@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dracory/api"
+)
+
+// Pagination defaults and limits of the lists
+const (
+	PER_PAGE_DEFAULT = 20
+	PER_PAGE_MAX     = 100
+)
+
+// maxBodyBytes limits the size of the accepted JSON request bodies
+const maxBodyBytes = 1 << 20
+
+// pagination is the page of a list requested by the client
+type pagination struct {
+	page    int
+	perPage int
+}
+
+// offset returns the number of items before the page
+func (p pagination) offset() int {
+	return (p.page - 1) * p.perPage
+}
+
+// toMap returns the pagination of the response, with the total number of
+// items and pages
+func (p pagination) toMap(total int64) map[string]any {
+	totalPages := (total + int64(p.perPage) - 1) / int64(p.perPage)
+
+	return map[string]any{
+		"page":        p.page,
+		"per_page":    p.perPage,
+		"total":       total,
+		"total_pages": totalPages,
+	}
+}
+
+// paginationFromRequest reads the "page" and "per_page" query parameters,
+// or returns an error message for invalid values
+func paginationFromRequest(r *http.Request) (pagination, string) {
+	p := pagination{page: 1, perPage: PER_PAGE_DEFAULT}
+
+	if value := r.URL.Query().Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+
+		if err != nil || page < 1 {
+			return p, "page must be a number from 1"
+		}
+
+		p.page = page
+	}
+
+	if value := r.URL.Query().Get("per_page"); value != "" {
+		perPage, err := strconv.Atoi(value)
+
+		if err != nil || perPage < 1 || perPage > PER_PAGE_MAX {
+			return p, "per_page must be a number from 1 to " + strconv.Itoa(PER_PAGE_MAX)
+		}
+
+		p.perPage = perPage
+	}
+
+	return p, ""
+}
+
+// respondList writes a page of items
+func respondList(w http.ResponseWriter, items []map[string]any, p pagination, total int64) {
+	respond(w, http.StatusOK, api.SuccessWithData("", map[string]any{
+		"items":      items,
+		"pagination": p.toMap(total),
+	}))
+}
+
+// respondItem writes a single item, with the status (e.g. 201 when created)
+func respondItem(w http.ResponseWriter, status int, item map[string]any) {
+	respond(w, status, api.SuccessWithData("", map[string]any{
+		"item": item,
+	}))
+}
+
+// respondError writes the error message, with the status
+func respondError(w http.ResponseWriter, status int, message string) {
+	respond(w, status, api.Error(message))
+}
+
+func respond(w http.ResponseWriter, status int, response interface{ ToString() string }) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if _, err := w.Write([]byte(response.ToString())); err != nil {
+		return
+	}
+}
+
+// decodeBody decodes the JSON request body into the value, or returns an
+// error message
+func decodeBody(w http.ResponseWriter, r *http.Request, value any) string {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(value); err != nil {
+		return "The request body must be a valid JSON object: " + err.Error()
+	}
+
+	return ""
+}
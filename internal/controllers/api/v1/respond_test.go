@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPaginationFromRequest(t *testing.T) {
+	cases := []struct {
+		query           string
+		expectedPage    int
+		expectedPerPage int
+		expectedError   bool
+	}{
+		{"", 1, PER_PAGE_DEFAULT, false},
+		{"?page=3&per_page=50", 3, 50, false},
+		{"?page=0", 1, PER_PAGE_DEFAULT, true},
+		{"?page=abc", 1, PER_PAGE_DEFAULT, true},
+		{"?per_page=101", 1, PER_PAGE_DEFAULT, true},
+	}
+
+	for _, c := range cases {
+		p, errorMessage := paginationFromRequest(httptest.NewRequest(http.MethodGet, "/"+c.query, nil))
+
+		if (errorMessage != "") != c.expectedError {
+			t.Errorf("%q: error = %q, want error %v", c.query, errorMessage, c.expectedError)
+		}
+
+		if !c.expectedError && (p.page != c.expectedPage || p.perPage != c.expectedPerPage) {
+			t.Errorf("%q: page = %d, per_page = %d, want %d, %d", c.query, p.page, p.perPage, c.expectedPage, c.expectedPerPage)
+		}
+	}
+}
+
+func TestPagination_ToMap(t *testing.T) {
+	p := pagination{page: 2, perPage: 20}
+
+	if p.offset() != 20 {
+		t.Errorf("offset = %d, want 20", p.offset())
+	}
+
+	result := p.toMap(41)
+
+	if result["total"] != int64(41) || result["total_pages"] != int64(3) {
+		t.Errorf("unexpected pagination %v", result)
+	}
+
+	if result := p.toMap(0); result["total_pages"] != int64(0) {
+		t.Errorf("expected no pages without items, got %v", result)
+	}
+}
+
+func TestRespondError(t *testing.T) {
+	res := httptest.NewRecorder()
+
+	respondError(res, http.StatusNotFound, "Post not found")
+
+	if res.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", res.Code, http.StatusNotFound)
+	}
+
+	if res.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", res.Header().Get("Content-Type"))
+	}
+}
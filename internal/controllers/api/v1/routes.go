@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"net/http"
+	"project/internal/app"
+	"project/internal/links"
+	"project/internal/middlewares"
+	"project/internal/ratelimit"
+
+	"github.com/dracory/rtr"
+)
+
+// Routes returns the routes of the API v1: one per endpoint, behind the
+// CORS, token and scope middlewares, and the public OpenAPI document
+func Routes(app app.AppInterface) []rtr.RouteInterface {
+	if app == nil || app.GetConfig() == nil {
+		return []rtr.RouteInterface{}
+	}
+
+	openAPIRoute := rtr.NewRoute().
+		SetName("API > V1 > OpenAPI").
+		SetMethod(http.MethodGet).
+		SetPath(links.API_V1_OPENAPI).
+		SetHandler(NewOpenAPIController(app).Handler)
+
+	openAPIRoute.AddBeforeMiddlewares([]rtr.MiddlewareInterface{
+		middlewares.NewAPICorsMiddleware(app),
+	})
+
+	routes := []rtr.RouteInterface{openAPIRoute}
+
+	// The tokens are kept in the custom store, and belong to the users
+	if app.GetCustomStore() == nil || app.GetUserStore() == nil {
+		return routes
+	}
+
+	for _, endpoint := range Endpoints(app) {
+		route := rtr.NewRoute().
+			SetName("API > V1 > " + endpoint.Method + " " + endpoint.Path).
+			SetMethod(endpoint.Method).
+			SetPath(links.API_V1 + endpoint.Path).
+			SetHandler(endpoint.Handler)
+
+		route.AddBeforeMiddlewares([]rtr.MiddlewareInterface{
+			middlewares.NewAPICorsMiddleware(app),
+			middlewares.NewAPIAuthMiddleware(app),
+			middlewares.NewRateLimitMiddleware(app, ratelimit.GROUP_API),
+			middlewares.NewAPIScopeMiddleware(endpoint.Scope),
+		})
+
+		routes = append(routes, route)
+	}
+
+	// The browsers send the preflight requests without the token, so they
+	// are answered by the CORS middleware
+	preflightRoute := rtr.NewRoute().
+		SetName("API > V1 > CORS Preflight").
+		SetMethod(http.MethodOptions).
+		SetPath(links.API_V1 + links.CATCHALL).
+		SetHandler(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+	preflightRoute.AddBeforeMiddlewares([]rtr.MiddlewareInterface{
+		middlewares.NewAPICorsMiddleware(app),
+	})
+
+	return append(routes, preflightRoute)
+}
@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"project/internal/apitoken"
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/links"
+	"project/internal/testutils"
+
+	"github.com/dracory/rtr"
+	"github.com/dracory/test"
+)
+
+// setupAPIApp creates an app with the stores needed to authenticate, and
+// the extra stores of the test
+func setupAPIApp(t *testing.T, options ...testutils.SetupOption) app.AppInterface {
+	t.Helper()
+
+	options = append([]testutils.SetupOption{
+		testutils.WithCacheStore(true),
+		testutils.WithCustomStore(true),
+		testutils.WithUserStore(true),
+	}, options...)
+
+	return testutils.Setup(options...)
+}
+
+// seedAPIToken creates a token with the scopes for the user, seeding the
+// user if needed
+func seedAPIToken(t *testing.T, app app.AppInterface, userID string, scopes ...string) string {
+	t.Helper()
+
+	user, err := testutils.SeedUser(app.GetUserStore(), userID)
+	if err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+
+	token, _, err := ext.APITokenCreate(app, user, "Test", scopes, time.Time{})
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+
+	return token
+}
+
+// callAPI serves the request through the API routes, and returns the
+// response with its decoded JSON body
+func callAPI(t *testing.T, app app.AppInterface, method, path, token, body string) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+
+	router := rtr.NewRouter()
+	for _, route := range Routes(app) {
+		router.AddRoute(route)
+	}
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	response := map[string]any{}
+	if res.Body.Len() > 0 {
+		if err := json.Unmarshal(res.Body.Bytes(), &response); err != nil {
+			t.Fatalf("expected a JSON response, got %q", res.Body.String())
+		}
+	}
+
+	return res, response
+}
+
+// responseData returns the data of the response envelope
+func responseData(t *testing.T, response map[string]any) map[string]any {
+	t.Helper()
+
+	data, ok := response["data"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected data in the response, got %v", response)
+	}
+
+	return data
+}
+
+func TestRoutes_OpenAPIIsPublic(t *testing.T) {
+	app := setupAPIApp(t)
+
+	res, response := callAPI(t, app, http.MethodGet, links.API_V1_OPENAPI, "", "")
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, res.Code)
+	}
+
+	if response["openapi"] != OPENAPI_VERSION {
+		t.Fatalf("expected the OpenAPI document, got %v", response)
+	}
+}
+
+func TestRoutes_RequireToken(t *testing.T) {
+	app := setupAPIApp(t)
+
+	res, response := callAPI(t, app, http.MethodGet, links.API_V1+"/me", "", "")
+
+	if res.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d, got %d", http.StatusUnauthorized, res.Code)
+	}
+
+	if response["status"] != "error" {
+		t.Fatalf("expected an error envelope, got %v", response)
+	}
+}
+
+func TestRoutes_RequireScope(t *testing.T) {
+	app := setupAPIApp(t)
+	token := seedAPIToken(t, app, test.USER_01, apitoken.SCOPE_BLOG_READ)
+
+	res, _ := callAPI(t, app, http.MethodGet, links.API_V1+"/me", token, "")
+
+	if res.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d", http.StatusForbidden, res.Code)
+	}
+}
+
+func TestRoutes_Preflight(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetAPICorsAllowedOrigins([]string{"https://spa.example.com"})
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	router := rtr.NewRouter()
+	for _, route := range Routes(app) {
+		router.AddRoute(route)
+	}
+
+	req := httptest.NewRequest(http.MethodOptions, links.API_V1+"/me", nil)
+	req.Header.Set("Origin", "https://spa.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, res.Code)
+	}
+
+	if res.Header().Get("Access-Control-Allow-Origin") != "https://spa.example.com" {
+		t.Fatalf("expected the origin to be allowed, got %q", res.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestRoutes_WithoutTokenStore(t *testing.T) {
+	app := testutils.Setup(testutils.WithUserStore(true))
+
+	routes := Routes(app)
+
+	if len(routes) != 1 || routes[0].GetPath() != links.API_V1_OPENAPI {
+		t.Fatalf("expected only the OpenAPI route without the custom store, got %d routes", len(routes))
+	}
+}
@@ -0,0 +1,161 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/apitoken"
+	"project/internal/app"
+	"project/internal/helpers"
+
+	"github.com/dracory/rtr"
+	"github.com/dracory/shopstore"
+)
+
+// == CONTROLLER ===============================================================
+
+// shopOrdersController serves the orders of the shop. The users see their
+// own orders only, the administrators see all of them.
+type shopOrdersController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR ==============================================================
+
+// NewShopOrdersController creates a new shop orders controller
+func NewShopOrdersController(app app.AppInterface) *shopOrdersController {
+	return &shopOrdersController{app: app}
+}
+
+func shopOrdersEndpoints(app app.AppInterface) []Endpoint {
+	controller := NewShopOrdersController(app)
+
+	return []Endpoint{
+		{
+			Method:      http.MethodGet,
+			Path:        "/shop/orders",
+			Tag:         "Shop",
+			Summary:     "List the orders",
+			Description: "Lists the orders, newest first. The users see their own orders only.",
+			Scope:       apitoken.SCOPE_ORDERS_READ,
+			Parameters: append(paginationParameters(),
+				Parameter{
+					Name:        "status",
+					In:          PARAMETER_IN_QUERY,
+					Type:        "string",
+					Description: "Only the orders with the status",
+					Enum: []string{
+						shopstore.ORDER_STATUS_AWAITING_PAYMENT,
+						shopstore.ORDER_STATUS_AWAITING_FULFILLMENT,
+						shopstore.ORDER_STATUS_AWAITING_SHIPMENT,
+						shopstore.ORDER_STATUS_AWAITING_PICKUP,
+						shopstore.ORDER_STATUS_SHIPPED,
+						shopstore.ORDER_STATUS_COMPLETED,
+						shopstore.ORDER_STATUS_CANCELLED,
+					},
+				},
+				Parameter{
+					Name:        "customer_id",
+					In:          PARAMETER_IN_QUERY,
+					Type:        "string",
+					Description: "Only the orders of the customer (administrators only)",
+				},
+			),
+			Handler: controller.List,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "/shop/orders/:id",
+			Tag:        "Shop",
+			Summary:    "Get an order",
+			Scope:      apitoken.SCOPE_ORDERS_READ,
+			Parameters: []Parameter{idParameter("The ID of the order")},
+			Handler:    controller.Get,
+		},
+	}
+}
+
+// == PUBLIC METHODS ===========================================================
+
+// List returns a page of the orders, newest first
+func (controller *shopOrdersController) List(w http.ResponseWriter, r *http.Request) {
+	page, errorMessage := paginationFromRequest(r)
+	if errorMessage != "" {
+		respondError(w, http.StatusBadRequest, errorMessage)
+		return
+	}
+
+	customerID := r.URL.Query().Get("customer_id")
+
+	if !isAdministrator(r) {
+		customerID = helpers.GetAPIAuthUser(r).GetID()
+	}
+
+	query := shopstore.NewOrderQuery().
+		SetOrderBy("created_at").
+		SetSortDirection("DESC").
+		SetOffset(page.offset()).
+		SetLimit(page.perPage)
+
+	if customerID != "" {
+		query.SetCustomerID(customerID)
+	}
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		query.SetStatus(status)
+	}
+
+	orders, err := controller.app.GetShopStore().OrderList(r.Context(), query)
+	if err != nil {
+		controller.app.GetLogger().Error("At shopOrdersController > List > OrderList", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Orders could not be loaded")
+		return
+	}
+
+	total, err := controller.app.GetShopStore().OrderCount(r.Context(), query)
+	if err != nil {
+		controller.app.GetLogger().Error("At shopOrdersController > List > OrderCount", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Orders could not be loaded")
+		return
+	}
+
+	items := make([]map[string]any, 0, len(orders))
+
+	for _, order := range orders {
+		items = append(items, orderToMap(order))
+	}
+
+	respondList(w, items, page, total)
+}
+
+// Get returns an order. The orders of the other customers are not found,
+// so their IDs are not disclosed.
+func (controller *shopOrdersController) Get(w http.ResponseWriter, r *http.Request) {
+	orderID, _ := rtr.GetParam(r, "id")
+
+	order, err := controller.app.GetShopStore().OrderFindByID(r.Context(), orderID)
+	if err != nil {
+		controller.app.GetLogger().Error("At shopOrdersController > Get > OrderFindByID", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Order could not be loaded")
+		return
+	}
+
+	if order == nil || (!isAdministrator(r) && order.GetCustomerID() != helpers.GetAPIAuthUser(r).GetID()) {
+		respondError(w, http.StatusNotFound, "Order not found")
+		return
+	}
+
+	respondItem(w, http.StatusOK, orderToMap(order))
+}
+
+// == PRIVATE METHODS ==========================================================
+
+func orderToMap(order shopstore.OrderInterface) map[string]any {
+	return map[string]any{
+		"id":          order.GetID(),
+		"customer_id": order.GetCustomerID(),
+		"status":      order.GetStatus(),
+		"price":       order.GetPrice(),
+		"created_at":  order.GetCreatedAt(),
+		"updated_at":  order.GetUpdatedAt(),
+	}
+}
@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+
+	"project/internal/apitoken"
+	"project/internal/links"
+	"project/internal/testutils"
+
+	"github.com/dracory/test"
+)
+
+func TestShopOrdersController_OwnOrdersOnly(t *testing.T) {
+	app := setupAPIApp(t, testutils.WithShopStore(true))
+	token := seedAPIToken(t, app, test.USER_01, apitoken.SCOPE_ORDERS_READ)
+
+	if _, err := testutils.SeedOrder(app.GetShopStore(), "order-own", test.USER_01); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := testutils.SeedOrder(app.GetShopStore(), "order-other", test.USER_02); err != nil {
+		t.Fatal(err)
+	}
+
+	res, response := callAPI(t, app, http.MethodGet, links.API_V1+"/shop/orders?customer_id="+test.USER_02, token, "")
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.Code, res.Body.String())
+	}
+
+	items := responseData(t, response)["items"].([]any)
+	if len(items) != 1 || items[0].(map[string]any)["id"] != "order-own" {
+		t.Fatalf("expected the own order only, got %v", items)
+	}
+
+	if res, _ := callAPI(t, app, http.MethodGet, links.API_V1+"/shop/orders/order-own", token, ""); res.Code != http.StatusOK {
+		t.Fatalf("expected the own order, got %d", res.Code)
+	}
+
+	if res, _ := callAPI(t, app, http.MethodGet, links.API_V1+"/shop/orders/order-other", token, ""); res.Code != http.StatusNotFound {
+		t.Fatalf("expected the order of another customer not to be found, got %d", res.Code)
+	}
+}
+
+func TestShopOrdersController_AdministratorFiltersByCustomer(t *testing.T) {
+	app := setupAPIApp(t, testutils.WithShopStore(true))
+	token := seedAPIToken(t, app, test.ADMIN_01, apitoken.SCOPE_ORDERS_READ)
+
+	for orderID, customerID := range map[string]string{"order-1": test.USER_01, "order-2": test.USER_02} {
+		if _, err := testutils.SeedOrder(app.GetShopStore(), orderID, customerID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, response := callAPI(t, app, http.MethodGet, links.API_V1+"/shop/orders", token, "")
+	if items := responseData(t, response)["items"].([]any); len(items) != 2 {
+		t.Fatalf("expected all the orders, got %d", len(items))
+	}
+
+	_, response = callAPI(t, app, http.MethodGet, links.API_V1+"/shop/orders?customer_id="+test.USER_02, token, "")
+	if items := responseData(t, response)["items"].([]any); len(items) != 1 {
+		t.Fatalf("expected the orders of the customer, got %d", len(items))
+	}
+}
@@ -0,0 +1,141 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/apitoken"
+	"project/internal/app"
+
+	"github.com/dracory/rtr"
+	"github.com/dracory/shopstore"
+)
+
+// == CONTROLLER ===============================================================
+
+// shopProductsController serves the products of the shop. The users see
+// the active products only, the administrators see all of them.
+type shopProductsController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR ==============================================================
+
+// NewShopProductsController creates a new shop products controller
+func NewShopProductsController(app app.AppInterface) *shopProductsController {
+	return &shopProductsController{app: app}
+}
+
+func shopProductsEndpoints(app app.AppInterface) []Endpoint {
+	controller := NewShopProductsController(app)
+
+	return []Endpoint{
+		{
+			Method:      http.MethodGet,
+			Path:        "/shop/products",
+			Tag:         "Shop",
+			Summary:     "List the products",
+			Description: "Lists the products, newest first. The users see the active products only.",
+			Scope:       apitoken.SCOPE_SHOP_READ,
+			Parameters: append(paginationParameters(), Parameter{
+				Name:        "status",
+				In:          PARAMETER_IN_QUERY,
+				Type:        "string",
+				Description: "Only the products with the status (administrators only)",
+				Enum:        []string{shopstore.PRODUCT_STATUS_ACTIVE, shopstore.PRODUCT_STATUS_DRAFT},
+			}),
+			Handler: controller.List,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "/shop/products/:id",
+			Tag:        "Shop",
+			Summary:    "Get a product",
+			Scope:      apitoken.SCOPE_SHOP_READ,
+			Parameters: []Parameter{idParameter("The ID of the product")},
+			Handler:    controller.Get,
+		},
+	}
+}
+
+// == PUBLIC METHODS ===========================================================
+
+// List returns a page of the products, newest first
+func (controller *shopProductsController) List(w http.ResponseWriter, r *http.Request) {
+	page, errorMessage := paginationFromRequest(r)
+	if errorMessage != "" {
+		respondError(w, http.StatusBadRequest, errorMessage)
+		return
+	}
+
+	status := shopstore.PRODUCT_STATUS_ACTIVE
+
+	if isAdministrator(r) {
+		status = r.URL.Query().Get("status")
+	}
+
+	query := shopstore.NewProductQuery().
+		SetOrderBy("created_at").
+		SetSortDirection("DESC").
+		SetOffset(page.offset()).
+		SetLimit(page.perPage)
+
+	if status != "" {
+		query.SetStatus(status)
+	}
+
+	products, err := controller.app.GetShopStore().ProductList(r.Context(), query)
+	if err != nil {
+		controller.app.GetLogger().Error("At shopProductsController > List > ProductList", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Products could not be loaded")
+		return
+	}
+
+	total, err := controller.app.GetShopStore().ProductCount(r.Context(), query)
+	if err != nil {
+		controller.app.GetLogger().Error("At shopProductsController > List > ProductCount", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Products could not be loaded")
+		return
+	}
+
+	items := make([]map[string]any, 0, len(products))
+
+	for _, product := range products {
+		items = append(items, productToMap(product))
+	}
+
+	respondList(w, items, page, total)
+}
+
+// Get returns a product
+func (controller *shopProductsController) Get(w http.ResponseWriter, r *http.Request) {
+	productID, _ := rtr.GetParam(r, "id")
+
+	product, err := controller.app.GetShopStore().ProductFindByID(r.Context(), productID)
+	if err != nil {
+		controller.app.GetLogger().Error("At shopProductsController > Get > ProductFindByID", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Product could not be loaded")
+		return
+	}
+
+	if product == nil || (product.GetStatus() != shopstore.PRODUCT_STATUS_ACTIVE && !isAdministrator(r)) {
+		respondError(w, http.StatusNotFound, "Product not found")
+		return
+	}
+
+	respondItem(w, http.StatusOK, productToMap(product))
+}
+
+// == PRIVATE METHODS ==========================================================
+
+func productToMap(product shopstore.ProductInterface) map[string]any {
+	return map[string]any{
+		"id":          product.GetID(),
+		"title":       product.GetTitle(),
+		"description": product.GetDescription(),
+		"price":       product.GetPrice(),
+		"quantity":    product.GetQuantity(),
+		"status":      product.GetStatus(),
+		"created_at":  product.GetCreatedAt(),
+		"updated_at":  product.GetUpdatedAt(),
+	}
+}
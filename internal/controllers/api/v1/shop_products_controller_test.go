@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"project/internal/apitoken"
+	"project/internal/links"
+	"project/internal/testutils"
+
+	"github.com/dracory/shopstore"
+	"github.com/dracory/test"
+)
+
+func TestShopProductsController_ActiveOnly(t *testing.T) {
+	app := setupAPIApp(t, testutils.WithShopStore(true))
+	token := seedAPIToken(t, app, test.USER_01, apitoken.SCOPE_SHOP_READ)
+
+	if _, err := testutils.SeedProduct(app.GetShopStore(), "product-active", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	draft := shopstore.NewProduct()
+	draft.SetID("product-draft")
+	draft.SetTitle("Draft Product")
+	draft.SetStatus(shopstore.PRODUCT_STATUS_DRAFT)
+
+	if err := app.GetShopStore().ProductCreate(context.Background(), draft); err != nil {
+		t.Fatal(err)
+	}
+
+	res, response := callAPI(t, app, http.MethodGet, links.API_V1+"/shop/products?status="+shopstore.PRODUCT_STATUS_DRAFT, token, "")
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.Code, res.Body.String())
+	}
+
+	items := responseData(t, response)["items"].([]any)
+	if len(items) != 1 || items[0].(map[string]any)["id"] != "product-active" {
+		t.Fatalf("expected the active product only, got %v", items)
+	}
+
+	if res, _ := callAPI(t, app, http.MethodGet, links.API_V1+"/shop/products/product-draft", token, ""); res.Code != http.StatusNotFound {
+		t.Fatalf("expected the draft product not to be found, got %d", res.Code)
+	}
+}
@@ -0,0 +1,192 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/apitoken"
+	"project/internal/app"
+	"strings"
+
+	"github.com/dracory/rtr"
+	"github.com/dracory/taskstore"
+)
+
+// taskEnqueueInput is the JSON body of the enqueue request
+type taskEnqueueInput struct {
+	Alias      string         `json:"alias"`
+	Parameters map[string]any `json:"parameters"`
+}
+
+// == CONTROLLER ===============================================================
+
+// tasksController serves the task queue to the administrators, and lets
+// them enqueue the tasks
+type tasksController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR ==============================================================
+
+// NewTasksController creates a new tasks controller
+func NewTasksController(app app.AppInterface) *tasksController {
+	return &tasksController{app: app}
+}
+
+func tasksEndpoints(app app.AppInterface) []Endpoint {
+	controller := NewTasksController(app)
+
+	return []Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    "/tasks",
+			Tag:     "Tasks",
+			Summary: "List the queued tasks",
+			Scope:   apitoken.SCOPE_TASKS_READ,
+			Parameters: append(paginationParameters(), Parameter{
+				Name:        "status",
+				In:          PARAMETER_IN_QUERY,
+				Type:        "string",
+				Description: "Only the queued tasks with the status, e.g. " + taskstore.TaskQueueStatusQueued,
+			}),
+			Handler: controller.List,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "/tasks/:id",
+			Tag:        "Tasks",
+			Summary:    "Get a queued task",
+			Scope:      apitoken.SCOPE_TASKS_READ,
+			Parameters: []Parameter{idParameter("The ID of the queued task")},
+			Handler:    controller.Get,
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/tasks",
+			Tag:         "Tasks",
+			Summary:     "Enqueue a task",
+			Description: "Adds a task to the default queue, to be run by the queue worker.",
+			Scope:       apitoken.SCOPE_TASKS_WRITE,
+			Parameters: []Parameter{
+				{Name: "alias", In: PARAMETER_IN_BODY, Type: "string", Description: "The alias of the task", Required: true},
+				{Name: "parameters", In: PARAMETER_IN_BODY, Type: "object", Description: "The parameters of the task"},
+			},
+			Handler: controller.Enqueue,
+		},
+	}
+}
+
+// == PUBLIC METHODS ===========================================================
+
+// List returns a page of the queued tasks, newest first
+func (controller *tasksController) List(w http.ResponseWriter, r *http.Request) {
+	page, errorMessage := paginationFromRequest(r)
+	if errorMessage != "" {
+		respondError(w, http.StatusBadRequest, errorMessage)
+		return
+	}
+
+	query := taskstore.TaskQueueQuery().
+		SetOrderBy("created_at").
+		SetSortOrder("DESC").
+		SetOffset(page.offset()).
+		SetLimit(page.perPage)
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		query.SetStatus(status)
+	}
+
+	queuedTasks, err := controller.app.GetTaskStore().TaskQueueList(r.Context(), query)
+	if err != nil {
+		controller.app.GetLogger().Error("At tasksController > List > TaskQueueList", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Tasks could not be loaded")
+		return
+	}
+
+	total, err := controller.app.GetTaskStore().TaskQueueCount(r.Context(), query)
+	if err != nil {
+		controller.app.GetLogger().Error("At tasksController > List > TaskQueueCount", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Tasks could not be loaded")
+		return
+	}
+
+	items := make([]map[string]any, 0, len(queuedTasks))
+
+	for _, queuedTask := range queuedTasks {
+		items = append(items, queuedTaskToMap(queuedTask))
+	}
+
+	respondList(w, items, page, total)
+}
+
+// Get returns a queued task
+func (controller *tasksController) Get(w http.ResponseWriter, r *http.Request) {
+	queuedTaskID, _ := rtr.GetParam(r, "id")
+
+	queuedTask, err := controller.app.GetTaskStore().TaskQueueFindByID(r.Context(), queuedTaskID)
+	if err != nil {
+		controller.app.GetLogger().Error("At tasksController > Get > TaskQueueFindByID", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Task could not be loaded")
+		return
+	}
+
+	if queuedTask == nil {
+		respondError(w, http.StatusNotFound, "Task not found")
+		return
+	}
+
+	respondItem(w, http.StatusOK, queuedTaskToMap(queuedTask))
+}
+
+// Enqueue adds a task to the default queue
+func (controller *tasksController) Enqueue(w http.ResponseWriter, r *http.Request) {
+	input := taskEnqueueInput{}
+
+	if errorMessage := decodeBody(w, r, &input); errorMessage != "" {
+		respondError(w, http.StatusBadRequest, errorMessage)
+		return
+	}
+
+	alias := strings.TrimSpace(input.Alias)
+
+	if alias == "" {
+		respondError(w, http.StatusUnprocessableEntity, "alias is required")
+		return
+	}
+
+	if input.Parameters == nil {
+		input.Parameters = map[string]any{}
+	}
+
+	taskDefinition, err := controller.app.GetTaskStore().TaskDefinitionFindByAlias(r.Context(), alias)
+	if err != nil {
+		controller.app.GetLogger().Error("At tasksController > Enqueue > TaskDefinitionFindByAlias", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Task could not be enqueued")
+		return
+	}
+
+	if taskDefinition == nil {
+		respondError(w, http.StatusUnprocessableEntity, "No task has the alias "+alias)
+		return
+	}
+
+	queuedTask, err := controller.app.GetTaskStore().TaskDefinitionEnqueueByAlias(r.Context(), taskstore.DefaultQueueName, alias, input.Parameters)
+	if err != nil {
+		controller.app.GetLogger().Error("At tasksController > Enqueue > TaskDefinitionEnqueueByAlias", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Task could not be enqueued")
+		return
+	}
+
+	respondItem(w, http.StatusCreated, queuedTaskToMap(queuedTask))
+}
+
+// == PRIVATE METHODS ==========================================================
+
+func queuedTaskToMap(queuedTask taskstore.TaskQueueInterface) map[string]any {
+	return map[string]any{
+		"id":         queuedTask.GetID(),
+		"task_id":    queuedTask.GetTaskID(),
+		"status":     queuedTask.GetStatus(),
+		"created_at": queuedTask.GetCreatedAt(),
+		"updated_at": queuedTask.GetUpdatedAt(),
+	}
+}
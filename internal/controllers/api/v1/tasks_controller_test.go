@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+
+	"project/internal/apitoken"
+	"project/internal/links"
+	"project/internal/testutils"
+
+	"github.com/dracory/test"
+)
+
+func TestTasksController_EnqueueUnknownAlias(t *testing.T) {
+	app := setupAPIApp(t, testutils.WithTaskStore(true))
+	token := seedAPIToken(t, app, test.ADMIN_01, apitoken.SCOPE_TASKS_WRITE)
+
+	res, _ := callAPI(t, app, http.MethodPost, links.API_V1+"/tasks", token, `{"alias":"UnknownTask"}`)
+
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusUnprocessableEntity, res.Code, res.Body.String())
+	}
+
+	res, _ = callAPI(t, app, http.MethodPost, links.API_V1+"/tasks", token, `{}`)
+
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected the alias to be required, got %d", res.Code)
+	}
+}
+
+func TestTasksController_List(t *testing.T) {
+	app := setupAPIApp(t, testutils.WithTaskStore(true))
+	token := seedAPIToken(t, app, test.ADMIN_01, apitoken.SCOPE_TASKS_READ)
+
+	res, response := callAPI(t, app, http.MethodGet, links.API_V1+"/tasks", token, "")
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.Code, res.Body.String())
+	}
+
+	if _, ok := responseData(t, response)["pagination"]; !ok {
+		t.Fatal("expected the pagination")
+	}
+}
@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/apitoken"
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/helpers"
+
+	"github.com/dracory/neat"
+	"github.com/dracory/rtr"
+	"github.com/dracory/userstore"
+)
+
+// == CONTROLLER ===============================================================
+
+// usersController serves the profile of the user of the token, and the
+// users to the administrators
+type usersController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR ==============================================================
+
+// NewUsersController creates a new users controller
+func NewUsersController(app app.AppInterface) *usersController {
+	return &usersController{app: app}
+}
+
+func meEndpoints(app app.AppInterface) []Endpoint {
+	controller := NewUsersController(app)
+
+	return []Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    "/me",
+			Tag:     "Profile",
+			Summary: "Get the profile of the user of the token",
+			Scope:   apitoken.SCOPE_PROFILE_READ,
+			Handler: controller.Me,
+		},
+	}
+}
+
+func usersEndpoints(app app.AppInterface) []Endpoint {
+	controller := NewUsersController(app)
+
+	return []Endpoint{
+		{
+			Method:  http.MethodGet,
+			Path:    "/users",
+			Tag:     "Users",
+			Summary: "List the users",
+			Scope:   apitoken.SCOPE_USERS_READ,
+			Parameters: append(paginationParameters(), Parameter{
+				Name:        "status",
+				In:          PARAMETER_IN_QUERY,
+				Type:        "string",
+				Description: "Only the users with the status",
+				Enum:        []string{userstore.USER_STATUS_ACTIVE, userstore.USER_STATUS_INACTIVE, userstore.USER_STATUS_UNVERIFIED, userstore.USER_STATUS_DELETED},
+			}),
+			Handler: controller.List,
+		},
+		{
+			Method:     http.MethodGet,
+			Path:       "/users/:id",
+			Tag:        "Users",
+			Summary:    "Get a user",
+			Scope:      apitoken.SCOPE_USERS_READ,
+			Parameters: []Parameter{idParameter("The ID of the user")},
+			Handler:    controller.Get,
+		},
+	}
+}
+
+// == PUBLIC METHODS ===========================================================
+
+// Me returns the user of the token
+func (controller *usersController) Me(w http.ResponseWriter, r *http.Request) {
+	user := helpers.GetAPIAuthUser(r)
+
+	if user == nil {
+		respondError(w, http.StatusUnauthorized, "Authorization token required")
+		return
+	}
+
+	controller.respondUser(w, r, user)
+}
+
+// List returns a page of the users, newest first
+func (controller *usersController) List(w http.ResponseWriter, r *http.Request) {
+	page, errorMessage := paginationFromRequest(r)
+	if errorMessage != "" {
+		respondError(w, http.StatusBadRequest, errorMessage)
+		return
+	}
+
+	query := userstore.NewUserQuery().
+		SetSortDirection(neat.SortDesc).
+		SetOrderBy(userstore.COLUMN_CREATED_AT).
+		SetOffset(page.offset()).
+		SetLimit(page.perPage)
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		query.SetStatus(status)
+	}
+
+	users, err := controller.app.GetUserStore().UserList(r.Context(), query)
+	if err != nil {
+		controller.app.GetLogger().Error("At usersController > List > UserList", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Users could not be loaded")
+		return
+	}
+
+	total, err := controller.app.GetUserStore().UserCount(r.Context(), query)
+	if err != nil {
+		controller.app.GetLogger().Error("At usersController > List > UserCount", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "Users could not be loaded")
+		return
+	}
+
+	items := make([]map[string]any, 0, len(users))
+
+	for _, user := range users {
+		items = append(items, controller.userToMap(r, user))
+	}
+
+	respondList(w, items, page, total)
+}
+
+// Get returns a user
+func (controller *usersController) Get(w http.ResponseWriter, r *http.Request) {
+	userID, _ := rtr.GetParam(r, "id")
+
+	user, err := controller.app.GetUserStore().UserFindByID(r.Context(), userID)
+	if err != nil {
+		controller.app.GetLogger().Error("At usersController > Get > UserFindByID", slog.String("error", err.Error()))
+		respondError(w, http.StatusInternalServerError, "User could not be loaded")
+		return
+	}
+
+	if user == nil {
+		respondError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	controller.respondUser(w, r, user)
+}
+
+// == PRIVATE METHODS ==========================================================
+
+func (controller *usersController) respondUser(w http.ResponseWriter, r *http.Request, user userstore.UserInterface) {
+	respondItem(w, http.StatusOK, controller.userToMap(r, user))
+}
+
+// userToMap returns the fields of the user, decrypted from the vault if
+// it is used
+func (controller *usersController) userToMap(r *http.Request, user userstore.UserInterface) map[string]any {
+	email, firstName, lastName, _, _, err := ext.UserUntokenizeTransparently(r.Context(), controller.app, user)
+	if err != nil {
+		controller.app.GetLogger().Error("At usersController > userToMap > UserUntokenizeTransparently", slog.String("error", err.Error()))
+		email, firstName, lastName = "n/a", "n/a", "n/a"
+	}
+
+	return map[string]any{
+		"id":         user.GetID(),
+		"email":      email,
+		"first_name": firstName,
+		"last_name":  lastName,
+		"role":       user.GetRole(),
+		"status":     user.GetStatus(),
+		"created_at": user.GetCreatedAt(),
+		"updated_at": user.GetUpdatedAt(),
+	}
+}
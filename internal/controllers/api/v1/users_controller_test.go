@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"net/http"
+	"testing"
+
+	"project/internal/apitoken"
+	"project/internal/links"
+	"project/internal/testutils"
+
+	"github.com/dracory/test"
+)
+
+func TestUsersController_Me(t *testing.T) {
+	app := setupAPIApp(t)
+	token := seedAPIToken(t, app, test.USER_01, apitoken.SCOPE_PROFILE_READ)
+
+	res, response := callAPI(t, app, http.MethodGet, links.API_V1+"/me", token, "")
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.Code, res.Body.String())
+	}
+
+	item := responseData(t, response)["item"].(map[string]any)
+	if item["id"] != test.USER_01 {
+		t.Fatalf("expected the user of the token, got %v", item)
+	}
+}
+
+func TestUsersController_List(t *testing.T) {
+	app := setupAPIApp(t)
+	token := seedAPIToken(t, app, test.ADMIN_01, apitoken.SCOPE_USERS_READ)
+	if _, err := testutils.SeedUser(app.GetUserStore(), test.USER_01); err != nil {
+		t.Fatal(err)
+	}
+
+	res, response := callAPI(t, app, http.MethodGet, links.API_V1+"/users?per_page=1", token, "")
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.Code, res.Body.String())
+	}
+
+	data := responseData(t, response)
+	pagination := data["pagination"].(map[string]any)
+
+	if len(data["items"].([]any)) != 1 || pagination["total"] != float64(2) || pagination["total_pages"] != float64(2) {
+		t.Fatalf("expected 1 of 2 users, got %v", data)
+	}
+}
+
+func TestUsersController_GetNotFound(t *testing.T) {
+	app := setupAPIApp(t)
+	token := seedAPIToken(t, app, test.ADMIN_01, apitoken.SCOPE_USERS_READ)
+
+	res, _ := callAPI(t, app, http.MethodGet, links.API_V1+"/users/missing", token, "")
+
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d, got %d", http.StatusNotFound, res.Code)
+	}
+}
+
+func TestUsersController_AdminOnly(t *testing.T) {
+	app := setupAPIApp(t)
+	if _, err := testutils.SeedUser(app.GetUserStore(), test.USER_01); err != nil {
+		t.Fatal(err)
+	}
+
+	tokenStore, err := apitoken.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A token created when the user was still an administrator
+	token := apitoken.TOKEN_PREFIX + "demoted"
+	if err := tokenStore.TokenCreate(&apitoken.Token{UserID: test.USER_01, Name: "Demoted", Hash: apitoken.Hash(token), Scopes: []string{apitoken.SCOPE_USERS_READ}}); err != nil {
+		t.Fatal(err)
+	}
+
+	res, _ := callAPI(t, app, http.MethodGet, links.API_V1+"/users", token, "")
+
+	if res.Code != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d", http.StatusForbidden, res.Code)
+	}
+}
@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"project/internal/app"
+	"project/internal/billing"
+	"project/internal/helpers"
+	"project/internal/payment"
+
+	"github.com/dracory/subscriptionstore"
+)
+
+// eventProcessor applies the payment gateway events to the shop orders
+// and to the subscriptions.
+//
+// Every handler is idempotent, so processing the same event twice
+// (e.g. concurrent deliveries) leaves the data in the same state.
+// Events referring to unknown orders or subscriptions are logged and
+// skipped, as retrying them would never succeed.
+type eventProcessor struct {
+	app app.AppInterface
+}
+
+func newEventProcessor(app app.AppInterface) *eventProcessor {
+	return &eventProcessor{app: app}
+}
+
+// Process dispatches the event to its handler, ignored events are skipped
+func (processor *eventProcessor) Process(ctx context.Context, event payment.Event) error {
+	switch event.Type {
+	case payment.EVENT_ORDER_PAID:
+		return processor.orderMarkAsPaid(ctx, event)
+	case payment.EVENT_SUBSCRIPTION_PAID:
+		return processor.subscriptionActivate(ctx, event)
+	case payment.EVENT_SUBSCRIPTION_CANCELLED:
+		return processor.subscriptionCancelled(ctx, event)
+	}
+
+	return nil
+}
+
+func (processor *eventProcessor) orderMarkAsPaid(ctx context.Context, event payment.Event) error {
+	shopStore := processor.app.GetShopStore()
+
+	if shopStore == nil {
+		return errors.New("shop store not configured")
+	}
+
+	if event.OrderID == "" {
+		processor.skip(event, "no order ID in the event")
+		return nil
+	}
+
+	order, err := shopStore.OrderFindByID(ctx, event.OrderID)
+
+	if err != nil {
+		return fmt.Errorf("failed to find order: %w", err)
+	}
+
+	if order == nil {
+		processor.skip(event, "order not found: "+event.OrderID)
+		return nil
+	}
+
+	if err := helpers.OrderMarkAsPaid(ctx, shopStore, order); err != nil {
+		return err
+	}
+
+	if err := helpers.OrderInvoiceIssue(processor.app, order); err != nil {
+		return err
+	}
+
+	if err := helpers.OrderDiscountRedeem(processor.app, order); err != nil {
+		return err
+	}
+
+	return helpers.OrderStockCommit(ctx, processor.app, order)
+}
+
+// subscriptionActivate activates the paid subscription, which replaces
+// the current subscription of the subscriber on a plan change
+func (processor *eventProcessor) subscriptionActivate(ctx context.Context, event payment.Event) error {
+	subscription, err := processor.subscriptionFind(ctx, event)
+
+	if err != nil || subscription == nil {
+		return err
+	}
+
+	return billing.SubscriptionActivate(ctx, processor.app.GetSubscriptionStore(), processor.app.GetPaymentGateway(), subscription)
+}
+
+// subscriptionCancelled marks cancelled the subscription, which has
+// already ended at the payment provider
+func (processor *eventProcessor) subscriptionCancelled(ctx context.Context, event payment.Event) error {
+	subscription, err := processor.subscriptionFind(ctx, event)
+
+	if err != nil || subscription == nil {
+		return err
+	}
+
+	if subscription.GetStatus() == subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED {
+		return nil
+	}
+
+	subscription.SetStatus(subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED)
+
+	return processor.app.GetSubscriptionStore().SubscriptionUpdate(ctx, subscription)
+}
+
+// subscriptionFind returns the subscription of the event, or nil
+// if the event is to be skipped
+func (processor *eventProcessor) subscriptionFind(ctx context.Context, event payment.Event) (subscriptionstore.SubscriptionInterface, error) {
+	subscriptionStore := processor.app.GetSubscriptionStore()
+
+	if subscriptionStore == nil {
+		return nil, errors.New("subscription store not configured")
+	}
+
+	if event.SubscriptionID == "" {
+		processor.skip(event, "no subscription ID in the event")
+		return nil, nil
+	}
+
+	subscription, err := subscriptionStore.SubscriptionFindByID(ctx, event.SubscriptionID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find subscription: %w", err)
+	}
+
+	if subscription == nil {
+		processor.skip(event, "subscription not found: "+event.SubscriptionID)
+		return nil, nil
+	}
+
+	return subscription, nil
+}
+
+func (processor *eventProcessor) skip(event payment.Event, reason string) {
+	processor.app.GetLogger().Warn("At eventProcessor > skip",
+		slog.String("reason", reason),
+		slog.String("event_id", event.ID),
+		slog.String("event_type", event.ProviderType))
+}
@@ -0,0 +1,24 @@
+package webhook
+
+import (
+	"project/internal/app"
+	"project/internal/links"
+
+	"github.com/dracory/rtr"
+)
+
+// Routes returns the payment gateway webhook routes
+func Routes(app app.AppInterface) []rtr.RouteInterface {
+	if app == nil || app.GetConfig() == nil {
+		return []rtr.RouteInterface{}
+	}
+
+	webhookRoute := rtr.NewRoute().
+		SetName("API > Internal > Payment Webhook").
+		SetPath(links.API_INTERNAL_WEBHOOK).
+		SetHandler(NewWebhookController(app).Handler)
+
+	return []rtr.RouteInterface{
+		webhookRoute,
+	}
+}
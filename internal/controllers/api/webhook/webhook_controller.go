@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/customrecords"
+	"project/internal/payment"
+)
+
+// maxPayloadBytes limits the size of the accepted event payloads,
+// as recommended by Stripe
+const maxPayloadBytes = 65536
+
+// RECORD_TYPE_PAYMENT_WEBHOOK_EVENT is the record type of the events claimed
+// for processing in the custom store. One record is kept per event, as the
+// log of the processed events
+const RECORD_TYPE_PAYMENT_WEBHOOK_EVENT = "payment_webhook_event"
+
+// == CONTROLLER ==============================================================
+
+// webhookController receives the events sent by the payment gateway
+type webhookController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewWebhookController creates a new payment webhook controller
+func NewWebhookController(app app.AppInterface) *webhookController {
+	return &webhookController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+// Handler verifies and processes a payment gateway event.
+//
+// Business logic:
+//  1. The signature header is verified by the payment gateway,
+//     unsigned or tampered payloads are rejected with 400
+//  2. The event is claimed, events already claimed (the gateway may deliver
+//     the same event more than once, even at the same moment) are
+//     acknowledged without being processed again
+//  3. The event is processed, on failure the claim is removed and 500 is
+//     returned, so that the gateway retries the delivery later
+//  4. The event is acknowledged with 200
+func (controller *webhookController) Handler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gateway := controller.app.GetPaymentGateway()
+
+	if gateway == nil || controller.app.GetCustomStore() == nil {
+		controller.app.GetLogger().Error("At webhookController > Handler", slog.String("error", "payment gateway or custom store not configured"))
+		http.Error(w, "Webhook not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadBytes))
+
+	if err != nil {
+		http.Error(w, "Payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	event, err := gateway.WebhookParse(payload, r.Header)
+
+	if err != nil {
+		controller.app.GetLogger().Warn("At webhookController > Handler > WebhookParse", slog.String("error", err.Error()))
+		http.Error(w, "Invalid signature", http.StatusBadRequest)
+		return
+	}
+
+	claimKey := gateway.Name() + "_" + event.ID
+
+	// The claim is atomic, so of the concurrent deliveries of the same
+	// event only one processes it
+	claimed, err := customrecords.Claim(controller.app.GetCustomStore(), RECORD_TYPE_PAYMENT_WEBHOOK_EVENT, claimKey, eventClaimPayload(event))
+
+	if err != nil {
+		controller.app.GetLogger().Error("At webhookController > Handler > Claim", slog.String("error", err.Error()))
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if !claimed {
+		controller.app.GetLogger().Info("At webhookController > Handler", slog.String("message", "event already processed"), slog.String("event_id", event.ID))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := newEventProcessor(controller.app).Process(r.Context(), event); err != nil {
+		controller.app.GetLogger().Error("At webhookController > Handler > Process",
+			slog.String("error", err.Error()),
+			slog.String("event_id", event.ID),
+			slog.String("event_type", event.ProviderType))
+
+		if err := customrecords.Unclaim(controller.app.GetCustomStore(), RECORD_TYPE_PAYMENT_WEBHOOK_EVENT, claimKey); err != nil {
+			controller.app.GetLogger().Error("At webhookController > Handler > Unclaim", slog.String("error", err.Error()), slog.String("event_id", event.ID))
+		}
+
+		http.Error(w, "Event processing failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// eventClaimPayload returns the payload of the claim of the event, for the
+// log of the processed events
+func eventClaimPayload(event payment.Event) string {
+	payload, _ := json.Marshal(map[string]string{
+		"event_id":   event.ID,
+		"event_type": event.Type,
+	})
+
+	return string(payload)
+}
@@ -0,0 +1,394 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/billing"
+	"project/internal/customrecords"
+	"project/internal/invoice"
+	"project/internal/payment"
+	"project/internal/testutils"
+
+	"github.com/dracory/shopstore"
+	"github.com/dracory/subscriptionstore"
+	"github.com/dracory/test"
+	"github.com/stripe/stripe-go/v73/webhook"
+)
+
+const testWebhookSecret = "whsec_test_secret"
+
+func setupWebhookApp(t *testing.T) app.AppInterface {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetShopStoreUsed(true)
+	cfg.SetSubscriptionStoreUsed(true)
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+	app.SetPaymentGateway(payment.NewStripeGateway("sk_test_123", testWebhookSecret))
+
+	return app
+}
+
+// loadFixture reads a recorded event payload from testdata,
+// replacing the placeholders with the IDs of the seeded records
+func loadFixture(t *testing.T, name string, replacements map[string]string) []byte {
+	t.Helper()
+
+	payload, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := string(payload)
+	for placeholder, value := range replacements {
+		content = strings.ReplaceAll(content, placeholder, value)
+	}
+
+	return []byte(content)
+}
+
+// sendEvent posts the payload to the webhook, signed with the given secret
+func sendEvent(t *testing.T, app app.AppInterface, payload []byte, secret string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload: payload,
+		Secret:  secret,
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/internal/webhook", strings.NewReader(string(payload)))
+	req.Header.Set("Stripe-Signature", signed.Header)
+	recorder := httptest.NewRecorder()
+
+	NewWebhookController(app).Handler(recorder, req)
+
+	return recorder
+}
+
+func seedOrderAwaitingPayment(t *testing.T, app app.AppInterface) shopstore.OrderInterface {
+	t.Helper()
+
+	order, err := testutils.SeedOrder(app.GetShopStore(), "order_01", test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order.SetStatus(shopstore.ORDER_STATUS_AWAITING_PAYMENT)
+
+	if err := app.GetShopStore().OrderUpdate(context.Background(), order); err != nil {
+		t.Fatal(err)
+	}
+
+	return order
+}
+
+func findOrderStatus(t *testing.T, app app.AppInterface, orderID string) string {
+	t.Helper()
+
+	order, err := app.GetShopStore().OrderFindByID(context.Background(), orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order == nil {
+		t.Fatalf("order %q not found", orderID)
+	}
+
+	return order.GetStatus()
+}
+
+func findSubscriptionStatus(t *testing.T, app app.AppInterface, subscriptionID string) string {
+	t.Helper()
+
+	subscription, err := app.GetSubscriptionStore().SubscriptionFindByID(context.Background(), subscriptionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if subscription == nil {
+		t.Fatalf("subscription %q not found", subscriptionID)
+	}
+
+	return subscription.GetStatus()
+}
+
+func TestWebhook_MethodNotAllowed(t *testing.T) {
+	app := setupWebhookApp(t)
+
+	recorder := httptest.NewRecorder()
+	NewWebhookController(app).Handler(recorder, httptest.NewRequest(http.MethodGet, "/api/internal/webhook", nil))
+
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status %d, got %d", http.StatusMethodNotAllowed, recorder.Code)
+	}
+}
+
+func TestWebhook_NotConfigured(t *testing.T) {
+	app := setupWebhookApp(t)
+	app.SetPaymentGateway(nil)
+
+	payload := loadFixture(t, "checkout_session_completed.json", map[string]string{"{{ORDER_ID}}": "order_01"})
+	recorder := sendEvent(t, app, payload, testWebhookSecret)
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d, got %d", http.StatusServiceUnavailable, recorder.Code)
+	}
+}
+
+func TestWebhook_InvalidSignature(t *testing.T) {
+	app := setupWebhookApp(t)
+	order := seedOrderAwaitingPayment(t, app)
+
+	payload := loadFixture(t, "checkout_session_completed.json", map[string]string{"{{ORDER_ID}}": order.GetID()})
+	recorder := sendEvent(t, app, payload, "whsec_wrong_secret")
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+
+	if status := findOrderStatus(t, app, order.GetID()); status != shopstore.ORDER_STATUS_AWAITING_PAYMENT {
+		t.Errorf("expected order to remain %q, got %q", shopstore.ORDER_STATUS_AWAITING_PAYMENT, status)
+	}
+}
+
+func TestWebhook_MissingSignature(t *testing.T) {
+	app := setupWebhookApp(t)
+
+	payload := loadFixture(t, "checkout_session_completed.json", map[string]string{"{{ORDER_ID}}": "order_01"})
+	req := httptest.NewRequest(http.MethodPost, "/api/internal/webhook", strings.NewReader(string(payload)))
+	recorder := httptest.NewRecorder()
+
+	NewWebhookController(app).Handler(recorder, req)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func TestWebhook_CheckoutSessionCompleted(t *testing.T) {
+	app := setupWebhookApp(t)
+	order := seedOrderAwaitingPayment(t, app)
+
+	payload := loadFixture(t, "checkout_session_completed.json", map[string]string{"{{ORDER_ID}}": order.GetID()})
+	recorder := sendEvent(t, app, payload, testWebhookSecret)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	if status := findOrderStatus(t, app, order.GetID()); status != shopstore.ORDER_STATUS_AWAITING_FULFILLMENT {
+		t.Errorf("expected order status %q, got %q", shopstore.ORDER_STATUS_AWAITING_FULFILLMENT, status)
+	}
+
+	invoiceStore, err := invoice.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issued, err := invoiceStore.InvoiceFindByOrderID(order.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if issued == nil || issued.Number != 1 {
+		t.Errorf("expected the invoice to be issued when the order is paid, got %+v", issued)
+	}
+}
+
+func TestWebhook_CheckoutSessionCompletedUnpaid(t *testing.T) {
+	app := setupWebhookApp(t)
+	order := seedOrderAwaitingPayment(t, app)
+
+	payload := loadFixture(t, "checkout_session_completed_unpaid.json", map[string]string{"{{ORDER_ID}}": order.GetID()})
+	recorder := sendEvent(t, app, payload, testWebhookSecret)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	if status := findOrderStatus(t, app, order.GetID()); status != shopstore.ORDER_STATUS_AWAITING_PAYMENT {
+		t.Errorf("expected order to remain %q, got %q", shopstore.ORDER_STATUS_AWAITING_PAYMENT, status)
+	}
+}
+
+func TestWebhook_UnknownOrderIsAcknowledged(t *testing.T) {
+	app := setupWebhookApp(t)
+
+	payload := loadFixture(t, "checkout_session_completed.json", map[string]string{"{{ORDER_ID}}": "order_missing"})
+	recorder := sendEvent(t, app, payload, testWebhookSecret)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+}
+
+func TestWebhook_DuplicateEventProcessedOnce(t *testing.T) {
+	app := setupWebhookApp(t)
+	order := seedOrderAwaitingPayment(t, app)
+
+	payload := loadFixture(t, "checkout_session_completed.json", map[string]string{"{{ORDER_ID}}": order.GetID()})
+
+	if recorder := sendEvent(t, app, payload, testWebhookSecret); recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	claim, err := app.GetCustomStore().RecordFindByID(customrecords.ClaimID(RECORD_TYPE_PAYMENT_WEBHOOK_EVENT, "stripe_evt_1NkTestCheckoutCompleted"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claim == nil || !strings.Contains(claim.Payload(), payment.EVENT_ORDER_PAID) {
+		t.Fatal("expected the event to be recorded as processed")
+	}
+
+	// Move the order on, so that a reprocessed event would be noticed
+	order.SetStatus(shopstore.ORDER_STATUS_SHIPPED)
+	if err := app.GetShopStore().OrderUpdate(context.Background(), order); err != nil {
+		t.Fatal(err)
+	}
+
+	if recorder := sendEvent(t, app, payload, testWebhookSecret); recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	if status := findOrderStatus(t, app, order.GetID()); status != shopstore.ORDER_STATUS_SHIPPED {
+		t.Errorf("expected the duplicate event to be ignored, got status %q", status)
+	}
+}
+
+func TestWebhook_InvoicePaid(t *testing.T) {
+	app := setupWebhookApp(t)
+
+	subscription, err := testutils.SeedSubscription(app.GetSubscriptionStore(), test.USER_01, testutils.PLAN_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscription.SetStatus(subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED)
+	if err := app.GetSubscriptionStore().SubscriptionUpdate(context.Background(), subscription); err != nil {
+		t.Fatal(err)
+	}
+
+	payload := loadFixture(t, "invoice_paid.json", map[string]string{"{{SUBSCRIPTION_ID}}": subscription.GetID()})
+	recorder := sendEvent(t, app, payload, testWebhookSecret)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	if status := findSubscriptionStatus(t, app, subscription.GetID()); status != subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE {
+		t.Errorf("expected subscription status %q, got %q", subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE, status)
+	}
+}
+
+func TestWebhook_CustomerSubscriptionDeleted(t *testing.T) {
+	app := setupWebhookApp(t)
+
+	subscription, err := testutils.SeedSubscription(app.GetSubscriptionStore(), test.USER_01, testutils.PLAN_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := loadFixture(t, "customer_subscription_deleted.json", map[string]string{"{{SUBSCRIPTION_ID}}": subscription.GetID()})
+	recorder := sendEvent(t, app, payload, testWebhookSecret)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	if status := findSubscriptionStatus(t, app, subscription.GetID()); status != subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED {
+		t.Errorf("expected subscription status %q, got %q", subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED, status)
+	}
+}
+
+func TestWebhook_UnhandledEventIsAcknowledged(t *testing.T) {
+	app := setupWebhookApp(t)
+
+	payload := []byte(`{"id":"evt_1NkTestUnhandled","object":"event","api_version":"2022-11-15","data":{"object":{"id":"cus_TestCustomer","object":"customer"}},"type":"customer.created"}`)
+	recorder := sendEvent(t, app, payload, testWebhookSecret)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, recorder.Code)
+	}
+}
+
+func TestWebhook_FakeGateway(t *testing.T) {
+	app := setupWebhookApp(t)
+	gateway := payment.NewFakeGateway(testWebhookSecret)
+	app.SetPaymentGateway(gateway)
+	order := seedOrderAwaitingPayment(t, app)
+
+	payload := []byte(`{"ID":"evt_fake_1","Type":"order.paid","OrderID":"` + order.GetID() + `"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/internal/webhook", strings.NewReader(string(payload)))
+	req.Header.Set(payment.FAKE_SIGNATURE_HEADER, gateway.Sign(payload))
+	recorder := httptest.NewRecorder()
+
+	NewWebhookController(app).Handler(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	if status := findOrderStatus(t, app, order.GetID()); status != shopstore.ORDER_STATUS_AWAITING_FULFILLMENT {
+		t.Errorf("expected order status %q, got %q", shopstore.ORDER_STATUS_AWAITING_FULFILLMENT, status)
+	}
+}
+
+func TestWebhook_SubscriptionPaidReplacesCurrentPlan(t *testing.T) {
+	app := setupWebhookApp(t)
+	gateway := payment.NewFakeGateway(testWebhookSecret)
+	app.SetPaymentGateway(gateway)
+
+	current, err := testutils.SeedSubscription(app.GetSubscriptionStore(), test.USER_01, billing.PLAN_PRO)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = gateway.CheckoutCreate(context.Background(), payment.CheckoutOptions{
+		Currency:       "GBP",
+		LineItems:      []payment.LineItem{{Name: "Pro plan", Quantity: 1, UnitAmount: 900}},
+		SubscriptionID: current.GetID(),
+		Interval:       payment.INTERVAL_MONTH,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	business, _ := billing.PlanFind(billing.PLAN_BUSINESS)
+	upgrade, err := billing.SubscriptionPendingCreate(context.Background(), app.GetSubscriptionStore(), test.USER_01, business)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload := []byte(`{"ID":"evt_fake_2","Type":"subscription.paid","SubscriptionID":"` + upgrade.GetID() + `"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/internal/webhook", strings.NewReader(string(payload)))
+	req.Header.Set(payment.FAKE_SIGNATURE_HEADER, gateway.Sign(payload))
+	recorder := httptest.NewRecorder()
+
+	NewWebhookController(app).Handler(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	if status := findSubscriptionStatus(t, app, upgrade.GetID()); status != subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE {
+		t.Errorf("expected the upgrade to be active, got %q", status)
+	}
+
+	if status := findSubscriptionStatus(t, app, current.GetID()); status != subscriptionstore.SUBSCRIPTION_STATUS_CANCELLED {
+		t.Errorf("expected the replaced subscription to be cancelled, got %q", status)
+	}
+
+	if !gateway.SubscriptionCancelled(current.GetID()) {
+		t.Error("expected the replaced subscription to be cancelled at the payment provider")
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"net/http"
 	"net/url"
 	"project/internal/app"
+	"project/internal/controllers/website/shop/cart"
+	"project/internal/ext"
 	"project/internal/helpers"
 	"project/internal/links"
 	"project/internal/testutils"
@@ -19,7 +21,6 @@ import (
 	"github.com/dracory/auth"
 	"github.com/dracory/auth/types"
 	basehttp "github.com/dracory/base/http"
-	"github.com/dracory/blindindexstore"
 	"github.com/dracory/req"
 	"github.com/dracory/sessionstore"
 	"github.com/dracory/userstore"
@@ -27,6 +28,14 @@ import (
 	"github.com/samber/lo"
 )
 
+// TOKEN_PURPOSE_TWO_FACTOR is the purpose of the tokens of the logins
+// waiting for the two-factor authentication step, which hold the user ID
+const TOKEN_PURPOSE_TWO_FACTOR = "two_factor"
+
+// twoFactorTokenExpiresSeconds is the time the user has to enter the
+// two-factor authentication code
+const twoFactorTokenExpiresSeconds = 5 * 60
+
 // Authentication error messages
 const (
 	msgAccountNotFound  = `Your account may have been deactivated or deleted. Please contact our support team for assistance.`
@@ -58,9 +67,10 @@ func NewAuthenticationController(application app.AppInterface) *authenticationCo
 // 3. Verifies the response from the AuthKnight service.
 // 4. Based on the email, it will find or create a user in the database.
 // 5. Creates a new session for the user.
-// 6. Checks if the user has completed their profile.
-// 7. If not, it will redirect the user to the profile page.
-// 8. If yes, it will redirect the user to the home page, or the admin panel.
+// 6. Merges the guest cart, if any, into the user's cart.
+// 7. Checks if the user has completed their profile.
+// 8. If not, it will redirect the user to the profile page.
+// 9. If yes, it will redirect the user to the home page, or the admin panel.
 //
 // Parameters:
 // - w: http.ResponseWriter: the response writer.
@@ -94,6 +104,17 @@ func (c *authenticationController) Handler(w http.ResponseWriter, r *http.Reques
 		return helpers.ToFlashError(c.app.GetCacheStore(), w, r, "Authentication Provider Error. "+errorMessage, homeURL, 5)
 	}
 
+	return c.LoginWithEmail(w, r, email, backUrl)
+}
+
+// LoginWithEmail logs in the user with the email, once the caller verified
+// the visitor owns it (e.g. AuthKnight, or a magic link). The user is
+// created on the first login.
+//
+// The back URL must be validated by the caller.
+func (c *authenticationController) LoginWithEmail(w http.ResponseWriter, r *http.Request, email string, backUrl string) string {
+	homeURL := links.Website().Home()
+
 	user, err := c.userFindByEmailOrCreate(r.Context(), email, userstore.USER_STATUS_ACTIVE)
 
 	if err != nil {
@@ -109,6 +130,44 @@ func (c *authenticationController) Handler(w http.ResponseWriter, r *http.Reques
 		return helpers.ToFlashError(c.app.GetCacheStore(), w, r, msgAccountNotActive, homeURL, 5)
 	}
 
+	return c.LoginComplete(w, r, user, backUrl)
+}
+
+// LoginComplete logs in the user, once the identity of the user has been
+// verified by the caller (e.g. AuthKnight, or the password login).
+//
+// Users with two-factor authentication enabled are sent to enter their
+// code first, and the session is only started once the code is verified.
+//
+// The back URL must be validated by the caller.
+func (c *authenticationController) LoginComplete(w http.ResponseWriter, r *http.Request, user userstore.UserInterface, backUrl string) string {
+	if !ext.UserTwoFactorEnabled(user) {
+		return c.SessionStart(w, r, user, backUrl)
+	}
+
+	token, err := helpers.AuthTokenCreate(c.app.GetCacheStore(), TOKEN_PURPOSE_TWO_FACTOR, user.GetID(), twoFactorTokenExpiresSeconds)
+
+	if err != nil {
+		c.app.GetLogger().Error("At Auth Controller > LoginComplete > AuthTokenCreate", slog.String("error", err.Error()))
+		return helpers.ToFlashError(c.app.GetCacheStore(), w, r, "Error creating session", links.Website().Home(), 5)
+	}
+
+	http.Redirect(w, r, links.Auth().TwoFactor(token, backUrl), http.StatusSeeOther)
+	return ""
+}
+
+// SessionStart starts the session of the user, once the user passed all
+// the authentication steps (see LoginComplete).
+//
+// 1. Creates a new session for the user, and sets the auth cookie.
+// 2. Forgets the failed login attempts of the user.
+// 3. Merges the guest cart, if any, into the user's cart.
+// 4. Redirects to the back URL, or as calculated by calculateRedirectURL.
+//
+// The back URL must be validated by the caller.
+func (c *authenticationController) SessionStart(w http.ResponseWriter, r *http.Request, user userstore.UserInterface, backUrl string) string {
+	homeURL := links.Website().Home()
+
 	session := sessionstore.NewSession().
 		SetUserID(user.GetID()).
 		SetUserAgent(r.UserAgent()).
@@ -119,7 +178,7 @@ func (c *authenticationController) Handler(w http.ResponseWriter, r *http.Reques
 		session.SetExpiresAt(carbon.Now(carbon.UTC).AddHours(4).ToDateTimeString(carbon.UTC))
 	}
 
-	err = c.app.GetSessionStore().SessionCreate(r.Context(), session)
+	err := c.app.GetSessionStore().SessionCreate(r.Context(), session)
 
 	if err != nil {
 		c.app.GetLogger().Error("At Auth Controller > AnyIndex > Session Store Error", slog.String("error", err.Error()))
@@ -135,6 +194,15 @@ func (c *authenticationController) Handler(w http.ResponseWriter, r *http.Reques
 
 	auth.AuthCookieSet(w, r, session.GetKey(), cookieOpts...)
 
+	ext.AuthLockoutSucceed(c.app, user)
+
+	// Carry over anything the visitor added to the cart before logging in
+	if c.app.GetCacheStore() != nil {
+		if err := cart.NewCartController(c.app).TransferCacheToUser(r.Context(), w, r, user); err != nil {
+			c.app.GetLogger().Warn("At Auth Controller > AnyIndex > Cart Transfer Error", slog.String("error", err.Error()))
+		}
+	}
+
 	redirectUrl := c.calculateRedirectURL(user)
 
 	if backUrl != "" {
@@ -146,22 +214,6 @@ func (c *authenticationController) Handler(w http.ResponseWriter, r *http.Reques
 
 // == PRIVATE METHODS =========================================================
 
-func (c *authenticationController) findUserIDInBlindIndex(ctx context.Context, email string) (userID string, err error) {
-	recordsFound, err := c.app.GetBlindIndexStoreEmail().SearchValueList(ctx, blindindexstore.NewSearchValueQuery().
-		SetSearchValue(email).
-		SetSearchType(blindindexstore.SEARCH_TYPE_EQUALS))
-
-	if err != nil {
-		return "", err
-	}
-
-	if len(recordsFound) < 1 {
-		return "", nil
-	}
-
-	return recordsFound[0].SourceReferenceID(), nil
-}
-
 func (c *authenticationController) emailAndBackUrlFromAuthKnightRequest(r *http.Request) (email, backUrl, errorMessage string) {
 	once := strings.TrimSpace(req.GetStringTrimmed(r, "once"))
 
@@ -331,117 +383,6 @@ func (c *authenticationController) calculateRedirectURL(user userstore.UserInter
 	return redirectUrl
 }
 
-// userCreate creates a new user with privacy-first email encryption and blind indexing.
-//
-// ## Privacy & Security Architecture:
-//
-// This function implements a sophisticated privacy protection system that ensures
-// user email addresses are never stored in plaintext in the main database.
-//
-// ## User Creation Flow:
-//
-// 1. **Initial User Creation**: Creates user object with provided email (temporary)
-// 2. **Privacy Check**: Determines if vault store encryption is enabled
-// 3. **Email Encryption Path** (when vault enabled):
-//   - Creates encrypted email token using vault store
-//   - Token length: 20 characters with vault-specific encryption
-//   - Replaces plaintext email with encrypted token in database
-//   - Stores mapping in blind index for future lookups
-//
-// 4. **Standard Path** (when vault disabled):
-//   - Stores email directly in database (less secure)
-//   - Skips encryption and blind indexing steps
-//
-// ## Blind Index System:
-//
-// The blind index allows email-based user lookups without storing plaintext:
-// - Index Key: Hashed representation of the email
-// - Index Value: User ID reference for database lookup
-// - Search Method: Exact match with SEARCH_TYPE_EQUALS
-// - Privacy Benefit: No email addresses stored in searchable form
-//
-// ## Security Considerations:
-//   - Email tokens are encrypted using vault store key
-//   - Blind index prevents email enumeration attacks
-//   - All database operations use provided context for cancellation
-//   - Proper error handling prevents partial data corruption
-//
-// ## Error Handling:
-//   - Validates required stores (user store, vault store) before operations
-//   - Returns descriptive errors for missing configurations
-//   - Ensures atomic operations to prevent inconsistent state
-//
-// Parameters:
-//   - ctx: Context for database operations and cancellation
-//   - email: User's email address (will be encrypted if vault enabled)
-//   - status: Initial user status (e.g., userstore.USER_STATUS_ACTIVE)
-//
-// Returns:
-//   - userstore.UserInterface: Created user object with encrypted/plaintext email
-//   - error: Error if user creation, encryption, or indexing fails
-//
-// Example Usage:
-//
-//	user, err := c.userCreate(ctx, "user@example.com", userstore.USER_STATUS_ACTIVE)
-//	if err != nil {
-//		return nil, fmt.Errorf("failed to create user: %w", err)
-//	}
-//	// User.ID() contains the generated user ID
-//	// User.Email() contains either encrypted token or plaintext email
-func (c *authenticationController) userCreate(ctx context.Context, email string, status string) (userstore.UserInterface, error) {
-	user := userstore.NewUser().
-		SetStatus(status).
-		SetEmail(email)
-
-	if c.app.GetUserStore() == nil {
-		return nil, errors.New("user store is nil")
-	}
-
-	if c.app.GetConfig().GetUserStoreVaultEnabled() && c.app.GetVaultStore() == nil {
-		return nil, errors.New(`vault store is nil`)
-	}
-
-	err := c.app.GetUserStore().UserCreate(ctx, user)
-
-	if err != nil {
-		return nil, err
-	}
-
-	if !c.app.GetConfig().GetUserStoreVaultEnabled() {
-		return user, nil
-	}
-
-	if c.app.GetVaultStore() == nil {
-		return nil, errors.New(`vault store is nil`)
-	}
-
-	emailToken, err := c.app.GetVaultStore().TokenCreate(ctx, email, c.app.GetConfig().GetVaultStoreKey(), 20)
-
-	if err != nil {
-		return nil, err
-	}
-
-	user.SetEmail(emailToken)
-
-	err = c.app.GetUserStore().UserUpdate(ctx, user)
-
-	if err != nil {
-		return nil, err
-	}
-
-	searchValue := blindindexstore.NewSearchValue().
-		SetSourceReferenceID(user.GetID()).
-		SetSearchValue(email)
-
-	err = c.app.GetBlindIndexStoreEmail().SearchValueCreate(ctx, searchValue)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return user, nil
-}
-
 // userFindByEmailOrCreate finds or creates a user based on the provided email.
 //
 // Business Logic:
@@ -466,45 +407,19 @@ func (c *authenticationController) userFindByEmailOrCreate(ctx context.Context,
 		return nil, errors.New("user store is nil")
 	}
 
-	if c.app.GetConfig().GetUserStoreVaultEnabled() {
-		if c.app.GetVaultStore() == nil {
-			return nil, errors.New(`vault store is nil`)
-		}
+	user, err := ext.UserFindByEmail(ctx, c.app, email)
 
-		userID, err := c.findUserIDInBlindIndex(ctx, email)
-		if err != nil {
-			return nil, err
-		}
-
-		if userID == "" {
-			return c.userCreate(ctx, email, status)
-		}
-
-		user, err := c.app.GetUserStore().UserFindByID(ctx, userID)
-
-		if err != nil {
-			return nil, err
-		}
-
-		if user == nil {
-			c.app.GetLogger().Warn("At Auth Controller > userFindByEmailOrCreate",
-				slog.String("error", "User not found, even though email was found in the blind index, and user ID returned successfully"),
-				slog.String("user", userID))
-			return nil, nil
-		}
-
-		return user, nil
+	if errors.Is(err, ext.ErrUserNotFoundForBlindIndex) {
+		return nil, nil
 	}
 
-	user, err := c.app.GetUserStore().UserFindByEmail(ctx, email)
-
 	if err != nil {
 		return nil, err
 	}
 
-	if user == nil {
-		return c.userCreate(ctx, email, status)
+	if user != nil {
+		return user, nil
 	}
 
-	return user, nil
+	return ext.UserCreateWithEmail(ctx, c.app, email, status)
 }
@@ -5,10 +5,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"project/internal/ext"
+	"project/internal/links"
 	"project/internal/testutils"
+	"strings"
 	"testing"
 
 	"github.com/dracory/test"
+	"github.com/dracory/userstore"
 )
 
 func TestAuthControllerOnceIsRequired(t *testing.T) {
@@ -278,3 +282,39 @@ func TestAuthController_NewController(t *testing.T) {
 		t.Fatal("Controller app should not be nil")
 	}
 }
+
+func TestAuthController_LoginCompleteAsksForTwoFactor(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetVaultStoreUsed(true)
+	cfg.SetVaultStoreKey("test-key")
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	user, err := ext.UserCreateWithEmail(context.Background(), app, "test@test.com", userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ext.UserTwoFactorEnable(context.Background(), app, user, "JBSWY3DPEHPK3PXP"); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	_ = NewAuthenticationController(app).LoginComplete(recorder, req, user, "")
+
+	if recorder.Code != http.StatusSeeOther {
+		t.Fatal(`Response MUST be 303`, recorder.Code)
+	}
+
+	location := recorder.Header().Get("Location")
+	if !strings.Contains(location, links.AUTH_TWO_FACTOR) {
+		t.Fatal(`Response MUST redirect to the two-factor step, but got: `, location)
+	}
+
+	if recorder.Header().Get("Set-Cookie") != "" {
+		t.Fatal(`Session MUST NOT be started before the two-factor step`)
+	}
+}
@@ -3,6 +3,9 @@ package login
 import (
 	"net/http"
 	"project/internal/app"
+	"project/internal/controllers/auth/magiclink"
+	"project/internal/controllers/auth/oauthlogin"
+	"project/internal/controllers/auth/password"
 	"project/internal/helpers"
 	"project/internal/links"
 	"strings"
@@ -30,6 +33,21 @@ func (controller *loginController) Handler(w http.ResponseWriter, r *http.Reques
 		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `vault store is required`, homeURL, 5)
 	}
 
+	// First-party email/password login, instead of AuthKnight
+	if controller.app.GetConfig().GetPasswordAuthEnabled() {
+		return password.NewLoginController(controller.app).Handler(w, r)
+	}
+
+	// First-party magic link login, instead of AuthKnight
+	if controller.app.GetConfig().GetMagicLinkEnabled() {
+		return magiclink.NewMagicLinkController(controller.app).Handler(w, r)
+	}
+
+	// The social login providers, with AuthKnight as the alternative
+	if oauthlogin.Enabled(controller.app) {
+		return oauthlogin.NewOAuthLoginController(controller.app).Handler(w, r)
+	}
+
 	backUrl := req.GetStringTrimmedOr(r, "back_url", userURL)
 
 	// Ensure back_url is part of our domain (contains our root URL)
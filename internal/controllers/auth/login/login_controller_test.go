@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"project/internal/testutils"
+	"strings"
 	"testing"
 
 	"github.com/dracory/test"
@@ -153,3 +154,25 @@ func TestLoginControllerHandler_InvalidBackURL(t *testing.T) {
 		t.Fatal(`Response MUST be 303`, recorder.Code)
 	}
 }
+
+func TestLoginControllerHandler_PasswordAuthShowsLoginForm(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetUserStoreUsed(true)
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetPasswordAuthEnabled(true)
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	body, response, err := test.CallStringEndpoint(http.MethodGet, NewLoginController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatal(`Response MUST be 200, but got: `, response.StatusCode)
+	}
+
+	if !strings.Contains(body, `name="password"`) {
+		t.Fatal(`Response MUST contain the password login form`)
+	}
+}
@@ -1,6 +1,7 @@
 package logout
 
 import (
+	"log/slog"
 	"net/http"
 	"project/internal/app"
 	"project/internal/helpers"
@@ -17,7 +18,16 @@ func NewLogoutController(app app.AppInterface) *logoutController {
 	return &logoutController{app: app}
 }
 
+// AnyIndex logs the user out. The session is deleted too, so it is no
+// longer listed on the devices and sessions page, and the cookie cannot
+// be reused.
 func (controller *logoutController) AnyIndex(w http.ResponseWriter, r *http.Request) string {
+	if session := helpers.GetAuthSession(r); session != nil && controller.app.GetSessionStore() != nil {
+		if err := controller.app.GetSessionStore().SessionDelete(r.Context(), session); err != nil {
+			controller.app.GetLogger().Error("At logoutController > AnyIndex > SessionDelete", slog.String("error", err.Error()))
+		}
+	}
+
 	auth.AuthCookieRemove(w, r)
 
 	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "You have been logged out successfully", links.Website().Home(), 5)
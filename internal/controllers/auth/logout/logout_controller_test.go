@@ -1,8 +1,10 @@
 package logout
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"project/internal/config"
 	"project/internal/links"
 	"project/internal/testutils"
 	"testing"
@@ -127,3 +129,34 @@ func TestLogoutControllerHandler_LogoutWithoutCookie(t *testing.T) {
 		t.Fatal(`Flash message MUST contain redirect to home page, but got: `, flashMessage.Url)
 	}
 }
+
+func TestLogoutControllerHandler_DeletesSession(t *testing.T) {
+	app := testutils.Setup(
+		testutils.WithCacheStore(true),
+		testutils.WithSessionStore(true),
+		testutils.WithUserStore(true),
+	)
+
+	req, err := test.NewRequest(http.MethodGet, "/", test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, session, err := testutils.SeedUserAndSession(app.GetUserStore(), app.GetSessionStore(), test.USER_01, req, 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req = req.WithContext(context.WithValue(req.Context(), config.AuthenticatedSessionContextKey{}, session))
+
+	_ = NewLogoutController(app).AnyIndex(httptest.NewRecorder(), req)
+
+	deleted, err := app.GetSessionStore().SessionFindByID(context.Background(), session.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if deleted != nil {
+		t.Fatal("expected the session to be deleted on logout")
+	}
+}
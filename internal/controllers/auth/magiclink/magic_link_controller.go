@@ -0,0 +1,204 @@
+// Package magiclink implements the passwordless login with a single use
+// link sent by email, handled by the application instead of AuthKnight.
+// It is enabled by the AUTH_MAGIC_LINK_ENABLED setting.
+package magiclink
+
+import (
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"project/internal/app"
+	"project/internal/controllers/auth/oauthlogin"
+	"project/internal/emails"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	authrules "project/internal/rules/auth"
+	"strings"
+
+	"github.com/dracory/bs"
+	"github.com/dracory/cdn"
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+)
+
+// TOKEN_PURPOSE_MAGIC_LINK is the purpose of the login link tokens,
+// which hold the email of the user
+const TOKEN_PURPOSE_MAGIC_LINK = "magic_link"
+
+// magicLinkExpiresMinutes is the lifetime of the login links
+const magicLinkExpiresMinutes = 15
+
+const msgMagicLinkSent = "If this email can be used to log in, we have sent you a login link. Please check your inbox."
+
+// == CONTROLLER ==============================================================
+
+// magicLinkController sends a login link to the email of the visitor
+type magicLinkController struct {
+	app app.AppInterface
+}
+
+type magicLinkControllerData struct {
+	email   string
+	backUrl string
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewMagicLinkController creates a new magic link request controller
+func NewMagicLinkController(app app.AppInterface) *magicLinkController {
+	return &magicLinkController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *magicLinkController) Handler(w http.ResponseWriter, r *http.Request) string {
+	homeURL := links.Website().Home()
+
+	if controller.app.GetUserStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `user store is required`, homeURL, 5)
+	}
+
+	if controller.app.GetCacheStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `cache store is required`, homeURL, 5)
+	}
+
+	data := controller.prepareData(r)
+
+	if r.Method != http.MethodPost {
+		return controller.page(r, data, "", "")
+	}
+
+	if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+		return controller.page(r, data, "Your session has expired. Please try again.", "")
+	}
+
+	if address, err := mail.ParseAddress(data.email); err != nil || address.Address != data.email {
+		return controller.page(r, data, "Please enter a valid email address", "")
+	}
+
+	emailAllowed := authrules.NewEmailAllowedRule(controller.app, data.email)
+	if emailAllowed.Fails() {
+		return controller.page(r, data, emailAllowed.FailMessageFirst(), "")
+	}
+
+	if controller.canLogin(r, data.email) {
+		controller.send(data)
+	}
+
+	// Same response whether a link was sent or not, so the form cannot
+	// be used to find out who has an account
+	return controller.page(r, data, "", msgMagicLinkSent)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *magicLinkController) prepareData(r *http.Request) magicLinkControllerData {
+	homeURL := links.Website().Home()
+
+	backUrl := req.GetStringTrimmed(r, "back_url")
+
+	// Ensure back_url is part of our domain (contains our root URL)
+	if !strings.HasPrefix(backUrl, homeURL) {
+		backUrl = ""
+	}
+
+	return magicLinkControllerData{
+		email:   strings.ToLower(req.GetStringTrimmed(r, "email")),
+		backUrl: backUrl,
+	}
+}
+
+// canLogin returns whether the email belongs to an active user, or can
+// be used to register, as the user is created on the first login
+func (controller *magicLinkController) canLogin(r *http.Request, email string) bool {
+	user, err := ext.UserFindByEmail(r.Context(), controller.app, email)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At magicLinkController > canLogin > UserFindByEmail", slog.String("error", err.Error()))
+		return false
+	}
+
+	if user != nil {
+		return user.IsActive()
+	}
+
+	return authrules.NewCanRegisterRule(controller.app, email).Passes()
+}
+
+// send sends the login link, failures are only logged as the response
+// must not tell whether a link was sent
+func (controller *magicLinkController) send(data magicLinkControllerData) {
+	token, err := helpers.AuthTokenCreate(controller.app.GetCacheStore(), TOKEN_PURPOSE_MAGIC_LINK, data.email, magicLinkExpiresMinutes*60)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At magicLinkController > send > AuthTokenCreate", slog.String("error", err.Error()))
+		return
+	}
+
+	loginURL := links.Auth().MagicLinkLogin(token, data.backUrl)
+
+	if err := emails.NewMagicLinkEmail(controller.app).Send(data.email, loginURL, magicLinkExpiresMinutes); err != nil {
+		controller.app.GetLogger().Error("At magicLinkController > send > Send", slog.String("error", err.Error()))
+	}
+}
+
+func (controller *magicLinkController) page(r *http.Request, data magicLinkControllerData, errorMessage string, infoMessage string) string {
+	form := hb.Form().
+		Method(http.MethodPost).
+		Action(links.Auth().MagicLink(data.backUrl)).
+		Child(hb.Input().
+			Type(hb.TYPE_HIDDEN).
+			Name("csrf_token").
+			Value(csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret()))).
+		Child(hb.Paragraph().
+			Class("text-muted small").
+			Text("Enter your email, and we will send you a link to log in. No password needed.")).
+		Child(hb.Div().
+			Class("mb-3").
+			Child(bs.FormLabel("Email")).
+			Child(bs.FormInput().
+				Type(hb.TYPE_EMAIL).
+				Name("email").
+				Value(data.email).
+				Attr("autocomplete", "email").
+				Attr("required", "required"))).
+		Child(bs.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn-primary w-100 py-2").
+			Text("Email me a login link"))
+
+	content := hb.Div().
+		Child(form).
+		Child(oauthlogin.LoginButtons(controller.app, data.backUrl))
+
+	return authPage(controller.app, r, "Log in", errorMessage, infoMessage, content)
+}
+
+// authPage renders the content in a card in the middle of a blank page,
+// like the other authentication pages
+func authPage(app app.AppInterface, r *http.Request, title string, errorMessage string, infoMessage string, content hb.TagInterface) string {
+	card := hb.Div().
+		Class("card").
+		Style("border-radius:24px;box-shadow:0 20px 60px rgba(33,37,41,0.08);").
+		Child(hb.Div().
+			Class("card-body p-4").
+			Child(hb.Heading1().Class("h4 mb-4 text-center").Text(title)).
+			ChildIf(errorMessage != "", bs.Alert().Class("alert-danger").Text(errorMessage)).
+			ChildIf(infoMessage != "", bs.Alert().Class("alert-info").Text(infoMessage)).
+			Child(content))
+
+	return layouts.NewBlankLayout(app, r, layouts.Options{
+		Title: title,
+		Content: hb.Div().
+			Class("container py-5").
+			Style("max-width:480px;").
+			Child(hb.Div().Class("text-center mb-4").Child(hb.Raw(layouts.LogoHTML()))).
+			Child(card),
+		ScriptURLs: []string{cdn.BootstrapJs_5_3_3()},
+		StyleURLs:  []string{cdn.BootstrapIconsCss_1_11_3()},
+		Styles:     []string{`body{background:rgba(128,0,128,0.05);}`},
+	}).ToHTML()
+}
@@ -0,0 +1,121 @@
+package magiclink
+
+import (
+	"net/http"
+	"net/url"
+	"project/internal/app"
+	"project/internal/testutils"
+	"strings"
+	"testing"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/test"
+)
+
+const testCsrfSecret = "test-csrf-secret"
+
+func setupMagicLinkApp(t *testing.T) app.AppInterface {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCsrfSecret(testCsrfSecret)
+	cfg.SetMagicLinkEnabled(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetEmailsAllowedAccess([]string{})
+
+	return testutils.Setup(testutils.WithCfg(cfg))
+}
+
+func csrfValues(values map[string]string) url.Values {
+	form := url.Values{"csrf_token": {csrf.TokenGenerate(testCsrfSecret)}}
+	for key, value := range values {
+		form.Set(key, value)
+	}
+	return form
+}
+
+func TestMagicLinkController_ShowsForm(t *testing.T) {
+	app := setupMagicLinkApp(t)
+
+	body, response, err := test.CallStringEndpoint(http.MethodGet, NewMagicLinkController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", response.StatusCode)
+	}
+
+	if !strings.Contains(body, `name="email"`) || !strings.Contains(body, "Email me a login link") {
+		t.Fatal("expected the magic link form")
+	}
+}
+
+func TestMagicLinkController_InvalidEmail(t *testing.T) {
+	app := setupMagicLinkApp(t)
+
+	body, _, err := test.CallStringEndpoint(http.MethodPost, NewMagicLinkController(app).Handler, test.NewRequestOptions{
+		FormValues: csrfValues(map[string]string{"email": "not-an-email"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "Please enter a valid email address") {
+		t.Fatal("expected the invalid email error")
+	}
+}
+
+func TestMagicLinkController_CsrfRequired(t *testing.T) {
+	app := setupMagicLinkApp(t)
+
+	body, _, err := test.CallStringEndpoint(http.MethodPost, NewMagicLinkController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{"email": {"user@test.com"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "Your session has expired") {
+		t.Fatal("expected a CSRF error")
+	}
+}
+
+func TestMagicLinkController_SameResponseWhenRegistrationDisabled(t *testing.T) {
+	app := setupMagicLinkApp(t)
+	app.GetConfig().SetRegistrationEnabled(false)
+
+	body, _, err := test.CallStringEndpoint(http.MethodPost, NewMagicLinkController(app).Handler, test.NewRequestOptions{
+		FormValues: csrfValues(map[string]string{"email": "unknown@test.com"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, msgMagicLinkSent) {
+		t.Fatal("expected the link sent message")
+	}
+}
+
+func TestMagicLinkController_CanLogin(t *testing.T) {
+	app := setupMagicLinkApp(t)
+	controller := NewMagicLinkController(app)
+
+	r, err := test.NewRequest(http.MethodGet, "/", test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !controller.canLogin(r, "new@test.com") {
+		t.Error("expected new emails to be able to log in when registration is enabled")
+	}
+
+	app.GetConfig().SetRegistrationEnabled(false)
+
+	if controller.canLogin(r, "new@test.com") {
+		t.Error("expected new emails not to be able to log in when registration is disabled")
+	}
+}
@@ -0,0 +1,104 @@
+package magiclink
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/controllers/auth/authentication"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/links"
+	"strings"
+
+	"github.com/dracory/bs"
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+)
+
+const msgMagicLinkInvalid = "The login link is invalid or has expired. Please request a new one."
+
+// == CONTROLLER ==============================================================
+
+// magicLinkLoginController logs in the user the login link was sent to.
+//
+// Opening the link (GET) only asks to confirm, and the token is used up
+// on the confirmation (POST), as email scanners open the links in the
+// emails they check, which would use up the token before the user.
+type magicLinkLoginController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewMagicLinkLoginController creates a new magic link login controller
+func NewMagicLinkLoginController(app app.AppInterface) *magicLinkLoginController {
+	return &magicLinkLoginController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *magicLinkLoginController) Handler(w http.ResponseWriter, r *http.Request) string {
+	homeURL := links.Website().Home()
+
+	if controller.app.GetUserStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `user store is required`, homeURL, 5)
+	}
+
+	if controller.app.GetSessionStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `session store is required`, homeURL, 5)
+	}
+
+	token := req.GetStringTrimmed(r, "token")
+	backUrl := req.GetStringTrimmed(r, "back_url")
+
+	// Ensure back_url is part of our domain (contains our root URL)
+	if !strings.HasPrefix(backUrl, homeURL) {
+		backUrl = ""
+	}
+
+	if r.Method != http.MethodPost {
+		return controller.page(r, token, backUrl)
+	}
+
+	if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+		return controller.page(r, token, backUrl)
+	}
+
+	// The links are not tied to an account, so the invalid ones are only
+	// counted for the IP address
+	if wait := ext.AuthLockoutWait(controller.app, nil, req.GetIP(r)); wait > 0 {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, ext.AuthLockoutWaitMessage(wait), links.Auth().MagicLink(backUrl), 10)
+	}
+
+	email, err := helpers.AuthTokenConsume(controller.app.GetCacheStore(), controller.app.GetCustomStore(), TOKEN_PURPOSE_MAGIC_LINK, token)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At magicLinkLoginController > Handler > AuthTokenConsume", slog.String("error", err.Error()))
+	}
+
+	if email == "" {
+		ext.AuthLockoutFail(r.Context(), controller.app, nil, req.GetIP(r))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgMagicLinkInvalid, links.Auth().MagicLink(backUrl), 10)
+	}
+
+	return authentication.NewAuthenticationController(controller.app).LoginWithEmail(w, r, email, backUrl)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *magicLinkLoginController) page(r *http.Request, token string, backUrl string) string {
+	form := hb.Form().
+		Method(http.MethodPost).
+		Action(links.Auth().MagicLinkLogin(token, backUrl)).
+		Child(hb.Input().
+			Type(hb.TYPE_HIDDEN).
+			Name("csrf_token").
+			Value(csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret()))).
+		Child(bs.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn-primary w-100 py-2").
+			Text("Continue to log in"))
+
+	return authPage(controller.app, r, "Log in", "", "", form)
+}
@@ -0,0 +1,102 @@
+package magiclink
+
+import (
+	"net/http"
+	"net/url"
+	"project/internal/helpers"
+	"project/internal/testutils"
+	"strings"
+	"testing"
+
+	"github.com/dracory/test"
+)
+
+func TestMagicLinkLoginController_GetAsksToConfirm(t *testing.T) {
+	app := setupMagicLinkApp(t)
+
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), TOKEN_PURPOSE_MAGIC_LINK, "user@test.com", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewMagicLinkLoginController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"token": {token}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "Continue to log in") {
+		t.Fatal("expected the confirmation form")
+	}
+
+	// Opening the link does not use up the token
+	email, _ := helpers.AuthTokenFind(app.GetCacheStore(), TOKEN_PURPOSE_MAGIC_LINK, token)
+	if email != "user@test.com" {
+		t.Fatal("expected the token to still be valid")
+	}
+}
+
+func TestMagicLinkLoginController_InvalidToken(t *testing.T) {
+	app := setupMagicLinkApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewMagicLinkLoginController(app).Handler, test.NewRequestOptions{
+		FormValues: csrfValues(map[string]string{"token": "unknown"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" || flashMessage.Message != msgMagicLinkInvalid {
+		t.Fatalf("expected the invalid link error, got %v", flashMessage)
+	}
+}
+
+func TestMagicLinkLoginController_LogsInOnce(t *testing.T) {
+	app := setupMagicLinkApp(t)
+
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), TOKEN_PURPOSE_MAGIC_LINK, "user@test.com", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := csrfValues(map[string]string{"token": token})
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewMagicLinkLoginController(app).Handler, test.NewRequestOptions{
+		FormValues: values,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" || flashMessage.Message != "Login was successful" {
+		t.Fatalf("expected a successful login, got %v", flashMessage)
+	}
+
+	// The link can only be used once
+	_, response, err = test.CallStringEndpoint(http.MethodPost, NewMagicLinkLoginController(app).Handler, test.NewRequestOptions{
+		FormValues: values,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err = testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Message != msgMagicLinkInvalid {
+		t.Fatalf("expected the used link to be invalid, got %v", flashMessage)
+	}
+}
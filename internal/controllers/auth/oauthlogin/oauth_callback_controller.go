@@ -0,0 +1,282 @@
+package oauthlogin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/controllers/auth/authentication"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/links"
+	"project/internal/oauth"
+	authrules "project/internal/rules/auth"
+
+	"github.com/dracory/req"
+	"github.com/dracory/userstore"
+	"github.com/dromara/carbon/v2"
+)
+
+const (
+	msgOAuthExpired         = "Your login has expired. Please try again."
+	msgOAuthCancelled       = "The login was cancelled."
+	msgOAuthFailed          = "We could not log you in. Please try again."
+	msgOAuthEmailUnverified = "Your email address is not verified with this provider. Please log in another way, and link the provider from your connected accounts."
+	msgOAuthAccountNotFound = "Your account may have been deactivated or deleted. Please contact our support team for assistance."
+	msgOAuthLinkedToOther   = "This account is already linked to another user."
+)
+
+// == CONTROLLER ==============================================================
+
+// oauthCallbackController receives the user back from the provider, and
+// logs in the user (or links the provider to the logged in user).
+//
+// On login the user is found by the link of the identity, or else by the
+// email, if the provider verified it, in which case the identity is linked
+// to the user, created if needed, for the next logins.
+type oauthCallbackController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewOAuthCallbackController creates a new social login callback controller
+func NewOAuthCallbackController(app app.AppInterface) *oauthCallbackController {
+	return &oauthCallbackController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *oauthCallbackController) Handler(w http.ResponseWriter, r *http.Request) string {
+	homeURL := links.Website().Home()
+	loginURL := links.Auth().Login("")
+
+	if controller.app.GetUserStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `user store is required`, homeURL, 5)
+	}
+
+	if controller.app.GetSessionStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `session store is required`, homeURL, 5)
+	}
+
+	linkStore, err := oauth.NewStore(controller.app.GetCustomStore())
+
+	if err != nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `custom store is required`, homeURL, 5)
+	}
+
+	state, found := controller.stateConsume(r, req.GetStringTrimmed(r, "state"))
+	stateCookieRemove(w, stateCookieSecure(controller.app))
+
+	if !found {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgOAuthExpired, loginURL, 5)
+	}
+
+	if state.UserID != "" {
+		loginURL = links.User().ConnectedAccounts()
+	}
+
+	// The user declined, or the provider refused the login
+	if req.GetStringTrimmed(r, "error") != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgOAuthCancelled, loginURL, 5)
+	}
+
+	provider := oauth.ProviderFind(oauth.ProvidersFromConfig(controller.app.GetConfig()), state.Provider)
+
+	if provider == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgProviderNotFound, loginURL, 5)
+	}
+
+	identity, err := provider.Identity(r.Context(), req.GetStringTrimmed(r, "code"), state.Verifier, links.Auth().OAuthCallback())
+
+	if err != nil {
+		controller.app.GetLogger().Error("At oauthCallbackController > Handler > Identity",
+			slog.String("provider", provider.Key()),
+			slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgOAuthFailed, loginURL, 5)
+	}
+
+	if state.UserID != "" {
+		return controller.link(w, r, linkStore, provider, identity, state)
+	}
+
+	return controller.login(w, r, linkStore, provider, identity, state)
+}
+
+// == PRIVATE METHODS =========================================================
+
+// stateConsume returns the login in progress for the state token, which
+// can only be used once, and only by the browser holding its state cookie
+func (controller *oauthCallbackController) stateConsume(r *http.Request, token string) (oauthState, bool) {
+	if token == "" {
+		return oauthState{}, false
+	}
+
+	cookie, err := r.Cookie(STATE_COOKIE_NAME)
+
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(stateCookieValue(token))) != 1 {
+		return oauthState{}, false
+	}
+
+	value, err := helpers.AuthTokenConsume(controller.app.GetCacheStore(), controller.app.GetCustomStore(), TOKEN_PURPOSE_OAUTH_STATE, token)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At oauthCallbackController > stateConsume > AuthTokenConsume", slog.String("error", err.Error()))
+	}
+
+	if value == "" {
+		return oauthState{}, false
+	}
+
+	state := oauthState{}
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		controller.app.GetLogger().Error("At oauthCallbackController > stateConsume > Unmarshal", slog.String("error", err.Error()))
+		return oauthState{}, false
+	}
+
+	return state, state.Provider != ""
+}
+
+// link links the identity to the logged in user, who started the linking
+func (controller *oauthCallbackController) link(w http.ResponseWriter, r *http.Request, linkStore oauth.StoreInterface, provider oauth.ProviderInterface, identity oauth.Identity, state oauthState) string {
+	connectedAccountsURL := links.User().ConnectedAccounts()
+	authUser := helpers.GetAuthUser(r)
+
+	if authUser == nil || authUser.GetID() != state.UserID {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgOAuthExpired, links.Auth().Login(connectedAccountsURL), 5)
+	}
+
+	existing, err := linkStore.LinkFind(provider.Key(), identity.Subject)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At oauthCallbackController > link > LinkFind", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgOAuthFailed, connectedAccountsURL, 5)
+	}
+
+	if existing != nil && existing.UserID != authUser.GetID() {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgOAuthLinkedToOther, connectedAccountsURL, 5)
+	}
+
+	if existing != nil {
+		return helpers.ToFlashInfo(controller.app.GetCacheStore(), w, r, "Your "+provider.Name()+" account is already linked.", connectedAccountsURL, 5)
+	}
+
+	err = linkStore.LinkCreate(&oauth.Link{
+		Provider: provider.Key(),
+		Subject:  identity.Subject,
+		UserID:   authUser.GetID(),
+	})
+
+	if err != nil {
+		controller.app.GetLogger().Error("At oauthCallbackController > link > LinkCreate", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgOAuthFailed, connectedAccountsURL, 5)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "Your "+provider.Name()+" account is now linked. You can use it to log in.", connectedAccountsURL, 5)
+}
+
+// login logs in the user the identity is linked to, linking the identity
+// by the verified email on the first login
+func (controller *oauthCallbackController) login(w http.ResponseWriter, r *http.Request, linkStore oauth.StoreInterface, provider oauth.ProviderInterface, identity oauth.Identity, state oauthState) string {
+	loginURL := links.Auth().Login(state.BackUrl)
+
+	link, err := linkStore.LinkFind(provider.Key(), identity.Subject)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At oauthCallbackController > login > LinkFind", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgOAuthFailed, loginURL, 5)
+	}
+
+	var user userstore.UserInterface
+	errorMessage := ""
+
+	if link != nil {
+		user, err = controller.app.GetUserStore().UserFindByID(r.Context(), link.UserID)
+	} else {
+		user, errorMessage, err = controller.userFromEmail(r, linkStore, provider, identity)
+	}
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, loginURL, 5)
+	}
+
+	if err != nil {
+		controller.app.GetLogger().Error("At oauthCallbackController > login > User", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgOAuthFailed, loginURL, 5)
+	}
+
+	if user == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgOAuthAccountNotFound, loginURL, 5)
+	}
+
+	userActive := authrules.NewUserActiveRule(user)
+	if userActive.Fails() {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, userActive.FailMessageFirst(), loginURL, 5)
+	}
+
+	return authentication.NewAuthenticationController(controller.app).LoginComplete(w, r, user, state.BackUrl)
+}
+
+// userFromEmail finds the user by the email verified by the provider, or
+// creates the user if registrations are open, and links the identity to
+// the user. The error message is shown to the user as is
+func (controller *oauthCallbackController) userFromEmail(r *http.Request, linkStore oauth.StoreInterface, provider oauth.ProviderInterface, identity oauth.Identity) (user userstore.UserInterface, errorMessage string, err error) {
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, msgOAuthEmailUnverified, nil
+	}
+
+	emailAllowed := authrules.NewEmailAllowedRule(controller.app, identity.Email)
+	if emailAllowed.Fails() {
+		return nil, emailAllowed.FailMessageFirst(), nil
+	}
+
+	user, err = ext.UserFindByEmail(r.Context(), controller.app, identity.Email)
+
+	if errors.Is(err, ext.ErrUserNotFoundForBlindIndex) {
+		return nil, msgOAuthAccountNotFound, nil
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if user == nil {
+		canRegister := authrules.NewCanRegisterRule(controller.app, identity.Email)
+		if canRegister.Fails() {
+			return nil, canRegister.FailMessageFirst(), nil
+		}
+
+		user, err = ext.UserCreateWithEmail(r.Context(), controller.app, identity.Email, userstore.USER_STATUS_ACTIVE)
+
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// The provider verified the email, so the user does not need to
+	// verify it again to log in with a password
+	if user.GetMeta(config.USER_META_EMAIL_VERIFIED_AT) == "" {
+		if err := user.SetMeta(config.USER_META_EMAIL_VERIFIED_AT, carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)); err != nil {
+			return nil, "", err
+		}
+
+		if err := controller.app.GetUserStore().UserUpdate(r.Context(), user); err != nil {
+			return nil, "", err
+		}
+	}
+
+	err = linkStore.LinkCreate(&oauth.Link{
+		Provider: provider.Key(),
+		Subject:  identity.Subject,
+		UserID:   user.GetID(),
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return user, "", nil
+}
@@ -0,0 +1,253 @@
+package oauthlogin
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/ext"
+	"project/internal/oauth"
+	"project/internal/testutils"
+	"testing"
+
+	basetypes "github.com/dracory/base/types"
+	"github.com/dracory/csrf"
+	"github.com/dracory/test"
+)
+
+func callbackFlashMessage(t *testing.T, app app.AppInterface, query url.Values, userID string) *basetypes.FlashMessage {
+	t.Helper()
+
+	var response *http.Response
+	var err error
+
+	// The browser that started the login holds the state cookie
+	options := test.NewRequestOptions{
+		QueryParams: query,
+		Headers:     map[string]string{"Cookie": STATE_COOKIE_NAME + "=" + stateCookieValue(query.Get("state"))},
+	}
+
+	if userID == "" {
+		_, response, err = test.CallStringEndpoint(http.MethodGet, NewOAuthCallbackController(app).Handler, options)
+	} else {
+		_, response, err = testutils.CallStringHandlerAsUser(app, http.MethodGet, NewOAuthCallbackController(app).Handler, options, userID)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil {
+		t.Fatal("expected a flash message")
+	}
+
+	return flashMessage
+}
+
+func TestOAuthCallbackController_CreatesUserAndLinks(t *testing.T) {
+	app, server := setupOAuthApp(t)
+	server.SetUser(testutils.OIDCUser{Subject: "sub-1", Email: "new@test.com", EmailVerified: true})
+
+	flashMessage := callbackFlashMessage(t, app, loginAtProvider(t, app, server), "")
+
+	if flashMessage.Type != "success" || flashMessage.Message != "Login was successful" {
+		t.Fatalf("expected a successful login, got %v", flashMessage)
+	}
+
+	user, err := ext.UserFindByEmail(context.Background(), app, "new@test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user == nil {
+		t.Fatal("expected the user to be created")
+	}
+
+	if user.GetMeta(config.USER_META_EMAIL_VERIFIED_AT) == "" {
+		t.Fatal("expected the email to be marked as verified")
+	}
+
+	linkStore, err := oauth.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := linkStore.LinkFind(oauth.PROVIDER_OIDC, "sub-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if link == nil || link.UserID != user.GetID() {
+		t.Fatalf("expected the identity to be linked to the user, got %v", link)
+	}
+
+	// The next login finds the user by the link, even with another email
+	server.SetUser(testutils.OIDCUser{Subject: "sub-1", Email: "changed@test.com"})
+
+	flashMessage = callbackFlashMessage(t, app, loginAtProvider(t, app, server), "")
+
+	if flashMessage.Type != "success" {
+		t.Fatalf("expected a successful login by the link, got %v", flashMessage)
+	}
+}
+
+func TestOAuthCallbackController_EmailNotVerified(t *testing.T) {
+	app, server := setupOAuthApp(t)
+	server.SetUser(testutils.OIDCUser{Subject: "sub-1", Email: "new@test.com"})
+
+	flashMessage := callbackFlashMessage(t, app, loginAtProvider(t, app, server), "")
+
+	if flashMessage.Type != "error" || flashMessage.Message != msgOAuthEmailUnverified {
+		t.Fatalf("expected the unverified email error, got %v", flashMessage)
+	}
+
+	user, err := ext.UserFindByEmail(context.Background(), app, "new@test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user != nil {
+		t.Fatal("expected no user to be created")
+	}
+}
+
+func TestOAuthCallbackController_StateUsedOnce(t *testing.T) {
+	app, server := setupOAuthApp(t)
+	server.SetUser(testutils.OIDCUser{Subject: "sub-1", Email: "new@test.com", EmailVerified: true})
+
+	query := loginAtProvider(t, app, server)
+	callbackFlashMessage(t, app, query, "")
+
+	flashMessage := callbackFlashMessage(t, app, query, "")
+
+	if flashMessage.Type != "error" || flashMessage.Message != msgOAuthExpired {
+		t.Fatalf("expected the expired error, got %v", flashMessage)
+	}
+}
+
+func TestOAuthCallbackController_StateRequiresCookie(t *testing.T) {
+	app, server := setupOAuthApp(t)
+	server.SetUser(testutils.OIDCUser{Subject: "sub-1", Email: "new@test.com", EmailVerified: true})
+
+	// A callback URL of a login started in another browser
+	query := loginAtProvider(t, app, server)
+
+	for _, cookie := range []string{"", STATE_COOKIE_NAME + "=" + stateCookieValue("other-state")} {
+		_, response, err := test.CallStringEndpoint(http.MethodGet, NewOAuthCallbackController(app).Handler, test.NewRequestOptions{
+			QueryParams: query,
+			Headers:     map[string]string{"Cookie": cookie},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if flashMessage == nil || flashMessage.Type != "error" || flashMessage.Message != msgOAuthExpired {
+			t.Fatalf("expected the expired error without the state cookie, got %v", flashMessage)
+		}
+	}
+
+	user, err := ext.UserFindByEmail(context.Background(), app, "new@test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user != nil {
+		t.Fatal("expected no user to be created")
+	}
+}
+
+func TestOAuthCallbackController_Cancelled(t *testing.T) {
+	app, server := setupOAuthApp(t)
+
+	query := loginAtProvider(t, app, server)
+	query.Del("code")
+	query.Set("error", "access_denied")
+
+	flashMessage := callbackFlashMessage(t, app, query, "")
+
+	if flashMessage.Type != "error" || flashMessage.Message != msgOAuthCancelled {
+		t.Fatalf("expected the cancelled error, got %v", flashMessage)
+	}
+}
+
+func TestOAuthCallbackController_LinksToLoggedInUser(t *testing.T) {
+	app, server := setupOAuthApp(t)
+
+	// Linking does not need a verified email, the user is logged in
+	server.SetUser(testutils.OIDCUser{Subject: "sub-1"})
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodPost, NewOAuthLoginController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"provider": {oauth.PROVIDER_OIDC}},
+		FormValues: url.Values{
+			"action":     {ACTION_LINK},
+			"csrf_token": {csrf.TokenGenerate(testCsrfSecret)},
+		},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := authorize(t, server, response)
+
+	flashMessage := callbackFlashMessage(t, app, query, test.USER_01)
+
+	if flashMessage.Type != "success" {
+		t.Fatalf("expected the account to be linked, got %v", flashMessage)
+	}
+
+	linkStore, err := oauth.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userLinks, err := linkStore.LinkListByUserID(test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(userLinks) != 1 || userLinks[0].Subject != "sub-1" {
+		t.Fatalf("expected the identity to be linked to the user, got %v", userLinks)
+	}
+}
+
+func TestOAuthCallbackController_LinkedToAnotherUser(t *testing.T) {
+	app, server := setupOAuthApp(t)
+	server.SetUser(testutils.OIDCUser{Subject: "sub-1"})
+
+	linkStore, err := oauth.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := linkStore.LinkCreate(&oauth.Link{Provider: oauth.PROVIDER_OIDC, Subject: "sub-1", UserID: test.USER_02}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodPost, NewOAuthLoginController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"provider": {oauth.PROVIDER_OIDC}},
+		FormValues: url.Values{
+			"action":     {ACTION_LINK},
+			"csrf_token": {csrf.TokenGenerate(testCsrfSecret)},
+		},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage := callbackFlashMessage(t, app, authorize(t, server, response), test.USER_01)
+
+	if flashMessage.Type != "error" || flashMessage.Message != msgOAuthLinkedToOther {
+		t.Fatalf("expected the linked to another user error, got %v", flashMessage)
+	}
+}
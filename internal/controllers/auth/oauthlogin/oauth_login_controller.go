@@ -0,0 +1,279 @@
+// Package oauthlogin implements the social login with the OAuth2 and
+// OpenID Connect providers (see the oauth package), and the linking of
+// the providers to the account of a logged in user.
+//
+// The identities are linked to the users on the first login by their
+// verified email, found the same way as with AuthKnight (see
+// ext.UserFindByEmail), and the login is completed by the authentication
+// controller, so all the logins share the sessions, cookies and redirects.
+package oauthlogin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"project/internal/oauth"
+	"strings"
+
+	"github.com/dracory/cdn"
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/samber/lo"
+	"golang.org/x/oauth2"
+)
+
+// TOKEN_PURPOSE_OAUTH_STATE is the purpose of the state tokens, which hold
+// the login (or linking) in progress while the user is at the provider
+const TOKEN_PURPOSE_OAUTH_STATE = "oauth_state"
+
+// STATE_COOKIE_NAME is the name of the cookie binding the state token to
+// the browser that started the login, so a login started by someone else
+// cannot be completed in it (login CSRF)
+const STATE_COOKIE_NAME = "oauth_state"
+
+// ACTION_LINK links the provider to the account of the logged in user,
+// instead of logging in
+const ACTION_LINK = "link"
+
+// stateExpiresSeconds is the time the user has to log in at the provider
+const stateExpiresSeconds = 10 * 60
+
+const msgProviderNotFound = "This login provider is not available."
+
+// oauthState is the login in progress, stored for the state token
+type oauthState struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"`
+	BackUrl  string `json:"back_url"`
+	// UserID the user linking the provider, empty when logging in
+	UserID string `json:"user_id"`
+}
+
+// == CONTROLLER ==============================================================
+
+// oauthLoginController sends the user to log in at the provider.
+//
+// Without a provider, it shows the providers to choose from.
+type oauthLoginController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewOAuthLoginController creates a new social login controller
+func NewOAuthLoginController(app app.AppInterface) *oauthLoginController {
+	return &oauthLoginController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *oauthLoginController) Handler(w http.ResponseWriter, r *http.Request) string {
+	homeURL := links.Website().Home()
+
+	backUrl := req.GetStringTrimmed(r, "back_url")
+
+	// Ensure back_url is part of our domain (contains our root URL)
+	if !strings.HasPrefix(backUrl, homeURL) {
+		backUrl = ""
+	}
+
+	providers := oauth.ProvidersFromConfig(controller.app.GetConfig())
+	providerKey := req.GetStringTrimmed(r, "provider")
+
+	if providerKey == "" {
+		return controller.page(r, providers, backUrl)
+	}
+
+	provider := oauth.ProviderFind(providers, providerKey)
+
+	if provider == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgProviderNotFound, links.Auth().Login(backUrl), 5)
+	}
+
+	state := oauthState{
+		Provider: provider.Key(),
+		Verifier: oauth2.GenerateVerifier(),
+		BackUrl:  backUrl,
+	}
+
+	failURL := links.Auth().Login(backUrl)
+
+	// Linking changes the account, so it must be requested by the user
+	// with the form on the connected accounts page
+	if req.GetStringTrimmed(r, "action") == ACTION_LINK {
+		authUser := helpers.GetAuthUser(r)
+
+		if authUser == nil {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Please log in first.", links.Auth().Login(links.User().ConnectedAccounts()), 5)
+		}
+
+		if r.Method != http.MethodPost || !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", links.User().ConnectedAccounts(), 5)
+		}
+
+		state.UserID = authUser.GetID()
+		state.BackUrl = ""
+		failURL = links.User().ConnectedAccounts()
+	}
+
+	redirectURL, err := controller.authCodeURL(w, r, provider, state)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At oauthLoginController > Handler > authCodeURL",
+			slog.String("provider", provider.Key()),
+			slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, provider.Name()+" is not available at the moment. Please try again later.", failURL, 5)
+	}
+
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+	return ""
+}
+
+// == PRIVATE METHODS =========================================================
+
+// authCodeURL stores the state, binds it to the browser with the state
+// cookie, and returns the login URL of the provider
+func (controller *oauthLoginController) authCodeURL(w http.ResponseWriter, r *http.Request, provider oauth.ProviderInterface, state oauthState) (string, error) {
+	value, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := helpers.AuthTokenCreate(controller.app.GetCacheStore(), TOKEN_PURPOSE_OAUTH_STATE, string(value), stateExpiresSeconds)
+	if err != nil {
+		return "", err
+	}
+
+	stateCookieSet(w, token, stateCookieSecure(controller.app))
+
+	return provider.AuthCodeURL(r.Context(), token, state.Verifier, links.Auth().OAuthCallback())
+}
+
+func (controller *oauthLoginController) page(r *http.Request, providers []oauth.ProviderInterface, backUrl string) string {
+	// AuthKnight remains available for the users without a provider
+	content := hb.Div().
+		Child(ProviderButtons(providers, backUrl)).
+		Child(hb.Div().
+			Class("text-center mt-4 small").
+			Child(hb.Hyperlink().
+				Href(links.Auth().AuthKnightLogin(lo.CoalesceOrEmpty(backUrl, links.User().Home()))).
+				Text("Log in with your email instead")))
+
+	card := hb.Div().
+		Class("card").
+		Style("border-radius:24px;box-shadow:0 20px 60px rgba(33,37,41,0.08);").
+		Child(hb.Div().
+			Class("card-body p-4").
+			Child(hb.Heading1().Class("h4 mb-4 text-center").Text("Log in")).
+			Child(content))
+
+	return layouts.NewBlankLayout(controller.app, r, layouts.Options{
+		Title: "Log in",
+		Content: hb.Div().
+			Class("container py-5").
+			Style("max-width:480px;").
+			Child(hb.Div().Class("text-center mb-4").Child(hb.Raw(layouts.LogoHTML()))).
+			Child(card),
+		ScriptURLs: []string{cdn.BootstrapJs_5_3_3()},
+		StyleURLs:  []string{cdn.BootstrapIconsCss_1_11_3()},
+		Styles:     []string{`body{background:rgba(128,0,128,0.05);}`},
+	}).ToHTML()
+}
+
+// stateCookieSet writes the HttpOnly state cookie, holding the hash of the
+// state token. It is Lax, so it is sent on the redirect back from the
+// provider.
+func stateCookieSet(w http.ResponseWriter, token string, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     STATE_COOKIE_NAME,
+		Value:    stateCookieValue(token),
+		Path:     "/",
+		MaxAge:   stateExpiresSeconds,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// stateCookieRemove expires the state cookie
+func stateCookieRemove(w http.ResponseWriter, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     STATE_COOKIE_NAME,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// stateCookieValue returns the hash of the state token kept in the cookie
+func stateCookieValue(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func stateCookieSecure(app app.AppInterface) bool {
+	return app.GetConfig() == nil || !app.GetConfig().IsEnvDevelopment()
+}
+
+// Enabled returns whether the social login is available, which requires
+// a provider to be configured, and the custom store for the links
+func Enabled(app app.AppInterface) bool {
+	return app.GetCustomStore() != nil && len(oauth.ProvidersFromConfig(app.GetConfig())) > 0
+}
+
+// LoginButtons returns the buttons of the providers, to show under the
+// other login forms, or nil if the social login is not enabled
+func LoginButtons(app app.AppInterface, backUrl string) hb.TagInterface {
+	if !Enabled(app) {
+		return nil
+	}
+
+	divider := hb.Div().
+		Class("d-flex align-items-center my-4 text-muted small").
+		Child(hb.HR().Class("flex-grow-1")).
+		Child(hb.Span().Class("px-3").Text("or")).
+		Child(hb.HR().Class("flex-grow-1"))
+
+	return hb.Div().
+		Child(divider).
+		Child(ProviderButtons(oauth.ProvidersFromConfig(app.GetConfig()), backUrl))
+}
+
+// ProviderButtons returns the buttons to log in with each of the providers
+func ProviderButtons(providers []oauth.ProviderInterface, backUrl string) hb.TagInterface {
+	buttons := hb.Div().Class("d-grid gap-2")
+
+	for _, provider := range providers {
+		buttons.Child(hb.Hyperlink().
+			Class("btn btn-outline-dark py-2").
+			Href(links.Auth().OAuth(provider.Key(), backUrl)).
+			Child(hb.I().Class("bi " + ProviderIcon(provider.Key()) + " me-2")).
+			Text("Continue with " + provider.Name()))
+	}
+
+	return buttons
+}
+
+// ProviderIcon returns the Bootstrap icon of the provider
+func ProviderIcon(providerKey string) string {
+	switch providerKey {
+	case oauth.PROVIDER_GOOGLE:
+		return "bi-google"
+	case oauth.PROVIDER_GITHUB:
+		return "bi-github"
+	case oauth.PROVIDER_MICROSOFT:
+		return "bi-microsoft"
+	default:
+		return "bi-box-arrow-in-right"
+	}
+}
@@ -0,0 +1,212 @@
+package oauthlogin
+
+import (
+	"net/http"
+	"net/url"
+	"project/internal/app"
+	"project/internal/helpers"
+	"project/internal/links"
+	"project/internal/oauth"
+	"project/internal/testutils"
+	"strings"
+	"testing"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/test"
+)
+
+const testCsrfSecret = "test-csrf-secret"
+
+// setupOAuthApp returns an app with the stand-in OpenID Connect server
+// configured as the generic OIDC provider
+func setupOAuthApp(t *testing.T) (app.AppInterface, *testutils.OIDCServer) {
+	t.Helper()
+
+	server := testutils.NewOIDCServer()
+	t.Cleanup(server.Close)
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCsrfSecret(testCsrfSecret)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetEmailsAllowedAccess([]string{})
+	cfg.SetOAuthOIDCIssuerURL(server.URL())
+	cfg.SetOAuthOIDCClientID(testutils.OIDC_CLIENT_ID)
+	cfg.SetOAuthOIDCClientSecret(testutils.OIDC_CLIENT_SECRET)
+
+	return testutils.Setup(testutils.WithCfg(cfg)), server
+}
+
+// loginAtProvider starts the login, logs in at the stand-in server, and
+// returns the query the server sends back to the callback
+func loginAtProvider(t *testing.T, app app.AppInterface, server *testutils.OIDCServer) url.Values {
+	t.Helper()
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewOAuthLoginController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"provider": {oauth.PROVIDER_OIDC}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return authorize(t, server, response)
+}
+
+// authorize follows the redirect to the stand-in server, and returns the
+// query of the callback
+func authorize(t *testing.T, server *testutils.OIDCServer, response *http.Response) url.Values {
+	t.Helper()
+
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected a redirect to the provider, got %d", response.StatusCode)
+	}
+
+	callbackURL, err := server.Authorize(response.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(callbackURL, links.Auth().OAuthCallback()) {
+		t.Fatalf("expected a redirect to the callback, got %s", callbackURL)
+	}
+
+	callback, err := url.Parse(callbackURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return callback.Query()
+}
+
+func TestOAuthLoginController_ShowsProviders(t *testing.T) {
+	app, _ := setupOAuthApp(t)
+
+	body, response, err := test.CallStringEndpoint(http.MethodGet, NewOAuthLoginController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", response.StatusCode)
+	}
+
+	if !strings.Contains(body, "Continue with Single Sign-On") {
+		t.Fatal("expected the button of the OIDC provider")
+	}
+
+	if !strings.Contains(body, "Log in with your email instead") {
+		t.Fatal("expected the email login to remain available")
+	}
+}
+
+func TestOAuthLoginController_UnknownProvider(t *testing.T) {
+	app, _ := setupOAuthApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewOAuthLoginController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"provider": {oauth.PROVIDER_GOOGLE}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" || flashMessage.Message != msgProviderNotFound {
+		t.Fatalf("expected the provider not found error, got %v", flashMessage)
+	}
+}
+
+func TestOAuthLoginController_RedirectsWithStateAndPKCE(t *testing.T) {
+	app, server := setupOAuthApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewOAuthLoginController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"provider": {oauth.PROVIDER_OIDC}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	location, err := url.Parse(response.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(location.String(), server.URL()+"/authorize") {
+		t.Fatalf("expected a redirect to the provider, got %s", location)
+	}
+
+	query := location.Query()
+
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatal("expected the PKCE code challenge")
+	}
+
+	value, err := helpers.AuthTokenFind(app.GetCacheStore(), TOKEN_PURPOSE_OAUTH_STATE, query.Get("state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value == "" {
+		t.Fatal("expected the state to be stored")
+	}
+
+	var stateCookie *http.Cookie
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == STATE_COOKIE_NAME {
+			stateCookie = cookie
+		}
+	}
+
+	if stateCookie == nil || !stateCookie.HttpOnly || stateCookie.Value != stateCookieValue(query.Get("state")) {
+		t.Fatalf("expected the HttpOnly state cookie, got %v", stateCookie)
+	}
+}
+
+func TestOAuthLoginController_LinkRequiresLogin(t *testing.T) {
+	app, _ := setupOAuthApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewOAuthLoginController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"provider": {oauth.PROVIDER_OIDC}},
+		FormValues: url.Values{
+			"action":     {ACTION_LINK},
+			"csrf_token": {csrf.TokenGenerate(testCsrfSecret)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" || flashMessage.Message != "Please log in first." {
+		t.Fatalf("expected the login required error, got %v", flashMessage)
+	}
+}
+
+func TestOAuthLoginController_LinkRequiresCsrf(t *testing.T) {
+	app, _ := setupOAuthApp(t)
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewOAuthLoginController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"provider": {oauth.PROVIDER_OIDC}, "action": {ACTION_LINK}},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" {
+		t.Fatalf("expected linking without the form to fail, got %v", flashMessage)
+	}
+}
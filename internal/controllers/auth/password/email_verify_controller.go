@@ -0,0 +1,76 @@
+package password
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/helpers"
+	"project/internal/links"
+
+	"github.com/dracory/req"
+)
+
+// == CONTROLLER ==============================================================
+
+// emailVerifyController verifies the email address of the user the
+// verification link was sent to, so the user can log in
+type emailVerifyController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewEmailVerifyController creates a new email verification controller
+func NewEmailVerifyController(app app.AppInterface) *emailVerifyController {
+	return &emailVerifyController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *emailVerifyController) Handler(w http.ResponseWriter, r *http.Request) string {
+	homeURL := links.Website().Home()
+	loginURL := links.Auth().Login("")
+
+	if controller.app.GetUserStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `user store is required`, homeURL, 5)
+	}
+
+	token := req.GetStringTrimmed(r, "token")
+
+	userID, err := helpers.AuthTokenFind(controller.app.GetCacheStore(), TOKEN_PURPOSE_EMAIL_VERIFY, token)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At password.emailVerifyController > Handler > AuthTokenFind", slog.String("error", err.Error()))
+	}
+
+	if userID == "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "The verification link is invalid or has expired. Please log in to receive a new one.", loginURL, 10)
+	}
+
+	user, err := controller.app.GetUserStore().UserFindByID(r.Context(), userID)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At password.emailVerifyController > Handler > UserFindByID", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Sorry, the email could not be verified. Please try again later.", loginURL, 10)
+	}
+
+	if user == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "The verification link is invalid or has expired. Please log in to receive a new one.", loginURL, 10)
+	}
+
+	if err := emailVerifiedMark(user); err != nil {
+		controller.app.GetLogger().Error("At password.emailVerifyController > Handler > emailVerifiedMark", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Sorry, the email could not be verified. Please try again later.", loginURL, 10)
+	}
+
+	if err := controller.app.GetUserStore().UserUpdate(r.Context(), user); err != nil {
+		controller.app.GetLogger().Error("At password.emailVerifyController > Handler > UserUpdate", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Sorry, the email could not be verified. Please try again later.", loginURL, 10)
+	}
+
+	if err := helpers.AuthTokenDelete(controller.app.GetCacheStore(), TOKEN_PURPOSE_EMAIL_VERIFY, token); err != nil {
+		controller.app.GetLogger().Error("At password.emailVerifyController > Handler > AuthTokenDelete", slog.String("error", err.Error()))
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "Thank you, your email has been verified. You can now log in.", loginURL, 10)
+}
@@ -0,0 +1,72 @@
+package password
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"project/internal/helpers"
+	"project/internal/testutils"
+	"testing"
+
+	"github.com/dracory/test"
+)
+
+func TestEmailVerifyController_InvalidToken(t *testing.T) {
+	app := setupPasswordApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewEmailVerifyController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"token": {"unknown"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" {
+		t.Fatalf("expected an error, got %v", flashMessage)
+	}
+}
+
+func TestEmailVerifyController_VerifiesEmail(t *testing.T) {
+	app := setupPasswordApp(t)
+	user := seedPasswordUser(t, app, "user@test.com", "correct-horse", false)
+
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), TOKEN_PURPOSE_EMAIL_VERIFY, user.GetID(), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewEmailVerifyController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"token": {token}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected the email to be verified, got %v", flashMessage)
+	}
+
+	user, err = app.GetUserStore().UserFindByID(context.Background(), user.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !isEmailVerified(user) {
+		t.Fatal("expected the email to be verified")
+	}
+
+	userID, _ := helpers.AuthTokenFind(app.GetCacheStore(), TOKEN_PURPOSE_EMAIL_VERIFY, token)
+	if userID != "" {
+		t.Fatal("expected the token to be used up")
+	}
+}
@@ -0,0 +1,157 @@
+package password
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/controllers/auth/authentication"
+	"project/internal/controllers/auth/oauthlogin"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/links"
+	authrules "project/internal/rules/auth"
+	"strings"
+
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+)
+
+const msgInvalidCredentials = "Invalid email or password"
+
+// == CONTROLLER ==============================================================
+
+// loginController logs in the users with their email and password
+type loginController struct {
+	app app.AppInterface
+}
+
+type loginControllerData struct {
+	email    string
+	password string
+	backUrl  string
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewLoginController creates a new password login controller
+func NewLoginController(app app.AppInterface) *loginController {
+	return &loginController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *loginController) Handler(w http.ResponseWriter, r *http.Request) string {
+	homeURL := links.Website().Home()
+
+	if controller.app.GetUserStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `user store is required`, homeURL, 5)
+	}
+
+	if controller.app.GetSessionStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `session store is required`, homeURL, 5)
+	}
+
+	data := controller.prepareData(r)
+
+	if r.Method != http.MethodPost {
+		return controller.page(r, data, "", "")
+	}
+
+	if !isCsrfValid(controller.app, req.GetStringTrimmed(r, "csrf_token")) {
+		return controller.page(r, data, "Your session has expired. Please try again.", "")
+	}
+
+	if data.email == "" || data.password == "" {
+		return controller.page(r, data, "Email and password are required", "")
+	}
+
+	emailAllowed := authrules.NewEmailAllowedRule(controller.app, data.email)
+	if emailAllowed.Fails() {
+		return controller.page(r, data, emailAllowed.FailMessageFirst(), "")
+	}
+
+	user, err := ext.UserFindByEmail(r.Context(), controller.app, data.email)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At password.loginController > Handler > UserFindByEmail", slog.String("error", err.Error()))
+		return controller.page(r, data, msgInvalidCredentials, "")
+	}
+
+	// Slow down and then stop the guessing of the passwords, before the
+	// password is checked
+	if wait := ext.AuthLockoutWait(controller.app, user, req.GetIP(r)); wait > 0 {
+		return controller.page(r, data, ext.AuthLockoutWaitMessage(wait), "")
+	}
+
+	// Same message for unknown emails and wrong passwords, so the form
+	// cannot be used to find out who has an account
+	if !passwordMatches(user, data.password) {
+		ext.AuthLockoutFail(r.Context(), controller.app, user, req.GetIP(r))
+		return controller.page(r, data, msgInvalidCredentials, "")
+	}
+
+	userActive := authrules.NewUserActiveRule(user)
+	if userActive.Fails() {
+		return controller.page(r, data, userActive.FailMessageFirst(), "")
+	}
+
+	if !isEmailVerified(user) {
+		emailVerifySend(controller.app, user.GetID(), data.email)
+		return controller.page(r, data, "", "Please verify your email address first. We have sent you a new verification link.")
+	}
+
+	return authentication.NewAuthenticationController(controller.app).LoginComplete(w, r, user, data.backUrl)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *loginController) prepareData(r *http.Request) loginControllerData {
+	homeURL := links.Website().Home()
+
+	backUrl := req.GetStringTrimmed(r, "back_url")
+
+	// Ensure back_url is part of our domain (contains our root URL)
+	if !strings.HasPrefix(backUrl, homeURL) {
+		backUrl = ""
+	}
+
+	return loginControllerData{
+		email:    emailNormalize(req.GetStringTrimmed(r, "email")),
+		password: req.GetString(r, "password"),
+		backUrl:  backUrl,
+	}
+}
+
+func (controller *loginController) page(r *http.Request, data loginControllerData, errorMessage string, infoMessage string) string {
+	form := hb.Form().
+		Method(http.MethodPost).
+		Action(links.Auth().Login(data.backUrl)).
+		Child(inputCsrf(controller.app)).
+		Child(formGroup("Email", inputEmail(data.email))).
+		Child(formGroup("Password", inputPassword("password", "current-password"))).
+		Child(buttonSubmit("Log in"))
+
+	magicLink := hb.Div().
+		Class("text-center mt-3").
+		Child(hb.Hyperlink().
+			Class("btn btn-outline-secondary w-100").
+			Href(links.Auth().MagicLink(data.backUrl)).
+			Text("Email me a login link instead"))
+
+	footer := hb.Div().
+		Class("d-flex justify-content-between mt-4 small").
+		Child(hb.Hyperlink().Href(links.Auth().PasswordForgot()).Text("Forgot password?")).
+		ChildIf(controller.app.GetConfig().GetRegistrationEnabled(),
+			hb.Hyperlink().Href(links.Auth().Signup()).Text("Create an account"))
+
+	body := []hb.TagInterface{form}
+	if controller.app.GetConfig().GetMagicLinkEnabled() {
+		body = append(body, magicLink)
+	}
+	if buttons := oauthlogin.LoginButtons(controller.app, data.backUrl); buttons != nil {
+		body = append(body, buttons)
+	}
+	body = append(body, footer)
+
+	return page(controller.app, r, "Log in", card("Log in", errorMessage, infoMessage, body...))
+}
@@ -0,0 +1,151 @@
+package password
+
+import (
+	"net/http"
+	"net/url"
+	"project/internal/lockout"
+	"project/internal/testutils"
+	"strings"
+	"testing"
+
+	"github.com/dracory/auth/types"
+	"github.com/dracory/test"
+)
+
+func TestLoginController_ShowsForm(t *testing.T) {
+	app := setupPasswordApp(t)
+
+	body, response, err := test.CallStringEndpoint(http.MethodGet, NewLoginController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", response.StatusCode)
+	}
+
+	for _, expected := range []string{`name="email"`, `name="password"`, `name="csrf_token"`, "Forgot password?", "Create an account"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected the form to contain %q", expected)
+		}
+	}
+}
+
+func TestLoginController_CsrfRequired(t *testing.T) {
+	app := setupPasswordApp(t)
+	seedPasswordUser(t, app, "user@test.com", "correct-horse", true)
+
+	body, _, err := test.CallStringEndpoint(http.MethodPost, NewLoginController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{"email": {"user@test.com"}, "password": {"correct-horse"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "Your session has expired") {
+		t.Fatal("expected a CSRF error")
+	}
+}
+
+func TestLoginController_WrongPassword(t *testing.T) {
+	app := setupPasswordApp(t)
+	seedPasswordUser(t, app, "user@test.com", "correct-horse", true)
+
+	for _, values := range []map[string]string{
+		{"email": "user@test.com", "password": "wrong-horse"},
+		{"email": "unknown@test.com", "password": "correct-horse"},
+	} {
+		body, _, err := test.CallStringEndpoint(http.MethodPost, NewLoginController(app).Handler, test.NewRequestOptions{
+			FormValues: formValues(values),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(body, msgInvalidCredentials) {
+			t.Fatalf("expected %q for %v", msgInvalidCredentials, values)
+		}
+	}
+}
+
+func TestLoginController_EmailNotVerified(t *testing.T) {
+	app := setupPasswordApp(t)
+	seedPasswordUser(t, app, "user@test.com", "correct-horse", false)
+
+	body, _, err := test.CallStringEndpoint(http.MethodPost, NewLoginController(app).Handler, test.NewRequestOptions{
+		FormValues: formValues(map[string]string{"email": "user@test.com", "password": "correct-horse"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "Please verify your email address first") {
+		t.Fatal("expected the email verification message")
+	}
+}
+
+func TestLoginController_Success(t *testing.T) {
+	app := setupPasswordApp(t)
+	seedPasswordUser(t, app, "user@test.com", "correct-horse", true)
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewLoginController(app).Handler, test.NewRequestOptions{
+		FormValues: formValues(map[string]string{"email": " User@Test.com", "password": "correct-horse"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" || flashMessage.Message != "Login was successful" {
+		t.Fatalf("expected a successful login, got %v", flashMessage)
+	}
+
+	hasAuthCookie := false
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == types.CookieName && cookie.Value != "" {
+			hasAuthCookie = true
+		}
+	}
+
+	if !hasAuthCookie {
+		t.Fatal("expected the auth cookie to be set")
+	}
+}
+
+func TestLoginController_LockoutAfterFailures(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetCsrfSecret(testCsrfSecret)
+	cfg.SetPasswordAuthEnabled(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+	seedPasswordUser(t, app, "user@test.com", "correct-horse", true)
+
+	login := func(password string) string {
+		body, _, err := test.CallStringEndpoint(http.MethodPost, NewLoginController(app).Handler, test.NewRequestOptions{
+			FormValues: formValues(map[string]string{"email": "user@test.com", "password": password}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	for range lockout.PolicyFor(lockout.SUBJECT_ACCOUNT).DelayAfter {
+		if body := login("wrong-horse"); !strings.Contains(body, msgInvalidCredentials) {
+			t.Fatalf("expected %q", msgInvalidCredentials)
+		}
+	}
+
+	// Even the right password must wait, so the passwords cannot be guessed quickly
+	if body := login("correct-horse"); !strings.Contains(body, "Too many failed attempts") {
+		t.Fatal("expected the login to be delayed after the failures")
+	}
+}
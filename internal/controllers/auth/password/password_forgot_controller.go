@@ -0,0 +1,86 @@
+package password
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/links"
+
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+)
+
+const msgPasswordResetSent = "If an account exists for this email, we have sent you a link to reset your password."
+
+// == CONTROLLER ==============================================================
+
+// passwordForgotController sends a link to reset the password to the email
+// of an account. The response does not tell whether the email has one
+type passwordForgotController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewPasswordForgotController creates a new forgot password controller
+func NewPasswordForgotController(app app.AppInterface) *passwordForgotController {
+	return &passwordForgotController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *passwordForgotController) Handler(w http.ResponseWriter, r *http.Request) string {
+	homeURL := links.Website().Home()
+
+	if controller.app.GetUserStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `user store is required`, homeURL, 5)
+	}
+
+	email := emailNormalize(req.GetStringTrimmed(r, "email"))
+
+	if r.Method != http.MethodPost {
+		return controller.page(r, email, "")
+	}
+
+	if !isCsrfValid(controller.app, req.GetStringTrimmed(r, "csrf_token")) {
+		return controller.page(r, email, "Your session has expired. Please try again.")
+	}
+
+	if !isEmailValid(email) {
+		return controller.page(r, email, "Please enter a valid email address")
+	}
+
+	user, err := ext.UserFindByEmail(r.Context(), controller.app, email)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At password.passwordForgotController > Handler > UserFindByEmail", slog.String("error", err.Error()))
+	}
+
+	if user != nil && user.IsActive() {
+		passwordResetSend(controller.app, user.GetID(), email)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, msgPasswordResetSent, links.Auth().Login(""), 10)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *passwordForgotController) page(r *http.Request, email string, errorMessage string) string {
+	form := hb.Form().
+		Method(http.MethodPost).
+		Action(links.Auth().PasswordForgot()).
+		Child(inputCsrf(controller.app)).
+		Child(hb.Paragraph().
+			Class("text-muted small").
+			Text("Enter the email of your account, and we will send you a link to reset your password.")).
+		Child(formGroup("Email", inputEmail(email))).
+		Child(buttonSubmit("Send reset link"))
+
+	footer := hb.Div().
+		Class("text-center mt-4 small").
+		Child(hb.Hyperlink().Href(links.Auth().Login("")).Text("Back to log in"))
+
+	return page(controller.app, r, "Forgot password", card("Forgot password", errorMessage, "", form, footer))
+}
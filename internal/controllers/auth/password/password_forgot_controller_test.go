@@ -0,0 +1,61 @@
+package password
+
+import (
+	"net/http"
+	"project/internal/testutils"
+	"strings"
+	"testing"
+
+	"github.com/dracory/test"
+)
+
+func TestPasswordForgotController_ShowsForm(t *testing.T) {
+	app := setupPasswordApp(t)
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewPasswordForgotController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, `name="email"`) || !strings.Contains(body, "Send reset link") {
+		t.Fatal("expected the forgot password form")
+	}
+}
+
+func TestPasswordForgotController_SameResponseForAnyEmail(t *testing.T) {
+	app := setupPasswordApp(t)
+	seedPasswordUser(t, app, "user@test.com", "correct-horse", true)
+
+	for _, email := range []string{"user@test.com", "unknown@test.com"} {
+		_, response, err := test.CallStringEndpoint(http.MethodPost, NewPasswordForgotController(app).Handler, test.NewRequestOptions{
+			FormValues: formValues(map[string]string{"email": email}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if flashMessage == nil || flashMessage.Type != "success" || flashMessage.Message != msgPasswordResetSent {
+			t.Fatalf("expected the reset sent message for %s, got %v", email, flashMessage)
+		}
+	}
+}
+
+func TestPasswordForgotController_InvalidEmail(t *testing.T) {
+	app := setupPasswordApp(t)
+
+	body, _, err := test.CallStringEndpoint(http.MethodPost, NewPasswordForgotController(app).Handler, test.NewRequestOptions{
+		FormValues: formValues(map[string]string{"email": "not-an-email"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "Please enter a valid email address") {
+		t.Fatal("expected the invalid email error")
+	}
+}
@@ -0,0 +1,205 @@
+// Package password implements the first-party email/password
+// authentication (login, signup, forgot and reset password, email
+// verification), an alternative to AuthKnight enabled by the
+// AUTH_PASSWORD_AUTH_ENABLED setting.
+//
+// The users are looked up by email the same way as with AuthKnight (see
+// ext.UserFindByEmail), and the login is completed by the authentication
+// controller, so both share the sessions, cookies and redirects.
+package password
+
+import (
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/emails"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"strings"
+
+	"github.com/dracory/bs"
+	"github.com/dracory/cdn"
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/str"
+	"github.com/dracory/userstore"
+	"github.com/dromara/carbon/v2"
+)
+
+// Purposes of the tokens sent by email
+const (
+	TOKEN_PURPOSE_PASSWORD_RESET = "password_reset"
+	TOKEN_PURPOSE_EMAIL_VERIFY   = "email_verify"
+)
+
+// Lifetimes of the tokens sent by email, in seconds
+const (
+	passwordResetTokenExpiresSeconds = 60 * 60
+	emailVerifyTokenExpiresSeconds   = 48 * 60 * 60
+)
+
+// == USERS ===================================================================
+
+// dummyPasswordHash is compared with the password when there is no hash to
+// compare it with, at the same cost as the hashes of the users, so unknown
+// emails take as long to check as wrong passwords
+const dummyPasswordHash = "$2a$10$mt2L4Jqu7WIWjLBjBrW1A.2JMl3FM38KSXZw.CzCEDrlCV1taPw0y"
+
+// passwordMatches returns whether the password matches the bcrypt hash
+// stored for the user. Users without a password (e.g. created on an
+// AuthKnight login) never match.
+//
+// The password is always compared with a hash, so the time taken does not
+// tell whether the email has an account
+func passwordMatches(user userstore.UserInterface, password string) bool {
+	if user == nil || user.GetPassword() == "" {
+		str.BcryptHashCompare(password, dummyPasswordHash)
+		return false
+	}
+
+	matches := str.BcryptHashCompare(password, user.GetPassword())
+
+	return matches && password != ""
+}
+
+// isEmailVerified returns whether the user verified their email address
+func isEmailVerified(user userstore.UserInterface) bool {
+	return user.GetMeta(config.USER_META_EMAIL_VERIFIED_AT) != ""
+}
+
+// emailVerifiedMark marks the email of the user verified, the user must
+// be saved by the caller
+func emailVerifiedMark(user userstore.UserInterface) error {
+	if isEmailVerified(user) {
+		return nil
+	}
+
+	return user.SetMeta(config.USER_META_EMAIL_VERIFIED_AT, carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+}
+
+// emailNormalize trims and lowercases the email address, so it matches the
+// blind index regardless of how it was typed
+func emailNormalize(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func isEmailValid(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
+
+func isCsrfValid(app app.AppInterface, token string) bool {
+	return csrf.TokenValidate(token, app.GetConfig().GetCsrfSecret())
+}
+
+// == EMAILS ==================================================================
+
+// emailVerifySend sends a link to verify the email address of the user.
+// Failures are only logged, as the user gets a new link on the next login
+func emailVerifySend(app app.AppInterface, userID string, email string) {
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), TOKEN_PURPOSE_EMAIL_VERIFY, userID, emailVerifyTokenExpiresSeconds)
+
+	if err != nil {
+		app.GetLogger().Error("At password > emailVerifySend > AuthTokenCreate", slog.String("error", err.Error()))
+		return
+	}
+
+	if err := emails.NewEmailVerifyEmail(app).Send(email, links.Auth().EmailVerify(token)); err != nil {
+		app.GetLogger().Error("At password > emailVerifySend > Send", slog.String("error", err.Error()))
+	}
+}
+
+// passwordResetSend sends a link to reset the password of the user.
+// Failures are only logged, as the response must not tell whether the
+// email has an account
+func passwordResetSend(app app.AppInterface, userID string, email string) {
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), TOKEN_PURPOSE_PASSWORD_RESET, userID, passwordResetTokenExpiresSeconds)
+
+	if err != nil {
+		app.GetLogger().Error("At password > passwordResetSend > AuthTokenCreate", slog.String("error", err.Error()))
+		return
+	}
+
+	if err := emails.NewPasswordResetEmail(app).Send(email, links.Auth().PasswordReset(token)); err != nil {
+		app.GetLogger().Error("At password > passwordResetSend > Send", slog.String("error", err.Error()))
+	}
+}
+
+// == PAGES ===================================================================
+
+// page renders the card in the middle of a blank page, like the other
+// authentication pages
+func page(app app.AppInterface, r *http.Request, title string, card hb.TagInterface) string {
+	content := hb.Div().
+		Class("container container-xs py-5").
+		Child(hb.Div().
+			Class("text-center mb-4").
+			Child(hb.Raw(layouts.LogoHTML()))).
+		Child(card)
+
+	return layouts.NewBlankLayout(app, r, layouts.Options{
+		Title:      title,
+		Content:    content,
+		ScriptURLs: []string{cdn.BootstrapJs_5_3_3()},
+		StyleURLs:  []string{cdn.BootstrapIconsCss_1_11_3()},
+		Styles: []string{`@media (min-width: 576px) {.container.container-xs {max-width: 480px;}}
+		body{background:rgba(128,0,128,0.05);}
+		.CardAuth{border-radius:24px;box-shadow:0 20px 60px rgba(33,37,41,0.08);overflow:hidden;}
+		.CardAuth .card-body{padding:32px;}
+		.CardAuth .form-group{margin-bottom:18px;}
+		.CardAuth .form-control{border-radius:14px;padding:12px 15px;}`},
+	}).ToHTML()
+}
+
+// card is the form card of the pages, with the error or info message if any
+func card(title string, errorMessage string, infoMessage string, body ...hb.TagInterface) hb.TagInterface {
+	return hb.Div().
+		Class("card CardAuth").
+		Child(hb.Div().
+			Class("card-body").
+			Child(hb.Heading1().Class("h4 mb-4 text-center").Text(title)).
+			ChildIf(errorMessage != "", bs.Alert().Class("alert-danger").Text(errorMessage)).
+			ChildIf(infoMessage != "", bs.Alert().Class("alert-info").Text(infoMessage)).
+			Children(body))
+}
+
+func formGroup(label string, input hb.TagInterface) hb.TagInterface {
+	return hb.Div().
+		Class("form-group").
+		Child(bs.FormLabel(label)).
+		Child(input)
+}
+
+func inputEmail(value string) hb.TagInterface {
+	return bs.FormInput().
+		Type(hb.TYPE_EMAIL).
+		Name("email").
+		Value(value).
+		Attr("autocomplete", "email").
+		Attr("required", "required")
+}
+
+func inputPassword(name string, autocomplete string) hb.TagInterface {
+	return bs.FormInput().
+		Type(hb.TYPE_PASSWORD).
+		Name(name).
+		Attr("autocomplete", autocomplete).
+		Attr("required", "required")
+}
+
+func inputCsrf(app app.AppInterface) hb.TagInterface {
+	return hb.Input().
+		Type(hb.TYPE_HIDDEN).
+		Name("csrf_token").
+		Value(csrf.TokenGenerate(app.GetConfig().GetCsrfSecret()))
+}
+
+func buttonSubmit(text string) hb.TagInterface {
+	return bs.Button().
+		Type(hb.TYPE_SUBMIT).
+		Class("btn-primary w-100 py-2").
+		Text(text)
+}
@@ -0,0 +1,134 @@
+package password
+
+import (
+	"context"
+	"net/url"
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/testutils"
+	"strings"
+	"testing"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/str"
+	"github.com/dracory/userstore"
+)
+
+const testCsrfSecret = "test-csrf-secret"
+
+func setupPasswordApp(t *testing.T) app.AppInterface {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCsrfSecret(testCsrfSecret)
+	cfg.SetPasswordAuthEnabled(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetCacheStoreUsed(true)
+
+	return testutils.Setup(testutils.WithCfg(cfg))
+}
+
+// seedPasswordUser creates an active user with the email and password
+func seedPasswordUser(t *testing.T, app app.AppInterface, email string, password string, verified bool) userstore.UserInterface {
+	t.Helper()
+
+	user, err := ext.UserCreateWithEmail(context.Background(), app, email, userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := user.SetPasswordAndHash(password); err != nil {
+		t.Fatal(err)
+	}
+
+	if verified {
+		if err := emailVerifiedMark(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := app.GetUserStore().UserUpdate(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	return user
+}
+
+// formValues returns the values with a valid CSRF token
+func formValues(values map[string]string) url.Values {
+	form := url.Values{"csrf_token": {csrf.TokenGenerate(testCsrfSecret)}}
+	for key, value := range values {
+		form.Set(key, value)
+	}
+	return form
+}
+
+func TestPasswordMatches(t *testing.T) {
+	app := setupPasswordApp(t)
+	user := seedPasswordUser(t, app, "user@test.com", "correct-horse", true)
+
+	if !passwordMatches(user, "correct-horse") {
+		t.Error("expected the password to match")
+	}
+
+	if passwordMatches(user, "wrong-horse") {
+		t.Error("expected a wrong password not to match")
+	}
+
+	if passwordMatches(userstore.NewUser(), "") {
+		t.Error("expected users without a password never to match")
+	}
+
+	if passwordMatches(nil, "correct-horse") {
+		t.Error("expected a nil user not to match")
+	}
+}
+
+func TestPasswordMatches_DummyHash(t *testing.T) {
+	// The dummy hash must be a valid hash, at the default cost, so it
+	// takes as long to compare as the hashes of the users
+	if !strings.HasPrefix(dummyPasswordHash, "$2a$10$") {
+		t.Errorf("expected a bcrypt hash at the default cost, got %q", dummyPasswordHash)
+	}
+
+	if !str.BcryptHashCompare("dummy-password-for-constant-time-login", dummyPasswordHash) {
+		t.Error("expected the dummy hash to be a valid bcrypt hash")
+	}
+
+	if passwordMatches(nil, "") {
+		t.Error("expected a nil user never to match")
+	}
+}
+
+func TestEmailVerifiedMark(t *testing.T) {
+	user := userstore.NewUser()
+
+	if isEmailVerified(user) {
+		t.Fatal("expected a new user not to be verified")
+	}
+
+	if err := emailVerifiedMark(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if !isEmailVerified(user) {
+		t.Fatal("expected the user to be verified")
+	}
+}
+
+func TestEmailNormalizeAndValidate(t *testing.T) {
+	if emailNormalize("  User@Example.COM ") != "user@example.com" {
+		t.Error("expected the email to be trimmed and lowercased")
+	}
+
+	if !isEmailValid("user@example.com") {
+		t.Error("expected user@example.com to be valid")
+	}
+
+	for _, email := range []string{"", "user", "user@", "User <user@example.com>"} {
+		if isEmailValid(email) {
+			t.Errorf("expected %q to be invalid", email)
+		}
+	}
+}
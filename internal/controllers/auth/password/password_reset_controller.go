@@ -0,0 +1,112 @@
+package password
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/helpers"
+	"project/internal/links"
+	authrules "project/internal/rules/auth"
+
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+)
+
+const msgResetLinkInvalid = "The password reset link is invalid or has expired. Please request a new one."
+
+// == CONTROLLER ==============================================================
+
+// passwordResetController sets a new password, for the user the reset
+// link was sent to. The link proves the user owns the email, so it also
+// verifies the email address
+type passwordResetController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewPasswordResetController creates a new reset password controller
+func NewPasswordResetController(app app.AppInterface) *passwordResetController {
+	return &passwordResetController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *passwordResetController) Handler(w http.ResponseWriter, r *http.Request) string {
+	homeURL := links.Website().Home()
+
+	if controller.app.GetUserStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `user store is required`, homeURL, 5)
+	}
+
+	token := req.GetStringTrimmed(r, "token")
+
+	userID, err := helpers.AuthTokenFind(controller.app.GetCacheStore(), TOKEN_PURPOSE_PASSWORD_RESET, token)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At password.passwordResetController > Handler > AuthTokenFind", slog.String("error", err.Error()))
+	}
+
+	if userID == "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgResetLinkInvalid, links.Auth().PasswordForgot(), 10)
+	}
+
+	if r.Method != http.MethodPost {
+		return controller.page(r, token, "")
+	}
+
+	if !isCsrfValid(controller.app, req.GetStringTrimmed(r, "csrf_token")) {
+		return controller.page(r, token, "Your session has expired. Please try again.")
+	}
+
+	passwordRule := authrules.NewPasswordRule(req.GetString(r, "password"), req.GetString(r, "password_confirm"))
+	if passwordRule.Fails() {
+		return controller.page(r, token, passwordRule.FailMessageFirst())
+	}
+
+	user, err := controller.app.GetUserStore().UserFindByID(r.Context(), userID)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At password.passwordResetController > Handler > UserFindByID", slog.String("error", err.Error()))
+		return controller.page(r, token, "Sorry, the password could not be changed. Please try again later.")
+	}
+
+	if user == nil || !user.IsActive() {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgResetLinkInvalid, links.Auth().PasswordForgot(), 10)
+	}
+
+	if err := user.SetPasswordAndHash(req.GetString(r, "password")); err != nil {
+		controller.app.GetLogger().Error("At password.passwordResetController > Handler > SetPasswordAndHash", slog.String("error", err.Error()))
+		return controller.page(r, token, "Sorry, the password could not be changed. Please try again later.")
+	}
+
+	if err := emailVerifiedMark(user); err != nil {
+		controller.app.GetLogger().Error("At password.passwordResetController > Handler > emailVerifiedMark", slog.String("error", err.Error()))
+		return controller.page(r, token, "Sorry, the password could not be changed. Please try again later.")
+	}
+
+	if err := controller.app.GetUserStore().UserUpdate(r.Context(), user); err != nil {
+		controller.app.GetLogger().Error("At password.passwordResetController > Handler > UserUpdate", slog.String("error", err.Error()))
+		return controller.page(r, token, "Sorry, the password could not be changed. Please try again later.")
+	}
+
+	if err := helpers.AuthTokenDelete(controller.app.GetCacheStore(), TOKEN_PURPOSE_PASSWORD_RESET, token); err != nil {
+		controller.app.GetLogger().Error("At password.passwordResetController > Handler > AuthTokenDelete", slog.String("error", err.Error()))
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "Your password has been changed. Please log in with your new password.", links.Auth().Login(""), 10)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *passwordResetController) page(r *http.Request, token string, errorMessage string) string {
+	form := hb.Form().
+		Method(http.MethodPost).
+		Action(links.Auth().PasswordReset(token)).
+		Child(inputCsrf(controller.app)).
+		Child(formGroup("New password", inputPassword("password", "new-password"))).
+		Child(formGroup("Confirm new password", inputPassword("password_confirm", "new-password"))).
+		Child(buttonSubmit("Change password"))
+
+	return page(controller.app, r, "Reset password", card("Reset password", errorMessage, "", form))
+}
@@ -0,0 +1,133 @@
+package password
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"project/internal/helpers"
+	"project/internal/testutils"
+	"strings"
+	"testing"
+
+	"github.com/dracory/test"
+)
+
+func TestPasswordResetController_InvalidToken(t *testing.T) {
+	app := setupPasswordApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewPasswordResetController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"token": {"unknown"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" || flashMessage.Message != msgResetLinkInvalid {
+		t.Fatalf("expected the invalid link error, got %v", flashMessage)
+	}
+}
+
+func TestPasswordResetController_ShowsForm(t *testing.T) {
+	app := setupPasswordApp(t)
+	user := seedPasswordUser(t, app, "user@test.com", "correct-horse", true)
+
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), TOKEN_PURPOSE_PASSWORD_RESET, user.GetID(), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewPasswordResetController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"token": {token}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, `name="password_confirm"`) || !strings.Contains(body, "Change password") {
+		t.Fatal("expected the reset password form")
+	}
+}
+
+func TestPasswordResetController_ChangesPassword(t *testing.T) {
+	app := setupPasswordApp(t)
+	user := seedPasswordUser(t, app, "user@test.com", "correct-horse", false)
+
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), TOKEN_PURPOSE_PASSWORD_RESET, user.GetID(), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := formValues(map[string]string{"token": token, "password": "battery-staple", "password_confirm": "battery-staple"})
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewPasswordResetController(app).Handler, test.NewRequestOptions{
+		FormValues: values,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected the password to be changed, got %v", flashMessage)
+	}
+
+	user, err = app.GetUserStore().UserFindByID(context.Background(), user.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !passwordMatches(user, "battery-staple") {
+		t.Error("expected the new password to be set")
+	}
+
+	if !isEmailVerified(user) {
+		t.Error("expected the reset link to verify the email")
+	}
+
+	// The token can only be used once
+	_, response, err = test.CallStringEndpoint(http.MethodPost, NewPasswordResetController(app).Handler, test.NewRequestOptions{
+		FormValues: values,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err = testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Message != msgResetLinkInvalid {
+		t.Fatalf("expected the used link to be invalid, got %v", flashMessage)
+	}
+}
+
+func TestPasswordResetController_ValidatesPassword(t *testing.T) {
+	app := setupPasswordApp(t)
+	user := seedPasswordUser(t, app, "user@test.com", "correct-horse", true)
+
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), TOKEN_PURPOSE_PASSWORD_RESET, user.GetID(), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _, err := test.CallStringEndpoint(http.MethodPost, NewPasswordResetController(app).Handler, test.NewRequestOptions{
+		FormValues: formValues(map[string]string{"token": token, "password": "short", "password_confirm": "short"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "Password must be at least 8 characters") {
+		t.Fatal("expected the password validation error")
+	}
+}
@@ -0,0 +1,137 @@
+package password
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/links"
+	authrules "project/internal/rules/auth"
+
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dracory/userstore"
+)
+
+const msgSignupEmailSent = "Thank you for signing up. We have sent you an email to verify your email address."
+
+// == CONTROLLER ==============================================================
+
+// signupController registers new users with their email and a password.
+// The users must verify their email address before they can log in
+type signupController struct {
+	app app.AppInterface
+}
+
+type signupControllerData struct {
+	email           string
+	password        string
+	passwordConfirm string
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewSignupController creates a new password signup controller
+func NewSignupController(app app.AppInterface) *signupController {
+	return &signupController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *signupController) Handler(w http.ResponseWriter, r *http.Request) string {
+	homeURL := links.Website().Home()
+
+	canRegister := authrules.NewCanRegisterRule(controller.app, "")
+	if canRegister.Fails() {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, canRegister.FailMessageFirst(), homeURL, 10)
+	}
+
+	if controller.app.GetUserStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `user store is required`, homeURL, 5)
+	}
+
+	data := signupControllerData{
+		email:           emailNormalize(req.GetStringTrimmed(r, "email")),
+		password:        req.GetString(r, "password"),
+		passwordConfirm: req.GetString(r, "password_confirm"),
+	}
+
+	if r.Method != http.MethodPost {
+		return controller.page(r, data, "")
+	}
+
+	if !isCsrfValid(controller.app, req.GetStringTrimmed(r, "csrf_token")) {
+		return controller.page(r, data, "Your session has expired. Please try again.")
+	}
+
+	if !isEmailValid(data.email) {
+		return controller.page(r, data, "Please enter a valid email address")
+	}
+
+	canRegisterEmail := authrules.NewCanRegisterRule(controller.app, data.email)
+	if canRegisterEmail.Fails() {
+		return controller.page(r, data, canRegisterEmail.FailMessageFirst())
+	}
+
+	passwordRule := authrules.NewPasswordRule(data.password, data.passwordConfirm)
+	if passwordRule.Fails() {
+		return controller.page(r, data, passwordRule.FailMessageFirst())
+	}
+
+	existingUser, err := ext.UserFindByEmail(r.Context(), controller.app, data.email)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At password.signupController > Handler > UserFindByEmail", slog.String("error", err.Error()))
+		return controller.page(r, data, "Sorry, the signup failed. Please try again later.")
+	}
+
+	// The email already has an account. Its owner gets a link to set a
+	// new password instead, and the response is the same as for a new
+	// account, so the form cannot be used to find out who has an account
+	if existingUser != nil {
+		passwordResetSend(controller.app, existingUser.GetID(), data.email)
+		return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, msgSignupEmailSent, links.Auth().Login(""), 10)
+	}
+
+	user, err := ext.UserCreateWithEmail(r.Context(), controller.app, data.email, userstore.USER_STATUS_ACTIVE)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At password.signupController > Handler > UserCreateWithEmail", slog.String("error", err.Error()))
+		return controller.page(r, data, "Sorry, the signup failed. Please try again later.")
+	}
+
+	if err := user.SetPasswordAndHash(data.password); err != nil {
+		controller.app.GetLogger().Error("At password.signupController > Handler > SetPasswordAndHash", slog.String("error", err.Error()))
+		return controller.page(r, data, "Sorry, the signup failed. Please try again later.")
+	}
+
+	if err := controller.app.GetUserStore().UserUpdate(r.Context(), user); err != nil {
+		controller.app.GetLogger().Error("At password.signupController > Handler > UserUpdate", slog.String("error", err.Error()))
+		return controller.page(r, data, "Sorry, the signup failed. Please try again later.")
+	}
+
+	emailVerifySend(controller.app, user.GetID(), data.email)
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, msgSignupEmailSent, links.Auth().Login(""), 10)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *signupController) page(r *http.Request, data signupControllerData, errorMessage string) string {
+	form := hb.Form().
+		Method(http.MethodPost).
+		Action(links.Auth().Signup()).
+		Child(inputCsrf(controller.app)).
+		Child(formGroup("Email", inputEmail(data.email))).
+		Child(formGroup("Password", inputPassword("password", "new-password"))).
+		Child(formGroup("Confirm password", inputPassword("password_confirm", "new-password"))).
+		Child(buttonSubmit("Create account"))
+
+	footer := hb.Div().
+		Class("text-center mt-4 small").
+		Text("Already have an account? ").
+		Child(hb.Hyperlink().Href(links.Auth().Login("")).Text("Log in"))
+
+	return page(controller.app, r, "Create an account", card("Create an account", errorMessage, "", form, footer))
+}
@@ -0,0 +1,125 @@
+package password
+
+import (
+	"context"
+	"net/http"
+	"project/internal/ext"
+	"project/internal/testutils"
+	"strings"
+	"testing"
+
+	"github.com/dracory/test"
+)
+
+func TestSignupController_RegistrationDisabled(t *testing.T) {
+	app := setupPasswordApp(t)
+	app.GetConfig().SetRegistrationEnabled(false)
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewSignupController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" || flashMessage.Message != "Registrations are currently disabled" {
+		t.Fatalf("expected the registrations disabled error, got %v", flashMessage)
+	}
+}
+
+func TestSignupController_ValidatesForm(t *testing.T) {
+	app := setupPasswordApp(t)
+
+	cases := []struct {
+		values   map[string]string
+		expected string
+	}{
+		{map[string]string{"email": "not-an-email", "password": "correct-horse", "password_confirm": "correct-horse"}, "Please enter a valid email address"},
+		{map[string]string{"email": "user@test.com", "password": "short", "password_confirm": "short"}, "Password must be at least 8 characters"},
+		{map[string]string{"email": "user@test.com", "password": "correct-horse", "password_confirm": "other-horse"}, "Passwords do not match"},
+	}
+
+	for _, c := range cases {
+		body, _, err := test.CallStringEndpoint(http.MethodPost, NewSignupController(app).Handler, test.NewRequestOptions{
+			FormValues: formValues(c.values),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(body, c.expected) {
+			t.Errorf("expected %q for %v", c.expected, c.values)
+		}
+	}
+}
+
+func TestSignupController_CreatesUnverifiedUser(t *testing.T) {
+	app := setupPasswordApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewSignupController(app).Handler, test.NewRequestOptions{
+		FormValues: formValues(map[string]string{"email": "New@Test.com", "password": "correct-horse", "password_confirm": "correct-horse"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" || flashMessage.Message != msgSignupEmailSent {
+		t.Fatalf("expected the signup message, got %v", flashMessage)
+	}
+
+	user, err := ext.UserFindByEmail(context.Background(), app, "new@test.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user == nil {
+		t.Fatal("expected the user to be created")
+	}
+
+	if !passwordMatches(user, "correct-horse") {
+		t.Error("expected the password to be set")
+	}
+
+	if isEmailVerified(user) {
+		t.Error("expected the email not to be verified yet")
+	}
+}
+
+func TestSignupController_ExistingEmail(t *testing.T) {
+	app := setupPasswordApp(t)
+	existing := seedPasswordUser(t, app, "user@test.com", "correct-horse", true)
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewSignupController(app).Handler, test.NewRequestOptions{
+		FormValues: formValues(map[string]string{"email": "user@test.com", "password": "other-horse", "password_confirm": "other-horse"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same response as for a new account
+	if flashMessage == nil || flashMessage.Message != msgSignupEmailSent {
+		t.Fatalf("expected the signup message, got %v", flashMessage)
+	}
+
+	user, err := app.GetUserStore().UserFindByID(context.Background(), existing.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !passwordMatches(user, "correct-horse") {
+		t.Error("expected the password of the existing user not to change")
+	}
+}
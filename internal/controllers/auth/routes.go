@@ -5,11 +5,16 @@ import (
 	"project/internal/controllers/auth/authentication"
 	"project/internal/controllers/auth/login"
 	"project/internal/controllers/auth/logout"
+	"project/internal/controllers/auth/magiclink"
+	"project/internal/controllers/auth/oauthlogin"
+	"project/internal/controllers/auth/password"
 	"project/internal/controllers/auth/register"
+	"project/internal/controllers/auth/twofactor"
 	"project/internal/links"
+	"project/internal/middlewares"
+	"project/internal/ratelimit"
 
 	"github.com/dracory/rtr"
-	rtrMiddleware "github.com/dracory/rtr/middlewares"
 )
 
 func Routes(application app.AppInterface) []rtr.RouteInterface {
@@ -28,6 +33,12 @@ func Routes(application app.AppInterface) []rtr.RouteInterface {
 		SetPath(links.AUTH_LOGOUT).
 		SetHTMLHandler(logout.NewLogoutController(application).AnyIndex)
 
+	// Any login can lead to the two-factor step, so it is always added
+	twoFactorRoute := rtr.NewRoute().
+		SetName("Auth > Two Factor Controller").
+		SetPath(links.AUTH_TWO_FACTOR).
+		SetHTMLHandler(twofactor.NewTwoFactorController(application).Handler)
+
 	registerRoute := rtr.NewRoute().
 		SetName("Auth > Register Controller").
 		SetPath(links.AUTH_REGISTER).
@@ -36,14 +47,29 @@ func Routes(application app.AppInterface) []rtr.RouteInterface {
 	authRoutes := []rtr.RouteInterface{
 		authRoute,
 		loginRoute,
+		twoFactorRoute,
+	}
+
+	if application.GetConfig().GetPasswordAuthEnabled() {
+		authRoutes = append(authRoutes, passwordRoutes(application)...)
+	}
+
+	if application.GetConfig().GetMagicLinkEnabled() {
+		authRoutes = append(authRoutes, magicLinkRoutes(application)...)
+	}
+
+	if oauthlogin.Enabled(application) {
+		authRoutes = append(authRoutes, oauthRoutes(application)...)
 	}
 
 	// Apply stricter rate limiting to sensitive authentication routes only
 	for i := range authRoutes {
 		authRoutes[i].AddBeforeMiddlewares([]rtr.MiddlewareInterface{
 			// Stricter rate limiting for authentication endpoints
-			// 5 requests per minute to prevent brute force attacks
-			rtrMiddleware.RateLimitByIPMiddleware(5, 60),
+			// to prevent brute force attacks (RATE_LIMIT_AUTH), counted
+			// per route, so moving through the login steps (e.g. the
+			// login, then the two-factor step) does not use up the limit
+			middlewares.NewRateLimitByRouteMiddleware(application, ratelimit.GROUP_AUTH, authRoutes[i].GetPath()),
 		})
 	}
 
@@ -53,9 +79,9 @@ func Routes(application app.AppInterface) []rtr.RouteInterface {
 	if application.GetConfig().GetRegistrationEnabled() {
 		// Apply moderate rate limiting for registration
 		registerRoute.AddBeforeMiddlewares([]rtr.MiddlewareInterface{
-			// Moderate rate limiting for registration endpoint
-			// 10 requests per minute to allow for legitimate interactions (country/timezone selection)
-			rtrMiddleware.RateLimitByIPMiddleware(10, 60),
+			// Moderate rate limiting for registration endpoint, to allow for
+			// legitimate interactions (country/timezone selection) (RATE_LIMIT_REGISTER)
+			middlewares.NewRateLimitMiddleware(application, ratelimit.GROUP_REGISTER),
 		})
 
 		routes = append(routes, registerRoute)
@@ -63,3 +89,62 @@ func Routes(application app.AppInterface) []rtr.RouteInterface {
 
 	return routes
 }
+
+// passwordRoutes are the routes of the email/password authentication,
+// added when password auth is enabled. The login itself is served by the
+// login route
+func passwordRoutes(application app.AppInterface) []rtr.RouteInterface {
+	routes := []rtr.RouteInterface{
+		rtr.NewRoute().
+			SetName("Auth > Password Forgot Controller").
+			SetPath(links.AUTH_PASSWORD_FORGOT).
+			SetHTMLHandler(password.NewPasswordForgotController(application).Handler),
+		rtr.NewRoute().
+			SetName("Auth > Password Reset Controller").
+			SetPath(links.AUTH_PASSWORD_RESET).
+			SetHTMLHandler(password.NewPasswordResetController(application).Handler),
+		rtr.NewRoute().
+			SetName("Auth > Email Verify Controller").
+			SetPath(links.AUTH_EMAIL_VERIFY).
+			SetHTMLHandler(password.NewEmailVerifyController(application).Handler),
+	}
+
+	if application.GetConfig().GetRegistrationEnabled() {
+		routes = append(routes, rtr.NewRoute().
+			SetName("Auth > Signup Controller").
+			SetPath(links.AUTH_SIGNUP).
+			SetHTMLHandler(password.NewSignupController(application).Handler))
+	}
+
+	return routes
+}
+
+// magicLinkRoutes are the routes of the magic link authentication, added
+// when magic links are enabled
+func magicLinkRoutes(application app.AppInterface) []rtr.RouteInterface {
+	return []rtr.RouteInterface{
+		rtr.NewRoute().
+			SetName("Auth > Magic Link Controller").
+			SetPath(links.AUTH_MAGIC_LINK).
+			SetHTMLHandler(magiclink.NewMagicLinkController(application).Handler),
+		rtr.NewRoute().
+			SetName("Auth > Magic Link Login Controller").
+			SetPath(links.AUTH_MAGIC_LINK_LOGIN).
+			SetHTMLHandler(magiclink.NewMagicLinkLoginController(application).Handler),
+	}
+}
+
+// oauthRoutes are the routes of the social login, added when a provider
+// is configured
+func oauthRoutes(application app.AppInterface) []rtr.RouteInterface {
+	return []rtr.RouteInterface{
+		rtr.NewRoute().
+			SetName("Auth > OAuth Login Controller").
+			SetPath(links.AUTH_OAUTH).
+			SetHTMLHandler(oauthlogin.NewOAuthLoginController(application).Handler),
+		rtr.NewRoute().
+			SetName("Auth > OAuth Callback Controller").
+			SetPath(links.AUTH_OAUTH_CALLBACK).
+			SetHTMLHandler(oauthlogin.NewOAuthCallbackController(application).Handler),
+	}
+}
@@ -138,3 +138,104 @@ func TestRoutesWithRegistrationDisabled(t *testing.T) {
 		t.Error("Logout route not found when registration is disabled")
 	}
 }
+
+// TestRoutesWithPasswordAuthDisabled verifies the password routes are
+// excluded by default
+func TestRoutesWithPasswordAuthDisabled(t *testing.T) {
+	t.Parallel()
+	app := testutils.Setup()
+
+	for _, route := range Routes(app) {
+		switch route.GetPath() {
+		case links.AUTH_SIGNUP, links.AUTH_PASSWORD_FORGOT, links.AUTH_PASSWORD_RESET, links.AUTH_EMAIL_VERIFY:
+			t.Errorf("Route %s should not be present when password auth is disabled", route.GetPath())
+		}
+	}
+}
+
+// TestRoutesWithPasswordAuthEnabled verifies the password routes are
+// included when enabled, and signup only when registration is enabled
+func TestRoutesWithPasswordAuthEnabled(t *testing.T) {
+	t.Parallel()
+
+	for _, registrationEnabled := range []bool{true, false} {
+		cfg := testutils.DefaultConf()
+		cfg.SetPasswordAuthEnabled(true)
+		cfg.SetRegistrationEnabled(registrationEnabled)
+		app := testutils.Setup(testutils.WithCfg(cfg))
+
+		found := map[string]bool{}
+		for _, route := range Routes(app) {
+			found[route.GetPath()] = true
+		}
+
+		for _, path := range []string{links.AUTH_LOGIN, links.AUTH_PASSWORD_FORGOT, links.AUTH_PASSWORD_RESET, links.AUTH_EMAIL_VERIFY} {
+			if !found[path] {
+				t.Errorf("Route %s not found when password auth is enabled", path)
+			}
+		}
+
+		if found[links.AUTH_SIGNUP] != registrationEnabled {
+			t.Errorf("Signup route present: %v, expected: %v", found[links.AUTH_SIGNUP], registrationEnabled)
+		}
+	}
+}
+
+// TestRoutesWithMagicLinkEnabled verifies the magic link routes are
+// included only when enabled
+func TestRoutesWithMagicLinkEnabled(t *testing.T) {
+	t.Parallel()
+
+	for _, enabled := range []bool{true, false} {
+		cfg := testutils.DefaultConf()
+		cfg.SetMagicLinkEnabled(enabled)
+		app := testutils.Setup(testutils.WithCfg(cfg))
+
+		found := map[string]bool{}
+		for _, route := range Routes(app) {
+			found[route.GetPath()] = true
+		}
+
+		if found[links.AUTH_MAGIC_LINK] != enabled || found[links.AUTH_MAGIC_LINK_LOGIN] != enabled {
+			t.Errorf("Magic link routes present: %v, expected: %v", found[links.AUTH_MAGIC_LINK], enabled)
+		}
+	}
+}
+
+func TestRoutesIncludeTwoFactor(t *testing.T) {
+	t.Parallel()
+	app := testutils.Setup()
+
+	for _, route := range Routes(app) {
+		if route.GetPath() == links.AUTH_TWO_FACTOR {
+			return
+		}
+	}
+
+	t.Error("Two-factor route not found")
+}
+
+// TestRoutesWithOAuthProvider verifies the social login routes are
+// included only when a provider is configured
+func TestRoutesWithOAuthProvider(t *testing.T) {
+	t.Parallel()
+
+	for _, configured := range []bool{true, false} {
+		cfg := testutils.DefaultConf()
+		cfg.SetCustomStoreUsed(true)
+		if configured {
+			cfg.SetOAuthGitHubClientID("github-id")
+			cfg.SetOAuthGitHubClientSecret("github-secret")
+		}
+		app := testutils.Setup(testutils.WithCfg(cfg))
+
+		found := map[string]bool{}
+		for _, route := range Routes(app) {
+			found[route.GetPath()] = true
+		}
+
+		if found[links.AUTH_OAUTH] != configured || found[links.AUTH_OAUTH_CALLBACK] != configured {
+			t.Errorf("OAuth routes present: %v, expected: %v", found[links.AUTH_OAUTH], configured)
+		}
+	}
+}
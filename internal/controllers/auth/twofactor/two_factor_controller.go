@@ -0,0 +1,163 @@
+// Package twofactor implements the two-factor authentication step of the
+// login, for the users who enabled two-factor authentication.
+package twofactor
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/controllers/auth/authentication"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"strings"
+
+	"github.com/dracory/bs"
+	"github.com/dracory/cdn"
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+)
+
+const msgTwoFactorExpired = "Your login has expired. Please log in again."
+const msgTwoFactorInvalid = "The code is invalid. Please try again."
+
+// == CONTROLLER ==============================================================
+
+// twoFactorController asks the user for the code of the authenticator app
+// (or a recovery code), after the identity of the user was verified by the
+// login, and only then starts the session.
+//
+// The login passes the user with a short lived token, see
+// authentication.LoginComplete.
+type twoFactorController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewTwoFactorController creates a new two-factor authentication controller
+func NewTwoFactorController(app app.AppInterface) *twoFactorController {
+	return &twoFactorController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *twoFactorController) Handler(w http.ResponseWriter, r *http.Request) string {
+	homeURL := links.Website().Home()
+
+	if controller.app.GetUserStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `user store is required`, homeURL, 5)
+	}
+
+	if controller.app.GetSessionStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, `session store is required`, homeURL, 5)
+	}
+
+	token := req.GetStringTrimmed(r, "token")
+	backUrl := req.GetStringTrimmed(r, "back_url")
+
+	// Ensure back_url is part of our domain (contains our root URL)
+	if !strings.HasPrefix(backUrl, homeURL) {
+		backUrl = ""
+	}
+
+	userID, err := helpers.AuthTokenFind(controller.app.GetCacheStore(), authentication.TOKEN_PURPOSE_TWO_FACTOR, token)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At twoFactorController > Handler > AuthTokenFind", slog.String("error", err.Error()))
+	}
+
+	if userID == "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgTwoFactorExpired, links.Auth().Login(backUrl), 10)
+	}
+
+	if r.Method != http.MethodPost {
+		return controller.page(r, token, backUrl, "")
+	}
+
+	if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+		return controller.page(r, token, backUrl, "Your session has expired. Please try again.")
+	}
+
+	user, err := controller.app.GetUserStore().UserFindByID(r.Context(), userID)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At twoFactorController > Handler > UserFindByID", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgTwoFactorExpired, links.Auth().Login(backUrl), 10)
+	}
+
+	if user == nil || !user.IsActive() {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgTwoFactorExpired, links.Auth().Login(backUrl), 10)
+	}
+
+	if wait := ext.AuthLockoutWait(controller.app, user, req.GetIP(r)); wait > 0 {
+		return controller.page(r, token, backUrl, ext.AuthLockoutWaitMessage(wait))
+	}
+
+	valid, err := ext.UserTwoFactorVerify(r.Context(), controller.app, user, req.GetStringTrimmed(r, "code"))
+
+	if err != nil {
+		controller.app.GetLogger().Error("At twoFactorController > Handler > UserTwoFactorVerify", slog.String("error", err.Error()))
+	}
+
+	if !valid {
+		ext.AuthLockoutFail(r.Context(), controller.app, user, req.GetIP(r))
+		return controller.page(r, token, backUrl, msgTwoFactorInvalid)
+	}
+
+	if err := helpers.AuthTokenDelete(controller.app.GetCacheStore(), authentication.TOKEN_PURPOSE_TWO_FACTOR, token); err != nil {
+		controller.app.GetLogger().Error("At twoFactorController > Handler > AuthTokenDelete", slog.String("error", err.Error()))
+	}
+
+	return authentication.NewAuthenticationController(controller.app).SessionStart(w, r, user, backUrl)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *twoFactorController) page(r *http.Request, token string, backUrl string, errorMessage string) string {
+	form := hb.Form().
+		Method(http.MethodPost).
+		Action(links.Auth().TwoFactor(token, backUrl)).
+		Child(hb.Input().
+			Type(hb.TYPE_HIDDEN).
+			Name("csrf_token").
+			Value(csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret()))).
+		Child(hb.Paragraph().
+			Class("text-muted small").
+			Text("Enter the 6-digit code from your authenticator app. If you lost your device, enter one of your recovery codes.")).
+		Child(hb.Div().
+			Class("mb-3").
+			Child(bs.FormLabel("Code")).
+			Child(bs.FormInput().
+				Type(hb.TYPE_TEXT).
+				Name("code").
+				Attr("autocomplete", "one-time-code").
+				Attr("autofocus", "autofocus").
+				Attr("required", "required"))).
+		Child(bs.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn-primary w-100 py-2").
+			Text("Verify"))
+
+	card := hb.Div().
+		Class("card").
+		Style("border-radius:24px;box-shadow:0 20px 60px rgba(33,37,41,0.08);").
+		Child(hb.Div().
+			Class("card-body p-4").
+			Child(hb.Heading1().Class("h4 mb-4 text-center").Text("Two-factor authentication")).
+			ChildIf(errorMessage != "", bs.Alert().Class("alert-danger").Text(errorMessage)).
+			Child(form))
+
+	return layouts.NewBlankLayout(controller.app, r, layouts.Options{
+		Title: "Two-factor authentication",
+		Content: hb.Div().
+			Class("container py-5").
+			Style("max-width:480px;").
+			Child(hb.Div().Class("text-center mb-4").Child(hb.Raw(layouts.LogoHTML()))).
+			Child(card),
+		ScriptURLs: []string{cdn.BootstrapJs_5_3_3()},
+		Styles:     []string{`body{background:rgba(128,0,128,0.05);}`},
+	}).ToHTML()
+}
@@ -0,0 +1,238 @@
+package twofactor
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"project/internal/app"
+	"project/internal/controllers/auth/authentication"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/lockout"
+	"project/internal/testutils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/test"
+	"github.com/dracory/userstore"
+	"github.com/pquerna/otp/totp"
+)
+
+const testCsrfSecret = "test-csrf-secret"
+
+func setupTwoFactorApp(t *testing.T) app.AppInterface {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCsrfSecret(testCsrfSecret)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetVaultStoreUsed(true)
+	cfg.SetVaultStoreKey("test-key")
+
+	return testutils.Setup(testutils.WithCfg(cfg))
+}
+
+// seedTwoFactorUser creates an active user with two-factor authentication
+// enabled, and returns the user, the TOTP secret and the recovery codes
+func seedTwoFactorUser(t *testing.T, app app.AppInterface) (userstore.UserInterface, string, []string) {
+	t.Helper()
+
+	user, err := ext.UserCreateWithEmail(context.Background(), app, "user@test.com", userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "Test", AccountName: "user@test.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes, err := ext.UserTwoFactorEnable(context.Background(), app, user, key.Secret())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user, key.Secret(), codes
+}
+
+func csrfValues(values map[string]string) url.Values {
+	form := url.Values{"csrf_token": {csrf.TokenGenerate(testCsrfSecret)}}
+	for key, value := range values {
+		form.Set(key, value)
+	}
+	return form
+}
+
+func TestTwoFactorController_InvalidToken(t *testing.T) {
+	app := setupTwoFactorApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewTwoFactorController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"token": {"unknown"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" || flashMessage.Message != msgTwoFactorExpired {
+		t.Fatalf("expected the expired login error, got %v", flashMessage)
+	}
+}
+
+func TestTwoFactorController_ShowsForm(t *testing.T) {
+	app := setupTwoFactorApp(t)
+	user, _, _ := seedTwoFactorUser(t, app)
+
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), authentication.TOKEN_PURPOSE_TWO_FACTOR, user.GetID(), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewTwoFactorController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"token": {token}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, `name="code"`) {
+		t.Fatal("expected the code form")
+	}
+}
+
+func TestTwoFactorController_WrongCode(t *testing.T) {
+	app := setupTwoFactorApp(t)
+	user, _, _ := seedTwoFactorUser(t, app)
+
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), authentication.TOKEN_PURPOSE_TWO_FACTOR, user.GetID(), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _, err := test.CallStringEndpoint(http.MethodPost, NewTwoFactorController(app).Handler, test.NewRequestOptions{
+		FormValues: csrfValues(map[string]string{"token": token, "code": "123456"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, msgTwoFactorInvalid) {
+		t.Fatal("expected the invalid code error")
+	}
+}
+
+func TestTwoFactorController_WrongCodesAreDelayed(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetCsrfSecret(testCsrfSecret)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetVaultStoreUsed(true)
+	cfg.SetVaultStoreKey("test-key")
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+	user, secret, _ := seedTwoFactorUser(t, app)
+
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), authentication.TOKEN_PURPOSE_TWO_FACTOR, user.GetID(), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verify := func(code string) string {
+		body, _, err := test.CallStringEndpoint(http.MethodPost, NewTwoFactorController(app).Handler, test.NewRequestOptions{
+			FormValues: csrfValues(map[string]string{"token": token, "code": code}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+
+	for range lockout.PolicyFor(lockout.SUBJECT_ACCOUNT).DelayAfter {
+		if body := verify("123456"); !strings.Contains(body, msgTwoFactorInvalid) {
+			t.Fatal("expected the invalid code error")
+		}
+	}
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Even the right code must wait, so the codes cannot be guessed quickly
+	if body := verify(code); !strings.Contains(body, "Too many failed attempts") {
+		t.Fatal("expected the code to be delayed after the failures")
+	}
+}
+
+func TestTwoFactorController_LogsInWithCode(t *testing.T) {
+	app := setupTwoFactorApp(t)
+	user, secret, _ := seedTwoFactorUser(t, app)
+
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), authentication.TOKEN_PURPOSE_TWO_FACTOR, user.GetID(), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewTwoFactorController(app).Handler, test.NewRequestOptions{
+		FormValues: csrfValues(map[string]string{"token": token, "code": code}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" || flashMessage.Message != "Login was successful" {
+		t.Fatalf("expected a successful login, got %v", flashMessage)
+	}
+
+	userID, _ := helpers.AuthTokenFind(app.GetCacheStore(), authentication.TOKEN_PURPOSE_TWO_FACTOR, token)
+	if userID != "" {
+		t.Fatal("expected the token to be used up")
+	}
+}
+
+func TestTwoFactorController_LogsInWithRecoveryCode(t *testing.T) {
+	app := setupTwoFactorApp(t)
+	user, _, codes := seedTwoFactorUser(t, app)
+
+	token, err := helpers.AuthTokenCreate(app.GetCacheStore(), authentication.TOKEN_PURPOSE_TWO_FACTOR, user.GetID(), 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewTwoFactorController(app).Handler, test.NewRequestOptions{
+		FormValues: csrfValues(map[string]string{"token": token, "code": codes[0]}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected a successful login, got %v", flashMessage)
+	}
+}
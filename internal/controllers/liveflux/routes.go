@@ -3,8 +3,12 @@ package liveflux
 import (
 	"net/http"
 	"project/internal/app"
+	"project/internal/controllers/website/contact"
 	"project/internal/links"
+	"project/internal/middlewares"
+	"project/internal/ratelimit"
 
+	"github.com/dracory/liveflux"
 	"github.com/dracory/rtr"
 )
 
@@ -18,6 +22,10 @@ func Routes(app app.AppInterface) []rtr.RouteInterface {
 		SetMethod(http.MethodPost).
 		SetHTMLHandler(ctrl.Handler)
 
+	livefluxPost.AddBeforeMiddlewares([]rtr.MiddlewareInterface{
+		middlewares.NewRateLimitByRequestMiddleware(app, "Contact Form Rate Limit Middleware", rateLimitGroup),
+	})
+
 	livefluxGet := rtr.NewRoute().
 		SetName("Liveflux > Handler").
 		SetPath(links.LIVEFLUX).
@@ -26,3 +34,14 @@ func Routes(app app.AppInterface) []rtr.RouteInterface {
 
 	return []rtr.RouteInterface{livefluxPost, livefluxGet}
 }
+
+// rateLimitGroup returns the rate limit group of the Liveflux request. Only
+// the submissions of the contact form are limited (RATE_LIMIT_CONTACT), the
+// other components are covered by the global limits
+func rateLimitGroup(r *http.Request) string {
+	if r.FormValue(liveflux.FormComponentKind) == contact.FORM_CONTACT_KIND && r.FormValue(liveflux.FormAction) == "submit" {
+		return ratelimit.GROUP_CONTACT
+	}
+
+	return ""
+}
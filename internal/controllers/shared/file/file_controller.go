@@ -1,27 +1,58 @@
 package file
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"project/internal/config"
+	"project/internal/helpers"
+	"project/internal/signedurl"
 	"strings"
+	"time"
 
 	"github.com/dracory/filesystem"
 	"github.com/dracory/str"
 	"github.com/samber/lo"
-	"github.com/spf13/cast"
 )
 
 // == CONTROLLER ==============================================================
 
 type fileController struct {
 	storage filesystem.StorageInterface
+
+	// cacheControl is the Cache-Control header of the files, see
+	// SetCacheControl
+	cacheControl string
+
+	// privatePaths are the folders of the private files, only served with
+	// a URL signed with the signing key, see SetPrivateFiles
+	privatePaths []string
+	signingKey   string
 }
 
 // == CONSTRUCTOR =============================================================
 
+// NewFileController creates the controller of the stored files, served
+// by the /files, /file and /media routes
 func NewFileController(storage filesystem.StorageInterface) *fileController {
 	return &fileController{storage: storage}
 }
 
+// SetCacheControl sets the Cache-Control header of the files, instead of
+// config.MEDIA_CACHE_CONTROL_DEFAULT
+func (c *fileController) SetCacheControl(cacheControl string) *fileController {
+	c.cacheControl = cacheControl
+	return c
+}
+
+// SetPrivateFiles sets the folders of the private files (e.g. "/private"),
+// only served with a URL signed with the key (see links.FileSigned)
+func (c *fileController) SetPrivateFiles(privatePaths []string, signingKey string) *fileController {
+	c.privatePaths = privatePaths
+	c.signingKey = signingKey
+	return c
+}
+
 // == PUBLIC METHODS ==========================================================
 
 func (c *fileController) Handler(w http.ResponseWriter, r *http.Request) string {
@@ -34,6 +65,19 @@ func (c *fileController) Handler(w http.ResponseWriter, r *http.Request) string
 		ElseIfF(strings.HasPrefix(r.URL.Path, "/media"), func() string { return str.RightFrom(r.URL.Path, "/media") }).
 		Else(r.URL.Path)
 
+	// The path checked is the path read, so "/public/../private/a.pdf"
+	// is private too
+	filePath = signedurl.CleanPath(filePath)
+	private := signedurl.IsPrivate(filePath, c.privatePaths)
+
+	// Before looking the file up, to not tell which private files exist
+	if private {
+		if status, message := c.authorizePrivate(r, filePath); message != "" {
+			w.WriteHeader(status)
+			return message
+		}
+	}
+
 	exists, err := c.storage.Exists(filePath)
 
 	if err != nil {
@@ -44,12 +88,6 @@ func (c *fileController) Handler(w http.ResponseWriter, r *http.Request) string
 		return "File not found"
 	}
 
-	content, err := c.storage.ReadFile(filePath)
-
-	if err != nil {
-		return err.Error()
-	}
-
 	extension := c.findExtension(filePath)
 	mimeType := c.findMIMEType(extension)
 
@@ -57,20 +95,76 @@ func (c *fileController) Handler(w http.ResponseWriter, r *http.Request) string
 		return "File not found"
 	}
 
-	w.Header().Set("Content-Type", mimeType)
+	size, err := c.storage.Size(filePath)
 
-	if mimeType == "application/octet-stream" {
-		w.Header().Set("Content-Disposition", "attachment; filename="+r.URL.Path)
-		w.Header().Set("Content-Length", cast.ToString(len(content)))
+	if err != nil {
+		return err.Error()
+	}
+
+	// The ETag is derived from the size and the time, to revalidate the
+	// file without reading it. Without the time, the file is only served
+	etag := ""
+	modTime, err := c.storage.LastModified(filePath)
+	if err != nil {
+		modTime = time.Time{}
+	} else {
+		etag = helpers.FileETag(size, modTime)
 	}
 
-	if _, err := w.Write(content); err != nil { // #nosec G705 -- content is file data from validated path
-		return "Failed to write file content: " + err.Error()
+	content, err := c.open(filePath, size)
+
+	if err != nil {
+		return err.Error()
 	}
 
+	defer content.Close()
+
+	// Answers the Range, If-None-Match and If-Modified-Since headers
+	helpers.ServeReader(w, r, content, helpers.ServeContentOptions{
+		Name:         c.findFileName(filePath),
+		ContentType:  mimeType,
+		CacheControl: lo.Ternary(private, config.MEDIA_PRIVATE_CACHE_CONTROL, lo.CoalesceOrEmpty(c.cacheControl, config.MEDIA_CACHE_CONTROL_DEFAULT)),
+		ModTime:      modTime,
+		Attachment:   mimeType == "application/octet-stream",
+		ETag:         etag,
+	})
+
 	return ""
 }
 
+// open opens the file to stream it, when the storage supports it, or else
+// returns a reader which reads the file only when its bytes are needed
+func (c *fileController) open(filePath string, size int64) (io.ReadSeekCloser, error) {
+	if opener, ok := c.storage.(fileOpener); ok {
+		return opener.Open(filePath)
+	}
+
+	return &storageReader{storage: c.storage, filePath: filePath, size: size}, nil
+}
+
+// authorizePrivate checks the signature of the URL of the private file,
+// and returns the status and the message of the refusal, or an empty
+// message if the file can be served
+func (c *fileController) authorizePrivate(r *http.Request, filePath string) (status int, message string) {
+	authUserID := ""
+	if authUser := helpers.GetAuthUser(r); authUser != nil {
+		authUserID = authUser.GetID()
+	}
+
+	err := signedurl.Verify(c.signingKey, filePath, r.URL.Query(), authUserID, time.Now())
+
+	if err == nil {
+		return http.StatusOK, ""
+	}
+
+	// Without a signing key, the private files are not served at all
+	if errors.Is(err, signedurl.ErrKeyMissing) {
+		return http.StatusNotFound, "File not found"
+	}
+
+	return http.StatusForbidden, "Access denied: " + err.Error()
+}
+
 // findExtension finds the file extension from a path.
 //
 // Parameter(s):
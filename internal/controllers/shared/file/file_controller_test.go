@@ -3,8 +3,11 @@ package file
 import (
 	"net/http"
 	"net/http/httptest"
+	"project/internal/config"
+	"project/internal/signedurl"
 	"project/internal/testutils"
 	"testing"
+	"time"
 
 	"github.com/dracory/filesystem"
 )
@@ -467,3 +470,135 @@ func TestFindMIMETypeEdgeCases_PngUpperCase(t *testing.T) {
 		t.Errorf("findMIMEType(PNG) = %q, want application/octet-stream", got)
 	}
 }
+
+// TestHandlerRangeAndConditionalRequests tests the partial and the
+// conditional requests of a stored file
+func TestHandlerRangeAndConditionalRequests(t *testing.T) {
+	app := testutils.Setup(testutils.WithUserStore(true), testutils.WithSessionStore(true))
+	defer app.GetDatabase().Close()
+
+	storage, err := filesystem.NewStorage(filesystem.Disk{
+		DiskName:  filesystem.DRIVER_SQL,
+		Driver:    filesystem.DRIVER_SQL,
+		Url:       "/files",
+		DB:        app.GetDatabase(),
+		TableName: "snv_files_file",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	if err := storage.Put("/video.webm", []byte("0123456789")); err != nil {
+		t.Fatalf("Failed to put file: %v", err)
+	}
+
+	controller := NewFileController(storage).SetCacheControl("public, max-age=60")
+
+	// Full file
+	req := httptest.NewRequest(http.MethodGet, "/files/video.webm", nil)
+	rec := httptest.NewRecorder()
+	if res := controller.Handler(rec, req); res != "" {
+		t.Fatalf("Handler = %q, want empty", res)
+	}
+
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || rec.Body.String() != "0123456789" || etag == "" {
+		t.Fatalf("expected the full file with an ETag, got %d %q %q", rec.Code, rec.Body.String(), etag)
+	}
+
+	if got := rec.Header().Get("Cache-Control"); got != "public, max-age=60" {
+		t.Errorf("Cache-Control = %q, want 'public, max-age=60'", got)
+	}
+
+	// Part of the file
+	req = httptest.NewRequest(http.MethodGet, "/files/video.webm", nil)
+	req.Header.Set("Range", "bytes=5-")
+	rec = httptest.NewRecorder()
+	controller.Handler(rec, req)
+
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "56789" {
+		t.Fatalf("expected the bytes from 5, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	// Unchanged file
+	req = httptest.NewRequest(http.MethodGet, "/files/video.webm", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	controller.Handler(rec, req)
+
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("expected 304 without a body, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+// TestHandlerPrivateFiles tests the private files are only served with a
+// signed URL
+func TestHandlerPrivateFiles(t *testing.T) {
+	app := testutils.Setup(testutils.WithUserStore(true), testutils.WithSessionStore(true))
+	defer app.GetDatabase().Close()
+
+	storage, err := filesystem.NewStorage(filesystem.Disk{
+		DiskName:  filesystem.DRIVER_SQL,
+		Driver:    filesystem.DRIVER_SQL,
+		Url:       "/files",
+		DB:        app.GetDatabase(),
+		TableName: "snv_files_file",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	if err := storage.Put("/private/invoice.pdf", []byte("%PDF-invoice")); err != nil {
+		t.Fatalf("Failed to put file: %v", err)
+	}
+
+	controller := NewFileController(storage).SetPrivateFiles([]string{"/private"}, "test-signing-key")
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		if res := controller.Handler(rec, req); res != "" {
+			_, _ = rec.WriteString(res)
+		}
+		return rec
+	}
+
+	// Not signed, also through another path
+	for _, target := range []string{"/files/private/invoice.pdf", "/files/public/../private/invoice.pdf"} {
+		if rec := serve(target); rec.Code != http.StatusForbidden {
+			t.Errorf("Handler(%s) status = %d, want 403", target, rec.Code)
+		}
+	}
+
+	// Signed
+	query, err := signedurl.Sign("test-signing-key", "/private/invoice.pdf", time.Now().Add(time.Hour), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := serve("/files/private/invoice.pdf?" + query.Encode())
+	if rec.Code != http.StatusOK || rec.Body.String() != "%PDF-invoice" {
+		t.Fatalf("expected the signed file, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	if got := rec.Header().Get("Cache-Control"); got != config.MEDIA_PRIVATE_CACHE_CONTROL {
+		t.Errorf("Cache-Control = %q, want %q", got, config.MEDIA_PRIVATE_CACHE_CONTROL)
+	}
+
+	// Signed for a user, requested by a guest
+	query, err = signedurl.Sign("test-signing-key", "/private/invoice.pdf", time.Now().Add(time.Hour), "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := serve("/files/private/invoice.pdf?" + query.Encode()); rec.Code != http.StatusForbidden {
+		t.Errorf("expected the file of a user to be refused to a guest, got %d", rec.Code)
+	}
+
+	// Without a signing key the private files are not served
+	controller.SetPrivateFiles([]string{"/private"}, "")
+
+	if rec := serve("/files/private/invoice.pdf"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without a signing key, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package file
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/dracory/filesystem"
+)
+
+// fileOpener is a storage which can open a file to stream it, instead of
+// reading the whole file into memory
+type fileOpener interface {
+	Open(filePath string) (io.ReadSeekCloser, error)
+}
+
+// storageReader reads a stored file only when its bytes are read, so the
+// file is not read to answer a 304 Not Modified or a HEAD request
+type storageReader struct {
+	storage  filesystem.StorageInterface
+	filePath string
+	size     int64
+
+	// offset is the position before the file is read, see Seek
+	offset int64
+	reader *bytes.Reader
+}
+
+func (s *storageReader) Read(p []byte) (int, error) {
+	if s.reader == nil {
+		content, err := s.storage.ReadFile(s.filePath)
+
+		if err != nil {
+			return 0, err
+		}
+
+		s.reader = bytes.NewReader(content)
+
+		if _, err := s.reader.Seek(s.offset, io.SeekStart); err != nil {
+			return 0, err
+		}
+	}
+
+	return s.reader.Read(p)
+}
+
+func (s *storageReader) Seek(offset int64, whence int) (int64, error) {
+	if s.reader != nil {
+		return s.reader.Seek(offset, whence)
+	}
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.offset
+	case io.SeekEnd:
+		offset += s.size
+	default:
+		return 0, errors.New("storageReader.Seek: invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("storageReader.Seek: negative position")
+	}
+
+	s.offset = offset
+
+	return offset, nil
+}
+
+func (s *storageReader) Close() error {
+	return nil
+}
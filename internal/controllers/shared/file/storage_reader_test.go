@@ -0,0 +1,95 @@
+package file
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dracory/filesystem"
+)
+
+// countingStorage counts the files read whole
+type countingStorage struct {
+	filesystem.StorageInterface
+	reads int
+}
+
+func (s *countingStorage) ReadFile(filePath string) ([]byte, error) {
+	s.reads++
+	return []byte("0123456789"), nil
+}
+
+func (s *countingStorage) Exists(filePath string) (bool, error) {
+	return true, nil
+}
+
+func (s *countingStorage) Size(filePath string) (int64, error) {
+	return 10, nil
+}
+
+func (s *countingStorage) LastModified(filePath string) (time.Time, error) {
+	return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), nil
+}
+
+// TestStorageReaderSeekBeforeRead tests the reader seeks without reading
+// the file, and reads from the position sought
+func TestStorageReaderSeekBeforeRead(t *testing.T) {
+	storage := &countingStorage{}
+	reader := &storageReader{storage: storage, filePath: "/video.webm", size: 10}
+
+	if end, err := reader.Seek(0, io.SeekEnd); err != nil || end != 10 {
+		t.Fatalf("Seek(0, SeekEnd) = %d, %v, want 10", end, err)
+	}
+
+	if _, err := reader.Seek(4, io.SeekStart); err != nil {
+		t.Fatalf("Seek(4, SeekStart) error: %v", err)
+	}
+
+	if storage.reads != 0 {
+		t.Fatalf("expected no read before Read, got %d", storage.reads)
+	}
+
+	content, err := io.ReadAll(reader)
+	if err != nil || string(content) != "456789" {
+		t.Fatalf("ReadAll = %q, %v, want '456789'", content, err)
+	}
+
+	if storage.reads != 1 {
+		t.Errorf("expected one read, got %d", storage.reads)
+	}
+
+	if _, err := reader.Seek(-1, io.SeekStart); err == nil {
+		t.Error("expected an error for a negative position")
+	}
+}
+
+// TestHandlerNotModifiedDoesNotRead tests the file is not read to answer
+// a 304 Not Modified
+func TestHandlerNotModifiedDoesNotRead(t *testing.T) {
+	storage := &countingStorage{}
+	controller := NewFileController(storage)
+
+	req := httptest.NewRequest(http.MethodGet, "/media/video.webm", nil)
+	rec := httptest.NewRecorder()
+	controller.Handler(rec, req)
+
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || rec.Body.String() != "0123456789" || etag == "" {
+		t.Fatalf("expected the full file with an ETag, got %d %q %q", rec.Code, rec.Body.String(), etag)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/media/video.webm", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	controller.Handler(rec, req)
+
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", rec.Code)
+	}
+
+	if storage.reads != 1 {
+		t.Errorf("expected the file read only for the first request, got %d reads", storage.reads)
+	}
+}
@@ -114,7 +114,7 @@ func (c flashController) pageHTML(r *http.Request) hb.TagInterface {
 		HTML(message)
 
 	linkRedirect := hb.Hyperlink().Href(url).HTML("Click here to continue")
-	scriptRedirect := hb.Script("setTimeout(()=>{location.href=\"" + url + "\"}, " + time + "*1000)")
+	scriptRedirect := helpers.CSPNonceStampTag(r.Context(), hb.Script("setTimeout(()=>{location.href=\""+url+"\"}, "+time+"*1000)"))
 
 	container := hb.Div().
 		Class("container").
@@ -6,7 +6,6 @@ import (
 	"project/internal/controllers/shared/cdn"
 	"project/internal/controllers/shared/file"
 	"project/internal/controllers/shared/flash"
-	"project/internal/controllers/shared/media"
 	"project/internal/controllers/shared/page_not_found"
 	"project/internal/controllers/shared/resource"
 	"project/internal/controllers/shared/thumb"
@@ -34,7 +33,10 @@ func Routes(app app.AppInterface) []rtr.RouteInterface {
 		SetName("Shared > Files Controller").
 		SetPath(links.FILES).
 		SetMethod(http.MethodGet).
-		SetHTMLHandler(file.NewFileController(app.GetSqlFileStorage()).Handler)
+		SetHTMLHandler(file.NewFileController(app.GetSqlFileStorage()).
+			SetCacheControl(app.GetConfig().GetMediaCacheControl()).
+			SetPrivateFiles(app.GetConfig().GetMediaPrivatePaths(), app.GetConfig().GetMediaSigningKey()).
+			Handler)
 
 	flash := rtr.NewRoute().
 		SetName("Shared > Flash Controller").
@@ -45,7 +47,10 @@ func Routes(app app.AppInterface) []rtr.RouteInterface {
 		SetName("Shared > Media Controller").
 		SetPath(links.MEDIA).
 		SetMethod(http.MethodGet).
-		SetHTMLHandler(media.NewMediaController(app.GetSqlFileStorage()).Handler)
+		SetHTMLHandler(file.NewFileController(app.GetSqlFileStorage()).
+			SetCacheControl(app.GetConfig().GetMediaCacheControl()).
+			SetPrivateFiles(app.GetConfig().GetMediaPrivatePaths(), app.GetConfig().GetMediaSigningKey()).
+			Handler)
 
 	resources := rtr.NewRoute().
 		SetName("Shared > Resources Controller").
@@ -11,7 +11,10 @@ import (
 	neturl "net/url"
 	"os"
 	"project/internal/app"
+	"project/internal/config"
+	"project/internal/helpers"
 	"project/internal/resources"
+	"project/internal/signedurl"
 	"strings"
 	"time"
 
@@ -99,6 +102,7 @@ type thumbnailController struct {
 // HTTP Response Headers Set:
 //   - Content-Type: Based on file extension (image/jpeg, image/png, image/gif)
 //   - Cache-Control: max-age=604800 (7 days for SEO optimization)
+//   - ETag: The hash of the thumbnail, for the conditional requests
 func (controller *thumbnailController) Handler(w http.ResponseWriter, r *http.Request) string {
 	data, errorMessage := controller.prepareData(r)
 
@@ -114,8 +118,8 @@ func (controller *thumbnailController) Handler(w http.ResponseWriter, r *http.Re
 			thumb, err := fileCache.Fetch(cacheKey)
 
 			if err == nil {
-				controller.setHeaders(w, data.extension)
-				return thumb
+				controller.serve(w, r, data.extension, thumb)
+				return ""
 			}
 		}
 	}
@@ -134,8 +138,16 @@ func (controller *thumbnailController) Handler(w http.ResponseWriter, r *http.Re
 		}
 	}
 
-	controller.setHeaders(w, data.extension)
-	return thumb
+	controller.serve(w, r, data.extension, thumb)
+	return ""
+}
+
+// serve writes the thumbnail with its headers, see setHeaders. Answers the
+// If-None-Match header with 304 Not Modified, as the thumbnail has the
+// same ETag as long as the image and the parameters do not change
+func (controller *thumbnailController) serve(w http.ResponseWriter, r *http.Request, fileExtension string, thumb string) {
+	controller.setHeaders(w, fileExtension)
+	helpers.ServeContent(w, r, []byte(thumb), helpers.ServeContentOptions{})
 }
 
 // setHeaders configures appropriate HTTP response headers for thumbnail images.
@@ -149,7 +161,8 @@ func (controller *thumbnailController) Handler(w http.ResponseWriter, r *http.Re
 //   - other extensions --> empty string (will be handled by browser)
 //
 // Cache Strategy:
-//   - Sets Cache-Control: max-age=604800 (7 days)
+//   - Sets Cache-Control: max-age=604800 (7 days), or the
+//     MEDIA_THUMB_CACHE_CONTROL of the config
 //   - Optimized for SEO and performance
 //   - Allows browser and CDN caching for better user experience
 //
@@ -169,7 +182,16 @@ func (controller *thumbnailController) setHeaders(w http.ResponseWriter, fileExt
 		ElseIf(fileExtension == "gif", "image/gif").
 		Else(""))
 
-	w.Header().Set("Cache-Control", "max-age=604800") // cache for SEO
+	w.Header().Set("Cache-Control", controller.cacheControl()) // cache for SEO
+}
+
+// cacheControl returns the Cache-Control header of the thumbnails
+func (controller *thumbnailController) cacheControl() string {
+	if controller.app == nil || controller.app.GetConfig() == nil || controller.app.GetConfig().GetMediaThumbCacheControl() == "" {
+		return config.MEDIA_THUMB_CACHE_CONTROL_DEFAULT
+	}
+
+	return controller.app.GetConfig().GetMediaThumbCacheControl()
 }
 
 // prepareData extracts and validates thumbnail generation parameters from HTTP request.
@@ -396,6 +418,11 @@ func (controller *thumbnailController) generateThumb(data thumbnailControllerDat
 			return "", err.Error()
 		}
 	} else if data.isFiles {
+		// The thumbnails have no signed URLs, so the private files have none
+		if controller.app.GetConfig() != nil && signedurl.IsPrivate(data.path, controller.app.GetConfig().GetMediaPrivatePaths()) {
+			return "", "file not found"
+		}
+
 		storage := controller.app.GetSqlFileStorage()
 		if storage == nil {
 			controller.app.GetLogger().Error("Error at thumbnailController > generateThumb > from FILES", "error", "file storage not initialized")
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"project/internal/testutils"
 	"strings"
 	"testing"
 
@@ -474,6 +475,20 @@ func TestSetHeaders(t *testing.T) {
 	}
 }
 
+// TestSetHeadersConfigCacheControl verifies the Cache-Control header of the config
+func TestSetHeadersConfigCacheControl(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetMediaThumbCacheControl("public, max-age=60")
+
+	c := NewThumbController(testutils.Setup(testutils.WithCfg(cfg)))
+	rec := httptest.NewRecorder()
+	c.setHeaders(rec, "png")
+
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=60" {
+		t.Fatalf("expected Cache-Control public, max-age=60, got: %q", cc)
+	}
+}
+
 // TestPrepareDataParsing verifies size parsing and URL normalization via rtr routing
 func TestPrepareDataParsing(t *testing.T) {
 	// width x height parsing
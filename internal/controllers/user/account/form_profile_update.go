@@ -8,6 +8,7 @@ import (
 
 	"project/internal/app"
 	"project/internal/ext"
+	"project/internal/helpers"
 	"project/internal/links"
 
 	"github.com/dracory/geostore"
@@ -391,10 +392,8 @@ func (c *formProfileUpdate) Render(ctx context.Context) hb.TagInterface {
 		ID("SelectCountries").
 		Class("form-select").
 		Name("country").
-		Attr("onchange", `
-			const btn = document.getElementById('btnCountryChange');
-			if (btn) btn.click();
-		`)
+		Attr("data-flux-trigger", "change").
+		Attr("data-flux-action", "country_change")
 	countrySelect.Child(hb.Option().Text("").Value(""))
 	for _, country := range c.Countries {
 		countrySelect.Child(hb.Option().Text(country.Name()).Value(country.IsoCode2()).
@@ -457,7 +456,7 @@ func (c *formProfileUpdate) Render(ctx context.Context) hb.TagInterface {
 			TimerProgressBar: true,
 			Position:         "top-end",
 		})
-		alerts = alerts.Child(swal)
+		alerts = alerts.Child(helpers.CSPNonceStampTag(ctx, swal))
 	}
 	if c.FormSuccess != "" && c.FormRedirectTo != "" {
 		swal := hb.SwalSuccess(hb.SwalOptions{
@@ -468,7 +467,7 @@ func (c *formProfileUpdate) Render(ctx context.Context) hb.TagInterface {
 			TimerProgressBar: true,
 			Position:         "top-end",
 		})
-		alerts = alerts.Child(swal)
+		alerts = alerts.Child(helpers.CSPNonceStampTag(ctx, swal))
 	} else if c.FormSuccess != "" {
 		swal := hb.SwalSuccess(hb.SwalOptions{
 			Text:             c.FormSuccess,
@@ -476,15 +475,9 @@ func (c *formProfileUpdate) Render(ctx context.Context) hb.TagInterface {
 			TimerProgressBar: true,
 			Position:         "top-end",
 		})
-		alerts = alerts.Child(swal)
+		alerts = alerts.Child(helpers.CSPNonceStampTag(ctx, swal))
 	}
 
-	buttonCountryChangeHidden := hb.Button().
-		ID("btnCountryChange").
-		Type("submit").
-		Attr("data-flux-action", "country_change").
-		Style("display:none;")
-
 	userIdHidden := hb.Input().
 		Type("hidden").
 		Name("user_id").
@@ -493,7 +486,6 @@ func (c *formProfileUpdate) Render(ctx context.Context) hb.TagInterface {
 	form := hb.Form().
 		Child(alerts).
 		Child(userIdHidden).
-		Child(buttonCountryChangeHidden).
 		Child(buildActions("mb-3")).
 		Child(emailGroup).
 		Child(firstNameGroup).
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 
+	"project/internal/controllers/auth/oauthlogin"
 	"project/internal/controllers/user/partials"
 	"project/internal/ext"
 	"project/internal/helpers"
@@ -81,6 +82,12 @@ func (controller *profileController) Handler(w http.ResponseWriter, r *http.Requ
 				Class("container").
 				Child(hb.Paragraph().Text("Please keep your details updated so that we can contact you if you need our help.").Style("margin-bottom:20px;")).
 				Child(rendered).
+				ChildIf(oauthlogin.Enabled(controller.app), hb.Paragraph().
+					Class("mt-3").
+					Child(hb.I().Class("bi bi-link-45deg me-2")).
+					Text("Link your accounts with other providers to log in with them, from your ").
+					Child(hb.Hyperlink().Href(links.User().ConnectedAccounts()).Text("connected accounts")).
+					Text(".")).
 				Child(hb.BR()).
 				Child(hb.BR()),
 		)
@@ -0,0 +1,129 @@
+package orders
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/helpers"
+	"project/internal/payment"
+	"project/internal/pricing"
+	"strings"
+
+	"github.com/dracory/shopstore"
+	"github.com/dracory/userstore"
+	"github.com/spf13/cast"
+)
+
+// orderSummaryLine is a line of the order totals, e.g. the shipping
+type orderSummaryLine struct {
+	Label  string
+	Amount string
+	Bold   bool
+}
+
+// customerOrderFind returns the order with its line items, if the order
+// belongs to the user. Otherwise an error message is returned, which does
+// not reveal the existence of the orders of other customers
+func customerOrderFind(ctx context.Context, app app.AppInterface, authUser userstore.UserInterface, orderID string) (shopstore.OrderInterface, []shopstore.OrderLineItemInterface, string) {
+	if orderID == "" {
+		return nil, nil, "Order ID is required"
+	}
+
+	order, err := app.GetShopStore().OrderFindByID(ctx, orderID)
+
+	if err != nil {
+		app.GetLogger().Error("At orders > customerOrderFind > OrderFindByID", slog.String("error", err.Error()))
+		return nil, nil, "Sorry, there was an error loading your order. Please try again later."
+	}
+
+	if order == nil || order.GetCustomerID() != authUser.GetID() {
+		return nil, nil, "Order not found"
+	}
+
+	lineItems, err := app.GetShopStore().OrderLineItemList(ctx, shopstore.NewOrderLineItemQuery().
+		SetOrderID(order.GetID()))
+
+	if err != nil {
+		app.GetLogger().Error("At orders > customerOrderFind > OrderLineItemList", slog.String("error", err.Error()))
+		return nil, nil, "Sorry, there was an error loading your order. Please try again later."
+	}
+
+	return order, lineItems, ""
+}
+
+// orderDate returns the date the order was placed on
+func orderDate(order shopstore.OrderInterface) string {
+	date, _, _ := strings.Cut(order.GetCreatedAt(), " ")
+	return date
+}
+
+// orderStatusLabel returns the order status in words, e.g. "Awaiting payment"
+func orderStatusLabel(order shopstore.OrderInterface) string {
+	label := strings.ReplaceAll(order.GetStatus(), "_", " ")
+
+	if label == "" {
+		return "Unknown"
+	}
+
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+// orderStatusBadgeClass returns the class of the badge showing the status
+func orderStatusBadgeClass(order shopstore.OrderInterface) string {
+	switch {
+	case helpers.IsOrderPaid(order):
+		return "badge bg-success"
+	case order.GetStatus() == shopstore.ORDER_STATUS_CANCELLED:
+		return "badge bg-secondary"
+	default:
+		return "badge bg-warning text-dark"
+	}
+}
+
+// orderSummaryLines returns the totals of the order: the subtotal, the
+// adjustments from the price breakdown stored at checkout, and the total
+func orderSummaryLines(app app.AppInterface, order shopstore.OrderInterface, currency string) []orderSummaryLine {
+	lines := []orderSummaryLine{}
+
+	if subtotal := order.GetMeta(config.ORDER_META_SUBTOTAL); subtotal != "" {
+		lines = append(lines, orderSummaryLine{Label: "Subtotal", Amount: subtotal})
+	}
+
+	breakdown := []pricing.Line{}
+	if breakdownJSON := order.GetMeta(config.ORDER_META_PRICE_BREAKDOWN); breakdownJSON != "" {
+		if err := json.Unmarshal([]byte(breakdownJSON), &breakdown); err != nil {
+			app.GetLogger().Warn("At orders > orderSummaryLines", slog.String("error", err.Error()), slog.String("order_id", order.GetID()))
+			breakdown = []pricing.Line{}
+		}
+	}
+
+	for _, line := range breakdown {
+		label := line.Label
+		if line.Included {
+			label += " (included)"
+		}
+
+		lines = append(lines, orderSummaryLine{Label: label, Amount: payment.FormatMinorUnits(line.Amount, currency)})
+	}
+
+	// Orders placed without the pricing calculator only have the discount
+	if len(breakdown) == 0 && order.GetMeta(config.ORDER_META_DISCOUNT_AMOUNT) != "" {
+		lines = append(lines, orderSummaryLine{
+			Label:  "Discount (" + order.GetMeta(config.ORDER_META_DISCOUNT_CODE) + ")",
+			Amount: "-" + order.GetMeta(config.ORDER_META_DISCOUNT_AMOUNT),
+		})
+	}
+
+	lines = append(lines, orderSummaryLine{Label: "Total", Amount: order.GetPrice(), Bold: true})
+
+	return lines
+}
+
+// lineItemTotal returns the price of the line item multiplied by its quantity
+func lineItemTotal(lineItem shopstore.OrderLineItemInterface, currency string) string {
+	// Line items were stored from minor units, so the price is always valid
+	unitAmount, _ := payment.ToMinorUnits(lineItem.GetPrice(), currency)
+	return payment.FormatMinorUnits(unitAmount*cast.ToInt64(lineItem.GetQuantity()), currency)
+}
@@ -0,0 +1,150 @@
+package orders
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/invoice"
+	"project/internal/links"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dracory/req"
+	"github.com/dracory/shopstore"
+	"github.com/dracory/userstore"
+	"github.com/samber/lo"
+)
+
+// == CONTROLLER ==============================================================
+
+// orderInvoiceController downloads the invoice PDF of a paid order of the
+// authenticated customer. The invoice is issued when the order is paid,
+// or the first time it is downloaded for the orders paid before
+type orderInvoiceController struct {
+	app app.AppInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewOrderInvoiceController creates a new order invoice controller
+func NewOrderInvoiceController(app app.AppInterface) *orderInvoiceController {
+	return &orderInvoiceController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *orderInvoiceController) Handler(w http.ResponseWriter, r *http.Request) string {
+	orderID := req.GetStringTrimmed(r, "order_id")
+
+	pdf, fileName, errorMessage := controller.render(r, orderID)
+
+	if errorMessage != "" {
+		redirectURL := links.User().Orders()
+		if orderID != "" && errorMessage != "Order not found" {
+			redirectURL = links.User().OrderView(orderID)
+		}
+
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, redirectURL, 10)
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdf)))
+	w.Header().Set("Cache-Control", "private, no-store")
+
+	if _, err := w.Write(pdf); err != nil {
+		controller.app.GetLogger().Error("At orderInvoiceController > Handler", slog.String("error", err.Error()))
+	}
+
+	return ""
+}
+
+// == PRIVATE METHODS =========================================================
+
+// render issues the invoice of the order if needed, and renders it to PDF
+func (controller *orderInvoiceController) render(r *http.Request, orderID string) (pdf []byte, fileName string, errorMessage string) {
+	if controller.app.GetShopStore() == nil {
+		return nil, "", "Sorry, the shop is currently unavailable. Please try again later."
+	}
+
+	authUser := helpers.GetAuthUser(r)
+
+	if authUser == nil {
+		return nil, "", "Please log in to download your invoice."
+	}
+
+	order, lineItems, errorMessage := customerOrderFind(r.Context(), controller.app, authUser, orderID)
+
+	if errorMessage != "" {
+		return nil, "", errorMessage
+	}
+
+	if !helpers.IsOrderPaid(order) {
+		return nil, "", "The invoice is available once the order has been paid."
+	}
+
+	invoiceStore, err := invoice.NewStore(controller.app.GetCustomStore())
+	if err != nil {
+		controller.app.GetLogger().Error("At orderInvoiceController > render > NewStore", slog.String("error", err.Error()))
+		return nil, "", "Sorry, invoices are currently unavailable. Please try again later."
+	}
+
+	// The invoice is issued when the order is paid, orders paid before
+	// the invoices existed get theirs on the first download
+	issued, err := invoiceStore.InvoiceIssue(order.GetID(), time.Now())
+	if errors.Is(err, invoice.ErrIssuing) {
+		return nil, "", "Your invoice is being prepared. Please try again in a moment."
+	}
+
+	if err != nil {
+		controller.app.GetLogger().Error("At orderInvoiceController > render > InvoiceIssue", slog.String("error", err.Error()))
+		return nil, "", "Sorry, the invoice could not be created. Please try again later."
+	}
+
+	pdf, err = invoice.RenderPDF(controller.document(r, authUser, *issued, order, lineItems))
+	if err != nil {
+		controller.app.GetLogger().Error("At orderInvoiceController > render > RenderPDF", slog.String("error", err.Error()))
+		return nil, "", "Sorry, the invoice could not be created. Please try again later."
+	}
+
+	return pdf, issued.FormattedNumber() + ".pdf", ""
+}
+
+// document collects the content of the invoice
+func (controller *orderInvoiceController) document(r *http.Request, authUser userstore.UserInterface, issued invoice.Invoice, order shopstore.OrderInterface, lineItems []shopstore.OrderLineItemInterface) invoice.Document {
+	currency := controller.app.GetConfig().GetPaymentCurrency()
+
+	email, firstName, lastName, businessName, _, err := ext.UserUntokenizeTransparently(r.Context(), controller.app, authUser)
+	if err != nil {
+		controller.app.GetLogger().Warn("At orderInvoiceController > document", slog.String("error", err.Error()))
+	}
+
+	customerName := strings.TrimSpace(firstName + " " + lastName)
+	if businessName != "" {
+		customerName = strings.TrimSpace(customerName + ", " + businessName)
+	}
+
+	return invoice.Document{
+		Invoice:       issued,
+		SellerName:    controller.app.GetConfig().GetAppName(),
+		CustomerName:  strings.TrimPrefix(customerName, ", "),
+		CustomerEmail: email,
+		OrderID:       order.GetID(),
+		Currency:      currency,
+		Lines: lo.Map(lineItems, func(lineItem shopstore.OrderLineItemInterface, _ int) invoice.DocumentLine {
+			return invoice.DocumentLine{
+				Description: lineItem.GetTitle(),
+				Quantity:    lineItem.GetQuantity(),
+				UnitPrice:   lineItem.GetPrice(),
+				Total:       lineItemTotal(lineItem, currency),
+			}
+		}),
+		Totals: lo.Map(orderSummaryLines(controller.app, order, currency), func(line orderSummaryLine, _ int) invoice.DocumentTotal {
+			return invoice.DocumentTotal{Label: line.Label, Amount: line.Amount, Bold: line.Bold}
+		}),
+	}
+}
@@ -0,0 +1,89 @@
+package orders
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"project/internal/invoice"
+	"project/internal/testutils"
+
+	"github.com/dracory/shopstore"
+	"github.com/dracory/test"
+)
+
+func TestOrderInvoiceController_DownloadsPDF(t *testing.T) {
+	app := setupOrdersApp(t)
+	first := seedOrder(t, app, "buyer", shopstore.ORDER_STATUS_COMPLETED)
+	second := seedOrder(t, app, "buyer", shopstore.ORDER_STATUS_AWAITING_FULFILLMENT)
+
+	for i, order := range []shopstore.OrderInterface{first, second, first} {
+		body, response, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewOrderInvoiceController(app).Handler, test.NewRequestOptions{
+			QueryParams: url.Values{"order_id": {order.GetID()}},
+		}, "buyer")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response.Header.Get("Content-Type") != "application/pdf" {
+			t.Fatalf("expected a PDF, got %q", response.Header.Get("Content-Type"))
+		}
+
+		if !strings.HasPrefix(body, "%PDF-") || !strings.Contains(body, "(Blue Mug)") {
+			t.Fatal("expected the invoice PDF of the order")
+		}
+
+		// The first order keeps its number when downloaded again
+		wantNumber := []string{"INV-000001", "INV-000002", "INV-000001"}[i]
+		if !strings.Contains(response.Header.Get("Content-Disposition"), wantNumber+".pdf") {
+			t.Errorf("download %d: Content-Disposition = %q, want %s", i, response.Header.Get("Content-Disposition"), wantNumber)
+		}
+	}
+
+	invoiceStore, err := invoice.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issued, err := invoiceStore.InvoiceFindByOrderID(second.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if issued == nil || issued.Number != 2 {
+		t.Fatalf("expected the second order to have invoice number 2, got %+v", issued)
+	}
+}
+
+func TestOrderInvoiceController_UnpaidOrder(t *testing.T) {
+	app := setupOrdersApp(t)
+	order := seedOrder(t, app, "buyer", shopstore.ORDER_STATUS_AWAITING_PAYMENT)
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewOrderInvoiceController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"order_id": {order.GetID()}},
+	}, "buyer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected a redirect for an unpaid order, got %d", response.StatusCode)
+	}
+}
+
+func TestOrderInvoiceController_OtherCustomerOrder(t *testing.T) {
+	app := setupOrdersApp(t)
+	order := seedOrder(t, app, "someone-else", shopstore.ORDER_STATUS_COMPLETED)
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewOrderInvoiceController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"order_id": {order.GetID()}},
+	}, "buyer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected a redirect for the order of another customer, got %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,158 @@
+package orders
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/controllers/user/partials"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"sort"
+
+	"github.com/dracory/bs"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dracory/shopstore"
+	"github.com/dracory/userstore"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// == CONTROLLER ==============================================================
+
+// orderListController lists the orders of the authenticated customer
+type orderListController struct {
+	app app.AppInterface
+}
+
+type orderListControllerData struct {
+	authUser   userstore.UserInterface
+	currency   string
+	orderList  []shopstore.OrderInterface
+	orderCount int
+	page       int
+	perPage    int
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewOrderListController creates a new order list controller
+func NewOrderListController(app app.AppInterface) *orderListController {
+	return &orderListController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *orderListController) Handler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.User().Home(), 10)
+	}
+
+	pageHeader := partials.PageHeader("bi-bag", "My Orders", []layouts.Breadcrumb{
+		{Name: "Dashboard", Icon: "bi-speedometer2", URL: links.User().Home()},
+		{Name: "My Orders", URL: links.User().Orders()},
+	})
+
+	page := hb.Section().
+		Child(hb.Div().
+			Class("container").
+			Child(pageHeader)).
+		Child(hb.Div().
+			Class("container").
+			Child(controller.table(data)))
+
+	return layouts.NewUserLayout(controller.app, r, layouts.Options{
+		Title:   "My Orders",
+		Content: hb.NewDiv().Class("p-3").Child(page),
+	}).ToHTML()
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *orderListController) table(data orderListControllerData) hb.TagInterface {
+	if data.orderCount == 0 {
+		return hb.Div().
+			Class("alert alert-info").
+			Text("You have not placed any orders yet. ").
+			Child(hb.A().Href(links.Website().Shop()).Text("Visit the shop"))
+	}
+
+	rows := lo.Map(data.orderList, func(order shopstore.OrderInterface, _ int) hb.TagInterface {
+		return hb.TR().
+			Child(hb.TD().Child(hb.A().Href(links.User().OrderView(order.GetID())).Text(order.GetID()))).
+			Child(hb.TD().Text(orderDate(order))).
+			Child(hb.TD().Child(hb.Span().Class(orderStatusBadgeClass(order)).Text(orderStatusLabel(order)))).
+			Child(hb.TD().Class("text-end").Text(data.currency + " " + order.GetPrice())).
+			Child(hb.TD().
+				Class("text-end").
+				Child(hb.A().
+					Class("btn btn-sm btn-outline-primary").
+					Href(links.User().OrderView(order.GetID())).
+					Text("View")).
+				ChildIf(helpers.IsOrderPaid(order), hb.A().
+					Class("btn btn-sm btn-outline-secondary ms-2").
+					Href(links.User().OrderInvoice(order.GetID())).
+					Child(hb.I().Class("bi bi-file-earmark-pdf me-1")).
+					Text("Invoice")))
+	})
+
+	pagination := bs.Pagination(bs.PaginationOptions{
+		NumberItems:       data.orderCount,
+		CurrentPageNumber: data.page,
+		PagesToShow:       10,
+		PerPage:           data.perPage,
+		URL:               links.User().Orders(map[string]string{"page": ""}),
+	})
+
+	return hb.Wrap().
+		Child(hb.Table().
+			Class("table table-striped align-middle").
+			Child(hb.Thead().Child(hb.TR().
+				Child(hb.TH().Text("Order")).
+				Child(hb.TH().Text("Date")).
+				Child(hb.TH().Text("Status")).
+				Child(hb.TH().Class("text-end").Text("Total")).
+				Child(hb.TH()))).
+			Child(hb.Tbody().Children(rows))).
+		Child(hb.Div().
+			Class("d-flex justify-content-center mt-4").
+			HTML(pagination))
+}
+
+func (controller *orderListController) prepareData(r *http.Request) (data orderListControllerData, errorMessage string) {
+	if controller.app.GetShopStore() == nil {
+		return data, "Sorry, the shop is currently unavailable. Please try again later."
+	}
+
+	data.authUser = helpers.GetAuthUser(r)
+
+	if data.authUser == nil {
+		return data, "Please log in to view your orders."
+	}
+
+	orderList, err := controller.app.GetShopStore().OrderList(r.Context(), shopstore.NewOrderQuery().
+		SetCustomerID(data.authUser.GetID()))
+
+	if err != nil {
+		controller.app.GetLogger().Error("At orderListController > prepareData > OrderList", slog.String("error", err.Error()))
+		return data, "Sorry, there was an error loading your orders. Please try again later."
+	}
+
+	// Most recent first
+	sort.SliceStable(orderList, func(i, j int) bool {
+		return orderList[i].GetCreatedAt() > orderList[j].GetCreatedAt()
+	})
+
+	data.currency = controller.app.GetConfig().GetPaymentCurrency()
+	data.perPage = 20
+	data.page = max(0, cast.ToInt(req.GetStringTrimmed(r, "page")))
+	data.orderCount = len(orderList)
+
+	offset := min(data.page*data.perPage, len(orderList))
+	data.orderList = orderList[offset:min(offset+data.perPage, len(orderList))]
+
+	return data, ""
+}
@@ -0,0 +1,113 @@
+package orders
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/testutils"
+
+	"github.com/dracory/shopstore"
+	"github.com/dracory/test"
+)
+
+func setupOrdersApp(t *testing.T) app.AppInterface {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetShopStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+
+	return testutils.Setup(testutils.WithCfg(cfg))
+}
+
+// seedOrder creates an order of the customer with one line item of two mugs
+func seedOrder(t *testing.T, app app.AppInterface, customerID string, status string) shopstore.OrderInterface {
+	t.Helper()
+
+	order := shopstore.NewOrder()
+	order.SetCustomerID(customerID)
+	order.SetStatus(status)
+	order.SetQuantity("2")
+	order.SetPrice("20.00")
+	if err := order.SetMeta(config.ORDER_META_SUBTOTAL, "20.00"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.GetShopStore().OrderCreate(context.Background(), order); err != nil {
+		t.Fatalf("failed to create order: %v", err)
+	}
+
+	lineItem := shopstore.NewOrderLineItem()
+	lineItem.SetOrderID(order.GetID())
+	lineItem.SetProductID("1001")
+	lineItem.SetTitle("Blue Mug")
+	lineItem.SetQuantity("2")
+	lineItem.SetPrice("10.00")
+
+	if err := app.GetShopStore().OrderLineItemCreate(context.Background(), lineItem); err != nil {
+		t.Fatalf("failed to create order line item: %v", err)
+	}
+
+	return order
+}
+
+func TestOrderListController_ListsOwnOrders(t *testing.T) {
+	app := setupOrdersApp(t)
+
+	paid := seedOrder(t, app, "buyer", shopstore.ORDER_STATUS_AWAITING_FULFILLMENT)
+	unpaid := seedOrder(t, app, "buyer", shopstore.ORDER_STATUS_AWAITING_PAYMENT)
+	other := seedOrder(t, app, "someone-else", shopstore.ORDER_STATUS_COMPLETED)
+
+	body, _, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewOrderListController(app).Handler, test.NewRequestOptions{}, "buyer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{paid.GetID(), unpaid.GetID(), "Awaiting fulfillment", "Awaiting payment"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected the order list to contain %q", expected)
+		}
+	}
+
+	if strings.Contains(body, other.GetID()) {
+		t.Error("expected the orders of other customers not to be listed")
+	}
+
+	// Only the paid order has an invoice
+	if strings.Count(body, "/user/orders/invoice") != 1 {
+		t.Errorf("expected one invoice link, got %d", strings.Count(body, "/user/orders/invoice"))
+	}
+}
+
+func TestOrderListController_NoOrders(t *testing.T) {
+	app := setupOrdersApp(t)
+
+	body, _, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewOrderListController(app).Handler, test.NewRequestOptions{}, "buyer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "You have not placed any orders yet") {
+		t.Error("expected the empty order list message")
+	}
+}
+
+func TestOrderListController_RequiresLogin(t *testing.T) {
+	app := setupOrdersApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewOrderListController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected status %d, got %d", http.StatusSeeOther, response.StatusCode)
+	}
+}
@@ -0,0 +1,150 @@
+package orders
+
+import (
+	"net/http"
+	"project/internal/app"
+	"project/internal/controllers/user/partials"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dracory/shopstore"
+	"github.com/samber/lo"
+)
+
+// == CONTROLLER ==============================================================
+
+// orderViewController shows an order of the authenticated customer
+type orderViewController struct {
+	app app.AppInterface
+}
+
+type orderViewControllerData struct {
+	currency  string
+	order     shopstore.OrderInterface
+	lineItems []shopstore.OrderLineItemInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewOrderViewController creates a new order view controller
+func NewOrderViewController(app app.AppInterface) *orderViewController {
+	return &orderViewController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *orderViewController) Handler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.User().Orders(), 10)
+	}
+
+	title := "Order " + data.order.GetID()
+
+	pageHeader := partials.PageHeader("bi-bag", title, []layouts.Breadcrumb{
+		{Name: "Dashboard", Icon: "bi-speedometer2", URL: links.User().Home()},
+		{Name: "My Orders", URL: links.User().Orders()},
+		{Name: title, URL: links.User().OrderView(data.order.GetID())},
+	})
+
+	page := hb.Section().
+		Child(hb.Div().
+			Class("container").
+			Child(pageHeader)).
+		Child(hb.Div().
+			Class("container").
+			Child(controller.details(data)))
+
+	return layouts.NewUserLayout(controller.app, r, layouts.Options{
+		Title:   title,
+		Content: hb.NewDiv().Class("p-3").Child(page),
+	}).ToHTML()
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *orderViewController) details(data orderViewControllerData) hb.TagInterface {
+	rows := lo.Map(data.lineItems, func(lineItem shopstore.OrderLineItemInterface, _ int) hb.TagInterface {
+		return hb.TR().
+			Child(hb.TD().Text(lineItem.GetTitle())).
+			Child(hb.TD().Class("text-end").Text(lineItem.GetQuantity())).
+			Child(hb.TD().Class("text-end").Text(lineItem.GetPrice())).
+			Child(hb.TD().Class("text-end").Text(lineItemTotal(lineItem, data.currency)))
+	})
+
+	summaryRows := lo.Map(orderSummaryLines(controller.app, data.order, data.currency), func(line orderSummaryLine, _ int) hb.TagInterface {
+		label, amount := hb.TD(), hb.TD()
+		if line.Bold {
+			label, amount = hb.TH(), hb.TH()
+		}
+
+		return hb.TR().
+			Child(label.Attr("colspan", "3").Class("text-end").Text(line.Label)).
+			Child(amount.Class("text-end").Text(data.currency + " " + line.Amount))
+	})
+
+	summary := hb.Div().
+		Class("d-flex flex-wrap gap-4 align-items-center mb-4").
+		Child(hb.Div().
+			Child(hb.Span().Class("text-muted me-2").Text("Placed on")).
+			Text(orderDate(data.order))).
+		Child(hb.Div().
+			Child(hb.Span().Class("text-muted me-2").Text("Status")).
+			Child(hb.Span().Class(orderStatusBadgeClass(data.order)).Text(orderStatusLabel(data.order))))
+
+	table := hb.Table().
+		Class("table table-striped").
+		Child(hb.Thead().Child(hb.TR().
+			Child(hb.TH().Text("Product")).
+			Child(hb.TH().Class("text-end").Text("Quantity")).
+			Child(hb.TH().Class("text-end").Text("Price")).
+			Child(hb.TH().Class("text-end").Text("Total")))).
+		Child(hb.Tbody().Children(rows)).
+		Child(hb.Tfoot().Children(summaryRows))
+
+	buttons := hb.Div().
+		Class("d-flex gap-2").
+		Child(hb.A().
+			Class("btn btn-secondary").
+			Href(links.User().Orders()).
+			Child(hb.I().Class("bi bi-arrow-left me-1")).
+			Text("Back to Orders")).
+		ChildIf(helpers.IsOrderPaid(data.order), hb.A().
+			Class("btn btn-primary").
+			Href(links.User().OrderInvoice(data.order.GetID())).
+			Child(hb.I().Class("bi bi-file-earmark-pdf me-1")).
+			Text("Download Invoice"))
+
+	return hb.Wrap().
+		Child(summary).
+		Child(table).
+		Child(buttons)
+}
+
+func (controller *orderViewController) prepareData(r *http.Request) (data orderViewControllerData, errorMessage string) {
+	if controller.app.GetShopStore() == nil {
+		return data, "Sorry, the shop is currently unavailable. Please try again later."
+	}
+
+	authUser := helpers.GetAuthUser(r)
+
+	if authUser == nil {
+		return data, "Please log in to view your order."
+	}
+
+	order, lineItems, errorMessage := customerOrderFind(r.Context(), controller.app, authUser, req.GetStringTrimmed(r, "order_id"))
+
+	if errorMessage != "" {
+		return data, errorMessage
+	}
+
+	data.currency = controller.app.GetConfig().GetPaymentCurrency()
+	data.order = order
+	data.lineItems = lineItems
+
+	return data, ""
+}
@@ -0,0 +1,47 @@
+package orders
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"project/internal/testutils"
+
+	"github.com/dracory/shopstore"
+	"github.com/dracory/test"
+)
+
+func TestOrderViewController_ShowsOrder(t *testing.T) {
+	app := setupOrdersApp(t)
+	order := seedOrder(t, app, "buyer", shopstore.ORDER_STATUS_AWAITING_FULFILLMENT)
+
+	body, _, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewOrderViewController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"order_id": {order.GetID()}},
+	}, "buyer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Blue Mug", "10.00", "20.00", "Subtotal", "Download Invoice"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected the order to contain %q", expected)
+		}
+	}
+}
+
+func TestOrderViewController_OtherCustomerOrder(t *testing.T) {
+	app := setupOrdersApp(t)
+	order := seedOrder(t, app, "someone-else", shopstore.ORDER_STATUS_AWAITING_FULFILLMENT)
+
+	body, response, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewOrderViewController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"order_id": {order.GetID()}},
+	}, "buyer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusSeeOther || strings.Contains(body, "Blue Mug") {
+		t.Fatal("expected the order of another customer not to be shown")
+	}
+}
@@ -3,7 +3,11 @@ package user
 import (
 	userAccount "project/internal/controllers/user/account"
 	userHome "project/internal/controllers/user/home"
+	userOrders "project/internal/controllers/user/orders"
+	userSecurity "project/internal/controllers/user/security"
+	userSubscription "project/internal/controllers/user/subscription"
 	"project/internal/app"
+	"project/internal/controllers/auth/oauthlogin"
 
 	"project/internal/links"
 	"project/internal/middlewares"
@@ -27,11 +31,84 @@ func Routes(app app.AppInterface) []rtr.RouteInterface {
 		SetPath(links.USER_PROFILE).
 		SetHTMLHandler(userAccount.NewProfileController(app).Handler)
 
+	twoFactor := rtr.NewRoute().
+		SetName("User > Two Factor").
+		SetPath(links.USER_TWO_FACTOR).
+		SetHTMLHandler(userSecurity.NewTwoFactorController(app).Handler)
+
+	connectedAccounts := rtr.NewRoute().
+		SetName("User > Connected Accounts").
+		SetPath(links.USER_CONNECTED_ACCOUNTS).
+		SetHTMLHandler(userSecurity.NewConnectedAccountsController(app).Handler)
+
+	apiTokens := rtr.NewRoute().
+		SetName("User > API Tokens").
+		SetPath(links.USER_API_TOKENS).
+		SetHTMLHandler(userSecurity.NewAPITokensController(app).Handler)
+
+	sessions := rtr.NewRoute().
+		SetName("User > Sessions").
+		SetPath(links.USER_SESSIONS).
+		SetHTMLHandler(userSecurity.NewSessionsController(app).Handler)
+
+	orders := rtr.NewRoute().
+		SetName("User > Orders").
+		SetPath(links.USER_ORDERS).
+		SetHTMLHandler(userOrders.NewOrderListController(app).Handler)
+
+	orderView := rtr.NewRoute().
+		SetName("User > Orders > View").
+		SetPath(links.USER_ORDER_VIEW).
+		SetHTMLHandler(userOrders.NewOrderViewController(app).Handler)
+
+	orderInvoice := rtr.NewRoute().
+		SetName("User > Orders > Invoice").
+		SetPath(links.USER_ORDER_INVOICE).
+		SetHTMLHandler(userOrders.NewOrderInvoiceController(app).Handler)
+
+	billing := rtr.NewRoute().
+		SetName("User > Billing").
+		SetPath(links.USER_SUBSCRIPTION).
+		SetHTMLHandler(userSubscription.NewBillingController(app).Handler)
+
+	billingPlanSelect := rtr.NewRoute().
+		SetName("User > Billing > Plan Select").
+		SetPath(links.USER_SUBSCRIPTION_PLAN_SELECT).
+		SetHTMLHandler(userSubscription.NewBillingController(app).Handler)
+
+	billingPaymentSuccess := rtr.NewRoute().
+		SetName("User > Billing > Payment Success").
+		SetPath(links.USER_SUBSCRIPTION_PAYMENT_SUCCESS).
+		SetHTMLHandler(userSubscription.NewBillingPaymentController(app).SuccessHandler)
+
+	billingPaymentCanceled := rtr.NewRoute().
+		SetName("User > Billing > Payment Canceled").
+		SetPath(links.USER_SUBSCRIPTION_PAYMENT_CANCELED).
+		SetHTMLHandler(userSubscription.NewBillingPaymentController(app).CanceledHandler)
+
 	// IMPORTANT: Specific routes must come BEFORE catch-all routes
 	// The catch-all route /user/* will match any path starting with /user/
 	// so it must be registered last to avoid intercepting specific routes
 
 	userRoutes := []rtr.RouteInterface{}
+	if app.GetShopStore() != nil {
+		userRoutes = append(userRoutes, orders, orderView, orderInvoice)
+	}
+	if app.GetSubscriptionStore() != nil {
+		userRoutes = append(userRoutes, billing, billingPlanSelect, billingPaymentSuccess, billingPaymentCanceled)
+	}
+	if app.GetVaultStore() != nil {
+		userRoutes = append(userRoutes, twoFactor)
+	}
+	if oauthlogin.Enabled(app) {
+		userRoutes = append(userRoutes, connectedAccounts)
+	}
+	if app.GetCustomStore() != nil {
+		userRoutes = append(userRoutes, apiTokens)
+	}
+	if app.GetSessionStore() != nil {
+		userRoutes = append(userRoutes, sessions)
+	}
 	userRoutes = append(userRoutes, profile)
 	userRoutes = append(userRoutes, home)
 	userRoutes = append(userRoutes, homeCatchAll) // Must be last!
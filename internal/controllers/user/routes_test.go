@@ -58,6 +58,7 @@ func TestUserRoutesAreAdded(t *testing.T) {
 		testutils.WithGeoStore(true),
 		testutils.WithSessionStore(true),
 		testutils.WithShopStore(true),
+		testutils.WithSubscriptionStore(true),
 		testutils.WithUserStore(true),
 	)
 	routes := userDir.Routes(app)
@@ -65,6 +66,12 @@ func TestUserRoutesAreAdded(t *testing.T) {
 	expectedPaths := []string{
 		links.USER_HOME,
 		links.USER_PROFILE,
+		links.USER_ORDERS,
+		links.USER_ORDER_VIEW,
+		links.USER_ORDER_INVOICE,
+		links.USER_SUBSCRIPTION,
+		links.USER_SUBSCRIPTION_PAYMENT_SUCCESS,
+		links.USER_SUBSCRIPTION_PAYMENT_CANCELED,
 		links.USER_HOME + links.CATCHALL, // catch-all route
 	}
 
@@ -382,3 +389,66 @@ func TestUserHomePage(t *testing.T) {
 		t.Errorf("handler returned unexpected body: got %v want %v", body, expected)
 	}
 }
+
+// TestUserRoutesConnectedAccounts verifies the connected accounts route is
+// included only when a social login provider is configured
+func TestUserRoutesConnectedAccounts(t *testing.T) {
+	for _, configured := range []bool{true, false} {
+		cfg := testutils.DefaultConf()
+		cfg.SetCustomStoreUsed(true)
+		if configured {
+			cfg.SetOAuthGitHubClientID("github-id")
+			cfg.SetOAuthGitHubClientSecret("github-secret")
+		}
+		app := testutils.Setup(testutils.WithCfg(cfg))
+
+		found := false
+		for _, rt := range userDir.Routes(app) {
+			if rt.GetPath() == links.USER_CONNECTED_ACCOUNTS {
+				found = true
+			}
+		}
+
+		if found != configured {
+			t.Errorf("connected accounts route present: %v, expected: %v", found, configured)
+		}
+	}
+}
+
+// TestUserRoutesAPITokens verifies the API tokens route is included only
+// when the custom store is used, where the tokens are stored
+func TestUserRoutesAPITokens(t *testing.T) {
+	for _, used := range []bool{true, false} {
+		app := testutils.Setup(testutils.WithCustomStore(used))
+
+		found := false
+		for _, rt := range userDir.Routes(app) {
+			if rt.GetPath() == links.USER_API_TOKENS {
+				found = true
+			}
+		}
+
+		if found != used {
+			t.Errorf("API tokens route present: %v, expected: %v", found, used)
+		}
+	}
+}
+
+// TestUserRoutesSessions verifies the sessions route is included only
+// when the session store is used
+func TestUserRoutesSessions(t *testing.T) {
+	for _, used := range []bool{true, false} {
+		app := testutils.Setup(testutils.WithSessionStore(used))
+
+		found := false
+		for _, rt := range userDir.Routes(app) {
+			if rt.GetPath() == links.USER_SESSIONS {
+				found = true
+			}
+		}
+
+		if found != used {
+			t.Errorf("sessions route present: %v, expected: %v", found, used)
+		}
+	}
+}
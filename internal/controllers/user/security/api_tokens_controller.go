@@ -0,0 +1,377 @@
+package security
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/apitoken"
+	"project/internal/app"
+	"project/internal/controllers/user/partials"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"strings"
+	"time"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dracory/userstore"
+	"github.com/dromara/carbon/v2"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// == CONTROLLER ==============================================================
+
+// apiTokensController lets the user create personal API tokens, to call
+// the API, and revoke them.
+//
+// The token is shown once, right after it is created, as only its hash
+// is stored.
+type apiTokensController struct {
+	app app.AppInterface
+}
+
+type apiTokensControllerData struct {
+	authUser userstore.UserInterface
+	tokens   []apitoken.Token
+
+	// createdToken is the token just created, shown once
+	createdToken string
+	formName     string
+	formScopes   []string
+	formExpires  string
+	formError    string
+}
+
+type apiTokenExpiryOption struct {
+	value string
+	label string
+}
+
+// apiTokenExpiryOptions are the expiry choices, in days ("0" for never)
+var apiTokenExpiryOptions = []apiTokenExpiryOption{
+	{"30", "30 days"},
+	{"90", "90 days"},
+	{"365", "1 year"},
+	{"0", "Never"},
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewAPITokensController creates a new API tokens controller
+func NewAPITokensController(app app.AppInterface) *apiTokensController {
+	return &apiTokensController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *apiTokensController) Handler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.User().Home(), 10)
+	}
+
+	if r.Method == http.MethodPost {
+		switch req.GetStringTrimmed(r, "action") {
+		case "create":
+			return controller.postCreate(w, r, data)
+		case "revoke":
+			return controller.postRevoke(w, r, data)
+		default:
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Invalid action", links.User().APITokens(), 10)
+		}
+	}
+
+	return controller.page(r, data)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *apiTokensController) prepareData(r *http.Request) (data apiTokensControllerData, errorMessage string) {
+	data.authUser = helpers.GetAuthUser(r)
+
+	if data.authUser == nil {
+		return data, "Please log in to manage your API tokens."
+	}
+
+	tokenStore, err := apitoken.NewStore(controller.app.GetCustomStore())
+
+	if err != nil {
+		controller.app.GetLogger().Error("At apiTokensController > prepareData > NewStore", slog.String("error", err.Error()))
+		return data, "Sorry, API tokens are currently unavailable."
+	}
+
+	data.tokens, err = tokenStore.TokenListByUserID(data.authUser.GetID())
+
+	if err != nil {
+		controller.app.GetLogger().Error("At apiTokensController > prepareData > TokenListByUserID", slog.String("error", err.Error()))
+		return data, "Sorry, your API tokens could not be loaded. Please try again later."
+	}
+
+	data.formExpires = "90"
+
+	return data, ""
+}
+
+// postCreate creates the token, and shows it on the page once
+func (controller *apiTokensController) postCreate(w http.ResponseWriter, r *http.Request, data apiTokensControllerData) string {
+	if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", links.User().APITokens(), 10)
+	}
+
+	data.formName = req.GetStringTrimmed(r, "name")
+	data.formScopes = req.GetArray(r, "scopes", []string{})
+	data.formExpires = req.GetStringTrimmed(r, "expires")
+
+	if !lo.ContainsBy(apiTokenExpiryOptions, func(option apiTokenExpiryOption) bool {
+		return option.value == data.formExpires
+	}) {
+		data.formError = "Please select when the token expires."
+		return controller.page(r, data)
+	}
+
+	expiresAt := time.Time{}
+	if days := cast.ToInt(data.formExpires); days > 0 {
+		expiresAt = time.Now().UTC().AddDate(0, 0, days)
+	}
+
+	token, created, err := ext.APITokenCreate(controller.app, data.authUser, data.formName, data.formScopes, expiresAt)
+
+	if err != nil {
+		data.formError = capitalize(err.Error()) + "."
+		return controller.page(r, data)
+	}
+
+	data.createdToken = token
+	data.tokens = append([]apitoken.Token{*created}, data.tokens...)
+	data.formName = ""
+	data.formScopes = nil
+
+	return controller.page(r, data)
+}
+
+// postRevoke revokes the token of the user
+func (controller *apiTokensController) postRevoke(w http.ResponseWriter, r *http.Request, data apiTokensControllerData) string {
+	apiTokensURL := links.User().APITokens()
+
+	if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", apiTokensURL, 10)
+	}
+
+	tokenID := req.GetStringTrimmed(r, "token_id")
+
+	// Only the tokens of the user are listed, so another user's token
+	// cannot be revoked
+	token, found := lo.Find(data.tokens, func(token apitoken.Token) bool {
+		return token.ID == tokenID
+	})
+
+	if !found {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Token not found.", apiTokensURL, 10)
+	}
+
+	if err := ext.APITokenRevoke(controller.app, &token); err != nil {
+		controller.app.GetLogger().Error("At apiTokensController > postRevoke > APITokenRevoke", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Sorry, the token could not be revoked. Please try again later.", apiTokensURL, 10)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "The token has been revoked.", apiTokensURL, 10)
+}
+
+func (controller *apiTokensController) page(r *http.Request, data apiTokensControllerData) string {
+	pageHeader := partials.PageHeader("bi-key", "API Tokens", []layouts.Breadcrumb{
+		{Name: "Dashboard", Icon: "bi-speedometer2", URL: links.User().Home()},
+		{Name: "API Tokens", URL: links.User().APITokens()},
+	})
+
+	page := hb.Section().
+		Child(hb.Div().
+			Class("container").
+			Child(pageHeader)).
+		Child(hb.Div().
+			Class("container").
+			ChildIf(data.createdToken != "", controller.createdTokenCard(data.createdToken)).
+			Child(controller.createCard(data)).
+			Child(controller.tokensCard(data)))
+
+	return layouts.NewUserLayout(controller.app, r, layouts.Options{
+		Title:   "API Tokens",
+		Content: hb.NewDiv().Class("p-3").Child(page),
+	}).ToHTML()
+}
+
+// createdTokenCard shows the token just created
+func (controller *apiTokensController) createdTokenCard(token string) hb.TagInterface {
+	return hb.Div().
+		Class("card border-success mb-4").
+		Child(hb.Div().
+			Class("card-body").
+			Child(hb.Heading5().Class("card-title").Text("Your new API token")).
+			Child(hb.Paragraph().Text("Copy the token now. For your security it will not be shown again.")).
+			Child(hb.Input().
+				Type(hb.TYPE_TEXT).
+				Class("form-control font-monospace").
+				Attr("aria-label", "API token").
+				ReadOnly(true).
+				Value(token)).
+			Child(hb.Paragraph().
+				Class("text-muted small mt-2 mb-0").
+				Text("Send it with the API requests in the header: Authorization: Bearer <token>")))
+}
+
+func (controller *apiTokensController) createCard(data apiTokensControllerData) hb.TagInterface {
+	scopes := hb.Div().Class("mb-3").
+		Child(hb.Label().Class("form-label").Text("Scopes"))
+
+	for _, scope := range apitoken.Scopes() {
+		if scope.AdminOnly && !data.authUser.IsAdministrator() && !data.authUser.IsSuperuser() {
+			continue
+		}
+
+		id := "scope_" + strings.ReplaceAll(scope.Key, ":", "_")
+
+		scopes.Child(hb.Div().
+			Class("form-check").
+			Child(hb.Input().
+				Type(hb.TYPE_CHECKBOX).
+				Class("form-check-input").
+				ID(id).
+				Name("scopes").
+				Value(scope.Key).
+				AttrIf(lo.Contains(data.formScopes, scope.Key), "checked", "checked")).
+			Child(hb.Label().
+				Class("form-check-label").
+				For(id).
+				Child(hb.Code().Text(scope.Key)).
+				Child(hb.Span().Text(" " + scope.Description))))
+	}
+
+	expires := hb.Select().
+		Class("form-select").
+		ID("expires").
+		Name("expires")
+
+	for _, option := range apiTokenExpiryOptions {
+		expires.Child(hb.Option().
+			Value(option.value).
+			Text(option.label).
+			Selected(option.value == data.formExpires))
+	}
+
+	form := hb.Form().
+		Method(http.MethodPost).
+		Action(links.User().APITokens()).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret()))).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value("create")).
+		ChildIf(data.formError != "", hb.Div().Class("alert alert-danger").Text(data.formError)).
+		Child(hb.Div().Class("mb-3").
+			Child(hb.Label().Class("form-label").For("name").Text("Name")).
+			Child(hb.Input().
+				Type(hb.TYPE_TEXT).
+				Class("form-control").
+				ID("name").
+				Name("name").
+				Placeholder("e.g. Deploy script").
+				Required(true).
+				Value(data.formName))).
+		Child(scopes).
+		Child(hb.Div().Class("mb-3").
+			Child(hb.Label().Class("form-label").For("expires").Text("Expires")).
+			Child(expires)).
+		Child(hb.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn btn-primary").
+			Text("Create Token"))
+
+	return hb.Div().
+		Class("card mb-4").
+		Child(hb.Div().
+			Class("card-body").
+			Child(hb.Heading5().Class("card-title").Text("Create a token")).
+			Child(hb.Paragraph().Text("Personal API tokens let your scripts and applications call the API as you, with the scopes you select.")).
+			Child(form))
+}
+
+func (controller *apiTokensController) tokensCard(data apiTokensControllerData) hb.TagInterface {
+	body := hb.Div().Class("card-body").
+		Child(hb.Heading5().Class("card-title").Text("Your tokens"))
+
+	if len(data.tokens) == 0 {
+		return hb.Div().
+			Class("card").
+			Child(body.Child(hb.Paragraph().Class("text-muted mb-0").Text("You have no API tokens yet.")))
+	}
+
+	now := time.Now().UTC()
+	rows := hb.TBody()
+
+	for _, token := range data.tokens {
+		status := token.Status(now)
+
+		rows.Child(hb.TR().
+			Child(hb.TD().
+				Child(hb.Div().Text(token.Name)).
+				Child(hb.Small().Class("text-muted font-monospace").Text("…" + token.Hint))).
+			Child(hb.TD().Text(strings.Join(token.Scopes, ", "))).
+			Child(hb.TD().Text(apiTokenTime(token.CreatedAt, "-"))).
+			Child(hb.TD().Text(apiTokenTime(token.ExpiresAt, "Never"))).
+			Child(hb.TD().Text(apiTokenTime(token.LastUsedAt, "Never"))).
+			Child(hb.TD().Child(hb.Span().
+				Class(lo.Ternary(status == apitoken.TOKEN_STATUS_ACTIVE, "badge bg-success", "badge bg-secondary")).
+				Text(status))).
+			Child(hb.TD().
+				Class("text-end").
+				ChildIf(status == apitoken.TOKEN_STATUS_ACTIVE, controller.revokeForm(token))))
+	}
+
+	table := hb.Table().
+		Class("table table-sm align-middle mb-0").
+		Child(hb.Thead().Child(hb.TR().
+			Child(hb.TH().Text("Name")).
+			Child(hb.TH().Text("Scopes")).
+			Child(hb.TH().Text("Created")).
+			Child(hb.TH().Text("Expires")).
+			Child(hb.TH().Text("Last Used")).
+			Child(hb.TH().Text("Status")).
+			Child(hb.TH()))).
+		Child(rows)
+
+	return hb.Div().
+		Class("card").
+		Child(body.Child(hb.Div().Class("table-responsive").Child(table)))
+}
+
+func (controller *apiTokensController) revokeForm(token apitoken.Token) hb.TagInterface {
+	return hb.Form().
+		Method(http.MethodPost).
+		Action(links.User().APITokens()).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret()))).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value("revoke")).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("token_id").Value(token.ID)).
+		Child(hb.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn btn-sm btn-outline-danger").
+			Text("Revoke"))
+}
+
+// apiTokenTime formats the time of a token, or returns the text if the
+// time is not set
+func apiTokenTime(t time.Time, zeroText string) string {
+	if t.IsZero() {
+		return zeroText
+	}
+
+	return carbon.CreateFromStdTime(t).ToDateTimeString(carbon.UTC)
+}
+
+// capitalize upper cases the first letter of the message
+func capitalize(message string) string {
+	if message == "" {
+		return message
+	}
+
+	return strings.ToUpper(message[:1]) + message[1:]
+}
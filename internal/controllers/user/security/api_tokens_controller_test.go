@@ -0,0 +1,208 @@
+package security
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"project/internal/apitoken"
+	"project/internal/app"
+	"project/internal/testutils"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/test"
+)
+
+func setupAPITokensApp(t *testing.T) app.AppInterface {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetCsrfSecret("test-csrf-secret")
+
+	return testutils.Setup(testutils.WithCfg(cfg))
+}
+
+func TestAPITokensController_ShowsPage(t *testing.T) {
+	app := setupAPITokensApp(t)
+
+	body, _, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewAPITokensController(app).Handler, test.NewRequestOptions{}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "Create Token") || !strings.Contains(body, "You have no API tokens yet.") {
+		t.Fatal("expected the create form and no tokens")
+	}
+
+	if !strings.Contains(body, apitoken.SCOPE_PROFILE_READ) {
+		t.Fatal("expected the profile:read scope to be offered")
+	}
+
+	if strings.Contains(body, apitoken.SCOPE_USERS_READ) {
+		t.Fatal("expected the admin only scopes to be hidden from users")
+	}
+}
+
+func TestAPITokensController_Create(t *testing.T) {
+	app := setupAPITokensApp(t)
+
+	body, _, err := testutils.CallStringHandlerAsUser(app, http.MethodPost, NewAPITokensController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":     {"create"},
+			"name":       {"Deploy script"},
+			"scopes":     {apitoken.SCOPE_PROFILE_READ, apitoken.SCOPE_ORDERS_READ},
+			"expires":    {"30"},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "Your new API token") || !strings.Contains(body, apitoken.TOKEN_PREFIX) {
+		t.Fatal("expected the new token to be shown")
+	}
+
+	tokenStore, err := apitoken.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := tokenStore.TokenListByUserID(test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tokens) != 1 {
+		t.Fatalf("expected 1 token, got %d", len(tokens))
+	}
+
+	if tokens[0].Name != "Deploy script" || len(tokens[0].Scopes) != 2 || tokens[0].ExpiresAt.IsZero() {
+		t.Fatalf("unexpected token %+v", tokens[0])
+	}
+
+	// The token is shown once only
+	body, _, err = testutils.CallStringHandlerAsUser(app, http.MethodGet, NewAPITokensController(app).Handler, test.NewRequestOptions{}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(body, "Your new API token") || !strings.Contains(body, "Deploy script") {
+		t.Fatal("expected the token to be listed, but not shown again")
+	}
+}
+
+func TestAPITokensController_CreateAdminScopeRejected(t *testing.T) {
+	app := setupAPITokensApp(t)
+
+	body, _, err := testutils.CallStringHandlerAsUser(app, http.MethodPost, NewAPITokensController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":     {"create"},
+			"name":       {"Users"},
+			"scopes":     {apitoken.SCOPE_USERS_READ},
+			"expires":    {"30"},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "can only be granted by administrators") {
+		t.Fatal("expected the admin only scope to be rejected")
+	}
+}
+
+func TestAPITokensController_CreateRequiresCsrf(t *testing.T) {
+	app := setupAPITokensApp(t)
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodPost, NewAPITokensController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":  {"create"},
+			"name":    {"Deploy script"},
+			"scopes":  {apitoken.SCOPE_PROFILE_READ},
+			"expires": {"30"},
+		},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" {
+		t.Fatalf("expected an error without the csrf token, got %v", flashMessage)
+	}
+}
+
+func TestAPITokensController_Revoke(t *testing.T) {
+	app := setupAPITokensApp(t)
+
+	tokenStore, err := apitoken.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	own := &apitoken.Token{UserID: test.USER_01, Name: "Own", Hash: apitoken.Hash("own")}
+	other := &apitoken.Token{UserID: test.USER_02, Name: "Other", Hash: apitoken.Hash("other")}
+
+	for _, token := range []*apitoken.Token{own, other} {
+		if err := tokenStore.TokenCreate(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	revoke := func(tokenID string) string {
+		_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodPost, NewAPITokensController(app).Handler, test.NewRequestOptions{
+			FormValues: url.Values{
+				"action":     {"revoke"},
+				"token_id":   {tokenID},
+				"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+			},
+		}, test.USER_01)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+		if err != nil || flashMessage == nil {
+			t.Fatalf("expected a flash message, got %v", err)
+		}
+
+		return flashMessage.Type
+	}
+
+	if messageType := revoke(other.ID); messageType != "error" {
+		t.Fatalf("expected the token of another user not to be revoked, got %s", messageType)
+	}
+
+	if messageType := revoke(own.ID); messageType != "success" {
+		t.Fatalf("expected the token to be revoked, got %s", messageType)
+	}
+
+	revoked, err := tokenStore.TokenFindByID(own.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked == nil || !revoked.IsRevoked() {
+		t.Fatal("expected the token to be revoked")
+	}
+
+	notRevoked, err := tokenStore.TokenFindByID(other.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if notRevoked == nil || notRevoked.IsRevoked() {
+		t.Fatal("expected the token of the other user to remain active")
+	}
+}
@@ -0,0 +1,198 @@
+package security
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/controllers/auth/oauthlogin"
+	"project/internal/controllers/user/partials"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"project/internal/oauth"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dracory/userstore"
+	"github.com/samber/lo"
+)
+
+// == CONTROLLER ==============================================================
+
+// connectedAccountsController lists the social login providers, and lets
+// the user link them to the account, or unlink them.
+//
+// Linking is started here, and completed by the social login controllers
+// (see the oauthlogin package), after the user logs in at the provider.
+type connectedAccountsController struct {
+	app app.AppInterface
+}
+
+type connectedAccountsControllerData struct {
+	authUser  userstore.UserInterface
+	linkStore oauth.StoreInterface
+	providers []oauth.ProviderInterface
+	links     []oauth.Link
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewConnectedAccountsController creates a new connected accounts controller
+func NewConnectedAccountsController(app app.AppInterface) *connectedAccountsController {
+	return &connectedAccountsController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *connectedAccountsController) Handler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.User().Profile(), 10)
+	}
+
+	if r.Method == http.MethodPost {
+		return controller.postUnlink(w, r, data)
+	}
+
+	return controller.page(r, data)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *connectedAccountsController) prepareData(r *http.Request) (data connectedAccountsControllerData, errorMessage string) {
+	if !oauthlogin.Enabled(controller.app) {
+		return data, "Sorry, connected accounts are currently unavailable."
+	}
+
+	data.authUser = helpers.GetAuthUser(r)
+
+	if data.authUser == nil {
+		return data, "Please log in to manage your connected accounts."
+	}
+
+	linkStore, err := oauth.NewStore(controller.app.GetCustomStore())
+
+	if err != nil {
+		controller.app.GetLogger().Error("At connectedAccountsController > prepareData > NewStore", slog.String("error", err.Error()))
+		return data, "Sorry, connected accounts are currently unavailable."
+	}
+
+	data.linkStore = linkStore
+	data.providers = oauth.ProvidersFromConfig(controller.app.GetConfig())
+	data.links, err = linkStore.LinkListByUserID(data.authUser.GetID())
+
+	if err != nil {
+		controller.app.GetLogger().Error("At connectedAccountsController > prepareData > LinkListByUserID", slog.String("error", err.Error()))
+		return data, "Sorry, your connected accounts could not be loaded. Please try again later."
+	}
+
+	return data, ""
+}
+
+// postUnlink removes the links of the user to the provider
+func (controller *connectedAccountsController) postUnlink(w http.ResponseWriter, r *http.Request, data connectedAccountsControllerData) string {
+	connectedAccountsURL := links.User().ConnectedAccounts()
+
+	if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", connectedAccountsURL, 10)
+	}
+
+	if req.GetStringTrimmed(r, "action") != "unlink" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Invalid action", connectedAccountsURL, 10)
+	}
+
+	providerKey := req.GetStringTrimmed(r, "provider")
+
+	// Only the links of the user are listed, so another user's link
+	// cannot be removed
+	providerLinks := lo.Filter(data.links, func(link oauth.Link, _ int) bool {
+		return link.Provider == providerKey
+	})
+
+	if len(providerLinks) == 0 {
+		return helpers.ToFlashInfo(controller.app.GetCacheStore(), w, r, "This account is not linked.", connectedAccountsURL, 10)
+	}
+
+	for _, link := range providerLinks {
+		if err := data.linkStore.LinkDelete(link); err != nil {
+			controller.app.GetLogger().Error("At connectedAccountsController > postUnlink > LinkDelete", slog.String("error", err.Error()))
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Sorry, the account could not be unlinked. Please try again later.", connectedAccountsURL, 10)
+		}
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "The account has been unlinked.", connectedAccountsURL, 10)
+}
+
+func (controller *connectedAccountsController) page(r *http.Request, data connectedAccountsControllerData) string {
+	pageHeader := partials.PageHeader("bi-link-45deg", "Connected Accounts", []layouts.Breadcrumb{
+		{Name: "Dashboard", Icon: "bi-speedometer2", URL: links.User().Home()},
+		{Name: "My Account", URL: links.User().Profile()},
+		{Name: "Connected Accounts", URL: links.User().ConnectedAccounts()},
+	})
+
+	list := hb.UL().Class("list-group list-group-flush")
+
+	for _, provider := range data.providers {
+		linked := lo.ContainsBy(data.links, func(link oauth.Link) bool {
+			return link.Provider == provider.Key()
+		})
+
+		list.Child(hb.LI().
+			Class("list-group-item d-flex align-items-center justify-content-between").
+			Child(hb.Div().
+				Child(hb.I().Class("bi " + oauthlogin.ProviderIcon(provider.Key()) + " me-2")).
+				Child(hb.Strong().Text(provider.Name())).
+				Child(hb.Span().
+					Class(lo.Ternary(linked, "badge bg-success ms-2", "badge bg-secondary ms-2")).
+					Text(lo.Ternary(linked, "Linked", "Not linked")))).
+			Child(lo.Ternary(linked, controller.unlinkForm(provider), controller.linkForm(provider))))
+	}
+
+	card := hb.Div().
+		Class("card").
+		Child(hb.Div().
+			Class("card-body").
+			Child(hb.Paragraph().Text("Link your accounts with these providers to log in with them. You can still log in with your email at any time.")).
+			Child(list))
+
+	page := hb.Section().
+		Child(hb.Div().
+			Class("container").
+			Child(pageHeader)).
+		Child(hb.Div().
+			Class("container").
+			Child(card))
+
+	return layouts.NewUserLayout(controller.app, r, layouts.Options{
+		Title:   "Connected Accounts",
+		Content: hb.NewDiv().Class("p-3").Child(page),
+	}).ToHTML()
+}
+
+// linkForm sends the user to log in at the provider, to link it
+func (controller *connectedAccountsController) linkForm(provider oauth.ProviderInterface) hb.TagInterface {
+	return hb.Form().
+		Method(http.MethodPost).
+		Action(links.Auth().OAuth(provider.Key(), "")).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret()))).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value(oauthlogin.ACTION_LINK)).
+		Child(hb.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn btn-sm btn-outline-primary").
+			Text("Link"))
+}
+
+func (controller *connectedAccountsController) unlinkForm(provider oauth.ProviderInterface) hb.TagInterface {
+	return hb.Form().
+		Method(http.MethodPost).
+		Action(links.User().ConnectedAccounts()).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret()))).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value("unlink")).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("provider").Value(provider.Key())).
+		Child(hb.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn btn-sm btn-outline-danger").
+			Text("Unlink"))
+}
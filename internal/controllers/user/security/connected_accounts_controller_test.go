@@ -0,0 +1,149 @@
+package security
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/links"
+	"project/internal/oauth"
+	"project/internal/testutils"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/test"
+)
+
+func setupConnectedAccountsApp(t *testing.T) app.AppInterface {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetCsrfSecret("test-csrf-secret")
+	cfg.SetOAuthGitHubClientID("github-id")
+	cfg.SetOAuthGitHubClientSecret("github-secret")
+
+	return testutils.Setup(testutils.WithCfg(cfg))
+}
+
+func TestConnectedAccountsController_RequiresProvider(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewConnectedAccountsController(app).Handler, test.NewRequestOptions{}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" {
+		t.Fatalf("expected an error without providers, got %v", flashMessage)
+	}
+}
+
+func TestConnectedAccountsController_ShowsProviders(t *testing.T) {
+	app := setupConnectedAccountsApp(t)
+
+	body, _, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewConnectedAccountsController(app).Handler, test.NewRequestOptions{}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "GitHub") || !strings.Contains(body, "Not linked") {
+		t.Fatal("expected GitHub to be listed as not linked")
+	}
+
+	if !strings.Contains(body, `action="`+links.Auth().OAuth(oauth.PROVIDER_GITHUB, "")) {
+		t.Fatal("expected the form to link GitHub")
+	}
+}
+
+func TestConnectedAccountsController_Unlink(t *testing.T) {
+	app := setupConnectedAccountsApp(t)
+
+	linkStore, err := oauth.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, link := range []*oauth.Link{
+		{Provider: oauth.PROVIDER_GITHUB, Subject: "42", UserID: test.USER_01},
+		{Provider: oauth.PROVIDER_GITHUB, Subject: "43", UserID: test.USER_02},
+	} {
+		if err := linkStore.LinkCreate(link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	body, _, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewConnectedAccountsController(app).Handler, test.NewRequestOptions{}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "Unlink") {
+		t.Fatal("expected the unlink button")
+	}
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodPost, NewConnectedAccountsController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":     {"unlink"},
+			"provider":   {oauth.PROVIDER_GITHUB},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected the account to be unlinked, got %v", flashMessage)
+	}
+
+	if link, _ := linkStore.LinkFind(oauth.PROVIDER_GITHUB, "42"); link != nil {
+		t.Fatal("expected the link of the user to be removed")
+	}
+
+	if link, _ := linkStore.LinkFind(oauth.PROVIDER_GITHUB, "43"); link == nil {
+		t.Fatal("expected the link of the other user to remain")
+	}
+}
+
+func TestConnectedAccountsController_UnlinkRequiresCsrf(t *testing.T) {
+	app := setupConnectedAccountsApp(t)
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodPost, NewConnectedAccountsController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"action":   {"unlink"},
+			"provider": {oauth.PROVIDER_GITHUB},
+		},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Message != "Your session has expired. Please try again." {
+		t.Fatalf("expected the session expired error, got %v", flashMessage)
+	}
+}
@@ -0,0 +1,285 @@
+package security
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/controllers/user/partials"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"strconv"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dracory/sessionstore"
+	"github.com/dracory/userstore"
+	"github.com/dromara/carbon/v2"
+)
+
+// Actions supported by the sessions controller
+const (
+	ACTION_SESSION_REVOKE        = "revoke"
+	ACTION_SESSION_REVOKE_OTHERS = "revoke_others"
+)
+
+// == CONTROLLER ==============================================================
+
+// sessionsController shows the user the devices they are signed in on,
+// and lets them sign out of a session, or of all the sessions but the
+// current one (e.g. after using a shared computer).
+type sessionsController struct {
+	app app.AppInterface
+}
+
+type sessionsControllerData struct {
+	authUser       userstore.UserInterface
+	currentSession sessionstore.SessionInterface
+	sessions       []sessionstore.SessionInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewSessionsController creates a new sessions controller
+func NewSessionsController(app app.AppInterface) *sessionsController {
+	return &sessionsController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *sessionsController) Handler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.User().Home(), 10)
+	}
+
+	if r.Method == http.MethodPost {
+		if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", links.User().Sessions(), 10)
+		}
+
+		switch req.GetStringTrimmed(r, "action") {
+		case ACTION_SESSION_REVOKE:
+			return controller.postRevoke(w, r, data)
+		case ACTION_SESSION_REVOKE_OTHERS:
+			return controller.postRevokeOthers(w, r, data)
+		default:
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Invalid action", links.User().Sessions(), 10)
+		}
+	}
+
+	return controller.page(r, data)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *sessionsController) prepareData(r *http.Request) (data sessionsControllerData, errorMessage string) {
+	data.authUser = helpers.GetAuthUser(r)
+
+	if data.authUser == nil {
+		return data, "Please log in to manage your sessions."
+	}
+
+	data.currentSession = helpers.GetAuthSession(r)
+
+	sessions, err := ext.UserSessionList(r.Context(), controller.app, data.authUser.GetID())
+
+	if err != nil {
+		controller.app.GetLogger().Error("At sessionsController > prepareData > UserSessionList", slog.String("error", err.Error()))
+		return data, "Sorry, your sessions could not be loaded. Please try again later."
+	}
+
+	data.sessions = sessions
+
+	return data, ""
+}
+
+// postRevoke signs the user out of one of the other sessions
+func (controller *sessionsController) postRevoke(w http.ResponseWriter, r *http.Request, data sessionsControllerData) string {
+	sessionsURL := links.User().Sessions()
+	sessionID := req.GetStringTrimmed(r, "session_id")
+
+	if data.currentSession != nil && data.currentSession.GetID() == sessionID {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "To sign out of this device, please log out.", sessionsURL, 10)
+	}
+
+	err := ext.UserSessionRevoke(r.Context(), controller.app, data.authUser.GetID(), sessionID)
+
+	if errors.Is(err, ext.ErrUserSessionNotFound) {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Session not found.", sessionsURL, 10)
+	}
+
+	if err != nil {
+		controller.app.GetLogger().Error("At sessionsController > postRevoke > UserSessionRevoke", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Sorry, the session could not be signed out. Please try again later.", sessionsURL, 10)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "The session has been signed out.", sessionsURL, 10)
+}
+
+// postRevokeOthers signs the user out everywhere but on this device
+func (controller *sessionsController) postRevokeOthers(w http.ResponseWriter, r *http.Request, data sessionsControllerData) string {
+	sessionsURL := links.User().Sessions()
+
+	currentSessionID := ""
+	if data.currentSession != nil {
+		currentSessionID = data.currentSession.GetID()
+	}
+
+	revoked, err := ext.UserSessionRevokeOthers(r.Context(), controller.app, data.authUser.GetID(), currentSessionID)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At sessionsController > postRevokeOthers > UserSessionRevokeOthers", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Sorry, the sessions could not be signed out. Please try again later.", sessionsURL, 10)
+	}
+
+	message := "You have been signed out of " + strconv.Itoa(revoked) + " other sessions."
+	if revoked == 1 {
+		message = "You have been signed out of 1 other session."
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, message, sessionsURL, 10)
+}
+
+func (controller *sessionsController) page(r *http.Request, data sessionsControllerData) string {
+	pageHeader := partials.PageHeader("bi-laptop", "Devices & Sessions", []layouts.Breadcrumb{
+		{Name: "Dashboard", Icon: "bi-speedometer2", URL: links.User().Home()},
+		{Name: "Devices & Sessions", URL: links.User().Sessions()},
+	})
+
+	page := hb.Section().
+		Child(hb.Div().
+			Class("container").
+			Child(pageHeader)).
+		Child(hb.Div().
+			Class("container").
+			Child(controller.sessionsCard(r, data)))
+
+	return layouts.NewUserLayout(controller.app, r, layouts.Options{
+		Title:   "Devices & Sessions",
+		Content: hb.NewDiv().Class("p-3").Child(page),
+	}).ToHTML()
+}
+
+func (controller *sessionsController) sessionsCard(r *http.Request, data sessionsControllerData) hb.TagInterface {
+	csrfToken := csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret())
+
+	body := hb.Div().Class("card-body").
+		Child(hb.Heading5().Class("card-title").Text("Where you are signed in")).
+		Child(hb.Paragraph().Text("If you do not recognize a device, sign it out and change your password."))
+
+	rows := hb.TBody()
+	others := 0
+
+	// The countries are looked up together, not one request per row
+	countryShown := controller.app.GetConfig().GetSessionCountryLookupEnabled()
+	countries := map[string]string{}
+
+	if countryShown {
+		ips := []string{}
+		for _, session := range data.sessions {
+			ips = append(ips, session.GetIPAddress())
+		}
+
+		countries = ext.UserSessionCountries(r.Context(), controller.app, ips)
+	}
+
+	for _, session := range data.sessions {
+		isCurrent := data.currentSession != nil && data.currentSession.GetID() == session.GetID()
+
+		if !isCurrent {
+			others++
+		}
+
+		userAgent := helpers.UserAgentParse(session.GetUserAgent())
+
+		device := hb.Div().
+			Child(hb.I().Class("bi "+sessionDeviceIcon(userAgent.DeviceType)+" me-2")).
+			Child(hb.Span().Text(userAgent.Summary())).
+			ChildIf(isCurrent, hb.Span().Class("badge bg-success ms-2").Text("This device"))
+
+		rows.Child(hb.TR().
+			Child(hb.TD().Child(device)).
+			Child(hb.TD().Class("font-monospace").Text(session.GetIPAddress())).
+			ChildIf(countryShown, hb.TD().Text(sessionCountryName(countries[session.GetIPAddress()]))).
+			Child(hb.TD().Text(sessionTime(session.GetCreatedAtCarbon()))).
+			Child(hb.TD().Text(sessionTime(session.GetUpdatedAtCarbon()))).
+			Child(hb.TD().
+				Class("text-end").
+				ChildIf(!isCurrent, controller.revokeForm(csrfToken, session))))
+	}
+
+	table := hb.Table().
+		Class("table table-sm align-middle mb-0").
+		Child(hb.Thead().Child(hb.TR().
+			Child(hb.TH().Text("Device")).
+			Child(hb.TH().Text("IP Address")).
+			ChildIf(countryShown, hb.TH().Text("Country")).
+			Child(hb.TH().Text("Signed In")).
+			Child(hb.TH().Text("Last Activity")).
+			Child(hb.TH()))).
+		Child(rows)
+
+	formRevokeOthers := hb.Form().
+		Method(http.MethodPost).
+		Action(links.User().Sessions()).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrfToken)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value(ACTION_SESSION_REVOKE_OTHERS)).
+		Child(hb.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn btn-outline-danger mt-3").
+			Text("Sign Out Everywhere Else"))
+
+	return hb.Div().
+		Class("card").
+		Child(body.
+			Child(hb.Div().Class("table-responsive").Child(table)).
+			ChildIf(others > 0, formRevokeOthers))
+}
+
+func (controller *sessionsController) revokeForm(csrfToken string, session sessionstore.SessionInterface) hb.TagInterface {
+	return hb.Form().
+		Method(http.MethodPost).
+		Action(links.User().Sessions()).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrfToken)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value(ACTION_SESSION_REVOKE)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("session_id").Value(session.GetID())).
+		Child(hb.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn btn-sm btn-outline-danger").
+			Text("Sign Out"))
+}
+
+// sessionCountryName returns the country looked up, or "Unknown"
+func sessionCountryName(country string) string {
+	if country == "" || country == helpers.COUNTRY_UNKNOWN {
+		return "Unknown"
+	}
+
+	return country
+}
+
+// sessionDeviceIcon returns the Bootstrap icon of the device type
+func sessionDeviceIcon(deviceType string) string {
+	switch deviceType {
+	case helpers.DEVICE_TYPE_MOBILE:
+		return "bi-phone"
+	case helpers.DEVICE_TYPE_TABLET:
+		return "bi-tablet"
+	}
+
+	return "bi-laptop"
+}
+
+// sessionTime formats the time of a session, or returns "-" if not set
+func sessionTime(t *carbon.Carbon) string {
+	if t.IsInvalid() || t.IsZero() {
+		return "-"
+	}
+
+	return t.ToDateTimeString(carbon.UTC)
+}
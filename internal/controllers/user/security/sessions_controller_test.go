@@ -0,0 +1,216 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/testutils"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/sessionstore"
+	"github.com/dracory/test"
+	"github.com/dromara/carbon/v2"
+)
+
+const sessionsTestUserAgent = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1"
+
+// setupSessionsApp returns an app with the user signed in on this device,
+// and on a phone
+func setupSessionsApp(t *testing.T) (app.AppInterface, map[any]any, sessionstore.SessionInterface) {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetCsrfSecret("test-csrf-secret")
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	user, current, err := testutils.SeedUserAndSession(app.GetUserStore(), app.GetSessionStore(), test.USER_01, httptest.NewRequest(http.MethodGet, "/", nil), 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := sessionstore.NewSession().
+		SetUserID(user.GetID()).
+		SetUserAgent(sessionsTestUserAgent).
+		SetIPAddress("10.0.0.9").
+		SetExpiresAt(carbon.Now(carbon.UTC).AddHours(1).ToDateTimeString(carbon.UTC))
+
+	if err := app.GetSessionStore().SessionCreate(context.Background(), other); err != nil {
+		t.Fatal(err)
+	}
+
+	requestContext := map[any]any{
+		config.AuthenticatedUserContextKey{}:    user,
+		config.AuthenticatedSessionContextKey{}: current,
+	}
+
+	return app, requestContext, other
+}
+
+func TestSessionsController_ShowsSessions(t *testing.T) {
+	app, requestContext, _ := setupSessionsApp(t)
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewSessionsController(app).Handler, test.NewRequestOptions{
+		Context: requestContext,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Devices &amp; Sessions", "This device", "Safari", "iOS", "10.0.0.9", "Sign Out Everywhere Else"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected the page to contain %q", expected)
+		}
+	}
+
+	// Only the other session can be signed out from the list
+	if count := strings.Count(body, `value="`+ACTION_SESSION_REVOKE+`"`); count != 1 {
+		t.Errorf("expected 1 sign out button, got %d", count)
+	}
+}
+
+func TestSessionsController_Revoke(t *testing.T) {
+	app, requestContext, other := setupSessionsApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewSessionsController(app).Handler, test.NewRequestOptions{
+		Context: requestContext,
+		FormValues: url.Values{
+			"action":     {ACTION_SESSION_REVOKE},
+			"session_id": {other.GetID()},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected a success flash message, got %+v", flashMessage)
+	}
+
+	revoked, err := app.GetSessionStore().SessionFindByID(context.Background(), other.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked != nil {
+		t.Fatal("expected the session to be signed out")
+	}
+}
+
+func TestSessionsController_RevokeCurrentRefused(t *testing.T) {
+	app, requestContext, _ := setupSessionsApp(t)
+	current := requestContext[config.AuthenticatedSessionContextKey{}].(sessionstore.SessionInterface)
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewSessionsController(app).Handler, test.NewRequestOptions{
+		Context: requestContext,
+		FormValues: url.Values{
+			"action":     {ACTION_SESSION_REVOKE},
+			"session_id": {current.GetID()},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" {
+		t.Fatalf("expected an error flash message, got %+v", flashMessage)
+	}
+
+	stillActive, err := app.GetSessionStore().SessionFindByID(context.Background(), current.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stillActive == nil {
+		t.Fatal("expected the current session to stay signed in")
+	}
+}
+
+func TestSessionsController_RevokeOthers(t *testing.T) {
+	app, requestContext, other := setupSessionsApp(t)
+	current := requestContext[config.AuthenticatedSessionContextKey{}].(sessionstore.SessionInterface)
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewSessionsController(app).Handler, test.NewRequestOptions{
+		Context: requestContext,
+		FormValues: url.Values{
+			"action":     {ACTION_SESSION_REVOKE_OTHERS},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || !strings.Contains(flashMessage.Message, "1 other session") {
+		t.Fatalf("expected the sign out of 1 other session, got %+v", flashMessage)
+	}
+
+	revoked, err := app.GetSessionStore().SessionFindByID(context.Background(), other.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked != nil {
+		t.Fatal("expected the other session to be signed out")
+	}
+
+	stillActive, err := app.GetSessionStore().SessionFindByID(context.Background(), current.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stillActive == nil {
+		t.Fatal("expected the current session to stay signed in")
+	}
+}
+
+func TestSessionsController_InvalidCsrf(t *testing.T) {
+	app, requestContext, other := setupSessionsApp(t)
+
+	_, _, err := test.CallStringEndpoint(http.MethodPost, NewSessionsController(app).Handler, test.NewRequestOptions{
+		Context: requestContext,
+		FormValues: url.Values{
+			"action":     {ACTION_SESSION_REVOKE},
+			"session_id": {other.GetID()},
+			"csrf_token": {"invalid"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stillActive, err := app.GetSessionStore().SessionFindByID(context.Background(), other.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stillActive == nil {
+		t.Fatal("expected the session to stay signed in without a valid csrf token")
+	}
+}
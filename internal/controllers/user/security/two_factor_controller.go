@@ -0,0 +1,370 @@
+package security
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image/png"
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/controllers/user/partials"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"strconv"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dracory/userstore"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/samber/lo"
+)
+
+// TWO_FACTOR_ENROL_CACHE_KEY_PREFIX prefixes the cache keys holding the
+// TOTP key (otpauth URL) shown to the user, until the user confirms it,
+// by user ID
+const TWO_FACTOR_ENROL_CACHE_KEY_PREFIX = "two_factor_enrol:"
+
+// twoFactorEnrolExpiresSeconds is the time the user has to scan the QR
+// code and confirm it
+const twoFactorEnrolExpiresSeconds = 15 * 60
+
+const msgTwoFactorCodeInvalid = "The code is invalid. Please try again."
+
+// == CONTROLLER ==============================================================
+
+// twoFactorController lets the user enable two-factor authentication with
+// an authenticator app (TOTP), and disable it, or get new recovery codes.
+//
+// The secret is only saved once the user confirms a code generated with
+// it, until then it is kept in the cache store.
+type twoFactorController struct {
+	app app.AppInterface
+}
+
+type twoFactorControllerData struct {
+	authUser userstore.UserInterface
+	enabled  bool
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewTwoFactorController creates a new two-factor authentication settings
+// controller
+func NewTwoFactorController(app app.AppInterface) *twoFactorController {
+	return &twoFactorController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *twoFactorController) Handler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.User().Home(), 10)
+	}
+
+	if r.Method == http.MethodPost {
+		return controller.post(w, r, data)
+	}
+
+	if data.enabled {
+		return controller.page(r, controller.enabledCard(data))
+	}
+
+	key, err := controller.enrolKey(r, data)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At twoFactorController > Handler > enrolKey", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Sorry, two-factor authentication could not be set up. Please try again later.", links.User().Home(), 10)
+	}
+
+	return controller.page(r, controller.enrolCard(key))
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *twoFactorController) prepareData(r *http.Request) (data twoFactorControllerData, errorMessage string) {
+	if controller.app.GetVaultStore() == nil || controller.app.GetCacheStore() == nil {
+		return data, "Sorry, two-factor authentication is currently unavailable."
+	}
+
+	data.authUser = helpers.GetAuthUser(r)
+
+	if data.authUser == nil {
+		return data, "Please log in to manage two-factor authentication."
+	}
+
+	data.enabled = ext.UserTwoFactorEnabled(data.authUser)
+
+	return data, ""
+}
+
+func (controller *twoFactorController) post(w http.ResponseWriter, r *http.Request, data twoFactorControllerData) string {
+	twoFactorURL := links.User().TwoFactor()
+
+	if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", twoFactorURL, 10)
+	}
+
+	code := req.GetStringTrimmed(r, "code")
+
+	switch req.GetStringTrimmed(r, "action") {
+	case "enable":
+		if data.enabled {
+			return helpers.ToFlashInfo(controller.app.GetCacheStore(), w, r, "Two-factor authentication is already enabled.", twoFactorURL, 10)
+		}
+
+		return controller.postEnable(w, r, data, code)
+	case "disable", "recovery-codes":
+		if !data.enabled {
+			return helpers.ToFlashInfo(controller.app.GetCacheStore(), w, r, "Two-factor authentication is not enabled.", twoFactorURL, 10)
+		}
+
+		valid, err := ext.UserTwoFactorVerify(r.Context(), controller.app, data.authUser, code)
+
+		if err != nil {
+			controller.app.GetLogger().Error("At twoFactorController > post > UserTwoFactorVerify", slog.String("error", err.Error()))
+		}
+
+		if !valid {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgTwoFactorCodeInvalid, twoFactorURL, 10)
+		}
+
+		if req.GetStringTrimmed(r, "action") == "disable" {
+			return controller.postDisable(w, r, data)
+		}
+
+		return controller.postRecoveryCodes(w, r, data)
+	}
+
+	return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Invalid action", twoFactorURL, 10)
+}
+
+func (controller *twoFactorController) postEnable(w http.ResponseWriter, r *http.Request, data twoFactorControllerData, code string) string {
+	twoFactorURL := links.User().TwoFactor()
+	cacheKey := TWO_FACTOR_ENROL_CACHE_KEY_PREFIX + data.authUser.GetID()
+
+	keyURL, err := controller.app.GetCacheStore().Get(cacheKey, "")
+
+	if err != nil {
+		controller.app.GetLogger().Error("At twoFactorController > postEnable > Get", slog.String("error", err.Error()))
+	}
+
+	key, err := otp.NewKeyFromURL(keyURL)
+
+	if keyURL == "" || err != nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "The setup has expired. Please scan the new QR code.", twoFactorURL, 10)
+	}
+
+	secret := key.Secret()
+
+	if !totp.Validate(code, secret) {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, msgTwoFactorCodeInvalid, twoFactorURL, 10)
+	}
+
+	recoveryCodes, err := ext.UserTwoFactorEnable(r.Context(), controller.app, data.authUser, secret)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At twoFactorController > postEnable > UserTwoFactorEnable", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Sorry, two-factor authentication could not be enabled. Please try again later.", twoFactorURL, 10)
+	}
+
+	if err := controller.app.GetCacheStore().Remove(cacheKey); err != nil {
+		controller.app.GetLogger().Warn("At twoFactorController > postEnable > Remove", slog.String("error", err.Error()))
+	}
+
+	return controller.page(r, controller.recoveryCodesCard("Two-factor authentication is now enabled.", recoveryCodes))
+}
+
+func (controller *twoFactorController) postDisable(w http.ResponseWriter, r *http.Request, data twoFactorControllerData) string {
+	twoFactorURL := links.User().TwoFactor()
+
+	if err := ext.UserTwoFactorDisable(r.Context(), controller.app, data.authUser); err != nil {
+		controller.app.GetLogger().Error("At twoFactorController > postDisable > UserTwoFactorDisable", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Sorry, two-factor authentication could not be disabled. Please try again later.", twoFactorURL, 10)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "Two-factor authentication has been disabled.", twoFactorURL, 10)
+}
+
+func (controller *twoFactorController) postRecoveryCodes(w http.ResponseWriter, r *http.Request, data twoFactorControllerData) string {
+	recoveryCodes, err := ext.UserTwoFactorRecoveryCodesRegenerate(r.Context(), controller.app, data.authUser)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At twoFactorController > postRecoveryCodes > UserTwoFactorRecoveryCodesRegenerate", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Sorry, new recovery codes could not be created. Please try again later.", links.User().TwoFactor(), 10)
+	}
+
+	return controller.page(r, controller.recoveryCodesCard("Your old recovery codes no longer work.", recoveryCodes))
+}
+
+// enrolKey returns the key being set up for the user, or a new one, so
+// reloading the page keeps the QR code the user may have scanned
+func (controller *twoFactorController) enrolKey(r *http.Request, data twoFactorControllerData) (*otp.Key, error) {
+	cacheKey := TWO_FACTOR_ENROL_CACHE_KEY_PREFIX + data.authUser.GetID()
+
+	keyURL, err := controller.app.GetCacheStore().Get(cacheKey, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if keyURL != "" {
+		return otp.NewKeyFromURL(keyURL)
+	}
+
+	email, _, _, _, _, err := ext.UserUntokenizeTransparently(r.Context(), controller.app, data.authUser)
+
+	if err != nil {
+		return nil, err
+	}
+
+	appName := controller.app.GetConfig().GetAppName()
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      lo.Ternary(appName != "", appName, "Account"),
+		AccountName: lo.Ternary(email != "", email, data.authUser.GetID()),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := controller.app.GetCacheStore().Set(cacheKey, key.URL(), twoFactorEnrolExpiresSeconds); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// qrCodeDataURI returns the QR code of the key as a PNG data URI, so the
+// secret is never sent to a third party to draw the QR code
+func (controller *twoFactorController) qrCodeDataURI(key *otp.Key) (string, error) {
+	image, err := key.Image(200, 200)
+
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, image); err != nil {
+		return "", err
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func (controller *twoFactorController) page(r *http.Request, content hb.TagInterface) string {
+	pageHeader := partials.PageHeader("bi-shield-lock", "Two-Factor Authentication", []layouts.Breadcrumb{
+		{Name: "Dashboard", Icon: "bi-speedometer2", URL: links.User().Home()},
+		{Name: "Two-Factor Authentication", URL: links.User().TwoFactor()},
+	})
+
+	page := hb.Section().
+		Child(hb.Div().
+			Class("container").
+			Child(pageHeader)).
+		Child(hb.Div().
+			Class("container").
+			Child(content))
+
+	return layouts.NewUserLayout(controller.app, r, layouts.Options{
+		Title:   "Two-Factor Authentication",
+		Content: hb.NewDiv().Class("p-3").Child(page),
+	}).ToHTML()
+}
+
+func (controller *twoFactorController) enrolCard(key *otp.Key) hb.TagInterface {
+	qrCode, err := controller.qrCodeDataURI(key)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At twoFactorController > enrolCard > qrCodeDataURI", slog.String("error", err.Error()))
+	}
+
+	return hb.Div().
+		Class("card").
+		Child(hb.Div().
+			Class("card-body").
+			Child(hb.H4().Text("Set up two-factor authentication")).
+			Child(hb.Paragraph().Text("Scan the QR code with your authenticator app (e.g. Google Authenticator, Authy, 1Password), then enter the 6-digit code it shows to finish.")).
+			ChildIf(qrCode != "", hb.Image(qrCode).
+				Class("img-thumbnail mb-3").
+				Attr("width", "200").
+				Attr("height", "200").
+				Alt("QR code")).
+			Child(hb.Paragraph().
+				Class("small text-muted").
+				Text("Cannot scan the code? Enter this key in your app instead: ").
+				Child(hb.Code().Text(key.Secret()))).
+			Child(controller.codeForm("enable", "Enable two-factor authentication", "btn-primary")))
+}
+
+func (controller *twoFactorController) enabledCard(data twoFactorControllerData) hb.TagInterface {
+	remaining := ext.UserTwoFactorRecoveryCodesRemaining(data.authUser)
+
+	return hb.Div().
+		Class("card").
+		Child(hb.Div().
+			Class("card-body").
+			Child(hb.H4().
+				Child(hb.I().Class("bi bi-shield-check text-success me-2")).
+				Text("Two-factor authentication is enabled")).
+			Child(hb.Paragraph().Text("When you log in, you are asked for the code of your authenticator app.")).
+			Child(hb.Paragraph().
+				Class(lo.Ternary(remaining < 3, "text-danger", "text-muted")).
+				Text("You have " + strconv.Itoa(remaining) + " unused recovery codes.")).
+			Child(hb.H5().Class("mt-4").Text("New recovery codes")).
+			Child(controller.codeForm("recovery-codes", "Create new recovery codes", "btn-outline-primary")).
+			Child(hb.H5().Class("mt-4").Text("Disable two-factor authentication")).
+			Child(controller.codeForm("disable", "Disable two-factor authentication", "btn-outline-danger")))
+}
+
+func (controller *twoFactorController) recoveryCodesCard(message string, recoveryCodes []string) hb.TagInterface {
+	items := lo.Map(recoveryCodes, func(code string, _ int) hb.TagInterface {
+		return hb.LI().Child(hb.Code().Text(code))
+	})
+
+	return hb.Div().
+		Class("card").
+		Child(hb.Div().
+			Class("card-body").
+			Child(hb.Div().Class("alert alert-success").Text(message)).
+			Child(hb.H4().Text("Your recovery codes")).
+			Child(hb.Paragraph().Text("Keep these codes somewhere safe. If you lose your device, you can log in with one of them instead of a code from your app. Each code works only once, and they will not be shown again.")).
+			Child(hb.UL().Class("list-unstyled fs-5").Children(items)).
+			Child(hb.Hyperlink().
+				Class("btn btn-primary").
+				Href(links.User().TwoFactor()).
+				Text("I have saved my recovery codes")))
+}
+
+// codeForm is a form asking for a code of the authenticator app, to
+// confirm the action
+func (controller *twoFactorController) codeForm(action string, buttonText string, buttonClass string) hb.TagInterface {
+	return hb.Form().
+		Method(http.MethodPost).
+		Action(links.User().TwoFactor()).
+		Class("row g-2 align-items-center").
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret()))).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value(action)).
+		Child(hb.Div().
+			Class("col-auto").
+			Child(hb.Input().
+				Type(hb.TYPE_TEXT).
+				Class("form-control").
+				Name("code").
+				Placeholder("Code").
+				Attr("autocomplete", "one-time-code").
+				Attr("required", "required"))).
+		Child(hb.Div().
+			Class("col-auto").
+			Child(hb.Button().
+				Type(hb.TYPE_SUBMIT).
+				Class("btn " + buttonClass).
+				Text(buttonText)))
+}
@@ -0,0 +1,174 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/testutils"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/test"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+func setupTwoFactorApp(t *testing.T) app.AppInterface {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetVaultStoreUsed(true)
+	cfg.SetVaultStoreKey("test-key")
+	cfg.SetCsrfSecret("test-csrf-secret")
+
+	return testutils.Setup(testutils.WithCfg(cfg))
+}
+
+func postTwoFactor(t *testing.T, app app.AppInterface, values url.Values) (string, *http.Response) {
+	t.Helper()
+
+	values.Set("csrf_token", csrf.TokenGenerate("test-csrf-secret"))
+
+	body, response, err := testutils.CallStringHandlerAsUser(app, http.MethodPost, NewTwoFactorController(app).Handler, test.NewRequestOptions{
+		FormValues: values,
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return body, response
+}
+
+// enrolCode opens the page, and returns a valid code for the key shown
+func enrolCode(t *testing.T, app app.AppInterface) string {
+	t.Helper()
+
+	body, _, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewTwoFactorController(app).Handler, test.NewRequestOptions{}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, "data:image/png;base64,") {
+		t.Fatal("expected the QR code")
+	}
+
+	keyURL, err := app.GetCacheStore().Get(TWO_FACTOR_ENROL_CACHE_KEY_PREFIX+test.USER_01, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := otp.NewKeyFromURL(keyURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, key.Secret()) {
+		t.Fatal("expected the secret to be shown, for manual entry")
+	}
+
+	code, err := totp.GenerateCode(key.Secret(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return code
+}
+
+func TestTwoFactorController_RequiresVault(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewTwoFactorController(app).Handler, test.NewRequestOptions{}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" {
+		t.Fatalf("expected an error, got %v", flashMessage)
+	}
+}
+
+func TestTwoFactorController_EnableWithWrongCode(t *testing.T) {
+	app := setupTwoFactorApp(t)
+	enrolCode(t, app)
+
+	_, response := postTwoFactor(t, app, url.Values{"action": {"enable"}, "code": {"000000"}})
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Message != msgTwoFactorCodeInvalid {
+		t.Fatalf("expected the invalid code error, got %v", flashMessage)
+	}
+
+	user, err := app.GetUserStore().UserFindByID(context.Background(), test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ext.UserTwoFactorEnabled(user) {
+		t.Fatal("expected two-factor authentication to stay disabled")
+	}
+}
+
+func TestTwoFactorController_EnableAndDisable(t *testing.T) {
+	app := setupTwoFactorApp(t)
+	code := enrolCode(t, app)
+
+	body, _ := postTwoFactor(t, app, url.Values{"action": {"enable"}, "code": {code}})
+
+	if !strings.Contains(body, "Your recovery codes") {
+		t.Fatal("expected the recovery codes to be shown")
+	}
+
+	user, err := app.GetUserStore().UserFindByID(context.Background(), test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ext.UserTwoFactorEnabled(user) {
+		t.Fatal("expected two-factor authentication to be enabled")
+	}
+
+	if ext.UserTwoFactorRecoveryCodesRemaining(user) != ext.TWO_FACTOR_RECOVERY_CODE_COUNT {
+		t.Fatal("expected the recovery codes to be saved")
+	}
+
+	_, response := postTwoFactor(t, app, url.Values{"action": {"disable"}, "code": {code}})
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected two-factor authentication to be disabled, got %v", flashMessage)
+	}
+
+	user, err = app.GetUserStore().UserFindByID(context.Background(), test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ext.UserTwoFactorEnabled(user) {
+		t.Fatal("expected two-factor authentication to be disabled")
+	}
+}
@@ -0,0 +1,350 @@
+package subscription
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/billing"
+	"project/internal/controllers/user/partials"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"project/internal/payment"
+	"strconv"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dracory/subscriptionstore"
+	"github.com/dracory/userstore"
+	"github.com/samber/lo"
+)
+
+// CHECKOUT_SESSION_CACHE_KEY_PREFIX prefixes the cache keys holding the
+// checkout session of a pending subscription, by subscription ID
+const CHECKOUT_SESSION_CACHE_KEY_PREFIX = "subscription_checkout_session:"
+
+// checkoutSessionExpirationSeconds how long the checkout session
+// is kept to verify the payment on return
+const checkoutSessionExpirationSeconds = 24 * 60 * 60
+
+// == CONTROLLER ==============================================================
+
+// billingController shows the plans and the subscription of the user, and
+// lets the user subscribe, upgrade, downgrade and cancel.
+//
+// Subscribing to a paid plan (or changing to one) goes through a checkout
+// session of the payment gateway. The new subscription stays pending until
+// paid, and then replaces the current one, see billing.SubscriptionActivate.
+// Plan changes are not prorated.
+type billingController struct {
+	app app.AppInterface
+}
+
+type billingControllerData struct {
+	authUser      userstore.UserInterface
+	currency      string
+	plan          billing.Plan
+	subscription  subscriptionstore.SubscriptionInterface
+	trialEligible bool
+	feature       string
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewBillingController creates a new billing controller
+func NewBillingController(app app.AppInterface) *billingController {
+	return &billingController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *billingController) Handler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.User().Home(), 10)
+	}
+
+	if r.Method == http.MethodPost {
+		return controller.post(w, r, data)
+	}
+
+	pageHeader := partials.PageHeader("bi-credit-card", "Billing", []layouts.Breadcrumb{
+		{Name: "Dashboard", Icon: "bi-speedometer2", URL: links.User().Home()},
+		{Name: "Billing", URL: links.User().Subscription()},
+	})
+
+	page := hb.Section().
+		Child(hb.Div().
+			Class("container").
+			Child(pageHeader)).
+		Child(hb.Div().
+			Class("container").
+			ChildIf(data.feature != "", controller.featureAlert(data)).
+			Child(controller.currentPlan(data)).
+			Child(controller.planCards(data)))
+
+	return layouts.NewUserLayout(controller.app, r, layouts.Options{
+		Title:   "Billing",
+		Content: hb.NewDiv().Class("p-3").Child(page),
+	}).ToHTML()
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *billingController) post(w http.ResponseWriter, r *http.Request, data billingControllerData) string {
+	billingURL := links.User().Subscription()
+
+	if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", billingURL, 10)
+	}
+
+	switch req.GetStringTrimmed(r, "action") {
+	case "subscribe":
+		plan, found := billing.PlanFind(req.GetStringTrimmed(r, "plan_id"))
+
+		if !found {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Plan not found", billingURL, 10)
+		}
+
+		return controller.postSubscribe(w, r, data, plan)
+	case "cancel":
+		return controller.postCancel(w, r, data)
+	}
+
+	return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Invalid action", billingURL, 10)
+}
+
+// postSubscribe changes the plan of the user. The free plan needs no
+// subscription, so changing to it cancels the current subscription
+func (controller *billingController) postSubscribe(w http.ResponseWriter, r *http.Request, data billingControllerData, plan billing.Plan) string {
+	ctx := r.Context()
+	billingURL := links.User().Subscription()
+
+	if plan.ID == data.plan.ID {
+		return helpers.ToFlashInfo(controller.app.GetCacheStore(), w, r, "You are already on the "+plan.Title+" plan.", billingURL, 10)
+	}
+
+	if plan.IsFree() {
+		return controller.postCancel(w, r, data)
+	}
+
+	gateway := controller.app.GetPaymentGateway()
+
+	if gateway == nil || controller.app.GetCacheStore() == nil {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Payments are currently unavailable. Please try again later.", billingURL, 10)
+	}
+
+	if err := billing.PlanEnsure(ctx, controller.app.GetSubscriptionStore(), plan, data.currency); err != nil {
+		controller.app.GetLogger().Error("At billingController > postSubscribe > PlanEnsure", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "We could not start your subscription. Please try again later.", billingURL, 10)
+	}
+
+	email, _, _, _, _, err := ext.UserUntokenizeTransparently(ctx, controller.app, data.authUser)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At billingController > postSubscribe > UserUntokenizeTransparently", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "We could not read your account details. Please try again later.", billingURL, 10)
+	}
+
+	subscription, err := billing.SubscriptionPendingCreate(ctx, controller.app.GetSubscriptionStore(), data.authUser.GetID(), plan)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At billingController > postSubscribe > SubscriptionPendingCreate", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "We could not start your subscription. Please try again later.", billingURL, 10)
+	}
+
+	trialDays := int64(0)
+	if data.trialEligible {
+		trialDays = int64(plan.TrialDays)
+	}
+
+	returnParams := map[string]string{"subscription_id": subscription.GetID()}
+
+	session, err := gateway.CheckoutCreate(ctx, payment.CheckoutOptions{
+		Currency:       data.currency,
+		CustomerEmail:  email,
+		LineItems:      []payment.LineItem{{Name: plan.Title + " plan", Quantity: 1, UnitAmount: plan.Amount}},
+		SubscriptionID: subscription.GetID(),
+		Interval:       plan.Interval,
+		TrialDays:      trialDays,
+		SuccessURL:     links.User().SubscriptionsPaymentSuccess(returnParams),
+		CancelURL:      links.User().SubscriptionsPaymentCanceled(returnParams),
+	})
+
+	if err != nil {
+		controller.app.GetLogger().Error("At billingController > postSubscribe > CheckoutCreate", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "The payment provider is currently unavailable. Please try again later.", billingURL, 10)
+	}
+
+	if err := controller.app.GetCacheStore().Set(CHECKOUT_SESSION_CACHE_KEY_PREFIX+subscription.GetID(), session.ID, checkoutSessionExpirationSeconds); err != nil {
+		// The webhook still activates the subscription once paid
+		controller.app.GetLogger().Warn("At billingController > postSubscribe > Set checkout session", slog.String("error", err.Error()))
+	}
+
+	http.Redirect(w, r, session.URL, http.StatusSeeOther)
+	return ""
+}
+
+func (controller *billingController) postCancel(w http.ResponseWriter, r *http.Request, data billingControllerData) string {
+	billingURL := links.User().Subscription()
+
+	if data.subscription == nil {
+		return helpers.ToFlashInfo(controller.app.GetCacheStore(), w, r, "You have no subscription to cancel.", billingURL, 10)
+	}
+
+	err := billing.SubscriptionCancel(r.Context(), controller.app.GetSubscriptionStore(), controller.app.GetPaymentGateway(), data.subscription)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At billingController > postCancel > SubscriptionCancel", slog.String("error", err.Error()), slog.String("subscription_id", data.subscription.GetID()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "We could not cancel your subscription. Please try again later.", billingURL, 10)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "Your subscription has been cancelled. You are now on the "+billing.FreePlan().Title+" plan.", billingURL, 10)
+}
+
+func (controller *billingController) featureAlert(data billingControllerData) hb.TagInterface {
+	return hb.Div().
+		Class("alert alert-warning").
+		Text("Your current plan does not include " + billing.FeatureName(data.feature) + ". Upgrade to a plan which does to continue.")
+}
+
+func (controller *billingController) currentPlan(data billingControllerData) hb.TagInterface {
+	cancelForm := hb.Form().
+		Method(http.MethodPost).
+		Action(links.User().Subscription()).
+		Attr("data-confirm", "Cancel your subscription? You will move to the "+billing.FreePlan().Title+" plan immediately.").
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret()))).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value("cancel")).
+		Child(hb.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn btn-outline-danger").
+			Text("Cancel subscription"))
+
+	return hb.Div().
+		Class("card mb-4").
+		Child(hb.Div().
+			Class("card-body d-flex flex-wrap justify-content-between align-items-center gap-3").
+			Child(hb.Div().
+				Child(hb.Div().Class("text-muted").Text("Current plan")).
+				Child(hb.H4().Class("mb-0").Text(data.plan.Title)).
+				ChildIf(data.subscription != nil, hb.Div().
+					Class("small text-muted").
+					Text("Subscribed since "+subscriptionDate(data.subscription)))).
+			ChildIf(data.subscription != nil, cancelForm))
+}
+
+func (controller *billingController) planCards(data billingControllerData) hb.TagInterface {
+	csrfToken := csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret())
+
+	cards := lo.Map(billing.Plans(), func(plan billing.Plan, _ int) hb.TagInterface {
+		isCurrent := plan.ID == data.plan.ID
+
+		price := "Free"
+		if !plan.IsFree() {
+			price = data.currency + " " + payment.FormatMinorUnits(plan.Amount, data.currency) + " / " + plan.Interval
+		}
+
+		features := lo.Map(billing.AllFeatures(), func(feature string, _ int) hb.TagInterface {
+			icon := "bi bi-x-lg text-muted me-2"
+			if plan.HasFeature(feature) {
+				icon = "bi bi-check-lg text-success me-2"
+			}
+
+			return hb.LI().Child(hb.I().Class(icon)).Text(billing.FeatureName(feature))
+		})
+
+		limits := lo.Map(billing.AllLimits(), func(name string, _ int) hb.TagInterface {
+			return hb.LI().
+				Child(hb.I().Class("bi bi-dot me-2")).
+				Text(billing.LimitName(name) + ": " + limitText(plan.Limit(name)))
+		})
+
+		return hb.Div().
+			Class("col-md-4 mb-4").
+			Child(hb.Div().
+				Class("card h-100").
+				ClassIf(isCurrent, "border-primary").
+				Child(hb.Div().
+					Class("card-body d-flex flex-column").
+					Child(hb.H5().Class("card-title").Text(plan.Title)).
+					Child(hb.P().Class("text-muted").Text(plan.Description)).
+					Child(hb.P().Class("fs-4 fw-bold").Text(price)).
+					Child(hb.UL().Class("list-unstyled").Children(features).Children(limits)).
+					Child(hb.Div().
+						Class("mt-auto").
+						Child(controller.planButton(data, plan, csrfToken)))))
+	})
+
+	return hb.Div().Class("row").Children(cards)
+}
+
+// planButton returns the button to change to the plan, labelled as an
+// upgrade, a downgrade or a trial
+func (controller *billingController) planButton(data billingControllerData, plan billing.Plan, csrfToken string) hb.TagInterface {
+	if plan.ID == data.plan.ID {
+		return hb.Button().
+			Class("btn btn-secondary w-100").
+			Attr("disabled", "disabled").
+			Text("Current plan")
+	}
+
+	label := "Downgrade"
+	buttonClass := "btn btn-outline-primary w-100"
+
+	if plan.Rank > data.plan.Rank {
+		label = "Upgrade"
+		buttonClass = "btn btn-primary w-100"
+
+		if data.trialEligible && plan.TrialDays > 0 {
+			label = "Start " + strconv.Itoa(plan.TrialDays) + "-day free trial"
+		}
+	}
+
+	return hb.Form().
+		Method(http.MethodPost).
+		Action(links.User().Subscription()).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrfToken)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value("subscribe")).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("plan_id").Value(plan.ID)).
+		Child(hb.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class(buttonClass).
+			Text(label))
+}
+
+func (controller *billingController) prepareData(r *http.Request) (data billingControllerData, errorMessage string) {
+	if controller.app.GetSubscriptionStore() == nil {
+		return data, "Sorry, billing is currently unavailable. Please try again later."
+	}
+
+	data.authUser = helpers.GetAuthUser(r)
+
+	if data.authUser == nil {
+		return data, "Please log in to manage your subscription."
+	}
+
+	plan, subscription, err := billing.SubscriberPlan(r.Context(), controller.app.GetSubscriptionStore(), data.authUser.GetID())
+
+	if err != nil {
+		controller.app.GetLogger().Error("At billingController > prepareData > SubscriberPlan", slog.String("error", err.Error()))
+		return data, "Sorry, there was an error loading your subscription. Please try again later."
+	}
+
+	trialEligible, err := billing.TrialEligible(r.Context(), controller.app.GetSubscriptionStore(), data.authUser.GetID())
+
+	if err != nil {
+		controller.app.GetLogger().Error("At billingController > prepareData > TrialEligible", slog.String("error", err.Error()))
+		return data, "Sorry, there was an error loading your subscription. Please try again later."
+	}
+
+	data.currency = controller.app.GetConfig().GetPaymentCurrency()
+	data.plan = plan
+	data.subscription = subscription
+	data.trialEligible = trialEligible
+	data.feature = req.GetStringTrimmed(r, "feature")
+
+	return data, ""
+}
@@ -0,0 +1,200 @@
+package subscription
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/billing"
+	"project/internal/payment"
+	"project/internal/testutils"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/subscriptionstore"
+	"github.com/dracory/test"
+)
+
+func setupBillingApp(t *testing.T) (app.AppInterface, *payment.FakeGateway) {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetSubscriptionStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetCsrfSecret("test-csrf-secret")
+	cfg.SetPaymentCurrency("GBP")
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+	gateway := payment.NewFakeGateway(payment.FAKE_WEBHOOK_SECRET)
+	app.SetPaymentGateway(gateway)
+
+	return app, gateway
+}
+
+func postBilling(t *testing.T, app app.AppInterface, values url.Values) *http.Response {
+	t.Helper()
+
+	values.Set("csrf_token", csrf.TokenGenerate("test-csrf-secret"))
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodPost, NewBillingController(app).Handler, test.NewRequestOptions{
+		FormValues: values,
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return response
+}
+
+func TestBillingController_ShowsPlans(t *testing.T) {
+	app, _ := setupBillingApp(t)
+
+	body, _, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewBillingController(app).Handler, test.NewRequestOptions{
+		QueryParams: url.Values{"feature": {billing.FEATURE_PRIORITY_SUPPORT}},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Billing", "Current plan", "Pro", "Business", "GBP 9.00 / month", "Start 14-day free trial", "does not include Priority support"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected body to contain %q", expected)
+		}
+	}
+
+	if strings.Contains(body, "Cancel subscription") {
+		t.Error("expected no cancel button on the free plan")
+	}
+}
+
+func TestBillingController_SubscribeStartsCheckoutWithTrial(t *testing.T) {
+	app, gateway := setupBillingApp(t)
+
+	response := postBilling(t, app, url.Values{"action": {"subscribe"}, "plan_id": {billing.PLAN_PRO}})
+
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected a redirect to the checkout, got %d", response.StatusCode)
+	}
+
+	sessions := gateway.Sessions()
+	if len(sessions) != 1 {
+		t.Fatalf("expected one checkout session, got %d", len(sessions))
+	}
+
+	options := sessions[0].Options
+	if options.SubscriptionID == "" || options.Interval != payment.INTERVAL_MONTH || options.TrialDays != 14 {
+		t.Errorf("expected a monthly subscription checkout with a trial, got %+v", options)
+	}
+
+	if sessions[0].Amount != 900 {
+		t.Errorf("expected amount 900, got %d", sessions[0].Amount)
+	}
+
+	subscription, err := app.GetSubscriptionStore().SubscriptionFindByID(context.Background(), options.SubscriptionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if subscription == nil || subscription.GetStatus() != billing.SUBSCRIPTION_STATUS_PENDING {
+		t.Fatal("expected a pending subscription until paid")
+	}
+}
+
+func TestBillingController_UpgradeHasNoTrial(t *testing.T) {
+	app, gateway := setupBillingApp(t)
+
+	if _, err := testutils.SeedSubscription(app.GetSubscriptionStore(), test.USER_01, billing.PLAN_PRO); err != nil {
+		t.Fatal(err)
+	}
+
+	response := postBilling(t, app, url.Values{"action": {"subscribe"}, "plan_id": {billing.PLAN_BUSINESS}})
+
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected a redirect to the checkout, got %d", response.StatusCode)
+	}
+
+	sessions := gateway.Sessions()
+	if len(sessions) != 1 || sessions[0].Options.TrialDays != 0 {
+		t.Fatal("expected a checkout session without trial for a former subscriber")
+	}
+}
+
+func TestBillingController_DowngradeToFreeCancels(t *testing.T) {
+	app, _ := setupBillingApp(t)
+
+	if _, err := testutils.SeedSubscription(app.GetSubscriptionStore(), test.USER_01, testutils.PLAN_01); err != nil {
+		t.Fatal(err)
+	}
+
+	response := postBilling(t, app, url.Values{"action": {"subscribe"}, "plan_id": {billing.PLAN_FREE}})
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected a success flash message, got %+v", flashMessage)
+	}
+
+	active, err := billing.SubscriptionActiveFind(context.Background(), app.GetSubscriptionStore(), test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if active != nil {
+		t.Error("expected no active subscription on the free plan")
+	}
+}
+
+func TestBillingController_InvalidCsrfToken(t *testing.T) {
+	app, gateway := setupBillingApp(t)
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodPost, NewBillingController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{"action": {"subscribe"}, "plan_id": {billing.PLAN_PRO}, "csrf_token": {"invalid"}},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" {
+		t.Fatalf("expected an error flash message, got %+v", flashMessage)
+	}
+
+	if len(gateway.Sessions()) != 0 {
+		t.Error("expected no checkout session")
+	}
+}
+
+func TestBillingController_CancelWithoutSubscription(t *testing.T) {
+	app, _ := setupBillingApp(t)
+
+	response := postBilling(t, app, url.Values{"action": {"cancel"}})
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "info" {
+		t.Fatalf("expected an info flash message, got %+v", flashMessage)
+	}
+
+	subscriptions, err := app.GetSubscriptionStore().SubscriptionList(context.Background(), subscriptionstore.NewSubscriptionQuery().SetSubscriberID(test.USER_01))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(subscriptions) != 0 {
+		t.Errorf("expected no subscriptions, got %d", len(subscriptions))
+	}
+}
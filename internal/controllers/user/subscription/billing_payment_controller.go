@@ -0,0 +1,145 @@
+package subscription
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/billing"
+	"project/internal/helpers"
+	"project/internal/links"
+
+	"github.com/dracory/req"
+	"github.com/dracory/subscriptionstore"
+)
+
+// == CONTROLLER ==============================================================
+
+// billingPaymentController handles the user returning from the checkout
+// of a subscription at the payment provider
+type billingPaymentController struct {
+	app app.AppInterface
+}
+
+type billingPaymentControllerData struct {
+	subscription subscriptionstore.SubscriptionInterface
+	plan         billing.Plan
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewBillingPaymentController creates a new billing payment return controller
+func NewBillingPaymentController(app app.AppInterface) *billingPaymentController {
+	return &billingPaymentController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+// SuccessHandler handles the success return URL.
+//
+// The return URL alone is not a proof of payment, so the checkout session
+// is verified with the payment provider before the subscription is
+// activated. Without the checkout session (e.g. expired from the cache)
+// the subscription is left to the webhook to activate.
+func (controller *billingPaymentController) SuccessHandler(w http.ResponseWriter, r *http.Request) string {
+	billingURL := links.User().Subscription()
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, billingURL, 10)
+	}
+
+	successMessage := "Thank you! You are now on the " + data.plan.Title + " plan."
+
+	if data.subscription.GetStatus() == subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE {
+		return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, successMessage, billingURL, 10)
+	}
+
+	pendingMessage := "We have not received the confirmation of your payment yet. Your plan will be updated as soon as it arrives."
+
+	if data.subscription.GetStatus() != billing.SUBSCRIPTION_STATUS_PENDING {
+		return helpers.ToFlashInfo(controller.app.GetCacheStore(), w, r, pendingMessage, billingURL, 10)
+	}
+
+	sessionID, err := controller.app.GetCacheStore().Get(CHECKOUT_SESSION_CACHE_KEY_PREFIX+data.subscription.GetID(), "")
+
+	if err != nil {
+		controller.app.GetLogger().Warn("At billingPaymentController > SuccessHandler > Get checkout session", slog.String("error", err.Error()))
+	}
+
+	if sessionID == "" {
+		return helpers.ToFlashInfo(controller.app.GetCacheStore(), w, r, pendingMessage, billingURL, 10)
+	}
+
+	isPaid, err := controller.app.GetPaymentGateway().CheckoutVerify(r.Context(), sessionID)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At billingPaymentController > SuccessHandler > CheckoutVerify", slog.String("error", err.Error()), slog.String("subscription_id", data.subscription.GetID()))
+	}
+
+	if !isPaid {
+		return helpers.ToFlashInfo(controller.app.GetCacheStore(), w, r, pendingMessage, billingURL, 10)
+	}
+
+	err = billing.SubscriptionActivate(r.Context(), controller.app.GetSubscriptionStore(), controller.app.GetPaymentGateway(), data.subscription)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At billingPaymentController > SuccessHandler > SubscriptionActivate", slog.String("error", err.Error()), slog.String("subscription_id", data.subscription.GetID()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your payment was received, but we could not update your plan. Our team has been notified.", billingURL, 10)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, successMessage, billingURL, 10)
+}
+
+// CanceledHandler handles the cancel return URL. The pending subscription
+// is left as it is, it never becomes active without a payment
+func (controller *billingPaymentController) CanceledHandler(w http.ResponseWriter, r *http.Request) string {
+	billingURL := links.User().Subscription()
+
+	if _, errorMessage := controller.prepareData(r); errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, billingURL, 10)
+	}
+
+	return helpers.ToFlashInfo(controller.app.GetCacheStore(), w, r, "Your payment was canceled. Your plan has not changed.", billingURL, 10)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *billingPaymentController) prepareData(r *http.Request) (data billingPaymentControllerData, errorMessage string) {
+	if controller.app.GetSubscriptionStore() == nil || controller.app.GetCacheStore() == nil || controller.app.GetPaymentGateway() == nil {
+		return data, "Sorry, billing is currently unavailable. Please try again later."
+	}
+
+	authUser := helpers.GetAuthUser(r)
+
+	if authUser == nil {
+		return data, "Please log in to manage your subscription."
+	}
+
+	subscriptionID := req.GetStringTrimmed(r, "subscription_id")
+
+	if subscriptionID == "" {
+		return data, "Subscription ID is required"
+	}
+
+	subscription, err := controller.app.GetSubscriptionStore().SubscriptionFindByID(r.Context(), subscriptionID)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At billingPaymentController > prepareData > SubscriptionFindByID", slog.String("error", err.Error()))
+		return data, "Sorry, there was an error loading your subscription. Please try again later."
+	}
+
+	if subscription == nil || subscription.GetSubscriberID() != authUser.GetID() {
+		return data, "Subscription not found"
+	}
+
+	plan, found := billing.PlanFind(subscription.GetPlanID())
+
+	if !found {
+		return data, "Plan not found"
+	}
+
+	data.subscription = subscription
+	data.plan = plan
+
+	return data, ""
+}
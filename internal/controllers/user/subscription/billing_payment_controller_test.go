@@ -0,0 +1,123 @@
+package subscription
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"project/internal/billing"
+	"project/internal/testutils"
+
+	"github.com/dracory/subscriptionstore"
+	"github.com/dracory/test"
+)
+
+func TestBillingPaymentController_SuccessActivatesSubscription(t *testing.T) {
+	app, gateway := setupBillingApp(t)
+
+	postBilling(t, app, url.Values{"action": {"subscribe"}, "plan_id": {billing.PLAN_PRO}})
+
+	sessions := gateway.Sessions()
+	if len(sessions) != 1 {
+		t.Fatalf("expected one checkout session, got %d", len(sessions))
+	}
+	subscriptionID := sessions[0].Options.SubscriptionID
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewBillingPaymentController(app).SuccessHandler, test.NewRequestOptions{
+		QueryParams: url.Values{"subscription_id": {subscriptionID}},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected a success flash message, got %+v", flashMessage)
+	}
+
+	plan, _, err := billing.SubscriberPlan(context.Background(), app.GetSubscriptionStore(), test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.ID != billing.PLAN_PRO {
+		t.Errorf("expected the pro plan, got %q", plan.ID)
+	}
+}
+
+func TestBillingPaymentController_SuccessUnpaidStaysPending(t *testing.T) {
+	app, gateway := setupBillingApp(t)
+	gateway.SetAutoPay(false)
+
+	postBilling(t, app, url.Values{"action": {"subscribe"}, "plan_id": {billing.PLAN_PRO}})
+	subscriptionID := gateway.Sessions()[0].Options.SubscriptionID
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewBillingPaymentController(app).SuccessHandler, test.NewRequestOptions{
+		QueryParams: url.Values{"subscription_id": {subscriptionID}},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "info" {
+		t.Fatalf("expected an info flash message, got %+v", flashMessage)
+	}
+
+	subscription, err := app.GetSubscriptionStore().SubscriptionFindByID(context.Background(), subscriptionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if subscription.GetStatus() != billing.SUBSCRIPTION_STATUS_PENDING {
+		t.Errorf("expected the subscription to stay pending, got %q", subscription.GetStatus())
+	}
+}
+
+func TestBillingPaymentController_OtherUserSubscription(t *testing.T) {
+	app, _ := setupBillingApp(t)
+
+	subscription, err := testutils.SeedSubscription(app.GetSubscriptionStore(), "someone-else", billing.PLAN_PRO)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subscription.SetStatus(billing.SUBSCRIPTION_STATUS_PENDING)
+	if err := app.GetSubscriptionStore().SubscriptionUpdate(context.Background(), subscription); err != nil {
+		t.Fatal(err)
+	}
+
+	_, response, err := testutils.CallStringHandlerAsUser(app, http.MethodGet, NewBillingPaymentController(app).SuccessHandler, test.NewRequestOptions{
+		QueryParams: url.Values{"subscription_id": {subscription.GetID()}},
+	}, test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" {
+		t.Fatalf("expected an error flash message, got %+v", flashMessage)
+	}
+
+	found, err := app.GetSubscriptionStore().SubscriptionFindByID(context.Background(), subscription.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.GetStatus() == subscriptionstore.SUBSCRIPTION_STATUS_ACTIVE {
+		t.Error("expected the subscription of another user not to be activated")
+	}
+}
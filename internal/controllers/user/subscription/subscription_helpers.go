@@ -0,0 +1,24 @@
+package subscription
+
+import (
+	"project/internal/billing"
+	"strconv"
+	"strings"
+
+	"github.com/dracory/subscriptionstore"
+)
+
+// subscriptionDate returns the date the subscription was created on
+func subscriptionDate(subscription subscriptionstore.SubscriptionInterface) string {
+	date, _, _ := strings.Cut(subscription.GetCreatedAt(), " ")
+	return date
+}
+
+// limitText returns the value of a plan limit in words
+func limitText(value int) string {
+	if value == billing.UNLIMITED {
+		return "Unlimited"
+	}
+
+	return strconv.Itoa(value)
+}
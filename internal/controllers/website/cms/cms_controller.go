@@ -3,6 +3,7 @@ package cms
 import (
 	"net/http"
 	"project/internal/app"
+	"project/internal/helpers"
 	"project/internal/widgets"
 	"sync"
 
@@ -36,7 +37,10 @@ func (controller cmsController) Handler(w http.ResponseWriter, r *http.Request)
 		w.WriteHeader(http.StatusInternalServerError)
 		return "cms is not configured"
 	}
-	return instance.StringHandler(w, r)
+
+	// Only the scripts of the templates carry the nonce, see
+	// cspTemplateStore, the ones of the content of the pages do not
+	return helpers.CSPNoncePlaceholderReplace(r.Context(), instance.StringHandler(w, r))
 }
 
 var instance cmsFrontend.FrontendInterface
@@ -51,12 +55,17 @@ func GetInstance(app app.AppInterface) cmsFrontend.FrontendInterface {
 			shortcodes = append(shortcodes, widget)
 		}
 
+		var store cmsstore.StoreInterface
+		if app.GetCmsStore() != nil {
+			store = cspTemplateStore{StoreInterface: app.GetCmsStore()}
+		}
+
 		frontend := cmsFrontend.New(cmsFrontend.Config{
 			// BlockEditorDefinitions: webtheme.BlockEditorDefinitions(),
 			BlockEditorRenderer: func(blocks []ui.BlockInterface) string {
 				return webtheme.New(blocks).ToHtml()
 			},
-			Store:              app.GetCmsStore(),
+			Store:              store,
 			Shortcodes:         shortcodes,
 			Logger:             app.GetLogger(),
 			CacheEnabled:       true,
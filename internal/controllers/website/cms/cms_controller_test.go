@@ -1,15 +1,16 @@
 package cms
 
 import (
-	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
-	"project/internal/links"
+	"project/internal/config"
+	"project/internal/helpers"
 	"project/internal/testutils"
 	"strings"
 	"testing"
 
-	"github.com/dracory/rtr"
+	"github.com/dracory/cmsstore"
 )
 
 func TestCmsController_Handler_Success(t *testing.T) {
@@ -135,41 +136,38 @@ func TestNewCmsController(t *testing.T) {
 	}
 }
 
-func TestCmsMcpEndpoint_RequiresApiKey(t *testing.T) {
+func TestCspTemplateStore_StampsTheTemplateOnly(t *testing.T) {
+	// --- Setup ---
 	cfg := testutils.DefaultConf()
 	cfg.SetCmsStoreUsed(true)
-	cfg.SetCmsStoreTemplateID("test-template")
-	cfg.SetCmsMcpApiKey("test-mcp-key")
-
 	app := testutils.Setup(testutils.WithCfg(cfg))
 
-	r := rtr.NewRouter()
-	r.AddRoutes(Routes(app))
-
-	// Missing key
-	reqMissing := httptest.NewRequest(http.MethodPost, links.MCP_CMS, bytes.NewBuffer([]byte(`{"jsonrpc":"2.0","id":"1","method":"list_tools","params":{}}`)))
-	resMissing := httptest.NewRecorder()
-	r.ServeHTTP(resMissing, reqMissing)
-	if resMissing.Code != http.StatusUnauthorized {
-		t.Fatalf("expected %d, got %d", http.StatusUnauthorized, resMissing.Code)
-	}
-
-	// Wrong key
-	reqWrong := httptest.NewRequest(http.MethodPost, links.MCP_CMS, bytes.NewBuffer([]byte(`{"jsonrpc":"2.0","id":"1","method":"list_tools","params":{}}`)))
-	reqWrong.Header.Set("X-MCP-API-Key", "wrong")
-	resWrong := httptest.NewRecorder()
-	r.ServeHTTP(resWrong, reqWrong)
-	if resWrong.Code != http.StatusUnauthorized {
-		t.Fatalf("expected %d, got %d", http.StatusUnauthorized, resWrong.Code)
-	}
-
-	// Correct key
-	reqOk := httptest.NewRequest(http.MethodPost, links.MCP_CMS, bytes.NewBuffer([]byte(`{"jsonrpc":"2.0","id":"1","method":"list_tools","params":{}}`)))
-	reqOk.Header.Set("X-MCP-API-Key", "test-mcp-key")
-	resOk := httptest.NewRecorder()
-	r.ServeHTTP(resOk, reqOk)
-	if resOk.Code != http.StatusOK {
-		t.Fatalf("expected %d, got %d", http.StatusOK, resOk.Code)
+	template := cmsstore.NewTemplate().
+		SetSiteID("test-site").
+		SetStatus(cmsstore.TEMPLATE_STATUS_ACTIVE).
+		SetName("test-template").
+		SetContent(`<script src="/app.js"></script>[[PageContent]]`)
+
+	if err := app.GetCmsStore().TemplateCreate(context.Background(), template); err != nil {
+		t.Fatalf("Failed to create test template: %v", err)
+	}
+
+	// --- Execute ---
+	found, err := cspTemplateStore{StoreInterface: app.GetCmsStore()}.TemplateFindByID(context.Background(), template.ID())
+	if err != nil || found == nil {
+		t.Fatalf("TemplateFindByID() = %v, %v", found, err)
+	}
+
+	// The content of the page is rendered into the template afterwards
+	html := strings.Replace(found.Content(), "[[PageContent]]", `<script>alert(1)</script>`, 1)
+
+	ctx := context.WithValue(context.Background(), config.CSPNonceContextKey{}, "abc123")
+	html = helpers.CSPNoncePlaceholderReplace(ctx, html)
+
+	// --- Assert ---
+	want := `<script nonce="abc123" src="/app.js"></script><script>alert(1)</script>`
+	if html != want {
+		t.Errorf("expected %q, got %q", want, html)
 	}
 }
 
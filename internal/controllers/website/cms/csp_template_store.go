@@ -0,0 +1,33 @@
+package cms
+
+import (
+	"context"
+	"project/internal/helpers"
+
+	"github.com/dracory/cmsstore"
+)
+
+// cspTemplateStore stamps a placeholder of the nonce on the scripts and
+// styles of the templates, when the frontend loads them.
+//
+// The templates are authored by the administrators, unlike the content of
+// the pages, which is rendered into them afterwards and so is left without
+// the nonce. The controller replaces the placeholder with the nonce of the
+// request (see helpers.CSPNoncePlaceholderReplace), which also works with
+// the pages cached by the frontend.
+type cspTemplateStore struct {
+	cmsstore.StoreInterface
+}
+
+// TemplateFindByID returns the template with the placeholder stamped
+func (store cspTemplateStore) TemplateFindByID(ctx context.Context, id string) (cmsstore.TemplateInterface, error) {
+	template, err := store.StoreInterface.TemplateFindByID(ctx, id)
+
+	if err != nil || template == nil {
+		return template, err
+	}
+
+	template.SetContent(helpers.CSPNoncePlaceholderStampHTML(template.Content()))
+
+	return template, nil
+}
@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"project/internal/app"
+	"project/internal/helpers"
 	"project/internal/links"
 	"project/internal/tasks/email_admin_new_contact"
 
@@ -23,6 +24,9 @@ import (
 	"github.com/dracory/liveflux"
 )
 
+// FORM_CONTACT_KIND is the Liveflux kind of the contact form
+const FORM_CONTACT_KIND = "website_contact_form"
+
 type formContact struct {
 	liveflux.Base
 	app             app.AppInterface
@@ -58,7 +62,7 @@ func NewFormContact(app app.AppInterface) liveflux.ComponentInterface {
 }
 
 func (c *formContact) GetKind() string {
-	return "website_contact_form"
+	return FORM_CONTACT_KIND
 }
 
 func (c *formContact) Mount(ctx context.Context, params map[string]string) error {
@@ -344,27 +348,27 @@ func (c *formContact) Render(ctx context.Context) hb.TagInterface {
 		})
 
 	if c.ErrorMessage != "" {
-		card = card.Child(hb.Script(`
+		card = card.Child(helpers.CSPNonceStampTag(ctx, hb.Script(`
  			Swal.fire({
  				icon: 'error',
  				title: 'Oops...',
- 				text: ` + string(errorMessageJSON) + `,
+ 				text: `+string(errorMessageJSON)+`,
  			})
- 		`))
+ 		`)))
 	}
 
 	if c.SuccessMessage != "" {
-		card = card.Child(hb.Script(`
+		card = card.Child(helpers.CSPNonceStampTag(ctx, hb.Script(`
  			Swal.fire({
  				icon: 'success',
  				title: 'Saved',
- 				text: ` + string(successMessageJSON) + `,
+ 				text: `+string(successMessageJSON)+`,
  			})
- 		`))
+ 		`)))
 	}
 
 	if c.RedirectURL != "" {
-		card = card.Child(hb.Script(`setTimeout(() => {window.location.href="` + c.RedirectURL + `";}, 5000);`))
+		card = card.Child(helpers.CSPNonceStampTag(ctx, hb.Script(`setTimeout(() => {window.location.href="`+c.RedirectURL+`";}, 5000);`)))
 	}
 
 	return c.Root(card)
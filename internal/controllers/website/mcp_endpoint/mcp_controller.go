@@ -0,0 +1,126 @@
+package mcp_endpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"project/internal/app"
+	"project/internal/helpers"
+	"project/internal/mcp"
+	"project/internal/mcp/providers"
+
+	"github.com/dracory/req"
+)
+
+// == CONTROLLER ===============================================================
+
+// mcpController serves the MCP server, with the tools of its providers
+type mcpController struct {
+	app    app.AppInterface
+	server *mcp.Server
+}
+
+// == CONSTRUCTOR ==============================================================
+
+// NewMCPController creates a new MCP controller, with the tools of all the
+// providers, or only the ones with the names (e.g. mcp.PROVIDER_BLOG)
+func NewMCPController(app app.AppInterface, providerNames ...string) *mcpController {
+	return &mcpController{
+		app:    app,
+		server: providers.NewServer(app, providerNames...),
+	}
+}
+
+// == PUBLIC METHODS ===========================================================
+
+// Handler answers the JSON-RPC request of the MCP client the request is
+// authenticated with, see middlewares.NewMCPAuthMiddleware
+func (controller *mcpController) Handler(w http.ResponseWriter, r *http.Request) {
+	client := helpers.GetMCPClient(r)
+	if client == nil {
+		respondError(w, http.StatusUnauthorized, mcp.ERROR_CODE_INVALID_REQUEST, "MCP client key required")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, mcp.MAX_REQUEST_BYTES))
+
+	maxBytesError := &http.MaxBytesError{}
+	if errors.As(err, &maxBytesError) {
+		respondError(w, http.StatusRequestEntityTooLarge, mcp.ERROR_CODE_INVALID_REQUEST, "the request is larger than the maximum of 1MB")
+		return
+	}
+
+	if err != nil {
+		respondError(w, http.StatusBadRequest, mcp.ERROR_CODE_PARSE, "the request could not be read")
+		return
+	}
+
+	response := controller.server.Handle(r.Context(), *client, req.GetIP(r), body)
+
+	// The notifications have no response
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	respond(w, http.StatusOK, response)
+}
+
+// Health answers the GET requests, with a stream of keepalive events to
+// the clients accepting them, else with a plain text status
+func (controller *mcpController) Health(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("MCP is working"))
+		return
+	}
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("event: ready\ndata: {}\n\n"))
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			_, _ = w.Write([]byte(": keepalive\n\n"))
+			_ = rc.Flush()
+		}
+	}
+}
+
+// == PRIVATE METHODS ==========================================================
+
+// respondError writes the JSON-RPC error with the status
+func respondError(w http.ResponseWriter, status int, code int, message string) {
+	respond(w, status, mcp.ErrorResponse(nil, code, message))
+}
+
+// respond writes the JSON-RPC response with the status
+func respond(w http.ResponseWriter, status int, response *mcp.Response) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,60 @@
+package mcp_endpoint
+
+import (
+	"net/http"
+
+	"project/internal/app"
+	"project/internal/links"
+	"project/internal/mcp"
+	"project/internal/middlewares"
+	"project/internal/ratelimit"
+
+	"github.com/dracory/rtr"
+)
+
+// Routes returns the routes of the MCP endpoints: the one with the tools
+// of all the providers, and the blog and CMS ones, kept for the clients
+// set up with them. The calls are authenticated with the key of an MCP
+// client, and limited per client
+func Routes(app app.AppInterface) []rtr.RouteInterface {
+	if app == nil {
+		return []rtr.RouteInterface{}
+	}
+
+	endpoints := []struct {
+		name      string
+		path      string
+		providers []string
+	}{
+		{name: "MCP Endpoint", path: links.MCP},
+		{name: "Blog > MCP Endpoint", path: links.MCP_BLOG, providers: []string{mcp.PROVIDER_BLOG}},
+		{name: "Cms > MCP Endpoint", path: links.MCP_CMS, providers: []string{mcp.PROVIDER_CMS}},
+	}
+
+	routes := []rtr.RouteInterface{}
+
+	for _, endpoint := range endpoints {
+		controller := NewMCPController(app, endpoint.providers...)
+
+		healthRoute := rtr.NewRoute().
+			SetName("Website > " + endpoint.name + " > Health").
+			SetPath(endpoint.path).
+			SetMethod(http.MethodGet).
+			SetHandler(controller.Health)
+
+		route := rtr.NewRoute().
+			SetName("Website > " + endpoint.name).
+			SetPath(endpoint.path).
+			SetMethod(http.MethodPost).
+			SetHandler(controller.Handler)
+
+		route.AddBeforeMiddlewares([]rtr.MiddlewareInterface{
+			middlewares.NewMCPAuthMiddleware(app),
+			middlewares.NewRateLimitMiddleware(app, ratelimit.GROUP_MCP),
+		})
+
+		routes = append(routes, healthRoute, route)
+	}
+
+	return routes
+}
@@ -0,0 +1,140 @@
+package mcp_endpoint
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/links"
+	"project/internal/testutils"
+
+	"github.com/dracory/rtr"
+)
+
+const listToolsBody = `{"jsonrpc":"2.0","id":"1","method":"list_tools","params":{}}`
+
+// callMCP posts the body to the MCP endpoint with the key, and returns
+// the response
+func callMCP(app app.AppInterface, path string, key string, body string) *httptest.ResponseRecorder {
+	router := rtr.NewRouter()
+	router.AddRoutes(Routes(app))
+
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	if key != "" {
+		req.Header.Set("X-MCP-API-Key", key)
+	}
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	return res
+}
+
+func TestRoutes(t *testing.T) {
+	if routes := Routes(nil); len(routes) != 0 {
+		t.Fatalf("Routes(nil) should return no routes, got %d", len(routes))
+	}
+
+	// A health and a JSON-RPC route for each endpoint
+	if routes := Routes(testutils.Setup()); len(routes) != 6 {
+		t.Fatalf("expected 6 routes, got %d", len(routes))
+	}
+}
+
+func TestBlogMcpEndpoint_RequiresApiKey(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetBlogStoreUsed(true)
+	cfg.SetCmsMcpApiKey("test-mcp-key")
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	if res := callMCP(app, links.MCP_BLOG, "", listToolsBody); res.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d for a missing key, got %d", http.StatusUnauthorized, res.Code)
+	}
+
+	if res := callMCP(app, links.MCP_BLOG, "wrong", listToolsBody); res.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d for a wrong key, got %d", http.StatusUnauthorized, res.Code)
+	}
+
+	if res := callMCP(app, links.MCP_BLOG, "test-mcp-key", listToolsBody); res.Code != http.StatusOK {
+		t.Fatalf("expected %d for the legacy key, got %d", http.StatusOK, res.Code)
+	}
+}
+
+func TestCmsMcpEndpoint_RequiresApiKey(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetCmsStoreUsed(true)
+	cfg.SetCmsStoreTemplateID("test-template")
+	cfg.SetCmsMcpApiKey("test-mcp-key")
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	if res := callMCP(app, links.MCP_CMS, "", listToolsBody); res.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d for a missing key, got %d", http.StatusUnauthorized, res.Code)
+	}
+
+	if res := callMCP(app, links.MCP_CMS, "wrong", listToolsBody); res.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d for a wrong key, got %d", http.StatusUnauthorized, res.Code)
+	}
+
+	if res := callMCP(app, links.MCP_CMS, "test-mcp-key", listToolsBody); res.Code != http.StatusOK {
+		t.Fatalf("expected %d for the legacy key, got %d", http.StatusOK, res.Code)
+	}
+}
+
+func TestMcpEndpoint_ClientPermissions(t *testing.T) {
+	app := testutils.Setup(testutils.WithCustomStore(true), testutils.WithShopStore(true), testutils.WithTaskStore(true))
+
+	key, _, err := ext.MCPClientCreate(app, "Assistant", []string{"shop_*"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := callMCP(app, links.MCP, key, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	if res.Code != http.StatusOK || !strings.Contains(res.Body.String(), "shop_product_list") || strings.Contains(res.Body.String(), "tasks_") {
+		t.Fatalf("expected the shop tools only, got %d %s", res.Code, res.Body.String())
+	}
+
+	if res.Header().Get("RateLimit-Limit") == "" {
+		t.Error("expected the MCP calls to be rate limited")
+	}
+
+	res = callMCP(app, links.MCP, key, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"tasks_queue_list"}}`)
+	if !strings.Contains(res.Body.String(), `"error"`) || !strings.Contains(res.Body.String(), "not allowed") {
+		t.Fatalf("expected the call to be denied, got %s", res.Body.String())
+	}
+}
+
+func TestMcpEndpoint_Limits(t *testing.T) {
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	key, _, err := ext.MCPClientCreate(app, "Assistant", []string{"*"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	large := `{"jsonrpc":"2.0","id":1,"method":"ping","params":{"padding":"` + strings.Repeat("x", 1<<20) + `"}}`
+	if res := callMCP(app, links.MCP, key, large); res.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected %d for a large request, got %d", http.StatusRequestEntityTooLarge, res.Code)
+	}
+
+	if res := callMCP(app, links.MCP, key, `{"jsonrpc":"2.0","method":"notifications/initialized"}`); res.Code != http.StatusAccepted {
+		t.Fatalf("expected %d for a notification, got %d", http.StatusAccepted, res.Code)
+	}
+}
+
+func TestMcpEndpoint_Health(t *testing.T) {
+	router := rtr.NewRouter()
+	router.AddRoutes(Routes(testutils.Setup()))
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, links.MCP, nil))
+
+	if res.Code != http.StatusOK || res.Body.String() != "MCP is working" {
+		t.Fatalf("expected the health status, got %d %q", res.Code, res.Body.String())
+	}
+}
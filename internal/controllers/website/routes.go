@@ -12,7 +12,11 @@ import (
 	"project/internal/controllers/website/cms"
 	"project/internal/controllers/website/contact"
 	"project/internal/controllers/website/home"
+	"project/internal/controllers/website/mcp_endpoint"
 	"project/internal/controllers/website/seo"
+	"project/internal/controllers/website/shop/cart"
+	"project/internal/controllers/website/shop/catalogue"
+	"project/internal/controllers/website/shop/checkout"
 	"project/internal/controllers/website/swagger"
 )
 
@@ -44,13 +48,19 @@ func Routes(app app.AppInterface) []rtr.RouteInterface {
 		faviconRoute,
 	}
 
+	// Before the CMS pages, which catch all the paths
+	websiteRoutes = append(websiteRoutes, mcp_endpoint.Routes(app)...)
+
 	// Comment if you do not use the blog routes
 	websiteRoutes = append(websiteRoutes, blog.Routes(app)...)
 	websiteRoutes = append(websiteRoutes, contact.Routes(app)...)
 
-	// Comment if you do not use the payment routes
-	// websiteRoutes = append(websiteRoutes, paymentRoutes...)
-	// websiteRoutes = append(websiteRoutes, cart.Routes(app)...)
+	// Comment if you do not use the shop and payment routes
+	if app.GetShopStore() != nil {
+		websiteRoutes = append(websiteRoutes, catalogue.Routes(app)...)
+		websiteRoutes = append(websiteRoutes, cart.Routes(app)...)
+		websiteRoutes = append(websiteRoutes, checkout.Routes(app)...)
+	}
 	websiteRoutes = append(websiteRoutes, seo.Routes(app)...)
 	websiteRoutes = append(websiteRoutes, swagger.Routes()...)
 
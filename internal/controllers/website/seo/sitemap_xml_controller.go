@@ -10,6 +10,8 @@ import (
 
 	"github.com/dracory/blogstore"
 	"github.com/dracory/neat"
+	"github.com/dracory/shopstore"
+	"github.com/dracory/str"
 	"github.com/dromara/carbon/v2"
 	"github.com/samber/lo"
 )
@@ -43,6 +45,7 @@ func (c sitemapXmlController) buildSitemapXML(w http.ResponseWriter, r *http.Req
 	}
 
 	locations = append(locations, c.blogPostLocations()...)
+	locations = append(locations, c.productLocations()...)
 
 	timeNow := carbon.Now().ToIso8601String()
 
@@ -101,3 +104,34 @@ func (c sitemapXmlController) blogPostLocations() []string {
 
 	return postLocations
 }
+
+func (c sitemapXmlController) productLocations() []string {
+	if c.app == nil {
+		slog.Warn("At sitemapXmlController > productLocations", slog.String("reason", "app is not configured"))
+		return []string{}
+	}
+
+	if !c.app.GetConfig().GetShopStoreUsed() || c.app.GetShopStore() == nil {
+		return []string{}
+	}
+
+	productList, err := c.app.GetShopStore().ProductList(context.Background(), shopstore.NewProductQuery().
+		SetStatus(shopstore.PRODUCT_STATUS_ACTIVE).
+		SetLimit(1000))
+
+	if err != nil {
+		slog.Error("At sitemapXmlController > productLocations", slog.String("error", err.Error()))
+		return nil
+	}
+
+	productLocations := make([]string, 0, len(productList)+1)
+	if len(productList) > 0 {
+		productLocations = append(productLocations, links.Website().Shop())
+	}
+
+	lo.ForEach(productList, func(product shopstore.ProductInterface, index int) {
+		productLocations = append(productLocations, links.Website().ShopProduct(product.GetID(), str.Slugify(product.GetTitle(), '-'), nil))
+	})
+
+	return productLocations
+}
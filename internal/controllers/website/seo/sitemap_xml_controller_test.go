@@ -75,3 +75,27 @@ func TestSitemapXmlController_WithBlogStore(t *testing.T) {
 		t.Fatalf("expected sitemap to contain %s, got: %s", expectedLoc, body)
 	}
 }
+
+func TestSitemapXmlController_WithShopStore(t *testing.T) {
+	app := testutils.Setup(testutils.WithShopStore(true))
+
+	if _, err := testutils.SeedProduct(app.GetShopStore(), "1001", 10); err != nil {
+		t.Fatalf("failed to seed product: %v", err)
+	}
+
+	controller := NewSitemapXmlController(app)
+	body, response, err := test.CallStringEndpoint(http.MethodGet, controller.Handler, test.NewRequestOptions{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, response.StatusCode)
+	}
+
+	expectedLoc := "/shop/product/1001/test-product"
+	if !strings.Contains(body, expectedLoc) {
+		t.Fatalf("expected sitemap to contain %s, got: %s", expectedLoc, body)
+	}
+}
@@ -27,6 +27,14 @@ type CartItem struct {
 // Cart represents the user's shopping cart
 type Cart struct {
 	Items []CartItem `json:"items"`
+	// DiscountCode is the discount code entered by the buyer. It is only
+	// a reference, the discount is re-validated every time it is applied
+	DiscountCode string `json:"discount_code,omitempty"`
+	// ShippingCountry, ShippingRegion and ShippingMethod are the delivery
+	// choices of the buyer, used to price the shipping and the tax
+	ShippingCountry string `json:"shipping_country,omitempty"`
+	ShippingRegion  string `json:"shipping_region,omitempty"`
+	ShippingMethod  string `json:"shipping_method,omitempty"`
 }
 
 const (
@@ -68,115 +76,98 @@ func (controller *cartController) validateProductAndGetPrice(ctx context.Context
 	return product.GetTitle(), product.GetPrice(), imageURL, nil
 }
 
-// getCartFromCache retrieves cart from cache for guest users
+// getCartFromCache retrieves cart from cache for guest users, using the
+// cart ID held in the signed cart cookie
 func (controller *cartController) getCartFromCache(ctx context.Context, r *http.Request) Cart {
 	if controller.app.GetCacheStore() == nil {
 		controller.app.GetLogger().Warn("getCartFromCache: Cache store not available")
 		return Cart{Items: []CartItem{}}
 	}
 
-	cacheKey := helpers.GenerateCartCacheKey(r)
-	controller.app.GetLogger().Info("getCartFromCache: Generated cache key", slog.String("cache_key", cacheKey))
+	cartID := helpers.CartIDFromRequest(r, controller.cartSecret())
+	if cartID == "" {
+		return Cart{Items: []CartItem{}}
+	}
 
-	var cart Cart
-	cartData, err := controller.app.GetCacheStore().GetJSON(cacheKey, "")
+	cartJSON, err := controller.app.GetCacheStore().Get(helpers.CartCacheKey(cartID), "")
 	if err != nil {
 		controller.app.GetLogger().Warn("getCartFromCache: Failed to get cart from cache", slog.String("error", err.Error()))
 		return Cart{Items: []CartItem{}}
 	}
 
-	if cartData != nil {
-		if data, ok := cartData.(map[string]any); ok {
-			if items, ok := data["items"].([]any); ok {
-				for _, item := range items {
-					if itemMap, ok := item.(map[string]any); ok {
-						cart.Items = append(cart.Items, CartItem{
-							ProductID:   itemMap["product_id"].(string),
-							ProductName: itemMap["product_name"].(string),
-							Price:       itemMap["price"].(string),
-							Quantity:    int(itemMap["quantity"].(float64)),
-							ImageURL:    itemMap["image_url"].(string),
-						})
-					}
-				}
-			}
-		}
+	cart, err := cartDecode(cartJSON)
+	if err != nil {
+		controller.app.GetLogger().Warn("getCartFromCache: Failed to decode cart", slog.String("error", err.Error()))
+		return Cart{Items: []CartItem{}}
 	}
 
 	return cart
 }
 
-// saveCartToCache saves cart to cache for guest users
-func (controller *cartController) saveCartToCache(ctx context.Context, r *http.Request, cart Cart) error {
+// saveCartToCache saves cart to cache for guest users. A new signed cart
+// cookie is issued when the visitor does not have a valid one yet
+func (controller *cartController) saveCartToCache(ctx context.Context, w http.ResponseWriter, r *http.Request, cart Cart) error {
 	if controller.app.GetCacheStore() == nil {
 		return fmt.Errorf("cache store not available")
 	}
 
-	cacheKey := helpers.GenerateCartCacheKey(r)
-	err := controller.app.GetCacheStore().SetJSON(cacheKey, cart, 30*24*60*60) // 30 days in seconds
+	cartID := helpers.CartIDFromRequest(r, controller.cartSecret())
+	if cartID == "" {
+		newCartID, err := helpers.CartIDNew()
+		if err != nil {
+			return err
+		}
+		cartID = newCartID
+	}
+
+	err := controller.app.GetCacheStore().SetJSON(helpers.CartCacheKey(cartID), cart, helpers.CART_COOKIE_MAX_AGE)
 	if err != nil {
 		return err
 	}
 
+	// Always (re)issue the cookie, so its expiry slides with the cache entry
+	helpers.CartCookieSet(w, cartID, controller.cartSecret(), controller.cartCookieSecure())
+
 	return nil
 }
 
-// TransferCacheToUser transfers cart from cache to user metadata
-func (controller *cartController) TransferCacheToUser(ctx context.Context, r *http.Request, user userstore.UserInterface) error {
+// TransferCacheToUser merges the guest cart from cache into the user's cart
+// on login, then removes the guest cart and its cookie
+func (controller *cartController) TransferCacheToUser(ctx context.Context, w http.ResponseWriter, r *http.Request, user userstore.UserInterface) error {
 	if controller.app.GetCacheStore() == nil {
 		return fmt.Errorf("cache store not available")
 	}
 
+	cartID := helpers.CartIDFromRequest(r, controller.cartSecret())
+	if cartID == "" {
+		return nil // No guest cart to transfer
+	}
+
 	// Get cart from cache
 	cacheCart := controller.getCartFromCache(ctx, r)
 	controller.app.GetLogger().Info("TransferCacheToUser: Retrieved cart from cache", slog.Int("item_count", len(cacheCart.Items)))
 
-	if len(cacheCart.Items) == 0 {
-		controller.app.GetLogger().Info("TransferCacheToUser: No items in cache cart, skipping transfer")
-		return nil // No items to transfer
-	}
+	if len(cacheCart.Items) > 0 {
+		// Get existing user cart
+		userCart := controller.getCartFromUser(user)
+		controller.app.GetLogger().Info("TransferCacheToUser: Retrieved user cart", slog.Int("item_count", len(userCart.Items)))
 
-	// Get existing user cart
-	userCart := controller.getCartFromUser(user)
-	controller.app.GetLogger().Info("TransferCacheToUser: Retrieved user cart", slog.Int("item_count", len(userCart.Items)))
-
-	// Merge carts (cache cart takes precedence for quantities)
-	// Use slice for deterministic ordering
-	var mergedItems []CartItem
-	itemMap := make(map[string]int) // Maps productID to index in mergedItems
+		mergedCart := cartMerge(userCart, cacheCart)
+		controller.app.GetLogger().Info("TransferCacheToUser: Merged cart", slog.Int("total_items", len(mergedCart.Items)))
 
-	// Add user cart items first
-	for _, item := range userCart.Items {
-		if _, exists := itemMap[item.ProductID]; !exists {
-			itemMap[item.ProductID] = len(mergedItems)
-			mergedItems = append(mergedItems, item)
+		if err := controller.saveCartToUser(user, mergedCart); err != nil {
+			controller.app.GetLogger().Error("TransferCacheToUser: Failed to save merged cart", slog.String("error", err.Error()))
+			return err
 		}
 	}
 
-	// Add or update with cache cart items
-	for _, item := range cacheCart.Items {
-		if idx, exists := itemMap[item.ProductID]; exists {
-			// Update existing item quantity
-			mergedItems[idx].Quantity += item.Quantity
-		} else {
-			// Add new item
-			itemMap[item.ProductID] = len(mergedItems)
-			mergedItems = append(mergedItems, item)
-		}
+	// The guest cart now lives in the user metadata
+	if err := controller.app.GetCacheStore().Remove(helpers.CartCacheKey(cartID)); err != nil {
+		controller.app.GetLogger().Warn("TransferCacheToUser: Failed to remove guest cart", slog.String("error", err.Error()))
 	}
 
-	// Create merged cart with deterministic ordering
-	mergedCart := Cart{Items: mergedItems}
-	controller.app.GetLogger().Info("TransferCacheToUser: Merged cart", slog.Int("total_items", len(mergedCart.Items)))
+	helpers.CartCookieRemove(w, controller.cartCookieSecure())
 
-	// Save to user metadata and sync with cache
-	if err := controller.saveCartToUserWithCache(ctx, r, user, mergedCart); err != nil {
-		controller.app.GetLogger().Error("TransferCacheToUser: Failed to save merged cart", slog.String("error", err.Error()))
-		return err
-	}
-
-	controller.app.GetLogger().Info("TransferCacheToUser: Cart saved successfully to user metadata")
-	// Keep cache in sync with user meta - both will be used
 	return nil
 }
 
@@ -187,6 +178,16 @@ func NewCartController(app app.AppInterface) *cartController {
 	}
 }
 
+// GetCart returns the cart of the current visitor, read from the user metadata
+// for authenticated users and from the cache for guests
+func (controller *cartController) GetCart(ctx context.Context, r *http.Request) Cart {
+	if authUser := helpers.GetAuthUser(r); authUser != nil {
+		return controller.getCartFromUser(authUser)
+	}
+
+	return controller.getCartFromCache(ctx, r)
+}
+
 // ClearCart clears the cart from both user metadata and cache
 func (controller *cartController) ClearCart(ctx context.Context, r *http.Request, user userstore.UserInterface) error {
 	// Clear from user metadata
@@ -194,10 +195,10 @@ func (controller *cartController) ClearCart(ctx context.Context, r *http.Request
 		return err
 	}
 
-	// Clear from cache
-	if controller.app.GetCacheStore() != nil {
-		cacheKey := helpers.GenerateCartCacheKey(r)
-		controller.app.GetCacheStore().SetJSON(cacheKey, Cart{Items: []CartItem{}}, 30*24*60*60)
+	// Clear any guest cart left behind in the cache
+	cartID := helpers.CartIDFromRequest(r, controller.cartSecret())
+	if controller.app.GetCacheStore() != nil && cartID != "" {
+		controller.app.GetCacheStore().Remove(helpers.CartCacheKey(cartID))
 	}
 
 	return nil
@@ -249,9 +250,7 @@ func (controller *cartController) handleGetCart(ctx context.Context, w http.Resp
 	cart := controller.getCartFromUser(authUser)
 
 	w.Header().Set("Content-Type", "application/json")
-	response := api.SuccessWithData("Cart retrieved successfully", map[string]any{
-		"cart": cart,
-	})
+	response := api.SuccessWithData("Cart retrieved successfully", controller.cartResponseData(ctx, r, cart))
 	w.Write([]byte(response.ToString()))
 	return ""
 }
@@ -261,9 +260,7 @@ func (controller *cartController) handleGetCartGuest(ctx context.Context, w http
 	cart := controller.getCartFromCache(ctx, r)
 
 	w.Header().Set("Content-Type", "application/json")
-	response := api.SuccessWithData("Cart retrieved successfully", map[string]any{
-		"cart": cart,
-	})
+	response := api.SuccessWithData("Cart retrieved successfully", controller.cartResponseData(ctx, r, cart))
 	w.Write([]byte(response.ToString()))
 	return ""
 }
@@ -359,8 +356,12 @@ func (controller *cartController) handleAddToCart(ctx context.Context, w http.Re
 		})
 	}
 
-	// Save cart to user metadata and sync with cache
-	if err := controller.saveCartToUserWithCache(ctx, r, authUser, cart); err != nil {
+	if !controller.stockCheck(ctx, w, cart, reqBody.ProductID) {
+		return ""
+	}
+
+	// Save cart to user metadata
+	if err := controller.saveCartToUser(authUser, cart); err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"status":"error","message":"Failed to save cart"}`))
@@ -368,9 +369,7 @@ func (controller *cartController) handleAddToCart(ctx context.Context, w http.Re
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	response := api.SuccessWithData("Item added to cart", map[string]any{
-		"cart": cart,
-	})
+	response := api.SuccessWithData("Item added to cart", controller.cartResponseData(ctx, r, cart))
 	w.Write([]byte(response.ToString()))
 	return ""
 }
@@ -466,8 +465,12 @@ func (controller *cartController) handleAddToCartGuest(ctx context.Context, w ht
 		})
 	}
 
+	if !controller.stockCheck(ctx, w, cart, reqBody.ProductID) {
+		return ""
+	}
+
 	// Save cart to cache
-	if err := controller.saveCartToCache(ctx, r, cart); err != nil {
+	if err := controller.saveCartToCache(ctx, w, r, cart); err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"status":"error","message":"Failed to save cart"}`))
@@ -475,9 +478,7 @@ func (controller *cartController) handleAddToCartGuest(ctx context.Context, w ht
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	response := api.SuccessWithData("Item added to cart", map[string]any{
-		"cart": cart,
-	})
+	response := api.SuccessWithData("Item added to cart", controller.cartResponseData(ctx, r, cart))
 	w.Write([]byte(response.ToString()))
 	return ""
 }
@@ -516,8 +517,8 @@ func (controller *cartController) handleRemoveFromCart(ctx context.Context, w ht
 	}
 	cart.Items = updatedItems
 
-	// Save cart to user metadata and sync with cache
-	if err := controller.saveCartToUserWithCache(ctx, r, authUser, cart); err != nil {
+	// Save cart to user metadata
+	if err := controller.saveCartToUser(authUser, cart); err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"status":"error","message":"Failed to save cart"}`))
@@ -525,9 +526,7 @@ func (controller *cartController) handleRemoveFromCart(ctx context.Context, w ht
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	response := api.SuccessWithData("Item removed from cart", map[string]any{
-		"cart": cart,
-	})
+	response := api.SuccessWithData("Item removed from cart", controller.cartResponseData(ctx, r, cart))
 	w.Write([]byte(response.ToString()))
 	return ""
 }
@@ -567,7 +566,7 @@ func (controller *cartController) handleRemoveFromCartGuest(ctx context.Context,
 	cart.Items = updatedItems
 
 	// Save cart to cache
-	if err := controller.saveCartToCache(ctx, r, cart); err != nil {
+	if err := controller.saveCartToCache(ctx, w, r, cart); err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"status":"error","message":"Failed to save cart"}`))
@@ -575,9 +574,7 @@ func (controller *cartController) handleRemoveFromCartGuest(ctx context.Context,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	response := api.SuccessWithData("Item removed from cart", map[string]any{
-		"cart": cart,
-	})
+	response := api.SuccessWithData("Item removed from cart", controller.cartResponseData(ctx, r, cart))
 	w.Write([]byte(response.ToString()))
 	return ""
 }
@@ -629,8 +626,12 @@ func (controller *cartController) handleUpdateCart(ctx context.Context, w http.R
 	}
 	cart.Items = updatedItems
 
-	// Save cart to user metadata and sync with cache
-	if err := controller.saveCartToUserWithCache(ctx, r, authUser, cart); err != nil {
+	if !controller.stockCheck(ctx, w, cart, reqBody.ProductID) {
+		return ""
+	}
+
+	// Save cart to user metadata
+	if err := controller.saveCartToUser(authUser, cart); err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"status":"error","message":"Failed to save cart"}`))
@@ -638,9 +639,7 @@ func (controller *cartController) handleUpdateCart(ctx context.Context, w http.R
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	response := api.SuccessWithData("Cart updated", map[string]any{
-		"cart": cart,
-	})
+	response := api.SuccessWithData("Cart updated", controller.cartResponseData(ctx, r, cart))
 	w.Write([]byte(response.ToString()))
 	return ""
 }
@@ -692,8 +691,12 @@ func (controller *cartController) handleUpdateCartGuest(ctx context.Context, w h
 	}
 	cart.Items = updatedItems
 
+	if !controller.stockCheck(ctx, w, cart, reqBody.ProductID) {
+		return ""
+	}
+
 	// Save cart to cache
-	if err := controller.saveCartToCache(ctx, r, cart); err != nil {
+	if err := controller.saveCartToCache(ctx, w, r, cart); err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(`{"status":"error","message":"Failed to save cart"}`))
@@ -701,9 +704,7 @@ func (controller *cartController) handleUpdateCartGuest(ctx context.Context, w h
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	response := api.SuccessWithData("Cart updated", map[string]any{
-		"cart": cart,
-	})
+	response := api.SuccessWithData("Cart updated", controller.cartResponseData(ctx, r, cart))
 	w.Write([]byte(response.ToString()))
 	return ""
 }
@@ -745,19 +746,86 @@ func (controller *cartController) saveCartToUser(user userstore.UserInterface, c
 	return nil
 }
 
-// saveCartToUserWithCache saves the cart to user metadata and syncs with cache
-func (controller *cartController) saveCartToUserWithCache(ctx context.Context, r *http.Request, user userstore.UserInterface, cart Cart) error {
-	if err := controller.saveCartToUser(user, cart); err != nil {
-		return err
+// cartSecret returns the secret used to sign the guest cart cookie
+func (controller *cartController) cartSecret() string {
+	if controller.app.GetConfig() == nil {
+		return ""
+	}
+
+	return controller.app.GetConfig().GetCsrfSecret()
+}
+
+// cartCookieSecure reports whether the cart cookie must be sent over HTTPS only.
+// In development (HTTP), the Secure flag must be disabled.
+func (controller *cartController) cartCookieSecure() bool {
+	return controller.app.GetConfig() == nil || !controller.app.GetConfig().IsEnvDevelopment()
+}
+
+// cartDecode decodes a cart stored as JSON
+func cartDecode(cartJSON string) (Cart, error) {
+	if cartJSON == "" {
+		return Cart{Items: []CartItem{}}, nil
+	}
+
+	var cart Cart
+	if err := json.Unmarshal([]byte(cartJSON), &cart); err != nil {
+		return Cart{Items: []CartItem{}}, err
+	}
+
+	if cart.Items == nil {
+		cart.Items = []CartItem{}
+	}
+
+	return cart, nil
+}
+
+// cartMerge merges the guest cart into the user cart. Quantities of products
+// present in both carts are added up, within the per item and per cart limits.
+// Uses a slice for deterministic ordering
+func cartMerge(userCart Cart, guestCart Cart) Cart {
+	mergedItems := []CartItem{}
+	itemMap := make(map[string]int) // Maps productID to index in mergedItems
+
+	// Add user cart items first
+	for _, item := range userCart.Items {
+		if _, exists := itemMap[item.ProductID]; !exists {
+			itemMap[item.ProductID] = len(mergedItems)
+			mergedItems = append(mergedItems, item)
+		}
 	}
 
-	// Sync with cache for guest users or when cache is available
-	if controller.app.GetCacheStore() != nil {
-		cacheKey := helpers.GenerateCartCacheKey(r)
-		if err := controller.app.GetCacheStore().SetJSON(cacheKey, cart, 30*24*60*60); err != nil {
-			controller.app.GetLogger().Warn("Failed to sync cart to cache", slog.String("error", err.Error()))
+	// Add or update with guest cart items
+	for _, item := range guestCart.Items {
+		if idx, exists := itemMap[item.ProductID]; exists {
+			mergedItems[idx].Quantity = min(mergedItems[idx].Quantity+item.Quantity, maxQuantity)
+			continue
 		}
+
+		if len(mergedItems) >= maxItems {
+			continue
+		}
+
+		itemMap[item.ProductID] = len(mergedItems)
+		mergedItems = append(mergedItems, item)
 	}
 
-	return nil
+	merged := userCart
+	merged.Items = mergedItems
+
+	// A code entered as a guest takes precedence, the most recent intent
+	if guestCart.DiscountCode != "" {
+		merged.DiscountCode = guestCart.DiscountCode
+	}
+
+	// Likewise for the delivery choices, which are kept together
+	if guestCart.ShippingCountry != "" {
+		merged.ShippingCountry = guestCart.ShippingCountry
+		merged.ShippingRegion = guestCart.ShippingRegion
+	}
+
+	if guestCart.ShippingMethod != "" {
+		merged.ShippingMethod = guestCart.ShippingMethod
+	}
+
+	return merged
 }
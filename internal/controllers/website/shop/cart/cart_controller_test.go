@@ -0,0 +1,214 @@
+package cart
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/helpers"
+	"project/internal/testutils"
+
+	"github.com/dracory/test"
+)
+
+func setupCartApp(t *testing.T) app.AppInterface {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetShopStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetCsrfSecret("test-csrf-secret")
+
+	return testutils.Setup(testutils.WithCfg(cfg))
+}
+
+// guestAddToCart adds a product to the guest cart, sending the given cookies,
+// and returns the cookies set by the response
+func guestAddToCart(t *testing.T, app app.AppInterface, productID string, quantity int, cookies []*http.Cookie) []*http.Cookie {
+	t.Helper()
+
+	body := `{"product_id":"` + productID + `","quantity":` + strconv.Itoa(quantity) + `}`
+	req := httptest.NewRequest(http.MethodPost, "/cart", strings.NewReader(body))
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	NewCartController(app).Handler(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("add to cart status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+
+	return recorder.Result().Cookies()
+}
+
+func requestWithCookies(cookies []*http.Cookie) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+func TestGuestCart_UsesSignedCookie(t *testing.T) {
+	app := setupCartApp(t)
+
+	if _, err := testutils.SeedProduct(app.GetShopStore(), "product_1", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := guestAddToCart(t, app, "product_1", 1, nil)
+	if len(cookies) != 1 || cookies[0].Name != helpers.CART_COOKIE_NAME {
+		t.Fatalf("expected the cart cookie to be set, got %v", cookies)
+	}
+	if !cookies[0].HttpOnly {
+		t.Error("cart cookie must be HttpOnly")
+	}
+
+	// Same cookie, same cart
+	guestAddToCart(t, app, "product_1", 2, cookies)
+
+	basket := NewCartController(app).GetCart(context.Background(), requestWithCookies(cookies))
+	if len(basket.Items) != 1 || basket.Items[0].Quantity != 3 {
+		t.Fatalf("expected 1 item with quantity 3, got %+v", basket.Items)
+	}
+
+	// Another visitor from the same IP and browser gets an empty cart
+	other := NewCartController(app).GetCart(context.Background(), requestWithCookies(nil))
+	if len(other.Items) != 0 {
+		t.Fatalf("expected an empty cart without cookie, got %+v", other.Items)
+	}
+
+	// A forged cookie is ignored
+	forged := []*http.Cookie{{Name: helpers.CART_COOKIE_NAME, Value: strings.Split(cookies[0].Value, ".")[0] + ".forged"}}
+	forgedCart := NewCartController(app).GetCart(context.Background(), requestWithCookies(forged))
+	if len(forgedCart.Items) != 0 {
+		t.Fatalf("expected an empty cart for a forged cookie, got %+v", forgedCart.Items)
+	}
+}
+
+func TestTransferCacheToUser_MergesGuestCart(t *testing.T) {
+	app := setupCartApp(t)
+
+	for _, id := range []string{"product_1", "product_2"} {
+		if _, err := testutils.SeedProduct(app.GetShopStore(), id, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	user, err := testutils.SeedUser(app.GetUserStore(), test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userCartJSON, _ := json.Marshal(Cart{Items: []CartItem{{ProductID: "product_1", Price: "10.00", Quantity: 1}}})
+	if err := user.SetMeta(config.USER_META_CART, string(userCartJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := guestAddToCart(t, app, "product_1", 2, nil)
+	guestAddToCart(t, app, "product_2", 1, cookies)
+
+	recorder := httptest.NewRecorder()
+	controller := NewCartController(app)
+	if err := controller.TransferCacheToUser(context.Background(), recorder, requestWithCookies(cookies), user); err != nil {
+		t.Fatal(err)
+	}
+
+	merged := controller.getCartFromUser(user)
+	if len(merged.Items) != 2 {
+		t.Fatalf("expected 2 items, got %+v", merged.Items)
+	}
+	if merged.Items[0].ProductID != "product_1" || merged.Items[0].Quantity != 3 {
+		t.Errorf("expected product_1 with quantity 3, got %+v", merged.Items[0])
+	}
+	if merged.Items[1].ProductID != "product_2" || merged.Items[1].Quantity != 1 {
+		t.Errorf("expected product_2 with quantity 1, got %+v", merged.Items[1])
+	}
+
+	// Guest cart is gone and its cookie expired
+	guestCart := controller.getCartFromCache(context.Background(), requestWithCookies(cookies))
+	if len(guestCart.Items) != 0 {
+		t.Errorf("expected the guest cart to be removed, got %+v", guestCart.Items)
+	}
+
+	responseCookies := recorder.Result().Cookies()
+	if len(responseCookies) != 1 || responseCookies[0].MaxAge >= 0 {
+		t.Errorf("expected the cart cookie to be expired, got %v", responseCookies)
+	}
+}
+
+func TestTransferCacheToUser_NoCookie(t *testing.T) {
+	app := setupCartApp(t)
+
+	user, err := testutils.SeedUser(app.GetUserStore(), test.USER_01)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	if err := NewCartController(app).TransferCacheToUser(context.Background(), recorder, requestWithCookies(nil), user); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recorder.Result().Cookies()) != 0 {
+		t.Error("expected no cookies to be set")
+	}
+}
+
+func TestCartDecode(t *testing.T) {
+	cart, err := cartDecode(`{"items":[{"product_id":"p1","product_name":"Product","price":"1.50","quantity":2,"image_url":""}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cart.Items) != 1 || cart.Items[0].ProductID != "p1" || cart.Items[0].Quantity != 2 {
+		t.Errorf("unexpected cart %+v", cart)
+	}
+
+	// Missing fields no longer panic
+	cart, err = cartDecode(`{"items":[{"product_id":"p1"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cart.Items) != 1 || cart.Items[0].Price != "" {
+		t.Errorf("unexpected cart %+v", cart)
+	}
+
+	empty, err := cartDecode("")
+	if err != nil || empty.Items == nil || len(empty.Items) != 0 {
+		t.Errorf("expected an empty cart, got %+v, %v", empty, err)
+	}
+
+	if _, err := cartDecode(`{"items":"broken"}`); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestCartMerge_RespectsLimits(t *testing.T) {
+	userCart := Cart{Items: []CartItem{{ProductID: "p1", Quantity: maxQuantity - 1}}}
+	guestCart := Cart{Items: []CartItem{{ProductID: "p1", Quantity: 5}}}
+
+	merged := cartMerge(userCart, guestCart)
+	if merged.Items[0].Quantity != maxQuantity {
+		t.Errorf("quantity = %d, want %d", merged.Items[0].Quantity, maxQuantity)
+	}
+
+	full := Cart{}
+	for i := 0; i < maxItems; i++ {
+		full.Items = append(full.Items, CartItem{ProductID: strconv.Itoa(i), Quantity: 1})
+	}
+
+	merged = cartMerge(full, Cart{Items: []CartItem{{ProductID: "extra", Quantity: 1}}})
+	if len(merged.Items) != maxItems {
+		t.Errorf("items = %d, want %d", len(merged.Items), maxItems)
+	}
+}
@@ -0,0 +1,135 @@
+package cart
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"project/internal/discount"
+	"project/internal/helpers"
+	"time"
+
+	"github.com/dracory/api"
+)
+
+// msgDiscountUnavailable is shown when a code cannot be checked
+// for technical reasons
+const msgDiscountUnavailable = "The discount code could not be applied. Please try again later."
+
+// DiscountHandler applies (POST) or removes (DELETE) a discount code
+// to the cart of the current visitor
+func (controller *cartController) DiscountHandler(w http.ResponseWriter, r *http.Request) string {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodPost:
+		return controller.handleDiscountApply(ctx, w, r)
+	case http.MethodDelete:
+		return controller.handleDiscountRemove(ctx, w, r)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(`{"status":"error","message":"Method not allowed"}`))
+		return ""
+	}
+}
+
+// handleDiscountApply validates the code against the current cart,
+// and stores it in the cart when it can be used
+func (controller *cartController) handleDiscountApply(ctx context.Context, w http.ResponseWriter, r *http.Request) string {
+	var reqBody struct {
+		Code string `json:"code"`
+	}
+
+	defer r.Body.Close()
+	r.Body = http.MaxBytesReader(nil, r.Body, maxBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error","message":"Invalid request body"}`))
+		return ""
+	}
+
+	code := discount.NormalizeCode(reqBody.Code)
+	if code == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error","message":"Discount code is required"}`))
+		return ""
+	}
+
+	cart := controller.GetCart(ctx, r)
+	currency := controller.app.GetConfig().GetPaymentCurrency()
+
+	application, err := controller.discountApply(r, code, controller.cartSubtotal(cart, currency), currency)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		if discount.IsRejection(err) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(api.Error(err.Error()).ToString()))
+			return ""
+		}
+
+		controller.app.GetLogger().Error("At cartController > handleDiscountApply", slog.String("error", err.Error()))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(api.Error(msgDiscountUnavailable).ToString()))
+		return ""
+	}
+
+	cart.DiscountCode = application.Discount.Code
+
+	if err := controller.saveCart(ctx, w, r, cart); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error","message":"Failed to save cart"}`))
+		return ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := api.SuccessWithData("Discount code applied", controller.cartResponseData(ctx, r, cart))
+	w.Write([]byte(response.ToString()))
+	return ""
+}
+
+// handleDiscountRemove removes the discount code from the cart
+func (controller *cartController) handleDiscountRemove(ctx context.Context, w http.ResponseWriter, r *http.Request) string {
+	cart := controller.GetCart(ctx, r)
+	cart.DiscountCode = ""
+
+	if err := controller.saveCart(ctx, w, r, cart); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error","message":"Failed to save cart"}`))
+		return ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := api.SuccessWithData("Discount code removed", controller.cartResponseData(ctx, r, cart))
+	w.Write([]byte(response.ToString()))
+	return ""
+}
+
+// saveCart saves the cart to the user metadata for authenticated users,
+// and to the cache for guests
+func (controller *cartController) saveCart(ctx context.Context, w http.ResponseWriter, r *http.Request, cart Cart) error {
+	if authUser := helpers.GetAuthUser(r); authUser != nil {
+		return controller.saveCartToUser(authUser, cart)
+	}
+
+	return controller.saveCartToCache(ctx, w, r, cart)
+}
+
+// discountApply checks the code server side, for the current visitor
+func (controller *cartController) discountApply(r *http.Request, code string, subtotal int64, currency string) (*discount.Application, error) {
+	discountStore, err := discount.NewStore(controller.app.GetCustomStore())
+	if err != nil {
+		return nil, err
+	}
+
+	userID := ""
+	if authUser := helpers.GetAuthUser(r); authUser != nil {
+		userID = authUser.GetID()
+	}
+
+	return discount.Apply(discountStore, code, userID, subtotal, currency, time.Now().UTC())
+}
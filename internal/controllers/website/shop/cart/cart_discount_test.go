@@ -0,0 +1,116 @@
+package cart
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"project/internal/discount"
+	"project/internal/testutils"
+)
+
+// discountRequest calls the discount endpoint as the guest owning the cookies
+func discountRequest(t *testing.T, controller *cartController, method string, body string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, "/cart/discount", strings.NewReader(body))
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	controller.DiscountHandler(recorder, req)
+
+	return recorder
+}
+
+func TestDiscountHandler_ApplyAndRemove(t *testing.T) {
+	app := setupCartApp(t)
+
+	if _, err := testutils.SeedProduct(app.GetShopStore(), "product_1", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	discountStore, err := discount.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := discountStore.DiscountCreate(&discount.Discount{Code: "SAVE10", Status: discount.STATUS_ACTIVE, Type: discount.TYPE_PERCENT, Value: "10"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := guestAddToCart(t, app, "product_1", 2, nil)
+	controller := NewCartController(app)
+
+	recorder := discountRequest(t, controller, http.MethodPost, `{"code":" save10 "}`, cookies)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("apply status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+
+	var response struct {
+		Data struct {
+			Totals CartTotals `json:"totals"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Data.Totals.Subtotal != "20.00" || response.Data.Totals.Discount != "2.00" || response.Data.Totals.Total != "18.00" {
+		t.Errorf("unexpected totals %+v", response.Data.Totals)
+	}
+
+	basket := controller.GetCart(context.Background(), requestWithCookies(cookies))
+	if basket.DiscountCode != "SAVE10" {
+		t.Fatalf("expected the code to be stored in the cart, got %q", basket.DiscountCode)
+	}
+
+	recorder = discountRequest(t, controller, http.MethodDelete, "", cookies)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("remove status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+
+	basket = controller.GetCart(context.Background(), requestWithCookies(cookies))
+	if basket.DiscountCode != "" {
+		t.Errorf("expected the code to be removed, got %q", basket.DiscountCode)
+	}
+}
+
+func TestDiscountHandler_RejectsInvalidCode(t *testing.T) {
+	app := setupCartApp(t)
+
+	if _, err := testutils.SeedProduct(app.GetShopStore(), "product_1", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := guestAddToCart(t, app, "product_1", 1, nil)
+	controller := NewCartController(app)
+
+	recorder := discountRequest(t, controller, http.MethodPost, `{"code":"UNKNOWN"}`, cookies)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+
+	if !strings.Contains(recorder.Body.String(), discount.ErrNotFound.Error()) {
+		t.Errorf("expected the rejection reason, got %s", recorder.Body.String())
+	}
+
+	basket := controller.GetCart(context.Background(), requestWithCookies(cookies))
+	if basket.DiscountCode != "" {
+		t.Errorf("an invalid code must not be stored, got %q", basket.DiscountCode)
+	}
+}
+
+func TestDiscountHandler_MethodNotAllowed(t *testing.T) {
+	app := setupCartApp(t)
+
+	recorder := discountRequest(t, NewCartController(app), http.MethodGet, "", nil)
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
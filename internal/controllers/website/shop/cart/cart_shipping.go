@@ -0,0 +1,100 @@
+package cart
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/dracory/api"
+)
+
+var countryCodeRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// ShippingHandler sets (POST) the delivery country, region and shipping
+// method of the cart of the current visitor, and returns the repriced cart
+func (controller *cartController) ShippingHandler(w http.ResponseWriter, r *http.Request) string {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(`{"status":"error","message":"Method not allowed"}`))
+		return ""
+	}
+
+	return controller.handleShippingUpdate(r.Context(), w, r)
+}
+
+// handleShippingUpdate validates the delivery address and stores it
+// in the cart together with the chosen shipping method
+func (controller *cartController) handleShippingUpdate(ctx context.Context, w http.ResponseWriter, r *http.Request) string {
+	var reqBody struct {
+		Country string `json:"country"`
+		Region  string `json:"region"`
+		Method  string `json:"method"`
+	}
+
+	defer r.Body.Close()
+	r.Body = http.MaxBytesReader(nil, r.Body, maxBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error","message":"Invalid request body"}`))
+		return ""
+	}
+
+	country := strings.ToUpper(strings.TrimSpace(reqBody.Country))
+	region := strings.ToUpper(strings.TrimSpace(reqBody.Region))
+
+	if !controller.countryValid(ctx, country) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error","message":"A valid country is required"}`))
+		return ""
+	}
+
+	if len(region) > 10 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error","message":"Invalid region"}`))
+		return ""
+	}
+
+	cart := controller.GetCart(ctx, r)
+	cart.ShippingCountry = country
+	cart.ShippingRegion = region
+	cart.ShippingMethod = strings.TrimSpace(reqBody.Method)
+
+	if err := controller.saveCart(ctx, w, r, cart); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error","message":"Failed to save cart"}`))
+		return ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := api.SuccessWithData("Shipping updated", controller.cartResponseData(ctx, r, cart))
+	w.Write([]byte(response.ToString()))
+	return ""
+}
+
+// countryValid checks the country is a 2 letter ISO code, known
+// to the geo store when the geo store is used
+func (controller *cartController) countryValid(ctx context.Context, country string) bool {
+	if !countryCodeRegex.MatchString(country) {
+		return false
+	}
+
+	if controller.app.GetGeoStore() == nil {
+		return true
+	}
+
+	found, err := controller.app.GetGeoStore().CountryFindByIso2(ctx, country)
+	if err != nil {
+		controller.app.GetLogger().Error("At cartController > countryValid", slog.String("error", err.Error()))
+		return false
+	}
+
+	return found != nil
+}
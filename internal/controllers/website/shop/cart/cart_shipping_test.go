@@ -0,0 +1,81 @@
+package cart
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"project/internal/testutils"
+)
+
+// shippingRequest calls the shipping endpoint as the guest owning the cookies
+func shippingRequest(t *testing.T, controller *cartController, method string, body string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, "/cart/shipping", strings.NewReader(body))
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	controller.ShippingHandler(recorder, req)
+
+	return recorder
+}
+
+func TestShippingHandler_UpdatesCart(t *testing.T) {
+	app := setupCartApp(t)
+	setupPricing(t, app)
+
+	if _, err := testutils.SeedProduct(app.GetShopStore(), "product_1", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := guestAddToCart(t, app, "product_1", 1, nil)
+	controller := NewCartController(app)
+
+	recorder := shippingRequest(t, controller, http.MethodPost, `{"country":"gb","method":"express"}`, cookies)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body.String())
+	}
+
+	var response struct {
+		Data struct {
+			Totals CartTotals `json:"totals"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Data.Totals.ShippingMethod != "express" || response.Data.Totals.Total != "22.80" {
+		t.Errorf("unexpected totals %+v", response.Data.Totals)
+	}
+
+	basket := controller.GetCart(context.Background(), requestWithCookies(cookies))
+	if basket.ShippingCountry != "GB" || basket.ShippingMethod != "express" {
+		t.Errorf("expected the shipping to be stored in the cart, got %q %q", basket.ShippingCountry, basket.ShippingMethod)
+	}
+}
+
+func TestShippingHandler_RejectsInvalidCountry(t *testing.T) {
+	app := setupCartApp(t)
+
+	recorder := shippingRequest(t, NewCartController(app), http.MethodPost, `{"country":"Great Britain"}`, nil)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestShippingHandler_MethodNotAllowed(t *testing.T) {
+	app := setupCartApp(t)
+
+	recorder := shippingRequest(t, NewCartController(app), http.MethodGet, "", nil)
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
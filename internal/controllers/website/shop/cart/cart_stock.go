@@ -0,0 +1,63 @@
+package cart
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"project/internal/inventory"
+	"time"
+
+	"github.com/dracory/api"
+)
+
+// stockCheck checks the quantity of the product in the cart is in stock,
+// when the stock is tracked. When it is not, the error response is written
+// and false is returned
+func (controller *cartController) stockCheck(ctx context.Context, w http.ResponseWriter, cart Cart, productID string) bool {
+	if !controller.app.GetConfig().GetShopStockTrackingEnabled() {
+		return true
+	}
+
+	quantity := int64(0)
+	title := ""
+	for _, item := range cart.Items {
+		if item.ProductID == productID {
+			quantity += int64(item.Quantity)
+			title = item.ProductName
+		}
+	}
+
+	if quantity == 0 {
+		return true
+	}
+
+	err := controller.stockAvailable(ctx, []inventory.Item{{ProductID: productID, Title: title, Quantity: quantity}})
+
+	if err == nil {
+		return true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if inventory.IsOutOfStock(err) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(api.Error(err.Error()).ToString()))
+		return false
+	}
+
+	controller.app.GetLogger().Error("At cartController > stockCheck", slog.String("error", err.Error()), slog.String("product_id", productID))
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write([]byte(`{"status":"error","message":"Failed to check the stock"}`))
+	return false
+}
+
+// stockAvailable checks there is enough stock for the items, taking
+// into account the stock reserved for the orders awaiting payment
+func (controller *cartController) stockAvailable(ctx context.Context, items []inventory.Item) error {
+	reservationStore, err := inventory.NewStore(controller.app.GetCustomStore())
+	if err != nil {
+		return err
+	}
+
+	return inventory.Check(ctx, controller.app.GetShopStore(), reservationStore, items, time.Now().UTC())
+}
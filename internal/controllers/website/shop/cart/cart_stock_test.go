@@ -0,0 +1,72 @@
+package cart
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"project/internal/testutils"
+)
+
+func TestCartStock_AddBeyondStockIsRejected(t *testing.T) {
+	app := setupCartApp(t)
+	app.GetConfig().SetShopStockTrackingEnabled(true)
+
+	// SeedProduct creates the product with a quantity of 10
+	if _, err := testutils.SeedProduct(app.GetShopStore(), "product_1", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := guestAddToCart(t, app, "product_1", 8, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/cart", strings.NewReader(`{"product_id":"product_1","quantity":3}`))
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	NewCartController(app).Handler(recorder, req)
+
+	if recorder.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d, body = %s", recorder.Code, http.StatusConflict, recorder.Body.String())
+	}
+
+	if !strings.Contains(recorder.Body.String(), "Only 10 of Test Product left in stock") {
+		t.Errorf("expected the out of stock message, got %s", recorder.Body.String())
+	}
+}
+
+func TestCartStock_UpdateBeyondStockIsRejected(t *testing.T) {
+	app := setupCartApp(t)
+	app.GetConfig().SetShopStockTrackingEnabled(true)
+
+	if _, err := testutils.SeedProduct(app.GetShopStore(), "product_1", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := guestAddToCart(t, app, "product_1", 1, nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/cart", strings.NewReader(`{"product_id":"product_1","quantity":11}`))
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	NewCartController(app).Handler(recorder, req)
+
+	if recorder.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d, body = %s", recorder.Code, http.StatusConflict, recorder.Body.String())
+	}
+}
+
+func TestCartStock_NotTrackedByDefault(t *testing.T) {
+	app := setupCartApp(t)
+
+	if _, err := testutils.SeedProduct(app.GetShopStore(), "product_1", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// More than the product quantity, accepted as the stock is not tracked
+	guestAddToCart(t, app, "product_1", 20, nil)
+}
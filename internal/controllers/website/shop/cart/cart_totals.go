@@ -0,0 +1,206 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"project/internal/discount"
+	"project/internal/helpers"
+	"project/internal/payment"
+	"project/internal/pricing"
+	"strings"
+)
+
+// msgPricingUnavailable is shown when the shipping and tax cannot be
+// calculated for technical reasons
+const msgPricingUnavailable = "Shipping and tax could not be calculated. Please try again later."
+
+// CartTotals are the cart amounts shown to the buyer, formatted in the shop
+// currency. They are indicative only, the checkout re-validates the prices,
+// the discount, the shipping and the tax
+type CartTotals struct {
+	Currency string `json:"currency"`
+	Subtotal string `json:"subtotal"`
+	Discount string `json:"discount"`
+	Shipping string `json:"shipping"`
+	Tax      string `json:"tax"`
+	Total    string `json:"total"`
+
+	// Lines is the line by line breakdown of the adjustments to the subtotal
+	Lines []CartTotalsLine `json:"lines"`
+
+	DiscountCode  string `json:"discount_code,omitempty"`
+	DiscountError string `json:"discount_error,omitempty"`
+
+	ShippingCountry string               `json:"shipping_country,omitempty"`
+	ShippingRegion  string               `json:"shipping_region,omitempty"`
+	ShippingMethod  string               `json:"shipping_method,omitempty"`
+	ShippingOptions []CartShippingOption `json:"shipping_options"`
+	ShippingError   string               `json:"shipping_error,omitempty"`
+}
+
+// CartTotalsLine is a line of the price breakdown
+type CartTotalsLine struct {
+	Type   string `json:"type"`
+	Code   string `json:"code"`
+	Label  string `json:"label"`
+	Amount string `json:"amount"`
+	// Included lines (e.g. VAT in tax inclusive prices) are not
+	// added to the total
+	Included bool `json:"included"`
+}
+
+// CartShippingOption is a shipping method the buyer can choose
+type CartShippingOption struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Amount string `json:"amount"`
+}
+
+// ShippingAddress returns the delivery address used to price the cart: the
+// one chosen in the cart, otherwise the country of the authenticated user
+func (controller *cartController) ShippingAddress(r *http.Request, cart Cart) pricing.Address {
+	if cart.ShippingCountry != "" {
+		return pricing.Address{Country: cart.ShippingCountry, Region: cart.ShippingRegion}
+	}
+
+	if authUser := helpers.GetAuthUser(r); authUser != nil {
+		return pricing.Address{Country: strings.ToUpper(authUser.GetCountry())}
+	}
+
+	return pricing.Address{}
+}
+
+// cartResponseData is the data returned by the cart API endpoints
+func (controller *cartController) cartResponseData(ctx context.Context, r *http.Request, cart Cart) map[string]any {
+	return map[string]any{
+		"cart":   cart,
+		"totals": controller.cartTotals(ctx, r, cart),
+	}
+}
+
+// cartTotals prices the cart: the discount code stored in the cart if it can
+// still be used, then the shipping and the tax from the pricing calculator
+func (controller *cartController) cartTotals(ctx context.Context, r *http.Request, cart Cart) CartTotals {
+	currency := controller.app.GetConfig().GetPaymentCurrency()
+	quote := pricing.NewQuote(currency, controller.ShippingAddress(r, cart), controller.cartQuoteItems(ctx, cart, currency))
+	quote.ShippingMethod = cart.ShippingMethod
+
+	totals := CartTotals{
+		Currency:        currency,
+		ShippingCountry: quote.Address.Country,
+		ShippingRegion:  quote.Address.Region,
+	}
+
+	if cart.DiscountCode != "" {
+		application, err := controller.discountApply(r, cart.DiscountCode, quote.Subtotal(), currency)
+
+		switch {
+		case err == nil:
+			totals.DiscountCode = application.Discount.Code
+			quote.AddLine(pricing.Line{
+				Type:   pricing.LINE_TYPE_DISCOUNT,
+				Code:   application.Discount.Code,
+				Label:  "Discount (" + application.Discount.Code + ")",
+				Amount: -application.Amount,
+			})
+		case discount.IsRejection(err):
+			totals.DiscountError = err.Error()
+		default:
+			controller.app.GetLogger().Error("At cartController > cartTotals", slog.String("error", err.Error()))
+			totals.DiscountError = msgDiscountUnavailable
+		}
+	}
+
+	if calculator := controller.app.GetPricingCalculator(); calculator != nil {
+		if err := calculator.Calculate(ctx, quote); err != nil {
+			if errors.Is(err, pricing.ErrShippingUnavailable) {
+				totals.ShippingError = pricing.ErrShippingUnavailable.Error()
+			} else {
+				controller.app.GetLogger().Error("At cartController > cartTotals > Calculate", slog.String("error", err.Error()))
+				totals.ShippingError = msgPricingUnavailable
+			}
+		}
+	}
+
+	totals.Subtotal = payment.FormatMinorUnits(quote.Subtotal(), currency)
+	totals.Discount = payment.FormatMinorUnits(-quote.Amount(pricing.LINE_TYPE_DISCOUNT), currency)
+	totals.Shipping = payment.FormatMinorUnits(quote.Amount(pricing.LINE_TYPE_SHIPPING), currency)
+	totals.Tax = payment.FormatMinorUnits(quote.Amount(pricing.LINE_TYPE_TAX), currency)
+	totals.Total = payment.FormatMinorUnits(quote.Total(), currency)
+	totals.ShippingMethod = quote.ShippingMethod
+
+	totals.Lines = []CartTotalsLine{}
+	for _, line := range quote.Lines {
+		totals.Lines = append(totals.Lines, CartTotalsLine{
+			Type:     line.Type,
+			Code:     line.Code,
+			Label:    line.Label,
+			Amount:   payment.FormatMinorUnits(line.Amount, currency),
+			Included: line.Included,
+		})
+	}
+
+	totals.ShippingOptions = []CartShippingOption{}
+	for _, option := range quote.ShippingOptions {
+		totals.ShippingOptions = append(totals.ShippingOptions, CartShippingOption{
+			Code:   option.Code,
+			Name:   option.Name,
+			Amount: payment.FormatMinorUnits(option.Amount, currency),
+		})
+	}
+
+	return totals
+}
+
+// cartQuoteItems converts the cart items to pricing items. The prices were
+// validated against the shop store when the items were added, the weights
+// are read from the shop store as they are not kept in the cart
+func (controller *cartController) cartQuoteItems(ctx context.Context, cart Cart, currency string) []pricing.Item {
+	items := []pricing.Item{}
+
+	for _, item := range cart.Items {
+		unitAmount, err := payment.ToMinorUnits(item.Price, currency)
+		if err != nil {
+			controller.app.GetLogger().Warn("At cartController > cartQuoteItems", slog.String("error", err.Error()), slog.String("product_id", item.ProductID))
+			continue
+		}
+
+		weight := int64(0)
+		if controller.app.GetShopStore() != nil {
+			product, err := controller.app.GetShopStore().ProductFindByID(ctx, item.ProductID)
+			if err != nil {
+				controller.app.GetLogger().Warn("At cartController > cartQuoteItems > ProductFindByID", slog.String("error", err.Error()), slog.String("product_id", item.ProductID))
+			}
+			weight = helpers.ProductWeight(product)
+		}
+
+		items = append(items, pricing.Item{
+			ProductID:  item.ProductID,
+			Name:       item.ProductName,
+			Quantity:   item.Quantity,
+			UnitAmount: unitAmount,
+			Weight:     weight,
+		})
+	}
+
+	return items
+}
+
+// cartSubtotal returns the sum of the cart items in minor units
+func (controller *cartController) cartSubtotal(cart Cart, currency string) int64 {
+	subtotal := int64(0)
+
+	for _, item := range cart.Items {
+		unitAmount, err := payment.ToMinorUnits(item.Price, currency)
+		if err != nil {
+			controller.app.GetLogger().Warn("At cartController > cartSubtotal", slog.String("error", err.Error()), slog.String("product_id", item.ProductID))
+			continue
+		}
+
+		subtotal += unitAmount * int64(item.Quantity)
+	}
+
+	return subtotal
+}
@@ -0,0 +1,100 @@
+package cart
+
+import (
+	"context"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/pricing"
+	"project/internal/testutils"
+)
+
+// setupPricing configures standard shipping to GB, free over 50.00,
+// and 20% VAT on top of the prices
+func setupPricing(t *testing.T, app app.AppInterface) {
+	t.Helper()
+
+	methods, err := pricing.ParseShippingMethods(`[{"code":"standard","name":"Standard","countries":["GB"],"tiers":[{"min_subtotal":"50.00","price":"0"},{"price":"5.00"}]},{"code":"express","name":"Express","countries":["GB"],"tiers":[{"price":"9.00"}]}]`, app.GetConfig().GetPaymentCurrency())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rates, err := pricing.ParseTaxRates(`[{"country":"GB","rate":"20","label":"VAT"}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.SetPricingCalculator(pricing.NewPipeline(
+		pricing.NewShippingCalculator(methods),
+		pricing.NewTaxCalculator(rates, false),
+	))
+}
+
+func TestCartTotals_ShippingAndTax(t *testing.T) {
+	app := setupCartApp(t)
+	setupPricing(t, app)
+
+	if _, err := testutils.SeedProduct(app.GetShopStore(), "product_1", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	controller := NewCartController(app)
+	cart := Cart{
+		Items:           []CartItem{{ProductID: "product_1", ProductName: "Test Product", Price: "10.00", Quantity: 2}},
+		ShippingCountry: "GB",
+	}
+
+	totals := controller.cartTotals(context.Background(), requestWithCookies(nil), cart)
+
+	if totals.Subtotal != "20.00" || totals.Shipping != "5.00" || totals.Tax != "5.00" || totals.Total != "30.00" {
+		t.Errorf("unexpected totals %+v", totals)
+	}
+
+	if totals.ShippingMethod != "standard" || len(totals.ShippingOptions) != 2 {
+		t.Errorf("expected standard shipping with 2 options, got %q %+v", totals.ShippingMethod, totals.ShippingOptions)
+	}
+
+	if len(totals.Lines) != 2 || totals.Lines[1].Label != "VAT (20%)" {
+		t.Errorf("unexpected lines %+v", totals.Lines)
+	}
+
+	cart.ShippingMethod = "express"
+	totals = controller.cartTotals(context.Background(), requestWithCookies(nil), cart)
+
+	if totals.ShippingMethod != "express" || totals.Shipping != "9.00" || totals.Total != "34.80" {
+		t.Errorf("unexpected express totals %+v", totals)
+	}
+}
+
+func TestCartTotals_ShippingUnavailable(t *testing.T) {
+	app := setupCartApp(t)
+	setupPricing(t, app)
+
+	controller := NewCartController(app)
+	cart := Cart{
+		Items:           []CartItem{{ProductID: "product_1", ProductName: "Test Product", Price: "10.00", Quantity: 1}},
+		ShippingCountry: "FR",
+	}
+
+	totals := controller.cartTotals(context.Background(), requestWithCookies(nil), cart)
+
+	if totals.ShippingError != pricing.ErrShippingUnavailable.Error() {
+		t.Errorf("expected the shipping error, got %q", totals.ShippingError)
+	}
+
+	if totals.Total != "10.00" || totals.Tax != "0.00" {
+		t.Errorf("unexpected totals %+v", totals)
+	}
+}
+
+func TestCartTotals_NoPricingCalculator(t *testing.T) {
+	app := setupCartApp(t)
+	app.SetPricingCalculator(nil)
+
+	cart := Cart{Items: []CartItem{{ProductID: "product_1", Price: "2.50", Quantity: 3}}}
+	totals := NewCartController(app).cartTotals(context.Background(), requestWithCookies(nil), cart)
+
+	if totals.Subtotal != "7.50" || totals.Total != "7.50" || len(totals.Lines) != 0 {
+		t.Errorf("unexpected totals %+v", totals)
+	}
+}
@@ -2,6 +2,7 @@ package cart
 
 import (
 	"project/internal/app"
+	"project/internal/links"
 
 	"github.com/dracory/rtr"
 )
@@ -19,7 +20,19 @@ func Routes(app app.AppInterface) []rtr.RouteInterface {
 		SetPath("/shop/cart/api").
 		SetHTMLHandler(cartController.Handler)
 
+	cartDiscountRoute := rtr.NewRoute().
+		SetName("Website > Shop Cart Discount").
+		SetPath(links.SHOP_CART_DISCOUNT).
+		SetHTMLHandler(cartController.DiscountHandler)
+
+	cartShippingRoute := rtr.NewRoute().
+		SetName("Website > Shop Cart Shipping").
+		SetPath(links.SHOP_CART_SHIPPING).
+		SetHTMLHandler(cartController.ShippingHandler)
+
 	return []rtr.RouteInterface{
 		cartAPIRoute,
+		cartDiscountRoute,
+		cartShippingRoute,
 	}
 }
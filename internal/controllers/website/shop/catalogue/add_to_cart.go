@@ -0,0 +1,88 @@
+package catalogue
+
+import (
+	"project/internal/links"
+
+	"github.com/dracory/hb"
+	"github.com/dracory/shopstore"
+)
+
+// addToCartForm returns the add to cart button of the product, with a
+// quantity field when withQuantity is set. Out of stock products get a
+// disabled button instead
+func addToCartForm(product shopstore.ProductInterface, inStock bool, withQuantity bool) hb.TagInterface {
+	if !inStock {
+		return hb.Button().
+			Type(hb.TYPE_BUTTON).
+			Class("btn btn-secondary").
+			Attr("disabled", "disabled").
+			Text("Out of Stock")
+	}
+
+	button := hb.Button().
+		Type(hb.TYPE_SUBMIT).
+		Class("btn btn-primary").
+		Child(hb.I().Class("bi bi-cart-plus me-2")).
+		Text("Add to Cart")
+
+	quantity := hb.Input().
+		Type(hb.TYPE_HIDDEN).
+		Name("quantity").
+		Value("1")
+
+	if withQuantity {
+		quantity = hb.Input().
+			Type(hb.TYPE_NUMBER).
+			Class("form-control").
+			Style("max-width:90px;").
+			Name("quantity").
+			Value("1").
+			Attr("min", "1").
+			Attr("max", "999").
+			Attr("aria-label", "Quantity")
+	}
+
+	return hb.Form().
+		Class("ShopAddToCart d-flex gap-2 align-items-center").
+		Data("product-id", product.GetID()).
+		Child(quantity).
+		Child(button).
+		Child(hb.Span().Class("ShopAddToCartMessage small"))
+}
+
+// addToCartScript posts the add to cart forms to the cart API
+func addToCartScript() string {
+	return `
+document.querySelectorAll('form.ShopAddToCart').forEach(function (form) {
+	form.addEventListener('submit', function (event) {
+		event.preventDefault();
+
+		var message = form.querySelector('.ShopAddToCartMessage');
+		var button = form.querySelector('button[type=submit]');
+		var quantity = parseInt(form.querySelector('input[name=quantity]').value, 10) || 1;
+
+		button.disabled = true;
+		message.className = 'ShopAddToCartMessage small';
+		message.textContent = '';
+
+		fetch('` + links.SHOP_CART_API + `', {
+			method: 'POST',
+			headers: {'Content-Type': 'application/json'},
+			credentials: 'same-origin',
+			body: JSON.stringify({product_id: form.dataset.productId, quantity: quantity})
+		}).then(function (response) {
+			return response.json();
+		}).then(function (result) {
+			var success = result && result.status === 'success';
+			message.className = 'ShopAddToCartMessage small ' + (success ? 'text-success' : 'text-danger');
+			message.textContent = success ? 'Added to cart' : ((result && result.message) || 'The product could not be added to the cart');
+		}).catch(function () {
+			message.className = 'ShopAddToCartMessage small text-danger';
+			message.textContent = 'The product could not be added to the cart';
+		}).finally(function () {
+			button.disabled = false;
+		});
+	});
+});
+`
+}
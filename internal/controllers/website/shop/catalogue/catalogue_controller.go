@@ -0,0 +1,303 @@
+package catalogue
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"sort"
+	"strings"
+
+	"github.com/dracory/bs"
+	"github.com/dracory/cdn"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dracory/rtr"
+	"github.com/dracory/shopstore"
+	"github.com/dracory/str"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// maxCatalogueProducts is the maximum number of active products loaded to
+// filter the catalogue
+const maxCatalogueProducts = 1000
+
+// maxSearchLength is the maximum length of the search phrase
+const maxSearchLength = 100
+
+// == CONTROLLER ==============================================================
+
+// catalogueController lists the active products of the shop, optionally
+// filtered by category and by a search phrase
+type catalogueController struct {
+	app app.AppInterface
+}
+
+type catalogueControllerData struct {
+	currency     string
+	categories   []shopstore.CategoryInterface
+	category     shopstore.CategoryInterface
+	search       string
+	productList  []shopstore.ProductInterface
+	productCount int
+	page         int
+	perPage      int
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewCatalogueController creates a new catalogue controller
+func NewCatalogueController(app app.AppInterface) *catalogueController {
+	return &catalogueController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *catalogueController) Handler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.Website().Home(), 10)
+	}
+
+	title := "Shop"
+	canonicalURL := links.Website().Shop()
+	if data.category != nil {
+		title = data.category.GetTitle()
+		canonicalURL = controller.categoryURL(data.category, nil)
+	}
+
+	return layouts.NewPageLayout(controller.app, r, layouts.Options{
+		WebsiteSection: "Shop",
+		Title:          title,
+		Content:        controller.page(r.Context(), data),
+		CanonicalURL:   canonicalURL,
+		ScriptURLs: []string{
+			cdn.Slazy_0_5_0(),
+		},
+		Scripts: []string{
+			addToCartScript(),
+		},
+	}).ToHTML()
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *catalogueController) page(ctx context.Context, data catalogueControllerData) hb.TagInterface {
+	heading := "Shop"
+	baseURL := links.Website().Shop()
+	if data.category != nil {
+		heading = data.category.GetTitle()
+		baseURL = controller.categoryURL(data.category, nil)
+	}
+
+	paginationURL := baseURL
+	if data.search != "" {
+		paginationURL = links.URL(strings.SplitN(baseURL, "?", 2)[0], map[string]string{"q": data.search})
+	}
+	paginationURL += lo.Ternary(strings.Contains(paginationURL, "?"), "&", "?") + "page="
+
+	pagination := bs.Pagination(bs.PaginationOptions{
+		NumberItems:       data.productCount,
+		CurrentPageNumber: data.page,
+		PagesToShow:       10,
+		PerPage:           data.perPage,
+		URL:               paginationURL,
+	})
+
+	searchForm := hb.Form().
+		Method(http.MethodGet).
+		Action(baseURL).
+		Class("d-flex gap-2 mb-4").
+		Child(hb.Input().
+			Type(hb.TYPE_SEARCH).
+			Class("form-control").
+			Name("q").
+			Value(data.search).
+			Placeholder("Search products").
+			Attr("maxlength", cast.ToString(maxSearchLength))).
+		Child(hb.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn btn-outline-primary").
+			Text("Search"))
+
+	productCards := lo.Map(data.productList, func(product shopstore.ProductInterface, _ int) hb.TagInterface {
+		return controller.cardProduct(ctx, data, product)
+	})
+
+	productGrid := hb.Div().
+		Class("row g-4").
+		Children(productCards).
+		ChildIf(len(productCards) == 0, hb.Div().
+			Class("col-12").
+			Child(hb.Div().Class("alert alert-info").Text("No products found.")))
+
+	return hb.Section().
+		Style("padding-top:40px; padding-bottom:60px;").
+		Child(hb.Div().
+			Class("container").
+			Child(hb.Heading1().Class("mb-4").Text(heading)).
+			Child(hb.Div().
+				Class("row").
+				Child(hb.Div().
+					Class("col-12 col-md-3 mb-4").
+					Child(controller.categoryNav(data))).
+				Child(hb.Div().
+					Class("col-12 col-md-9").
+					Child(searchForm).
+					Child(productGrid).
+					Child(hb.Div().
+						Class("d-flex justify-content-center mt-5 mb-0").
+						HTML(pagination)))))
+}
+
+// categoryNav lists the categories, with a link to all the products
+func (controller *catalogueController) categoryNav(data catalogueControllerData) hb.TagInterface {
+	params := map[string]string{}
+	if data.search != "" {
+		params["q"] = data.search
+	}
+
+	allLink := hb.A().
+		Class("list-group-item list-group-item-action").
+		ClassIf(data.category == nil, "active").
+		Href(links.Website().Shop(params)).
+		Text("All Products")
+
+	categoryLinks := lo.Map(data.categories, func(category shopstore.CategoryInterface, _ int) hb.TagInterface {
+		isActive := data.category != nil && data.category.GetID() == category.GetID()
+		return hb.A().
+			Class("list-group-item list-group-item-action").
+			ClassIf(isActive, "active").
+			Href(controller.categoryURL(category, params)).
+			Text(category.GetTitle())
+	})
+
+	return hb.Nav().
+		Class("list-group").
+		Attr("aria-label", "Categories").
+		Child(allLink).
+		Children(categoryLinks)
+}
+
+func (controller *catalogueController) cardProduct(ctx context.Context, data catalogueControllerData, product shopstore.ProductInterface) hb.TagInterface {
+	url := productURL(product)
+
+	image := hb.Div().
+		Class("bg-light d-flex align-items-center justify-content-center text-muted").
+		Style("height:200px;").
+		Child(hb.I().Class("bi bi-image fs-1"))
+
+	if imageURLs := productImageURLs(ctx, controller.app, product.GetID(), 1); len(imageURLs) > 0 {
+		image = hb.Image("").
+			Class("card-img-top").
+			Class("slazy-placeholder").
+			Style("object-fit: cover;").
+			Style("height: 200px;").
+			Attr("data-slazy-src", thumbnailURL(controller.app, imageURLs[0], "400", "300")).
+			Attr("loading", "lazy").
+			Alt(product.GetTitle())
+	}
+
+	price := productPrice(product, data.currency)
+
+	return hb.Div().
+		Class("col-12 col-sm-6 col-lg-4").
+		Child(hb.Div().
+			Class("card h-100 shadow-sm").
+			Child(hb.A().Href(url).Child(image)).
+			Child(hb.Div().
+				Class("card-body d-flex flex-column").
+				Child(hb.Heading5().
+					Class("card-title").
+					Child(hb.A().Href(url).Class("text-decoration-none").Text(product.GetTitle()))).
+				ChildIf(price != "", hb.Paragraph().
+					Class("card-text fw-bold").
+					Text(data.currency+" "+price)).
+				Child(hb.Div().
+					Class("mt-auto").
+					ChildIf(price != "", addToCartForm(product, productInStock(ctx, controller.app, product), false)))))
+}
+
+// categoryURL returns the URL of the category listing
+func (controller *catalogueController) categoryURL(category shopstore.CategoryInterface, params map[string]string) string {
+	return links.Website().ShopCategory(category.GetID(), str.Slugify(category.GetTitle(), '-'), params)
+}
+
+func (controller *catalogueController) prepareData(r *http.Request) (data catalogueControllerData, errorMessage string) {
+	shopStore := controller.app.GetShopStore()
+
+	if shopStore == nil {
+		controller.app.GetLogger().Error("At catalogueController > prepareData", slog.String("error", "shop store is not initialized"))
+		return data, "Sorry, the shop is currently unavailable. Please try again later."
+	}
+
+	data.currency = controller.app.GetConfig().GetPaymentCurrency()
+	data.perPage = 12 // 4 rows x 3 products
+	data.page = max(0, cast.ToInt(req.GetStringTrimmed(r, "page")))
+
+	data.search = req.GetStringTrimmed(r, "q")
+	if len(data.search) > maxSearchLength {
+		data.search = data.search[:maxSearchLength]
+	}
+
+	categories, err := shopStore.CategoryList(r.Context(), shopstore.NewCategoryQuery().
+		SetStatus(shopstore.CATEGORY_STATUS_ACTIVE))
+
+	if err != nil {
+		controller.app.GetLogger().Error("At catalogueController > prepareData > CategoryList", slog.String("error", err.Error()))
+		return data, "Sorry, there was an error loading the categories. Please try again later."
+	}
+
+	sort.SliceStable(categories, func(i, j int) bool {
+		return strings.ToLower(categories[i].GetTitle()) < strings.ToLower(categories[j].GetTitle())
+	})
+
+	data.categories = categories
+
+	if categoryID, _ := rtr.GetParam(r, "id"); categoryID != "" {
+		category, found := lo.Find(categories, func(category shopstore.CategoryInterface) bool {
+			return category.GetID() == categoryID
+		})
+
+		if !found {
+			return data, "The category you are looking for no longer exists."
+		}
+
+		data.category = category
+	}
+
+	products, err := shopStore.ProductList(r.Context(), shopstore.NewProductQuery().
+		SetStatus(shopstore.PRODUCT_STATUS_ACTIVE).
+		SetLimit(maxCatalogueProducts))
+
+	if err != nil {
+		controller.app.GetLogger().Error("At catalogueController > prepareData > ProductList", slog.String("error", err.Error()))
+		return data, "Sorry, there was an error loading the products. Please try again later."
+	}
+
+	// The category is kept in the product metadata, and the search spans
+	// the title and the description, so both are filtered here
+	products = lo.Filter(products, func(product shopstore.ProductInterface, _ int) bool {
+		if data.category != nil && productCategoryID(product) != data.category.GetID() {
+			return false
+		}
+
+		return productMatchesSearch(product, data.search)
+	})
+
+	sort.SliceStable(products, func(i, j int) bool {
+		return strings.ToLower(products[i].GetTitle()) < strings.ToLower(products[j].GetTitle())
+	})
+
+	data.productCount = len(products)
+
+	offset := min(data.page*data.perPage, len(products))
+	data.productList = products[offset:min(offset+data.perPage, len(products))]
+
+	return data, ""
+}
@@ -0,0 +1,173 @@
+package catalogue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/testutils"
+	"strings"
+	"testing"
+
+	"github.com/dracory/rtr"
+	"github.com/dracory/shopstore"
+)
+
+func setupCatalogueApp(t *testing.T) app.AppInterface {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetShopStoreUsed(true)
+
+	return testutils.Setup(testutils.WithCfg(cfg))
+}
+
+// seedCatalogueProduct creates an active product in the category
+func seedCatalogueProduct(t *testing.T, app app.AppInterface, productID string, title string, categoryID string) shopstore.ProductInterface {
+	t.Helper()
+
+	product := shopstore.NewProduct()
+	product.SetID(productID)
+	product.SetTitle(title)
+	product.SetDescription("Description of " + title)
+	product.SetStatus(shopstore.PRODUCT_STATUS_ACTIVE)
+	product.SetPriceFloat(12.5)
+	product.SetQuantityInt(3)
+
+	if categoryID != "" {
+		if err := product.SetMeta(config.PRODUCT_META_CATEGORY_ID, categoryID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := app.GetShopStore().ProductCreate(context.Background(), product); err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+
+	return product
+}
+
+func newRequestWithParams(method, path string, params map[string]string) *http.Request {
+	if params == nil {
+		params = map[string]string{}
+	}
+
+	req := httptest.NewRequest(method, path, nil)
+	ctx := context.WithValue(req.Context(), rtr.ParamsKey, params)
+	return req.WithContext(ctx)
+}
+
+func TestCatalogueController_ListsActiveProducts(t *testing.T) {
+	app := setupCatalogueApp(t)
+
+	seedCatalogueProduct(t, app, "1001", "Blue Mug", "")
+	seedCatalogueProduct(t, app, "1002", "Red Mug", "")
+
+	draft := shopstore.NewProduct()
+	draft.SetID("1003")
+	draft.SetTitle("Draft Mug")
+	draft.SetStatus(shopstore.PRODUCT_STATUS_DRAFT)
+	draft.SetPriceFloat(5)
+	if err := app.GetShopStore().ProductCreate(context.Background(), draft); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	html := NewCatalogueController(app).Handler(w, newRequestWithParams(http.MethodGet, "/shop", nil))
+
+	for _, expected := range []string{"Blue Mug", "Red Mug", "/shop/product/1001/blue-mug", "Add to Cart", "ShopAddToCart", "/shop/cart/api"} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("expected the catalogue to contain %q", expected)
+		}
+	}
+
+	if strings.Contains(html, "Draft Mug") {
+		t.Error("expected the draft product not to be listed")
+	}
+}
+
+func TestCatalogueController_Search(t *testing.T) {
+	app := setupCatalogueApp(t)
+
+	seedCatalogueProduct(t, app, "1001", "Blue Mug", "")
+	seedCatalogueProduct(t, app, "1002", "Green Teapot", "")
+
+	w := httptest.NewRecorder()
+	html := NewCatalogueController(app).Handler(w, newRequestWithParams(http.MethodGet, "/shop?q=teapot", nil))
+
+	if !strings.Contains(html, "Green Teapot") {
+		t.Error("expected the search to find the teapot")
+	}
+
+	if strings.Contains(html, "Blue Mug") {
+		t.Error("expected the search not to find the mug")
+	}
+}
+
+func TestCatalogueController_Category(t *testing.T) {
+	app := setupCatalogueApp(t)
+
+	category := shopstore.NewCategory()
+	category.SetTitle("Kitchen")
+	category.SetStatus(shopstore.CATEGORY_STATUS_ACTIVE)
+	if err := app.GetShopStore().CategoryCreate(context.Background(), category); err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+
+	seedCatalogueProduct(t, app, "1001", "Blue Mug", category.GetID())
+	seedCatalogueProduct(t, app, "1002", "Garden Hose", "")
+
+	w := httptest.NewRecorder()
+	r := newRequestWithParams(http.MethodGet, "/shop/category/"+category.GetID()+"/kitchen", map[string]string{
+		"id":    category.GetID(),
+		"title": "kitchen",
+	})
+	html := NewCatalogueController(app).Handler(w, r)
+
+	if !strings.Contains(html, "Kitchen") || !strings.Contains(html, "Blue Mug") {
+		t.Error("expected the category listing to contain the category and its product")
+	}
+
+	if strings.Contains(html, "Garden Hose") {
+		t.Error("expected the category listing not to contain products of other categories")
+	}
+}
+
+func TestCatalogueController_UnknownCategory(t *testing.T) {
+	app := setupCatalogueApp(t)
+
+	w := httptest.NewRecorder()
+	r := newRequestWithParams(http.MethodGet, "/shop/category/999", map[string]string{"id": "999"})
+	html := NewCatalogueController(app).Handler(w, r)
+
+	if html != "" {
+		t.Fatalf("expected a redirect, got: %s", html)
+	}
+
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected status %d, got %d", http.StatusSeeOther, w.Code)
+	}
+}
+
+func TestCatalogueController_Pagination(t *testing.T) {
+	app := setupCatalogueApp(t)
+
+	for i := 0; i < 14; i++ {
+		seedCatalogueProduct(t, app, fmt.Sprintf("20%02d", i), "Product "+string(rune('A'+i)), "")
+	}
+
+	w := httptest.NewRecorder()
+	html := NewCatalogueController(app).Handler(w, newRequestWithParams(http.MethodGet, "/shop?page=1", nil))
+
+	if !strings.Contains(html, "Product M") || !strings.Contains(html, "Product N") {
+		t.Error("expected the second page to contain the last products")
+	}
+
+	if strings.Contains(html, "Product A<") {
+		t.Error("expected the second page not to contain the first product")
+	}
+}
@@ -0,0 +1,221 @@
+package catalogue
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+
+	"github.com/dracory/hb"
+	"github.com/dracory/rtr"
+	"github.com/dracory/shopstore"
+	"github.com/dracory/str"
+	"github.com/samber/lo"
+)
+
+// == CONTROLLER ==============================================================
+
+// productController shows the detail page of an active product
+type productController struct {
+	app app.AppInterface
+}
+
+type productControllerData struct {
+	currency  string
+	product   shopstore.ProductInterface
+	category  shopstore.CategoryInterface
+	imageURLs []string
+	inStock   bool
+	jsonLd    string
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewProductController creates a new product controller
+func NewProductController(app app.AppInterface) *productController {
+	return &productController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *productController) Handler(w http.ResponseWriter, r *http.Request) string {
+	productID, _ := rtr.GetParam(r, "id")
+	productSlugParam, _ := rtr.GetParam(r, "title")
+	shopURL := links.Website().Shop()
+
+	data, errorMessage := controller.prepareData(r, productID)
+
+	if errorMessage != "" {
+		return helpers.ToFlashWarning(controller.app.GetCacheStore(), w, r, errorMessage, shopURL, 5)
+	}
+
+	if productSlugParam != productSlug(data.product) {
+		return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "The product location has changed. Redirecting to the new address...", productURL(data.product), 5)
+	}
+
+	imageURL := ""
+	if len(data.imageURLs) > 0 {
+		imageURL = absoluteURL(data.imageURLs[0])
+	}
+
+	return layouts.NewPageLayout(controller.app, r, layouts.Options{
+		WebsiteSection:  "Shop",
+		Title:           data.product.GetTitle(),
+		Content:         controller.page(data),
+		CanonicalURL:    productURL(data.product),
+		MetaDescription: str.Truncate(data.product.GetDescription(), 160, "..."),
+		ImageURL:        imageURL,
+		Scripts: []string{
+			addToCartScript(),
+			controller.galleryScript(),
+		},
+	}).ToHTML()
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *productController) page(data productControllerData) hb.TagInterface {
+	breadcrumbs := hb.OL().
+		Class("breadcrumb").
+		Child(hb.LI().
+			Class("breadcrumb-item").
+			Child(hb.A().Href(links.Website().Shop()).Text("Shop")))
+
+	if data.category != nil {
+		breadcrumbs.Child(hb.LI().
+			Class("breadcrumb-item").
+			Child(hb.A().
+				Href(links.Website().ShopCategory(data.category.GetID(), str.Slugify(data.category.GetTitle(), '-'), nil)).
+				Text(data.category.GetTitle())))
+	}
+
+	breadcrumbs.Child(hb.LI().
+		Class("breadcrumb-item active").
+		Attr("aria-current", "page").
+		Text(data.product.GetTitle()))
+
+	price := productPrice(data.product, data.currency)
+
+	availability := hb.Span().
+		ClassIfElse(data.inStock, "badge bg-success", "badge bg-secondary").
+		TextIfElse(data.inStock, "In stock", "Out of stock")
+
+	details := hb.Div().
+		Class("col-12 col-md-6").
+		Child(hb.Heading1().Class("mb-3").Text(data.product.GetTitle())).
+		ChildIf(price != "", hb.Paragraph().
+			Class("fs-4 fw-bold").
+			Text(data.currency+" "+price)).
+		Child(hb.Paragraph().Child(availability)).
+		Child(hb.Div().
+			Class("mb-4").
+			Style("white-space: pre-line;").
+			Text(data.product.GetDescription())).
+		ChildIf(price != "", addToCartForm(data.product, data.inStock, true))
+
+	return hb.Section().
+		Style("padding-top:40px; padding-bottom:60px;").
+		Child(hb.Script(data.jsonLd).Type("application/ld+json")).
+		Child(hb.Div().
+			Class("container").
+			Child(hb.Nav().Attr("aria-label", "breadcrumb").Child(breadcrumbs)).
+			Child(hb.Div().
+				Class("row g-5").
+				Child(hb.Div().
+					Class("col-12 col-md-6").
+					Child(controller.gallery(data))).
+				Child(details)))
+}
+
+// gallery shows the first image of the product, and the thumbnails of
+// all its images, which swap the main image when clicked
+func (controller *productController) gallery(data productControllerData) hb.TagInterface {
+	if len(data.imageURLs) == 0 {
+		return hb.Div().
+			Class("bg-light d-flex align-items-center justify-content-center text-muted rounded").
+			Style("height:400px;").
+			Child(hb.I().Class("bi bi-image display-1"))
+	}
+
+	mainImage := hb.Image(thumbnailURL(controller.app, data.imageURLs[0], "800", "800")).
+		ID("ShopProductImage").
+		Class("img-fluid rounded mb-3").
+		Alt(data.product.GetTitle())
+
+	thumbnails := lo.Map(data.imageURLs, func(imageURL string, index int) hb.TagInterface {
+		return hb.Image(thumbnailURL(controller.app, imageURL, "100", "100")).
+			Class("ShopProductThumbnail img-thumbnail").
+			Style("width:80px; height:80px; object-fit:cover; cursor:pointer;").
+			Data("image", thumbnailURL(controller.app, imageURL, "800", "800")).
+			Attr("loading", "lazy").
+			Alt(data.product.GetTitle())
+	})
+
+	return hb.Div().
+		Child(mainImage).
+		ChildIf(len(thumbnails) > 1, hb.Div().
+			Class("d-flex flex-wrap gap-2").
+			Children(thumbnails))
+}
+
+func (controller *productController) galleryScript() string {
+	return `
+document.querySelectorAll('.ShopProductThumbnail').forEach(function (thumbnail) {
+	thumbnail.addEventListener('click', function () {
+		document.getElementById('ShopProductImage').src = thumbnail.dataset.image;
+	});
+});
+`
+}
+
+func (controller *productController) prepareData(r *http.Request, productID string) (data productControllerData, errorMessage string) {
+	shopStore := controller.app.GetShopStore()
+
+	if shopStore == nil {
+		controller.app.GetLogger().Error("At productController > prepareData", slog.String("error", "shop store is not initialized"))
+		return data, "Sorry, the shop is currently unavailable. Please try again later."
+	}
+
+	if productID == "" {
+		return data, "The product you are looking for no longer exists. Redirecting to the shop..."
+	}
+
+	product, err := shopStore.ProductFindByID(r.Context(), productID)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At productController > prepareData > ProductFindByID", slog.String("error", err.Error()), slog.String("product_id", productID))
+		return data, "The product you are looking for no longer exists. Redirecting to the shop..."
+	}
+
+	if product == nil || product.GetStatus() != shopstore.PRODUCT_STATUS_ACTIVE {
+		return data, "The product you are looking for no longer exists. Redirecting to the shop..."
+	}
+
+	if categoryID := productCategoryID(product); categoryID != "" {
+		category, err := shopStore.CategoryFindByID(r.Context(), categoryID)
+
+		if err != nil {
+			controller.app.GetLogger().Warn("At productController > prepareData > CategoryFindByID", slog.String("error", err.Error()), slog.String("category_id", categoryID))
+		}
+
+		if category != nil && category.GetStatus() == shopstore.CATEGORY_STATUS_ACTIVE {
+			data.category = category
+		}
+	}
+
+	data.currency = controller.app.GetConfig().GetPaymentCurrency()
+	data.product = product
+	data.imageURLs = productImageURLs(r.Context(), controller.app, product.GetID(), 0)
+	data.inStock = productInStock(r.Context(), controller.app, product)
+
+	data.jsonLd, err = productJsonLdScript(product, data.imageURLs, data.currency, data.inStock)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At productController > prepareData > productJsonLdScript", slog.String("error", err.Error()))
+		return data, "Sorry, the product could not be displayed. Please try again later."
+	}
+
+	return data, ""
+}
@@ -0,0 +1,109 @@
+package catalogue
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"project/internal/testutils"
+)
+
+func TestProductController_ShowsProduct(t *testing.T) {
+	app := setupCatalogueApp(t)
+	seedCatalogueProduct(t, app, "1001", "Blue Mug", "")
+
+	w := httptest.NewRecorder()
+	r := newRequestWithParams(http.MethodGet, "/shop/product/1001/blue-mug", map[string]string{
+		"id":    "1001",
+		"title": "blue-mug",
+	})
+	html := NewProductController(app).Handler(w, r)
+
+	for _, expected := range []string{
+		"Blue Mug",
+		"Description of Blue Mug",
+		`application/ld+json`,
+		`"@type":"Product"`,
+		`"price":"12.50"`,
+		`"availability":"https://schema.org/InStock"`,
+		"ShopAddToCart",
+		`name="quantity"`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("expected the product page to contain %q", expected)
+		}
+	}
+}
+
+func TestProductController_WrongSlugRedirects(t *testing.T) {
+	app := setupCatalogueApp(t)
+	seedCatalogueProduct(t, app, "1001", "Blue Mug", "")
+
+	w := httptest.NewRecorder()
+	r := newRequestWithParams(http.MethodGet, "/shop/product/1001/old-title", map[string]string{
+		"id":    "1001",
+		"title": "old-title",
+	})
+	html := NewProductController(app).Handler(w, r)
+
+	if html != "" {
+		t.Fatalf("expected a redirect, got: %s", html)
+	}
+
+	response := w.Result()
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected status %d, got %d", http.StatusSeeOther, response.StatusCode)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || !strings.Contains(flashMessage.Url, "/shop/product/1001/blue-mug") {
+		t.Fatalf("expected a redirect to the product URL, got: %+v", flashMessage)
+	}
+}
+
+func TestProductController_MissingProduct(t *testing.T) {
+	app := setupCatalogueApp(t)
+
+	w := httptest.NewRecorder()
+	r := newRequestWithParams(http.MethodGet, "/shop/product/999", map[string]string{"id": "999"})
+	html := NewProductController(app).Handler(w, r)
+
+	if html != "" {
+		t.Fatalf("expected a redirect, got: %s", html)
+	}
+
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected status %d, got %d", http.StatusSeeOther, w.Code)
+	}
+}
+
+func TestProductController_OutOfStock(t *testing.T) {
+	app := setupCatalogueApp(t)
+	app.GetConfig().SetShopStockTrackingEnabled(true)
+
+	product := seedCatalogueProduct(t, app, "1001", "Blue Mug", "")
+	product.SetQuantityInt(0)
+	if err := app.GetShopStore().ProductUpdate(t.Context(), product); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	r := newRequestWithParams(http.MethodGet, "/shop/product/1001/blue-mug", map[string]string{
+		"id":    "1001",
+		"title": "blue-mug",
+	})
+	html := NewProductController(app).Handler(w, r)
+
+	if !strings.Contains(html, "Out of Stock") {
+		t.Error("expected the add to cart button to be disabled")
+	}
+
+	if !strings.Contains(html, `"availability":"https://schema.org/OutOfStock"`) {
+		t.Error("expected the structured data to be out of stock")
+	}
+}
@@ -0,0 +1,127 @@
+package catalogue
+
+import (
+	"context"
+	"log/slog"
+	"path"
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/inventory"
+	"project/internal/links"
+	"project/internal/payment"
+	"strings"
+	"time"
+
+	"github.com/dracory/shopstore"
+	"github.com/dracory/str"
+)
+
+// productSlug returns the slug of the product used in its URL
+func productSlug(product shopstore.ProductInterface) string {
+	return str.Slugify(product.GetTitle(), '-')
+}
+
+// productURL returns the URL of the product detail page
+func productURL(product shopstore.ProductInterface) string {
+	return links.Website().ShopProduct(product.GetID(), productSlug(product), nil)
+}
+
+// productCategoryID returns the ID of the category the product is listed under
+func productCategoryID(product shopstore.ProductInterface) string {
+	return strings.TrimSpace(product.GetMeta(config.PRODUCT_META_CATEGORY_ID))
+}
+
+// productPrice returns the price of the product formatted in the shop
+// currency, or an empty string if the price is not valid
+func productPrice(product shopstore.ProductInterface, currency string) string {
+	amount, err := payment.ToMinorUnits(product.GetPrice(), currency)
+	if err != nil {
+		return ""
+	}
+
+	return payment.FormatMinorUnits(amount, currency)
+}
+
+// productMatchesSearch checks if the search phrase is found in the title
+// or the description of the product, ignoring the case
+func productMatchesSearch(product shopstore.ProductInterface, search string) bool {
+	search = strings.ToLower(strings.TrimSpace(search))
+	if search == "" {
+		return true
+	}
+
+	return strings.Contains(strings.ToLower(product.GetTitle()), search) ||
+		strings.Contains(strings.ToLower(product.GetDescription()), search)
+}
+
+// productImageURLs returns the URLs of the active images of the product.
+// A limit of zero returns all the images
+func productImageURLs(ctx context.Context, app app.AppInterface, productID string, limit int) []string {
+	mediaQuery := shopstore.NewMediaQuery()
+	mediaQuery.SetEntityID(productID)
+	mediaQuery.SetStatus(shopstore.MEDIA_STATUS_ACTIVE)
+	if limit > 0 {
+		mediaQuery.SetLimit(limit)
+	}
+
+	medias, err := app.GetShopStore().MediaList(ctx, mediaQuery)
+	if err != nil {
+		app.GetLogger().Warn("At catalogue > productImageURLs", slog.String("error", err.Error()), slog.String("product_id", productID))
+		return []string{}
+	}
+
+	imageURLs := []string{}
+	for _, media := range medias {
+		if media.GetURL() != "" {
+			imageURLs = append(imageURLs, media.GetURL())
+		}
+	}
+
+	return imageURLs
+}
+
+// productInStock checks if the product can be added to the cart. Products
+// are always in stock, unless the stock is tracked
+func productInStock(ctx context.Context, app app.AppInterface, product shopstore.ProductInterface) bool {
+	if !app.GetConfig().GetShopStockTrackingEnabled() {
+		return true
+	}
+
+	reservationStore, err := inventory.NewStore(app.GetCustomStore())
+	if err != nil {
+		app.GetLogger().Error("At catalogue > productInStock", slog.String("error", err.Error()))
+		return false
+	}
+
+	available, err := inventory.Available(ctx, app.GetShopStore(), reservationStore, product, time.Now().UTC())
+	if err != nil {
+		app.GetLogger().Error("At catalogue > productInStock", slog.String("error", err.Error()), slog.String("product_id", product.GetID()))
+		return false
+	}
+
+	return available > 0
+}
+
+// thumbnailURL returns the URL of the image resized by the thumb controller
+func thumbnailURL(app app.AppInterface, imageURL string, width string, height string) string {
+	extension := strings.TrimPrefix(strings.ToLower(path.Ext(imageURL)), ".")
+
+	switch extension {
+	case "jpeg":
+		extension = "jpg"
+	case "jpg", "png", "webp":
+	default:
+		extension = "png"
+	}
+
+	return links.Website().Thumbnail(extension, width, height, "80", imageURL, app.GetFileCache())
+}
+
+// absoluteURL prefixes the relative URLs with the root URL of the website
+func absoluteURL(url string) string {
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		return url
+	}
+
+	return links.RootURL() + url
+}
@@ -0,0 +1,80 @@
+package catalogue
+
+import (
+	"encoding/json"
+
+	"github.com/dracory/shopstore"
+)
+
+const (
+	schemaInStock    = "https://schema.org/InStock"
+	schemaOutOfStock = "https://schema.org/OutOfStock"
+)
+
+// productJsonLd is the schema.org Product structured data
+type productJsonLd struct {
+	Context     string              `json:"@context"`
+	Type        string              `json:"@type"`
+	SKU         string              `json:"sku"`
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Image       []string            `json:"image,omitempty"`
+	URL         string              `json:"url"`
+	Offers      *productJsonLdOffer `json:"offers,omitempty"`
+}
+
+// productJsonLdOffer is the schema.org Offer of the product
+type productJsonLdOffer struct {
+	Type          string `json:"@type"`
+	URL           string `json:"url"`
+	Price         string `json:"price"`
+	PriceCurrency string `json:"priceCurrency"`
+	Availability  string `json:"availability"`
+}
+
+// productJsonLdScript returns the schema.org Product structured data of
+// the product, to be embedded in a script of type application/ld+json.
+//
+// The JSON encoder escapes <, > and &, so the product content cannot
+// close the script tag.
+func productJsonLdScript(product shopstore.ProductInterface, imageURLs []string, currency string, inStock bool) (string, error) {
+	url := absoluteURL(productURL(product))
+
+	images := []string{}
+	for _, imageURL := range imageURLs {
+		images = append(images, absoluteURL(imageURL))
+	}
+
+	data := productJsonLd{
+		Context:     "https://schema.org",
+		Type:        "Product",
+		SKU:         product.GetID(),
+		Name:        product.GetTitle(),
+		Description: product.GetDescription(),
+		Image:       images,
+		URL:         url,
+	}
+
+	// Products without a valid price cannot be offered
+	if price := productPrice(product, currency); price != "" {
+		availability := schemaOutOfStock
+		if inStock {
+			availability = schemaInStock
+		}
+
+		data.Offers = &productJsonLdOffer{
+			Type:          "Offer",
+			URL:           url,
+			Price:         price,
+			PriceCurrency: currency,
+			Availability:  availability,
+		}
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(encoded), nil
+}
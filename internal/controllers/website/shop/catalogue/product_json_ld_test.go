@@ -0,0 +1,63 @@
+package catalogue
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dracory/shopstore"
+)
+
+func TestProductJsonLdScript(t *testing.T) {
+	product := shopstore.NewProduct()
+	product.SetID("1001")
+	product.SetTitle("Mug </script><script>alert(1)</script>")
+	product.SetDescription("A mug")
+	product.SetPriceFloat(9.99)
+
+	script, err := productJsonLdScript(product, []string{"https://cdn.example.com/mug.png"}, "USD", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(script, "</script>") {
+		t.Fatalf("expected the script content to be escaped, got: %s", script)
+	}
+
+	data := productJsonLd{}
+	if err := json.Unmarshal([]byte(script), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Type != "Product" || data.SKU != "1001" || data.Description != "A mug" {
+		t.Errorf("unexpected product data: %+v", data)
+	}
+
+	if len(data.Image) != 1 || data.Image[0] != "https://cdn.example.com/mug.png" {
+		t.Errorf("Image = %v, want the absolute image URL", data.Image)
+	}
+
+	if data.Offers == nil {
+		t.Fatal("expected an offer")
+	}
+
+	if data.Offers.Price != "9.99" || data.Offers.PriceCurrency != "USD" || data.Offers.Availability != schemaOutOfStock {
+		t.Errorf("unexpected offer: %+v", data.Offers)
+	}
+}
+
+func TestProductJsonLdScript_NoOfferWithoutPrice(t *testing.T) {
+	product := shopstore.NewProduct()
+	product.SetID("1001")
+	product.SetTitle("Mug")
+	product.SetPrice("free")
+
+	script, err := productJsonLdScript(product, nil, "USD", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(script, "offers") {
+		t.Errorf("expected no offer for a product without a valid price, got: %s", script)
+	}
+}
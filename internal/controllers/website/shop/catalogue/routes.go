@@ -0,0 +1,48 @@
+package catalogue
+
+import (
+	"project/internal/app"
+	"project/internal/links"
+
+	"github.com/dracory/rtr"
+)
+
+// Routes returns the storefront routes
+func Routes(app app.AppInterface) []rtr.RouteInterface {
+	if app == nil || app.GetConfig() == nil {
+		return []rtr.RouteInterface{}
+	}
+
+	catalogueRoute := rtr.NewRoute().
+		SetName("Website > Shop > Catalogue").
+		SetPath(links.SHOP).
+		SetHTMLHandler(NewCatalogueController(app).Handler)
+
+	categoryRegex01Route := rtr.NewRoute().
+		SetName("Website > Shop > Category with ID").
+		SetPath(links.SHOP_CATEGORY_WITH_REGEX).
+		SetHTMLHandler(NewCatalogueController(app).Handler)
+
+	categoryRegex02Route := rtr.NewRoute().
+		SetName("Website > Shop > Category with ID && Title").
+		SetPath(links.SHOP_CATEGORY_WITH_REGEX2).
+		SetHTMLHandler(NewCatalogueController(app).Handler)
+
+	productRegex01Route := rtr.NewRoute().
+		SetName("Website > Shop > Product with ID").
+		SetPath(links.SHOP_PRODUCT_WITH_REGEX).
+		SetHTMLHandler(NewProductController(app).Handler)
+
+	productRegex02Route := rtr.NewRoute().
+		SetName("Website > Shop > Product with ID && Title").
+		SetPath(links.SHOP_PRODUCT_WITH_REGEX2).
+		SetHTMLHandler(NewProductController(app).Handler)
+
+	return []rtr.RouteInterface{
+		catalogueRoute,
+		categoryRegex01Route,
+		categoryRegex02Route,
+		productRegex01Route,
+		productRegex02Route,
+	}
+}
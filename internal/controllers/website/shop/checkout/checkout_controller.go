@@ -0,0 +1,625 @@
+package checkout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/controllers/website/shop/cart"
+	"project/internal/discount"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/inventory"
+	"project/internal/layouts"
+	"project/internal/links"
+	"project/internal/payment"
+	"project/internal/pricing"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dracory/shopstore"
+	"github.com/dracory/uid"
+	"github.com/dracory/userstore"
+	"github.com/samber/lo"
+)
+
+// idempotencyExpirationSeconds matches the lifetime of the payment code data
+const idempotencyExpirationSeconds = 24 * 60 * 60
+
+// == CONTROLLER ==============================================================
+
+// checkoutController converts the visitor's cart into an order
+// and sends the buyer to the payment provider
+type checkoutController struct {
+	app app.AppInterface
+}
+
+type checkoutControllerData struct {
+	authUser      userstore.UserInterface
+	currency      string
+	lines         []orderLine
+	subtotal      int64
+	discount      *discount.Application
+	discountError string
+	address       pricing.Address
+	quote         *pricing.Quote
+	pricingError  string
+	total         int64
+	csrfToken     string
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewCheckoutController creates a new checkout controller
+func NewCheckoutController(app app.AppInterface) *checkoutController {
+	return &checkoutController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+// Handler shows the order summary on GET, and begins the payment on POST
+func (controller *checkoutController) Handler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage, redirectURL := controller.prepareData(r)
+
+	if redirectURL != "" {
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return ""
+	}
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.Website().Home(), 10)
+	}
+
+	if r.Method == http.MethodPost {
+		return controller.postPaymentBegin(w, r, data)
+	}
+
+	return layouts.NewPageLayout(controller.app, r, layouts.Options{
+		WebsiteSection: "Shop",
+		Title:          "Checkout",
+		Content:        controller.page(data),
+	}).ToHTML()
+}
+
+// == PRIVATE METHODS =========================================================
+
+// postPaymentBegin creates (or reuses) the order for the current basket
+// and redirects the buyer to the payment provider
+func (controller *checkoutController) postPaymentBegin(w http.ResponseWriter, r *http.Request, data checkoutControllerData) string {
+	checkoutURL := links.Website().ShopCheckout()
+
+	csrfToken := req.GetStringTrimmed(r, "csrf_token")
+	if !csrf.TokenValidate(csrfToken, controller.app.GetConfig().GetCsrfSecret()) {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", checkoutURL, 10)
+	}
+
+	if controller.app.GetCacheStore() == nil {
+		controller.app.GetLogger().Error("At checkoutController > postPaymentBegin", slog.String("error", "cache store is not initialized"))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Checkout is currently unavailable. Please try again later.", checkoutURL, 10)
+	}
+
+	if data.pricingError != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, data.pricingError, checkoutURL, 10)
+	}
+
+	gateway := controller.app.GetPaymentGateway()
+
+	if gateway == nil && data.total > 0 {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Payments are not configured. Please contact us to complete your order.", checkoutURL, 10)
+	}
+
+	ctx := r.Context()
+	idempotencyKey := checkoutIdempotencyKey(data.authUser.GetID(), data.lines, quotePricingKey(data.quote))
+
+	order, paymentKey, err := controller.orderFindByIdempotencyKey(ctx, idempotencyKey)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At checkoutController > postPaymentBegin > orderFindByIdempotencyKey", slog.String("error", err.Error()))
+	}
+
+	if order == nil {
+		order, paymentKey, err = controller.orderCreate(ctx, data, idempotencyKey)
+
+		if err != nil {
+			controller.app.GetLogger().Error("At checkoutController > postPaymentBegin > orderCreate", slog.String("error", err.Error()))
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "We could not create your order. Please try again later.", checkoutURL, 10)
+		}
+	}
+
+	if err := controller.discountClaim(order, data); err != nil {
+		if discount.IsRejection(err) {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your discount code could not be applied: "+err.Error()+". Please review your cart.", checkoutURL, 10)
+		}
+
+		controller.app.GetLogger().Error("At checkoutController > postPaymentBegin > discountClaim", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "We could not apply your discount code. Please try again later.", checkoutURL, 10)
+	}
+
+	if err := controller.stockReserve(ctx, order, data); err != nil {
+		if inventory.IsOutOfStock(err) {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, err.Error()+". Please review your cart.", checkoutURL, 10)
+		}
+
+		controller.app.GetLogger().Error("At checkoutController > postPaymentBegin > stockReserve", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "We could not reserve the items of your order. Please try again later.", checkoutURL, 10)
+	}
+
+	if data.total <= 0 {
+		return controller.orderCompleteWithoutPayment(w, r, data, order, idempotencyKey)
+	}
+
+	email, _, _, _, _, err := ext.UserUntokenizeTransparently(ctx, controller.app, data.authUser)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At checkoutController > postPaymentBegin > UserUntokenizeTransparently", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "We could not read your account details. Please try again later.", checkoutURL, 10)
+	}
+
+	session, err := gateway.CheckoutCreate(ctx, payment.CheckoutOptions{
+		Currency:      data.currency,
+		CustomerEmail: email,
+		LineItems:     paymentLineItems(data.lines, data.quote),
+		OrderID:       order.GetID(),
+		SuccessURL:    links.Website().PaymentSuccess(paymentKey),
+		CancelURL:     links.Website().PaymentCanceled(paymentKey),
+	})
+
+	if err != nil {
+		controller.app.GetLogger().Error("At checkoutController > postPaymentBegin > CheckoutCreate", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "The payment provider is currently unavailable. Please try again later.", checkoutURL, 10)
+	}
+
+	if err := order.SetMeta(config.ORDER_META_PAYMENT_SESSION_ID, session.ID); err != nil {
+		controller.app.GetLogger().Error("At checkoutController > postPaymentBegin > SetMeta", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "We could not update your order. Please try again later.", checkoutURL, 10)
+	}
+
+	if err := controller.app.GetShopStore().OrderUpdate(ctx, order); err != nil {
+		controller.app.GetLogger().Error("At checkoutController > postPaymentBegin > OrderUpdate", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "We could not update your order. Please try again later.", checkoutURL, 10)
+	}
+
+	http.Redirect(w, r, session.URL, http.StatusSeeOther)
+	return ""
+}
+
+// orderCompleteWithoutPayment completes an order fully covered by a discount,
+// as there is nothing to collect from the payment provider
+func (controller *checkoutController) orderCompleteWithoutPayment(w http.ResponseWriter, r *http.Request, data checkoutControllerData, order shopstore.OrderInterface, idempotencyKey string) string {
+	ctx := r.Context()
+	confirmationURL := links.Website().ShopOrderConfirmation(order.GetID())
+
+	if err := helpers.OrderMarkAsPaid(ctx, controller.app.GetShopStore(), order); err != nil {
+		controller.app.GetLogger().Error("At checkoutController > orderCompleteWithoutPayment > OrderMarkAsPaid", slog.String("error", err.Error()), slog.String("order_id", order.GetID()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "We could not update your order. Please try again later.", links.Website().ShopCheckout(), 10)
+	}
+
+	if err := helpers.OrderInvoiceIssue(controller.app, order); err != nil {
+		controller.app.GetLogger().Error("At checkoutController > orderCompleteWithoutPayment > OrderInvoiceIssue", slog.String("error", err.Error()), slog.String("order_id", order.GetID()))
+	}
+
+	if err := helpers.OrderDiscountRedeem(controller.app, order); err != nil {
+		controller.app.GetLogger().Error("At checkoutController > orderCompleteWithoutPayment > OrderDiscountRedeem", slog.String("error", err.Error()), slog.String("order_id", order.GetID()))
+	}
+
+	if err := helpers.OrderStockCommit(ctx, controller.app, order); err != nil {
+		controller.app.GetLogger().Error("At checkoutController > orderCompleteWithoutPayment > OrderStockCommit", slog.String("error", err.Error()), slog.String("order_id", order.GetID()))
+	}
+
+	if err := controller.app.GetCacheStore().Remove(helpers.IDEMPOTENCY_CACHE_KEY_PREFIX + idempotencyKey); err != nil {
+		controller.app.GetLogger().Warn("At checkoutController > orderCompleteWithoutPayment > Remove idempotency key", slog.String("error", err.Error()))
+	}
+
+	if err := cart.NewCartController(controller.app).ClearCart(ctx, r, data.authUser); err != nil {
+		controller.app.GetLogger().Warn("At checkoutController > orderCompleteWithoutPayment > ClearCart", slog.String("error", err.Error()))
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "Thank you, your order has been placed.", confirmationURL, 10)
+}
+
+// discountClaim holds a use of the discount code for the order while the
+// buyer is paying, so the concurrent checkouts with the same code cannot
+// exceed its usage limits. The use is held as long as the stock
+func (controller *checkoutController) discountClaim(order shopstore.OrderInterface, data checkoutControllerData) error {
+	if data.discount == nil {
+		return nil
+	}
+
+	discountStore, err := discount.NewStore(controller.app.GetCustomStore())
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().UTC().Add(time.Duration(controller.app.GetConfig().GetShopStockReservationMinutes()) * time.Minute)
+
+	return discountStore.UsageClaim(data.discount.Discount.ID, data.authUser.GetID(), order.GetID(), expiresAt)
+}
+
+// stockReserve reserves the ordered quantities while the buyer is paying,
+// when the stock is tracked. An order reused by a new checkout attempt
+// is reserved again if its reservations were released
+func (controller *checkoutController) stockReserve(ctx context.Context, order shopstore.OrderInterface, data checkoutControllerData) error {
+	if !controller.app.GetConfig().GetShopStockTrackingEnabled() {
+		return nil
+	}
+
+	reservationStore, err := inventory.NewStore(controller.app.GetCustomStore())
+	if err != nil {
+		return err
+	}
+
+	items := lo.Map(data.lines, func(line orderLine, _ int) inventory.Item {
+		return inventory.Item{ProductID: line.ProductID, Title: line.Title, Quantity: int64(line.Quantity)}
+	})
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(time.Duration(controller.app.GetConfig().GetShopStockReservationMinutes()) * time.Minute)
+
+	return inventory.Reserve(ctx, controller.app.GetShopStore(), reservationStore, order.GetID(), items, now, expiresAt)
+}
+
+// orderFindByIdempotencyKey returns the order (and its payment key) already
+// created for this basket, if it is still awaiting payment
+func (controller *checkoutController) orderFindByIdempotencyKey(ctx context.Context, idempotencyKey string) (shopstore.OrderInterface, string, error) {
+	paymentKey, err := controller.app.GetCacheStore().Get(helpers.IDEMPOTENCY_CACHE_KEY_PREFIX+idempotencyKey, "")
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if paymentKey == "" {
+		return nil, "", nil
+	}
+
+	paymentData, err := helpers.GetPaymentCodeData(controller.app, paymentKey)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if paymentData.OrderID == "" || paymentData.IdempotencyKey != idempotencyKey {
+		return nil, "", nil
+	}
+
+	order, err := controller.app.GetShopStore().OrderFindByID(ctx, paymentData.OrderID)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if order == nil || order.GetStatus() != shopstore.ORDER_STATUS_AWAITING_PAYMENT {
+		return nil, "", nil
+	}
+
+	return order, paymentKey, nil
+}
+
+// orderCreate creates the order with its line items, and stores the
+// payment code data used by the payment return URLs
+func (controller *checkoutController) orderCreate(ctx context.Context, data checkoutControllerData, idempotencyKey string) (shopstore.OrderInterface, string, error) {
+	shopStore := controller.app.GetShopStore()
+	paymentKey := uid.HumanUid()
+
+	order := shopstore.NewOrder()
+	order.SetCustomerID(data.authUser.GetID())
+	order.SetStatus(shopstore.ORDER_STATUS_AWAITING_PAYMENT)
+	order.SetQuantity(strconv.Itoa(orderLinesQuantity(data.lines)))
+	order.SetPrice(payment.FormatMinorUnits(data.total, data.currency))
+
+	metas := map[string]string{
+		config.ORDER_META_PAYMENT_KEY: paymentKey,
+		config.ORDER_META_SUBTOTAL:    payment.FormatMinorUnits(data.subtotal, data.currency),
+	}
+
+	if data.discount != nil {
+		metas[config.ORDER_META_DISCOUNT_ID] = data.discount.Discount.ID
+		metas[config.ORDER_META_DISCOUNT_CODE] = data.discount.Discount.Code
+		metas[config.ORDER_META_DISCOUNT_AMOUNT] = payment.FormatMinorUnits(data.discount.Amount, data.currency)
+	}
+
+	if data.quote != nil {
+		breakdown, err := json.Marshal(data.quote.Lines)
+		if err != nil {
+			return nil, "", err
+		}
+
+		metas[config.ORDER_META_PRICE_BREAKDOWN] = string(breakdown)
+		metas[config.ORDER_META_SHIPPING_COUNTRY] = data.quote.Address.Country
+		metas[config.ORDER_META_SHIPPING_REGION] = data.quote.Address.Region
+		metas[config.ORDER_META_SHIPPING_METHOD] = data.quote.ShippingMethod
+		metas[config.ORDER_META_SHIPPING_AMOUNT] = payment.FormatMinorUnits(data.quote.Amount(pricing.LINE_TYPE_SHIPPING), data.currency)
+		metas[config.ORDER_META_TAX_AMOUNT] = payment.FormatMinorUnits(data.quote.Amount(pricing.LINE_TYPE_TAX), data.currency)
+	}
+
+	for key, value := range metas {
+		if err := order.SetMeta(key, value); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := shopStore.OrderCreate(ctx, order); err != nil {
+		return nil, "", err
+	}
+
+	for _, line := range data.lines {
+		lineItem := shopstore.NewOrderLineItem()
+		lineItem.SetOrderID(order.GetID())
+		lineItem.SetProductID(line.ProductID)
+		lineItem.SetTitle(line.Title)
+		lineItem.SetQuantity(strconv.Itoa(line.Quantity))
+		lineItem.SetPrice(payment.FormatMinorUnits(line.UnitAmount, data.currency))
+
+		if err := shopStore.OrderLineItemCreate(ctx, lineItem); err != nil {
+			return nil, "", err
+		}
+	}
+
+	err := helpers.SetPaymentCodeData(controller.app, paymentKey, helpers.PaymentCodeData{
+		OrderID:        order.GetID(),
+		BuyerID:        data.authUser.GetID(),
+		Amount:         payment.FormatMinorUnits(data.total, data.currency),
+		DiscountID:     data.discountID(),
+		IdempotencyKey: idempotencyKey,
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = controller.app.GetCacheStore().Set(helpers.IDEMPOTENCY_CACHE_KEY_PREFIX+idempotencyKey, paymentKey, idempotencyExpirationSeconds)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return order, paymentKey, nil
+}
+
+func (controller *checkoutController) page(data checkoutControllerData) hb.TagInterface {
+	rows := lo.Map(data.lines, func(line orderLine, _ int) hb.TagInterface {
+		return hb.TR().
+			Child(hb.TD().Text(line.Title)).
+			Child(hb.TD().Class("text-end").Text(strconv.Itoa(line.Quantity))).
+			Child(hb.TD().Class("text-end").Text(payment.FormatMinorUnits(line.UnitAmount, data.currency))).
+			Child(hb.TD().Class("text-end").Text(payment.FormatMinorUnits(line.Total(), data.currency)))
+	})
+
+	breakdownRows := lo.Map(data.quoteLines(), func(line pricing.Line, _ int) hb.TagInterface {
+		amount := payment.FormatMinorUnits(line.Amount, data.currency)
+		if line.Included {
+			amount = "incl. " + amount
+		}
+
+		return hb.TR().
+			Child(hb.TD().Attr("colspan", "3").Class("text-end").Text(line.Label)).
+			Child(hb.TD().Class("text-end").Text(amount))
+	})
+
+	table := hb.Table().
+		Class("table table-striped").
+		Child(hb.Thead().Child(hb.TR().
+			Child(hb.TH().Text("Product")).
+			Child(hb.TH().Class("text-end").Text("Quantity")).
+			Child(hb.TH().Class("text-end").Text("Price")).
+			Child(hb.TH().Class("text-end").Text("Total")))).
+		Child(hb.Tbody().Children(rows)).
+		Child(hb.Tfoot().
+			ChildIf(len(data.quoteLines()) > 0, hb.TR().
+				Child(hb.TD().Attr("colspan", "3").Class("text-end").Text("Subtotal")).
+				Child(hb.TD().Class("text-end").Text(payment.FormatMinorUnits(data.subtotal, data.currency)))).
+			Children(breakdownRows).
+			Child(hb.TR().
+				Child(hb.TH().Attr("colspan", "3").Class("text-end").Text("Order Total")).
+				Child(hb.TH().Class("text-end").Text(data.currency + " " + payment.FormatMinorUnits(data.total, data.currency)))))
+
+	discountAlert := hb.Div().
+		Class("alert alert-warning").
+		Text("Your discount code was not applied: " + data.discountError)
+
+	pricingAlert := hb.Div().
+		Class("alert alert-danger").
+		Text(data.pricingError)
+
+	form := hb.Form().
+		Method(http.MethodPost).
+		Action(links.Website().ShopCheckout()).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(data.csrfToken)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("country").Value(data.address.Country)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("region").Value(data.address.Region)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("shipping_method").Value(data.shippingMethod())).
+		Child(hb.Button().
+			Type(hb.TYPE_SUBMIT).
+			Class("btn btn-primary btn-lg").
+			AttrIf(data.pricingError != "", "disabled", "disabled").
+			Child(hb.I().Class("bi bi-credit-card me-2")).
+			Text("Proceed to Payment"))
+
+	return hb.Section().
+		Style("padding-top:40px; padding-bottom:60px;").
+		Child(hb.Div().
+			Class("container").
+			Child(hb.Heading1().Class("mb-4").Text("Checkout")).
+			ChildIf(data.discountError != "", discountAlert).
+			ChildIf(data.pricingError != "", pricingAlert).
+			Child(controller.deliveryForm(data)).
+			Child(table).
+			Child(hb.Div().Class("text-end").Child(form)))
+}
+
+// deliveryForm lets the buyer choose the delivery country, region and
+// shipping method. The checkout is repriced when the form is submitted
+func (controller *checkoutController) deliveryForm(data checkoutControllerData) hb.TagInterface {
+	shippingOptions := []pricing.ShippingOption{}
+	if data.quote != nil {
+		shippingOptions = data.quote.ShippingOptions
+	}
+
+	methodSelect := hb.Select().
+		Class("form-select").
+		Name("shipping_method").
+		Children(lo.Map(shippingOptions, func(option pricing.ShippingOption, _ int) hb.TagInterface {
+			return hb.Option().
+				Value(option.Code).
+				AttrIf(option.Code == data.shippingMethod(), "selected", "selected").
+				Text(option.Name + " (" + payment.FormatMinorUnits(option.Amount, data.currency) + ")")
+		}))
+
+	return hb.Form().
+		Method(http.MethodGet).
+		Action(links.Website().ShopCheckout()).
+		Class("row g-3 align-items-end mb-4").
+		Child(hb.Div().Class("col-md-3").
+			Child(hb.Label().Class("form-label").Text("Country")).
+			Child(hb.Input().Class("form-control").Name("country").Attr("maxlength", "2").Attr("placeholder", "e.g. GB").Value(data.address.Country))).
+		Child(hb.Div().Class("col-md-3").
+			Child(hb.Label().Class("form-label").Text("Region")).
+			Child(hb.Input().Class("form-control").Name("region").Attr("placeholder", "Optional").Value(data.address.Region))).
+		ChildIf(len(shippingOptions) > 0, hb.Div().Class("col-md-4").
+			Child(hb.Label().Class("form-label").Text("Shipping")).
+			Child(methodSelect)).
+		Child(hb.Div().Class("col-md-2").
+			Child(hb.Button().Type(hb.TYPE_SUBMIT).Class("btn btn-outline-secondary w-100").Text("Update")))
+}
+
+// prepareData loads the buyer and the re-validated basket. A non-empty
+// redirect URL is returned when the visitor must log in first
+func (controller *checkoutController) prepareData(r *http.Request) (data checkoutControllerData, errorMessage string, redirectURL string) {
+	if controller.app.GetShopStore() == nil {
+		controller.app.GetLogger().Error("At checkoutController > prepareData", slog.String("error", "shop store is not initialized"))
+		return data, "Sorry, the shop is currently unavailable. Please try again later.", ""
+	}
+
+	data.authUser = helpers.GetAuthUser(r)
+
+	if data.authUser == nil {
+		return data, "", links.Auth().Login(links.Website().ShopCheckout())
+	}
+
+	data.currency = controller.app.GetConfig().GetPaymentCurrency()
+
+	basket := cart.NewCartController(controller.app).GetCart(r.Context(), r)
+
+	lines, err := orderLinesFromCart(r.Context(), controller.app.GetShopStore(), basket, data.currency)
+
+	if err != nil {
+		controller.app.GetLogger().Warn("At checkoutController > prepareData > orderLinesFromCart", slog.String("error", err.Error()))
+		return data, "Some of the items in your cart are no longer available. Please review your cart.", ""
+	}
+
+	if len(lines) == 0 {
+		return data, "Your cart is empty.", ""
+	}
+
+	data.lines = lines
+	data.subtotal = orderLinesTotal(lines)
+	data.total = data.subtotal
+
+	if basket.DiscountCode != "" {
+		controller.discountApply(r, basket.DiscountCode, &data)
+	}
+
+	data.address = cart.NewCartController(controller.app).ShippingAddress(r, basket)
+	shippingMethod := basket.ShippingMethod
+
+	if country := strings.ToUpper(req.GetStringTrimmed(r, "country")); len(country) == 2 {
+		data.address = pricing.Address{
+			Country: country,
+			Region:  strings.ToUpper(req.GetStringTrimmed(r, "region")),
+		}
+	}
+
+	if method := req.GetStringTrimmed(r, "shipping_method"); method != "" {
+		shippingMethod = method
+	}
+
+	controller.quoteCalculate(r.Context(), &data, shippingMethod)
+
+	data.csrfToken = csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret())
+
+	return data, "", ""
+}
+
+// discountApply re-validates the discount code stored in the cart against the
+// re-validated basket. A code which can no longer be used is reported to the
+// buyer, and the checkout continues without it
+func (controller *checkoutController) discountApply(r *http.Request, code string, data *checkoutControllerData) {
+	discountStore, err := discount.NewStore(controller.app.GetCustomStore())
+
+	var application *discount.Application
+	if err == nil {
+		application, err = discount.Apply(discountStore, code, data.authUser.GetID(), data.subtotal, data.currency, time.Now().UTC())
+	}
+
+	if err != nil {
+		if discount.IsRejection(err) {
+			data.discountError = err.Error()
+			return
+		}
+
+		controller.app.GetLogger().Error("At checkoutController > discountApply", slog.String("error", err.Error()))
+		data.discountError = "the discount code could not be checked"
+		return
+	}
+
+	data.discount = application
+	data.total = data.subtotal - application.Amount
+}
+
+// quoteCalculate prices the basket: the discount applied, then the shipping
+// and the tax from the pricing calculator. A basket which cannot be shipped
+// or priced is reported to the buyer, who cannot proceed to payment
+func (controller *checkoutController) quoteCalculate(ctx context.Context, data *checkoutControllerData, shippingMethod string) {
+	quote := pricing.NewQuote(data.currency, data.address, orderLinesToQuoteItems(data.lines))
+	quote.ShippingMethod = shippingMethod
+
+	if data.discount != nil {
+		quote.AddLine(pricing.Line{
+			Type:   pricing.LINE_TYPE_DISCOUNT,
+			Code:   data.discount.Discount.Code,
+			Label:  "Discount (" + data.discount.Discount.Code + ")",
+			Amount: -data.discount.Amount,
+		})
+	}
+
+	if calculator := controller.app.GetPricingCalculator(); calculator != nil {
+		if err := calculator.Calculate(ctx, quote); err != nil {
+			if errors.Is(err, pricing.ErrShippingUnavailable) {
+				data.pricingError = "Sorry, " + pricing.ErrShippingUnavailable.Error() + ". Please choose another delivery country."
+			} else {
+				controller.app.GetLogger().Error("At checkoutController > quoteCalculate", slog.String("error", err.Error()))
+				data.pricingError = "Shipping and tax could not be calculated. Please try again later."
+			}
+		}
+	}
+
+	data.quote = quote
+	data.total = quote.Total()
+}
+
+func (data checkoutControllerData) quoteLines() []pricing.Line {
+	if data.quote == nil {
+		return []pricing.Line{}
+	}
+	return data.quote.Lines
+}
+
+func (data checkoutControllerData) shippingMethod() string {
+	if data.quote == nil {
+		return ""
+	}
+	return data.quote.ShippingMethod
+}
+
+func (data checkoutControllerData) discountID() string {
+	if data.discount == nil {
+		return ""
+	}
+	return data.discount.Discount.ID
+}
@@ -0,0 +1,632 @@
+package checkout
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/controllers/website/shop/cart"
+	"project/internal/discount"
+	"project/internal/invoice"
+	"project/internal/links"
+	"project/internal/payment"
+	"project/internal/pricing"
+	"project/internal/testutils"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/sessionstore"
+	"github.com/dracory/shopstore"
+	"github.com/dracory/test"
+	"github.com/dracory/userstore"
+)
+
+func setupCheckoutApp(t *testing.T) (app.AppInterface, *payment.FakeGateway) {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetShopStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetCsrfSecret("test-csrf-secret")
+
+	gateway := payment.NewFakeGateway(payment.FAKE_WEBHOOK_SECRET)
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+	app.SetPaymentGateway(gateway)
+
+	return app, gateway
+}
+
+func seedBuyerWithCart(t *testing.T, app app.AppInterface) (userstore.UserInterface, sessionstore.SessionInterface) {
+	t.Helper()
+
+	user, session, err := testutils.SeedUserAndSession(app.GetUserStore(), app.GetSessionStore(), test.USER_01, httptest.NewRequest("GET", "/", nil), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	product, err := testutils.SeedProduct(app.GetShopStore(), "product_1", 12.50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	basket := cart.Cart{Items: []cart.CartItem{
+		{ProductID: product.GetID(), ProductName: product.GetTitle(), Price: product.GetPrice(), Quantity: 2},
+	}}
+
+	basketJSON, err := json.Marshal(basket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := user.SetMeta(config.USER_META_CART, string(basketJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.GetUserStore().UserUpdate(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	return user, session
+}
+
+// seedCartDiscount creates the discount, and enters its code in the buyer's cart
+func seedCartDiscount(t *testing.T, app app.AppInterface, user userstore.UserInterface, d discount.Discount) discount.Discount {
+	t.Helper()
+
+	discountStore, err := discount.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := discountStore.DiscountCreate(&d); err != nil {
+		t.Fatal(err)
+	}
+
+	basket := cart.Cart{}
+	if err := json.Unmarshal([]byte(user.GetMeta(config.USER_META_CART)), &basket); err != nil {
+		t.Fatal(err)
+	}
+
+	basket.DiscountCode = d.Code
+
+	basketJSON, err := json.Marshal(basket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := user.SetMeta(config.USER_META_CART, string(basketJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.GetUserStore().UserUpdate(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	return d
+}
+
+func authContext(user userstore.UserInterface, session sessionstore.SessionInterface) map[any]any {
+	return map[any]any{
+		config.AuthenticatedUserContextKey{}:    user,
+		config.AuthenticatedSessionContextKey{}: session,
+	}
+}
+
+func TestCheckoutController_GuestRedirectedToLogin(t *testing.T) {
+	app, _ := setupCheckoutApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewCheckoutController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected status %d, got %d", http.StatusSeeOther, response.StatusCode)
+	}
+
+	if !strings.Contains(response.Header.Get("Location"), "/auth/login") {
+		t.Errorf("expected redirect to login, got %q", response.Header.Get("Location"))
+	}
+}
+
+func TestCheckoutController_EmptyCart(t *testing.T) {
+	app, _ := setupCheckoutApp(t)
+
+	user, session, err := testutils.SeedUserAndSession(app.GetUserStore(), app.GetSessionStore(), test.USER_01, httptest.NewRequest("GET", "/", nil), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context: authContext(user, session),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected status %d, got %d", http.StatusSeeOther, response.StatusCode)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || !strings.Contains(flashMessage.Message, "cart is empty") {
+		t.Errorf("expected empty cart flash message, got %+v", flashMessage)
+	}
+}
+
+func TestCheckoutController_ShowsSummary(t *testing.T) {
+	app, _ := setupCheckoutApp(t)
+	user, session := seedBuyerWithCart(t, app)
+
+	body, response, err := test.CallStringEndpoint(http.MethodGet, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context: authContext(user, session),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, response.StatusCode)
+	}
+
+	for _, expected := range []string{"Checkout", "Test Product", "25.00", "Proceed to Payment", "csrf_token"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected body to contain %q", expected)
+		}
+	}
+}
+
+func TestCheckoutController_PaymentBeginIsIdempotent(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	user, session := seedBuyerWithCart(t, app)
+
+	controller := NewCheckoutController(app)
+
+	for i := 0; i < 2; i++ {
+		_, response, err := test.CallStringEndpoint(http.MethodPost, controller.Handler, test.NewRequestOptions{
+			Context: authContext(user, session),
+			FormValues: url.Values{
+				"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The fake gateway checkout URL is the success URL
+		if !strings.Contains(response.Header.Get("Location"), links.PAYMENT_SUCCESS) {
+			t.Fatalf("expected redirect to the payment gateway, got %q", response.Header.Get("Location"))
+		}
+	}
+
+	sessions := gateway.Sessions()
+
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 payment sessions, got %d", len(sessions))
+	}
+
+	if sessions[0].Options.OrderID == "" || sessions[0].Options.OrderID != sessions[1].Options.OrderID {
+		t.Fatalf("expected the same order to be reused, got %q and %q", sessions[0].Options.OrderID, sessions[1].Options.OrderID)
+	}
+
+	if sessions[0].Amount != 2500 {
+		t.Errorf("expected amount 2500, got %d", sessions[0].Amount)
+	}
+
+	if sessions[0].Options.Currency != "GBP" {
+		t.Errorf("expected currency GBP, got %q", sessions[0].Options.Currency)
+	}
+
+	order, err := app.GetShopStore().OrderFindByID(context.Background(), sessions[0].Options.OrderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order == nil {
+		t.Fatal("order should have been created")
+	}
+
+	if order.GetStatus() != shopstore.ORDER_STATUS_AWAITING_PAYMENT {
+		t.Errorf("expected status %q, got %q", shopstore.ORDER_STATUS_AWAITING_PAYMENT, order.GetStatus())
+	}
+
+	if order.GetMeta(config.ORDER_META_PAYMENT_SESSION_ID) != sessions[1].ID {
+		t.Errorf("expected the latest payment session ID to be stored on the order")
+	}
+}
+
+func TestCheckoutController_PaymentBeginCurrencyFromConfig(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	app.GetConfig().SetPaymentCurrency("EUR")
+	user, session := seedBuyerWithCart(t, app)
+
+	_, _, err := test.CallStringEndpoint(http.MethodPost, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context:    authContext(user, session),
+		FormValues: url.Values{"csrf_token": {csrf.TokenGenerate("test-csrf-secret")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := gateway.Sessions()
+
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 payment session, got %d", len(sessions))
+	}
+
+	if sessions[0].Options.Currency != "EUR" {
+		t.Errorf("expected currency EUR, got %q", sessions[0].Options.Currency)
+	}
+}
+
+func TestCheckoutController_PaymentBeginWithoutGateway(t *testing.T) {
+	app, _ := setupCheckoutApp(t)
+	app.SetPaymentGateway(nil)
+	user, session := seedBuyerWithCart(t, app)
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context:    authContext(user, session),
+		FormValues: url.Values{"csrf_token": {csrf.TokenGenerate("test-csrf-secret")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || !strings.Contains(flashMessage.Message, "Payments are not configured") {
+		t.Errorf("expected payments not configured flash message, got %+v", flashMessage)
+	}
+}
+
+func TestCheckoutController_PaymentBeginInvalidCsrf(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	user, session := seedBuyerWithCart(t, app)
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context:    authContext(user, session),
+		FormValues: url.Values{"csrf_token": {"invalid"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected status %d, got %d", http.StatusSeeOther, response.StatusCode)
+	}
+
+	if len(gateway.Sessions()) != 0 {
+		t.Error("payment session should not be created with an invalid CSRF token")
+	}
+}
+
+func TestCheckoutController_PaymentBeginWithDiscount(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	user, session := seedBuyerWithCart(t, app)
+	d := seedCartDiscount(t, app, user, discount.Discount{Code: "SAVE10", Status: discount.STATUS_ACTIVE, Type: discount.TYPE_PERCENT, Value: "10"})
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context: authContext(user, session),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Subtotal", "Discount (SAVE10)", "-2.50", "22.50"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected body to contain %q", expected)
+		}
+	}
+
+	_, _, err = test.CallStringEndpoint(http.MethodPost, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context:    authContext(user, session),
+		FormValues: url.Values{"csrf_token": {csrf.TokenGenerate("test-csrf-secret")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := gateway.Sessions()
+
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 payment session, got %d", len(sessions))
+	}
+
+	if sessions[0].Amount != 2250 {
+		t.Errorf("expected the discounted amount 2250, got %d", sessions[0].Amount)
+	}
+
+	order, err := app.GetShopStore().OrderFindByID(context.Background(), sessions[0].Options.OrderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order.GetPrice() != "22.50" {
+		t.Errorf("expected order price 22.50, got %q", order.GetPrice())
+	}
+
+	if order.GetMeta(config.ORDER_META_DISCOUNT_ID) != d.ID || order.GetMeta(config.ORDER_META_DISCOUNT_AMOUNT) != "2.50" {
+		t.Errorf("expected the discount to be stored on the order")
+	}
+
+	if order.GetMeta(config.ORDER_META_SUBTOTAL) != "25.00" {
+		t.Errorf("expected subtotal 25.00, got %q", order.GetMeta(config.ORDER_META_SUBTOTAL))
+	}
+}
+
+func TestCheckoutController_DiscountUsageLimitHeldByPendingOrder(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	user, session := seedBuyerWithCart(t, app)
+	seedCartDiscount(t, app, user, discount.Discount{Code: "ONCE", Status: discount.STATUS_ACTIVE, Type: discount.TYPE_PERCENT, Value: "10", UsageLimit: 1})
+
+	paymentBegin := func() *http.Response {
+		_, response, err := test.CallStringEndpoint(http.MethodPost, NewCheckoutController(app).Handler, test.NewRequestOptions{
+			Context:    authContext(user, session),
+			FormValues: url.Values{"csrf_token": {csrf.TokenGenerate("test-csrf-secret")}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return response
+	}
+
+	paymentBegin()
+
+	if len(gateway.Sessions()) != 1 {
+		t.Fatalf("expected 1 payment session, got %d", len(gateway.Sessions()))
+	}
+
+	// A different basket creates a second pending order with the same code
+	basket := cart.Cart{}
+	if err := json.Unmarshal([]byte(user.GetMeta(config.USER_META_CART)), &basket); err != nil {
+		t.Fatal(err)
+	}
+
+	basket.Items[0].Quantity = 3
+
+	basketJSON, err := json.Marshal(basket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := user.SetMeta(config.USER_META_CART, string(basketJSON)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.GetUserStore().UserUpdate(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), paymentBegin())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "error" || !strings.Contains(flashMessage.Message, discount.ErrUsageLimitReached.Error()) {
+		t.Fatalf("expected the usage limit to be reported, got %+v", flashMessage)
+	}
+
+	if len(gateway.Sessions()) != 1 {
+		t.Errorf("the second order must not be sent to the payment gateway, got %d sessions", len(gateway.Sessions()))
+	}
+}
+
+func TestCheckoutController_ExpiredDiscountIsNotApplied(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	user, session := seedBuyerWithCart(t, app)
+	seedCartDiscount(t, app, user, discount.Discount{Code: "OLD10", Status: discount.STATUS_ACTIVE, Type: discount.TYPE_PERCENT, Value: "10", EndsAt: "2020-01-01 00:00:00"})
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context: authContext(user, session),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, discount.ErrExpired.Error()) {
+		t.Error("expected the buyer to be told the discount code has expired")
+	}
+
+	_, _, err = test.CallStringEndpoint(http.MethodPost, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context:    authContext(user, session),
+		FormValues: url.Values{"csrf_token": {csrf.TokenGenerate("test-csrf-secret")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := gateway.Sessions()
+
+	if len(sessions) != 1 || sessions[0].Amount != 2500 {
+		t.Fatalf("expected a single payment session for the full amount, got %+v", sessions)
+	}
+}
+
+func TestCheckoutController_FreeOrderSkipsPayment(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	user, session := seedBuyerWithCart(t, app)
+	d := seedCartDiscount(t, app, user, discount.Discount{Code: "FREE", Status: discount.STATUS_ACTIVE, Type: discount.TYPE_PERCENT, Value: "100"})
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context:    authContext(user, session),
+		FormValues: url.Values{"csrf_token": {csrf.TokenGenerate("test-csrf-secret")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gateway.Sessions()) != 0 {
+		t.Fatal("a free order must not be sent to the payment gateway")
+	}
+
+	flashMessage, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flashMessage == nil || flashMessage.Type != "success" {
+		t.Fatalf("expected a success flash message, got %+v", flashMessage)
+	}
+
+	discountStore, err := discount.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := discountStore.RedemptionCount(d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 1 {
+		t.Errorf("expected the discount redemption to be recorded, got %d", count)
+	}
+
+	confirmationURL, err := url.Parse(flashMessage.Url)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invoiceStore, err := invoice.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issued, err := invoiceStore.InvoiceFindByOrderID(confirmationURL.Query().Get("order_id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if issued == nil || issued.Number != 1 {
+		t.Errorf("expected the invoice to be issued when the free order is placed, got %+v", issued)
+	}
+}
+
+func TestCheckoutController_PaymentBeginWithShippingAndTax(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	user, session := seedBuyerWithCart(t, app)
+
+	methods, err := pricing.ParseShippingMethods(`[{"code":"standard","name":"Standard","countries":["GB"],"tiers":[{"price":"5.00"}]}]`, app.GetConfig().GetPaymentCurrency())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rates, err := pricing.ParseTaxRates(`[{"country":"GB","rate":"20","label":"VAT"}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.SetPricingCalculator(pricing.NewPipeline(
+		pricing.NewShippingCalculator(methods),
+		pricing.NewTaxCalculator(rates, false),
+	))
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context:     authContext(user, session),
+		QueryParams: url.Values{"country": {"fr"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(body, pricing.ErrShippingUnavailable.Error()) {
+		t.Error("expected the shipping to be unavailable for FR")
+	}
+
+	body, _, err = test.CallStringEndpoint(http.MethodGet, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context:     authContext(user, session),
+		QueryParams: url.Values{"country": {"gb"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Standard", "VAT (20%)", "6.00", "36.00"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected body to contain %q", expected)
+		}
+	}
+
+	_, _, err = test.CallStringEndpoint(http.MethodPost, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context: authContext(user, session),
+		FormValues: url.Values{
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+			"country":    {"GB"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := gateway.Sessions()
+
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 payment session, got %d", len(sessions))
+	}
+
+	if sessions[0].Amount != 3600 {
+		t.Errorf("expected the amount with shipping and tax 3600, got %d", sessions[0].Amount)
+	}
+
+	if len(sessions[0].Options.LineItems) != 3 {
+		t.Errorf("expected the product, shipping and tax line items, got %+v", sessions[0].Options.LineItems)
+	}
+
+	order, err := app.GetShopStore().OrderFindByID(context.Background(), sessions[0].Options.OrderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order.GetMeta(config.ORDER_META_SHIPPING_METHOD) != "standard" || order.GetMeta(config.ORDER_META_SHIPPING_AMOUNT) != "5.00" {
+		t.Errorf("expected the shipping to be stored on the order")
+	}
+
+	if order.GetMeta(config.ORDER_META_TAX_AMOUNT) != "6.00" || order.GetMeta(config.ORDER_META_SHIPPING_COUNTRY) != "GB" {
+		t.Errorf("expected the tax to be stored on the order")
+	}
+}
+
+func TestCheckoutController_PaymentBeginOutOfStock(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	app.GetConfig().SetShopStockTrackingEnabled(true)
+	user, session := seedBuyerWithCart(t, app)
+
+	product, err := app.GetShopStore().ProductFindByID(context.Background(), "product_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	product.SetQuantity("1")
+	if err := app.GetShopStore().ProductUpdate(context.Background(), product); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = test.CallStringEndpoint(http.MethodPost, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context:    authContext(user, session),
+		FormValues: url.Values{"csrf_token": {csrf.TokenGenerate("test-csrf-secret")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(gateway.Sessions()) != 0 {
+		t.Fatal("expected no payment session when the items are out of stock")
+	}
+}
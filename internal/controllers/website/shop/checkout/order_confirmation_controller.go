@@ -0,0 +1,142 @@
+package checkout
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"project/internal/payment"
+
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dracory/shopstore"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+// == CONTROLLER ==============================================================
+
+// orderConfirmationController shows the order to the buyer after checkout
+type orderConfirmationController struct {
+	app app.AppInterface
+}
+
+type orderConfirmationControllerData struct {
+	currency  string
+	order     shopstore.OrderInterface
+	lineItems []shopstore.OrderLineItemInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewOrderConfirmationController creates a new order confirmation controller
+func NewOrderConfirmationController(app app.AppInterface) *orderConfirmationController {
+	return &orderConfirmationController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *orderConfirmationController) Handler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.Website().Home(), 10)
+	}
+
+	return layouts.NewPageLayout(controller.app, r, layouts.Options{
+		WebsiteSection: "Shop",
+		Title:          "Order Confirmation",
+		Content:        controller.page(data),
+	}).ToHTML()
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *orderConfirmationController) page(data orderConfirmationControllerData) hb.TagInterface {
+	isPaid := helpers.IsOrderPaid(data.order)
+
+	alert := hb.Div().
+		ClassIfElse(isPaid, "alert alert-success", "alert alert-info").
+		TextIfElse(isPaid,
+			"Thank you, your payment has been received and your order is being processed.",
+			"Your order has been placed and is awaiting the confirmation of your payment.")
+
+	rows := lo.Map(data.lineItems, func(lineItem shopstore.OrderLineItemInterface, _ int) hb.TagInterface {
+		// Line items were stored from minor units, so the price is always valid
+		unitAmount, _ := payment.ToMinorUnits(lineItem.GetPrice(), data.currency)
+		total := unitAmount * cast.ToInt64(lineItem.GetQuantity())
+		return hb.TR().
+			Child(hb.TD().Text(lineItem.GetTitle())).
+			Child(hb.TD().Class("text-end").Text(lineItem.GetQuantity())).
+			Child(hb.TD().Class("text-end").Text(lineItem.GetPrice())).
+			Child(hb.TD().Class("text-end").Text(payment.FormatMinorUnits(total, data.currency)))
+	})
+
+	table := hb.Table().
+		Class("table table-striped").
+		Child(hb.Thead().Child(hb.TR().
+			Child(hb.TH().Text("Product")).
+			Child(hb.TH().Class("text-end").Text("Quantity")).
+			Child(hb.TH().Class("text-end").Text("Price")).
+			Child(hb.TH().Class("text-end").Text("Total")))).
+		Child(hb.Tbody().Children(rows)).
+		Child(hb.Tfoot().Child(hb.TR().
+			Child(hb.TH().Attr("colspan", "3").Class("text-end").Text("Order Total")).
+			Child(hb.TH().Class("text-end").Text(data.currency + " " + data.order.GetPrice()))))
+
+	return hb.Section().
+		Style("padding-top:40px; padding-bottom:60px;").
+		Child(hb.Div().
+			Class("container").
+			Child(hb.Heading1().Class("mb-4").Text("Order Confirmation")).
+			Child(hb.Paragraph().Text("Order reference: " + data.order.GetID())).
+			Child(alert).
+			Child(table).
+			Child(hb.A().Class("btn btn-secondary").Href(links.Website().Home()).Text("Continue Shopping")))
+}
+
+func (controller *orderConfirmationController) prepareData(r *http.Request) (data orderConfirmationControllerData, errorMessage string) {
+	if controller.app.GetShopStore() == nil {
+		return data, "Sorry, the shop is currently unavailable. Please try again later."
+	}
+
+	authUser := helpers.GetAuthUser(r)
+
+	if authUser == nil {
+		return data, "Please log in to view your order."
+	}
+
+	orderID := req.GetStringTrimmed(r, "order_id")
+
+	if orderID == "" {
+		return data, "Order ID is required"
+	}
+
+	order, err := controller.app.GetShopStore().OrderFindByID(r.Context(), orderID)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At orderConfirmationController > prepareData > OrderFindByID", slog.String("error", err.Error()))
+		return data, "Sorry, there was an error loading your order. Please try again later."
+	}
+
+	// Do not reveal the existence of orders of other customers
+	if order == nil || order.GetCustomerID() != authUser.GetID() {
+		return data, "Order not found"
+	}
+
+	lineItems, err := controller.app.GetShopStore().OrderLineItemList(r.Context(), shopstore.NewOrderLineItemQuery().
+		SetOrderID(order.GetID()))
+
+	if err != nil {
+		controller.app.GetLogger().Error("At orderConfirmationController > prepareData > OrderLineItemList", slog.String("error", err.Error()))
+		return data, "Sorry, there was an error loading your order. Please try again later."
+	}
+
+	data.currency = controller.app.GetConfig().GetPaymentCurrency()
+	data.order = order
+	data.lineItems = lineItems
+
+	return data, ""
+}
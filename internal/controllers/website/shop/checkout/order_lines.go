@@ -0,0 +1,192 @@
+package checkout
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"project/internal/controllers/website/shop/cart"
+	"project/internal/helpers"
+	"project/internal/payment"
+	"project/internal/pricing"
+	"strings"
+
+	"github.com/dracory/shopstore"
+	"github.com/samber/lo"
+)
+
+// orderLine is a cart item re-validated against the shop store,
+// ready to be converted into an order line item
+type orderLine struct {
+	ProductID string
+	Title     string
+	Quantity  int
+	// UnitAmount the unit price in minor units of the currency
+	UnitAmount int64
+	// Weight the weight of one unit in grams, zero when unknown
+	Weight int64
+}
+
+// Total returns the line total (unit price times quantity) in minor units
+func (line orderLine) Total() int64 {
+	return line.UnitAmount * int64(line.Quantity)
+}
+
+// orderLinesFromCart converts the cart items to order lines.
+//
+// The prices and titles stored in the cart are not trusted, each product
+// is looked up again in the shop store, following the same anti-tampering
+// approach as the cart API.
+func orderLinesFromCart(ctx context.Context, shopStore shopstore.StoreInterface, c cart.Cart, currency string) ([]orderLine, error) {
+	if shopStore == nil {
+		return nil, errors.New("shop store not available")
+	}
+
+	lines := []orderLine{}
+
+	for _, item := range c.Items {
+		if item.Quantity <= 0 {
+			continue
+		}
+
+		product, err := shopStore.ProductFindByID(ctx, item.ProductID)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to find product: %w", err)
+		}
+
+		if product == nil || product.GetStatus() != shopstore.PRODUCT_STATUS_ACTIVE {
+			return nil, fmt.Errorf("product %q is no longer available", item.ProductName)
+		}
+
+		unitAmount, err := payment.ToMinorUnits(product.GetPrice(), currency)
+
+		if err != nil {
+			return nil, fmt.Errorf("product %q has an invalid price: %w", product.GetID(), err)
+		}
+
+		lines = append(lines, orderLine{
+			ProductID:  product.GetID(),
+			Title:      product.GetTitle(),
+			Quantity:   item.Quantity,
+			UnitAmount: unitAmount,
+			Weight:     helpers.ProductWeight(product),
+		})
+	}
+
+	return lines, nil
+}
+
+// orderLinesToQuoteItems converts the order lines to the items priced
+// by the pricing calculator
+func orderLinesToQuoteItems(lines []orderLine) []pricing.Item {
+	return lo.Map(lines, func(line orderLine, _ int) pricing.Item {
+		return pricing.Item{
+			ProductID:  line.ProductID,
+			Name:       line.Title,
+			Quantity:   line.Quantity,
+			UnitAmount: line.UnitAmount,
+			Weight:     line.Weight,
+		}
+	})
+}
+
+// orderLinesTotal returns the sum of all the line totals in minor units
+func orderLinesTotal(lines []orderLine) int64 {
+	total := int64(0)
+	for _, line := range lines {
+		total += line.Total()
+	}
+	return total
+}
+
+// orderLinesQuantity returns the total number of units ordered
+func orderLinesQuantity(lines []orderLine) int {
+	quantity := 0
+	for _, line := range lines {
+		quantity += line.Quantity
+	}
+	return quantity
+}
+
+// checkoutIdempotencyKey returns a key which is the same for the same buyer
+// checking out the same basket with the same discount, shipping and tax, so
+// that submitting the checkout twice (double click, back button, retry after
+// canceling the payment) reuses the existing order instead of creating
+// a duplicate one
+func checkoutIdempotencyKey(buyerID string, lines []orderLine, pricingKey string) string {
+	parts := []string{buyerID}
+	for _, line := range lines {
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", line.ProductID, line.Quantity, line.UnitAmount))
+	}
+
+	if pricingKey != "" {
+		parts = append(parts, "pricing:"+pricingKey)
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(hash[:])
+}
+
+// quotePricingKey identifies the price breakdown (discount, shipping, tax)
+// of the quote in the checkout idempotency key
+func quotePricingKey(quote *pricing.Quote) string {
+	if quote == nil {
+		return ""
+	}
+
+	parts := lo.Map(quote.Lines, func(line pricing.Line, _ int) string {
+		return fmt.Sprintf("%s:%s:%d", line.Type, line.Code, line.Amount)
+	})
+
+	return strings.Join(parts, ",")
+}
+
+// paymentLineItems converts the order lines and the price breakdown to the
+// line items sent to the payment provider. The shipping and the tax (unless
+// already included in the prices) are sent as line items of their own.
+// Payment providers do not accept negative line items, so when a discount
+// is applied a single line for the order total is sent instead
+func paymentLineItems(lines []orderLine, quote *pricing.Quote) []payment.LineItem {
+	items := lo.Map(lines, func(line orderLine, _ int) payment.LineItem {
+		return payment.LineItem{
+			Name:       line.Title,
+			Quantity:   int64(line.Quantity),
+			UnitAmount: line.UnitAmount,
+		}
+	})
+
+	if quote == nil {
+		return items
+	}
+
+	discountCodes := []string{}
+
+	for _, line := range quote.Lines {
+		if line.Included || line.Amount == 0 {
+			continue
+		}
+
+		if line.Amount < 0 {
+			discountCodes = append(discountCodes, line.Code)
+			continue
+		}
+
+		items = append(items, payment.LineItem{
+			Name:       line.Label,
+			Quantity:   1,
+			UnitAmount: line.Amount,
+		})
+	}
+
+	if len(discountCodes) == 0 {
+		return items
+	}
+
+	return []payment.LineItem{{
+		Name:       fmt.Sprintf("Order of %d item(s), discount code %s applied", orderLinesQuantity(lines), strings.Join(discountCodes, ", ")),
+		Quantity:   1,
+		UnitAmount: quote.Total(),
+	}}
+}
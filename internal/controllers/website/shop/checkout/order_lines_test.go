@@ -0,0 +1,163 @@
+package checkout
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"project/internal/controllers/website/shop/cart"
+	"project/internal/pricing"
+	"project/internal/testutils"
+)
+
+func TestOrderLineTotal(t *testing.T) {
+	line := orderLine{ProductID: "p1", Title: "Product", Quantity: 3, UnitAmount: 1999}
+
+	if line.Total() != 5997 {
+		t.Errorf("Total() = %d, want %d", line.Total(), 5997)
+	}
+}
+
+func TestOrderLinesTotalAndQuantity(t *testing.T) {
+	lines := []orderLine{
+		{ProductID: "p1", Quantity: 2, UnitAmount: 1010},
+		{ProductID: "p2", Quantity: 1, UnitAmount: 20},
+	}
+
+	if total := orderLinesTotal(lines); total != 2040 {
+		t.Errorf("orderLinesTotal() = %d, want %d", total, 2040)
+	}
+
+	if quantity := orderLinesQuantity(lines); quantity != 3 {
+		t.Errorf("orderLinesQuantity() = %d, want %d", quantity, 3)
+	}
+}
+
+func TestCheckoutIdempotencyKey(t *testing.T) {
+	lines := []orderLine{{ProductID: "p1", Quantity: 2, UnitAmount: 1000}}
+
+	key1 := checkoutIdempotencyKey("user1", lines, "")
+	key2 := checkoutIdempotencyKey("user1", lines, "")
+
+	if key1 == "" {
+		t.Fatal("checkoutIdempotencyKey() should not be empty")
+	}
+
+	if key1 != key2 {
+		t.Error("checkoutIdempotencyKey() should be the same for the same buyer and basket")
+	}
+
+	if key1 == checkoutIdempotencyKey("user2", lines, "") {
+		t.Error("checkoutIdempotencyKey() should differ between buyers")
+	}
+
+	changed := []orderLine{{ProductID: "p1", Quantity: 3, UnitAmount: 1000}}
+	if key1 == checkoutIdempotencyKey("user1", changed, "") {
+		t.Error("checkoutIdempotencyKey() should differ when the basket changes")
+	}
+
+	if key1 == checkoutIdempotencyKey("user1", lines, "discount_1:200") {
+		t.Error("checkoutIdempotencyKey() should differ when a discount is applied")
+	}
+}
+
+func TestPaymentLineItems(t *testing.T) {
+	lines := []orderLine{
+		{ProductID: "p1", Title: "Product 1", Quantity: 2, UnitAmount: 1000},
+		{ProductID: "p2", Title: "Product 2", Quantity: 1, UnitAmount: 500},
+	}
+
+	quote := pricing.NewQuote("GBP", pricing.Address{}, orderLinesToQuoteItems(lines))
+
+	items := paymentLineItems(lines, quote)
+	if len(items) != 2 || items[0].Name != "Product 1" || items[0].Quantity != 2 || items[0].UnitAmount != 1000 {
+		t.Fatalf("expected one line item per order line, got %+v", items)
+	}
+
+	quote.AddLine(pricing.Line{Type: pricing.LINE_TYPE_SHIPPING, Code: "standard", Label: "Standard", Amount: 500})
+	quote.AddLine(pricing.Line{Type: pricing.LINE_TYPE_TAX, Code: "GB", Label: "VAT (20%)", Amount: 500, Included: true})
+
+	items = paymentLineItems(lines, quote)
+	if len(items) != 3 || items[2].Name != "Standard" || items[2].Quantity != 1 || items[2].UnitAmount != 500 {
+		t.Fatalf("expected the shipping as a line item and the included tax left out, got %+v", items)
+	}
+
+	quote.AddLine(pricing.Line{Type: pricing.LINE_TYPE_DISCOUNT, Code: "SAVE10", Label: "Discount (SAVE10)", Amount: -250})
+
+	items = paymentLineItems(lines, quote)
+	if len(items) != 1 {
+		t.Fatalf("expected a single line item with a discount, got %+v", items)
+	}
+	if items[0].Quantity != 1 || items[0].UnitAmount != 2750 {
+		t.Errorf("expected the discounted total 2750, got %+v", items[0])
+	}
+	if !strings.Contains(items[0].Name, "SAVE10") {
+		t.Errorf("expected the line item to mention the code, got %q", items[0].Name)
+	}
+}
+
+func TestQuotePricingKey(t *testing.T) {
+	if quotePricingKey(nil) != "" {
+		t.Error("quotePricingKey() should be empty without a quote")
+	}
+
+	quote := pricing.NewQuote("GBP", pricing.Address{}, nil)
+	quote.AddLine(pricing.Line{Type: pricing.LINE_TYPE_SHIPPING, Code: "standard", Amount: 500})
+
+	key := quotePricingKey(quote)
+	if key != "shipping:standard:500" {
+		t.Errorf("quotePricingKey() = %q", key)
+	}
+
+	quote.Lines[0].Amount = 900
+	if quotePricingKey(quote) == key {
+		t.Error("quotePricingKey() should differ when the shipping changes")
+	}
+}
+
+func TestOrderLinesFromCart_UsesStorePrice(t *testing.T) {
+	app := testutils.Setup(testutils.WithShopStore(true))
+
+	product, err := testutils.SeedProduct(app.GetShopStore(), "product_1", 25.50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	basket := cart.Cart{Items: []cart.CartItem{
+		{ProductID: product.GetID(), ProductName: "Tampered", Price: "0.01", Quantity: 2},
+	}}
+
+	lines, err := orderLinesFromCart(context.Background(), app.GetShopStore(), basket, "GBP")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d", len(lines))
+	}
+
+	if lines[0].UnitAmount != 2550 {
+		t.Errorf("expected price from the shop store 2550, got %d", lines[0].UnitAmount)
+	}
+
+	if lines[0].Title != product.GetTitle() {
+		t.Errorf("expected title from the shop store %q, got %q", product.GetTitle(), lines[0].Title)
+	}
+}
+
+func TestOrderLinesFromCart_MissingProduct(t *testing.T) {
+	app := testutils.Setup(testutils.WithShopStore(true))
+
+	basket := cart.Cart{Items: []cart.CartItem{
+		{ProductID: "missing", ProductName: "Missing", Price: "1.00", Quantity: 1},
+	}}
+
+	_, err := orderLinesFromCart(context.Background(), app.GetShopStore(), basket, "GBP")
+	if err == nil {
+		t.Fatal("expected error for missing product")
+	}
+
+	if !strings.Contains(err.Error(), "no longer available") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
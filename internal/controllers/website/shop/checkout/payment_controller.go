@@ -0,0 +1,170 @@
+package checkout
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/controllers/website/shop/cart"
+	"project/internal/helpers"
+	"project/internal/links"
+
+	"github.com/dracory/req"
+	"github.com/dracory/shopstore"
+)
+
+// == CONTROLLER ==============================================================
+
+// paymentController handles the buyer returning from the payment provider
+type paymentController struct {
+	app app.AppInterface
+}
+
+type paymentControllerData struct {
+	paymentKey  string
+	paymentData helpers.PaymentCodeData
+	order       shopstore.OrderInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+// NewPaymentController creates a new payment return controller
+func NewPaymentController(app app.AppInterface) *paymentController {
+	return &paymentController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+// SuccessHandler handles the success return URL.
+//
+// The return URL alone is not a proof of payment, so the checkout session
+// is verified with the payment provider before the order is marked as paid.
+// Visiting the URL again (refresh, back button) is safe, as a paid order
+// only redirects to the confirmation page.
+func (controller *paymentController) SuccessHandler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.Website().Home(), 10)
+	}
+
+	confirmationURL := links.Website().ShopOrderConfirmation(data.order.GetID())
+
+	if helpers.IsOrderPaid(data.order) {
+		http.Redirect(w, r, confirmationURL, http.StatusSeeOther)
+		return ""
+	}
+
+	sessionID := data.order.GetMeta(config.ORDER_META_PAYMENT_SESSION_ID)
+	isPaid, err := controller.app.GetPaymentGateway().CheckoutVerify(r.Context(), sessionID)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At paymentController > SuccessHandler > CheckoutVerify", slog.String("error", err.Error()), slog.String("order_id", data.order.GetID()))
+	}
+
+	if !isPaid {
+		return helpers.ToFlashInfo(controller.app.GetCacheStore(), w, r, "We have not received the confirmation of your payment yet. Your order will be updated as soon as it arrives.", confirmationURL, 10)
+	}
+
+	if err := helpers.OrderMarkAsPaid(r.Context(), controller.app.GetShopStore(), data.order); err != nil {
+		controller.app.GetLogger().Error("At paymentController > SuccessHandler > OrderMarkAsPaid", slog.String("error", err.Error()), slog.String("order_id", data.order.GetID()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your payment was received, but we could not update your order. Our team has been notified.", confirmationURL, 10)
+	}
+
+	if err := helpers.OrderInvoiceIssue(controller.app, data.order); err != nil {
+		controller.app.GetLogger().Error("At paymentController > SuccessHandler > OrderInvoiceIssue", slog.String("error", err.Error()), slog.String("order_id", data.order.GetID()))
+	}
+
+	if err := helpers.OrderDiscountRedeem(controller.app, data.order); err != nil {
+		controller.app.GetLogger().Error("At paymentController > SuccessHandler > OrderDiscountRedeem", slog.String("error", err.Error()), slog.String("order_id", data.order.GetID()))
+	}
+
+	if err := helpers.OrderStockCommit(r.Context(), controller.app, data.order); err != nil {
+		controller.app.GetLogger().Error("At paymentController > SuccessHandler > OrderStockCommit", slog.String("error", err.Error()), slog.String("order_id", data.order.GetID()))
+	}
+
+	// The basket is now an order, so a new checkout must start a new order
+	if err := controller.app.GetCacheStore().Remove(helpers.IDEMPOTENCY_CACHE_KEY_PREFIX + data.paymentData.IdempotencyKey); err != nil {
+		controller.app.GetLogger().Warn("At paymentController > SuccessHandler > Remove idempotency key", slog.String("error", err.Error()))
+	}
+
+	if authUser := helpers.GetAuthUser(r); authUser != nil {
+		if err := cart.NewCartController(controller.app).ClearCart(r.Context(), r, authUser); err != nil {
+			controller.app.GetLogger().Warn("At paymentController > SuccessHandler > ClearCart", slog.String("error", err.Error()))
+		}
+	}
+
+	http.Redirect(w, r, confirmationURL, http.StatusSeeOther)
+	return ""
+}
+
+// CanceledHandler handles the cancel return URL.
+//
+// The order is left awaiting payment, and the basket is kept, so checking
+// out again reuses the same order via the idempotency key. The use of the
+// discount code is released, and held again on the next checkout.
+func (controller *paymentController) CanceledHandler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, links.Website().Home(), 10)
+	}
+
+	if helpers.IsOrderPaid(data.order) {
+		http.Redirect(w, r, links.Website().ShopOrderConfirmation(data.order.GetID()), http.StatusSeeOther)
+		return ""
+	}
+
+	if err := helpers.OrderDiscountRelease(controller.app, data.order); err != nil {
+		controller.app.GetLogger().Error("At paymentController > CanceledHandler > OrderDiscountRelease", slog.String("error", err.Error()), slog.String("order_id", data.order.GetID()))
+	}
+
+	return helpers.ToFlashInfo(controller.app.GetCacheStore(), w, r, "Your payment was canceled. Your cart has been kept, so you can complete your order at any time.", links.Website().ShopCheckout(), 10)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *paymentController) prepareData(r *http.Request) (data paymentControllerData, errorMessage string) {
+	if controller.app.GetShopStore() == nil || controller.app.GetCacheStore() == nil || controller.app.GetPaymentGateway() == nil {
+		return data, "Sorry, the shop is currently unavailable. Please try again later."
+	}
+
+	authUser := helpers.GetAuthUser(r)
+
+	if authUser == nil {
+		return data, "Please log in to view your order."
+	}
+
+	data.paymentKey = req.GetStringTrimmed(r, "payment_key")
+
+	if data.paymentKey == "" {
+		return data, "Payment key is required"
+	}
+
+	paymentData, err := helpers.GetPaymentCodeData(controller.app, data.paymentKey)
+
+	if err != nil || paymentData.OrderID == "" {
+		return data, "The payment link is invalid or has expired."
+	}
+
+	if paymentData.BuyerID != authUser.GetID() {
+		controller.app.GetLogger().Warn("At paymentController > prepareData", slog.String("error", "buyer mismatch"), slog.String("order_id", paymentData.OrderID))
+		return data, "The payment link is invalid or has expired."
+	}
+
+	order, err := controller.app.GetShopStore().OrderFindByID(r.Context(), paymentData.OrderID)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At paymentController > prepareData > OrderFindByID", slog.String("error", err.Error()))
+		return data, "Sorry, there was an error loading your order. Please try again later."
+	}
+
+	if order == nil {
+		return data, "Order not found"
+	}
+
+	data.paymentData = paymentData
+	data.order = order
+
+	return data, ""
+}
@@ -0,0 +1,204 @@
+package checkout
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/inventory"
+	"project/internal/links"
+	"project/internal/payment"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/sessionstore"
+	"github.com/dracory/shopstore"
+	"github.com/dracory/test"
+	"github.com/dracory/userstore"
+)
+
+// beginCheckout submits the checkout form and returns the created order ID
+// together with the payment key found in the success return URL
+func beginCheckout(t *testing.T, app app.AppInterface, gateway *payment.FakeGateway, user userstore.UserInterface, session sessionstore.SessionInterface) (orderID string, paymentKey string) {
+	t.Helper()
+
+	_, _, err := test.CallStringEndpoint(http.MethodPost, NewCheckoutController(app).Handler, test.NewRequestOptions{
+		Context:    authContext(user, session),
+		FormValues: url.Values{"csrf_token": {csrf.TokenGenerate("test-csrf-secret")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := gateway.Sessions()
+	if len(sessions) == 0 {
+		t.Fatal("expected the checkout to create a payment session")
+	}
+
+	latest := sessions[len(sessions)-1]
+
+	successURL, err := url.Parse(latest.Options.SuccessURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orderID = latest.Options.OrderID
+	paymentKey = successURL.Query().Get("payment_key")
+
+	if orderID == "" || paymentKey == "" {
+		t.Fatal("expected the checkout to create an order and a payment key")
+	}
+
+	return orderID, paymentKey
+}
+
+func TestPaymentController_SuccessMarksOrderAsPaid(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	user, session := seedBuyerWithCart(t, app)
+	orderID, paymentKey := beginCheckout(t, app, gateway, user, session)
+
+	controller := NewPaymentController(app)
+
+	// Calling the success URL twice must be safe
+	for i := 0; i < 2; i++ {
+		_, response, err := test.CallStringEndpoint(http.MethodGet, controller.SuccessHandler, test.NewRequestOptions{
+			Context:     authContext(user, session),
+			QueryParams: url.Values{"payment_key": {paymentKey}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if response.Header.Get("Location") != links.Website().ShopOrderConfirmation(orderID) {
+			t.Fatalf("expected redirect to the order confirmation, got %q", response.Header.Get("Location"))
+		}
+	}
+
+	order, err := app.GetShopStore().OrderFindByID(context.Background(), orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order.GetStatus() != shopstore.ORDER_STATUS_AWAITING_FULFILLMENT {
+		t.Errorf("expected status %q, got %q", shopstore.ORDER_STATUS_AWAITING_FULFILLMENT, order.GetStatus())
+	}
+}
+
+func TestPaymentController_SuccessNotVerified(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	gateway.SetAutoPay(false)
+	user, session := seedBuyerWithCart(t, app)
+	orderID, paymentKey := beginCheckout(t, app, gateway, user, session)
+
+	_, _, err := test.CallStringEndpoint(http.MethodGet, NewPaymentController(app).SuccessHandler, test.NewRequestOptions{
+		Context:     authContext(user, session),
+		QueryParams: url.Values{"payment_key": {paymentKey}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := app.GetShopStore().OrderFindByID(context.Background(), orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order.GetStatus() != shopstore.ORDER_STATUS_AWAITING_PAYMENT {
+		t.Errorf("expected status %q, got %q", shopstore.ORDER_STATUS_AWAITING_PAYMENT, order.GetStatus())
+	}
+}
+
+func TestPaymentController_CanceledKeepsOrderAwaitingPayment(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	gateway.SetAutoPay(false)
+	user, session := seedBuyerWithCart(t, app)
+	orderID, paymentKey := beginCheckout(t, app, gateway, user, session)
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewPaymentController(app).CanceledHandler, test.NewRequestOptions{
+		Context:     authContext(user, session),
+		QueryParams: url.Values{"payment_key": {paymentKey}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected status %d, got %d", http.StatusSeeOther, response.StatusCode)
+	}
+
+	order, err := app.GetShopStore().OrderFindByID(context.Background(), orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order.GetStatus() != shopstore.ORDER_STATUS_AWAITING_PAYMENT {
+		t.Errorf("expected status %q, got %q", shopstore.ORDER_STATUS_AWAITING_PAYMENT, order.GetStatus())
+	}
+
+	// Checking out again after canceling must reuse the same order
+	secondOrderID, _ := beginCheckout(t, app, gateway, user, session)
+	if secondOrderID != orderID {
+		t.Errorf("expected order %q to be reused, got %q", orderID, secondOrderID)
+	}
+}
+
+func TestPaymentController_InvalidPaymentKey(t *testing.T) {
+	app, _ := setupCheckoutApp(t)
+	user, session := seedBuyerWithCart(t, app)
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewPaymentController(app).SuccessHandler, test.NewRequestOptions{
+		Context:     authContext(user, session),
+		QueryParams: url.Values{"payment_key": {"invalid"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected status %d, got %d", http.StatusSeeOther, response.StatusCode)
+	}
+}
+
+func TestPaymentController_SuccessCommitsReservedStock(t *testing.T) {
+	app, gateway := setupCheckoutApp(t)
+	app.GetConfig().SetShopStockTrackingEnabled(true)
+	user, session := seedBuyerWithCart(t, app)
+	orderID, paymentKey := beginCheckout(t, app, gateway, user, session)
+
+	reservationStore, err := inventory.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reservations, err := reservationStore.ReservationListByOrder(orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reservations) != 1 || reservations[0].Quantity != 2 || reservations[0].Status != inventory.RESERVATION_STATUS_ACTIVE {
+		t.Fatalf("expected the ordered quantity to be reserved, got %+v", reservations)
+	}
+
+	controller := NewPaymentController(app)
+
+	// Calling the success URL twice must decrement the stock once
+	for i := 0; i < 2; i++ {
+		_, _, err := test.CallStringEndpoint(http.MethodGet, controller.SuccessHandler, test.NewRequestOptions{
+			Context:     authContext(user, session),
+			QueryParams: url.Values{"payment_key": {paymentKey}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	product, err := app.GetShopStore().ProductFindByID(context.Background(), "product_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stock := inventory.ProductStock(product); stock != 8 {
+		t.Errorf("expected the stock to be decremented to 8, got %d", stock)
+	}
+}
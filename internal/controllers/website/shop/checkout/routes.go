@@ -0,0 +1,44 @@
+package checkout
+
+import (
+	"project/internal/app"
+	"project/internal/links"
+
+	"github.com/dracory/rtr"
+)
+
+// Routes returns the checkout, payment return and order confirmation routes
+func Routes(app app.AppInterface) []rtr.RouteInterface {
+	if app == nil || app.GetConfig() == nil {
+		return []rtr.RouteInterface{}
+	}
+
+	paymentController := NewPaymentController(app)
+
+	checkoutRoute := rtr.NewRoute().
+		SetName("Website > Shop Checkout").
+		SetPath(links.SHOP_CHECKOUT).
+		SetHTMLHandler(NewCheckoutController(app).Handler)
+
+	paymentSuccessRoute := rtr.NewRoute().
+		SetName("Website > Payment Success").
+		SetPath(links.PAYMENT_SUCCESS).
+		SetHTMLHandler(paymentController.SuccessHandler)
+
+	paymentCanceledRoute := rtr.NewRoute().
+		SetName("Website > Payment Canceled").
+		SetPath(links.PAYMENT_CANCELED).
+		SetHTMLHandler(paymentController.CanceledHandler)
+
+	orderConfirmationRoute := rtr.NewRoute().
+		SetName("Website > Shop Order Confirmation").
+		SetPath(links.SHOP_ORDER_CONFIRMATION).
+		SetHTMLHandler(NewOrderConfirmationController(app).Handler)
+
+	return []rtr.RouteInterface{
+		checkoutRoute,
+		paymentSuccessRoute,
+		paymentCanceledRoute,
+		orderConfirmationRoute,
+	}
+}
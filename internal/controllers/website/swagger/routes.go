@@ -6,7 +6,7 @@ import (
 	"github.com/dracory/rtr"
 )
 
-// Routes returns the routes for Swagger UI and YAML
+// Routes returns the route of the Swagger UI
 func Routes() []rtr.RouteInterface {
 	return []rtr.RouteInterface{
 		rtr.NewRoute().
@@ -14,11 +14,5 @@ func Routes() []rtr.RouteInterface {
 			SetPath("/swagger").
 			SetHandler(SwaggerUIController).
 			SetMethod(http.MethodGet),
-
-		rtr.NewRoute().
-			SetName("Swagger YAML").
-			SetPath("/docs/swagger.yaml").
-			SetHandler(SwaggerYAMLController).
-			SetMethod(http.MethodGet),
 	}
 }
@@ -6,10 +6,11 @@ import (
 	"net/http"
 )
 
-//go:embed swagger-ui.html swagger.yaml
+//go:embed swagger-ui.html
 var swaggerFiles embed.FS
 
-// SwaggerUIController serves the embedded Swagger UI HTML
+// SwaggerUIController serves the embedded Swagger UI HTML, which shows the
+// OpenAPI document generated by the API (/api/v1/openapi.json)
 func SwaggerUIController(w http.ResponseWriter, r *http.Request) {
 	data, err := swaggerFiles.ReadFile("swagger-ui.html")
 	if err != nil {
@@ -21,16 +22,3 @@ func SwaggerUIController(w http.ResponseWriter, r *http.Request) {
 		log.Printf("swagger: failed to write swagger-ui response: %v", err)
 	}
 }
-
-// SwaggerYAMLController serves the embedded Swagger YAML file
-func SwaggerYAMLController(w http.ResponseWriter, r *http.Request) {
-	data, err := swaggerFiles.ReadFile("swagger.yaml")
-	if err != nil {
-		http.Error(w, "Swagger YAML not found", http.StatusInternalServerError)
-		return
-	}
-	w.Header().Set("Content-Type", "application/x-yaml; charset=utf-8")
-	if _, err := w.Write(data); err != nil {
-		log.Printf("swagger: failed to write swagger.yaml response: %v", err)
-	}
-}
@@ -31,26 +31,13 @@ func TestSwaggerUIController(t *testing.T) {
 	}
 }
 
-func TestSwaggerYAMLController(t *testing.T) {
-	req := httptest.NewRequest("GET", "/swagger.yaml", nil)
+func TestSwaggerUIController_LoadsGeneratedDocument(t *testing.T) {
+	req := httptest.NewRequest("GET", "/swagger", nil)
 	w := httptest.NewRecorder()
 
-	SwaggerYAMLController(w, req)
-
-	if w.Code != http.StatusOK {
-		t.Errorf("SwaggerYAMLController() status = %d, want %d", w.Code, http.StatusOK)
-	}
-
-	contentType := w.Header().Get("Content-Type")
-	if contentType != "application/x-yaml; charset=utf-8" {
-		t.Errorf("Content-Type = %q, want %q", contentType, "application/x-yaml; charset=utf-8")
-	}
+	SwaggerUIController(w, req)
 
-	body := w.Body.String()
-	if len(body) == 0 {
-		t.Error("SwaggerYAMLController() returned empty body")
-	}
-	if !strings.Contains(body, "openapi") && !strings.Contains(body, "swagger") {
-		t.Error("SwaggerYAMLController() body does not contain expected swagger YAML content")
+	if !strings.Contains(w.Body.String(), "/api/v1/openapi.json") {
+		t.Error("SwaggerUIController() should load the generated OpenAPI document")
 	}
 }
@@ -0,0 +1,74 @@
+package customrecords
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/dracory/customstore"
+)
+
+// Claim creates the record of the type for the key, and returns whether it
+// was created, or false when the key was already claimed.
+//
+// The ID of the record is derived from the type and the key, and the ID is
+// the primary key of the custom store, so of the concurrent claims of the
+// same key (e.g. the webhook and the return URL of the same payment) only
+// one succeeds, in all the instances of the application.
+//
+// Example:
+//
+//	claimed, err := customrecords.Claim(customStore, RECORD_TYPE_REDEMPTION, orderID, payload)
+//	if err != nil || !claimed {
+//		return err // already done by another request
+//	}
+func Claim(customStore customstore.StoreInterface, recordType string, key string, payload string) (bool, error) {
+	if customStore == nil {
+		return false, errors.New("custom store is required")
+	}
+
+	if recordType == "" || key == "" {
+		return false, errors.New("record type and key are required")
+	}
+
+	id := ClaimID(recordType, key)
+
+	record := customstore.NewRecord(recordType, customstore.WithID(id), customstore.WithPayload(payload))
+
+	createErr := customStore.RecordCreate(record)
+
+	if createErr == nil {
+		return true, nil
+	}
+
+	// The create fails on the duplicate ID, as on any other error, so
+	// confirm the key was claimed
+	existing, err := customStore.RecordFindByID(id)
+	if err != nil {
+		return false, err
+	}
+
+	if existing == nil {
+		return false, createErr
+	}
+
+	return false, nil
+}
+
+// Unclaim removes the claim of the key, so it can be claimed again, e.g.
+// when the work claimed failed and must be retried
+func Unclaim(customStore customstore.StoreInterface, recordType string, key string) error {
+	if customStore == nil {
+		return errors.New("custom store is required")
+	}
+
+	return customStore.RecordDeleteByID(ClaimID(recordType, key))
+}
+
+// ClaimID returns the ID of the record claiming the key, a hash of the type
+// and the key fitting the ID column
+func ClaimID(recordType string, key string) string {
+	hash := sha256.Sum256([]byte(recordType + "\n" + key))
+
+	return hex.EncodeToString(hash[:20])
+}
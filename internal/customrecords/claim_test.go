@@ -0,0 +1,73 @@
+package customrecords
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClaim(t *testing.T) {
+	customStore := newTestCustomStore(t)
+
+	claimed, err := Claim(customStore, "test_claim", "order-1", `{"order_id":"order-1"}`)
+	if err != nil || !claimed {
+		t.Fatalf("expected the first claim to succeed, got %v %v", claimed, err)
+	}
+
+	claimed, err = Claim(customStore, "test_claim", "order-1", `{"order_id":"order-1"}`)
+	if err != nil || claimed {
+		t.Fatalf("expected the second claim to fail without error, got %v %v", claimed, err)
+	}
+
+	// The keys are claimed per type
+	claimed, err = Claim(customStore, "other_claim", "order-1", "")
+	if err != nil || !claimed {
+		t.Fatalf("expected the claim of another type to succeed, got %v %v", claimed, err)
+	}
+
+	if err := Unclaim(customStore, "test_claim", "order-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err = Claim(customStore, "test_claim", "order-1", "")
+	if err != nil || !claimed {
+		t.Fatalf("expected the key to be claimed again once unclaimed, got %v %v", claimed, err)
+	}
+}
+
+func TestClaim_Concurrent(t *testing.T) {
+	customStore := newTestCustomStore(t)
+
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if claimed, _ := Claim(customStore, "test_claim", "order-2", ""); claimed {
+				wins.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if wins.Load() != 1 {
+		t.Errorf("expected exactly one claim to succeed, got %d", wins.Load())
+	}
+}
+
+func TestClaimID(t *testing.T) {
+	id := ClaimID("test_claim", "order-1")
+
+	if len(id) != 40 {
+		t.Errorf("expected a 40 characters ID, got %d", len(id))
+	}
+
+	if id == ClaimID("test_claim", "order-2") || id == ClaimID("other_claim", "order-1") {
+		t.Error("expected the ID to depend on the type and the key")
+	}
+}
@@ -0,0 +1,80 @@
+package customrecords
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/dracory/customstore"
+)
+
+// FindByPayload returns the records of the query whose payloads have all the
+// fields, with exactly the values. Without fields, it returns all the
+// records of the query.
+//
+// The payload searches of the custom store are combined with OR, so only
+// the field with the longest value (the most selective one) is searched,
+// and the others are confirmed on the records found.
+//
+// Example:
+//
+//	records, err := customrecords.FindByPayload(customStore, customstore.NewRecordQuery().
+//		SetType(RECORD_TYPE_INVOICE), map[string]string{"order_id": orderID})
+func FindByPayload(customStore customstore.StoreInterface, query customstore.RecordQueryInterface, fields map[string]string) ([]customstore.RecordInterface, error) {
+	if key, found := searchKey(fields); found {
+		needle, err := PayloadNeedle(key, fields[key])
+		if err != nil {
+			return nil, err
+		}
+
+		query = query.AddPayloadSearch(needle)
+	}
+
+	records, err := customStore.RecordList(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fields) == 0 {
+		return records, nil
+	}
+
+	matching := []customstore.RecordInterface{}
+
+	for _, record := range records {
+		if PayloadMatches(record.Payload(), fields) {
+			matching = append(matching, record)
+		}
+	}
+
+	return matching, nil
+}
+
+// CountByPayload returns how many records of the query have all the fields,
+// with exactly the values
+func CountByPayload(customStore customstore.StoreInterface, query customstore.RecordQueryInterface, fields map[string]string) (int64, error) {
+	records, err := FindByPayload(customStore, query, fields)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(records)), nil
+}
+
+// searchKey returns the key of the field with the longest value, the first
+// by name on a tie
+func searchKey(fields map[string]string) (string, bool) {
+	keys := slices.Sorted(maps.Keys(fields))
+
+	if len(keys) == 0 {
+		return "", false
+	}
+
+	longest := keys[0]
+	for _, key := range keys[1:] {
+		if len(fields[key]) > len(fields[longest]) {
+			longest = key
+		}
+	}
+
+	return longest, true
+}
@@ -0,0 +1,66 @@
+package customrecords
+
+import (
+	"testing"
+
+	"project/internal/testutils"
+
+	"github.com/dracory/customstore"
+)
+
+func newTestCustomStore(t *testing.T) customstore.StoreInterface {
+	t.Helper()
+
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	return app.GetCustomStore()
+}
+
+func TestFindByPayload(t *testing.T) {
+	customStore := newTestCustomStore(t)
+
+	payloads := []string{
+		`{"code":"SAVE10","status":"active"}`,
+		`{"code":"SAVE100","status":"active"}`,
+		`{"code":"SAVE10","status":"inactive"}`,
+		`{"code":"SAVE_0","status":"active"}`,
+	}
+
+	for _, payload := range payloads {
+		if err := customStore.RecordCreate(customstore.NewRecord("test_record", customstore.WithPayload(payload))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	query := func() customstore.RecordQueryInterface {
+		return customstore.NewRecordQuery().SetType("test_record")
+	}
+
+	records, err := FindByPayload(customStore, query(), map[string]string{"code": "SAVE10", "status": "active"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 1 || records[0].Payload() != payloads[0] {
+		t.Fatalf("expected only the exact match, got %d records", len(records))
+	}
+
+	// "_" is a wildcard of the LIKE search
+	records, err = FindByPayload(customStore, query(), map[string]string{"code": "SAVE_0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 1 || records[0].Payload() != payloads[3] {
+		t.Fatalf("expected the wildcard not to match other codes, got %d records", len(records))
+	}
+
+	count, err := CountByPayload(customStore, query(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != int64(len(payloads)) {
+		t.Errorf("expected all the records without fields, got %d", count)
+	}
+}
@@ -0,0 +1,51 @@
+// Package customrecords holds the helpers shared by the stores built on top
+// of the custom store (discounts, reservations, invoices, tokens, etc.),
+// which keep their fields as JSON in the payload of the records.
+package customrecords
+
+import (
+	"encoding/json"
+)
+
+// PayloadNeedle returns the JSON fragment `"key":"value"` as it appears in a
+// payload encoded with encoding/json, to search the payloads with
+func PayloadNeedle(key string, value string) (string, error) {
+	encodedKey, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+
+	encodedValue, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(encodedKey) + ":" + string(encodedValue), nil
+}
+
+// PayloadMatches returns whether the JSON payload has all the fields, with
+// exactly the values.
+//
+// The payload search of the custom store is a LIKE, where "%" and "_" in
+// the values are wildcards, and a needle can match inside another field,
+// so the records found must be confirmed with the exact match.
+func PayloadMatches(payload string, fields map[string]string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+
+	values := map[string]any{}
+	if err := json.Unmarshal([]byte(payload), &values); err != nil {
+		return false
+	}
+
+	for key, expected := range fields {
+		value, isString := values[key].(string)
+
+		if !isString || value != expected {
+			return false
+		}
+	}
+
+	return true
+}
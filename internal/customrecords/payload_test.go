@@ -0,0 +1,47 @@
+package customrecords
+
+import (
+	"testing"
+)
+
+func TestPayloadNeedle(t *testing.T) {
+	needle, err := PayloadNeedle("code", `SAVE"10`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if needle != `"code":"SAVE\"10"` {
+		t.Errorf("PayloadNeedle() = %s", needle)
+	}
+}
+
+func TestPayloadMatches(t *testing.T) {
+	payload := `{"code":"SAVE10","user_id":"u1","limit":5,"note":"\"code\":\"SAVE1\""}`
+
+	tests := []struct {
+		name     string
+		fields   map[string]string
+		expected bool
+	}{
+		{"no fields", nil, true},
+		{"exact", map[string]string{"code": "SAVE10"}, true},
+		{"all fields", map[string]string{"code": "SAVE10", "user_id": "u1"}, true},
+		{"shorter value", map[string]string{"code": "SAVE1"}, false},
+		{"wildcard", map[string]string{"code": "SAVE%"}, false},
+		{"one field differs", map[string]string{"code": "SAVE10", "user_id": "u2"}, false},
+		{"not a string", map[string]string{"limit": "5"}, false},
+		{"missing", map[string]string{"status": ""}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := PayloadMatches(payload, test.fields); got != test.expected {
+				t.Errorf("PayloadMatches(%v) = %v, want %v", test.fields, got, test.expected)
+			}
+		})
+	}
+
+	if PayloadMatches("not json", map[string]string{"code": "SAVE10"}) {
+		t.Error("expected an invalid payload not to match")
+	}
+}
@@ -0,0 +1,63 @@
+package discount
+
+import (
+	"time"
+)
+
+// Application is a discount code successfully applied to a basket
+type Application struct {
+	Discount Discount
+	// Amount is the discount in minor units of the currency
+	Amount int64
+}
+
+// Apply looks up the code and checks it can be used by the buyer on a basket
+// with the given subtotal (in minor units), returning the discount amount.
+//
+// Everything is re-validated server side on each call: the code as sent
+// by the client is only used to find the discount, never its value.
+// The userID can be empty for guests, in which case per user limits
+// are checked again at checkout.
+//
+// The usage limits are checked against the paid orders only. They are
+// enforced when the checkout holds a use of the code (see UsageClaim),
+// so the concurrent checkouts cannot exceed them.
+func Apply(store StoreInterface, code string, userID string, subtotal int64, currency string, now time.Time) (*Application, error) {
+	if store == nil {
+		return nil, ErrNotFound
+	}
+
+	discount, err := store.DiscountFindByCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if discount == nil {
+		return nil, ErrNotFound
+	}
+
+	usageCount := int64(0)
+	if discount.UsageLimit > 0 {
+		if usageCount, err = store.RedemptionCount(discount.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	userUsageCount := int64(0)
+	if discount.UsageLimitPerUser > 0 && userID != "" {
+		if userUsageCount, err = store.RedemptionCountByUser(discount.ID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := discount.CheckEligibility(now, subtotal, currency, usageCount, userUsageCount); err != nil {
+		return nil, err
+	}
+
+	amount, err := discount.Amount(subtotal, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Application{Discount: *discount, Amount: amount}, nil
+}
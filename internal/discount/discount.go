@@ -0,0 +1,226 @@
+package discount
+
+import (
+	"errors"
+	"fmt"
+	"project/internal/payment"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Discount types
+const (
+	TYPE_PERCENT = "percent"
+	TYPE_FIXED   = "fixed"
+)
+
+// Discount statuses
+const (
+	STATUS_ACTIVE   = "active"
+	STATUS_INACTIVE = "inactive"
+)
+
+// DATETIME_FORMAT is the format of the StartsAt and EndsAt fields (UTC)
+const DATETIME_FORMAT = "2006-01-02 15:04:05"
+
+// Errors returned when a code cannot be applied. The messages are safe to
+// show to the buyer
+var (
+	ErrNotFound              = errors.New("the discount code is not valid")
+	ErrInactive              = errors.New("the discount code is not active")
+	ErrNotStarted            = errors.New("the discount code is not valid yet")
+	ErrExpired               = errors.New("the discount code has expired")
+	ErrUsageLimitReached     = errors.New("the discount code has reached its usage limit")
+	ErrUserUsageLimitReached = errors.New("you have already used this discount code")
+	ErrMinimumSubtotal       = errors.New("your basket is below the minimum value for this discount code")
+)
+
+// IsRejection returns true if the error is one of the reasons for rejecting
+// a code, as opposed to a technical failure
+func IsRejection(err error) bool {
+	for _, rejection := range []error{ErrNotFound, ErrInactive, ErrNotStarted, ErrExpired, ErrUsageLimitReached, ErrUserUsageLimitReached, ErrMinimumSubtotal} {
+		if errors.Is(err, rejection) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var codeRegex = regexp.MustCompile(`^[A-Z0-9-]{3,32}$`)
+
+// Discount is a discount code which can be applied to the shop basket
+type Discount struct {
+	ID          string `json:"-"`
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+
+	// Type is either TYPE_PERCENT or TYPE_FIXED
+	Type string `json:"type"`
+
+	// Value is a whole percentage (e.g. "10") for TYPE_PERCENT,
+	// or an amount in the shop currency (e.g. "5.00") for TYPE_FIXED
+	Value string `json:"value"`
+
+	// StartsAt and EndsAt limit when the code can be used (UTC,
+	// DATETIME_FORMAT). Empty means no limit
+	StartsAt string `json:"starts_at"`
+	EndsAt   string `json:"ends_at"`
+
+	// UsageLimit is the maximum number of paid orders using the code,
+	// UsageLimitPerUser the maximum per buyer. Zero means unlimited
+	UsageLimit        int `json:"usage_limit"`
+	UsageLimitPerUser int `json:"usage_limit_per_user"`
+
+	// MinimumSubtotal is the minimum basket value in the shop currency
+	// (e.g. "50.00"). Empty means no minimum
+	MinimumSubtotal string `json:"minimum_subtotal"`
+}
+
+// NormalizeCode trims and upper-cases a code as typed by a buyer or admin
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks the discount is well formed, before saving it
+func (d Discount) Validate(currency string) error {
+	if !codeRegex.MatchString(d.Code) {
+		return errors.New("code must be 3 to 32 characters long, and contain only letters, digits and dashes")
+	}
+
+	if d.Status != STATUS_ACTIVE && d.Status != STATUS_INACTIVE {
+		return errors.New("status must be active or inactive")
+	}
+
+	switch d.Type {
+	case TYPE_PERCENT:
+		percent, err := strconv.Atoi(d.Value)
+		if err != nil || percent < 1 || percent > 100 {
+			return errors.New("percentage must be a whole number between 1 and 100")
+		}
+	case TYPE_FIXED:
+		amount, err := payment.ToMinorUnits(d.Value, currency)
+		if err != nil || amount <= 0 {
+			return errors.New("amount must be a positive value")
+		}
+	default:
+		return errors.New("type must be percent or fixed")
+	}
+
+	startsAt, err := parseDatetime(d.StartsAt)
+	if err != nil {
+		return fmt.Errorf("starts at must be in the format %s", DATETIME_FORMAT)
+	}
+
+	endsAt, err := parseDatetime(d.EndsAt)
+	if err != nil {
+		return fmt.Errorf("ends at must be in the format %s", DATETIME_FORMAT)
+	}
+
+	if !startsAt.IsZero() && !endsAt.IsZero() && !endsAt.After(startsAt) {
+		return errors.New("ends at must be after starts at")
+	}
+
+	if d.UsageLimit < 0 || d.UsageLimitPerUser < 0 {
+		return errors.New("usage limits cannot be negative")
+	}
+
+	if d.MinimumSubtotal != "" {
+		if minimum, err := payment.ToMinorUnits(d.MinimumSubtotal, currency); err != nil || minimum < 0 {
+			return errors.New("minimum basket value must be a valid amount")
+		}
+	}
+
+	return nil
+}
+
+// CheckEligibility checks the code can be applied at the given time, to a
+// basket with the given subtotal (in minor units), given how many times it
+// has already been used overall and by the buyer
+func (d Discount) CheckEligibility(now time.Time, subtotal int64, currency string, usageCount int64, userUsageCount int64) error {
+	if d.Status != STATUS_ACTIVE {
+		return ErrInactive
+	}
+
+	startsAt, err := parseDatetime(d.StartsAt)
+	if err != nil {
+		return ErrInactive
+	}
+
+	if !startsAt.IsZero() && now.Before(startsAt) {
+		return ErrNotStarted
+	}
+
+	endsAt, err := parseDatetime(d.EndsAt)
+	if err != nil {
+		return ErrInactive
+	}
+
+	if !endsAt.IsZero() && !now.Before(endsAt) {
+		return ErrExpired
+	}
+
+	if d.UsageLimit > 0 && usageCount >= int64(d.UsageLimit) {
+		return ErrUsageLimitReached
+	}
+
+	if d.UsageLimitPerUser > 0 && userUsageCount >= int64(d.UsageLimitPerUser) {
+		return ErrUserUsageLimitReached
+	}
+
+	if d.MinimumSubtotal != "" {
+		minimum, err := payment.ToMinorUnits(d.MinimumSubtotal, currency)
+		if err != nil {
+			return ErrInactive
+		}
+
+		if subtotal < minimum {
+			return ErrMinimumSubtotal
+		}
+	}
+
+	return nil
+}
+
+// Amount returns the discount for a basket with the given subtotal, in minor
+// units. The discount never exceeds the subtotal
+func (d Discount) Amount(subtotal int64, currency string) (int64, error) {
+	if subtotal <= 0 {
+		return 0, nil
+	}
+
+	var amount int64
+
+	switch d.Type {
+	case TYPE_PERCENT:
+		percent, err := strconv.Atoi(d.Value)
+		if err != nil || percent < 0 || percent > 100 {
+			return 0, fmt.Errorf("invalid percentage %q", d.Value)
+		}
+		// Round half up
+		amount = (subtotal*int64(percent) + 50) / 100
+	case TYPE_FIXED:
+		fixed, err := payment.ToMinorUnits(d.Value, currency)
+		if err != nil {
+			return 0, err
+		}
+		amount = fixed
+	default:
+		return 0, fmt.Errorf("invalid discount type %q", d.Type)
+	}
+
+	return max(0, min(amount, subtotal)), nil
+}
+
+// parseDatetime parses a DATETIME_FORMAT value in UTC. Empty values
+// return the zero time
+func parseDatetime(value string) (time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return time.Time{}, nil
+	}
+
+	return time.ParseInLocation(DATETIME_FORMAT, strings.TrimSpace(value), time.UTC)
+}
@@ -0,0 +1,147 @@
+package discount
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func validDiscount() Discount {
+	return Discount{
+		Code:   "SAVE10",
+		Status: STATUS_ACTIVE,
+		Type:   TYPE_PERCENT,
+		Value:  "10",
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	if got := NormalizeCode("  save10 "); got != "SAVE10" {
+		t.Errorf("NormalizeCode() = %q, want %q", got, "SAVE10")
+	}
+}
+
+func TestDiscount_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(d *Discount)
+		wantErr bool
+	}{
+		{"valid percent", func(d *Discount) {}, false},
+		{"valid fixed", func(d *Discount) { d.Type = TYPE_FIXED; d.Value = "5.00" }, false},
+		{"valid dates", func(d *Discount) { d.StartsAt = "2026-01-01 00:00:00"; d.EndsAt = "2026-02-01 00:00:00" }, false},
+		{"lowercase code", func(d *Discount) { d.Code = "save10" }, true},
+		{"short code", func(d *Discount) { d.Code = "AB" }, true},
+		{"code with spaces", func(d *Discount) { d.Code = "SAVE 10" }, true},
+		{"invalid status", func(d *Discount) { d.Status = "draft" }, true},
+		{"invalid type", func(d *Discount) { d.Type = "bogo" }, true},
+		{"percent zero", func(d *Discount) { d.Value = "0" }, true},
+		{"percent over 100", func(d *Discount) { d.Value = "101" }, true},
+		{"percent decimal", func(d *Discount) { d.Value = "12.5" }, true},
+		{"fixed negative", func(d *Discount) { d.Type = TYPE_FIXED; d.Value = "-5" }, true},
+		{"fixed not a number", func(d *Discount) { d.Type = TYPE_FIXED; d.Value = "five" }, true},
+		{"bad starts at", func(d *Discount) { d.StartsAt = "01/01/2026" }, true},
+		{"ends before starts", func(d *Discount) { d.StartsAt = "2026-02-01 00:00:00"; d.EndsAt = "2026-01-01 00:00:00" }, true},
+		{"negative usage limit", func(d *Discount) { d.UsageLimit = -1 }, true},
+		{"negative minimum", func(d *Discount) { d.MinimumSubtotal = "-1" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := validDiscount()
+			tt.mutate(&d)
+
+			err := d.Validate("GBP")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiscount_CheckEligibility(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		mutate         func(d *Discount)
+		subtotal       int64
+		usageCount     int64
+		userUsageCount int64
+		want           error
+	}{
+		{"eligible", func(d *Discount) {}, 1000, 0, 0, nil},
+		{"inactive", func(d *Discount) { d.Status = STATUS_INACTIVE }, 1000, 0, 0, ErrInactive},
+		{"not started", func(d *Discount) { d.StartsAt = "2026-03-02 00:00:00" }, 1000, 0, 0, ErrNotStarted},
+		{"expired", func(d *Discount) { d.EndsAt = "2026-03-01 12:00:00" }, 1000, 0, 0, ErrExpired},
+		{"within dates", func(d *Discount) { d.StartsAt = "2026-02-01 00:00:00"; d.EndsAt = "2026-04-01 00:00:00" }, 1000, 0, 0, nil},
+		{"usage limit reached", func(d *Discount) { d.UsageLimit = 5 }, 1000, 5, 0, ErrUsageLimitReached},
+		{"usage limit not reached", func(d *Discount) { d.UsageLimit = 5 }, 1000, 4, 0, nil},
+		{"user limit reached", func(d *Discount) { d.UsageLimitPerUser = 1 }, 1000, 0, 1, ErrUserUsageLimitReached},
+		{"below minimum", func(d *Discount) { d.MinimumSubtotal = "20.00" }, 1999, 0, 0, ErrMinimumSubtotal},
+		{"at minimum", func(d *Discount) { d.MinimumSubtotal = "20.00" }, 2000, 0, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := validDiscount()
+			tt.mutate(&d)
+
+			err := d.CheckEligibility(now, tt.subtotal, "GBP", tt.usageCount, tt.userUsageCount)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("CheckEligibility() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscount_Amount(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      string
+		value    string
+		currency string
+		subtotal int64
+		want     int64
+	}{
+		{"percent", TYPE_PERCENT, "10", "GBP", 2500, 250},
+		{"percent rounds half up", TYPE_PERCENT, "15", "GBP", 1010, 152},
+		{"percent 100", TYPE_PERCENT, "100", "GBP", 2500, 2500},
+		{"fixed", TYPE_FIXED, "5.00", "GBP", 2500, 500},
+		{"fixed capped at subtotal", TYPE_FIXED, "50.00", "GBP", 2500, 2500},
+		{"fixed zero decimal currency", TYPE_FIXED, "500", "JPY", 2500, 500},
+		{"empty basket", TYPE_FIXED, "5.00", "GBP", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Discount{Type: tt.typ, Value: tt.value}
+
+			got, err := d.Amount(tt.subtotal, tt.currency)
+			if err != nil {
+				t.Fatalf("Amount() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Amount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscount_AmountInvalidType(t *testing.T) {
+	if _, err := (Discount{Type: "bogus", Value: "1"}).Amount(1000, "GBP"); err == nil {
+		t.Error("Amount() expected error for invalid type")
+	}
+}
+
+func TestIsRejection(t *testing.T) {
+	if !IsRejection(ErrExpired) {
+		t.Error("IsRejection(ErrExpired) = false, want true")
+	}
+	if IsRejection(errors.New("database is down")) {
+		t.Error("IsRejection(other) = true, want false")
+	}
+	if IsRejection(nil) {
+		t.Error("IsRejection(nil) = true, want false")
+	}
+}
@@ -0,0 +1,311 @@
+package discount
+
+import (
+	"encoding/json"
+	"errors"
+	"project/internal/customrecords"
+	"time"
+
+	"github.com/dracory/customstore"
+)
+
+// Record types used in the custom store
+const (
+	RECORD_TYPE_DISCOUNT   = "shop_discount"
+	RECORD_TYPE_REDEMPTION = "shop_discount_redemption"
+)
+
+// StoreInterface persists discounts, the redemptions of the paid orders,
+// and the uses held by the orders to enforce their usage limits
+type StoreInterface interface {
+	DiscountCreate(discount *Discount) error
+	DiscountDelete(discountID string) error
+	DiscountFindByCode(code string) (*Discount, error)
+	DiscountFindByID(discountID string) (*Discount, error)
+	DiscountList() ([]Discount, error)
+	DiscountUpdate(discount *Discount) error
+
+	RedemptionCount(discountID string) (int64, error)
+	RedemptionCountByUser(discountID string, userID string) (int64, error)
+	RedemptionCreate(discountID string, userID string, orderID string) error
+	RedemptionExistsForOrder(orderID string) (bool, error)
+
+	UsageClaim(discountID string, userID string, orderID string, expiresAt time.Time) error
+	UsageRelease(orderID string) error
+	UsageReleaseExpired(now time.Time) (int, error)
+}
+
+// redemption records one paid order which used a discount
+type redemption struct {
+	DiscountID string `json:"discount_id"`
+	UserID     string `json:"user_id"`
+	OrderID    string `json:"order_id"`
+
+	// DiscountUser combines the discount and user IDs, so that the per user
+	// usage can be counted with a single payload search
+	DiscountUser string `json:"discount_user"`
+}
+
+type store struct {
+	customStore customstore.StoreInterface
+}
+
+var _ StoreInterface = (*store)(nil)
+
+// NewStore creates a discount store on top of the custom store
+func NewStore(customStore customstore.StoreInterface) (StoreInterface, error) {
+	if customStore == nil {
+		return nil, errors.New("custom store is required")
+	}
+
+	return &store{customStore: customStore}, nil
+}
+
+// DiscountCreate creates the discount, and sets its ID.
+// The code must not be used by another discount
+func (s *store) DiscountCreate(discount *Discount) error {
+	if discount == nil {
+		return errors.New("discount is nil")
+	}
+
+	if err := s.codeMustBeUnique(discount.Code, ""); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(discount)
+	if err != nil {
+		return err
+	}
+
+	record := customstore.NewRecord(RECORD_TYPE_DISCOUNT, customstore.WithPayload(string(payload)))
+
+	if err := s.customStore.RecordCreate(record); err != nil {
+		return err
+	}
+
+	discount.ID = record.ID()
+
+	return nil
+}
+
+// DiscountDelete soft deletes the discount. Its redemptions are kept
+func (s *store) DiscountDelete(discountID string) error {
+	record, err := s.discountRecordFindByID(discountID)
+	if err != nil {
+		return err
+	}
+
+	if record == nil {
+		return nil
+	}
+
+	return s.customStore.RecordSoftDelete(record)
+}
+
+// DiscountFindByCode finds the discount with the given code.
+// Returns nil when there is none
+func (s *store) DiscountFindByCode(code string) (*Discount, error) {
+	code = NormalizeCode(code)
+	if code == "" {
+		return nil, nil
+	}
+
+	records, err := customrecords.FindByPayload(s.customStore, customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_DISCOUNT), map[string]string{"code": code})
+
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	return discountFromRecord(records[0])
+}
+
+// DiscountFindByID finds the discount with the given ID.
+// Returns nil when there is none
+func (s *store) DiscountFindByID(discountID string) (*Discount, error) {
+	record, err := s.discountRecordFindByID(discountID)
+	if err != nil || record == nil {
+		return nil, err
+	}
+
+	return discountFromRecord(record)
+}
+
+// DiscountList returns all the discounts, newest first
+func (s *store) DiscountList() ([]Discount, error) {
+	records, err := s.customStore.RecordList(customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_DISCOUNT).
+		SetOrderBy(customstore.COLUMN_CREATED_AT))
+
+	if err != nil {
+		return nil, err
+	}
+
+	discounts := []Discount{}
+
+	for _, record := range records {
+		discount, err := discountFromRecord(record)
+		if err != nil {
+			return nil, err
+		}
+
+		discounts = append(discounts, *discount)
+	}
+
+	return discounts, nil
+}
+
+// DiscountUpdate saves the changes to an existing discount
+func (s *store) DiscountUpdate(discount *Discount) error {
+	if discount == nil {
+		return errors.New("discount is nil")
+	}
+
+	record, err := s.discountRecordFindByID(discount.ID)
+	if err != nil {
+		return err
+	}
+
+	if record == nil {
+		return errors.New("discount not found")
+	}
+
+	if err := s.codeMustBeUnique(discount.Code, discount.ID); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(discount)
+	if err != nil {
+		return err
+	}
+
+	record.SetPayload(string(payload))
+
+	return s.customStore.RecordUpdate(record)
+}
+
+// RedemptionCount returns how many paid orders used the discount
+func (s *store) RedemptionCount(discountID string) (int64, error) {
+	return s.redemptionCount("discount_id", discountID)
+}
+
+// RedemptionCountByUser returns how many paid orders of the user used the discount
+func (s *store) RedemptionCountByUser(discountID string, userID string) (int64, error) {
+	return s.redemptionCount("discount_user", discountID+":"+userID)
+}
+
+// RedemptionCreate records that the order used the discount. The
+// redemption is keyed by the order and the discount, so recording it again
+// (e.g. from the webhook and the return URL of the same payment, at the
+// same moment) does nothing.
+//
+// The usage limits are checked again: the uses held by the order since
+// the checkout are kept (see UsageClaim), or claimed if they were released.
+// Returns ErrUsageLimitReached or ErrUserUsageLimitReached, without
+// recording the redemption, when other orders hold all the uses
+func (s *store) RedemptionCreate(discountID string, userID string, orderID string) error {
+	if discountID == "" || orderID == "" {
+		return errors.New("discount ID and order ID are required")
+	}
+
+	redemptionKey := orderID + ":" + discountID
+
+	existing, err := s.customStore.RecordFindByID(customrecords.ClaimID(RECORD_TYPE_REDEMPTION, redemptionKey))
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		return nil
+	}
+
+	discount, err := s.DiscountFindByID(discountID)
+	if err != nil {
+		return err
+	}
+
+	// A deleted discount has no limits left to enforce
+	if discount != nil {
+		uses, err := s.usageClaim(*discount, usage{
+			DiscountID: discountID,
+			OrderID:    orderID,
+			UserID:     userID,
+			Status:     USAGE_STATUS_REDEEMED,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if err := s.usageRedeem(uses); err != nil {
+			return err
+		}
+	}
+
+	payload, err := json.Marshal(redemption{
+		DiscountID:   discountID,
+		UserID:       userID,
+		OrderID:      orderID,
+		DiscountUser: discountID + ":" + userID,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = customrecords.Claim(s.customStore, RECORD_TYPE_REDEMPTION, redemptionKey, string(payload))
+
+	return err
+}
+
+// RedemptionExistsForOrder checks if a redemption was already recorded for the order
+func (s *store) RedemptionExistsForOrder(orderID string) (bool, error) {
+	count, err := s.redemptionCount("order_id", orderID)
+	return count > 0, err
+}
+
+func (s *store) codeMustBeUnique(code string, discountID string) error {
+	existing, err := s.DiscountFindByCode(code)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil && existing.ID != discountID {
+		return errors.New("a discount with this code already exists")
+	}
+
+	return nil
+}
+
+func (s *store) discountRecordFindByID(discountID string) (customstore.RecordInterface, error) {
+	if discountID == "" {
+		return nil, nil
+	}
+
+	record, err := s.customStore.RecordFindByID(discountID)
+	if err != nil || record == nil {
+		return nil, err
+	}
+
+	if record.Type() != RECORD_TYPE_DISCOUNT || record.IsSoftDeleted() {
+		return nil, nil
+	}
+
+	return record, nil
+}
+
+func (s *store) redemptionCount(key string, value string) (int64, error) {
+	return customrecords.CountByPayload(s.customStore, customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_REDEMPTION), map[string]string{key: value})
+}
+
+func discountFromRecord(record customstore.RecordInterface) (*Discount, error) {
+	discount := &Discount{}
+	if err := json.Unmarshal([]byte(record.Payload()), discount); err != nil {
+		return nil, err
+	}
+
+	discount.ID = record.ID()
+
+	return discount, nil
+}
@@ -0,0 +1,199 @@
+package discount
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"project/internal/testutils"
+)
+
+func newTestStore(t *testing.T) StoreInterface {
+	t.Helper()
+
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	store, err := NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func TestNewStore_RequiresCustomStore(t *testing.T) {
+	if _, err := NewStore(nil); err == nil {
+		t.Error("NewStore(nil) expected error")
+	}
+}
+
+func TestStore_DiscountCRUD(t *testing.T) {
+	store := newTestStore(t)
+
+	discount := validDiscount()
+	if err := store.DiscountCreate(&discount); err != nil {
+		t.Fatal(err)
+	}
+	if discount.ID == "" {
+		t.Fatal("DiscountCreate() must set the ID")
+	}
+
+	found, err := store.DiscountFindByCode(" save10 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == nil || found.ID != discount.ID || found.Value != "10" {
+		t.Fatalf("DiscountFindByCode() = %+v", found)
+	}
+
+	// Similar codes are not matched
+	other, err := store.DiscountFindByCode("SAVE1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other != nil {
+		t.Fatalf("DiscountFindByCode(SAVE1) = %+v, want nil", other)
+	}
+
+	duplicate := validDiscount()
+	if err := store.DiscountCreate(&duplicate); err == nil {
+		t.Error("DiscountCreate() expected error for a duplicate code")
+	}
+
+	found.Value = "20"
+	if err := store.DiscountUpdate(found); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := store.DiscountFindByID(discount.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated == nil || updated.Value != "20" {
+		t.Fatalf("DiscountFindByID() = %+v", updated)
+	}
+
+	list, err := store.DiscountList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("DiscountList() returned %d discounts, want 1", len(list))
+	}
+
+	if err := store.DiscountDelete(discount.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := store.DiscountFindByCode("SAVE10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != nil {
+		t.Error("deleted discount must not be found")
+	}
+}
+
+func TestStore_Redemptions(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.RedemptionCreate("discount_1", "user_1", "order_1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RedemptionCreate("discount_1", "user_2", "order_2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RedemptionCreate("discount_2", "user_1", "order_3"); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := store.RedemptionCount("discount_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("RedemptionCount() = %d, want 2", count)
+	}
+
+	userCount, err := store.RedemptionCountByUser("discount_1", "user_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userCount != 1 {
+		t.Errorf("RedemptionCountByUser() = %d, want 1", userCount)
+	}
+
+	exists, err := store.RedemptionExistsForOrder("order_2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Error("RedemptionExistsForOrder(order_2) = false, want true")
+	}
+
+	// Recording the redemption of the same order again does nothing
+	if err := store.RedemptionCreate("discount_1", "user_2", "order_2"); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err = store.RedemptionCount("discount_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("RedemptionCount() after recording again = %d, want 2", count)
+	}
+
+	exists, err = store.RedemptionExistsForOrder("order_9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Error("RedemptionExistsForOrder(order_9) = true, want false")
+	}
+}
+
+func TestApply(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Now().UTC()
+
+	discount := validDiscount()
+	discount.UsageLimit = 2
+	discount.UsageLimitPerUser = 1
+	discount.MinimumSubtotal = "10.00"
+	if err := store.DiscountCreate(&discount); err != nil {
+		t.Fatal(err)
+	}
+
+	application, err := Apply(store, "save10", "user_1", 2500, "GBP", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if application.Amount != 250 || application.Discount.ID != discount.ID {
+		t.Errorf("Apply() = %+v", application)
+	}
+
+	if _, err := Apply(store, "UNKNOWN", "user_1", 2500, "GBP", now); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Apply(UNKNOWN) error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := Apply(store, "SAVE10", "user_1", 500, "GBP", now); !errors.Is(err, ErrMinimumSubtotal) {
+		t.Errorf("Apply() below minimum error = %v, want ErrMinimumSubtotal", err)
+	}
+
+	if err := store.RedemptionCreate(discount.ID, "user_1", "order_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Apply(store, "SAVE10", "user_1", 2500, "GBP", now); !errors.Is(err, ErrUserUsageLimitReached) {
+		t.Errorf("Apply() second use error = %v, want ErrUserUsageLimitReached", err)
+	}
+
+	if err := store.RedemptionCreate(discount.ID, "user_2", "order_2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Apply(store, "SAVE10", "user_3", 2500, "GBP", now); !errors.Is(err, ErrUsageLimitReached) {
+		t.Errorf("Apply() over limit error = %v, want ErrUsageLimitReached", err)
+	}
+}
@@ -0,0 +1,277 @@
+package discount
+
+import (
+	"encoding/json"
+	"errors"
+	"project/internal/customrecords"
+	"strconv"
+	"time"
+
+	"github.com/dracory/customstore"
+)
+
+// RECORD_TYPE_USAGE is the record type of the uses of the discounts with a
+// usage limit in the custom store
+const RECORD_TYPE_USAGE = "shop_discount_usage"
+
+// Statuses of the uses
+const (
+	USAGE_STATUS_HELD     = "held"
+	USAGE_STATUS_REDEEMED = "redeemed"
+)
+
+// Kinds of the uses, counted towards the usage limit of the code, or of
+// the buyer
+const (
+	USAGE_KIND_CODE = "code"
+	USAGE_KIND_USER = "user"
+)
+
+// usage is one use of a discount with a usage limit, held by an order from
+// the checkout until it expires, and kept once the order is paid.
+//
+// The uses are numbered slots, from 0 to the limit, each claimed atomically
+// (see customrecords.Claim), so the concurrent checkouts with the same code
+// cannot hold more uses than the limit
+type usage struct {
+	ID         string    `json:"-"`
+	DiscountID string    `json:"discount_id"`
+	OrderID    string    `json:"order_id"`
+	UserID     string    `json:"user_id"`
+	Kind       string    `json:"kind"`
+	Key        string    `json:"key"`
+	Status     string    `json:"status"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// UsageClaim holds a use of the discount for the order until the given
+// expiry time, within the usage limit of the code and of the buyer.
+// Returns ErrUsageLimitReached or ErrUserUsageLimitReached when all the
+// uses are held by other orders.
+//
+// The operation is idempotent, an order already holding its uses does not
+// claim them again. The discounts without a usage limit hold no uses
+func (s *store) UsageClaim(discountID string, userID string, orderID string, expiresAt time.Time) error {
+	if discountID == "" || orderID == "" {
+		return errors.New("discount ID and order ID are required")
+	}
+
+	discount, err := s.DiscountFindByID(discountID)
+	if err != nil {
+		return err
+	}
+
+	if discount == nil {
+		return ErrNotFound
+	}
+
+	_, err = s.usageClaim(*discount, usage{
+		DiscountID: discountID,
+		OrderID:    orderID,
+		UserID:     userID,
+		Status:     USAGE_STATUS_HELD,
+		ExpiresAt:  expiresAt.UTC(),
+	})
+
+	return err
+}
+
+// UsageRelease releases the uses held by the order, e.g. when its payment
+// is cancelled. The uses of a paid order are kept
+func (s *store) UsageRelease(orderID string) error {
+	uses, err := s.usageList(map[string]string{"order_id": orderID, "status": USAGE_STATUS_HELD})
+	if err != nil {
+		return err
+	}
+
+	for _, use := range uses {
+		if err := customrecords.Unclaim(s.customStore, RECORD_TYPE_USAGE, use.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UsageReleaseExpired releases the uses held by the unpaid orders which
+// expired before the given time, and returns how many were released
+func (s *store) UsageReleaseExpired(now time.Time) (int, error) {
+	uses, err := s.usageList(map[string]string{"status": USAGE_STATUS_HELD})
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+
+	for _, use := range uses {
+		if use.ExpiresAt.After(now) {
+			continue
+		}
+
+		if err := customrecords.Unclaim(s.customStore, RECORD_TYPE_USAGE, use.Key); err != nil {
+			return released, err
+		}
+
+		released++
+	}
+
+	return released, nil
+}
+
+// usageClaim claims the uses of the code and of the buyer the order does
+// not hold yet, and returns the uses of the order. The use of the code is
+// released when the use of the buyer cannot be claimed
+func (s *store) usageClaim(discount Discount, template usage) ([]usage, error) {
+	held, err := s.usageList(map[string]string{"order_id": template.OrderID, "discount_id": discount.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	heldKinds := map[string]bool{}
+	for _, use := range held {
+		heldKinds[use.Kind] = true
+	}
+
+	claimed := []usage{}
+
+	if discount.UsageLimit > 0 && !heldKinds[USAGE_KIND_CODE] {
+		use, err := s.usageSlotClaim(template, USAGE_KIND_CODE, discount.ID+":code", discount.UsageLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		if use == nil {
+			return nil, usageLimitError(USAGE_KIND_CODE)
+		}
+
+		claimed = append(claimed, *use)
+	}
+
+	// The per user limit of the guests is checked when they log in
+	if discount.UsageLimitPerUser > 0 && template.UserID != "" && !heldKinds[USAGE_KIND_USER] {
+		use, err := s.usageSlotClaim(template, USAGE_KIND_USER, discount.ID+":user:"+template.UserID, discount.UsageLimitPerUser)
+
+		if err == nil && use == nil {
+			err = usageLimitError(USAGE_KIND_USER)
+		}
+
+		if err != nil {
+			for _, claimedUse := range claimed {
+				if unclaimErr := customrecords.Unclaim(s.customStore, RECORD_TYPE_USAGE, claimedUse.Key); unclaimErr != nil {
+					return nil, errors.Join(err, unclaimErr)
+				}
+			}
+
+			return nil, err
+		}
+
+		claimed = append(claimed, *use)
+	}
+
+	return append(held, claimed...), nil
+}
+
+// usageSlotClaim claims the first free slot of the prefix, below the
+// limit, and returns the use claimed, or nil when all the slots are held
+func (s *store) usageSlotClaim(template usage, kind string, prefix string, limit int) (*usage, error) {
+	use := template
+	use.Kind = kind
+
+	for slot := 0; slot < limit; slot++ {
+		use.Key = prefix + ":" + strconv.Itoa(slot)
+
+		payload, err := json.Marshal(use)
+		if err != nil {
+			return nil, err
+		}
+
+		claimed, err := customrecords.Claim(s.customStore, RECORD_TYPE_USAGE, use.Key, string(payload))
+		if err != nil {
+			return nil, err
+		}
+
+		if claimed {
+			use.ID = customrecords.ClaimID(RECORD_TYPE_USAGE, use.Key)
+			return &use, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// usageRedeem keeps the uses of the paid order, so they are not released.
+// A use released meanwhile is claimed again, if its slot is still free
+func (s *store) usageRedeem(uses []usage) error {
+	for _, use := range uses {
+		if use.Status == USAGE_STATUS_REDEEMED {
+			continue
+		}
+
+		use.Status = USAGE_STATUS_REDEEMED
+		use.ExpiresAt = time.Time{}
+
+		payload, err := json.Marshal(use)
+		if err != nil {
+			return err
+		}
+
+		record, err := s.customStore.RecordFindByID(use.ID)
+		if err != nil {
+			return err
+		}
+
+		if record == nil {
+			claimed, err := customrecords.Claim(s.customStore, RECORD_TYPE_USAGE, use.Key, string(payload))
+			if err != nil {
+				return err
+			}
+
+			if !claimed {
+				return usageLimitError(use.Kind)
+			}
+
+			continue
+		}
+
+		record.SetPayload(string(payload))
+
+		if err := s.customStore.RecordUpdate(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// usageLimitError returns the error of the usage limit of the kind
+func usageLimitError(kind string) error {
+	if kind == USAGE_KIND_USER {
+		return ErrUserUsageLimitReached
+	}
+
+	return ErrUsageLimitReached
+}
+
+// usageList returns the uses matching all the payload fields
+func (s *store) usageList(fields map[string]string) ([]usage, error) {
+	records, err := customrecords.FindByPayload(s.customStore, customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_USAGE), fields)
+
+	if err != nil {
+		return nil, err
+	}
+
+	uses := []usage{}
+
+	for _, record := range records {
+		use := usage{}
+		if err := json.Unmarshal([]byte(record.Payload()), &use); err != nil {
+			return nil, err
+		}
+
+		use.ID = record.ID()
+		uses = append(uses, use)
+	}
+
+	return uses, nil
+}
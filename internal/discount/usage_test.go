@@ -0,0 +1,145 @@
+package discount
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestLimitedDiscount(t *testing.T, store StoreInterface, usageLimit int, usageLimitPerUser int) Discount {
+	t.Helper()
+
+	discount := validDiscount()
+	discount.UsageLimit = usageLimit
+	discount.UsageLimitPerUser = usageLimitPerUser
+
+	if err := store.DiscountCreate(&discount); err != nil {
+		t.Fatal(err)
+	}
+
+	return discount
+}
+
+func TestStore_UsageClaimTwoPendingOrders(t *testing.T) {
+	store := newTestStore(t)
+	discount := newTestLimitedDiscount(t, store, 1, 0)
+	expiresAt := time.Now().UTC().Add(time.Hour)
+
+	if err := store.UsageClaim(discount.ID, "user_1", "order_1", expiresAt); err != nil {
+		t.Fatal(err)
+	}
+
+	// The same order claims again, e.g. when the checkout is retried
+	if err := store.UsageClaim(discount.ID, "user_1", "order_1", expiresAt); err != nil {
+		t.Fatalf("UsageClaim() again for the same order = %v, want nil", err)
+	}
+
+	if err := store.UsageClaim(discount.ID, "user_2", "order_2", expiresAt); !errors.Is(err, ErrUsageLimitReached) {
+		t.Fatalf("UsageClaim() for a second pending order = %v, want ErrUsageLimitReached", err)
+	}
+
+	// The second order cannot be redeemed either, if it was paid anyway
+	if err := store.RedemptionCreate(discount.ID, "user_2", "order_2"); !errors.Is(err, ErrUsageLimitReached) {
+		t.Fatalf("RedemptionCreate() for the second order = %v, want ErrUsageLimitReached", err)
+	}
+
+	if err := store.RedemptionCreate(discount.ID, "user_1", "order_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := store.RedemptionCount(discount.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("RedemptionCount() = %d, want 1", count)
+	}
+
+	// The use of the paid order is kept
+	if err := store.UsageRelease("order_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.UsageClaim(discount.ID, "user_2", "order_2", expiresAt); !errors.Is(err, ErrUsageLimitReached) {
+		t.Fatalf("UsageClaim() after the paid order was released = %v, want ErrUsageLimitReached", err)
+	}
+}
+
+func TestStore_UsageRelease(t *testing.T) {
+	store := newTestStore(t)
+	discount := newTestLimitedDiscount(t, store, 1, 0)
+	now := time.Now().UTC()
+
+	if err := store.UsageClaim(discount.ID, "user_1", "order_1", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.UsageRelease("order_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.UsageClaim(discount.ID, "user_2", "order_2", now.Add(-time.Minute)); err != nil {
+		t.Fatalf("UsageClaim() after the release = %v, want nil", err)
+	}
+
+	released, err := store.UsageReleaseExpired(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released != 1 {
+		t.Errorf("UsageReleaseExpired() = %d, want 1", released)
+	}
+
+	if err := store.UsageClaim(discount.ID, "user_3", "order_3", now.Add(time.Hour)); err != nil {
+		t.Fatalf("UsageClaim() after the expiry = %v, want nil", err)
+	}
+}
+
+func TestStore_UsageClaimPerUser(t *testing.T) {
+	store := newTestStore(t)
+	discount := newTestLimitedDiscount(t, store, 2, 1)
+	expiresAt := time.Now().UTC().Add(time.Hour)
+
+	if err := store.UsageClaim(discount.ID, "user_1", "order_1", expiresAt); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.UsageClaim(discount.ID, "user_1", "order_2", expiresAt); !errors.Is(err, ErrUserUsageLimitReached) {
+		t.Fatalf("UsageClaim() for a second order of the user = %v, want ErrUserUsageLimitReached", err)
+	}
+
+	// The use of the code claimed by the refused order was released
+	if err := store.UsageClaim(discount.ID, "user_2", "order_3", expiresAt); err != nil {
+		t.Fatalf("UsageClaim() for another user = %v, want nil", err)
+	}
+}
+
+func TestStore_UsageClaimConcurrent(t *testing.T) {
+	store := newTestStore(t)
+	discount := newTestLimitedDiscount(t, store, 1, 0)
+	expiresAt := time.Now().UTC().Add(time.Hour)
+
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+
+	for i := range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := store.UsageClaim(discount.ID, "user_"+strconv.Itoa(i), "order_"+strconv.Itoa(i), expiresAt); err == nil {
+				wins.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if wins.Load() != 1 {
+		t.Errorf("expected exactly one order to hold the use, got %d", wins.Load())
+	}
+}
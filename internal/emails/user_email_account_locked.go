@@ -0,0 +1,83 @@
+package emails
+
+import (
+	"project/internal/app"
+
+	"github.com/dracory/email"
+	"github.com/dracory/hb"
+	"github.com/samber/lo"
+)
+
+func NewAccountLockedEmail(app app.AppInterface) *accountLockedEmail {
+	return &accountLockedEmail{app: app}
+}
+
+type accountLockedEmail struct{ app app.AppInterface }
+
+// Send tells the user their account was locked after too many failed
+// login attempts, until the time
+func (e *accountLockedEmail) Send(recipientEmail string, lockedUntil string, passwordForgotURL string) error {
+	appName := lo.IfF(e.app != nil, func() string {
+		if e.app.GetConfig() == nil {
+			return ""
+		}
+		return e.app.GetConfig().GetAppName()
+	}).Else("")
+
+	fromEmail := lo.IfF(e.app != nil, func() string {
+		if e.app.GetConfig() == nil {
+			return ""
+		}
+		return e.app.GetConfig().GetMailFromAddress()
+	}).Else("")
+
+	fromName := lo.IfF(e.app != nil, func() string {
+		if e.app.GetConfig() == nil {
+			return ""
+		}
+		return e.app.GetConfig().GetMailFromName()
+	}).Else("")
+
+	emailSubject := appName + ". Your Account Was Locked"
+	emailContent := e.template(appName, lockedUntil, passwordForgotURL)
+
+	finalHtml := CreateEmailTemplate(e.app, emailSubject, emailContent)
+
+	errSend := SendEmail(SendOptions{
+		From:     fromEmail,
+		FromName: fromName,
+		To:       []string{recipientEmail},
+		Subject:  emailSubject,
+		HtmlBody: finalHtml,
+	})
+	return errSend
+}
+
+func (e *accountLockedEmail) template(appName string, lockedUntil string, passwordForgotURL string) string {
+	h1 := hb.Heading1().
+		HTML(`Your Account Was Locked`).
+		Style(email.StyleHeading1)
+
+	p1 := hb.Paragraph().
+		Text(`There were too many failed attempts to log in to your ` + appName + ` account, so we locked it until ` + lockedUntil + ` (UTC).`).
+		Style(email.StyleParagraph)
+
+	p2 := hb.Paragraph().
+		Text(`If it was you, you can log in again once the lock expires.`).
+		Style(email.StyleParagraph)
+
+	p3 := hb.Paragraph().
+		Text(`If it was not you, someone may be trying to guess your password. We recommend changing it, and turning on two-factor authentication.`).
+		Style(email.StyleParagraph)
+
+	p4 := hb.Paragraph().
+		Child(hb.Hyperlink().Text("Change Your Password").Href(passwordForgotURL)).
+		Style(email.StyleParagraph)
+
+	return hb.Div().Children([]hb.TagInterface{
+		h1,
+		p1,
+		p2,
+		p3,
+	}).ChildIf(passwordForgotURL != "", p4).ToHTML()
+}
@@ -0,0 +1,84 @@
+package emails
+
+import (
+	"project/internal/app"
+
+	"github.com/dracory/email"
+	"github.com/dracory/hb"
+	"github.com/samber/lo"
+	"github.com/spf13/cast"
+)
+
+func NewMagicLinkEmail(app app.AppInterface) *magicLinkEmail {
+	return &magicLinkEmail{app: app}
+}
+
+type magicLinkEmail struct{ app app.AppInterface }
+
+// Send sends the single use link to log in without a password
+func (e *magicLinkEmail) Send(recipientEmail string, loginURL string, expiresMinutes int) error {
+	appName := lo.IfF(e.app != nil, func() string {
+		if e.app.GetConfig() == nil {
+			return ""
+		}
+		return e.app.GetConfig().GetAppName()
+	}).Else("")
+
+	fromEmail := lo.IfF(e.app != nil, func() string {
+		if e.app.GetConfig() == nil {
+			return ""
+		}
+		return e.app.GetConfig().GetMailFromAddress()
+	}).Else("")
+
+	fromName := lo.IfF(e.app != nil, func() string {
+		if e.app.GetConfig() == nil {
+			return ""
+		}
+		return e.app.GetConfig().GetMailFromName()
+	}).Else("")
+
+	emailSubject := appName + ". Your Login Link"
+	emailContent := e.template(appName, loginURL, expiresMinutes)
+
+	finalHtml := CreateEmailTemplate(e.app, emailSubject, emailContent)
+
+	errSend := SendEmail(SendOptions{
+		From:     fromEmail,
+		FromName: fromName,
+		To:       []string{recipientEmail},
+		Subject:  emailSubject,
+		HtmlBody: finalHtml,
+	})
+	return errSend
+}
+
+func (e *magicLinkEmail) template(appName string, loginURL string, expiresMinutes int) string {
+	h1 := hb.Heading1().
+		HTML(`Your Login Link`).
+		Style(email.StyleHeading1)
+
+	p1 := hb.Paragraph().
+		Text(`Use the link below to log in to ` + appName + `.`).
+		Style(email.StyleParagraph)
+
+	p2 := hb.Paragraph().
+		Child(hb.Hyperlink().Text("Click to Log In").Href(loginURL)).
+		Style(email.StyleParagraph)
+
+	p3 := hb.Paragraph().
+		Text(`The link is valid for ` + cast.ToString(expiresMinutes) + ` minutes, and can be used once.`).
+		Style(email.StyleParagraph)
+
+	p4 := hb.Paragraph().
+		Text(`If you did not ask to log in, you can ignore this email.`).
+		Style(email.StyleParagraph)
+
+	return hb.Div().Children([]hb.TagInterface{
+		h1,
+		p1,
+		p2,
+		p3,
+		p4,
+	}).ToHTML()
+}
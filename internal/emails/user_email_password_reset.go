@@ -0,0 +1,83 @@
+package emails
+
+import (
+	"project/internal/app"
+
+	"github.com/dracory/email"
+	"github.com/dracory/hb"
+	"github.com/samber/lo"
+)
+
+func NewPasswordResetEmail(app app.AppInterface) *passwordResetEmail {
+	return &passwordResetEmail{app: app}
+}
+
+type passwordResetEmail struct{ app app.AppInterface }
+
+// Send sends the link to reset the password of the user
+func (e *passwordResetEmail) Send(recipientEmail string, resetURL string) error {
+	appName := lo.IfF(e.app != nil, func() string {
+		if e.app.GetConfig() == nil {
+			return ""
+		}
+		return e.app.GetConfig().GetAppName()
+	}).Else("")
+
+	fromEmail := lo.IfF(e.app != nil, func() string {
+		if e.app.GetConfig() == nil {
+			return ""
+		}
+		return e.app.GetConfig().GetMailFromAddress()
+	}).Else("")
+
+	fromName := lo.IfF(e.app != nil, func() string {
+		if e.app.GetConfig() == nil {
+			return ""
+		}
+		return e.app.GetConfig().GetMailFromName()
+	}).Else("")
+
+	emailSubject := appName + ". Reset Your Password"
+	emailContent := e.template(appName, resetURL)
+
+	finalHtml := CreateEmailTemplate(e.app, emailSubject, emailContent)
+
+	errSend := SendEmail(SendOptions{
+		From:     fromEmail,
+		FromName: fromName,
+		To:       []string{recipientEmail},
+		Subject:  emailSubject,
+		HtmlBody: finalHtml,
+	})
+	return errSend
+}
+
+func (e *passwordResetEmail) template(appName string, resetURL string) string {
+	h1 := hb.Heading1().
+		HTML(`Reset Your Password`).
+		Style(email.StyleHeading1)
+
+	p1 := hb.Paragraph().
+		Text(`We received a request to reset the password of your ` + appName + ` account.`).
+		Style(email.StyleParagraph)
+
+	p2 := hb.Paragraph().
+		Child(hb.Hyperlink().Text("Click to Reset Your Password").Href(resetURL)).
+		Style(email.StyleParagraph)
+
+	p3 := hb.Paragraph().
+		Text(`The link is valid for one hour, and can be used once.`).
+		Style(email.StyleParagraph)
+
+	p4 := hb.Paragraph().
+		Text(`If you did not request a password reset, you can ignore this email. Your password will not change.`).
+		Style(email.StyleParagraph)
+
+	return hb.Div().Children([]hb.TagInterface{
+		h1,
+		p1,
+		p2,
+		p3,
+		p4,
+	}).ToHTML()
+}
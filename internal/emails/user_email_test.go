@@ -0,0 +1,148 @@
+package emails
+
+import (
+	"strings"
+	"testing"
+
+	"project/internal/testutils"
+)
+
+func TestPasswordResetEmail_Template(t *testing.T) {
+	app := testutils.Setup()
+	email := NewPasswordResetEmail(app)
+
+	html := email.template("TestApp", "https://example.com/auth/password-reset?token=abc123")
+
+	if !strings.Contains(html, "Reset Your Password") {
+		t.Error("template() should contain the heading")
+	}
+	if !strings.Contains(html, "TestApp") {
+		t.Error("template() should contain the app name")
+	}
+	if !strings.Contains(html, "token=abc123") {
+		t.Error("template() should contain the reset link")
+	}
+}
+
+func TestPasswordResetEmail_Send(t *testing.T) {
+	senderMu.Lock()
+	originalSender := emailSender
+	emailSender = nil
+	senderMu.Unlock()
+	defer func() {
+		senderMu.Lock()
+		emailSender = originalSender
+		senderMu.Unlock()
+	}()
+
+	err := NewPasswordResetEmail(testutils.Setup()).Send("user@example.com", "https://example.com")
+	if err == nil {
+		t.Error("Send() with uninitialized sender should return error")
+	}
+}
+
+func TestEmailVerifyEmail_Template(t *testing.T) {
+	app := testutils.Setup()
+	email := NewEmailVerifyEmail(app)
+
+	html := email.template("TestApp", "https://example.com/auth/email-verify?token=abc123")
+
+	if !strings.Contains(html, "Verify Your Email") {
+		t.Error("template() should contain the heading")
+	}
+	if !strings.Contains(html, "TestApp") {
+		t.Error("template() should contain the app name")
+	}
+	if !strings.Contains(html, "token=abc123") {
+		t.Error("template() should contain the verification link")
+	}
+}
+
+func TestEmailVerifyEmail_Send(t *testing.T) {
+	senderMu.Lock()
+	originalSender := emailSender
+	emailSender = nil
+	senderMu.Unlock()
+	defer func() {
+		senderMu.Lock()
+		emailSender = originalSender
+		senderMu.Unlock()
+	}()
+
+	err := NewEmailVerifyEmail(testutils.Setup()).Send("user@example.com", "https://example.com")
+	if err == nil {
+		t.Error("Send() with uninitialized sender should return error")
+	}
+}
+
+func TestMagicLinkEmail_Template(t *testing.T) {
+	app := testutils.Setup()
+	email := NewMagicLinkEmail(app)
+
+	html := email.template("TestApp", "https://example.com/auth/magic-link/login?token=abc123", 15)
+
+	if !strings.Contains(html, "Your Login Link") {
+		t.Error("template() should contain the heading")
+	}
+	if !strings.Contains(html, "token=abc123") {
+		t.Error("template() should contain the login link")
+	}
+	if !strings.Contains(html, "15 minutes") {
+		t.Error("template() should contain the expiry")
+	}
+}
+
+func TestMagicLinkEmail_Send(t *testing.T) {
+	senderMu.Lock()
+	originalSender := emailSender
+	emailSender = nil
+	senderMu.Unlock()
+	defer func() {
+		senderMu.Lock()
+		emailSender = originalSender
+		senderMu.Unlock()
+	}()
+
+	err := NewMagicLinkEmail(testutils.Setup()).Send("user@example.com", "https://example.com", 15)
+	if err == nil {
+		t.Error("Send() with uninitialized sender should return error")
+	}
+}
+
+func TestAccountLockedEmail_Template(t *testing.T) {
+	app := testutils.Setup()
+	email := NewAccountLockedEmail(app)
+
+	html := email.template("TestApp", "2026-01-01 12:15:00", "https://example.com/auth/password-forgot")
+
+	if !strings.Contains(html, "Your Account Was Locked") {
+		t.Error("template() should contain the heading")
+	}
+	if !strings.Contains(html, "TestApp") || !strings.Contains(html, "2026-01-01 12:15:00") {
+		t.Error("template() should contain the app name and the lock expiry")
+	}
+	if !strings.Contains(html, "password-forgot") {
+		t.Error("template() should contain the password change link")
+	}
+
+	if html := email.template("TestApp", "2026-01-01 12:15:00", ""); strings.Contains(html, "Change Your Password") {
+		t.Error("template() should not contain the password change link without a URL")
+	}
+}
+
+func TestAccountLockedEmail_Send(t *testing.T) {
+	senderMu.Lock()
+	originalSender := emailSender
+	emailSender = nil
+	senderMu.Unlock()
+	defer func() {
+		senderMu.Lock()
+		emailSender = originalSender
+		senderMu.Unlock()
+	}()
+
+	err := NewAccountLockedEmail(testutils.Setup()).Send("user@example.com", "2026-01-01 12:15:00", "")
+	if err == nil {
+		t.Error("Send() with uninitialized sender should return error")
+	}
+}
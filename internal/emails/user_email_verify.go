@@ -0,0 +1,78 @@
+package emails
+
+import (
+	"project/internal/app"
+
+	"github.com/dracory/email"
+	"github.com/dracory/hb"
+	"github.com/samber/lo"
+)
+
+func NewEmailVerifyEmail(app app.AppInterface) *emailVerifyEmail {
+	return &emailVerifyEmail{app: app}
+}
+
+type emailVerifyEmail struct{ app app.AppInterface }
+
+// Send sends the link to verify the email address of a new user
+func (e *emailVerifyEmail) Send(recipientEmail string, verifyURL string) error {
+	appName := lo.IfF(e.app != nil, func() string {
+		if e.app.GetConfig() == nil {
+			return ""
+		}
+		return e.app.GetConfig().GetAppName()
+	}).Else("")
+
+	fromEmail := lo.IfF(e.app != nil, func() string {
+		if e.app.GetConfig() == nil {
+			return ""
+		}
+		return e.app.GetConfig().GetMailFromAddress()
+	}).Else("")
+
+	fromName := lo.IfF(e.app != nil, func() string {
+		if e.app.GetConfig() == nil {
+			return ""
+		}
+		return e.app.GetConfig().GetMailFromName()
+	}).Else("")
+
+	emailSubject := appName + ". Verify Your Email"
+	emailContent := e.template(appName, verifyURL)
+
+	finalHtml := CreateEmailTemplate(e.app, emailSubject, emailContent)
+
+	errSend := SendEmail(SendOptions{
+		From:     fromEmail,
+		FromName: fromName,
+		To:       []string{recipientEmail},
+		Subject:  emailSubject,
+		HtmlBody: finalHtml,
+	})
+	return errSend
+}
+
+func (e *emailVerifyEmail) template(appName string, verifyURL string) string {
+	h1 := hb.Heading1().
+		HTML(`Verify Your Email`).
+		Style(email.StyleHeading1)
+
+	p1 := hb.Paragraph().
+		Text(`Thank you for signing up to ` + appName + `. Please confirm this is your email address.`).
+		Style(email.StyleParagraph)
+
+	p2 := hb.Paragraph().
+		Child(hb.Hyperlink().Text("Click to Verify Your Email").Href(verifyURL)).
+		Style(email.StyleParagraph)
+
+	p3 := hb.Paragraph().
+		Text(`If you did not sign up, you can ignore this email.`).
+		Style(email.StyleParagraph)
+
+	return hb.Div().Children([]hb.TagInterface{
+		h1,
+		p1,
+		p2,
+		p3,
+	}).ToHTML()
+}
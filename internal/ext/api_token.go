@@ -0,0 +1,197 @@
+package ext
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"project/internal/apitoken"
+	"project/internal/app"
+	"strings"
+	"time"
+
+	"github.com/dracory/userstore"
+)
+
+// ErrAPITokenInvalid is returned for the tokens which do not exist, or are
+// expired or revoked
+var ErrAPITokenInvalid = errors.New("invalid or expired token")
+
+// apiTokenCacheKeyPrefix prefixes the memory cache keys of the validated
+// tokens, by token hash
+const apiTokenCacheKeyPrefix = "api-token:"
+
+// apiTokenCacheDuration is the time a validated token is kept in the
+// memory cache, to not look it up on every request
+const apiTokenCacheDuration = 1 * time.Minute
+
+// apiTokenNameMaxLength is the maximum length of the name of a token
+const apiTokenNameMaxLength = 100
+
+type apiTokenCacheItem struct {
+	token apitoken.Token
+	user  userstore.UserInterface
+}
+
+// APITokenCreate creates a personal API token for the user, with the
+// scopes, expiring at the time (zero for never).
+//
+// Returns the token, which is shown to the user once, as only its hash
+// is stored. The error is meant to be shown to the user.
+func APITokenCreate(app app.AppInterface, user userstore.UserInterface, name string, scopes []string, expiresAt time.Time) (string, *apitoken.Token, error) {
+	tokenStore, err := apitoken.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if user == nil {
+		return "", nil, errors.New("user is nil")
+	}
+
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return "", nil, errors.New("please enter a name for the token")
+	}
+
+	if len(name) > apiTokenNameMaxLength {
+		return "", nil, errors.New("the name of the token is too long")
+	}
+
+	if len(scopes) == 0 {
+		return "", nil, errors.New("please select at least one scope")
+	}
+
+	for _, key := range scopes {
+		scope := apitoken.ScopeFind(key)
+
+		if scope == nil {
+			return "", nil, errors.New("unknown scope " + key)
+		}
+
+		if scope.AdminOnly && !user.IsAdministrator() && !user.IsSuperuser() {
+			return "", nil, errors.New("the scope " + key + " can only be granted by administrators")
+		}
+	}
+
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return "", nil, errors.New("the expiry date must be in the future")
+	}
+
+	plain, hash, err := apitoken.Generate()
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	token := &apitoken.Token{
+		UserID:    user.GetID(),
+		Name:      name,
+		Hash:      hash,
+		Hint:      plain[len(plain)-4:],
+		Scopes:    scopes,
+		ExpiresAt: expiresAt.UTC(),
+	}
+
+	if err := tokenStore.TokenCreate(token); err != nil {
+		return "", nil, err
+	}
+
+	return plain, token, nil
+}
+
+// APITokenAuthenticate returns the token and its user for the token sent
+// by an API client, or ErrAPITokenInvalid.
+//
+// The validated tokens are cached in memory for a minute, and the last
+// used time is saved when the token is looked up again.
+func APITokenAuthenticate(ctx context.Context, app app.AppInterface, plain string) (*apitoken.Token, userstore.UserInterface, error) {
+	if !apitoken.IsToken(plain) {
+		return nil, nil, ErrAPITokenInvalid
+	}
+
+	now := time.Now().UTC()
+	hash := apitoken.Hash(plain)
+	memoryCache := app.GetMemoryCache()
+
+	if memoryCache != nil {
+		if item := memoryCache.Get(apiTokenCacheKeyPrefix + hash); item != nil {
+			if cached, ok := item.Value().(apiTokenCacheItem); ok && cached.token.IsActive(now) {
+				token := cached.token
+				return &token, cached.user, nil
+			}
+		}
+	}
+
+	tokenStore, err := apitoken.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token, err := tokenStore.TokenFindByHash(hash)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if token == nil || !token.IsActive(now) {
+		return nil, nil, ErrAPITokenInvalid
+	}
+
+	if app.GetUserStore() == nil {
+		return nil, nil, errors.New("user store is nil")
+	}
+
+	user, err := app.GetUserStore().UserFindByID(ctx, token.UserID)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user == nil || user.GetStatus() != userstore.USER_STATUS_ACTIVE {
+		return nil, nil, ErrAPITokenInvalid
+	}
+
+	token.LastUsedAt = now
+
+	if err := tokenStore.TokenUpdate(token); err != nil {
+		app.GetLogger().Warn("At ext > APITokenAuthenticate > TokenUpdate", slog.String("error", err.Error()))
+	}
+
+	if memoryCache != nil {
+		memoryCache.Set(apiTokenCacheKeyPrefix+hash, apiTokenCacheItem{token: *token, user: user}, apiTokenCacheDuration)
+	}
+
+	return token, user, nil
+}
+
+// APITokenRevoke revokes the token, which stops working at once (within
+// a minute on the other instances of the application, see the cache)
+func APITokenRevoke(app app.AppInterface, token *apitoken.Token) error {
+	tokenStore, err := apitoken.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		return errors.New("token is nil")
+	}
+
+	if token.IsRevoked() {
+		return nil
+	}
+
+	token.RevokedAt = time.Now().UTC()
+
+	if err := tokenStore.TokenUpdate(token); err != nil {
+		return err
+	}
+
+	if app.GetMemoryCache() != nil {
+		app.GetMemoryCache().Delete(apiTokenCacheKeyPrefix + token.Hash)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package ext
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"project/internal/apitoken"
+	"project/internal/testutils"
+
+	"github.com/dracory/userstore"
+)
+
+func TestAPIToken_CreateAuthenticateRevoke(t *testing.T) {
+	app := testutils.Setup(
+		testutils.WithUserStore(true),
+		testutils.WithCustomStore(true),
+	)
+	ctx := context.Background()
+
+	user, err := UserCreateWithEmail(ctx, app, "user@example.com", userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatalf("UserCreateWithEmail failed: %v", err)
+	}
+
+	plain, token, err := APITokenCreate(app, user, "CI", []string{apitoken.SCOPE_PROFILE_READ}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("APITokenCreate failed: %v", err)
+	}
+
+	if !apitoken.IsToken(plain) || token.Hash == plain || token.Hash != apitoken.Hash(plain) {
+		t.Fatal("expected only the hash of the token to be stored")
+	}
+
+	found, foundUser, err := APITokenAuthenticate(ctx, app, plain)
+	if err != nil {
+		t.Fatalf("APITokenAuthenticate failed: %v", err)
+	}
+
+	if found.ID != token.ID || foundUser.GetID() != user.GetID() {
+		t.Fatal("expected the token and its user")
+	}
+
+	if found.LastUsedAt.IsZero() {
+		t.Fatal("expected the last used time to be set")
+	}
+
+	if err := APITokenRevoke(app, found); err != nil {
+		t.Fatalf("APITokenRevoke failed: %v", err)
+	}
+
+	// The revoked token is removed from the cache at once
+	if _, _, err := APITokenAuthenticate(ctx, app, plain); !errors.Is(err, ErrAPITokenInvalid) {
+		t.Fatalf("expected the revoked token to be invalid, got %v", err)
+	}
+}
+
+func TestAPITokenAuthenticate_Invalid(t *testing.T) {
+	app := testutils.Setup(
+		testutils.WithUserStore(true),
+		testutils.WithCustomStore(true),
+	)
+
+	for _, plain := range []string{"", "some-session-key", apitoken.TOKEN_PREFIX + "unknown"} {
+		if _, _, err := APITokenAuthenticate(context.Background(), app, plain); !errors.Is(err, ErrAPITokenInvalid) {
+			t.Errorf("%q: expected ErrAPITokenInvalid, got %v", plain, err)
+		}
+	}
+}
+
+func TestAPITokenCreate_Validation(t *testing.T) {
+	app := testutils.Setup(
+		testutils.WithUserStore(true),
+		testutils.WithCustomStore(true),
+	)
+
+	user, err := UserCreateWithEmail(context.Background(), app, "user@example.com", userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		tokenName string
+		scopes    []string
+		expiresAt time.Time
+	}{
+		{"no name", "", []string{apitoken.SCOPE_PROFILE_READ}, time.Time{}},
+		{"no scopes", "CI", nil, time.Time{}},
+		{"unknown scope", "CI", []string{"unknown:read"}, time.Time{}},
+		{"admin only scope", "CI", []string{apitoken.SCOPE_USERS_READ}, time.Time{}},
+		{"expired", "CI", []string{apitoken.SCOPE_PROFILE_READ}, time.Now().Add(-time.Hour)},
+	}
+
+	for _, c := range cases {
+		if _, _, err := APITokenCreate(app, user, c.tokenName, c.scopes, c.expiresAt); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+
+	user.SetRole(userstore.USER_ROLE_ADMINISTRATOR)
+
+	if _, _, err := APITokenCreate(app, user, "Admin", []string{apitoken.SCOPE_USERS_READ}, time.Time{}); err != nil {
+		t.Fatalf("expected administrators to grant the admin only scopes, got %v", err)
+	}
+}
+
+func TestAPITokenAuthenticate_InactiveUser(t *testing.T) {
+	app := testutils.Setup(
+		testutils.WithUserStore(true),
+		testutils.WithCustomStore(true),
+	)
+	ctx := context.Background()
+
+	user, err := UserCreateWithEmail(ctx, app, "user@example.com", userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, _, err := APITokenCreate(app, user, "CI", []string{apitoken.SCOPE_PROFILE_READ}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user.SetStatus(userstore.USER_STATUS_INACTIVE)
+	if err := app.GetUserStore().UserUpdate(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := APITokenAuthenticate(ctx, app, plain); !errors.Is(err, ErrAPITokenInvalid) {
+		t.Fatalf("expected the token of an inactive user to be invalid, got %v", err)
+	}
+}
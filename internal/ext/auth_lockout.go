@@ -0,0 +1,238 @@
+package ext
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"project/internal/app"
+	"project/internal/emails"
+	"project/internal/links"
+	"project/internal/lockout"
+	"strconv"
+	"time"
+
+	"github.com/dracory/auditstore"
+	"github.com/dracory/userstore"
+	"github.com/dromara/carbon/v2"
+)
+
+// The actions of the audit records of the lockouts
+const (
+	AUDIT_ACTION_AUTH_LOCKOUT = "auth.lockout"
+	AUDIT_ACTION_AUTH_UNLOCK  = "auth.unlock"
+)
+
+// AuthLockoutWait returns how long the user and the IP address must wait
+// before their next authentication attempt, zero if they can try now.
+//
+// The user is nil when not known (e.g. an unknown email, or a login link),
+// and the IP address empty when not known. Nothing is tracked without the
+// custom store.
+func AuthLockoutWait(app app.AppInterface, user userstore.UserInterface, ip string) time.Duration {
+	attemptStore, err := lockout.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return 0
+	}
+
+	now := time.Now().UTC()
+	wait := time.Duration(0)
+
+	for subject, key := range authLockoutSubjects(user, ip) {
+		attempt, err := attemptStore.AttemptFind(subject, key)
+
+		if err != nil {
+			app.GetLogger().Error("At ext > AuthLockoutWait > AttemptFind", slog.String("error", err.Error()))
+			continue
+		}
+
+		if attempt != nil {
+			wait = max(wait, attempt.Wait(now))
+		}
+	}
+
+	return wait
+}
+
+// AuthLockoutWaitMessage returns the message telling the user how long to
+// wait before trying again
+func AuthLockoutWaitMessage(wait time.Duration) string {
+	count, unit := int(math.Ceil(wait.Minutes())), "minute"
+
+	if wait <= time.Minute {
+		count, unit = max(int(math.Ceil(wait.Seconds())), 1), "second"
+	}
+
+	if count > 1 {
+		unit += "s"
+	}
+
+	return "Too many failed attempts. Please try again in " + strconv.Itoa(count) + " " + unit + "."
+}
+
+// AuthLockoutFail records a failed authentication attempt (e.g. a wrong
+// password) of the user and the IP address.
+//
+// Each lockout is added to the audit log, and the user is told by email
+// their account was locked. Errors are only logged, as they must not stop
+// the authentication.
+func AuthLockoutFail(ctx context.Context, app app.AppInterface, user userstore.UserInterface, ip string) {
+	attemptStore, err := lockout.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	for subject, key := range authLockoutSubjects(user, ip) {
+		attempt, err := attemptStore.AttemptFind(subject, key)
+
+		if err != nil {
+			app.GetLogger().Error("At ext > AuthLockoutFail > AttemptFind", slog.String("error", err.Error()))
+			continue
+		}
+
+		if attempt == nil {
+			attempt = &lockout.Attempt{Subject: subject, Key: key}
+		}
+
+		locked := attempt.Fail(now)
+
+		if err := attemptStore.AttemptSave(attempt); err != nil {
+			app.GetLogger().Error("At ext > AuthLockoutFail > AttemptSave", slog.String("error", err.Error()))
+			continue
+		}
+
+		if !locked {
+			continue
+		}
+
+		app.GetLogger().Warn("Authentication locked out",
+			slog.String("subject", subject),
+			slog.String("key", key),
+			slog.String("locked_until", attempt.LockedUntil.Format(time.DateTime)))
+
+		authLockoutAudit(ctx, app, AUDIT_ACTION_AUTH_LOCKOUT, attempt, "", ip)
+
+		if subject == lockout.SUBJECT_ACCOUNT {
+			authLockoutNotify(ctx, app, user, attempt)
+		}
+	}
+}
+
+// AuthLockoutSucceed forgets the failed attempts of the user, once logged
+// in. The failures of the IP address are kept, so logging in to another
+// account does not reset them.
+func AuthLockoutSucceed(app app.AppInterface, user userstore.UserInterface) {
+	attemptStore, err := lockout.NewStore(app.GetCustomStore())
+
+	if err != nil || user == nil {
+		return
+	}
+
+	attempt, err := attemptStore.AttemptFind(lockout.SUBJECT_ACCOUNT, user.GetID())
+
+	if err != nil {
+		app.GetLogger().Error("At ext > AuthLockoutSucceed > AttemptFind", slog.String("error", err.Error()))
+		return
+	}
+
+	if attempt == nil {
+		return
+	}
+
+	if err := attemptStore.AttemptDelete(attempt); err != nil {
+		app.GetLogger().Error("At ext > AuthLockoutSucceed > AttemptDelete", slog.String("error", err.Error()))
+	}
+}
+
+// AuthLockoutUnlock unlocks the account or IP address, on behalf of the
+// administrator, and adds the unlock to the audit log
+func AuthLockoutUnlock(ctx context.Context, app app.AppInterface, attempt *lockout.Attempt, administrator userstore.UserInterface) error {
+	attemptStore, err := lockout.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return err
+	}
+
+	if attempt == nil {
+		return errors.New("attempt is nil")
+	}
+
+	attempt.Unlock()
+
+	if err := attemptStore.AttemptSave(attempt); err != nil {
+		return err
+	}
+
+	administratorID := ""
+	if administrator != nil {
+		administratorID = administrator.GetID()
+	}
+
+	authLockoutAudit(ctx, app, AUDIT_ACTION_AUTH_UNLOCK, attempt, administratorID, "")
+
+	return nil
+}
+
+// authLockoutSubjects returns the keys of the subjects the attempts are
+// tracked for, by subject
+func authLockoutSubjects(user userstore.UserInterface, ip string) map[string]string {
+	subjects := map[string]string{}
+
+	if user != nil && user.GetID() != "" {
+		subjects[lockout.SUBJECT_ACCOUNT] = user.GetID()
+	}
+
+	if ip != "" {
+		subjects[lockout.SUBJECT_IP] = ip
+	}
+
+	return subjects
+}
+
+// authLockoutAudit adds the lockout or unlock to the audit log, if the
+// audit store is used
+func authLockoutAudit(ctx context.Context, app app.AppInterface, action string, attempt *lockout.Attempt, userID string, ip string) {
+	if app.GetAuditStore() == nil {
+		return
+	}
+
+	record := auditstore.NewRecord().
+		SetAction(action).
+		SetEntityType(attempt.Subject).
+		SetEntityID(attempt.Key).
+		SetUserID(userID).
+		SetIPAddress(ip)
+
+	if err := app.GetAuditStore().RecordCreate(ctx, record); err != nil {
+		app.GetLogger().Error("At ext > authLockoutAudit > RecordCreate", slog.String("error", err.Error()))
+	}
+}
+
+// authLockoutNotify tells the user by email their account was locked
+func authLockoutNotify(ctx context.Context, app app.AppInterface, user userstore.UserInterface, attempt *lockout.Attempt) {
+	email, _, _, _, _, err := UserUntokenizeTransparently(ctx, app, user)
+
+	if err != nil {
+		app.GetLogger().Error("At ext > authLockoutNotify > UserUntokenizeTransparently", slog.String("error", err.Error()))
+		return
+	}
+
+	if email == "" {
+		return
+	}
+
+	passwordForgotURL := ""
+	if app.GetConfig() != nil && app.GetConfig().GetPasswordAuthEnabled() {
+		passwordForgotURL = links.Auth().PasswordForgot()
+	}
+
+	lockedUntil := carbon.CreateFromStdTime(attempt.LockedUntil).ToDateTimeString(carbon.UTC)
+
+	if err := emails.NewAccountLockedEmail(app).Send(email, lockedUntil, passwordForgotURL); err != nil {
+		app.GetLogger().Error("At ext > authLockoutNotify > Send", slog.String("error", err.Error()))
+	}
+}
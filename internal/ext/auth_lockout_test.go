@@ -0,0 +1,109 @@
+package ext
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"project/internal/lockout"
+	"project/internal/testutils"
+
+	"github.com/dracory/userstore"
+)
+
+func TestAuthLockout_FailLockAndSucceed(t *testing.T) {
+	app := testutils.Setup(
+		testutils.WithUserStore(true),
+		testutils.WithCustomStore(true),
+		testutils.WithAuditStore(true),
+	)
+	ctx := context.Background()
+
+	user, err := UserCreateWithEmail(ctx, app, "user@example.com", userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatalf("UserCreateWithEmail failed: %v", err)
+	}
+
+	if wait := AuthLockoutWait(app, user, "10.0.0.1"); wait != 0 {
+		t.Fatalf("AuthLockoutWait() = %v without failures, want 0", wait)
+	}
+
+	for range lockout.PolicyFor(lockout.SUBJECT_ACCOUNT).LockAfter {
+		AuthLockoutFail(ctx, app, user, "10.0.0.1")
+	}
+
+	if wait := AuthLockoutWait(app, user, ""); wait < lockout.LOCK_DURATION-time.Minute {
+		t.Fatalf("AuthLockoutWait() = %v, want the account to be locked", wait)
+	}
+
+	// The IP address tolerates more failures
+	if wait := AuthLockoutWait(app, nil, "10.0.0.1"); wait != 0 {
+		t.Fatalf("AuthLockoutWait() = %v for the IP address, want 0", wait)
+	}
+
+	AuthLockoutSucceed(app, user)
+
+	if wait := AuthLockoutWait(app, user, ""); wait != 0 {
+		t.Fatalf("AuthLockoutWait() = %v after the login, want 0", wait)
+	}
+}
+
+func TestAuthLockoutUnlock(t *testing.T) {
+	app := testutils.Setup(testutils.WithCustomStore(true))
+	ctx := context.Background()
+
+	for range lockout.PolicyFor(lockout.SUBJECT_IP).LockAfter {
+		AuthLockoutFail(ctx, app, nil, "10.0.0.2")
+	}
+
+	if wait := AuthLockoutWait(app, nil, "10.0.0.2"); wait == 0 {
+		t.Fatal("expected the IP address to be locked")
+	}
+
+	attemptStore, err := lockout.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attempt, err := attemptStore.AttemptFind(lockout.SUBJECT_IP, "10.0.0.2")
+	if err != nil || attempt == nil {
+		t.Fatalf("expected the attempts of the IP address, got %v", err)
+	}
+
+	if err := AuthLockoutUnlock(ctx, app, attempt, nil); err != nil {
+		t.Fatalf("AuthLockoutUnlock failed: %v", err)
+	}
+
+	if wait := AuthLockoutWait(app, nil, "10.0.0.2"); wait != 0 {
+		t.Fatalf("AuthLockoutWait() = %v after the unlock, want 0", wait)
+	}
+}
+
+func TestAuthLockout_WithoutCustomStore(t *testing.T) {
+	app := testutils.Setup()
+
+	AuthLockoutFail(context.Background(), app, nil, "10.0.0.3")
+
+	if wait := AuthLockoutWait(app, nil, "10.0.0.3"); wait != 0 {
+		t.Fatalf("AuthLockoutWait() = %v without the custom store, want 0", wait)
+	}
+}
+
+func TestAuthLockoutWaitMessage(t *testing.T) {
+	cases := []struct {
+		wait     time.Duration
+		expected string
+	}{
+		{500 * time.Millisecond, "1 second."},
+		{30 * time.Second, "30 seconds"},
+		{15 * time.Minute, "15 minutes"},
+		{90 * time.Second, "2 minutes"},
+	}
+
+	for _, c := range cases {
+		if message := AuthLockoutWaitMessage(c.wait); !strings.Contains(message, c.expected) {
+			t.Errorf("AuthLockoutWaitMessage(%v) = %q, want %q", c.wait, message, c.expected)
+		}
+	}
+}
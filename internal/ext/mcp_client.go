@@ -0,0 +1,187 @@
+package ext
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"project/internal/app"
+	"project/internal/mcp"
+	"strings"
+	"time"
+)
+
+// ErrMCPClientInvalid is returned for the keys of no client, or of a
+// revoked one
+var ErrMCPClientInvalid = errors.New("invalid MCP client key")
+
+// MCP_LEGACY_CLIENT_ID is the ID of the client of the shared MCP_API_KEY,
+// allowed the blog and CMS tools it was used for
+const MCP_LEGACY_CLIENT_ID = "legacy"
+
+// mcpClientCacheKeyPrefix prefixes the memory cache keys of the validated
+// clients, by key hash
+const mcpClientCacheKeyPrefix = "mcp-client:"
+
+// mcpClientCacheDuration is the time a validated client is kept in the
+// memory cache, to not look it up on every request
+const mcpClientCacheDuration = 1 * time.Minute
+
+// mcpClientNameMaxLength is the maximum length of the name of a client
+const mcpClientNameMaxLength = 100
+
+// MCPClientCreate creates an MCP client allowed the tools (names, or
+// patterns as "blog_*"), created by the administrator.
+//
+// Returns the key, which is shown to the administrator once, as only its
+// hash is stored. The error is meant to be shown to the administrator.
+func MCPClientCreate(app app.AppInterface, name string, tools []string, createdBy string) (string, *mcp.Client, error) {
+	clientStore, err := mcp.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return "", nil, errors.New("please enter a name for the client")
+	}
+
+	if len(name) > mcpClientNameMaxLength {
+		return "", nil, errors.New("the name of the client is too long")
+	}
+
+	if len(tools) == 0 {
+		return "", nil, errors.New("please select at least one tool")
+	}
+
+	plain, hash, err := mcp.Generate()
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	client := &mcp.Client{
+		Name:      name,
+		Hash:      hash,
+		Hint:      plain[len(plain)-4:],
+		Tools:     tools,
+		CreatedBy: createdBy,
+	}
+
+	if err := clientStore.ClientCreate(client); err != nil {
+		return "", nil, err
+	}
+
+	return plain, client, nil
+}
+
+// MCPClientAuthenticate returns the client of the key sent by an MCP
+// client, or ErrMCPClientInvalid.
+//
+// The shared MCP_API_KEY of the config, if set, authenticates the legacy
+// client, allowed the blog and CMS tools only. The validated clients are
+// cached in memory for a minute, and the last used time is saved when the
+// client is looked up again.
+func MCPClientAuthenticate(app app.AppInterface, plain string) (*mcp.Client, error) {
+	if plain == "" {
+		return nil, ErrMCPClientInvalid
+	}
+
+	if !mcp.IsKey(plain) {
+		return mcpLegacyClient(app, plain)
+	}
+
+	if app.GetCustomStore() == nil {
+		return nil, ErrMCPClientInvalid
+	}
+
+	hash := mcp.Hash(plain)
+	memoryCache := app.GetMemoryCache()
+
+	if memoryCache != nil {
+		if item := memoryCache.Get(mcpClientCacheKeyPrefix + hash); item != nil {
+			if cached, ok := item.Value().(mcp.Client); ok && !cached.IsRevoked() {
+				return &cached, nil
+			}
+		}
+	}
+
+	clientStore, err := mcp.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := clientStore.ClientFindByHash(hash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if client == nil || client.IsRevoked() {
+		return nil, ErrMCPClientInvalid
+	}
+
+	client.LastUsedAt = time.Now().UTC()
+
+	if err := clientStore.ClientUpdate(client); err != nil {
+		app.GetLogger().Warn("At ext > MCPClientAuthenticate > ClientUpdate", slog.String("error", err.Error()))
+	}
+
+	if memoryCache != nil {
+		memoryCache.Set(mcpClientCacheKeyPrefix+hash, *client, mcpClientCacheDuration)
+	}
+
+	return client, nil
+}
+
+// MCPClientRevoke revokes the client, which stops working at once (within
+// a minute on the other instances of the application, see the cache)
+func MCPClientRevoke(app app.AppInterface, client *mcp.Client) error {
+	clientStore, err := mcp.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return err
+	}
+
+	if client == nil {
+		return errors.New("client is nil")
+	}
+
+	if client.IsRevoked() {
+		return nil
+	}
+
+	client.RevokedAt = time.Now().UTC()
+
+	if err := clientStore.ClientUpdate(client); err != nil {
+		return err
+	}
+
+	if app.GetMemoryCache() != nil {
+		app.GetMemoryCache().Delete(mcpClientCacheKeyPrefix + client.Hash)
+	}
+
+	return nil
+}
+
+// mcpLegacyClient returns the legacy client if the key is the shared
+// MCP_API_KEY of the config
+func mcpLegacyClient(app app.AppInterface, plain string) (*mcp.Client, error) {
+	if app.GetConfig() == nil {
+		return nil, ErrMCPClientInvalid
+	}
+
+	expected := strings.TrimSpace(app.GetConfig().GetCmsMcpApiKey())
+
+	if expected == "" || subtle.ConstantTimeCompare([]byte(plain), []byte(expected)) != 1 {
+		return nil, ErrMCPClientInvalid
+	}
+
+	return &mcp.Client{
+		ID:    MCP_LEGACY_CLIENT_ID,
+		Name:  "MCP_API_KEY",
+		Tools: []string{mcp.PROVIDER_BLOG + "_*", mcp.PROVIDER_CMS + "_*"},
+	}, nil
+}
@@ -0,0 +1,73 @@
+package ext
+
+import (
+	"errors"
+	"testing"
+
+	"project/internal/mcp"
+	"project/internal/testutils"
+)
+
+func TestMCPClient_CreateAuthenticateRevoke(t *testing.T) {
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	plain, client, err := MCPClientCreate(app, "Assistant", []string{"blog_*"}, "admin_1")
+	if err != nil {
+		t.Fatalf("MCPClientCreate failed: %v", err)
+	}
+
+	if !mcp.IsKey(plain) || client.Hash == plain || client.Hash != mcp.Hash(plain) {
+		t.Fatal("expected only the hash of the key to be stored")
+	}
+
+	found, err := MCPClientAuthenticate(app, plain)
+	if err != nil {
+		t.Fatalf("MCPClientAuthenticate failed: %v", err)
+	}
+
+	if found.ID != client.ID || found.LastUsedAt.IsZero() || !found.Allows("blog_post_list") {
+		t.Fatalf("expected the client, with the last used time set, got %+v", found)
+	}
+
+	if err := MCPClientRevoke(app, found); err != nil {
+		t.Fatalf("MCPClientRevoke failed: %v", err)
+	}
+
+	// The revoked client is removed from the cache at once
+	if _, err := MCPClientAuthenticate(app, plain); !errors.Is(err, ErrMCPClientInvalid) {
+		t.Fatalf("expected the revoked client to be invalid, got %v", err)
+	}
+}
+
+func TestMCPClientCreate_Validation(t *testing.T) {
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	if _, _, err := MCPClientCreate(app, " ", []string{"blog_*"}, ""); err == nil {
+		t.Error("expected an error without a name")
+	}
+
+	if _, _, err := MCPClientCreate(app, "Assistant", nil, ""); err == nil {
+		t.Error("expected an error without tools")
+	}
+}
+
+func TestMCPClientAuthenticate_LegacyKey(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetCmsMcpApiKey("shared-key")
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	client, err := MCPClientAuthenticate(app, "shared-key")
+	if err != nil {
+		t.Fatalf("MCPClientAuthenticate failed: %v", err)
+	}
+
+	if client.ID != MCP_LEGACY_CLIENT_ID || !client.Allows("cms_page_list") || client.Allows("users_list") {
+		t.Fatalf("expected the legacy client, allowed the blog and CMS tools only, got %+v", client)
+	}
+
+	for _, key := range []string{"", "wrong-key", "mcp_unknown"} {
+		if _, err := MCPClientAuthenticate(app, key); !errors.Is(err, ErrMCPClientInvalid) {
+			t.Errorf("expected %q to be invalid, got %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package ext
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"project/internal/app"
+
+	"github.com/dracory/blindindexstore"
+	"github.com/dracory/userstore"
+)
+
+// ErrUserNotFoundForBlindIndex is returned by UserFindByEmail when the email
+// is in the blind index, but the user it references does not exist
+var ErrUserNotFoundForBlindIndex = errors.New("user not found, even though email was found in the blind index")
+
+// UserFindByEmail finds the user with the given email address.
+//
+// When the user store vault is enabled the emails are stored as vault
+// tokens, so the user is found through the email blind index instead.
+//
+// Returns nil, without error, if no user has the email address.
+// Returns ErrUserNotFoundForBlindIndex if the blind index is out of sync.
+func UserFindByEmail(ctx context.Context, app app.AppInterface, email string) (userstore.UserInterface, error) {
+	if app.GetUserStore() == nil {
+		return nil, errors.New("user store is nil")
+	}
+
+	if !app.GetConfig().GetUserStoreVaultEnabled() {
+		return app.GetUserStore().UserFindByEmail(ctx, email)
+	}
+
+	if app.GetVaultStore() == nil {
+		return nil, errors.New(`vault store is nil`)
+	}
+
+	if app.GetBlindIndexStoreEmail() == nil {
+		return nil, errors.New(`blind index store is nil`)
+	}
+
+	recordsFound, err := app.GetBlindIndexStoreEmail().SearchValueList(ctx, blindindexstore.NewSearchValueQuery().
+		SetSearchValue(email).
+		SetSearchType(blindindexstore.SEARCH_TYPE_EQUALS))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(recordsFound) < 1 {
+		return nil, nil
+	}
+
+	userID := recordsFound[0].SourceReferenceID()
+
+	user, err := app.GetUserStore().UserFindByID(ctx, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		app.GetLogger().Warn("At ext > UserFindByEmail",
+			slog.String("error", "User not found, even though email was found in the blind index, and user ID returned successfully"),
+			slog.String("user", userID))
+		return nil, ErrUserNotFoundForBlindIndex
+	}
+
+	return user, nil
+}
+
+// UserCreateWithEmail creates a new user with the given email address.
+//
+// When the user store vault is enabled, the email is never stored in
+// plaintext: the user keeps a vault token of the email, and the email
+// is added to the blind index, so UserFindByEmail can find the user.
+func UserCreateWithEmail(ctx context.Context, app app.AppInterface, email string, status string) (userstore.UserInterface, error) {
+	if app.GetUserStore() == nil {
+		return nil, errors.New("user store is nil")
+	}
+
+	vaultEnabled := app.GetConfig().GetUserStoreVaultEnabled()
+
+	if vaultEnabled && app.GetVaultStore() == nil {
+		return nil, errors.New(`vault store is nil`)
+	}
+
+	if vaultEnabled && app.GetBlindIndexStoreEmail() == nil {
+		return nil, errors.New(`blind index store is nil`)
+	}
+
+	user := userstore.NewUser().
+		SetStatus(status).
+		SetEmail(email)
+
+	if err := app.GetUserStore().UserCreate(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if !vaultEnabled {
+		return user, nil
+	}
+
+	emailToken, err := app.GetVaultStore().TokenCreate(ctx, email, app.GetConfig().GetVaultStoreKey(), 20)
+
+	if err != nil {
+		return nil, err
+	}
+
+	user.SetEmail(emailToken)
+
+	if err := app.GetUserStore().UserUpdate(ctx, user); err != nil {
+		return nil, err
+	}
+
+	searchValue := blindindexstore.NewSearchValue().
+		SetSourceReferenceID(user.GetID()).
+		SetSearchValue(email)
+
+	if err := app.GetBlindIndexStoreEmail().SearchValueCreate(ctx, searchValue); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,85 @@
+package ext
+
+import (
+	"context"
+	"testing"
+
+	"project/internal/testutils"
+
+	"github.com/dracory/userstore"
+)
+
+func TestUserFindByEmail_WithoutVault(t *testing.T) {
+	app := testutils.Setup(testutils.WithUserStore(true))
+	ctx := context.Background()
+
+	user, err := UserFindByEmail(ctx, app, "user@example.com")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if user != nil {
+		t.Fatal("expected no user before creation")
+	}
+
+	created, err := UserCreateWithEmail(ctx, app, "user@example.com", userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatalf("UserCreateWithEmail failed: %v", err)
+	}
+
+	if created.GetEmail() != "user@example.com" {
+		t.Fatalf("expected plain email without vault, got %q", created.GetEmail())
+	}
+
+	user, err = UserFindByEmail(ctx, app, "user@example.com")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if user == nil || user.GetID() != created.GetID() {
+		t.Fatal("expected to find the created user")
+	}
+}
+
+func TestUserFindByEmail_WithVault(t *testing.T) {
+	app := testutils.Setup(
+		testutils.WithUserStore(true, true),
+		testutils.WithVaultStore(true, untokenizeVaultKey),
+	)
+	ctx := context.Background()
+
+	created, err := UserCreateWithEmail(ctx, app, "user@example.com", userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatalf("UserCreateWithEmail failed: %v", err)
+	}
+
+	if created.GetEmail() == "user@example.com" {
+		t.Fatal("expected the email to be tokenized with vault enabled")
+	}
+
+	user, err := UserFindByEmail(ctx, app, "user@example.com")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if user == nil || user.GetID() != created.GetID() {
+		t.Fatal("expected to find the created user through the blind index")
+	}
+
+	user, err = UserFindByEmail(ctx, app, "other@example.com")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if user != nil {
+		t.Fatal("expected no user for an unknown email")
+	}
+}
+
+func TestUserFindByEmail_UserStoreNil(t *testing.T) {
+	app := testutils.Setup()
+
+	if _, err := UserFindByEmail(context.Background(), app, "user@example.com"); err == nil {
+		t.Fatal("expected error when user store is nil")
+	}
+
+	if _, err := UserCreateWithEmail(context.Background(), app, "user@example.com", userstore.USER_STATUS_ACTIVE); err == nil {
+		t.Fatal("expected error when user store is nil")
+	}
+}
@@ -0,0 +1,229 @@
+package ext
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/helpers"
+	"sync"
+	"time"
+
+	"github.com/dracory/sessionstore"
+	"github.com/dromara/carbon/v2"
+)
+
+// USER_SESSION_ACTIVITY_INTERVAL is how often the last activity of a
+// session is saved, so not every request writes to the session store
+const USER_SESSION_ACTIVITY_INTERVAL = 5 * time.Minute
+
+// ErrUserSessionNotFound is returned when the session does not exist, or
+// is not a session of the user
+var ErrUserSessionNotFound = errors.New("session not found")
+
+const (
+	userSessionCountryCacheKeyPrefix = "user_session_country:"
+	userSessionCountryCacheSeconds   = 24 * 60 * 60
+)
+
+// userSessionCountryEndpoint is the ip2c compatible endpoint the
+// countries are looked up with
+var userSessionCountryEndpoint = "https://ip2c.org/"
+
+// userSessionCountryHTTPClient has a short timeout, as the countries are
+// looked up while the sessions page is shown
+var userSessionCountryHTTPClient = &http.Client{
+	Timeout: 2 * time.Second,
+}
+
+// userSessionCountryLookupTimeout limits the time all the lookups of a
+// page take together. The countries not found in time are unknown
+var userSessionCountryLookupTimeout = 3 * time.Second
+
+// userSessionCountryLookupConcurrency is how many countries are looked up
+// at the same time
+const userSessionCountryLookupConcurrency = 5
+
+// UserSessionList returns the active sessions of the user (the devices
+// the user is signed in on), the most recently active first
+func UserSessionList(ctx context.Context, app app.AppInterface, userID string) ([]sessionstore.SessionInterface, error) {
+	if app.GetSessionStore() == nil {
+		return nil, errors.New("session store is nil")
+	}
+
+	if userID == "" {
+		return nil, errors.New("user id is empty")
+	}
+
+	query := sessionstore.NewSessionQuery().
+		SetUserID(userID).
+		SetExpiresAtGte(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC)).
+		SetOrderBy(sessionstore.COLUMN_UPDATED_AT).
+		SetSortOrder("DESC")
+
+	return app.GetSessionStore().SessionList(ctx, query)
+}
+
+// UserSessionRevoke signs the user out of the session, which is deleted
+func UserSessionRevoke(ctx context.Context, app app.AppInterface, userID string, sessionID string) error {
+	if app.GetSessionStore() == nil {
+		return errors.New("session store is nil")
+	}
+
+	if userID == "" || sessionID == "" {
+		return ErrUserSessionNotFound
+	}
+
+	session, err := app.GetSessionStore().SessionFindByID(ctx, sessionID)
+
+	if err != nil {
+		return err
+	}
+
+	// Another user's session is not found, so it cannot be revoked
+	if session == nil || session.GetUserID() != userID {
+		return ErrUserSessionNotFound
+	}
+
+	return app.GetSessionStore().SessionDelete(ctx, session)
+}
+
+// UserSessionRevokeOthers signs the user out of all the sessions but the
+// current one, and returns how many were revoked
+func UserSessionRevokeOthers(ctx context.Context, app app.AppInterface, userID string, currentSessionID string) (int, error) {
+	sessions, err := UserSessionList(ctx, app, userID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+
+	for _, session := range sessions {
+		if session.GetID() == currentSessionID {
+			continue
+		}
+
+		if err := app.GetSessionStore().SessionDelete(ctx, session); err != nil {
+			return revoked, err
+		}
+
+		revoked++
+	}
+
+	return revoked, nil
+}
+
+// UserSessionTouch saves the last activity of the session, at most once
+// per USER_SESSION_ACTIVITY_INTERVAL. The expiry is not changed.
+func UserSessionTouch(ctx context.Context, app app.AppInterface, session sessionstore.SessionInterface) {
+	if app.GetSessionStore() == nil || session == nil {
+		return
+	}
+
+	updatedAt := session.GetUpdatedAtCarbon()
+
+	if updatedAt != nil && updatedAt.StdTime().After(time.Now().Add(-USER_SESSION_ACTIVITY_INTERVAL)) {
+		return
+	}
+
+	if err := app.GetSessionStore().SessionUpdate(ctx, session); err != nil {
+		app.GetLogger().Error("At ext > UserSessionTouch > SessionUpdate", slog.String("error", err.Error()))
+		return
+	}
+
+	session.SetUpdatedAt(carbon.Now(carbon.UTC).ToDateTimeString(carbon.UTC))
+}
+
+// UserSessionCountry returns the approximate country (the ISO code) of
+// the IP address of a session, or helpers.COUNTRY_UNKNOWN. See
+// UserSessionCountries.
+func UserSessionCountry(ctx context.Context, app app.AppInterface, ip string) string {
+	return UserSessionCountries(ctx, app, []string{ip})[ip]
+}
+
+// UserSessionCountries returns the approximate countries (the ISO codes)
+// of the IP addresses of the sessions, keyed by IP address, or
+// helpers.COUNTRY_UNKNOWN.
+//
+// The countries are cached for a day in the cache store, if used, as the
+// same IP addresses are looked up each time the sessions are listed. The
+// others are looked up concurrently, within userSessionCountryLookupTimeout
+// in total, unless the lookup is disabled in the config.
+func UserSessionCountries(ctx context.Context, app app.AppInterface, ips []string) map[string]string {
+	countries := map[string]string{}
+	lookups := []string{}
+
+	for _, ip := range ips {
+		if _, found := countries[ip]; found {
+			continue
+		}
+
+		countries[ip] = helpers.COUNTRY_UNKNOWN
+
+		if app.GetCacheStore() != nil {
+			if country, err := app.GetCacheStore().Get(userSessionCountryCacheKeyPrefix+ip, ""); err == nil && country != "" {
+				countries[ip] = country
+				continue
+			}
+		}
+
+		lookups = append(lookups, ip)
+	}
+
+	if len(lookups) == 0 || app.GetConfig() == nil || !app.GetConfig().GetSessionCountryLookupEnabled() {
+		return countries
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, userSessionCountryLookupTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, userSessionCountryLookupConcurrency)
+
+	for _, ip := range lookups {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			country := userSessionCountryLookup(ctx, app, ip)
+
+			mu.Lock()
+			countries[ip] = country
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	return countries
+}
+
+// userSessionCountryLookup looks up the country of the IP address, and
+// caches it
+func userSessionCountryLookup(ctx context.Context, app app.AppInterface, ip string) string {
+	if ctx.Err() != nil {
+		return helpers.COUNTRY_UNKNOWN
+	}
+
+	country := helpers.CountryByIP(ctx, userSessionCountryHTTPClient, userSessionCountryEndpoint, ip)
+
+	// Failed lookups are retried next time
+	if country == helpers.COUNTRY_ERROR {
+		return helpers.COUNTRY_UNKNOWN
+	}
+
+	if app.GetCacheStore() != nil {
+		if err := app.GetCacheStore().Set(userSessionCountryCacheKeyPrefix+ip, country, userSessionCountryCacheSeconds); err != nil {
+			app.GetLogger().Error("At ext > userSessionCountryLookup > Set", slog.String("error", err.Error()))
+		}
+	}
+
+	return country
+}
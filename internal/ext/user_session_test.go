@@ -0,0 +1,266 @@
+package ext
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"project/internal/helpers"
+	"project/internal/testutils"
+
+	"github.com/dracory/sessionstore"
+	"github.com/dromara/carbon/v2"
+)
+
+// seedUserSessions seeds the sessions of the users, and returns them
+func seedUserSessions(t *testing.T, sessionStore sessionstore.StoreInterface, userIDs ...string) []sessionstore.SessionInterface {
+	t.Helper()
+
+	sessions := []sessionstore.SessionInterface{}
+
+	for _, userID := range userIDs {
+		session := sessionstore.NewSession().
+			SetUserID(userID).
+			SetIPAddress("127.0.0.1").
+			SetUserAgent("Mozilla/5.0").
+			SetExpiresAt(carbon.Now(carbon.UTC).AddHours(2).ToDateTimeString(carbon.UTC))
+
+		if err := sessionStore.SessionCreate(context.Background(), session); err != nil {
+			t.Fatal(err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions
+}
+
+func TestUserSessionList(t *testing.T) {
+	app := testutils.Setup(testutils.WithSessionStore(true))
+	ctx := context.Background()
+
+	seedUserSessions(t, app.GetSessionStore(), "user1", "user1", "user2")
+
+	expired := sessionstore.NewSession().
+		SetUserID("user1").
+		SetExpiresAt(carbon.Now(carbon.UTC).SubHours(1).ToDateTimeString(carbon.UTC))
+	if err := app.GetSessionStore().SessionCreate(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := UserSessionList(ctx, app, "user1")
+	if err != nil {
+		t.Fatalf("UserSessionList failed: %v", err)
+	}
+
+	if len(sessions) != 2 {
+		t.Fatalf("UserSessionList() returned %d sessions, want the 2 active sessions of the user", len(sessions))
+	}
+
+	if _, err := UserSessionList(ctx, app, ""); err == nil {
+		t.Fatal("expected an error without the user id")
+	}
+}
+
+func TestUserSessionRevoke(t *testing.T) {
+	app := testutils.Setup(testutils.WithSessionStore(true))
+	ctx := context.Background()
+
+	sessions := seedUserSessions(t, app.GetSessionStore(), "user1", "user2")
+
+	// Another user's session is not revoked
+	if err := UserSessionRevoke(ctx, app, "user1", sessions[1].GetID()); !errors.Is(err, ErrUserSessionNotFound) {
+		t.Fatalf("UserSessionRevoke() = %v for another user's session, want ErrUserSessionNotFound", err)
+	}
+
+	if err := UserSessionRevoke(ctx, app, "user1", sessions[0].GetID()); err != nil {
+		t.Fatalf("UserSessionRevoke failed: %v", err)
+	}
+
+	revoked, err := app.GetSessionStore().SessionFindByID(ctx, sessions[0].GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked != nil {
+		t.Fatal("expected the session to be deleted")
+	}
+}
+
+func TestUserSessionRevokeOthers(t *testing.T) {
+	app := testutils.Setup(testutils.WithSessionStore(true))
+	ctx := context.Background()
+
+	sessions := seedUserSessions(t, app.GetSessionStore(), "user1", "user1", "user1", "user2")
+
+	revoked, err := UserSessionRevokeOthers(ctx, app, "user1", sessions[0].GetID())
+	if err != nil {
+		t.Fatalf("UserSessionRevokeOthers failed: %v", err)
+	}
+
+	if revoked != 2 {
+		t.Fatalf("UserSessionRevokeOthers() = %d, want 2", revoked)
+	}
+
+	remaining, err := UserSessionList(ctx, app, "user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(remaining) != 1 || remaining[0].GetID() != sessions[0].GetID() {
+		t.Fatal("expected only the current session to remain")
+	}
+
+	others, err := UserSessionList(ctx, app, "user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(others) != 1 {
+		t.Fatal("expected the sessions of the other users to remain")
+	}
+}
+
+func TestUserSessionTouch(t *testing.T) {
+	app := testutils.Setup(testutils.WithSessionStore(true))
+	ctx := context.Background()
+
+	session := seedUserSessions(t, app.GetSessionStore(), "user1")[0]
+	session.SetUpdatedAt(carbon.Now(carbon.UTC).SubHours(1).ToDateTimeString(carbon.UTC))
+
+	UserSessionTouch(ctx, app, session)
+
+	if session.GetUpdatedAtCarbon().StdTime().Before(carbon.Now(carbon.UTC).SubMinutes(1).StdTime()) {
+		t.Fatal("expected the last activity of the session to be saved")
+	}
+}
+
+func TestUserSessionCountry_Cached(t *testing.T) {
+	app := testutils.Setup(testutils.WithCacheStore(true))
+
+	if err := app.GetCacheStore().Set(userSessionCountryCacheKeyPrefix+"8.8.8.8", "US", 60); err != nil {
+		t.Fatal(err)
+	}
+
+	if country := UserSessionCountry(context.Background(), app, "8.8.8.8"); country != "US" {
+		t.Fatalf("UserSessionCountry() = %q, want the cached US", country)
+	}
+
+	if country := UserSessionCountry(context.Background(), app, "127.0.0.1"); country != helpers.COUNTRY_UNKNOWN {
+		t.Fatalf("UserSessionCountry() = %q for localhost, want %q", country, helpers.COUNTRY_UNKNOWN)
+	}
+}
+
+func TestUserSessionCountry_LookupFailed(t *testing.T) {
+	app := testutils.Setup(testutils.WithCacheStore(true))
+	app.GetConfig().SetSessionCountryLookupEnabled(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	endpoint := userSessionCountryEndpoint
+	userSessionCountryEndpoint = server.URL + "/"
+	defer func() { userSessionCountryEndpoint = endpoint }()
+
+	if country := UserSessionCountry(context.Background(), app, "8.8.4.4"); country != helpers.COUNTRY_UNKNOWN {
+		t.Fatalf("UserSessionCountry() = %q after a failed lookup, want %q", country, helpers.COUNTRY_UNKNOWN)
+	}
+
+	if cached, _ := app.GetCacheStore().Get(userSessionCountryCacheKeyPrefix+"8.8.4.4", ""); cached != "" {
+		t.Fatalf("expected the failed lookup not to be cached, got %q", cached)
+	}
+}
+
+func TestUserSessionCountries_Concurrent(t *testing.T) {
+	app := testutils.Setup(testutils.WithCacheStore(true))
+	app.GetConfig().SetSessionCountryLookupEnabled(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		_, _ = w.Write([]byte("1;US;USA;United States"))
+	}))
+	defer server.Close()
+
+	endpoint := userSessionCountryEndpoint
+	userSessionCountryEndpoint = server.URL + "/"
+	defer func() { userSessionCountryEndpoint = endpoint }()
+
+	ips := []string{"8.8.8.1", "8.8.8.2", "8.8.8.3", "8.8.8.4", "8.8.8.5", "8.8.8.1"}
+
+	start := time.Now()
+	countries := UserSessionCountries(context.Background(), app, ips)
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected the countries to be looked up concurrently, took %s", elapsed)
+	}
+
+	for _, ip := range ips {
+		if countries[ip] != "US" {
+			t.Errorf("UserSessionCountries()[%q] = %q, want US", ip, countries[ip])
+		}
+	}
+}
+
+func TestUserSessionCountries_Timeout(t *testing.T) {
+	app := testutils.Setup(testutils.WithCacheStore(true))
+	app.GetConfig().SetSessionCountryLookupEnabled(true)
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	endpoint := userSessionCountryEndpoint
+	userSessionCountryEndpoint = server.URL + "/"
+	defer func() { userSessionCountryEndpoint = endpoint }()
+
+	timeout := userSessionCountryLookupTimeout
+	userSessionCountryLookupTimeout = 100 * time.Millisecond
+	defer func() { userSessionCountryLookupTimeout = timeout }()
+
+	start := time.Now()
+	countries := UserSessionCountries(context.Background(), app, []string{"8.8.8.1", "8.8.8.2", "8.8.8.3", "8.8.8.4", "8.8.8.5", "8.8.8.6", "8.8.8.7"})
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the lookups to stop at the timeout, took %s", elapsed)
+	}
+
+	if countries["8.8.8.7"] != helpers.COUNTRY_UNKNOWN {
+		t.Errorf("UserSessionCountries() = %q after the timeout, want %q", countries["8.8.8.7"], helpers.COUNTRY_UNKNOWN)
+	}
+}
+
+func TestUserSessionCountries_LookupDisabled(t *testing.T) {
+	app := testutils.Setup(testutils.WithCacheStore(true))
+	app.GetConfig().SetSessionCountryLookupEnabled(false)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte("1;US;USA;United States"))
+	}))
+	defer server.Close()
+
+	endpoint := userSessionCountryEndpoint
+	userSessionCountryEndpoint = server.URL + "/"
+	defer func() { userSessionCountryEndpoint = endpoint }()
+
+	if country := UserSessionCountry(context.Background(), app, "8.8.8.8"); country != helpers.COUNTRY_UNKNOWN {
+		t.Fatalf("UserSessionCountry() = %q with the lookup disabled, want %q", country, helpers.COUNTRY_UNKNOWN)
+	}
+
+	if requests.Load() != 0 {
+		t.Errorf("expected no lookup, got %d requests", requests.Load())
+	}
+}
@@ -0,0 +1,344 @@
+package ext
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/customrecords"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dracory/str"
+	"github.com/dracory/userstore"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// TWO_FACTOR_RECOVERY_CODE_COUNT is the number of recovery codes given to
+// the user when two-factor authentication is enabled
+const TWO_FACTOR_RECOVERY_CODE_COUNT = 10
+
+// Record types of the claims of the TOTP time steps and of the recovery
+// codes used, in the custom store
+const (
+	RECORD_TYPE_USER_TWO_FACTOR_TOTP_STEP     = "user_two_factor_totp_step"
+	RECORD_TYPE_USER_TWO_FACTOR_RECOVERY_CODE = "user_two_factor_recovery_code"
+)
+
+// twoFactorTotpPeriod and twoFactorTotpSkew match the defaults of
+// totp.Validate: 30 second codes, accepted one step before or after now
+const twoFactorTotpPeriod = 30
+const twoFactorTotpSkew = 1
+
+// twoFactorRecoveryCodeGamma leaves out the characters easily mistaken
+// for one another (0/o, 1/l/i)
+const twoFactorRecoveryCodeGamma = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// UserTwoFactorEnabled returns whether the user has two-factor
+// authentication enabled
+func UserTwoFactorEnabled(user userstore.UserInterface) bool {
+	if user == nil {
+		return false
+	}
+
+	return user.GetMeta(config.USER_META_TWO_FACTOR_SECRET) != ""
+}
+
+// UserTwoFactorEnable enables two-factor authentication for the user with
+// the TOTP secret, once the user confirmed a code generated with it.
+//
+// The secret is stored encrypted in the vault store, and only its token
+// is kept on the user. Returns the new recovery codes, which are shown to
+// the user once, as only their hashes are stored.
+func UserTwoFactorEnable(ctx context.Context, app app.AppInterface, user userstore.UserInterface, secret string) ([]string, error) {
+	if app.GetUserStore() == nil {
+		return nil, errors.New("user store is nil")
+	}
+
+	if app.GetVaultStore() == nil {
+		return nil, errors.New("vault store is nil")
+	}
+
+	if user == nil {
+		return nil, errors.New("user is nil")
+	}
+
+	if secret == "" {
+		return nil, errors.New("secret is required")
+	}
+
+	secretToken, err := app.GetVaultStore().TokenCreate(ctx, secret, app.GetConfig().GetVaultStoreKey(), 20)
+
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := userTwoFactorRecoveryCodesSet(user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.SetMeta(config.USER_META_TWO_FACTOR_SECRET, secretToken); err != nil {
+		return nil, err
+	}
+
+	if err := app.GetUserStore().UserUpdate(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// UserTwoFactorDisable disables two-factor authentication for the user,
+// removing the secret from the vault store and the recovery codes.
+// Used by the user, and by the administrators to reset a lost device.
+func UserTwoFactorDisable(ctx context.Context, app app.AppInterface, user userstore.UserInterface) error {
+	if app.GetUserStore() == nil {
+		return errors.New("user store is nil")
+	}
+
+	if user == nil {
+		return errors.New("user is nil")
+	}
+
+	secretToken := user.GetMeta(config.USER_META_TWO_FACTOR_SECRET)
+
+	if secretToken != "" && app.GetVaultStore() != nil {
+		if err := app.GetVaultStore().TokenDelete(ctx, secretToken); err != nil {
+			return err
+		}
+	}
+
+	if err := user.SetMeta(config.USER_META_TWO_FACTOR_SECRET, ""); err != nil {
+		return err
+	}
+
+	if err := user.SetMeta(config.USER_META_TWO_FACTOR_RECOVERY_CODES, ""); err != nil {
+		return err
+	}
+
+	if err := user.SetMeta(config.USER_META_TWO_FACTOR_LAST_STEP, ""); err != nil {
+		return err
+	}
+
+	return app.GetUserStore().UserUpdate(ctx, user)
+}
+
+// UserTwoFactorVerify returns whether the code is a valid TOTP code of the
+// user, or one of the unused recovery codes of the user. A recovery code
+// is used up, so it cannot be used again.
+//
+// A TOTP code is claimed by its time step, and a recovery code by its
+// hash, in the custom store, so of the concurrent requests with the same
+// code only one is accepted, and a code cannot be replayed within its
+// window.
+func UserTwoFactorVerify(ctx context.Context, app app.AppInterface, user userstore.UserInterface, code string) (bool, error) {
+	if !UserTwoFactorEnabled(user) {
+		return false, nil
+	}
+
+	if app.GetUserStore() == nil {
+		return false, errors.New("user store is nil")
+	}
+
+	if app.GetVaultStore() == nil {
+		return false, errors.New("vault store is nil")
+	}
+
+	if app.GetCustomStore() == nil {
+		return false, errors.New("custom store is nil")
+	}
+
+	code = strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+
+	if code == "" {
+		return false, nil
+	}
+
+	secret, err := app.GetVaultStore().TokenRead(ctx, user.GetMeta(config.USER_META_TWO_FACTOR_SECRET), app.GetConfig().GetVaultStoreKey())
+
+	if err != nil {
+		return false, err
+	}
+
+	if step, found := userTwoFactorTotpStep(code, secret, time.Now()); found {
+		return userTwoFactorTotpStepUse(ctx, app, user, step)
+	}
+
+	return userTwoFactorRecoveryCodeUse(ctx, app, user, code)
+}
+
+// UserTwoFactorRecoveryCodesRegenerate replaces the recovery codes of the
+// user with new ones, and returns them
+func UserTwoFactorRecoveryCodesRegenerate(ctx context.Context, app app.AppInterface, user userstore.UserInterface) ([]string, error) {
+	if app.GetUserStore() == nil {
+		return nil, errors.New("user store is nil")
+	}
+
+	if !UserTwoFactorEnabled(user) {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	codes, err := userTwoFactorRecoveryCodesSet(user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := app.GetUserStore().UserUpdate(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// UserTwoFactorRecoveryCodesRemaining returns the number of unused
+// recovery codes of the user
+func UserTwoFactorRecoveryCodesRemaining(user userstore.UserInterface) int {
+	return len(userTwoFactorRecoveryCodeHashes(user))
+}
+
+// userTwoFactorRecoveryCodesSet generates new recovery codes, and sets
+// their hashes on the user (without saving the user)
+func userTwoFactorRecoveryCodesSet(user userstore.UserInterface) ([]string, error) {
+	codes := make([]string, 0, TWO_FACTOR_RECOVERY_CODE_COUNT)
+	hashes := make([]string, 0, TWO_FACTOR_RECOVERY_CODE_COUNT)
+
+	for range TWO_FACTOR_RECOVERY_CODE_COUNT {
+		code, err := str.RandomFromGamma(10, twoFactorRecoveryCodeGamma)
+
+		if err != nil {
+			return nil, err
+		}
+
+		hash, err := str.ToBcryptHash(code)
+
+		if err != nil {
+			return nil, err
+		}
+
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hash)
+	}
+
+	hashesJSON, err := json.Marshal(hashes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := user.SetMeta(config.USER_META_TWO_FACTOR_RECOVERY_CODES, string(hashesJSON)); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// userTwoFactorTotpStep returns the time step the TOTP code was generated
+// for, looking at the steps within the allowed skew of now
+func userTwoFactorTotpStep(code string, secret string, now time.Time) (int64, bool) {
+	current := now.Unix() / twoFactorTotpPeriod
+
+	for step := current + twoFactorTotpSkew; step >= current-twoFactorTotpSkew; step-- {
+		valid, err := totp.ValidateCustom(code, secret, time.Unix(step*twoFactorTotpPeriod, 0), totp.ValidateOpts{
+			Period: twoFactorTotpPeriod,
+			Digits: otp.DigitsSix,
+		})
+
+		if err == nil && valid {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// userTwoFactorTotpStepUse claims the time step of an accepted TOTP code
+// for the user, and returns false when the step was claimed before. The
+// last step is saved on the user for display only
+func userTwoFactorTotpStepUse(ctx context.Context, app app.AppInterface, user userstore.UserInterface, step int64) (bool, error) {
+	claimed, err := customrecords.Claim(app.GetCustomStore(), RECORD_TYPE_USER_TWO_FACTOR_TOTP_STEP, user.GetID()+":"+strconv.FormatInt(step, 10), user.GetID())
+
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	lastStep, err := strconv.ParseInt(user.GetMeta(config.USER_META_TWO_FACTOR_LAST_STEP), 10, 64)
+
+	if err == nil && step <= lastStep {
+		return true, nil
+	}
+
+	if err := user.SetMeta(config.USER_META_TWO_FACTOR_LAST_STEP, strconv.FormatInt(step, 10)); err != nil {
+		return false, err
+	}
+
+	if err := app.GetUserStore().UserUpdate(ctx, user); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// userTwoFactorRecoveryCodeUse claims the recovery code for the user, and
+// returns whether it was one of the unused recovery codes. The code is then
+// removed from the user, which keeps the codes left for display only
+func userTwoFactorRecoveryCodeUse(ctx context.Context, app app.AppInterface, user userstore.UserInterface, code string) (bool, error) {
+	hashes := userTwoFactorRecoveryCodeHashes(user)
+
+	for i, hash := range hashes {
+		if !str.BcryptHashCompare(code, hash) {
+			continue
+		}
+
+		claimed, err := customrecords.Claim(app.GetCustomStore(), RECORD_TYPE_USER_TWO_FACTOR_RECOVERY_CODE, user.GetID()+":"+hash, user.GetID())
+
+		if err != nil || !claimed {
+			return false, err
+		}
+
+		remaining := append(hashes[:i:i], hashes[i+1:]...)
+
+		remainingJSON, err := json.Marshal(remaining)
+
+		if err != nil {
+			return false, err
+		}
+
+		if err := user.SetMeta(config.USER_META_TWO_FACTOR_RECOVERY_CODES, string(remainingJSON)); err != nil {
+			return false, err
+		}
+
+		if err := app.GetUserStore().UserUpdate(ctx, user); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func userTwoFactorRecoveryCodeHashes(user userstore.UserInterface) []string {
+	hashes := []string{}
+
+	if user == nil {
+		return hashes
+	}
+
+	hashesJSON := user.GetMeta(config.USER_META_TWO_FACTOR_RECOVERY_CODES)
+
+	if hashesJSON == "" {
+		return hashes
+	}
+
+	if err := json.Unmarshal([]byte(hashesJSON), &hashes); err != nil {
+		return []string{}
+	}
+
+	return hashes
+}
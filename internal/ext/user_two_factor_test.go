@@ -0,0 +1,226 @@
+package ext
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"project/internal/config"
+	"project/internal/testutils"
+
+	"github.com/dracory/userstore"
+	"github.com/pquerna/otp/totp"
+)
+
+func TestUserTwoFactor_EnableVerifyDisable(t *testing.T) {
+	app := testutils.Setup(
+		testutils.WithUserStore(true),
+		testutils.WithVaultStore(true),
+		testutils.WithCustomStore(true),
+	)
+	ctx := context.Background()
+
+	user, err := UserCreateWithEmail(ctx, app, "user@example.com", userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatalf("UserCreateWithEmail failed: %v", err)
+	}
+
+	if UserTwoFactorEnabled(user) {
+		t.Fatal("expected two-factor authentication to be disabled for a new user")
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "Test", AccountName: "user@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes, err := UserTwoFactorEnable(ctx, app, user, key.Secret())
+	if err != nil {
+		t.Fatalf("UserTwoFactorEnable failed: %v", err)
+	}
+
+	if len(codes) != TWO_FACTOR_RECOVERY_CODE_COUNT {
+		t.Fatalf("expected %d recovery codes, got %d", TWO_FACTOR_RECOVERY_CODE_COUNT, len(codes))
+	}
+
+	user, err = app.GetUserStore().UserFindByID(ctx, user.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !UserTwoFactorEnabled(user) {
+		t.Fatal("expected two-factor authentication to be enabled")
+	}
+
+	if user.GetMeta(config.USER_META_TWO_FACTOR_SECRET) == key.Secret() {
+		t.Fatal("expected the secret to be stored in the vault, not on the user")
+	}
+
+	code, err := totp.GenerateCode(key.Secret(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if valid, err := UserTwoFactorVerify(ctx, app, user, code); err != nil || !valid {
+		t.Fatalf("expected the TOTP code to be valid, got %v, %v", valid, err)
+	}
+
+	if valid, _ := UserTwoFactorVerify(ctx, app, user, code); valid {
+		t.Fatal("expected a used TOTP code to be invalid")
+	}
+
+	if valid, _ := UserTwoFactorVerify(ctx, app, user, "000000-wrong"); valid {
+		t.Fatal("expected a wrong code to be invalid")
+	}
+
+	if err := UserTwoFactorDisable(ctx, app, user); err != nil {
+		t.Fatalf("UserTwoFactorDisable failed: %v", err)
+	}
+
+	if UserTwoFactorEnabled(user) {
+		t.Fatal("expected two-factor authentication to be disabled")
+	}
+
+	if UserTwoFactorRecoveryCodesRemaining(user) != 0 {
+		t.Fatal("expected the recovery codes to be removed")
+	}
+}
+
+func TestUserTwoFactor_RecoveryCodesAreSingleUse(t *testing.T) {
+	app := testutils.Setup(
+		testutils.WithUserStore(true),
+		testutils.WithVaultStore(true),
+		testutils.WithCustomStore(true),
+	)
+	ctx := context.Background()
+
+	user, err := UserCreateWithEmail(ctx, app, "user@example.com", userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatalf("UserCreateWithEmail failed: %v", err)
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "Test", AccountName: "user@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes, err := UserTwoFactorEnable(ctx, app, user, key.Secret())
+	if err != nil {
+		t.Fatalf("UserTwoFactorEnable failed: %v", err)
+	}
+
+	if valid, err := UserTwoFactorVerify(ctx, app, user, codes[3]); err != nil || !valid {
+		t.Fatalf("expected the recovery code to be valid, got %v, %v", valid, err)
+	}
+
+	if UserTwoFactorRecoveryCodesRemaining(user) != TWO_FACTOR_RECOVERY_CODE_COUNT-1 {
+		t.Fatalf("expected %d recovery codes left, got %d", TWO_FACTOR_RECOVERY_CODE_COUNT-1, UserTwoFactorRecoveryCodesRemaining(user))
+	}
+
+	if valid, _ := UserTwoFactorVerify(ctx, app, user, codes[3]); valid {
+		t.Fatal("expected a used recovery code to be invalid")
+	}
+
+	regenerated, err := UserTwoFactorRecoveryCodesRegenerate(ctx, app, user)
+	if err != nil {
+		t.Fatalf("UserTwoFactorRecoveryCodesRegenerate failed: %v", err)
+	}
+
+	if valid, _ := UserTwoFactorVerify(ctx, app, user, codes[0]); valid {
+		t.Fatal("expected the old recovery codes to be replaced")
+	}
+
+	if valid, _ := UserTwoFactorVerify(ctx, app, user, regenerated[0]); !valid {
+		t.Fatal("expected the new recovery code to be valid")
+	}
+}
+
+func TestUserTwoFactor_ConcurrentVerifyAcceptsCodeOnce(t *testing.T) {
+	app := testutils.Setup(
+		testutils.WithUserStore(true),
+		testutils.WithVaultStore(true),
+		testutils.WithCustomStore(true),
+	)
+	ctx := context.Background()
+
+	user, err := UserCreateWithEmail(ctx, app, "user@example.com", userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatalf("UserCreateWithEmail failed: %v", err)
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "Test", AccountName: "user@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codes, err := UserTwoFactorEnable(ctx, app, user, key.Secret())
+	if err != nil {
+		t.Fatalf("UserTwoFactorEnable failed: %v", err)
+	}
+
+	code, err := totp.GenerateCode(key.Secret(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		code string
+	}{
+		{name: "totp code", code: code},
+		{name: "recovery code", code: codes[0]},
+	} {
+		var wins atomic.Int32
+		var wg sync.WaitGroup
+
+		for range 10 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				// Each request loads the user, as the login does
+				requestUser, err := app.GetUserStore().UserFindByID(ctx, user.GetID())
+				if err != nil || requestUser == nil {
+					return
+				}
+
+				if valid, err := UserTwoFactorVerify(ctx, app, requestUser, tc.code); err == nil && valid {
+					wins.Add(1)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		if wins.Load() != 1 {
+			t.Errorf("%s: expected exactly one request to be accepted, got %d", tc.name, wins.Load())
+		}
+	}
+}
+
+func TestUserTwoFactorTotpStep(t *testing.T) {
+	secret := "JBSWY3DPEHPK3PXP"
+	now := time.Unix(1_700_000_000, 0)
+	current := now.Unix() / twoFactorTotpPeriod
+
+	previous, err := totp.GenerateCode(secret, now.Add(-twoFactorTotpPeriod*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if step, found := userTwoFactorTotpStep(previous, secret, now); !found || step != current-1 {
+		t.Fatalf("expected step %d, got %d, %v", current-1, step, found)
+	}
+
+	old, err := totp.GenerateCode(secret, now.Add(-2*twoFactorTotpPeriod*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found := userTwoFactorTotpStep(old, secret, now); found {
+		t.Fatal("expected a code outside the skew to be invalid")
+	}
+}
@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"project/internal/customrecords"
+
+	"github.com/dracory/cachestore"
+	"github.com/dracory/customstore"
+)
+
+const AUTH_TOKEN_CACHE_KEY_PREFIX = "auth_token_"
+
+// RECORD_TYPE_AUTH_TOKEN_CONSUMED is the record type of the claims of the
+// consumed tokens in the custom store
+const RECORD_TYPE_AUTH_TOKEN_CONSUMED = "auth_token_consumed"
+
+// AuthTokenCreate creates a random token sent to the user by email (e.g.
+// to reset a password), and stores the value (e.g. the user ID) for it in
+// the cache store until it expires.
+//
+// Only the hash of the token is used as cache key, so the cache store
+// does not hold usable tokens. A token is only valid for its purpose.
+func AuthTokenCreate(cacheStore cachestore.StoreInterface, purpose string, value string, expiresSeconds int64) (string, error) {
+	if cacheStore == nil {
+		return "", errors.New("cache store is nil")
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	token := hex.EncodeToString(b)
+
+	if err := cacheStore.Set(authTokenCacheKey(purpose, token), value, expiresSeconds); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// AuthTokenFind returns the value stored for the token, or an empty string
+// if the token is unknown or has expired
+func AuthTokenFind(cacheStore cachestore.StoreInterface, purpose string, token string) (string, error) {
+	if cacheStore == nil {
+		return "", errors.New("cache store is nil")
+	}
+
+	if token == "" {
+		return "", nil
+	}
+
+	return cacheStore.Get(authTokenCacheKey(purpose, token), "")
+}
+
+// AuthTokenDelete removes the token, once used
+func AuthTokenDelete(cacheStore cachestore.StoreInterface, purpose string, token string) error {
+	if cacheStore == nil {
+		return errors.New("cache store is nil")
+	}
+
+	return cacheStore.Remove(authTokenCacheKey(purpose, token))
+}
+
+// AuthTokenConsume returns the value stored for the token, like
+// AuthTokenFind, and removes the token so it cannot be used again.
+//
+// The token is claimed by its hash in the custom store, so of the
+// concurrent requests with the same token only one gets the value
+func AuthTokenConsume(cacheStore cachestore.StoreInterface, customStore customstore.StoreInterface, purpose string, token string) (string, error) {
+	if customStore == nil {
+		return "", errors.New("custom store is nil")
+	}
+
+	value, err := AuthTokenFind(cacheStore, purpose, token)
+
+	if err != nil || value == "" {
+		return "", err
+	}
+
+	claimed, err := customrecords.Claim(customStore, RECORD_TYPE_AUTH_TOKEN_CONSUMED, authTokenCacheKey(purpose, token), "")
+
+	if err != nil || !claimed {
+		return "", err
+	}
+
+	if err := AuthTokenDelete(cacheStore, purpose, token); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+func authTokenCacheKey(purpose string, token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return AUTH_TOKEN_CACHE_KEY_PREFIX + purpose + "_" + hex.EncodeToString(hash[:])
+}
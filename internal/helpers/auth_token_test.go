@@ -0,0 +1,115 @@
+package helpers
+
+import (
+	"project/internal/testutils"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAuthToken_CreateFindDelete(t *testing.T) {
+	app := testutils.Setup(testutils.WithCacheStore(true))
+
+	token, err := AuthTokenCreate(app.GetCacheStore(), "password_reset", "user-1", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(token) != 64 {
+		t.Fatalf("expected a 64 characters token, got %q", token)
+	}
+
+	value, err := AuthTokenFind(app.GetCacheStore(), "password_reset", token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "user-1" {
+		t.Fatalf("expected user-1, got %q", value)
+	}
+
+	// Tokens are only valid for their purpose
+	value, _ = AuthTokenFind(app.GetCacheStore(), "email_verify", token)
+	if value != "" {
+		t.Fatalf("expected the token not to be valid for another purpose, got %q", value)
+	}
+
+	// Only the hash of the token is stored
+	stored, _ := app.GetCacheStore().Get(AUTH_TOKEN_CACHE_KEY_PREFIX+"password_reset_"+token, "")
+	if stored != "" {
+		t.Fatal("expected the token not to be stored in plain text")
+	}
+
+	if err := AuthTokenDelete(app.GetCacheStore(), "password_reset", token); err != nil {
+		t.Fatal(err)
+	}
+
+	value, _ = AuthTokenFind(app.GetCacheStore(), "password_reset", token)
+	if value != "" {
+		t.Fatalf("expected the deleted token not to be found, got %q", value)
+	}
+}
+
+func TestAuthToken_Consume(t *testing.T) {
+	app := testutils.Setup(testutils.WithCacheStore(true), testutils.WithCustomStore(true))
+
+	token, err := AuthTokenCreate(app.GetCacheStore(), "magic_link", "user@example.com", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := AuthTokenConsume(app.GetCacheStore(), app.GetCustomStore(), "magic_link", token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "user@example.com" {
+		t.Fatalf("expected user@example.com, got %q", value)
+	}
+
+	value, err = AuthTokenConsume(app.GetCacheStore(), app.GetCustomStore(), "magic_link", token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "" {
+		t.Fatalf("expected the token to be single use, got %q", value)
+	}
+}
+
+func TestAuthToken_ConsumeConcurrent(t *testing.T) {
+	app := testutils.Setup(testutils.WithCacheStore(true), testutils.WithCustomStore(true))
+
+	token, err := AuthTokenCreate(app.GetCacheStore(), "magic_link", "user@example.com", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if value, err := AuthTokenConsume(app.GetCacheStore(), app.GetCustomStore(), "magic_link", token); err == nil && value != "" {
+				wins.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if wins.Load() != 1 {
+		t.Errorf("expected exactly one request to consume the token, got %d", wins.Load())
+	}
+}
+
+func TestAuthToken_NilCacheStore(t *testing.T) {
+	if _, err := AuthTokenCreate(nil, "magic_link", "value", 60); err == nil {
+		t.Fatal("expected error when cache store is nil")
+	}
+
+	if _, err := AuthTokenFind(nil, "magic_link", "token"); err == nil {
+		t.Fatal("expected error when cache store is nil")
+	}
+}
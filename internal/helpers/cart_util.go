@@ -1,22 +1,98 @@
 package helpers
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
-	"fmt"
 	"net/http"
-
-	"github.com/dracory/req"
+	"strings"
 )
 
-// GenerateCartCacheKey generates a cache key for guest cart based on IP and user agent
-func GenerateCartCacheKey(r *http.Request) string {
-	ip := req.GetIP(r)
-	userAgent := r.UserAgent()
+// CART_COOKIE_NAME is the name of the cookie holding the signed guest cart ID
+const CART_COOKIE_NAME = "cart_id"
+
+// CART_COOKIE_MAX_AGE is the lifetime of the guest cart cookie in seconds (30 days)
+const CART_COOKIE_MAX_AGE = 30 * 24 * 60 * 60
+
+// CartIDNew generates a new random guest cart ID
+func CartIDNew() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+// CartIDFromRequest returns the guest cart ID from the signed cart cookie.
+// An empty string is returned when the cookie is missing or its signature
+// does not match the secret.
+func CartIDFromRequest(r *http.Request, secret string) string {
+	if r == nil || secret == "" {
+		return ""
+	}
+
+	cookie, err := r.Cookie(CART_COOKIE_NAME)
+	if err != nil {
+		return ""
+	}
+
+	cartID, signature, found := strings.Cut(cookie.Value, ".")
+	if !found || cartID == "" {
+		return ""
+	}
+
+	expected := cartIDSign(cartID, secret)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ""
+	}
+
+	return cartID
+}
+
+// CartCookieSet writes the signed, HttpOnly guest cart cookie
+func CartCookieSet(w http.ResponseWriter, cartID string, secret string, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CART_COOKIE_NAME,
+		Value:    cartID + "." + cartIDSign(cartID, secret),
+		Path:     "/",
+		MaxAge:   CART_COOKIE_MAX_AGE,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// CartCookieRemove expires the guest cart cookie
+func CartCookieRemove(w http.ResponseWriter, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CART_COOKIE_NAME,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// CartCacheKey returns the cache key for the guest cart with the given ID
+func CartCacheKey(cartID string) string {
+	return "cart_" + cartID
+}
+
+// cartCookieKeyLabel derives the key of the cart cookie from the secret, so
+// the secret (shared with the CSRF tokens) never signs the cookie directly
+const cartCookieKeyLabel = "cart-cookie"
 
-	// Hash the user agent for a shorter key
-	hash := sha256.Sum256([]byte(userAgent))
-	userAgentHash := hex.EncodeToString(hash[:16]) // Use 16 bytes for better collision resistance
+// cartIDSign returns the hex encoded HMAC-SHA256 signature of the cart ID,
+// keyed with a sub-key derived from the secret
+func cartIDSign(cartID string, secret string) string {
+	keyMac := hmac.New(sha256.New, []byte(secret))
+	keyMac.Write([]byte(cartCookieKeyLabel))
 
-	return fmt.Sprintf("cart_%s_%s", ip, userAgentHash)
+	mac := hmac.New(sha256.New, keyMac.Sum(nil))
+	mac.Write([]byte(cartID))
+	return hex.EncodeToString(mac.Sum(nil))
 }
@@ -0,0 +1,133 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCartIDNew(t *testing.T) {
+	first, err := CartIDNew()
+	if err != nil {
+		t.Fatalf("CartIDNew() unexpected error: %v", err)
+	}
+	second, err := CartIDNew()
+	if err != nil {
+		t.Fatalf("CartIDNew() unexpected error: %v", err)
+	}
+
+	if len(first) != 32 {
+		t.Errorf("CartIDNew() length = %d, want 32", len(first))
+	}
+	if first == second {
+		t.Error("CartIDNew() expected unique IDs")
+	}
+}
+
+func TestCartCookieSet_RoundTrip(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	CartCookieSet(recorder, "abc123", "secret", true)
+
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected 1 cookie, got %d", len(cookies))
+	}
+
+	cookie := cookies[0]
+	if cookie.Name != CART_COOKIE_NAME {
+		t.Errorf("cookie name = %q, want %q", cookie.Name, CART_COOKIE_NAME)
+	}
+	if !cookie.HttpOnly {
+		t.Error("cookie must be HttpOnly")
+	}
+	if !cookie.Secure {
+		t.Error("cookie must be Secure when requested")
+	}
+	if cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("cookie SameSite = %v, want Lax", cookie.SameSite)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookie)
+
+	if got := CartIDFromRequest(req, "secret"); got != "abc123" {
+		t.Errorf("CartIDFromRequest() = %q, want %q", got, "abc123")
+	}
+}
+
+func TestCartIDFromRequest_Invalid(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	CartCookieSet(recorder, "abc123", "secret", false)
+	signed := recorder.Result().Cookies()[0].Value
+
+	tests := []struct {
+		name   string
+		value  string
+		secret string
+	}{
+		{"wrong secret", signed, "other"},
+		{"tampered id", "xyz789" + signed[strings.Index(signed, "."):], "secret"},
+		{"unsigned", "abc123", "secret"},
+		{"empty id", "." + signed[strings.Index(signed, ".")+1:], "secret"},
+		{"empty secret", signed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.AddCookie(&http.Cookie{Name: CART_COOKIE_NAME, Value: tt.value})
+
+			if got := CartIDFromRequest(req, tt.secret); got != "" {
+				t.Errorf("CartIDFromRequest() = %q, want empty", got)
+			}
+		})
+	}
+}
+
+func TestCartCookieSet_DerivedKey(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	CartCookieSet(recorder, "abc123", "secret", false)
+	signed := recorder.Result().Cookies()[0].Value
+
+	// The secret is shared with the CSRF tokens, so it must not sign the
+	// cart ID directly
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("abc123"))
+
+	if signed == "abc123."+hex.EncodeToString(mac.Sum(nil)) {
+		t.Error("expected the cart cookie to be signed with a key derived from the secret")
+	}
+}
+
+func TestCartIDFromRequest_NoCookie(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if got := CartIDFromRequest(req, "secret"); got != "" {
+		t.Errorf("CartIDFromRequest() = %q, want empty", got)
+	}
+	if got := CartIDFromRequest(nil, "secret"); got != "" {
+		t.Errorf("CartIDFromRequest(nil) = %q, want empty", got)
+	}
+}
+
+func TestCartCookieRemove(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	CartCookieRemove(recorder, false)
+
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected 1 cookie, got %d", len(cookies))
+	}
+	if cookies[0].MaxAge >= 0 {
+		t.Errorf("cookie MaxAge = %d, want negative", cookies[0].MaxAge)
+	}
+}
+
+func TestCartCacheKey(t *testing.T) {
+	if got := CartCacheKey("abc"); got != "cart_abc" {
+		t.Errorf("CartCacheKey() = %q, want %q", got, "cart_abc")
+	}
+}
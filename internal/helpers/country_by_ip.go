@@ -0,0 +1,83 @@
+package helpers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// The country codes returned when the country of an IP address is not
+// found
+const (
+	COUNTRY_UNKNOWN = "UN"
+	COUNTRY_ERROR   = "ER"
+)
+
+// CountryByIP looks up the ISO country code of the IP address with an
+// ip2c compatible endpoint (e.g. "https://ip2c.org/").
+//
+// Returns COUNTRY_UNKNOWN for local and private IP addresses, or if the
+// country is not known, and COUNTRY_ERROR if the lookup failed. The
+// timeout is set by the client, or the context.
+func CountryByIP(ctx context.Context, client *http.Client, endpoint string, ip string) string {
+	if ip == "" || ip == "127.0.0.1" {
+		return COUNTRY_UNKNOWN
+	}
+
+	if parsed := net.ParseIP(ip); parsed != nil && (parsed.IsLoopback() || parsed.IsPrivate()) {
+		return COUNTRY_UNKNOWN
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+ip, nil)
+	if err != nil {
+		log.Printf("Creating geo lookup request failed: %s", err)
+		return COUNTRY_ERROR
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+
+	if err != nil {
+		log.Printf("Request Failed: %s", err)
+		return COUNTRY_ERROR
+	}
+
+	if resp == nil {
+		return COUNTRY_UNKNOWN
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Closing response body failed: %s", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Geo lookup returned status code %d", resp.StatusCode)
+		return COUNTRY_ERROR
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Printf("Reading geo lookup response failed: %s", err)
+		return COUNTRY_ERROR
+	}
+
+	// The response is "status;ISO2;ISO3;name" (e.g. "1;US;USA;United States")
+	parts := strings.Split(string(body), ";")
+	if len(parts) > 2 {
+		code := strings.TrimSpace(parts[1])
+		if code == "" {
+			return COUNTRY_UNKNOWN
+		}
+		return code
+	}
+
+	return COUNTRY_UNKNOWN
+}
@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCountryByIP_LocalAddresses(t *testing.T) {
+	for _, ip := range []string{"", "127.0.0.1", "::1", "10.0.0.1", "192.168.1.1"} {
+		if country := CountryByIP(context.Background(), nil, "http://invalid.invalid/", ip); country != COUNTRY_UNKNOWN {
+			t.Errorf("CountryByIP(%q) = %q, want %q", ip, country, COUNTRY_UNKNOWN)
+		}
+	}
+}
+
+func TestCountryByIP(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		body     string
+		expected string
+	}{
+		{"found", http.StatusOK, "1;US;USA;United States", "US"},
+		{"empty country", http.StatusOK, "1;;USA;", COUNTRY_UNKNOWN},
+		{"malformed", http.StatusOK, "0", COUNTRY_UNKNOWN},
+		{"error status", http.StatusInternalServerError, "", COUNTRY_ERROR},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/8.8.8.8" {
+					t.Errorf("path = %q, want /8.8.8.8", r.URL.Path)
+				}
+				w.WriteHeader(c.status)
+				_, _ = w.Write([]byte(c.body))
+			}))
+			defer server.Close()
+
+			if country := CountryByIP(context.Background(), server.Client(), server.URL+"/", "8.8.8.8"); country != c.expected {
+				t.Errorf("CountryByIP() = %q, want %q", country, c.expected)
+			}
+		})
+	}
+}
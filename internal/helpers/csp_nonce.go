@@ -0,0 +1,113 @@
+package helpers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"project/internal/config"
+	"regexp"
+	"strings"
+
+	"github.com/dracory/hb"
+)
+
+// cspNonceTagRegex matches the opening script and style tags
+var cspNonceTagRegex = regexp.MustCompile(`(?i)<(script|style)(\s[^>]*)?>`)
+
+// cspNoncePlaceholder is stamped instead of the nonce on the HTML rendered
+// before the request, e.g. the cached CMS templates, and replaced with the
+// nonce of the request by CSPNoncePlaceholderReplace. It is random, so the
+// content rendered into the HTML cannot carry it
+var cspNoncePlaceholder = cspNoncePlaceholderNew()
+
+func cspNoncePlaceholderNew() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return "csp-nonce-" + hex.EncodeToString(b)
+}
+
+// GetCSPNonce returns the Content Security Policy nonce of the request,
+// set in its context by the security headers middleware, or an empty string
+func GetCSPNonce(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	nonce, _ := ctx.Value(config.CSPNonceContextKey{}).(string)
+
+	return nonce
+}
+
+// CSPNonceStampTag stamps the nonce of the request on a script or style
+// tag, so that the browser runs it.
+//
+// Only stamp the tags created by the application, never the ones coming
+// from the input of the users.
+//
+// Example:
+//
+//	helpers.CSPNonceStampTag(r.Context(), hb.Script(`console.log("hello")`))
+func CSPNonceStampTag(ctx context.Context, tag hb.TagInterface) hb.TagInterface {
+	nonce := GetCSPNonce(ctx)
+
+	if nonce == "" {
+		return tag
+	}
+
+	if t, ok := tag.(*hb.Tag); ok {
+		return t.Attr("nonce", nonce)
+	}
+
+	return tag
+}
+
+// CSPNonceStampHTML stamps the nonce of the request on all the script and
+// style tags of the HTML, which do not have a nonce yet.
+//
+// Only stamp the HTML created by the application (i.e. the layouts),
+// never the one coming from the input of the users, or the content of the
+// CMS pages (see CSPNoncePlaceholderStampHTML for the CMS templates).
+func CSPNonceStampHTML(ctx context.Context, html string) string {
+	nonce := GetCSPNonce(ctx)
+
+	if nonce == "" {
+		return html
+	}
+
+	return cspNonceStamp(html, nonce)
+}
+
+// CSPNoncePlaceholderStampHTML stamps a placeholder of the nonce on all the
+// script and style tags of the HTML, like CSPNonceStampHTML, for the HTML
+// rendered before the request (e.g. the CMS templates). The placeholder is
+// replaced with the nonce of the request by CSPNoncePlaceholderReplace.
+func CSPNoncePlaceholderStampHTML(html string) string {
+	return cspNonceStamp(html, cspNoncePlaceholder)
+}
+
+// CSPNoncePlaceholderReplace replaces the placeholder of the nonce stamped
+// by CSPNoncePlaceholderStampHTML with the nonce of the request, or removes
+// it when the request has no nonce
+func CSPNoncePlaceholderReplace(ctx context.Context, html string) string {
+	nonce := GetCSPNonce(ctx)
+
+	if nonce == "" {
+		return strings.ReplaceAll(html, ` nonce="`+cspNoncePlaceholder+`"`, "")
+	}
+
+	return strings.ReplaceAll(html, cspNoncePlaceholder, nonce)
+}
+
+// cspNonceStamp stamps the nonce on the script and style tags of the HTML,
+// which do not have a nonce yet
+func cspNonceStamp(html string, nonce string) string {
+	return cspNonceTagRegex.ReplaceAllStringFunc(html, func(tag string) string {
+		if strings.Contains(strings.ToLower(tag), "nonce=") {
+			return tag
+		}
+
+		matches := cspNonceTagRegex.FindStringSubmatch(tag)
+
+		return "<" + matches[1] + ` nonce="` + nonce + `"` + matches[2] + ">"
+	})
+}
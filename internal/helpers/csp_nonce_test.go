@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"context"
+	"project/internal/config"
+	"strings"
+	"testing"
+
+	"github.com/dracory/hb"
+)
+
+// newCSPNonceContext returns a context with the nonce
+func newCSPNonceContext(nonce string) context.Context {
+	return context.WithValue(context.Background(), config.CSPNonceContextKey{}, nonce)
+}
+
+func TestGetCSPNonce(t *testing.T) {
+	if nonce := GetCSPNonce(context.Background()); nonce != "" {
+		t.Errorf("GetCSPNonce() without context = %q, want empty", nonce)
+	}
+
+	if nonce := GetCSPNonce(newCSPNonceContext("abc123")); nonce != "abc123" {
+		t.Errorf("GetCSPNonce() = %q, want abc123", nonce)
+	}
+}
+
+func TestCSPNonceStampTag(t *testing.T) {
+	ctx := newCSPNonceContext("abc123")
+
+	html := CSPNonceStampTag(ctx, hb.Script(`alert(1)`)).ToHTML()
+	if html != `<script nonce="abc123">alert(1)</script>` {
+		t.Errorf("CSPNonceStampTag() = %q", html)
+	}
+
+	html = CSPNonceStampTag(context.Background(), hb.Script(`alert(1)`)).ToHTML()
+	if html != `<script>alert(1)</script>` {
+		t.Errorf("CSPNonceStampTag() without nonce = %q", html)
+	}
+}
+
+func TestCSPNonceStampHTML(t *testing.T) {
+	ctx := newCSPNonceContext("abc123")
+
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "script",
+			html: `<script>alert(1)</script>`,
+			want: `<script nonce="abc123">alert(1)</script>`,
+		},
+		{
+			name: "script with attributes",
+			html: `<SCRIPT src="/app.js" defer></SCRIPT>`,
+			want: `<SCRIPT nonce="abc123" src="/app.js" defer></SCRIPT>`,
+		},
+		{
+			name: "style",
+			html: `<style>body{}</style>`,
+			want: `<style nonce="abc123">body{}</style>`,
+		},
+		{
+			name: "already stamped",
+			html: `<script nonce="other">alert(1)</script>`,
+			want: `<script nonce="other">alert(1)</script>`,
+		},
+		{
+			name: "other tags",
+			html: `<scripts><stylesheet><div>`,
+			want: `<scripts><stylesheet><div>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CSPNonceStampHTML(ctx, test.html); got != test.want {
+				t.Errorf("CSPNonceStampHTML() = %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	if got := CSPNonceStampHTML(context.Background(), `<script></script>`); got != `<script></script>` {
+		t.Errorf("CSPNonceStampHTML() without nonce = %q", got)
+	}
+}
+
+func TestCSPNoncePlaceholder(t *testing.T) {
+	template := CSPNoncePlaceholderStampHTML(`<script src="/app.js"></script>`)
+
+	if strings.Contains(template, "abc123") || !strings.Contains(template, "nonce=") {
+		t.Fatalf("expected the placeholder to be stamped, got %q", template)
+	}
+
+	// The content rendered into the template after the stamping is left
+	// without the nonce
+	html := template + `<script>alert(1)</script>`
+
+	want := `<script nonce="abc123" src="/app.js"></script><script>alert(1)</script>`
+	if got := CSPNoncePlaceholderReplace(newCSPNonceContext("abc123"), html); got != want {
+		t.Errorf("CSPNoncePlaceholderReplace() = %q, want %q", got, want)
+	}
+
+	want = `<script src="/app.js"></script><script>alert(1)</script>`
+	if got := CSPNoncePlaceholderReplace(context.Background(), html); got != want {
+		t.Errorf("CSPNoncePlaceholderReplace() without a nonce = %q, want %q", got, want)
+	}
+}
@@ -2,7 +2,9 @@ package helpers
 
 import (
 	"net/http"
+	"project/internal/apitoken"
 	"project/internal/config"
+	"project/internal/mcp"
 
 	"github.com/dracory/userstore"
 )
@@ -36,3 +38,25 @@ func GetAPIAuthUser(r *http.Request) userstore.UserInterface {
 	user := value.(userstore.UserInterface)
 	return user
 }
+
+// GetAPIAuthToken returns the personal API token the API request is
+// authenticated with
+func GetAPIAuthToken(r *http.Request) *apitoken.Token {
+	if r == nil {
+		return nil
+	}
+
+	token, _ := r.Context().Value(config.APIAuthenticatedTokenContextKey{}).(*apitoken.Token)
+	return token
+}
+
+// GetMCPClient returns the MCP client the MCP request is authenticated
+// with
+func GetMCPClient(r *http.Request) *mcp.Client {
+	if r == nil {
+		return nil
+	}
+
+	client, _ := r.Context().Value(config.MCPAuthenticatedClientContextKey{}).(*mcp.Client)
+	return client
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"project/internal/apitoken"
 	"project/internal/config"
+	"project/internal/mcp"
 	"project/internal/testutils"
 	"testing"
 
@@ -110,3 +112,41 @@ func TestGetAPIAuthUser_WithValidUser(t *testing.T) {
 		t.Errorf("GetAPIAuthUser ID = %v, want %v", result.GetID(), user.GetID())
 	}
 }
+
+// TestGetAPIAuthToken tests retrieving the API token from context
+func TestGetAPIAuthToken(t *testing.T) {
+	if GetAPIAuthToken(nil) != nil {
+		t.Error("GetAPIAuthToken(nil) should return nil")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+	if GetAPIAuthToken(req) != nil {
+		t.Error("GetAPIAuthToken(req) without context should return nil")
+	}
+
+	token := &apitoken.Token{ID: "token_1"}
+	req = req.WithContext(context.WithValue(req.Context(), config.APIAuthenticatedTokenContextKey{}, token))
+
+	if result := GetAPIAuthToken(req); result == nil || result.ID != "token_1" {
+		t.Errorf("GetAPIAuthToken(req) = %v, want the token", result)
+	}
+}
+
+// TestGetMCPClient tests retrieving the MCP client from context
+func TestGetMCPClient(t *testing.T) {
+	if GetMCPClient(nil) != nil {
+		t.Error("GetMCPClient(nil) should return nil")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	if GetMCPClient(req) != nil {
+		t.Error("GetMCPClient(req) without context should return nil")
+	}
+
+	client := &mcp.Client{ID: "client_1"}
+	req = req.WithContext(context.WithValue(req.Context(), config.MCPAuthenticatedClientContextKey{}, client))
+
+	if result := GetMCPClient(req); result == nil || result.ID != "client_1" {
+		t.Errorf("GetMCPClient(req) = %v, want the client", result)
+	}
+}
@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dracory/shopstore"
+	"github.com/samber/lo"
+)
+
+// paidOrderStatuses lists the order statuses reached only after a payment
+// has been confirmed
+var paidOrderStatuses = []string{
+	shopstore.ORDER_STATUS_AWAITING_FULFILLMENT,
+	shopstore.ORDER_STATUS_AWAITING_SHIPMENT,
+	shopstore.ORDER_STATUS_AWAITING_PICKUP,
+	shopstore.ORDER_STATUS_SHIPPED,
+	shopstore.ORDER_STATUS_COMPLETED,
+}
+
+// IsOrderPaid returns true if the payment for the order has been confirmed
+func IsOrderPaid(order shopstore.OrderInterface) bool {
+	if order == nil {
+		return false
+	}
+
+	return lo.Contains(paidOrderStatuses, order.GetStatus())
+}
+
+// OrderMarkAsPaid moves an order awaiting payment to awaiting fulfillment.
+//
+// The operation is idempotent, orders which are already paid are left
+// untouched, so it is safe to call it both from the payment return URL
+// and from the payment provider webhook.
+//
+// Parameters:
+// - ctx: the context
+// - shopStore: the shop store
+// - order: the order to mark as paid
+//
+// Returns:
+// - error: if the order cannot be marked as paid
+func OrderMarkAsPaid(ctx context.Context, shopStore shopstore.StoreInterface, order shopstore.OrderInterface) error {
+	if shopStore == nil {
+		return errors.New("shop store is nil")
+	}
+
+	if order == nil {
+		return errors.New("order is nil")
+	}
+
+	if IsOrderPaid(order) {
+		return nil
+	}
+
+	if order.GetStatus() == shopstore.ORDER_STATUS_CANCELLED {
+		return errors.New("order is cancelled")
+	}
+
+	order.SetStatus(shopstore.ORDER_STATUS_AWAITING_FULFILLMENT)
+
+	return shopStore.OrderUpdate(ctx, order)
+}
@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"errors"
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/discount"
+
+	"github.com/dracory/shopstore"
+)
+
+// OrderDiscountRedeem records the use of the discount code applied to a paid
+// order, so that it counts towards the usage limits of the code.
+//
+// The operation is idempotent, the redemption is keyed by the order and the
+// discount, so it is safe to call it both from the payment return URL and
+// from the payment provider webhook, even at the same moment.
+//
+// Parameters:
+// - app: the app interface
+// - order: the paid order
+//
+// Returns:
+// - error: if the redemption cannot be recorded
+func OrderDiscountRedeem(app app.AppInterface, order shopstore.OrderInterface) error {
+	if order == nil {
+		return errors.New("order is nil")
+	}
+
+	discountID := order.GetMeta(config.ORDER_META_DISCOUNT_ID)
+
+	if discountID == "" {
+		return nil
+	}
+
+	discountStore, err := discount.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return err
+	}
+
+	return discountStore.RedemptionCreate(discountID, order.GetCustomerID(), order.GetID())
+}
+
+// OrderDiscountRelease releases the use of the discount code held by an
+// unpaid order since the checkout (see discount.StoreInterface UsageClaim),
+// e.g. when its payment is cancelled. The use is claimed again if the buyer
+// checks out the order again. The use of a paid order is kept.
+//
+// Parameters:
+// - app: the app interface
+// - order: the unpaid order
+//
+// Returns:
+// - error: if the use cannot be released
+func OrderDiscountRelease(app app.AppInterface, order shopstore.OrderInterface) error {
+	if order == nil {
+		return errors.New("order is nil")
+	}
+
+	if order.GetMeta(config.ORDER_META_DISCOUNT_ID) == "" {
+		return nil
+	}
+
+	discountStore, err := discount.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return err
+	}
+
+	return discountStore.UsageRelease(order.GetID())
+}
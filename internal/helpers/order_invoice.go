@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"errors"
+	"project/internal/app"
+	"project/internal/invoice"
+	"time"
+
+	"github.com/dracory/shopstore"
+)
+
+// OrderInvoiceIssue issues the invoice of a paid order, numbered in the
+// order the payments are confirmed, and dated with the time of the payment.
+//
+// The operation is idempotent, the order is claimed before its invoice is
+// issued, so it is safe to call it both from the payment return URL and
+// from the payment provider webhook, even at the same moment.
+//
+// Parameters:
+// - app: the app interface
+// - order: the paid order
+//
+// Returns:
+// - error: if the invoice cannot be issued
+func OrderInvoiceIssue(app app.AppInterface, order shopstore.OrderInterface) error {
+	if order == nil {
+		return errors.New("order is nil")
+	}
+
+	// The invoices are held in the custom store
+	if app.GetCustomStore() == nil {
+		return nil
+	}
+
+	invoiceStore, err := invoice.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return err
+	}
+
+	_, err = invoiceStore.InvoiceIssue(order.GetID(), time.Now())
+
+	// The concurrent call is issuing the invoice
+	if errors.Is(err, invoice.ErrIssuing) {
+		return nil
+	}
+
+	return err
+}
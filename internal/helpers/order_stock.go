@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"project/internal/app"
+	"project/internal/inventory"
+
+	"github.com/dracory/shopstore"
+)
+
+// OrderStockCommit decrements the stock of the products reserved for a
+// paid order.
+//
+// Orders placed without stock tracking have no reservations, and are left
+// untouched. The operation is idempotent, the commit of each reservation is
+// claimed first, so it is safe to call it both from the payment return URL
+// and from the payment provider webhook, even at the same moment.
+//
+// Parameters:
+// - ctx: the context
+// - app: the app interface
+// - order: the paid order
+//
+// Returns:
+// - error: if the stock cannot be updated
+func OrderStockCommit(ctx context.Context, app app.AppInterface, order shopstore.OrderInterface) error {
+	if order == nil {
+		return errors.New("order is nil")
+	}
+
+	// The reservations are held in the custom store
+	if app.GetCustomStore() == nil {
+		return nil
+	}
+
+	reservationStore, err := inventory.NewStore(app.GetCustomStore())
+
+	if err != nil {
+		return err
+	}
+
+	return inventory.Commit(ctx, app.GetShopStore(), reservationStore, order.GetID())
+}
@@ -0,0 +1,31 @@
+package helpers
+
+import (
+	"project/internal/config"
+	"strconv"
+	"strings"
+
+	"github.com/dracory/shopstore"
+)
+
+// ProductWeight returns the weight of one unit of the product in grams, as
+// set in the product metadata. Products without a valid weight weigh zero.
+//
+// Parameters:
+// - product: the product
+//
+// Returns:
+// - int64: the weight in grams
+func ProductWeight(product shopstore.ProductInterface) int64 {
+	if product == nil {
+		return 0
+	}
+
+	weight, err := strconv.ParseInt(strings.TrimSpace(product.GetMeta(config.PRODUCT_META_WEIGHT)), 10, 64)
+
+	if err != nil || weight < 0 {
+		return 0
+	}
+
+	return weight
+}
@@ -0,0 +1,39 @@
+package helpers
+
+import (
+	"project/internal/config"
+	"testing"
+
+	"github.com/dracory/shopstore"
+)
+
+func TestProductWeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		weight string
+		want   int64
+	}{
+		{"grams", "1500", 1500},
+		{"spaces", " 250 ", 250},
+		{"not set", "", 0},
+		{"not a number", "heavy", 0},
+		{"negative", "-5", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := shopstore.NewProduct()
+			if err := product.SetMeta(config.PRODUCT_META_WEIGHT, tt.weight); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := ProductWeight(product); got != tt.want {
+				t.Errorf("ProductWeight() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if got := ProductWeight(nil); got != 0 {
+		t.Errorf("ProductWeight(nil) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,105 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ServeContentOptions are the options of ServeContent
+type ServeContentOptions struct {
+	// Name is the name of the file, e.g. "report.pdf", used for the
+	// Content-Type when not set, and for the name of the downloads
+	Name string
+
+	// ContentType is the Content-Type header, detected from the name (or
+	// the content) when empty
+	ContentType string
+
+	// CacheControl is the Cache-Control header, not set when empty
+	CacheControl string
+
+	// ModTime is the time the file was last modified, sent as the
+	// Last-Modified header when set
+	ModTime time.Time
+
+	// Attachment serves the file as a download (Content-Disposition)
+	Attachment bool
+
+	// ETag is the ETag header, e.g. FileETag of a stored file. ServeContent
+	// uses the hash of the content when empty
+	ETag string
+}
+
+// ServeContent writes the content of a file to the response, with a strong
+// ETag (the hash of the content, unless options.ETag is set), and answers
+// the conditional requests (If-None-Match, If-Modified-Since, If-Range) and
+// the byte range requests (Range), e.g. for seeking in a video or resuming
+// a download.
+//
+// Only the requested bytes are copied to the response, with the status
+// 304 Not Modified, 206 Partial Content or 416 Range Not Satisfiable as
+// appropriate.
+//
+// Example:
+//
+//	helpers.ServeContent(w, r, content, helpers.ServeContentOptions{
+//		Name:         "video.webm",
+//		CacheControl: "public, max-age=86400",
+//	})
+func ServeContent(w http.ResponseWriter, r *http.Request, content []byte, options ServeContentOptions) {
+	if options.ETag == "" {
+		options.ETag = ContentETag(content)
+	}
+
+	ServeReader(w, r, bytes.NewReader(content), options)
+}
+
+// ServeReader is ServeContent for a file not in memory, read only for the
+// requested bytes, so nothing is read for a 304 Not Modified. The ETag
+// header is only set when options.ETag is set, e.g. with FileETag
+func ServeReader(w http.ResponseWriter, r *http.Request, content io.ReadSeeker, options ServeContentOptions) {
+	if options.ETag != "" {
+		w.Header().Set("ETag", options.ETag)
+	}
+
+	if options.ContentType != "" {
+		w.Header().Set("Content-Type", options.ContentType)
+	}
+
+	if options.CacheControl != "" {
+		w.Header().Set("Cache-Control", options.CacheControl)
+	}
+
+	if options.Attachment {
+		disposition := mime.FormatMediaType("attachment", map[string]string{"filename": options.Name})
+
+		if disposition == "" {
+			disposition = "attachment"
+		}
+
+		w.Header().Set("Content-Disposition", disposition)
+	}
+
+	http.ServeContent(w, r, options.Name, options.ModTime, content)
+}
+
+// ContentETag returns the strong ETag of the content, the quoted start of
+// its SHA-256 hash, which changes whenever a byte of the content changes
+func ContentETag(content []byte) string {
+	hash := sha256.Sum256(content)
+
+	return `"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+// FileETag returns the ETag of a stored file from its size and the time it
+// was last modified, like most web servers, so the file is not read to
+// revalidate it
+func FileETag(size int64, modTime time.Time) string {
+	return `"` + strconv.FormatInt(modTime.Unix(), 16) + "-" + strconv.FormatInt(size, 16) + `"`
+}
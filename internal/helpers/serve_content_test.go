@@ -0,0 +1,183 @@
+package helpers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serveContentRequest(t *testing.T, headers map[string]string, options ServeContentOptions) *httptest.ResponseRecorder {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, "/media/video.webm", nil)
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+
+	w := httptest.NewRecorder()
+	ServeContent(w, r, []byte("0123456789"), options)
+
+	return w
+}
+
+func TestServeContent_Full(t *testing.T) {
+	w := serveContentRequest(t, nil, ServeContentOptions{
+		Name:         "video.webm",
+		ContentType:  "video/webm",
+		CacheControl: "public, max-age=60",
+		ModTime:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+
+	if w.Code != http.StatusOK || w.Body.String() != "0123456789" {
+		t.Fatalf("expected the full content, got %d %q", w.Code, w.Body.String())
+	}
+
+	expected := map[string]string{
+		"ETag":          ContentETag([]byte("0123456789")),
+		"Content-Type":  "video/webm",
+		"Cache-Control": "public, max-age=60",
+		"Accept-Ranges": "bytes",
+		"Last-Modified": "Fri, 02 Jan 2026 03:04:05 GMT",
+	}
+
+	for name, value := range expected {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("expected %s %q, got %q", name, value, got)
+		}
+	}
+}
+
+func TestServeContent_Range(t *testing.T) {
+	w := serveContentRequest(t, map[string]string{"Range": "bytes=2-5"}, ServeContentOptions{Name: "video.webm"})
+
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("expected 206, got %d", w.Code)
+	}
+
+	if w.Body.String() != "2345" {
+		t.Errorf("expected the bytes 2 to 5, got %q", w.Body.String())
+	}
+
+	if got := w.Header().Get("Content-Range"); got != "bytes 2-5/10" {
+		t.Errorf("expected Content-Range bytes 2-5/10, got %q", got)
+	}
+}
+
+func TestServeContent_RangeNotSatisfiable(t *testing.T) {
+	w := serveContentRequest(t, map[string]string{"Range": "bytes=20-30"}, ServeContentOptions{Name: "video.webm"})
+
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("expected 416, got %d", w.Code)
+	}
+}
+
+func TestServeContent_IfNoneMatch(t *testing.T) {
+	etag := ContentETag([]byte("0123456789"))
+
+	w := serveContentRequest(t, map[string]string{"If-None-Match": etag}, ServeContentOptions{Name: "video.webm"})
+
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", w.Code)
+	}
+
+	if w.Body.Len() != 0 {
+		t.Errorf("expected no body, got %q", w.Body.String())
+	}
+
+	w = serveContentRequest(t, map[string]string{"If-None-Match": `"stale"`}, ServeContentOptions{Name: "video.webm"})
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 for a stale ETag, got %d", w.Code)
+	}
+}
+
+func TestServeContent_IfModifiedSince(t *testing.T) {
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	w := serveContentRequest(t, map[string]string{"If-Modified-Since": modTime.Format(http.TimeFormat)}, ServeContentOptions{
+		Name:    "video.webm",
+		ModTime: modTime,
+	})
+
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", w.Code)
+	}
+}
+
+func TestServeContent_IfRangeStale(t *testing.T) {
+	// The whole file is sent when it changed since the part was downloaded
+	w := serveContentRequest(t, map[string]string{"Range": "bytes=2-5", "If-Range": `"stale"`}, ServeContentOptions{Name: "video.webm"})
+
+	if w.Code != http.StatusOK || w.Body.String() != "0123456789" {
+		t.Fatalf("expected the full content, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestServeContent_Attachment(t *testing.T) {
+	w := serveContentRequest(t, nil, ServeContentOptions{Name: "my report.bin", Attachment: true})
+
+	disposition := w.Header().Get("Content-Disposition")
+
+	if !strings.HasPrefix(disposition, "attachment;") || !strings.Contains(disposition, `filename="my report.bin"`) {
+		t.Errorf("expected an attachment with the file name, got %q", disposition)
+	}
+}
+
+func TestContentETag(t *testing.T) {
+	etag := ContentETag([]byte("a"))
+
+	if !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) || strings.HasPrefix(etag, "W/") {
+		t.Errorf("expected a quoted strong ETag, got %q", etag)
+	}
+
+	if etag == ContentETag([]byte("b")) {
+		t.Error("expected different contents to have different ETags")
+	}
+
+	if etag != ContentETag([]byte("a")) {
+		t.Error("expected the same content to have the same ETag")
+	}
+}
+
+func TestServeContent_OptionsETag(t *testing.T) {
+	w := serveContentRequest(t, nil, ServeContentOptions{Name: "video.webm", ETag: `"given"`})
+
+	if got := w.Header().Get("ETag"); got != `"given"` {
+		t.Errorf("expected the given ETag, got %q", got)
+	}
+}
+
+func TestServeReader_Range(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/media/video.webm", nil)
+	r.Header.Set("Range", "bytes=2-5")
+	w := httptest.NewRecorder()
+
+	ServeReader(w, r, strings.NewReader("0123456789"), ServeContentOptions{Name: "video.webm"})
+
+	if w.Code != http.StatusPartialContent || w.Body.String() != "2345" {
+		t.Fatalf("expected the bytes 2 to 5, got %d %q", w.Code, w.Body.String())
+	}
+
+	if got := w.Header().Get("ETag"); got != "" {
+		t.Errorf("expected no ETag without options.ETag, got %q", got)
+	}
+}
+
+func TestFileETag(t *testing.T) {
+	modTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	etag := FileETag(10, modTime)
+
+	if !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+		t.Errorf("expected a quoted strong ETag, got %q", etag)
+	}
+
+	if etag != FileETag(10, modTime) {
+		t.Error("expected the same file to have the same ETag")
+	}
+
+	if etag == FileETag(11, modTime) || etag == FileETag(10, modTime.Add(time.Second)) {
+		t.Error("expected a changed size or time to change the ETag")
+	}
+}
@@ -0,0 +1,85 @@
+package helpers
+
+import (
+	"strings"
+
+	"github.com/mileusna/useragent"
+)
+
+// The device types of a user agent
+const (
+	DEVICE_TYPE_BOT     = "bot"
+	DEVICE_TYPE_DESKTOP = "desktop"
+	DEVICE_TYPE_MOBILE  = "mobile"
+	DEVICE_TYPE_TABLET  = "tablet"
+)
+
+// UserAgentDetails are the browser, operating system and device parsed
+// from a user agent string
+type UserAgentDetails struct {
+	Browser        string
+	BrowserVersion string
+	OS             string
+	OSVersion      string
+	Device         string
+	DeviceType     string
+}
+
+// UserAgentParse parses the user agent string (e.g. the User-Agent header)
+func UserAgentParse(userAgent string) UserAgentDetails {
+	ua := useragent.Parse(userAgent)
+
+	deviceType := ""
+
+	if ua.Mobile {
+		deviceType = DEVICE_TYPE_MOBILE
+	}
+	if ua.Tablet {
+		deviceType = DEVICE_TYPE_TABLET
+	}
+	if ua.Desktop {
+		deviceType = DEVICE_TYPE_DESKTOP
+	}
+	if ua.Bot {
+		deviceType = DEVICE_TYPE_BOT
+	}
+
+	return UserAgentDetails{
+		Browser:        ua.Name,
+		BrowserVersion: ua.Version,
+		OS:             ua.OS,
+		OSVersion:      ua.OSVersion,
+		Device:         ua.Device,
+		DeviceType:     deviceType,
+	}
+}
+
+// Summary returns the browser and operating system in a human readable
+// form (e.g. "Chrome 120.0 on Windows 10"), or "Unknown device"
+func (d UserAgentDetails) Summary() string {
+	browser := strings.TrimSpace(d.Browser + " " + majorMinor(d.BrowserVersion))
+	os := strings.TrimSpace(d.OS + " " + d.OSVersion)
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	}
+
+	return "Unknown device"
+}
+
+// majorMinor shortens the version to its major and minor parts
+// (e.g. "120.0.6099.109" to "120.0")
+func majorMinor(version string) string {
+	parts := strings.Split(version, ".")
+
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,41 @@
+package helpers
+
+import "testing"
+
+func TestUserAgentParse(t *testing.T) {
+	details := UserAgentParse("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.109 Safari/537.36")
+
+	if details.Browser != "Chrome" {
+		t.Errorf("Browser = %q, want Chrome", details.Browser)
+	}
+
+	if details.OS != "Windows" {
+		t.Errorf("OS = %q, want Windows", details.OS)
+	}
+
+	if details.DeviceType != DEVICE_TYPE_DESKTOP {
+		t.Errorf("DeviceType = %q, want %q", details.DeviceType, DEVICE_TYPE_DESKTOP)
+	}
+
+	if summary := details.Summary(); summary != "Chrome 120.0 on Windows 10.0" {
+		t.Errorf("Summary() = %q, want %q", summary, "Chrome 120.0 on Windows 10.0")
+	}
+}
+
+func TestUserAgentParse_Mobile(t *testing.T) {
+	details := UserAgentParse("Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1")
+
+	if details.DeviceType != DEVICE_TYPE_MOBILE {
+		t.Errorf("DeviceType = %q, want %q", details.DeviceType, DEVICE_TYPE_MOBILE)
+	}
+
+	if details.OS != "iOS" {
+		t.Errorf("OS = %q, want iOS", details.OS)
+	}
+}
+
+func TestUserAgentDetails_SummaryUnknown(t *testing.T) {
+	if summary := UserAgentParse("").Summary(); summary != "Unknown device" {
+		t.Errorf("Summary() = %q, want %q", summary, "Unknown device")
+	}
+}
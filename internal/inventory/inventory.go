@@ -0,0 +1,104 @@
+// Package inventory tracks the stock of the shop products.
+//
+// The stock on hand is the quantity of the product in the shop store. When
+// the checkout begins, the ordered quantities are reserved, so that they
+// cannot be sold twice while the buyer is paying. The reservations are
+// committed (the stock is decremented) when the payment is confirmed, or
+// released by a scheduled task when the payment never completes. The stock
+// of a product is locked while it is reserved or decremented, so that the
+// concurrent orders cannot oversell it.
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dracory/shopstore"
+)
+
+// Statuses of a stock reservation
+const (
+	RESERVATION_STATUS_ACTIVE    = "active"
+	RESERVATION_STATUS_COMMITTED = "committed"
+	RESERVATION_STATUS_RELEASED  = "released"
+)
+
+// The lock of the stock of a product is held while the stock is checked
+// and reserved, or decremented. It expires after productLockExpiry, when
+// the caller holding it stopped, and is waited for up to productLockTimeout
+const (
+	productLockExpiry        = 30 * time.Second
+	productLockTimeout       = 5 * time.Second
+	productLockRetryInterval = 20 * time.Millisecond
+)
+
+// ErrProductLocked is returned when the stock of a product stays locked by
+// another order for longer than productLockTimeout
+var ErrProductLocked = errors.New("the stock of the product is being updated, please try again")
+
+// Reservation holds a quantity of a product for an order awaiting payment
+type Reservation struct {
+	ID        string    `json:"-"`
+	OrderID   string    `json:"order_id"`
+	ProductID string    `json:"product_id"`
+	Quantity  int64     `json:"quantity"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// IsHeld returns true if the reservation still holds the stock at the given time
+func (reservation Reservation) IsHeld(now time.Time) bool {
+	return reservation.Status == RESERVATION_STATUS_ACTIVE && reservation.ExpiresAt.After(now)
+}
+
+// Item is a quantity of a product to reserve
+type Item struct {
+	ProductID string
+	Title     string
+	Quantity  int64
+}
+
+// OutOfStockError is returned when there is not enough stock of a product
+type OutOfStockError struct {
+	ProductID string
+	Title     string
+	Available int64
+}
+
+func (err *OutOfStockError) Error() string {
+	name := err.Title
+	if name == "" {
+		name = "This product"
+	}
+
+	if err.Available <= 0 {
+		return name + " is out of stock"
+	}
+
+	return fmt.Sprintf("Only %d of %s left in stock", err.Available, name)
+}
+
+// IsOutOfStock checks if the error is an out of stock error, which can be
+// shown to the buyer, as opposed to a technical error
+func IsOutOfStock(err error) bool {
+	var outOfStock *OutOfStockError
+	return errors.As(err, &outOfStock)
+}
+
+// ProductStock returns the stock on hand of the product, which is its
+// quantity in the shop store. Products without a valid quantity have no stock
+func ProductStock(product shopstore.ProductInterface) int64 {
+	if product == nil {
+		return 0
+	}
+
+	stock, err := strconv.ParseInt(strings.TrimSpace(product.GetQuantity()), 10, 64)
+	if err != nil || stock < 0 {
+		return 0
+	}
+
+	return stock
+}
@@ -0,0 +1,67 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dracory/shopstore"
+)
+
+func TestReservationIsHeld(t *testing.T) {
+	now := time.Now().UTC()
+
+	held := Reservation{Status: RESERVATION_STATUS_ACTIVE, ExpiresAt: now.Add(time.Minute)}
+	if !held.IsHeld(now) {
+		t.Error("an active reservation expiring later must be held")
+	}
+
+	expired := Reservation{Status: RESERVATION_STATUS_ACTIVE, ExpiresAt: now.Add(-time.Minute)}
+	if expired.IsHeld(now) {
+		t.Error("an expired reservation must not be held")
+	}
+
+	released := Reservation{Status: RESERVATION_STATUS_RELEASED, ExpiresAt: now.Add(time.Minute)}
+	if released.IsHeld(now) {
+		t.Error("a released reservation must not be held")
+	}
+}
+
+func TestOutOfStockError(t *testing.T) {
+	err := error(&OutOfStockError{ProductID: "p1", Title: "Mug", Available: 0})
+	if err.Error() != "Mug is out of stock" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	err = &OutOfStockError{ProductID: "p1", Title: "Mug", Available: 2}
+	if err.Error() != "Only 2 of Mug left in stock" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	if !IsOutOfStock(fmt.Errorf("wrapped: %w", err)) {
+		t.Error("IsOutOfStock() must detect wrapped errors")
+	}
+
+	if IsOutOfStock(errors.New("database is down")) {
+		t.Error("IsOutOfStock() must not match other errors")
+	}
+}
+
+func TestProductStock(t *testing.T) {
+	if ProductStock(nil) != 0 {
+		t.Error("a nil product has no stock")
+	}
+
+	product := shopstore.NewProduct()
+
+	product.SetQuantity("7")
+	if ProductStock(product) != 7 {
+		t.Errorf("ProductStock() = %d, want 7", ProductStock(product))
+	}
+
+	product.SetQuantity("-1")
+	if ProductStock(product) != 0 {
+		t.Errorf("a negative quantity must be no stock, got %d", ProductStock(product))
+	}
+}
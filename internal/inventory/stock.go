@@ -0,0 +1,327 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dracory/shopstore"
+)
+
+// Available returns the stock of the product which can still be sold: the
+// stock on hand minus the quantities held by the active reservations
+func Available(ctx context.Context, shopStore shopstore.StoreInterface, store StoreInterface, product shopstore.ProductInterface, now time.Time) (int64, error) {
+	if shopStore == nil || store == nil {
+		return 0, errors.New("shop store and reservation store are required")
+	}
+
+	if product == nil {
+		return 0, nil
+	}
+
+	reservations, err := store.ReservationListActiveByProduct(product.GetID())
+	if err != nil {
+		return 0, err
+	}
+
+	available := ProductStock(product)
+	for _, reservation := range reservations {
+		if reservation.IsHeld(now) {
+			available -= reservation.Quantity
+		}
+	}
+
+	return max(0, available), nil
+}
+
+// Check checks there is enough stock available for all the items.
+// Returns an *OutOfStockError for the first item which cannot be supplied
+func Check(ctx context.Context, shopStore shopstore.StoreInterface, store StoreInterface, items []Item, now time.Time) error {
+	if shopStore == nil || store == nil {
+		return errors.New("shop store and reservation store are required")
+	}
+
+	for _, item := range items {
+		product, err := shopStore.ProductFindByID(ctx, item.ProductID)
+		if err != nil {
+			return err
+		}
+
+		available, err := Available(ctx, shopStore, store, product, now)
+		if err != nil {
+			return err
+		}
+
+		if item.Quantity > available {
+			title := item.Title
+			if title == "" && product != nil {
+				title = product.GetTitle()
+			}
+
+			return &OutOfStockError{ProductID: item.ProductID, Title: title, Available: available}
+		}
+	}
+
+	return nil
+}
+
+// Reserve holds the stock of the items for the order until the given
+// expiry time. The operation is idempotent, an order whose reservations
+// are still held is not reserved again.
+//
+// The availability check and the reservation are made holding the locks
+// of the products, so two buyers checking out the last unit at the same
+// moment cannot both reserve it.
+func Reserve(ctx context.Context, shopStore shopstore.StoreInterface, store StoreInterface, orderID string, items []Item, now time.Time, expiresAt time.Time) error {
+	if orderID == "" {
+		return errors.New("order ID is required")
+	}
+
+	productIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	return withProductLocks(ctx, store, productIDs, func() error {
+		existing, err := store.ReservationListByOrder(orderID)
+		if err != nil {
+			return err
+		}
+
+		for _, reservation := range existing {
+			if reservation.IsHeld(now) {
+				return nil
+			}
+		}
+
+		if err := Check(ctx, shopStore, store, items, now); err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			err := store.ReservationCreate(&Reservation{
+				OrderID:   orderID,
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+				Status:    RESERVATION_STATUS_ACTIVE,
+				ExpiresAt: expiresAt.UTC(),
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Commit decrements the stock of the products reserved for the paid order.
+//
+// Reservations released because the payment took too long are committed as
+// well, as the goods were paid for. The operation is idempotent, the commit
+// of each reservation is claimed before the stock is decremented, so it is
+// safe to call it both from the payment return URL and from the payment
+// provider webhook, even at the same moment. The stock is decremented
+// holding the lock of the product, so the concurrent orders of the same
+// product do not lose a decrement.
+func Commit(ctx context.Context, shopStore shopstore.StoreInterface, store StoreInterface, orderID string) error {
+	if shopStore == nil || store == nil {
+		return errors.New("shop store and reservation store are required")
+	}
+
+	reservations, err := store.ReservationListByOrder(orderID)
+	if err != nil {
+		return err
+	}
+
+	for i := range reservations {
+		reservation := &reservations[i]
+
+		if reservation.Status == RESERVATION_STATUS_COMMITTED {
+			continue
+		}
+
+		err := withProductLocks(ctx, store, []string{reservation.ProductID}, func() error {
+			return commitReservation(ctx, shopStore, store, reservation)
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commitReservation decrements the stock of the product of the reservation
+// once, and marks the reservation as committed. The lock of the product
+// must be held
+func commitReservation(ctx context.Context, shopStore shopstore.StoreInterface, store StoreInterface, reservation *Reservation) error {
+	claimed, err := store.ReservationClaimCommit(reservation.ID)
+	if err != nil {
+		return err
+	}
+
+	if !claimed {
+		return nil
+	}
+
+	if err := decrementStock(ctx, shopStore, reservation); err != nil {
+		// Let the commit be retried
+		if unclaimErr := store.ReservationUnclaimCommit(reservation.ID); unclaimErr != nil {
+			return errors.Join(err, unclaimErr)
+		}
+
+		return err
+	}
+
+	reservation.Status = RESERVATION_STATUS_COMMITTED
+
+	return store.ReservationUpdate(reservation)
+}
+
+// decrementStock decrements the stock of the product by the quantity of
+// the reservation. The lock of the product must be held, as the stock is
+// read, then saved
+func decrementStock(ctx context.Context, shopStore shopstore.StoreInterface, reservation *Reservation) error {
+	product, err := shopStore.ProductFindByID(ctx, reservation.ProductID)
+	if err != nil || product == nil {
+		return err
+	}
+
+	product.SetQuantity(strconv.FormatInt(max(0, ProductStock(product)-reservation.Quantity), 10))
+
+	return shopStore.ProductUpdate(ctx, product)
+}
+
+// Release releases the active reservations of the order, e.g. when the
+// order is cancelled
+func Release(store StoreInterface, orderID string) error {
+	reservations, err := store.ReservationListByOrder(orderID)
+	if err != nil {
+		return err
+	}
+
+	for i := range reservations {
+		if reservations[i].Status != RESERVATION_STATUS_ACTIVE {
+			continue
+		}
+
+		if _, err := releaseReservation(store, reservations[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReleaseExpired releases the reservations which expired before the given
+// time, and returns how many were released
+func ReleaseExpired(store StoreInterface, now time.Time) (int, error) {
+	reservations, err := store.ReservationListExpired(now)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+
+	for i := range reservations {
+		ok, err := releaseReservation(store, reservations[i])
+		if err != nil {
+			return released, err
+		}
+
+		if ok {
+			released++
+		}
+	}
+
+	return released, nil
+}
+
+// releaseReservation releases the reservation, holding the lock of its
+// product, and returns false when it was committed (or released) since it
+// was listed, e.g. by the payment of the order at the same moment
+func releaseReservation(store StoreInterface, reservation Reservation) (bool, error) {
+	released := false
+
+	err := withProductLocks(context.Background(), store, []string{reservation.ProductID}, func() error {
+		current, err := store.ReservationFindByID(reservation.ID)
+		if err != nil {
+			return err
+		}
+
+		if current == nil || current.Status != RESERVATION_STATUS_ACTIVE {
+			return nil
+		}
+
+		current.Status = RESERVATION_STATUS_RELEASED
+
+		if err := store.ReservationUpdate(current); err != nil {
+			return err
+		}
+
+		released = true
+
+		return nil
+	})
+
+	return released, err
+}
+
+// withProductLocks runs the function holding the locks of the stock of the
+// products. The locks are taken in the order of the product IDs, so two
+// callers locking the same products cannot wait for each other
+func withProductLocks(ctx context.Context, store StoreInterface, productIDs []string, fn func() error) (err error) {
+	productIDs = slices.Clone(productIDs)
+	slices.Sort(productIDs)
+	productIDs = slices.Compact(productIDs)
+
+	locked := []string{}
+
+	defer func() {
+		for _, productID := range locked {
+			if unlockErr := store.ProductUnlock(productID); unlockErr != nil {
+				err = errors.Join(err, unlockErr)
+			}
+		}
+	}()
+
+	for _, productID := range productIDs {
+		if err := productLockWait(ctx, store, productID); err != nil {
+			return err
+		}
+
+		locked = append(locked, productID)
+	}
+
+	return fn()
+}
+
+// productLockWait locks the stock of the product, waiting for the lock
+// held by another caller up to productLockTimeout
+func productLockWait(ctx context.Context, store StoreInterface, productID string) error {
+	deadline := time.Now().Add(productLockTimeout)
+
+	for {
+		now := time.Now().UTC()
+
+		locked, err := store.ProductLock(productID, now, now.Add(productLockExpiry))
+		if err != nil || locked {
+			return err
+		}
+
+		if now.After(deadline) {
+			return ErrProductLocked
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(productLockRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package inventory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"project/internal/app"
+	"project/internal/testutils"
+)
+
+func setupStock(t *testing.T) (app.AppInterface, StoreInterface) {
+	t.Helper()
+
+	app := testutils.Setup(testutils.WithCustomStore(true), testutils.WithShopStore(true))
+
+	// SeedProduct creates the product with a quantity of 10
+	if _, err := testutils.SeedProduct(app.GetShopStore(), "product_1", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app, store
+}
+
+func TestReserve_HoldsStock(t *testing.T) {
+	app, store := setupStock(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	items := []Item{{ProductID: "product_1", Title: "Test Product", Quantity: 8}}
+
+	if err := Reserve(ctx, app.GetShopStore(), store, "order_1", items, now, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Idempotent
+	if err := Reserve(ctx, app.GetShopStore(), store, "order_1", items, now, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	product, err := app.GetShopStore().ProductFindByID(ctx, "product_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	available, err := Available(ctx, app.GetShopStore(), store, product, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if available != 2 {
+		t.Errorf("Available() = %d, want 2", available)
+	}
+
+	err = Reserve(ctx, app.GetShopStore(), store, "order_2", []Item{{ProductID: "product_1", Title: "Test Product", Quantity: 3}}, now, now.Add(time.Hour))
+	if !IsOutOfStock(err) {
+		t.Fatalf("expected an out of stock error, got %v", err)
+	}
+
+	if err.Error() != "Only 2 of Test Product left in stock" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+func TestCommit_DecrementsStockOnce(t *testing.T) {
+	app, store := setupStock(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	if err := Reserve(ctx, app.GetShopStore(), store, "order_1", []Item{{ProductID: "product_1", Quantity: 3}}, now, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if err := Commit(ctx, app.GetShopStore(), store, "order_1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	product, err := app.GetShopStore().ProductFindByID(ctx, "product_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ProductStock(product) != 7 {
+		t.Errorf("expected the stock to be decremented once to 7, got %d", ProductStock(product))
+	}
+
+	available, err := Available(ctx, app.GetShopStore(), store, product, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if available != 7 {
+		t.Errorf("a committed reservation must not hold stock, available = %d", available)
+	}
+}
+
+// staleStore returns the reservations of the order as read by a concurrent
+// call, before the other call committed them
+type staleStore struct {
+	StoreInterface
+	reservations []Reservation
+}
+
+func (store staleStore) ReservationListByOrder(orderID string) ([]Reservation, error) {
+	return store.reservations, nil
+}
+
+func TestCommit_ConcurrentCallsDecrementStockOnce(t *testing.T) {
+	app, store := setupStock(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	if err := Reserve(ctx, app.GetShopStore(), store, "order_1", []Item{{ProductID: "product_1", Quantity: 3}}, now, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The webhook and the return URL both read the reservation as not
+	// committed yet
+	reservations, err := store.ReservationListByOrder("order_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Commit(ctx, app.GetShopStore(), store, "order_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Commit(ctx, app.GetShopStore(), staleStore{StoreInterface: store, reservations: reservations}, "order_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	product, err := app.GetShopStore().ProductFindByID(ctx, "product_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ProductStock(product) != 7 {
+		t.Errorf("expected the stock to be decremented once to 7, got %d", ProductStock(product))
+	}
+}
+
+func TestReserve_ConcurrentOrdersDoNotOversell(t *testing.T) {
+	app, store := setupStock(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	var wins atomic.Int32
+	var wg sync.WaitGroup
+
+	// 10 orders of 2 units, for a stock of 10
+	for i := range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := Reserve(ctx, app.GetShopStore(), store, "order_"+strconv.Itoa(i), []Item{{ProductID: "product_1", Quantity: 2}}, now, now.Add(time.Hour))
+			if err == nil {
+				wins.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if wins.Load() != 5 {
+		t.Errorf("expected exactly 5 orders to reserve the stock, got %d", wins.Load())
+	}
+}
+
+func TestCommit_ConcurrentOrdersDecrementStock(t *testing.T) {
+	app, store := setupStock(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for _, orderID := range []string{"order_1", "order_2"} {
+		if err := Reserve(ctx, app.GetShopStore(), store, orderID, []Item{{ProductID: "product_1", Quantity: 3}}, now, now.Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+
+	for _, orderID := range []string{"order_1", "order_2"} {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := Commit(ctx, app.GetShopStore(), store, orderID); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	product, err := app.GetShopStore().ProductFindByID(ctx, "product_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ProductStock(product) != 4 {
+		t.Errorf("expected both orders to decrement the stock to 4, got %d", ProductStock(product))
+	}
+}
+
+// expiredStore lists the reservations as expired, as read by the release
+// task before the payment of the order committed them
+type expiredStore struct {
+	StoreInterface
+	reservations []Reservation
+}
+
+func (store expiredStore) ReservationListExpired(now time.Time) ([]Reservation, error) {
+	return store.reservations, nil
+}
+
+func TestReleaseExpired_SkipsCommitted(t *testing.T) {
+	app, store := setupStock(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	if err := Reserve(ctx, app.GetShopStore(), store, "order_1", []Item{{ProductID: "product_1", Quantity: 3}}, now, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	expired, err := store.ReservationListExpired(now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Commit(ctx, app.GetShopStore(), store, "order_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	released, err := ReleaseExpired(expiredStore{StoreInterface: store, reservations: expired}, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released != 0 {
+		t.Errorf("a committed reservation must not be released, released %d", released)
+	}
+
+	reservations, err := store.ReservationListByOrder("order_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reservations) != 1 || reservations[0].Status != RESERVATION_STATUS_COMMITTED {
+		t.Errorf("expected the reservation to stay committed, got %+v", reservations)
+	}
+}
+
+func TestReleaseExpired(t *testing.T) {
+	app, store := setupStock(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	if err := Reserve(ctx, app.GetShopStore(), store, "order_1", []Item{{ProductID: "product_1", Quantity: 10}}, now, now.Add(30*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	released, err := ReleaseExpired(store, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released != 0 {
+		t.Errorf("no reservation has expired yet, released %d", released)
+	}
+
+	released, err = ReleaseExpired(store, now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released != 1 {
+		t.Errorf("expected 1 released reservation, got %d", released)
+	}
+
+	reservations, err := store.ReservationListByOrder("order_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reservations) != 1 || reservations[0].Status != RESERVATION_STATUS_RELEASED {
+		t.Errorf("expected the reservation to be released, got %+v", reservations)
+	}
+
+	// A late payment still commits the released reservation
+	if err := Commit(ctx, app.GetShopStore(), store, "order_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	product, err := app.GetShopStore().ProductFindByID(ctx, "product_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ProductStock(product) != 0 {
+		t.Errorf("expected the stock to be 0 after the late payment, got %d", ProductStock(product))
+	}
+}
+
+func TestRelease(t *testing.T) {
+	app, store := setupStock(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	if err := Reserve(ctx, app.GetShopStore(), store, "order_1", []Item{{ProductID: "product_1", Quantity: 10}}, now, now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Release(store, "order_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Check(ctx, app.GetShopStore(), store, []Item{{ProductID: "product_1", Quantity: 10}}, now); err != nil {
+		t.Errorf("the released stock must be available again, got %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"project/internal/customrecords"
+	"time"
+
+	"github.com/dracory/customstore"
+)
+
+// RECORD_TYPE_RESERVATION is the record type of the reservations in the custom store
+const RECORD_TYPE_RESERVATION = "shop_stock_reservation"
+
+// RECORD_TYPE_RESERVATION_COMMIT is the record type of the claims to commit
+// the reservations in the custom store
+const RECORD_TYPE_RESERVATION_COMMIT = "shop_stock_reservation_commit"
+
+// RECORD_TYPE_PRODUCT_LOCK is the record type of the locks of the stock of
+// the products in the custom store
+const RECORD_TYPE_PRODUCT_LOCK = "shop_stock_product_lock"
+
+// StoreInterface persists the stock reservations, and the locks of the
+// stock of the products
+type StoreInterface interface {
+	ProductLock(productID string, now time.Time, expiresAt time.Time) (bool, error)
+	ProductUnlock(productID string) error
+
+	ReservationClaimCommit(reservationID string) (bool, error)
+	ReservationCreate(reservation *Reservation) error
+	ReservationFindByID(reservationID string) (*Reservation, error)
+	ReservationListActiveByProduct(productID string) ([]Reservation, error)
+	ReservationListByOrder(orderID string) ([]Reservation, error)
+	ReservationListExpired(now time.Time) ([]Reservation, error)
+	ReservationUnclaimCommit(reservationID string) error
+	ReservationUpdate(reservation *Reservation) error
+}
+
+// productLock is the lock of the stock of a product, taken over once
+// expired, e.g. when the instance holding it stopped
+type productLock struct {
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type store struct {
+	customStore customstore.StoreInterface
+}
+
+var _ StoreInterface = (*store)(nil)
+
+// NewStore creates a reservation store on top of the custom store
+func NewStore(customStore customstore.StoreInterface) (StoreInterface, error) {
+	if customStore == nil {
+		return nil, errors.New("custom store is required")
+	}
+
+	return &store{customStore: customStore}, nil
+}
+
+// ProductLock locks the stock of the product until the given expiry time,
+// and returns false when it is locked by another caller. The lock is a
+// claim (see customrecords.Claim), so only one caller holds it, in all
+// the instances of the application
+func (s *store) ProductLock(productID string, now time.Time, expiresAt time.Time) (bool, error) {
+	if productID == "" {
+		return false, errors.New("product ID is required")
+	}
+
+	payload, err := json.Marshal(productLock{ExpiresAt: expiresAt.UTC()})
+	if err != nil {
+		return false, err
+	}
+
+	claimed, err := customrecords.Claim(s.customStore, RECORD_TYPE_PRODUCT_LOCK, productID, string(payload))
+	if err != nil || claimed {
+		return claimed, err
+	}
+
+	record, err := s.customStore.RecordFindByID(customrecords.ClaimID(RECORD_TYPE_PRODUCT_LOCK, productID))
+	if err != nil || record == nil {
+		return false, err
+	}
+
+	lock := productLock{}
+	if err := json.Unmarshal([]byte(record.Payload()), &lock); err != nil {
+		return false, err
+	}
+
+	if lock.ExpiresAt.After(now) {
+		return false, nil
+	}
+
+	// The expired lock is taken over
+	if err := customrecords.Unclaim(s.customStore, RECORD_TYPE_PRODUCT_LOCK, productID); err != nil {
+		return false, err
+	}
+
+	return customrecords.Claim(s.customStore, RECORD_TYPE_PRODUCT_LOCK, productID, string(payload))
+}
+
+// ProductUnlock unlocks the stock of the product
+func (s *store) ProductUnlock(productID string) error {
+	if productID == "" {
+		return errors.New("product ID is required")
+	}
+
+	return customrecords.Unclaim(s.customStore, RECORD_TYPE_PRODUCT_LOCK, productID)
+}
+
+// ReservationClaimCommit claims the commit of the reservation, and returns
+// false when it was already claimed. The claim is atomic, so of the
+// concurrent commits of the same reservation only one decrements the stock
+func (s *store) ReservationClaimCommit(reservationID string) (bool, error) {
+	if reservationID == "" {
+		return false, errors.New("reservation ID is required")
+	}
+
+	return customrecords.Claim(s.customStore, RECORD_TYPE_RESERVATION_COMMIT, reservationID, "")
+}
+
+// ReservationCreate creates the reservation, and sets its ID
+func (s *store) ReservationCreate(reservation *Reservation) error {
+	if reservation == nil {
+		return errors.New("reservation is nil")
+	}
+
+	if reservation.OrderID == "" || reservation.ProductID == "" {
+		return errors.New("order ID and product ID are required")
+	}
+
+	payload, err := json.Marshal(reservation)
+	if err != nil {
+		return err
+	}
+
+	record := customstore.NewRecord(RECORD_TYPE_RESERVATION, customstore.WithPayload(string(payload)))
+
+	if err := s.customStore.RecordCreate(record); err != nil {
+		return err
+	}
+
+	reservation.ID = record.ID()
+
+	return nil
+}
+
+// ReservationFindByID finds the reservation with the given ID.
+// Returns nil when there is none
+func (s *store) ReservationFindByID(reservationID string) (*Reservation, error) {
+	if reservationID == "" {
+		return nil, nil
+	}
+
+	record, err := s.customStore.RecordFindByID(reservationID)
+	if err != nil || record == nil || record.Type() != RECORD_TYPE_RESERVATION {
+		return nil, err
+	}
+
+	reservation := &Reservation{}
+	if err := json.Unmarshal([]byte(record.Payload()), reservation); err != nil {
+		return nil, err
+	}
+
+	reservation.ID = record.ID()
+
+	return reservation, nil
+}
+
+// ReservationListActiveByProduct returns the active reservations of the
+// product, including the expired ones not released yet
+func (s *store) ReservationListActiveByProduct(productID string) ([]Reservation, error) {
+	return s.reservationList(map[string]string{
+		"product_id": productID,
+		"status":     RESERVATION_STATUS_ACTIVE,
+	})
+}
+
+// ReservationListByOrder returns all the reservations of the order
+func (s *store) ReservationListByOrder(orderID string) ([]Reservation, error) {
+	return s.reservationList(map[string]string{"order_id": orderID})
+}
+
+// ReservationListExpired returns the active reservations which expired
+// before the given time
+func (s *store) ReservationListExpired(now time.Time) ([]Reservation, error) {
+	reservations, err := s.reservationList(map[string]string{"status": RESERVATION_STATUS_ACTIVE})
+	if err != nil {
+		return nil, err
+	}
+
+	expired := []Reservation{}
+	for _, reservation := range reservations {
+		if !reservation.IsHeld(now) {
+			expired = append(expired, reservation)
+		}
+	}
+
+	return expired, nil
+}
+
+// ReservationUnclaimCommit removes the claim of the commit of the
+// reservation, when the stock could not be decremented
+func (s *store) ReservationUnclaimCommit(reservationID string) error {
+	if reservationID == "" {
+		return errors.New("reservation ID is required")
+	}
+
+	return customrecords.Unclaim(s.customStore, RECORD_TYPE_RESERVATION_COMMIT, reservationID)
+}
+
+// ReservationUpdate saves the changes to an existing reservation
+func (s *store) ReservationUpdate(reservation *Reservation) error {
+	if reservation == nil {
+		return errors.New("reservation is nil")
+	}
+
+	record, err := s.customStore.RecordFindByID(reservation.ID)
+	if err != nil {
+		return err
+	}
+
+	if record == nil || record.Type() != RECORD_TYPE_RESERVATION {
+		return errors.New("reservation not found")
+	}
+
+	payload, err := json.Marshal(reservation)
+	if err != nil {
+		return err
+	}
+
+	record.SetPayload(string(payload))
+
+	return s.customStore.RecordUpdate(record)
+}
+
+// reservationList returns the reservations matching all the payload fields
+func (s *store) reservationList(fields map[string]string) ([]Reservation, error) {
+	records, err := customrecords.FindByPayload(s.customStore, customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_RESERVATION), fields)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reservations := []Reservation{}
+
+	for _, record := range records {
+		reservation := Reservation{}
+		if err := json.Unmarshal([]byte(record.Payload()), &reservation); err != nil {
+			return nil, err
+		}
+
+		reservation.ID = record.ID()
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, nil
+}
@@ -0,0 +1,163 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	"project/internal/testutils"
+)
+
+func newTestStore(t *testing.T) StoreInterface {
+	t.Helper()
+
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	store, err := NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func TestNewStore_RequiresCustomStore(t *testing.T) {
+	if _, err := NewStore(nil); err == nil {
+		t.Error("NewStore(nil) expected error")
+	}
+}
+
+func TestStore_ReservationCreateAndList(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Now().UTC()
+
+	held := Reservation{OrderID: "order_1", ProductID: "product_1", Quantity: 2, Status: RESERVATION_STATUS_ACTIVE, ExpiresAt: now.Add(time.Hour)}
+	if err := store.ReservationCreate(&held); err != nil {
+		t.Fatal(err)
+	}
+	if held.ID == "" {
+		t.Fatal("ReservationCreate() must set the ID")
+	}
+
+	expired := Reservation{OrderID: "order_2", ProductID: "product_1", Quantity: 1, Status: RESERVATION_STATUS_ACTIVE, ExpiresAt: now.Add(-time.Hour)}
+	if err := store.ReservationCreate(&expired); err != nil {
+		t.Fatal(err)
+	}
+
+	other := Reservation{OrderID: "order_10", ProductID: "product_10", Quantity: 1, Status: RESERVATION_STATUS_ACTIVE, ExpiresAt: now.Add(time.Hour)}
+	if err := store.ReservationCreate(&other); err != nil {
+		t.Fatal(err)
+	}
+
+	byProduct, err := store.ReservationListActiveByProduct("product_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byProduct) != 2 {
+		t.Errorf("expected 2 reservations of product_1, got %d", len(byProduct))
+	}
+
+	byOrder, err := store.ReservationListByOrder("order_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byOrder) != 1 || byOrder[0].Quantity != 2 {
+		t.Errorf("expected the reservation of order_1, got %+v", byOrder)
+	}
+
+	expiredList, err := store.ReservationListExpired(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expiredList) != 1 || expiredList[0].ID != expired.ID {
+		t.Errorf("expected the expired reservation, got %+v", expiredList)
+	}
+
+	held.Status = RESERVATION_STATUS_RELEASED
+	if err := store.ReservationUpdate(&held); err != nil {
+		t.Fatal(err)
+	}
+
+	byProduct, err = store.ReservationListActiveByProduct("product_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byProduct) != 1 {
+		t.Errorf("expected 1 active reservation after the update, got %d", len(byProduct))
+	}
+}
+
+func TestStore_ReservationClaimCommit(t *testing.T) {
+	store := newTestStore(t)
+
+	claimed, err := store.ReservationClaimCommit("reservation_1")
+	if err != nil || !claimed {
+		t.Fatalf("expected the first claim to succeed, got %v %v", claimed, err)
+	}
+
+	claimed, err = store.ReservationClaimCommit("reservation_1")
+	if err != nil || claimed {
+		t.Fatalf("expected the second claim to fail, got %v %v", claimed, err)
+	}
+
+	if err := store.ReservationUnclaimCommit("reservation_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	claimed, err = store.ReservationClaimCommit("reservation_1")
+	if err != nil || !claimed {
+		t.Fatalf("expected the claim to succeed once removed, got %v %v", claimed, err)
+	}
+
+	if _, err := store.ReservationClaimCommit(""); err == nil {
+		t.Error("expected an error without reservation ID")
+	}
+}
+
+func TestStore_ProductLock(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Now().UTC()
+
+	locked, err := store.ProductLock("product_1", now, now.Add(time.Minute))
+	if err != nil || !locked {
+		t.Fatalf("expected the first lock to succeed, got %v %v", locked, err)
+	}
+
+	locked, err = store.ProductLock("product_1", now, now.Add(time.Minute))
+	if err != nil || locked {
+		t.Fatalf("expected the second lock to fail, got %v %v", locked, err)
+	}
+
+	// The other products are not locked
+	locked, err = store.ProductLock("product_2", now, now.Add(time.Minute))
+	if err != nil || !locked {
+		t.Fatalf("expected the lock of another product to succeed, got %v %v", locked, err)
+	}
+
+	// The expired lock is taken over
+	later := now.Add(2 * time.Minute)
+	locked, err = store.ProductLock("product_1", later, later.Add(time.Minute))
+	if err != nil || !locked {
+		t.Fatalf("expected the expired lock to be taken over, got %v %v", locked, err)
+	}
+
+	if err := store.ProductUnlock("product_1"); err != nil {
+		t.Fatal(err)
+	}
+
+	locked, err = store.ProductLock("product_1", later, later.Add(time.Minute))
+	if err != nil || !locked {
+		t.Fatalf("expected the lock to succeed once unlocked, got %v %v", locked, err)
+	}
+}
+
+func TestStore_ReservationCreateValidates(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.ReservationCreate(nil); err == nil {
+		t.Error("expected an error for a nil reservation")
+	}
+
+	if err := store.ReservationCreate(&Reservation{ProductID: "product_1"}); err == nil {
+		t.Error("expected an error without an order ID")
+	}
+}
@@ -0,0 +1,131 @@
+package invoice
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// Document is the content of the invoice PDF
+type Document struct {
+	Invoice       Invoice
+	SellerName    string
+	CustomerName  string
+	CustomerEmail string
+	OrderID       string
+	Currency      string
+	Lines         []DocumentLine
+	Totals        []DocumentTotal
+}
+
+// DocumentLine is an ordered product
+type DocumentLine struct {
+	Description string
+	Quantity    string
+	UnitPrice   string
+	Total       string
+}
+
+// DocumentTotal is a line of the totals under the products, e.g. the
+// shipping, the tax or the grand total
+type DocumentTotal struct {
+	Label  string
+	Amount string
+	Bold   bool
+}
+
+// page layout in points
+const (
+	marginLeft        = 50.0
+	marginRight       = pdfPageWidth - 50.0
+	marginTop         = pdfPageHeight - 60.0
+	marginBottom      = 70.0
+	columnQuantity    = 360.0
+	columnUnitPrice   = 450.0
+	lineHeight        = 18.0
+	maxDescriptionLen = 55
+)
+
+// RenderPDF renders the invoice to a PDF document
+func RenderPDF(document Document) ([]byte, error) {
+	if document.Invoice.Number <= 0 {
+		return nil, errors.New("invoice number is required")
+	}
+
+	writer := newPdfWriter()
+
+	y := marginTop
+	writer.text(marginLeft, y, 22, true, "INVOICE")
+	writer.textRight(marginRight, y, 12, true, document.SellerName)
+
+	y -= 40
+	writer.text(marginLeft, y, 10, true, "Invoice number")
+	writer.text(marginLeft+110, y, 10, false, document.Invoice.FormattedNumber())
+	y -= 15
+	writer.text(marginLeft, y, 10, true, "Invoice date")
+	writer.text(marginLeft+110, y, 10, false, document.Invoice.IssuedAt.Format("2 January 2006"))
+	y -= 15
+	writer.text(marginLeft, y, 10, true, "Order reference")
+	writer.text(marginLeft+110, y, 10, false, document.OrderID)
+
+	y -= 35
+	writer.text(marginLeft, y, 10, true, "Billed to")
+	y -= 15
+	if document.CustomerName != "" {
+		writer.text(marginLeft, y, 10, false, document.CustomerName)
+		y -= 15
+	}
+	writer.text(marginLeft, y, 10, false, document.CustomerEmail)
+
+	y -= 35
+	y = tableHeader(writer, y, document.Currency)
+
+	for _, line := range document.Lines {
+		if y < marginBottom+lineHeight {
+			writer.addPage()
+			y = tableHeader(writer, marginTop, document.Currency)
+		}
+
+		writer.text(marginLeft, y, 10, false, truncate(line.Description, maxDescriptionLen))
+		writer.textRight(columnQuantity+40, y, 10, false, line.Quantity)
+		writer.textRight(columnUnitPrice+50, y, 10, false, line.UnitPrice)
+		writer.textRight(marginRight, y, 10, false, line.Total)
+		y -= lineHeight
+	}
+
+	writer.line(marginLeft, y+lineHeight-6, marginRight, y+lineHeight-6)
+	y -= 6
+
+	for _, total := range document.Totals {
+		if y < marginBottom {
+			writer.addPage()
+			y = marginTop
+		}
+
+		writer.textRight(columnUnitPrice+50, y, 10, total.Bold, total.Label)
+		writer.textRight(marginRight, y, 10, total.Bold, total.Amount)
+		y -= lineHeight
+	}
+
+	return writer.bytes(), nil
+}
+
+// tableHeader writes the header of the products table, and returns the
+// position of the first row
+func tableHeader(writer *pdfWriter, y float64, currency string) float64 {
+	writer.text(marginLeft, y, 10, true, "Description")
+	writer.textRight(columnQuantity+40, y, 10, true, "Quantity")
+	writer.textRight(columnUnitPrice+50, y, 10, true, "Unit price")
+	writer.textRight(marginRight, y, 10, true, "Total "+currency)
+	writer.line(marginLeft, y-6, marginRight, y-6)
+
+	return y - lineHeight - 4
+}
+
+// truncate shortens the text to the maximum number of characters
+func truncate(text string, maxLength int) string {
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+
+	return string([]rune(text)[:maxLength-3]) + "..."
+}
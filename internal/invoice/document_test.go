@@ -0,0 +1,100 @@
+package invoice
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderPDF(t *testing.T) {
+	document := Document{
+		Invoice:       Invoice{Number: 7, IssuedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		SellerName:    "Acme (Shop)",
+		CustomerName:  "Jane Doe",
+		CustomerEmail: "jane@example.com",
+		OrderID:       "ORDER1",
+		Currency:      "USD",
+		Lines: []DocumentLine{
+			{Description: "Blue Mug", Quantity: "2", UnitPrice: "10.00", Total: "20.00"},
+		},
+		Totals: []DocumentTotal{
+			{Label: "Total", Amount: "20.00", Bold: true},
+		},
+	}
+
+	pdf, err := RenderPDF(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatal("expected a PDF document")
+	}
+
+	for _, expected := range []string{"(INV-000007)", "(Blue Mug)", "(Acme \\(Shop\\))", "(1 March 2026)", "/Count 1"} {
+		if !strings.Contains(string(pdf), expected) {
+			t.Errorf("expected the PDF to contain %q", expected)
+		}
+	}
+}
+
+func TestRenderPDF_XrefOffsets(t *testing.T) {
+	pdf, err := RenderPDF(Document{Invoice: Invoice{Number: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := string(pdf)
+	xref := content[strings.Index(content, "xref\n"):]
+	entries := strings.Split(xref, "\n")[3:]
+
+	for i, entry := range entries {
+		if !strings.HasSuffix(entry, " n ") {
+			break
+		}
+
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := strconv.Itoa(i+1) + " 0 obj"
+		if !strings.HasPrefix(content[offset:], want) {
+			t.Errorf("xref entry %d points to %q, want %q", i+1, content[offset:offset+10], want)
+		}
+	}
+}
+
+func TestRenderPDF_PaginatesLongInvoices(t *testing.T) {
+	lines := []DocumentLine{}
+	for i := 0; i < 60; i++ {
+		lines = append(lines, DocumentLine{Description: "Item", Quantity: "1", UnitPrice: "1.00", Total: "1.00"})
+	}
+
+	pdf, err := RenderPDF(Document{Invoice: Invoice{Number: 1}, Lines: lines})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(pdf), "/Count 2") {
+		t.Error("expected the invoice to span two pages")
+	}
+}
+
+func TestRenderPDF_RequiresNumber(t *testing.T) {
+	if _, err := RenderPDF(Document{}); err == nil {
+		t.Error("expected an error without an invoice number")
+	}
+}
+
+func TestPdfEscape(t *testing.T) {
+	if got := pdfEscape(`a(b)c\`); got != `a\(b\)c\\` {
+		t.Errorf("pdfEscape() = %q", got)
+	}
+
+	if got := pdfEscape("café €"); got != `caf\351 ?` {
+		t.Errorf("pdfEscape() = %q", got)
+	}
+}
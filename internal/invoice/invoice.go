@@ -0,0 +1,29 @@
+// Package invoice issues the invoices of the paid shop orders.
+//
+// Each order gets one invoice, issued when the payment of the order is
+// confirmed, and numbered sequentially in the order the invoices are
+// issued. The invoice is rendered to PDF on request from the order, its
+// line items and the invoice record, so it is never stored.
+package invoice
+
+import (
+	"fmt"
+	"time"
+)
+
+// NUMBER_PREFIX is the prefix of the formatted invoice numbers
+const NUMBER_PREFIX = "INV-"
+
+// Invoice is the invoice issued for an order
+type Invoice struct {
+	ID       string    `json:"-"`
+	OrderID  string    `json:"order_id"`
+	Number   int64     `json:"number"`
+	IssuedAt time.Time `json:"issued_at"`
+}
+
+// FormattedNumber returns the invoice number shown to the customer,
+// e.g. INV-000042
+func (invoice Invoice) FormattedNumber() string {
+	return fmt.Sprintf("%s%06d", NUMBER_PREFIX, invoice.Number)
+}
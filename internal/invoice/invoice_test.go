@@ -0,0 +1,20 @@
+package invoice
+
+import "testing"
+
+func TestInvoice_FormattedNumber(t *testing.T) {
+	tests := []struct {
+		number int64
+		want   string
+	}{
+		{1, "INV-000001"},
+		{42, "INV-000042"},
+		{1234567, "INV-1234567"},
+	}
+
+	for _, tt := range tests {
+		if got := (Invoice{Number: tt.number}).FormattedNumber(); got != tt.want {
+			t.Errorf("FormattedNumber(%d) = %q, want %q", tt.number, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A4 page size in points
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+)
+
+// helveticaWidths are the widths of the printable ASCII characters (32 to
+// 126) of the Helvetica standard font, in thousandths of the font size.
+// They are used for Helvetica-Bold too, which is close enough to align
+// the amounts, which are mostly digits of the same width in both fonts
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// pdfWriter writes a minimal PDF document with text and lines, using the
+// Helvetica standard fonts, which PDF readers provide, so no font is embedded
+type pdfWriter struct {
+	pages   []*bytes.Buffer
+	current *bytes.Buffer
+}
+
+func newPdfWriter() *pdfWriter {
+	writer := &pdfWriter{}
+	writer.addPage()
+	return writer
+}
+
+// addPage starts a new page
+func (writer *pdfWriter) addPage() {
+	writer.current = &bytes.Buffer{}
+	writer.pages = append(writer.pages, writer.current)
+}
+
+// text writes the text with its baseline starting at x, y. The origin is
+// the bottom left corner of the page
+func (writer *pdfWriter) text(x float64, y float64, size float64, bold bool, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+
+	fmt.Fprintf(writer.current, "BT /%s %s Tf %s %s Td (%s) Tj ET\n",
+		font, pdfNumber(size), pdfNumber(x), pdfNumber(y), pdfEscape(text))
+}
+
+// textRight writes the text so that it ends at x
+func (writer *pdfWriter) textRight(x float64, y float64, size float64, bold bool, text string) {
+	writer.text(x-pdfTextWidth(text, size), y, size, bold, text)
+}
+
+// line draws a thin line from x1, y1 to x2, y2
+func (writer *pdfWriter) line(x1 float64, y1 float64, x2 float64, y2 float64) {
+	fmt.Fprintf(writer.current, "0.5 w %s %s m %s %s l S\n",
+		pdfNumber(x1), pdfNumber(y1), pdfNumber(x2), pdfNumber(y2))
+}
+
+// bytes returns the PDF document
+func (writer *pdfWriter) bytes() []byte {
+	// Objects: 1 catalog, 2 pages, 3 regular font, 4 bold font, then
+	// a page object followed by its content stream for each page
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+
+	kids := []string{}
+
+	for _, page := range writer.pages {
+		pageNumber := len(objects) + 1
+		kids = append(kids, strconv.Itoa(pageNumber)+" 0 R")
+
+		objects = append(objects, fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfNumber(pdfPageWidth), pdfNumber(pdfPageHeight), pageNumber+1))
+
+		objects = append(objects, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(writer.pages))
+
+	document := &bytes.Buffer{}
+	document.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = document.Len()
+		fmt.Fprintf(document, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xrefOffset := document.Len()
+	fmt.Fprintf(document, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(document, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+
+	return document.Bytes()
+}
+
+// pdfEscape converts the text to the WinAnsi encoding of the standard
+// fonts, and escapes the characters special in PDF strings. Characters
+// which cannot be encoded are replaced with a question mark
+func pdfEscape(text string) string {
+	escaped := strings.Builder{}
+
+	for _, char := range text {
+		switch {
+		case char == '\\' || char == '(' || char == ')':
+			escaped.WriteByte('\\')
+			escaped.WriteByte(byte(char))
+		case char == '\n' || char == '\r' || char == '\t':
+			escaped.WriteByte(' ')
+		case char >= 32 && char <= 126:
+			escaped.WriteByte(byte(char))
+		case char >= 160 && char <= 255:
+			// Latin-1 and WinAnsi share this range
+			fmt.Fprintf(&escaped, "\\%03o", char)
+		default:
+			escaped.WriteByte('?')
+		}
+	}
+
+	return escaped.String()
+}
+
+// pdfTextWidth returns the width of the text in points
+func pdfTextWidth(text string, size float64) float64 {
+	width := 0
+
+	for _, char := range text {
+		if char >= 32 && char <= 126 {
+			width += helveticaWidths[char-32]
+		} else {
+			width += 556
+		}
+	}
+
+	return float64(width) * size / 1000
+}
+
+func pdfNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', 2, 64)
+}
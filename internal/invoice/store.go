@@ -0,0 +1,261 @@
+package invoice
+
+import (
+	"encoding/json"
+	"errors"
+	"project/internal/customrecords"
+	"strconv"
+	"time"
+
+	"github.com/dracory/customstore"
+)
+
+// Record types of the invoices in the custom store
+const (
+	RECORD_TYPE_INVOICE = "shop_invoice"
+
+	// RECORD_TYPE_INVOICE_NUMBER claims each invoice number, so that no
+	// two invoices get the same number, whichever instance issues them
+	RECORD_TYPE_INVOICE_NUMBER = "shop_invoice_number"
+
+	// RECORD_TYPE_INVOICE_COUNTER holds the last invoice number issued,
+	// where the search for the next free number starts
+	RECORD_TYPE_INVOICE_COUNTER = "shop_invoice_counter"
+)
+
+// ErrIssuing is returned when the invoice of the order is being issued by
+// another request
+var ErrIssuing = errors.New("the invoice is being issued")
+
+// issuingTimeout is how long an invoice is left to the request issuing it,
+// after which it is assumed the request failed, and the invoice is issued
+// again
+const issuingTimeout = time.Minute
+
+// StoreInterface persists the invoices
+type StoreInterface interface {
+	InvoiceFindByOrderID(orderID string) (*Invoice, error)
+	InvoiceIssue(orderID string, now time.Time) (*Invoice, error)
+}
+
+type store struct {
+	customStore customstore.StoreInterface
+}
+
+var _ StoreInterface = (*store)(nil)
+
+// NewStore creates an invoice store on top of the custom store
+func NewStore(customStore customstore.StoreInterface) (StoreInterface, error) {
+	if customStore == nil {
+		return nil, errors.New("custom store is required")
+	}
+
+	return &store{customStore: customStore}, nil
+}
+
+// InvoiceFindByOrderID returns the invoice of the order, or nil if no
+// invoice was issued yet
+func (s *store) InvoiceFindByOrderID(orderID string) (*Invoice, error) {
+	if orderID == "" {
+		return nil, errors.New("order ID is required")
+	}
+
+	invoices, err := s.invoiceList(customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_INVOICE), map[string]string{"order_id": orderID})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range invoices {
+		if invoices[i].Number > 0 {
+			return &invoices[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// InvoiceIssue returns the invoice of the order, issuing it with the next
+// invoice number if the order has no invoice yet.
+//
+// The order is claimed first, so the concurrent issues of the same order
+// (e.g. from the webhook and the return URL of the payment) issue a single
+// invoice. The losing request gets ErrIssuing while the winning one has not
+// numbered the invoice yet.
+func (s *store) InvoiceIssue(orderID string, now time.Time) (*Invoice, error) {
+	existing, err := s.InvoiceFindByOrderID(orderID)
+	if err != nil || existing != nil {
+		return existing, err
+	}
+
+	invoice := &Invoice{
+		OrderID:  orderID,
+		IssuedAt: now.UTC(),
+	}
+
+	payload, err := json.Marshal(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed, err := customrecords.Claim(s.customStore, RECORD_TYPE_INVOICE, orderID, string(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := s.customStore.RecordFindByID(customrecords.ClaimID(RECORD_TYPE_INVOICE, orderID))
+	if err != nil {
+		return nil, err
+	}
+
+	if record == nil {
+		return nil, errors.New("invoice not found")
+	}
+
+	if !claimed {
+		pending, err := invoiceFromRecord(record)
+		if err != nil {
+			return nil, err
+		}
+
+		if pending.Number > 0 {
+			return pending, nil
+		}
+
+		if now.Sub(pending.IssuedAt) < issuingTimeout {
+			return nil, ErrIssuing
+		}
+
+		// The request issuing the invoice failed, issue it now
+		invoice.IssuedAt = pending.IssuedAt
+	}
+
+	invoice.Number, err = s.numberNext()
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err = json.Marshal(invoice)
+	if err != nil {
+		return nil, err
+	}
+
+	record.SetPayload(string(payload))
+
+	if err := s.customStore.RecordUpdate(record); err != nil {
+		return nil, err
+	}
+
+	invoice.ID = record.ID()
+
+	return invoice, nil
+}
+
+// numberNext claims the next free invoice number, from the last number
+// issued. The counter is only where the search starts, the claim of the
+// number is what makes it unique
+func (s *store) numberNext() (int64, error) {
+	counter, lastNumber, err := s.counterFind()
+	if err != nil {
+		return 0, err
+	}
+
+	number := lastNumber + 1
+
+	for {
+		claimed, err := customrecords.Claim(s.customStore, RECORD_TYPE_INVOICE_NUMBER, strconv.FormatInt(number, 10), "")
+		if err != nil {
+			return 0, err
+		}
+
+		if claimed {
+			break
+		}
+
+		number++
+	}
+
+	counter.SetPayload(strconv.FormatInt(number, 10))
+
+	if err := s.customStore.RecordUpdate(counter); err != nil {
+		return 0, err
+	}
+
+	return number, nil
+}
+
+// counterFind returns the record of the counter of the invoice numbers,
+// and the last number issued. The counter is created on the first issue,
+// from the highest number of the invoices issued before it existed
+func (s *store) counterFind() (customstore.RecordInterface, int64, error) {
+	counterID := customrecords.ClaimID(RECORD_TYPE_INVOICE_COUNTER, "last")
+
+	counter, err := s.customStore.RecordFindByID(counterID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if counter == nil {
+		invoices, err := s.invoiceList(customstore.NewRecordQuery().SetType(RECORD_TYPE_INVOICE), nil)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		lastNumber := int64(0)
+		for _, invoice := range invoices {
+			lastNumber = max(lastNumber, invoice.Number)
+		}
+
+		if _, err := customrecords.Claim(s.customStore, RECORD_TYPE_INVOICE_COUNTER, "last", strconv.FormatInt(lastNumber, 10)); err != nil {
+			return nil, 0, err
+		}
+
+		counter, err = s.customStore.RecordFindByID(counterID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if counter == nil {
+			return nil, 0, errors.New("invoice counter not found")
+		}
+	}
+
+	lastNumber, err := strconv.ParseInt(counter.Payload(), 10, 64)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return counter, lastNumber, nil
+}
+
+func (s *store) invoiceList(query customstore.RecordQueryInterface, fields map[string]string) ([]Invoice, error) {
+	records, err := customrecords.FindByPayload(s.customStore, query, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	invoices := []Invoice{}
+
+	for _, record := range records {
+		invoice, err := invoiceFromRecord(record)
+		if err != nil {
+			return nil, err
+		}
+
+		invoices = append(invoices, *invoice)
+	}
+
+	return invoices, nil
+}
+
+func invoiceFromRecord(record customstore.RecordInterface) (*Invoice, error) {
+	invoice := &Invoice{}
+	if err := json.Unmarshal([]byte(record.Payload()), invoice); err != nil {
+		return nil, err
+	}
+
+	invoice.ID = record.ID()
+
+	return invoice, nil
+}
@@ -0,0 +1,180 @@
+package invoice
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"project/internal/customrecords"
+	"project/internal/testutils"
+
+	"github.com/dracory/customstore"
+)
+
+func newTestStore(t *testing.T) StoreInterface {
+	t.Helper()
+
+	store, _ := newTestStores(t)
+
+	return store
+}
+
+func newTestStores(t *testing.T) (StoreInterface, customstore.StoreInterface) {
+	t.Helper()
+
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	store, err := NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store, app.GetCustomStore()
+}
+
+func TestNewStore_RequiresCustomStore(t *testing.T) {
+	if _, err := NewStore(nil); err == nil {
+		t.Error("NewStore(nil) expected error")
+	}
+}
+
+func TestStore_InvoiceIssue_SequentialNumbers(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Now().UTC()
+
+	first, err := store.InvoiceIssue("order_1", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := store.InvoiceIssue("order_2", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Number != 1 || second.Number != 2 {
+		t.Errorf("numbers = %d, %d, want 1, 2", first.Number, second.Number)
+	}
+
+	if first.ID == "" {
+		t.Error("InvoiceIssue() must set the ID")
+	}
+}
+
+func TestStore_InvoiceIssue_Idempotent(t *testing.T) {
+	store := newTestStore(t)
+	issuedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	first, err := store.InvoiceIssue("order_1", issuedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := store.InvoiceIssue("order_1", issuedAt.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again.Number != first.Number || !again.IssuedAt.Equal(issuedAt) {
+		t.Errorf("reissued invoice = %+v, want %+v", again, first)
+	}
+}
+
+func TestStore_InvoiceFindByOrderID(t *testing.T) {
+	store := newTestStore(t)
+
+	missing, err := store.InvoiceFindByOrderID("order_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing != nil {
+		t.Fatal("expected no invoice before it is issued")
+	}
+
+	if _, err := store.InvoiceIssue("order_10", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// order_1 is a prefix of order_10, the exact order must match
+	missing, err = store.InvoiceFindByOrderID("order_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing != nil {
+		t.Fatalf("expected no invoice for order_1, got %+v", missing)
+	}
+
+	found, err := store.InvoiceFindByOrderID("order_10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == nil || found.OrderID != "order_10" {
+		t.Fatalf("expected the invoice of order_10, got %+v", found)
+	}
+}
+
+func TestStore_InvoiceIssue_ContinuesExistingNumbers(t *testing.T) {
+	store, customStore := newTestStores(t)
+
+	// An invoice issued before the counter of the numbers existed
+	payload, err := json.Marshal(Invoice{OrderID: "order_1", Number: 5, IssuedAt: time.Now().UTC()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := customStore.RecordCreate(customstore.NewRecord(RECORD_TYPE_INVOICE, customstore.WithPayload(string(payload)))); err != nil {
+		t.Fatal(err)
+	}
+
+	// The number 6 was claimed by an invoice which failed to be saved
+	if _, err := customrecords.Claim(customStore, RECORD_TYPE_INVOICE_NUMBER, "6", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	issued, err := store.InvoiceIssue("order_2", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if issued.Number != 7 {
+		t.Errorf("expected the next free number 7, got %d", issued.Number)
+	}
+}
+
+func TestStore_InvoiceIssue_BeingIssued(t *testing.T) {
+	store, customStore := newTestStores(t)
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// Another request claimed the order, and has not numbered the invoice yet
+	payload, err := json.Marshal(Invoice{OrderID: "order_1", IssuedAt: startedAt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := customrecords.Claim(customStore, RECORD_TYPE_INVOICE, "order_1", string(payload)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.InvoiceIssue("order_1", startedAt.Add(time.Second)); !errors.Is(err, ErrIssuing) {
+		t.Fatalf("expected ErrIssuing, got %v", err)
+	}
+
+	missing, err := store.InvoiceFindByOrderID("order_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing != nil {
+		t.Fatalf("expected no invoice while it is being issued, got %+v", missing)
+	}
+
+	// The other request failed, the invoice is issued with its date
+	issued, err := store.InvoiceIssue("order_1", startedAt.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if issued.Number != 1 || !issued.IssuedAt.Equal(startedAt) {
+		t.Errorf("expected invoice 1 issued at %v, got %+v", startedAt, issued)
+	}
+}
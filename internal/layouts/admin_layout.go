@@ -52,6 +52,10 @@ func adminLayout(app app.AppInterface, r *http.Request, options Options) dashboa
 	scripts := []string{} // prepend any if required
 	scripts = append(scripts, options.Scripts...)
 
+	if nonceScript := cspNonceScript(r); nonceScript != "" {
+		scripts = append(scripts, nonceScript)
+	}
+
 	styleURLs := []string{} // prepend any if required
 	styleURLs = append(styleURLs, options.StyleURLs...)
 	styleURLs = lo.Uniq(styleURLs)
@@ -111,5 +115,5 @@ func adminLayout(app app.AppInterface, r *http.Request, options Options) dashboa
 	template.SetStyleURLs(styleURLs)
 	template.SetFaviconURL(FaviconURL())
 
-	return template
+	return &cspDashboard{DashboardInterface: template, request: r}
 }
@@ -48,14 +48,32 @@ func NewBlankLayout(app app.AppInterface, r *http.Request, options Options) *bla
 
 // ToHTML generates the HTML for the guest layout
 func (layout *blankLayout) ToHTML() string {
-	layout.styleURLs = append([]string{cdn.BootstrapCss_5_3_3()}, layout.styleURLs...)
+	content := ""
+	if layout.content != nil {
+		content = layout.content.ToHTML()
+	}
+
+	return cspStampLayout(layout.r, content, layout.title, layout.render)
+}
+
+// == PRIVATE METHODS =========================================================
+
+// render generates the HTML with the content and the title given
+func (layout *blankLayout) render(content string, title string) string {
+	styleURLs := append([]string{cdn.BootstrapCss_5_3_3()}, layout.styleURLs...)
+	scripts := append([]string{}, layout.scripts...)
+
+	if nonceScript := cspNonceScript(layout.r); nonceScript != "" {
+		scripts = append(scripts, nonceScript)
+	}
+
 	webpage := hb.Webpage().
-		SetTitle(layout.title).
+		SetTitle(title).
 		SetFavicon(FaviconURL()).
 		AddStyles(layout.styles).
-		AddStyleURLs(layout.styleURLs).
-		AddScripts(layout.scripts).
+		AddStyleURLs(styleURLs).
+		AddScripts(scripts).
 		AddScriptURLs(layout.scriptURLs).
-		AddChild(layout.content)
+		AddChild(hb.Raw(content))
 	return webpage.ToHTML()
 }
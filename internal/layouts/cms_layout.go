@@ -66,54 +66,67 @@ func (layout *cmsLayout) ToHTML() string {
 		Logger: layout.app.GetLogger(),
 	})
 
-	pageContent := ""
-
-	for _, styleURL := range layout.styleURLs {
-		pageContent += "<link rel='stylesheet' href='" + styleURL + "'>"
-	}
-
-	for _, style := range layout.styles {
-		pageContent += "<style>" + style + "</style>"
-	}
-
-	pageContent += layout.content.ToHTML()
-
-	for _, script := range layout.scripts {
-		pageContent += "<script>" + script + "</script>"
+	content := ""
+	if layout.content != nil {
+		content = layout.content.ToHTML()
 	}
 
-	for _, scriptURL := range layout.scriptURLs {
-		pageContent += "<script src='" + scriptURL + "'></script>"
-	}
-
-	html, err := fe.TemplateRenderHtmlByID(
-		layout.request,
-		layout.app.GetConfig().GetCmsStoreTemplateID(),
-		struct {
-			PageContent         string
-			PageCanonicalURL    string
-			PageMetaDescription string
-			PageMetaKeywords    string
-			PageMetaRobots      string
-			PageTitle           string
-			Language            string
-		}{
-			PageContent:         pageContent,
-			PageCanonicalURL:    "",
-			PageMetaDescription: "",
-			PageMetaKeywords:    "",
-			PageMetaRobots:      "",
-			PageTitle:           layout.title,
-			Language:            "en",
-		})
-
-	if err != nil {
-		layout.app.GetLogger().Error(
-			"At CmsLayout",
-			slog.Any("error", err),
-			slog.Any("template_id", layout.app.GetConfig().GetCmsStoreTemplateID()))
-		return "Template (" + layout.app.GetConfig().GetCmsStoreTemplateID() + ") error. Please try again later"
-	}
-
-	return html
+	// The CMS template is authored by the administrators, so its scripts
+	// are stamped with the nonce, unlike the ones injected into the content
+	return cspStampLayout(layout.request, content, layout.title, func(content string, title string) string {
+		pageContent := ""
+
+		for _, styleURL := range layout.styleURLs {
+			pageContent += "<link rel='stylesheet' href='" + styleURL + "'>"
+		}
+
+		for _, style := range layout.styles {
+			pageContent += "<style>" + style + "</style>"
+		}
+
+		pageContent += content
+
+		for _, script := range layout.scripts {
+			pageContent += "<script>" + script + "</script>"
+		}
+
+		for _, scriptURL := range layout.scriptURLs {
+			pageContent += "<script src='" + scriptURL + "'></script>"
+		}
+
+		if nonceScript := cspNonceScript(layout.request); nonceScript != "" {
+			pageContent += "<script>" + nonceScript + "</script>"
+		}
+
+		html, err := fe.TemplateRenderHtmlByID(
+			layout.request,
+			layout.app.GetConfig().GetCmsStoreTemplateID(),
+			struct {
+				PageContent         string
+				PageCanonicalURL    string
+				PageMetaDescription string
+				PageMetaKeywords    string
+				PageMetaRobots      string
+				PageTitle           string
+				Language            string
+			}{
+				PageContent:         pageContent,
+				PageCanonicalURL:    "",
+				PageMetaDescription: "",
+				PageMetaKeywords:    "",
+				PageMetaRobots:      "",
+				PageTitle:           title,
+				Language:            "en",
+			})
+
+		if err != nil {
+			layout.app.GetLogger().Error(
+				"At CmsLayout",
+				slog.Any("error", err),
+				slog.Any("template_id", layout.app.GetConfig().GetCmsStoreTemplateID()))
+			return "Template (" + layout.app.GetConfig().GetCmsStoreTemplateID() + ") error. Please try again later"
+		}
+
+		return html
+	})
 }
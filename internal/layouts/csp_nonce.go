@@ -0,0 +1,99 @@
+package layouts
+
+import (
+	"encoding/json"
+	"net/http"
+	"project/internal/helpers"
+	"strings"
+
+	dashboardTypes "github.com/dracory/dashboard/types"
+)
+
+// Placeholders the content and the title are rendered as, while the nonce
+// is stamped on the scripts and styles of the layout
+const (
+	cspContentPlaceholder = "<!-- CSP_CONTENT_PLACEHOLDER -->"
+	cspTitlePlaceholder   = "CSP_TITLE_PLACEHOLDER"
+)
+
+// cspStampLayout stamps the nonce of the request on the scripts and styles
+// of the layout.
+//
+// The layout is rendered with placeholders for the content and the title,
+// which are put back after the stamping, so that any scripts injected into
+// them are not run. The content stamps its own scripts with
+// helpers.CSPNonceStampTag.
+func cspStampLayout(r *http.Request, content string, title string, render func(content string, title string) string) string {
+	if r == nil || helpers.GetCSPNonce(r.Context()) == "" {
+		return render(content, title)
+	}
+
+	html := helpers.CSPNonceStampHTML(r.Context(), render(cspContentPlaceholder, cspTitlePlaceholder))
+	html = strings.ReplaceAll(html, cspTitlePlaceholder, title)
+	return strings.Replace(html, cspContentPlaceholder, content, 1)
+}
+
+// cspNonceScript returns the script, which lets the client libraries
+// stamp the nonce on the scripts they load:
+//   - htmx stamps it on the scripts of the swapped content
+//   - Liveflux sends it back with the actions (see the security headers
+//     middleware), so that the server stamps it on the scripts of the
+//     components
+//
+// It also asks for a confirmation before submitting the forms with a
+// data-confirm attribute, as the inline event handlers are not allowed.
+func cspNonceScript(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+
+	nonce := helpers.GetCSPNonce(r.Context())
+
+	if nonce == "" {
+		return ""
+	}
+
+	nonceJSON, _ := json.Marshal(nonce)
+
+	return `(function () {
+	var nonce = ` + string(nonceJSON) + `;
+	if (window.htmx) {
+		window.htmx.config.inlineScriptNonce = nonce;
+		window.htmx.config.inlineStyleNonce = nonce;
+	}
+	if (window.liveflux) {
+		window.liveflux.headers = Object.assign(window.liveflux.headers || {}, {"X-CSP-Nonce": nonce});
+	}
+	document.addEventListener("submit", function (event) {
+		var message = event.target.getAttribute("data-confirm");
+		if (message && !window.confirm(message)) {
+			event.preventDefault();
+		}
+	});
+})();`
+}
+
+// cspDashboard stamps the nonce of the request on the scripts and styles
+// of the dashboard, when rendered
+type cspDashboard struct {
+	dashboardTypes.DashboardInterface
+	request *http.Request
+}
+
+// ToHTML renders the dashboard with the nonce stamped
+func (d *cspDashboard) ToHTML() string {
+	content := d.GetContent()
+	title := d.GetTitle()
+
+	return cspStampLayout(d.request, content, title, func(contentPlaceholder string, titlePlaceholder string) string {
+		d.SetContent(contentPlaceholder)
+		d.SetTitle(titlePlaceholder)
+
+		defer func() {
+			d.SetContent(content)
+			d.SetTitle(title)
+		}()
+
+		return d.DashboardInterface.ToHTML()
+	})
+}
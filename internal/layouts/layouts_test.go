@@ -1,10 +1,13 @@
 package layouts
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"project/internal/config"
 	"project/internal/testutils"
 
 	"github.com/dracory/hb"
@@ -245,3 +248,26 @@ func TestNewAdminCrudLayout(t *testing.T) {
 		t.Error("NewAdminCrudLayout() should contain the title")
 	}
 }
+
+func TestNewBlankLayout_CSPNonce(t *testing.T) {
+	app := testutils.Setup()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(context.WithValue(r.Context(), config.CSPNonceContextKey{}, "abc123"))
+	opts := Options{
+		Title:   "Test",
+		Content: hb.Raw(`<script>alert('content');</script>`),
+		Scripts: []string{"alert('test');"},
+	}
+
+	html := NewBlankLayout(app, r, opts).ToHTML()
+
+	if !strings.Contains(html, `<script nonce="abc123">alert('test');`) {
+		t.Error("ToHTML() should stamp the nonce on the scripts of the layout")
+	}
+	if !strings.Contains(html, `<script>alert('content');</script>`) {
+		t.Error("ToHTML() should not stamp the nonce on the scripts of the content")
+	}
+	if !strings.Contains(html, `htmx.config.inlineScriptNonce`) {
+		t.Error("ToHTML() should pass the nonce to the client libraries")
+	}
+}
@@ -65,6 +65,16 @@ func NewPageLayout(app app.AppInterface, r *http.Request, options Options) Layou
 
 // ToHTML generates the complete HTML page.
 func (layout *pageLayout) ToHTML() string {
+	content := ""
+	if layout.content != nil {
+		content = layout.content.ToHTML()
+	}
+
+	return cspStampLayout(layout.request, content, layout.title, layout.render)
+}
+
+// render generates the HTML page with the content and the title given
+func (layout *pageLayout) render(content string, title string) string {
 	styleURLs := append([]string{
 		cdn.BootstrapCss_5_3_3(),
 		cdn.BootstrapIconsCss_1_13_1(),
@@ -98,7 +108,7 @@ func (layout *pageLayout) ToHTML() string {
 	});`
 
 	webpage := hb.Webpage().
-		SetTitle(layout.title).
+		SetTitle(title).
 		SetFavicon(FaviconURL()).
 		AddStyleURLs(styleURLs).
 		AddStyles(layout.styles).
@@ -109,6 +119,7 @@ func (layout *pageLayout) ToHTML() string {
 
 	if layout.app != nil && layout.app.GetConfig() != nil && layout.app.GetConfig().IsEnvProduction() {
 		ga4ID := "G-4ZHR10WN2P"
+		currency := layout.app.GetConfig().GetPaymentCurrency()
 		ga4Script := `(function(){
 	var ga4Id = '` + ga4ID + `';
 	var consent = localStorage.getItem('cf-cookie-consent');
@@ -126,7 +137,7 @@ func (layout *pageLayout) ToHTML() string {
 		if (p.get('ga_purchase')) {
 			gtag('event', 'purchase', {
 				value: parseFloat(p.get('ga_purchase')),
-				currency: 'GBP',
+				currency: '` + currency + `',
 				transaction_id: p.get('ga_order') || ''
 			});
 		}
@@ -166,6 +177,10 @@ func (layout *pageLayout) ToHTML() string {
 		webpage.AddScripts([]string{ga4Script})
 	}
 
+	if nonceScript := cspNonceScript(layout.request); nonceScript != "" {
+		webpage.AddScripts([]string{nonceScript})
+	}
+
 	if layout.metaDescription != "" {
 		webpage.AddChild(hb.Meta().Attr("name", "description").Attr("content", layout.metaDescription))
 	}
@@ -179,7 +194,7 @@ func (layout *pageLayout) ToHTML() string {
 		Child(hb.Div().
 			Class("container").
 			Style("max-width: 1200px;").
-			Child(layout.content))
+			Child(hb.Raw(content)))
 
 	footer := hb.Footer().
 		Class("cf-footer py-4 mt-auto").
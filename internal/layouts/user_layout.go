@@ -57,6 +57,10 @@ func userLayout(app app.AppInterface, r *http.Request, options Options) dashboar
 	scripts := []string{} // prepend any if required
 	scripts = append(scripts, options.Scripts...)
 
+	if nonceScript := cspNonceScript(r); nonceScript != "" {
+		scripts = append(scripts, nonceScript)
+	}
+
 	// Prepare styles
 	styles := []string{ // prepend any if required
 		`a.navbar-brand{font-size:18px;}`,
@@ -111,5 +115,5 @@ func userLayout(app app.AppInterface, r *http.Request, options Options) dashboar
 	dashboard.SetStyles(styles)
 	dashboard.SetStyleURLs(options.StyleURLs)
 
-	return dashboard
+	return &cspDashboard{DashboardInterface: dashboard, request: r}
 }
@@ -40,6 +40,36 @@ func userLayoutMainMenuItems(user userstore.UserInterface) []dashboardTypes.Menu
 		URL:   links.User().Profile(),
 	}
 
+	twoFactorMenuItem := dashboardTypes.MenuItem{
+		Icon:  hb.I().Class("bi bi-shield-lock").Style("margin-right:10px;").ToHTML(),
+		Title: "Two-Factor Auth",
+		URL:   links.User().TwoFactor(),
+	}
+
+	sessionsMenuItem := dashboardTypes.MenuItem{
+		Icon:  hb.I().Class("bi bi-laptop").Style("margin-right:10px;").ToHTML(),
+		Title: "Devices & Sessions",
+		URL:   links.User().Sessions(),
+	}
+
+	apiTokensMenuItem := dashboardTypes.MenuItem{
+		Icon:  hb.I().Class("bi bi-key").Style("margin-right:10px;").ToHTML(),
+		Title: "API Tokens",
+		URL:   links.User().APITokens(),
+	}
+
+	ordersMenuItem := dashboardTypes.MenuItem{
+		Icon:  hb.I().Class("bi bi-bag").Style("margin-right:10px;").ToHTML(),
+		Title: "Orders",
+		URL:   links.User().Orders(),
+	}
+
+	billingMenuItem := dashboardTypes.MenuItem{
+		Icon:  hb.I().Class("bi bi-credit-card").Style("margin-right:10px;").ToHTML(),
+		Title: "Billing",
+		URL:   links.User().Subscription(),
+	}
+
 	// shopMenuItem := dashboard.MenuItem{
 	// 	Icon:  hb.I().Class("bi bi-shop").Style("margin-right:10px;").ToHTML(),
 	// 	Title: "Your Shop",
@@ -76,7 +106,12 @@ func userLayoutMainMenuItems(user userstore.UserInterface) []dashboardTypes.Menu
 	if user != nil {
 		menuItems = append(menuItems, dashboardMenuItem)
 		// menuItems = append(menuItems, shopMenuItem)
+		menuItems = append(menuItems, ordersMenuItem)
+		menuItems = append(menuItems, billingMenuItem)
 		menuItems = append(menuItems, profileMenuItem)
+		menuItems = append(menuItems, twoFactorMenuItem)
+		menuItems = append(menuItems, sessionsMenuItem)
+		menuItems = append(menuItems, apiTokensMenuItem)
 		// menuItems = append(menuItems, inviteFriendMenuItem)
 		menuItems = append(menuItems, websiteMenuItem)
 		menuItems = append(menuItems, logoutMenuItem)
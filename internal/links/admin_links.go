@@ -13,6 +13,12 @@ func (l *adminLinks) Home(params ...map[string]string) string {
 	return URL(ADMIN_HOME, p)
 }
 
+// APITokens is the personal API tokens manager
+func (l *adminLinks) APITokens(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(ADMIN_API_TOKENS, p)
+}
+
 func (l *adminLinks) Blog(params ...map[string]string) string {
 	p := lo.FirstOr(params, map[string]string{})
 	return URL(ADMIN_BLOG, p)
@@ -36,12 +42,26 @@ func (l *adminLinks) FileManager(params ...map[string]string) string {
 	return URL(ADMIN_FILE_MANAGER, p)
 }
 
+// Lockouts is the list of the accounts and IP addresses locked out after
+// too many failed login attempts
+func (l *adminLinks) Lockouts(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(ADMIN_LOCKOUTS, p)
+}
+
 // Logs is the logs manager
 func (l *adminLinks) Logs(params ...map[string]string) string {
 	p := lo.FirstOr(params, map[string]string{})
 	return URL(ADMIN_LOGS, p)
 }
 
+// MCPClients is the manager of the MCP clients, and the tools they may
+// call
+func (l *adminLinks) MCPClients(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(ADMIN_MCP_CLIENTS, p)
+}
+
 func (l *adminLinks) MediaManager(params ...map[string]string) string {
 	p := lo.FirstOr(params, map[string]string{})
 	return URL(ADMIN_MEDIA, p)
@@ -52,6 +72,12 @@ func (l *adminLinks) Shop(params ...map[string]string) string {
 	return URL(ADMIN_SHOP, p)
 }
 
+// ShopDiscounts is the discount codes manager
+func (l *adminLinks) ShopDiscounts(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(ADMIN_SHOP_DISCOUNTS, p)
+}
+
 func (l *adminLinks) Stats(params ...map[string]string) string {
 	p := lo.FirstOr(params, map[string]string{})
 	return URL(ADMIN_STATS, p)
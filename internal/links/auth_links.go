@@ -42,3 +42,78 @@ func (l *authLinks) Register(params ...map[string]string) string {
 	p := lo.FirstOr(params, map[string]string{})
 	return URL(AUTH_REGISTER, p)
 }
+
+// Signup is the email/password registration, when password auth is enabled
+func (l *authLinks) Signup(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(AUTH_SIGNUP, p)
+}
+
+func (l *authLinks) PasswordForgot(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(AUTH_PASSWORD_FORGOT, p)
+}
+
+func (l *authLinks) PasswordReset(token string) string {
+	return URL(AUTH_PASSWORD_RESET, map[string]string{"token": token})
+}
+
+func (l *authLinks) EmailVerify(token string) string {
+	return URL(AUTH_EMAIL_VERIFY, map[string]string{"token": token})
+}
+
+// MagicLink is the form to request a login link by email
+func (l *authLinks) MagicLink(backUrl string) string {
+	p := map[string]string{}
+
+	if backUrl != "" {
+		p["back_url"] = backUrl
+	}
+
+	return URL(AUTH_MAGIC_LINK, p)
+}
+
+// MagicLinkLogin is the login link sent by email
+func (l *authLinks) MagicLinkLogin(token string, backUrl string) string {
+	p := map[string]string{"token": token}
+
+	if backUrl != "" {
+		p["back_url"] = backUrl
+	}
+
+	return URL(AUTH_MAGIC_LINK_LOGIN, p)
+}
+
+// TwoFactor is the two-factor authentication step of the login, after
+// the identity of the user was verified
+func (l *authLinks) TwoFactor(token string, backUrl string) string {
+	p := map[string]string{"token": token}
+
+	if backUrl != "" {
+		p["back_url"] = backUrl
+	}
+
+	return URL(AUTH_TWO_FACTOR, p)
+}
+
+// OAuth starts the login with a social login provider, or without a
+// provider lists the providers to choose from
+func (l *authLinks) OAuth(provider string, backUrl string) string {
+	p := map[string]string{}
+
+	if provider != "" {
+		p["provider"] = provider
+	}
+
+	if backUrl != "" {
+		p["back_url"] = backUrl
+	}
+
+	return URL(AUTH_OAUTH, p)
+}
+
+// OAuthCallback is the redirect URL registered with the social login
+// providers
+func (l *authLinks) OAuthCallback() string {
+	return URL(AUTH_OAUTH_CALLBACK, map[string]string{})
+}
@@ -10,21 +10,34 @@ const AUTH_AUTH = "/auth/auth"
 const AUTH_LOGIN = "/auth/login"
 const AUTH_LOGOUT = "/auth/logout"
 const AUTH_REGISTER = "/auth/register"
+const AUTH_SIGNUP = "/auth/signup"
+const AUTH_PASSWORD_FORGOT = "/auth/password-forgot"
+const AUTH_PASSWORD_RESET = "/auth/password-reset"
+const AUTH_EMAIL_VERIFY = "/auth/email-verify"
+const AUTH_MAGIC_LINK = "/auth/magic-link"
+const AUTH_MAGIC_LINK_LOGIN = "/auth/magic-link/login"
+const AUTH_TWO_FACTOR = "/auth/two-factor"
+const AUTH_OAUTH = "/auth/oauth"
+const AUTH_OAUTH_CALLBACK = "/auth/oauth/callback"
 
 // ===========================================================================
 // == ADMIN LINKS
 // ===========================================================================
 
 const ADMIN_HOME = "/admin"
+const ADMIN_API_TOKENS = ADMIN_HOME + "/api-tokens"
 const ADMIN_BLOG = ADMIN_HOME + "/blog"
 const ADMIN_CHAT = ADMIN_HOME + "/chat"
 const ADMIN_CHAT_RAG = ADMIN_HOME + "/chat/rag"
 const ADMIN_CMS = ADMIN_HOME + "/cms"
 const ADMIN_CMS_OLD = ADMIN_HOME + "/cmsold"
 const ADMIN_FILE_MANAGER = ADMIN_HOME + "/file-manager"
+const ADMIN_LOCKOUTS = ADMIN_HOME + "/lockouts"
 const ADMIN_LOGS = ADMIN_HOME + "/logs"
+const ADMIN_MCP_CLIENTS = ADMIN_HOME + "/mcp-clients"
 const ADMIN_MEDIA = ADMIN_HOME + "/media"
 const ADMIN_SHOP = ADMIN_HOME + "/shop"
+const ADMIN_SHOP_DISCOUNTS = ADMIN_SHOP + "/discounts"
 const ADMIN_STATS = ADMIN_HOME + "/stats"
 const ADMIN_TASKS = ADMIN_HOME + "/tasks"
 const ADMIN_USERS = ADMIN_HOME + "/users"
@@ -35,6 +48,7 @@ const ADMIN_USERS_USER_IMPERSONATE = ADMIN_USERS + "/user-impersonate"
 // ===========================================================================
 
 const USER_HOME = "/user"
+const USER_API_TOKENS = USER_HOME + "/api-tokens"
 
 // User Orders
 const USER_ORDERS = USER_HOME + "/orders"
@@ -42,8 +56,13 @@ const USER_ORDER_CREATE = USER_ORDERS + "/create"
 const USER_ORDER_CREATE_PAYMENT_BEGIN = USER_ORDER_CREATE + "/payment-begin"
 const USER_ORDER_DELETE = USER_ORDERS + "/delete"
 const USER_ORDER_LIST = USER_ORDERS + "/list"
+const USER_ORDER_VIEW = USER_ORDERS + "/view"
+const USER_ORDER_INVOICE = USER_ORDERS + "/invoice"
 
 const USER_PROFILE = USER_HOME + "/profile"
+const USER_TWO_FACTOR = USER_HOME + "/two-factor"
+const USER_CONNECTED_ACCOUNTS = USER_PROFILE + "/connected-accounts"
+const USER_SESSIONS = USER_HOME + "/sessions"
 
 // User Subscription
 const USER_SUBSCRIPTION = USER_HOME + "/subscription"
@@ -62,8 +81,21 @@ const CHAT_HOME = "/chat"
 // == MCP LINKS
 // ===========================================================================
 
-const MCP_CMS = "/mcp/cms"
-const MCP_BLOG = "/mcp/blog"
+// MCP is the endpoint with the tools of all the providers, MCP_BLOG and
+// MCP_CMS the endpoints with the blog and CMS tools only
+const MCP = "/mcp"
+const MCP_CMS = MCP + "/cms"
+const MCP_BLOG = MCP + "/blog"
+
+// ===========================================================================
+// == API LINKS
+// ===========================================================================
+
+const API = "/api"
+const API_INTERNAL_WEBHOOK = API + "/internal/webhook"
+const API_INTERNAL_CSP_REPORT = API + "/internal/csp-report"
+const API_V1 = API + "/v1"
+const API_V1_OPENAPI = API_V1 + "/openapi.json"
 
 // ===========================================================================
 // == WEBSITE LINKS
@@ -94,7 +126,14 @@ const SHOP_CART_ADD = SHOP_CART + "/add"
 const SHOP_CART_REMOVE = SHOP_CART + "/remove"
 const SHOP_CART_UPDATE = SHOP_CART + "/update"
 const SHOP_CART_API = SHOP_CART + "/api"
+const SHOP_CART_DISCOUNT = SHOP_CART + "/discount"
+const SHOP_CART_SHIPPING = SHOP_CART + "/shipping"
 const SHOP_CHECKOUT = SHOP + "/checkout"
+const SHOP_ORDER = SHOP + "/order"
+const SHOP_ORDER_CONFIRMATION = SHOP_ORDER + "/confirmation"
+const SHOP_CATEGORY = SHOP + "/category"
+const SHOP_CATEGORY_WITH_REGEX = SHOP_CATEGORY + "/{id:[0-9]+}"
+const SHOP_CATEGORY_WITH_REGEX2 = SHOP_CATEGORY + "/{id:[0-9]+}/{title}"
 const SHOP_PRODUCT = SHOP + "/product"
 const SHOP_PRODUCT_WITH_REGEX = SHOP_PRODUCT + "/{id:[0-9]+}"
 const SHOP_PRODUCT_WITH_REGEX2 = SHOP_PRODUCT + "/{id:[0-9]+}/{title}"
@@ -114,6 +114,46 @@ func TestAdminLinks_Shop(t *testing.T) {
 	}
 }
 
+func TestAdminLinks_ShopDiscounts(t *testing.T) {
+	t.Setenv("APP_ENV", "testing")
+	t.Setenv("APP_URL", "")
+	admin := Admin()
+	result := admin.ShopDiscounts()
+	if !strings.Contains(result, "/admin/shop/discounts") {
+		t.Errorf("ShopDiscounts() = %q, should contain /admin/shop/discounts", result)
+	}
+}
+
+func TestAdminLinks_APITokens(t *testing.T) {
+	t.Setenv("APP_ENV", "testing")
+	t.Setenv("APP_URL", "")
+	admin := Admin()
+	result := admin.APITokens()
+	if !strings.Contains(result, "/admin/api-tokens") {
+		t.Errorf("APITokens() = %q, should contain /admin/api-tokens", result)
+	}
+}
+
+func TestAdminLinks_MCPClients(t *testing.T) {
+	t.Setenv("APP_ENV", "testing")
+	t.Setenv("APP_URL", "")
+	admin := Admin()
+	result := admin.MCPClients()
+	if !strings.Contains(result, "/admin/mcp-clients") {
+		t.Errorf("MCPClients() = %q, should contain /admin/mcp-clients", result)
+	}
+}
+
+func TestAdminLinks_Lockouts(t *testing.T) {
+	t.Setenv("APP_ENV", "testing")
+	t.Setenv("APP_URL", "")
+	admin := Admin()
+	result := admin.Lockouts()
+	if !strings.Contains(result, "/admin/lockouts") {
+		t.Errorf("Lockouts() = %q, should contain /admin/lockouts", result)
+	}
+}
+
 func TestAdminLinks_Stats(t *testing.T) {
 	t.Setenv("APP_ENV", "testing")
 	t.Setenv("APP_URL", "")
@@ -252,6 +292,26 @@ func TestWebsiteLinks_Shop(t *testing.T) {
 	}
 }
 
+func TestWebsiteLinks_ShopCartDiscount(t *testing.T) {
+	t.Setenv("APP_ENV", "testing")
+	t.Setenv("APP_URL", "")
+	website := Website()
+	result := website.ShopCartDiscount()
+	if !strings.Contains(result, "/shop/cart/discount") {
+		t.Errorf("ShopCartDiscount() = %q, should contain /shop/cart/discount", result)
+	}
+}
+
+func TestWebsiteLinks_ShopCartShipping(t *testing.T) {
+	t.Setenv("APP_ENV", "testing")
+	t.Setenv("APP_URL", "")
+	website := Website()
+	result := website.ShopCartShipping()
+	if !strings.Contains(result, "/shop/cart/shipping") {
+		t.Errorf("ShopCartShipping() = %q, should contain /shop/cart/shipping", result)
+	}
+}
+
 func TestWebsiteLinks_SitemapXml(t *testing.T) {
 	t.Setenv("APP_ENV", "testing")
 	t.Setenv("APP_URL", "")
@@ -328,6 +388,32 @@ func TestWebsiteLinks_File(t *testing.T) {
 	}
 }
 
+func TestWebsiteLinks_FileSigned(t *testing.T) {
+	t.Setenv("APP_ENV", "testing")
+	t.Setenv("APP_URL", "")
+
+	website := Website()
+	expiresAt := time.Now().Add(time.Hour)
+
+	result := website.FileSigned("private/invoice.pdf", "test-signing-key", expiresAt, "user-1")
+	if !strings.Contains(result, "/files/private/invoice.pdf?") {
+		t.Errorf("FileSigned() should contain the file path, got %q", result)
+	}
+	for _, param := range []string{"expires=", "user=user-1", "signature="} {
+		if !strings.Contains(result, param) {
+			t.Errorf("FileSigned() should contain %q, got %q", param, result)
+		}
+	}
+
+	if result := website.MediaSigned("/private/invoice.pdf", "test-signing-key", expiresAt, ""); !strings.Contains(result, "/media/private/invoice.pdf?") || strings.Contains(result, "user=") {
+		t.Errorf("MediaSigned() should contain the media path without user, got %q", result)
+	}
+
+	if result := website.FileSigned("private/invoice.pdf", "", expiresAt, ""); result != "" {
+		t.Errorf("FileSigned() without key should return empty string, got %q", result)
+	}
+}
+
 func TestWebsiteLinks_Flash(t *testing.T) {
 	t.Setenv("APP_ENV", "testing")
 	t.Setenv("APP_URL", "")
@@ -376,6 +462,36 @@ func TestWebsiteLinks_ShopProduct(t *testing.T) {
 	}
 }
 
+func TestWebsiteLinks_ShopCategory(t *testing.T) {
+	t.Setenv("APP_ENV", "testing")
+	t.Setenv("APP_URL", "")
+
+	website := Website()
+
+	result := website.ShopCategory("123", "books", nil)
+	if !strings.Contains(result, SHOP_CATEGORY+"/123/books") {
+		t.Errorf("ShopCategory() = %q, should contain %q", result, SHOP_CATEGORY+"/123/books")
+	}
+
+	result = website.ShopCategory("456", "", map[string]string{"page": "1"})
+	if !strings.Contains(result, SHOP_CATEGORY+"/456") || !strings.Contains(result, "page=1") {
+		t.Errorf("ShopCategory() = %q, should contain the category ID and the params", result)
+	}
+}
+
+func TestWebsiteLinks_ShopOrderConfirmation(t *testing.T) {
+	t.Setenv("APP_ENV", "testing")
+	t.Setenv("APP_URL", "")
+
+	result := Website().ShopOrderConfirmation("ORD123")
+	if !strings.Contains(result, SHOP_ORDER_CONFIRMATION) {
+		t.Errorf("ShopOrderConfirmation() = %q, should contain %q", result, SHOP_ORDER_CONFIRMATION)
+	}
+	if !strings.Contains(result, "order_id=ORD123") {
+		t.Errorf("ShopOrderConfirmation() = %q, should contain order ID", result)
+	}
+}
+
 func TestWebsiteLinks_Payment(t *testing.T) {
 	t.Setenv("APP_ENV", "testing")
 	t.Setenv("APP_URL", "")
@@ -517,6 +633,41 @@ func TestWebsiteLinks_Thumbnail_WithDataURI(t *testing.T) {
 // User Links Tests
 // ============================================================================
 
+func TestUserLinks_Orders(t *testing.T) {
+	t.Setenv("APP_ENV", "testing")
+	t.Setenv("APP_URL", "")
+	user := User()
+
+	if result := user.Orders(); !strings.Contains(result, USER_ORDERS) {
+		t.Errorf("Orders() = %q, should contain %q", result, USER_ORDERS)
+	}
+
+	result := user.OrderView("ORD123")
+	if !strings.Contains(result, USER_ORDER_VIEW) || !strings.Contains(result, "order_id=ORD123") {
+		t.Errorf("OrderView() = %q, should contain %q and the order ID", result, USER_ORDER_VIEW)
+	}
+
+	result = user.OrderInvoice("ORD123")
+	if !strings.Contains(result, USER_ORDER_INVOICE) || !strings.Contains(result, "order_id=ORD123") {
+		t.Errorf("OrderInvoice() = %q, should contain %q and the order ID", result, USER_ORDER_INVOICE)
+	}
+}
+
+func TestUserLinks_Subscription(t *testing.T) {
+	t.Setenv("APP_ENV", "testing")
+	t.Setenv("APP_URL", "")
+	user := User()
+
+	if result := user.Subscription(); !strings.Contains(result, USER_SUBSCRIPTION) {
+		t.Errorf("Subscription() = %q, should contain %q", result, USER_SUBSCRIPTION)
+	}
+
+	result := user.Subscription(map[string]string{"feature": "api_access"})
+	if !strings.Contains(result, "feature=api_access") {
+		t.Errorf("Subscription() = %q, should contain the feature", result)
+	}
+}
+
 func TestUserLinks_Subscriptions_PlanSelect(t *testing.T) {
 	t.Setenv("APP_ENV", "testing")
 	t.Setenv("APP_URL", "")
@@ -145,6 +145,60 @@ func TestAuthLinks(t *testing.T) {
 		t.Error("auth.Register() should return non-empty string")
 	}
 
+	// Test Signup()
+	result = auth.Signup()
+	if !strings.Contains(result, AUTH_SIGNUP) {
+		t.Error("auth.Signup() should contain the signup path")
+	}
+
+	// Test PasswordForgot()
+	result = auth.PasswordForgot()
+	if !strings.Contains(result, AUTH_PASSWORD_FORGOT) {
+		t.Error("auth.PasswordForgot() should contain the forgot password path")
+	}
+
+	// Test PasswordReset()
+	result = auth.PasswordReset("abc123")
+	if !strings.Contains(result, AUTH_PASSWORD_RESET) || !strings.Contains(result, "token=abc123") {
+		t.Error("auth.PasswordReset() should contain the reset path and the token, got: " + result)
+	}
+
+	// Test EmailVerify()
+	result = auth.EmailVerify("abc123")
+	if !strings.Contains(result, AUTH_EMAIL_VERIFY) || !strings.Contains(result, "token=abc123") {
+		t.Error("auth.EmailVerify() should contain the verify path and the token, got: " + result)
+	}
+
+	// Test MagicLink()
+	result = auth.MagicLink("")
+	if !strings.Contains(result, AUTH_MAGIC_LINK) || strings.Contains(result, "back_url") {
+		t.Error("auth.MagicLink() should contain the magic link path only, got: " + result)
+	}
+
+	// Test MagicLinkLogin()
+	result = auth.MagicLinkLogin("abc123", "/back")
+	if !strings.Contains(result, AUTH_MAGIC_LINK_LOGIN) || !strings.Contains(result, "token=abc123") || !strings.Contains(result, "back_url=") {
+		t.Error("auth.MagicLinkLogin() should contain the login path, the token and the back URL, got: " + result)
+	}
+
+	// Test TwoFactor()
+	result = auth.TwoFactor("abc123", "")
+	if !strings.Contains(result, AUTH_TWO_FACTOR) || !strings.Contains(result, "token=abc123") || strings.Contains(result, "back_url") {
+		t.Error("auth.TwoFactor() should contain the two-factor path and the token, got: " + result)
+	}
+
+	// Test OAuth()
+	result = auth.OAuth("github", "")
+	if !strings.Contains(result, AUTH_OAUTH) || !strings.Contains(result, "provider=github") || strings.Contains(result, "back_url") {
+		t.Error("auth.OAuth() should contain the OAuth path and the provider, got: " + result)
+	}
+
+	// Test OAuthCallback()
+	result = auth.OAuthCallback()
+	if !strings.HasSuffix(result, AUTH_OAUTH_CALLBACK) {
+		t.Error("auth.OAuthCallback() should end with the callback path, got: " + result)
+	}
+
 	// Test AuthKnightLogin()
 	result = auth.AuthKnightLogin("/back-url")
 	if result == "" {
@@ -190,4 +244,28 @@ func TestUserLinks(t *testing.T) {
 	if result == "" {
 		t.Error("user.Profile(params) should return non-empty string")
 	}
+
+	// Test TwoFactor()
+	result = user.TwoFactor()
+	if !strings.Contains(result, USER_TWO_FACTOR) {
+		t.Error("user.TwoFactor() should contain the two-factor path, got: " + result)
+	}
+
+	// Test ConnectedAccounts()
+	result = user.ConnectedAccounts()
+	if !strings.Contains(result, USER_CONNECTED_ACCOUNTS) {
+		t.Error("user.ConnectedAccounts() should contain the connected accounts path, got: " + result)
+	}
+
+	// Test APITokens()
+	result = user.APITokens()
+	if !strings.Contains(result, USER_API_TOKENS) {
+		t.Error("user.APITokens() should contain the API tokens path, got: " + result)
+	}
+
+	// Test Sessions()
+	result = user.Sessions()
+	if !strings.Contains(result, USER_SESSIONS) {
+		t.Error("user.Sessions() should contain the sessions path, got: " + result)
+	}
 }
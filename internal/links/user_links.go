@@ -15,12 +15,60 @@ func (l *userLinks) Home(params ...map[string]string) string {
 	return URL(USER_HOME, p)
 }
 
+// Orders URL
+func (l *userLinks) Orders(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(USER_ORDERS, p)
+}
+
+// OrderView URL
+func (l *userLinks) OrderView(orderID string) string {
+	return URL(USER_ORDER_VIEW, map[string]string{"order_id": orderID})
+}
+
+// OrderInvoice URL, which downloads the invoice PDF of the order
+func (l *userLinks) OrderInvoice(orderID string) string {
+	return URL(USER_ORDER_INVOICE, map[string]string{"order_id": orderID})
+}
+
 // Profile URL
 func (l *userLinks) Profile(params ...map[string]string) string {
 	p := lo.FirstOr(params, map[string]string{})
 	return URL(USER_PROFILE, p)
 }
 
+// TwoFactor URL, where the user enables two-factor authentication
+func (l *userLinks) TwoFactor(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(USER_TWO_FACTOR, p)
+}
+
+// APITokens URL, where the user manages the personal API tokens
+func (l *userLinks) APITokens(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(USER_API_TOKENS, p)
+}
+
+// Sessions URL, where the user sees the devices signed in, and signs
+// them out
+func (l *userLinks) Sessions(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(USER_SESSIONS, p)
+}
+
+// ConnectedAccounts URL, where the user links and unlinks the social
+// login providers
+func (l *userLinks) ConnectedAccounts(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(USER_CONNECTED_ACCOUNTS, p)
+}
+
+// Subscription URL, the billing page
+func (l userLinks) Subscription(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(USER_SUBSCRIPTION, p)
+}
+
 // SubscriptionsPlanSelect URL
 func (l userLinks) SubscriptionsPlanSelect(params ...map[string]string) string {
 	p := lo.FirstOr(params, map[string]string{})
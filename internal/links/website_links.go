@@ -2,6 +2,7 @@ package links
 
 import (
 	"project/internal/cache"
+	"project/internal/signedurl"
 	"strings"
 	"time"
 
@@ -47,6 +48,22 @@ func (l *websiteLinks) File(filePath string) string {
 	return URL(path, map[string]string{})
 }
 
+// FileSigned returns the URL of a private file (see MEDIA_PRIVATE_PATHS),
+// signed with the key until the time, and for the user only when the user
+// ID is set. Returns an empty string when the key is not set
+//
+// Example:
+//
+//	links.Website().FileSigned("private/invoices/INV-1.pdf", app.GetConfig().GetMediaSigningKey(), time.Now().Add(time.Hour), user.GetID())
+func (l *websiteLinks) FileSigned(filePath string, key string, expiresAt time.Time, userID string) string {
+	return signedFileURL(FILES, filePath, key, expiresAt, userID)
+}
+
+// MediaSigned returns the URL of a private media file, see FileSigned
+func (l *websiteLinks) MediaSigned(filePath string, key string, expiresAt time.Time, userID string) string {
+	return signedFileURL(MEDIA, filePath, key, expiresAt, userID)
+}
+
 func (l *websiteLinks) Flash(params ...map[string]string) string {
 	p := lo.FirstOr(params, map[string]string{})
 	return URL(FLASH, p)
@@ -103,11 +120,36 @@ func (l *websiteLinks) ShopCartAPI(params map[string]string) string {
 	return URL(SHOP_CART_API, params)
 }
 
+func (l *websiteLinks) ShopCartDiscount(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(SHOP_CART_DISCOUNT, p)
+}
+
+func (l *websiteLinks) ShopCartShipping(params ...map[string]string) string {
+	p := lo.FirstOr(params, map[string]string{})
+	return URL(SHOP_CART_SHIPPING, p)
+}
+
+func (l *websiteLinks) ShopCategory(categoryID string, categorySlug string, params map[string]string) string {
+	uri := SHOP_CATEGORY
+	uri += "/" + categoryID
+	if categorySlug != "" {
+		uri += "/" + categorySlug
+	}
+	return URL(uri, params)
+}
+
 func (l *websiteLinks) ShopCheckout(params ...map[string]string) string {
 	p := lo.FirstOr(params, map[string]string{})
 	return URL(SHOP_CHECKOUT, p)
 }
 
+func (l *websiteLinks) ShopOrderConfirmation(orderID string) string {
+	params := map[string]string{}
+	params["order_id"] = orderID
+	return URL(SHOP_ORDER_CONFIRMATION, params)
+}
+
 func (l *websiteLinks) ShopProduct(productID string, productSlug string, params map[string]string) string {
 	uri := SHOP_PRODUCT
 	uri += "/" + productID
@@ -163,3 +205,21 @@ func (l *websiteLinks) Widget(alias string, params map[string]string) string {
 func (l *websiteLinks) SitemapXml() string {
 	return URL(SITEMAP_XML, map[string]string{})
 }
+
+// signedFileURL returns the URL of the file under the route, with the
+// signature of the file path
+func signedFileURL(route string, filePath string, key string, expiresAt time.Time, userID string) string {
+	filePath = signedurl.CleanPath(filePath)
+
+	query, err := signedurl.Sign(key, filePath, expiresAt, userID)
+	if err != nil {
+		return ""
+	}
+
+	params := map[string]string{}
+	for name := range query {
+		params[name] = query.Get(name)
+	}
+
+	return URL(strings.TrimSuffix(route, CATCHALL)+filePath, params)
+}
@@ -0,0 +1,147 @@
+// Package lockout tracks the failed authentication attempts (wrong
+// passwords, invalid login links and two-factor codes), per account and
+// per IP address, to slow down and then stop brute-force attacks.
+//
+// After a few failures, each new attempt must wait a little longer than
+// the previous one. After more failures the account or IP address is
+// locked for a time, which doubles with each new lockout, until the lock
+// expires or an administrator unlocks it.
+package lockout
+
+import "time"
+
+// The subjects the attempts are tracked for
+const (
+	SUBJECT_ACCOUNT = "account"
+	SUBJECT_IP      = "ip"
+)
+
+// DELAY_MAX is the longest delay between two attempts, before the lockout
+const DELAY_MAX = 30 * time.Second
+
+// LOCK_DURATION is the duration of the first lockout, doubled with each
+// new lockout up to LOCK_DURATION_MAX
+const LOCK_DURATION = 15 * time.Minute
+
+// LOCK_DURATION_MAX is the duration of the longest lockout
+const LOCK_DURATION_MAX = 24 * time.Hour
+
+// FAILURES_RESET_AFTER is the time without failures after which the
+// failures are forgotten
+const FAILURES_RESET_AFTER = 1 * time.Hour
+
+// Policy is the number of failures tolerated for a subject
+type Policy struct {
+	// DelayAfter is the number of failures after which each new attempt
+	// must wait
+	DelayAfter int
+	// LockAfter is the number of failures which lock the subject
+	LockAfter int
+}
+
+// PolicyFor returns the policy of the subject. The IP addresses tolerate
+// more failures, as they may be shared by many users (e.g. an office)
+func PolicyFor(subject string) Policy {
+	if subject == SUBJECT_IP {
+		return Policy{DelayAfter: 10, LockAfter: 50}
+	}
+
+	return Policy{DelayAfter: 3, LockAfter: 10}
+}
+
+// Attempt holds the failed attempts of an account or IP address
+type Attempt struct {
+	ID      string `json:"-"`
+	Subject string `json:"subject"`
+	// Key is the user ID for the accounts, or the IP address
+	Key string `json:"key"`
+	// Failures is the number of failures since the last lockout
+	Failures int `json:"failures"`
+	// Lockouts is the number of lockouts, which sets the duration of the
+	// next one
+	Lockouts      int       `json:"lockouts"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+	LockedUntil   time.Time `json:"locked_until"`
+}
+
+// IsLocked returns whether the subject is locked at the time
+func (a Attempt) IsLocked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
+
+// Wait returns how long the subject must wait at the time before its
+// next attempt, zero if it can try now
+func (a Attempt) Wait(now time.Time) time.Duration {
+	if a.IsLocked(now) {
+		return a.LockedUntil.Sub(now)
+	}
+
+	failures := a.failuresAt(now)
+	policy := PolicyFor(a.Subject)
+
+	if failures < policy.DelayAfter {
+		return 0
+	}
+
+	wait := a.LastFailureAt.Add(delay(failures - policy.DelayAfter)).Sub(now)
+
+	return max(wait, 0)
+}
+
+// Fail records a failed attempt at the time, and returns whether it
+// locked the subject
+func (a *Attempt) Fail(now time.Time) bool {
+	// The lockouts are forgotten after the longest lockout without failures
+	if now.Sub(a.LastFailureAt) >= LOCK_DURATION_MAX {
+		a.Lockouts = 0
+	}
+
+	a.Failures = a.failuresAt(now) + 1
+	a.LastFailureAt = now
+
+	if a.Failures < PolicyFor(a.Subject).LockAfter {
+		return false
+	}
+
+	a.LockedUntil = now.Add(lockDuration(a.Lockouts))
+	a.Lockouts++
+	a.Failures = 0
+
+	return true
+}
+
+// Unlock lifts the lock and forgets the failures, but keeps the number of
+// lockouts, so the next lockout lasts longer
+func (a *Attempt) Unlock() {
+	a.Failures = 0
+	a.LockedUntil = time.Time{}
+}
+
+// failuresAt returns the failures which are not forgotten at the time
+func (a Attempt) failuresAt(now time.Time) int {
+	if now.Sub(a.LastFailureAt) >= FAILURES_RESET_AFTER {
+		return 0
+	}
+
+	return a.Failures
+}
+
+// delay returns the delay after the failures over the policy: one second,
+// doubled with each failure, up to DELAY_MAX
+func delay(failuresOver int) time.Duration {
+	if failuresOver >= 16 {
+		return DELAY_MAX
+	}
+
+	return min(time.Second<<failuresOver, DELAY_MAX)
+}
+
+// lockDuration returns the duration of the lockout after the previous
+// lockouts
+func lockDuration(lockouts int) time.Duration {
+	if lockouts >= 16 {
+		return LOCK_DURATION_MAX
+	}
+
+	return min(LOCK_DURATION<<lockouts, LOCK_DURATION_MAX)
+}
@@ -0,0 +1,140 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyFor(t *testing.T) {
+	account := PolicyFor(SUBJECT_ACCOUNT)
+	ip := PolicyFor(SUBJECT_IP)
+
+	if account.DelayAfter >= account.LockAfter || ip.DelayAfter >= ip.LockAfter {
+		t.Fatalf("expected the delays before the lockout, got %+v and %+v", account, ip)
+	}
+
+	if ip.LockAfter <= account.LockAfter {
+		t.Fatalf("expected the IP addresses to tolerate more failures, got %+v and %+v", account, ip)
+	}
+}
+
+func TestAttempt_ProgressiveDelay(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	attempt := &Attempt{Subject: SUBJECT_ACCOUNT, Key: "user_1"}
+	policy := PolicyFor(SUBJECT_ACCOUNT)
+
+	for range policy.DelayAfter - 1 {
+		attempt.Fail(now)
+	}
+
+	if wait := attempt.Wait(now); wait != 0 {
+		t.Fatalf("Wait() = %v before the delays start, want 0", wait)
+	}
+
+	attempt.Fail(now)
+
+	if wait := attempt.Wait(now); wait != time.Second {
+		t.Fatalf("Wait() = %v after the first delayed failure, want 1s", wait)
+	}
+
+	attempt.Fail(now)
+
+	if wait := attempt.Wait(now); wait != 2*time.Second {
+		t.Fatalf("Wait() = %v after the second delayed failure, want 2s", wait)
+	}
+
+	if wait := attempt.Wait(now.Add(5 * time.Second)); wait != 0 {
+		t.Fatalf("Wait() = %v once the delay passed, want 0", wait)
+	}
+}
+
+func TestAttempt_Lockout(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	attempt := &Attempt{Subject: SUBJECT_ACCOUNT, Key: "user_1"}
+	policy := PolicyFor(SUBJECT_ACCOUNT)
+
+	for i := range policy.LockAfter {
+		locked := attempt.Fail(now)
+
+		if locked != (i == policy.LockAfter-1) {
+			t.Fatalf("failure %d: Fail() = %v", i+1, locked)
+		}
+	}
+
+	if !attempt.IsLocked(now) || attempt.Wait(now) != LOCK_DURATION {
+		t.Fatalf("expected a lockout of %v, got %v", LOCK_DURATION, attempt.Wait(now))
+	}
+
+	if attempt.IsLocked(now.Add(LOCK_DURATION)) {
+		t.Fatal("expected the lockout to expire")
+	}
+
+	// The next lockout lasts twice as long
+	later := now.Add(LOCK_DURATION)
+	for range policy.LockAfter {
+		attempt.Fail(later)
+	}
+
+	if wait := attempt.Wait(later); wait != 2*LOCK_DURATION {
+		t.Fatalf("Wait() = %v after the second lockout, want %v", wait, 2*LOCK_DURATION)
+	}
+}
+
+func TestAttempt_FailuresAreForgotten(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	attempt := &Attempt{Subject: SUBJECT_ACCOUNT, Key: "user_1"}
+
+	for range PolicyFor(SUBJECT_ACCOUNT).LockAfter - 1 {
+		attempt.Fail(now)
+	}
+
+	if locked := attempt.Fail(now.Add(FAILURES_RESET_AFTER)); locked {
+		t.Fatal("expected the old failures to be forgotten")
+	}
+
+	if attempt.Failures != 1 {
+		t.Fatalf("Failures = %d, want 1", attempt.Failures)
+	}
+}
+
+func TestAttempt_Unlock(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	attempt := &Attempt{Subject: SUBJECT_IP, Key: "127.0.0.1"}
+
+	for range PolicyFor(SUBJECT_IP).LockAfter {
+		attempt.Fail(now)
+	}
+
+	if !attempt.IsLocked(now) {
+		t.Fatal("expected the IP address to be locked")
+	}
+
+	attempt.Unlock()
+
+	if attempt.IsLocked(now) || attempt.Wait(now) != 0 {
+		t.Fatal("expected the IP address to be unlocked")
+	}
+
+	if attempt.Lockouts != 1 {
+		t.Fatalf("Lockouts = %d, want 1", attempt.Lockouts)
+	}
+}
+
+func TestLockDuration(t *testing.T) {
+	cases := []struct {
+		lockouts int
+		expected time.Duration
+	}{
+		{0, LOCK_DURATION},
+		{1, 2 * LOCK_DURATION},
+		{2, 4 * LOCK_DURATION},
+		{10, LOCK_DURATION_MAX},
+		{100, LOCK_DURATION_MAX},
+	}
+
+	for _, c := range cases {
+		if got := lockDuration(c.lockouts); got != c.expected {
+			t.Errorf("lockDuration(%d) = %v, want %v", c.lockouts, got, c.expected)
+		}
+	}
+}
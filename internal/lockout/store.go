@@ -0,0 +1,154 @@
+package lockout
+
+import (
+	"encoding/json"
+	"errors"
+	"project/internal/customrecords"
+	"sort"
+
+	"github.com/dracory/customstore"
+)
+
+// RECORD_TYPE_AUTH_ATTEMPT is the record type of the attempts in the
+// custom store
+const RECORD_TYPE_AUTH_ATTEMPT = "auth_attempt"
+
+// StoreInterface persists the attempts
+type StoreInterface interface {
+	AttemptDelete(attempt *Attempt) error
+	AttemptFind(subject string, key string) (*Attempt, error)
+	AttemptFindByID(id string) (*Attempt, error)
+	AttemptList() ([]Attempt, error)
+	AttemptSave(attempt *Attempt) error
+}
+
+type store struct {
+	customStore customstore.StoreInterface
+}
+
+var _ StoreInterface = (*store)(nil)
+
+// NewStore creates an attempt store on top of the custom store
+func NewStore(customStore customstore.StoreInterface) (StoreInterface, error) {
+	if customStore == nil {
+		return nil, errors.New("custom store is required")
+	}
+
+	return &store{customStore: customStore}, nil
+}
+
+// AttemptDelete deletes the attempts, e.g. once the user logged in
+func (s *store) AttemptDelete(attempt *Attempt) error {
+	if attempt == nil || attempt.ID == "" {
+		return errors.New("attempt ID is required")
+	}
+
+	return s.customStore.RecordDeleteByID(attempt.ID)
+}
+
+// AttemptFind returns the attempts of the subject, or nil if there are
+// none
+func (s *store) AttemptFind(subject string, key string) (*Attempt, error) {
+	if subject == "" || key == "" {
+		return nil, errors.New("subject and key are required")
+	}
+
+	attempts, err := s.attemptList(customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_AUTH_ATTEMPT), map[string]string{"subject": subject, "key": key})
+
+	if err != nil || len(attempts) == 0 {
+		return nil, err
+	}
+
+	return &attempts[0], nil
+}
+
+// AttemptFindByID returns the attempts with the ID, or nil if not found
+func (s *store) AttemptFindByID(id string) (*Attempt, error) {
+	if id == "" {
+		return nil, errors.New("attempt ID is required")
+	}
+
+	attempts, err := s.attemptList(customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_AUTH_ATTEMPT).
+		SetID(id), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(attempts) == 0 {
+		return nil, nil
+	}
+
+	return &attempts[0], nil
+}
+
+// AttemptList returns the attempts of all the subjects, the latest
+// failure first
+func (s *store) AttemptList() ([]Attempt, error) {
+	return s.attemptList(customstore.NewRecordQuery().SetType(RECORD_TYPE_AUTH_ATTEMPT), nil)
+}
+
+// AttemptSave creates the attempts, and sets their ID, or saves the
+// changes to them
+func (s *store) AttemptSave(attempt *Attempt) error {
+	if attempt == nil || attempt.Subject == "" || attempt.Key == "" {
+		return errors.New("subject and key are required")
+	}
+
+	payload, err := json.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+
+	if attempt.ID == "" {
+		record := customstore.NewRecord(RECORD_TYPE_AUTH_ATTEMPT, customstore.WithPayload(string(payload)))
+
+		if err := s.customStore.RecordCreate(record); err != nil {
+			return err
+		}
+
+		attempt.ID = record.ID()
+
+		return nil
+	}
+
+	record, err := s.customStore.RecordFindByID(attempt.ID)
+	if err != nil {
+		return err
+	}
+
+	if record == nil {
+		return errors.New("attempt not found")
+	}
+
+	record.SetPayload(string(payload))
+
+	return s.customStore.RecordUpdate(record)
+}
+
+func (s *store) attemptList(query customstore.RecordQueryInterface, fields map[string]string) ([]Attempt, error) {
+	records, err := customrecords.FindByPayload(s.customStore, query, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := []Attempt{}
+
+	for _, record := range records {
+		attempt := Attempt{}
+		if err := json.Unmarshal([]byte(record.Payload()), &attempt); err != nil {
+			return nil, err
+		}
+
+		attempt.ID = record.ID()
+		attempts = append(attempts, attempt)
+	}
+
+	sort.SliceStable(attempts, func(i, j int) bool {
+		return attempts[i].LastFailureAt.After(attempts[j].LastFailureAt)
+	})
+
+	return attempts, nil
+}
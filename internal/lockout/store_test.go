@@ -0,0 +1,117 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+
+	"project/internal/testutils"
+)
+
+func newTestStore(t *testing.T) StoreInterface {
+	t.Helper()
+
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	store, err := NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func TestNewStore_RequiresCustomStore(t *testing.T) {
+	if _, err := NewStore(nil); err == nil {
+		t.Error("NewStore(nil) expected error")
+	}
+}
+
+func TestStore_AttemptSaveAndFind(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Now().UTC()
+
+	attempt := &Attempt{Subject: SUBJECT_ACCOUNT, Key: "user_1"}
+	attempt.Fail(now)
+
+	if err := store.AttemptSave(attempt); err != nil {
+		t.Fatal(err)
+	}
+
+	if attempt.ID == "" {
+		t.Fatal("AttemptSave() must set the ID")
+	}
+
+	// The same key with another subject is another attempt
+	if err := store.AttemptSave(&Attempt{Subject: SUBJECT_IP, Key: "user_1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := store.AttemptFind(SUBJECT_ACCOUNT, "user_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found == nil || found.ID != attempt.ID || found.Failures != 1 {
+		t.Fatalf("expected the attempt, got %+v", found)
+	}
+
+	found.Fail(now)
+
+	if err := store.AttemptSave(found); err != nil {
+		t.Fatal(err)
+	}
+
+	byID, err := store.AttemptFindByID(attempt.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if byID == nil || byID.Failures != 2 {
+		t.Fatalf("expected the saved failures, got %+v", byID)
+	}
+
+	notFound, err := store.AttemptFind(SUBJECT_ACCOUNT, "user_2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if notFound != nil {
+		t.Fatalf("expected no attempt for another key, got %+v", notFound)
+	}
+}
+
+func TestStore_AttemptListAndDelete(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Now().UTC()
+
+	older := &Attempt{Subject: SUBJECT_IP, Key: "10.0.0.1", LastFailureAt: now.Add(-time.Minute)}
+	newer := &Attempt{Subject: SUBJECT_IP, Key: "10.0.0.2", LastFailureAt: now}
+
+	for _, attempt := range []*Attempt{older, newer} {
+		if err := store.AttemptSave(attempt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	attempts, err := store.AttemptList()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attempts) != 2 || attempts[0].ID != newer.ID {
+		t.Fatalf("expected the latest failure first, got %+v", attempts)
+	}
+
+	if err := store.AttemptDelete(newer); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := store.AttemptFindByID(newer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if deleted != nil {
+		t.Fatal("expected the attempt to be deleted")
+	}
+}
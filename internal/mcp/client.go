@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// KEY_PREFIX prefixes the keys of the MCP clients, so they are easy to
+// recognise (e.g. by secret scanners), and told apart from the personal
+// API tokens
+const KEY_PREFIX = "mcp_"
+
+// The statuses of a client, see Client.Status
+const (
+	CLIENT_STATUS_ACTIVE  = "active"
+	CLIENT_STATUS_REVOKED = "revoked"
+)
+
+// TOOLS_ALL is the tool pattern allowing all the tools
+const TOOLS_ALL = "*"
+
+// Client is an MCP client, e.g. an AI assistant, with the tools it is
+// allowed to call
+type Client struct {
+	ID   string `json:"-"`
+	Name string `json:"name"`
+	// Hash is the SHA-256 hash of the key, see Hash
+	Hash string `json:"hash"`
+	// Hint is the end of the key, to tell the clients apart
+	Hint string `json:"hint"`
+	// Tools are the names of the tools the client may call, or patterns
+	// ending with "*", e.g. "blog_*" for all the blog tools
+	Tools []string `json:"tools"`
+	// CreatedBy is the ID of the administrator who created the client
+	CreatedBy string `json:"created_by"`
+
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	RevokedAt  time.Time `json:"revoked_at"`
+}
+
+// IsRevoked returns whether the client was revoked
+func (c Client) IsRevoked() bool {
+	return !c.RevokedAt.IsZero()
+}
+
+// Status returns the status of the client
+func (c Client) Status() string {
+	if c.IsRevoked() {
+		return CLIENT_STATUS_REVOKED
+	}
+
+	return CLIENT_STATUS_ACTIVE
+}
+
+// Allows returns whether the client may call the tool
+func (c Client) Allows(tool string) bool {
+	if c.IsRevoked() || tool == "" {
+		return false
+	}
+
+	for _, pattern := range c.Tools {
+		if ToolMatches(pattern, tool) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ToolMatches returns whether the tool matches the pattern, the name of
+// the tool or a prefix ending with "*" (e.g. "blog_*", or "*" for all)
+func ToolMatches(pattern string, tool string) bool {
+	if prefix, found := strings.CutSuffix(pattern, "*"); found {
+		return strings.HasPrefix(tool, prefix)
+	}
+
+	return pattern == tool
+}
+
+// Generate returns a new random client key, and its hash to store
+func Generate() (key string, hash string, err error) {
+	random := make([]byte, 32)
+
+	if _, err := rand.Read(random); err != nil {
+		return "", "", err
+	}
+
+	key = KEY_PREFIX + base64.RawURLEncoding.EncodeToString(random)
+
+	return key, Hash(key), nil
+}
+
+// Hash returns the hash of the key, as stored. The keys are random, so a
+// fast hash is enough
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsKey returns whether the value looks like an MCP client key
+func IsKey(value string) bool {
+	return strings.HasPrefix(value, KEY_PREFIX) && len(value) > len(KEY_PREFIX)
+}
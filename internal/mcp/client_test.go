@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerate(t *testing.T) {
+	key, hash, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(key, KEY_PREFIX) || !IsKey(key) {
+		t.Fatalf("expected the key to start with %s, got %s", KEY_PREFIX, key)
+	}
+
+	if hash != Hash(key) || hash == key {
+		t.Fatal("expected the hash of the key")
+	}
+
+	other, _, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if other == key {
+		t.Fatal("expected the keys to be random")
+	}
+}
+
+func TestIsKey(t *testing.T) {
+	cases := map[string]bool{
+		"mcp_abc": true,
+		"mcp_":    false,
+		"pat_abc": false,
+		"":        false,
+	}
+
+	for value, expected := range cases {
+		if IsKey(value) != expected {
+			t.Errorf("IsKey(%q) = %v, want %v", value, !expected, expected)
+		}
+	}
+}
+
+func TestClient_Allows(t *testing.T) {
+	client := Client{Tools: []string{"blog_*", "shop_product_get"}}
+
+	cases := map[string]bool{
+		"blog_post_list":    true,
+		"blog_post_create":  true,
+		"shop_product_get":  true,
+		"shop_product_list": false,
+		"cms_page_list":     false,
+		"":                  false,
+	}
+
+	for tool, expected := range cases {
+		if client.Allows(tool) != expected {
+			t.Errorf("Allows(%q) = %v, want %v", tool, !expected, expected)
+		}
+	}
+
+	if !(Client{Tools: []string{TOOLS_ALL}}).Allows("users_get") {
+		t.Error("expected * to allow all the tools")
+	}
+
+	revoked := Client{Tools: []string{TOOLS_ALL}, RevokedAt: time.Now()}
+	if revoked.Allows("users_get") || revoked.Status() != CLIENT_STATUS_REVOKED {
+		t.Error("expected a revoked client not to be allowed any tool")
+	}
+}
@@ -0,0 +1,10 @@
+// Package mcp implements the Model Context Protocol (MCP) server, which
+// lets the AI assistants call the tools of the application (e.g. list the
+// blog posts) over JSON-RPC.
+//
+// The tools are grouped by provider (blog, CMS, shop, users, tasks,
+// settings), the name of the provider prefixing the names of its tools.
+// The clients are created by the administrators, each with its own key
+// and the tools it is allowed to call. Only the SHA-256 hash of a key is
+// stored, the key itself is shown once, when the client is created.
+package mcp
@@ -0,0 +1,178 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// handlerProvider provides the tools of an MCP handler of a store (e.g.
+// the one of the blog store), prefixing their names with its name
+type handlerProvider struct {
+	name    string
+	handler http.Handler
+
+	mutex sync.Mutex
+	// upstream maps the names of the tools to their names in the handler
+	upstream map[string]string
+}
+
+var _ ProviderInterface = (*handlerProvider)(nil)
+
+// NewHandlerProvider returns the provider of the tools of the MCP handler,
+// which answers the tools/list and tools/call JSON-RPC requests
+func NewHandlerProvider(name string, handler http.Handler) ProviderInterface {
+	return &handlerProvider{name: name, handler: handler, upstream: map[string]string{}}
+}
+
+func (p *handlerProvider) Name() string {
+	return p.name
+}
+
+func (p *handlerProvider) Tools(ctx context.Context) ([]Tool, error) {
+	result, err := p.request(ctx, "tools/list", map[string]any{})
+	if err != nil {
+		return nil, err
+	}
+
+	list := struct {
+		Tools []Tool `json:"tools"`
+	}{}
+
+	if err := json.Unmarshal(result, &list); err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	tools := make([]Tool, 0, len(list.Tools))
+
+	for _, tool := range list.Tools {
+		name := tool.Name
+		if !strings.HasPrefix(name, p.name+"_") {
+			name = p.name + "_" + name
+		}
+
+		p.upstream[name] = tool.Name
+		tool.Name = name
+
+		if len(tool.InputSchema) == 0 {
+			tool.InputSchema = ObjectSchema(map[string]any{})
+		}
+
+		tools = append(tools, tool)
+	}
+
+	return tools, nil
+}
+
+func (p *handlerProvider) Call(ctx context.Context, tool string, arguments json.RawMessage) (any, error) {
+	upstream, err := p.upstreamName(ctx, tool)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+
+	result, err := p.request(ctx, "tools/call", map[string]any{
+		"name":      upstream,
+		"arguments": arguments,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// upstreamName returns the name of the tool in the handler, listing the
+// tools when not known yet
+func (p *handlerProvider) upstreamName(ctx context.Context, tool string) (string, error) {
+	p.mutex.Lock()
+	upstream, found := p.upstream[tool]
+	p.mutex.Unlock()
+
+	if found {
+		return upstream, nil
+	}
+
+	if _, err := p.Tools(ctx); err != nil {
+		return "", err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if upstream, found := p.upstream[tool]; found {
+		return upstream, nil
+	}
+
+	return "", ErrToolNotFound
+}
+
+// request sends the JSON-RPC request to the handler, and returns its
+// result. The errors of the handler are returned as a ToolError
+func (p *handlerProvider) request(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	body, err := json.Marshal(map[string]any{
+		"jsonrpc": JSONRPC_VERSION,
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := &responseRecorder{header: http.Header{}, status: http.StatusOK}
+	p.handler.ServeHTTP(recorder, request)
+
+	response := struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Error          `json:"error"`
+	}{}
+
+	if err := json.Unmarshal(recorder.body.Bytes(), &response); err != nil {
+		return nil, errors.New(p.name + " handler answered with status " + http.StatusText(recorder.status) + " and no JSON-RPC response")
+	}
+
+	if response.Error != nil {
+		return nil, NewToolError(response.Error.Message)
+	}
+
+	return response.Result, nil
+}
+
+// responseRecorder keeps the response of the handler
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	return r.body.Write(p)
+}
@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// The providers of tools, their names prefixing the names of their tools
+const (
+	PROVIDER_BLOG     = "blog"
+	PROVIDER_CMS      = "cms"
+	PROVIDER_SETTINGS = "settings"
+	PROVIDER_SHOP     = "shop"
+	PROVIDER_TASKS    = "tasks"
+	PROVIDER_USERS    = "users"
+)
+
+// ErrToolNotFound is returned for the tools no provider has
+var ErrToolNotFound = errors.New("tool not found")
+
+// Tool describes a tool to the MCP clients
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// InputSchema is the JSON schema of the arguments of the tool
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// ProviderInterface provides a group of tools, e.g. the blog tools
+type ProviderInterface interface {
+	// Name returns the name of the provider, e.g. PROVIDER_BLOG, which
+	// prefixes the names of its tools (e.g. blog_post_list)
+	Name() string
+
+	// Tools returns the tools of the provider
+	Tools(ctx context.Context) ([]Tool, error)
+
+	// Call calls the tool with the arguments, and returns its result.
+	// The errors meant for the client are returned as a ToolError, the
+	// others are logged and reported as a failure
+	Call(ctx context.Context, tool string, arguments json.RawMessage) (any, error)
+}
+
+// ToolError is an error of a tool the client can act on, e.g. a missing
+// argument, returned to it as the result of the tool
+type ToolError struct {
+	Message string
+}
+
+// NewToolError returns the error of a tool, shown to the client
+func NewToolError(message string) *ToolError {
+	return &ToolError{Message: message}
+}
+
+func (e *ToolError) Error() string {
+	return e.Message
+}
+
+// TextResult returns the result of a tool as a JSON text content, the way
+// the MCP clients read it
+func TextResult(value any) (any, error) {
+	text, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"content": []map[string]any{
+			{"type": "text", "text": string(text)},
+		},
+	}, nil
+}
+
+// DecodeArguments decodes the arguments of a tool into the value,
+// returning a ToolError when they are not valid
+func DecodeArguments(arguments json.RawMessage, value any) error {
+	if len(arguments) == 0 || string(arguments) == "null" {
+		return nil
+	}
+
+	if err := json.Unmarshal(arguments, value); err != nil {
+		return NewToolError("invalid arguments: " + err.Error())
+	}
+
+	return nil
+}
+
+// ObjectSchema returns the JSON schema of an object with the properties,
+// each a JSON schema, and the required ones
+func ObjectSchema(properties map[string]any, required ...string) json.RawMessage {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	encoded, _ := json.Marshal(schema)
+
+	return encoded
+}
+
+// toolProvider returns the name of the provider of the tool, the part of
+// its name before the first underscore
+func toolProvider(tool string) string {
+	provider, _, _ := strings.Cut(tool, "_")
+	return provider
+}
@@ -0,0 +1,55 @@
+package providers
+
+// The page size of the list tools, when not set, and at most
+const (
+	listLimitDefault = 20
+	listLimitMax     = 100
+)
+
+// listArguments are the arguments of the list tools
+type listArguments struct {
+	Status string `json:"status"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+// limit returns the page size, the default one when not set
+func (arguments listArguments) limit() int {
+	if arguments.Limit <= 0 {
+		return listLimitDefault
+	}
+
+	return min(arguments.Limit, listLimitMax)
+}
+
+// offset returns the offset of the page, never negative
+func (arguments listArguments) offset() int {
+	return max(arguments.Offset, 0)
+}
+
+// idArguments are the arguments of the get tools
+type idArguments struct {
+	ID string `json:"id"`
+}
+
+// listSchemaProperties returns the JSON schema properties of the list
+// arguments, the status being one of the statuses
+func listSchemaProperties(statuses ...string) map[string]any {
+	status := map[string]any{"type": "string", "description": "Only the items with the status"}
+	if len(statuses) > 0 {
+		status["enum"] = statuses
+	}
+
+	return map[string]any{
+		"status": status,
+		"limit":  map[string]any{"type": "integer", "description": "The number of items, at most 100", "default": listLimitDefault},
+		"offset": map[string]any{"type": "integer", "description": "The number of items to skip", "default": 0},
+	}
+}
+
+// idSchemaProperties returns the JSON schema properties of the ID argument
+func idSchemaProperties(description string) map[string]any {
+	return map[string]any{
+		"id": map[string]any{"type": "string", "description": description},
+	}
+}
@@ -0,0 +1,7 @@
+// Package providers provides the tools of the stores of the application
+// to the MCP server: the blog and CMS tools of their stores' MCP handlers,
+// and the shop products, users (read only), task queue and settings tools.
+//
+// NewServer returns the MCP server with the providers of the stores in
+// use, auditing every tool call in the audit store.
+package providers
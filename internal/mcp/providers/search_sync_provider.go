@@ -0,0 +1,89 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"project/internal/app"
+	"project/internal/mcp"
+	"project/internal/tasks/search_reindex"
+)
+
+// searchSyncReadActions are the actions of the tools which do not change
+// the documents, e.g. blog_post_list
+var searchSyncReadActions = []string{"list", "get", "find", "search", "count"}
+
+// searchSyncProvider keeps the search index in sync with the documents
+// changed by the tools of the provider, as they are changed by the MCP
+// handler of the store, not by the application
+type searchSyncProvider struct {
+	mcp.ProviderInterface
+	app          app.AppInterface
+	documentType string
+	// prefix prefixes the tools of the documents, e.g. blog_post_
+	prefix string
+	// idArgument is the argument with the ID of the document, besides id
+	idArgument string
+}
+
+var _ mcp.ProviderInterface = (*searchSyncProvider)(nil)
+
+// NewSearchSyncProvider returns the provider, which updates the document
+// of the type in the search index after the tools with the prefix change
+// it (e.g. blog_post_update). The document is found by the id argument,
+// or the idArgument (e.g. post_id), else all the documents of the type are
+// refreshed (e.g. after blog_post_create)
+func NewSearchSyncProvider(app app.AppInterface, provider mcp.ProviderInterface, documentType string, prefix string, idArgument string) mcp.ProviderInterface {
+	return &searchSyncProvider{
+		ProviderInterface: provider,
+		app:               app,
+		documentType:      documentType,
+		prefix:            prefix,
+		idArgument:        idArgument,
+	}
+}
+
+func (p *searchSyncProvider) Call(ctx context.Context, tool string, arguments json.RawMessage) (any, error) {
+	result, err := p.ProviderInterface.Call(ctx, tool, arguments)
+
+	if err != nil || !p.changes(tool) {
+		return result, err
+	}
+
+	search_reindex.Changed(ctx, p.app, p.documentType, p.sourceIDs(arguments)...)
+
+	return result, nil
+}
+
+// changes returns whether the tool changes the documents
+func (p *searchSyncProvider) changes(tool string) bool {
+	action, found := strings.CutPrefix(tool, p.prefix)
+	if !found {
+		return false
+	}
+
+	for _, readAction := range searchSyncReadActions {
+		if strings.HasPrefix(action, readAction) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sourceIDs returns the ID of the document in the arguments, if any
+func (p *searchSyncProvider) sourceIDs(arguments json.RawMessage) []string {
+	values := map[string]any{}
+	if err := json.Unmarshal(arguments, &values); err != nil {
+		return []string{}
+	}
+
+	for _, key := range []string{"id", p.idArgument} {
+		if id, ok := values[key].(string); ok && strings.TrimSpace(id) != "" {
+			return []string{strings.TrimSpace(id)}
+		}
+	}
+
+	return []string{}
+}
@@ -0,0 +1,95 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"project/internal/mcp"
+	"project/internal/searchindex"
+	"project/internal/testutils"
+
+	"github.com/dracory/blogstore"
+)
+
+// testBlogProvider stands in for the MCP handler of the blog store,
+// publishing the post of the blog_post_update tool
+type testBlogProvider struct {
+	store blogstore.StoreInterface
+}
+
+func (p *testBlogProvider) Name() string {
+	return mcp.PROVIDER_BLOG
+}
+
+func (p *testBlogProvider) Tools(ctx context.Context) ([]mcp.Tool, error) {
+	return []mcp.Tool{}, nil
+}
+
+func (p *testBlogProvider) Call(ctx context.Context, tool string, arguments json.RawMessage) (any, error) {
+	input := idArguments{}
+	if err := mcp.DecodeArguments(arguments, &input); err != nil {
+		return nil, err
+	}
+
+	post, err := p.store.PostFindByID(ctx, input.ID)
+	if err != nil || post == nil {
+		return nil, mcp.NewToolError("post not found")
+	}
+
+	if tool == "blog_post_update" {
+		post.SetStatus(blogstore.POST_STATUS_PUBLISHED)
+		if err := p.store.PostUpdate(ctx, post); err != nil {
+			return nil, err
+		}
+	}
+
+	return map[string]any{"id": post.GetID()}, nil
+}
+
+func TestSearchSyncProvider_SyncsChangedPosts(t *testing.T) {
+	app := testutils.Setup(testutils.WithBlogStore(true))
+	ctx := context.Background()
+
+	post := blogstore.NewPost()
+	post.SetTitle("Draft origami post")
+	post.SetStatus(blogstore.POST_STATUS_DRAFT)
+
+	if err := app.GetBlogStore().PostCreate(ctx, post); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewSearchSyncProvider(app, &testBlogProvider{store: app.GetBlogStore()}, searchindex.DOCUMENT_TYPE_POST, "blog_post_", "post_id")
+	arguments := json.RawMessage(`{"id":"` + post.GetID() + `"}`)
+
+	if _, err := provider.Call(ctx, "blog_post_get", arguments); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Call(ctx, "blog_post_update", arguments); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, total, _ := app.GetSearchIndex().Search(ctx, searchindex.Query{Text: "origami"}); total != 1 {
+		t.Fatalf("expected the updated post to be indexed, got %d results", total)
+	}
+}
+
+func TestSearchSyncProvider_Changes(t *testing.T) {
+	provider := &searchSyncProvider{prefix: "blog_post_"}
+
+	cases := map[string]bool{
+		"blog_post_create": true,
+		"blog_post_update": true,
+		"blog_post_delete": true,
+		"blog_post_list":   false,
+		"blog_post_get":    false,
+		"blog_tag_create":  false,
+	}
+
+	for tool, expected := range cases {
+		if provider.changes(tool) != expected {
+			t.Errorf("expected changes(%s) to be %v", tool, expected)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/mcp"
+	"project/internal/searchindex"
+
+	"github.com/dracory/auditstore"
+	blogstoreMcp "github.com/dracory/blogstore/mcp"
+	cmsstoreMcp "github.com/dracory/cmsstore/mcp"
+)
+
+// The actions of the tool calls in the audit store, see mcp.Invocation
+const (
+	AUDIT_ACTION_MCP_TOOL_CALL   = "mcp.tool_call"
+	AUDIT_ACTION_MCP_TOOL_DENIED = "mcp.tool_denied"
+	AUDIT_ACTION_MCP_TOOL_ERROR  = "mcp.tool_error"
+)
+
+// AUDIT_ENTITY_TYPE_MCP_TOOL is the entity type of the tool calls in the
+// audit store, the entity ID being the name of the tool
+const AUDIT_ENTITY_TYPE_MCP_TOOL = "mcp_tool"
+
+// AUDIT_USER_ID_PREFIX prefixes the ID of the MCP client in the user ID
+// of the tool calls in the audit store, as the clients are not users
+const AUDIT_USER_ID_PREFIX = "mcp_client:"
+
+// Providers returns the providers of the stores in use, all of them or
+// only the ones with the names (e.g. mcp.PROVIDER_BLOG)
+func Providers(app app.AppInterface, names ...string) []mcp.ProviderInterface {
+	if app == nil {
+		return []mcp.ProviderInterface{}
+	}
+
+	included := func(name string) bool {
+		return len(names) == 0 || slices.Contains(names, name)
+	}
+
+	providers := []mcp.ProviderInterface{}
+
+	// The posts and pages changed by the tools are updated in the search
+	// index
+	if included(mcp.PROVIDER_BLOG) && app.GetBlogStore() != nil {
+		provider := mcp.NewHandlerProvider(mcp.PROVIDER_BLOG, http.HandlerFunc(blogstoreMcp.NewMCP(app.GetBlogStore()).Handler))
+		providers = append(providers, NewSearchSyncProvider(app, provider, searchindex.DOCUMENT_TYPE_POST, "blog_post_", "post_id"))
+	}
+
+	if included(mcp.PROVIDER_CMS) && app.GetCmsStore() != nil {
+		provider := mcp.NewHandlerProvider(mcp.PROVIDER_CMS, http.HandlerFunc(cmsstoreMcp.NewMCP(app.GetCmsStore()).Handler))
+		providers = append(providers, NewSearchSyncProvider(app, provider, searchindex.DOCUMENT_TYPE_PAGE, "cms_page_", "page_id"))
+	}
+
+	if included(mcp.PROVIDER_SHOP) && app.GetShopStore() != nil {
+		providers = append(providers, NewShopProvider(app.GetShopStore()))
+	}
+
+	if included(mcp.PROVIDER_USERS) && app.GetUserStore() != nil {
+		providers = append(providers, NewUsersProvider(app))
+	}
+
+	if included(mcp.PROVIDER_TASKS) && app.GetTaskStore() != nil {
+		providers = append(providers, NewTasksProvider(app.GetTaskStore()))
+	}
+
+	if included(mcp.PROVIDER_SETTINGS) && app.GetSettingStore() != nil {
+		providers = append(providers, NewSettingsProvider(app.GetSettingStore()))
+	}
+
+	return providers
+}
+
+// NewServer returns the MCP server with the providers of the stores in
+// use, all of them or only the ones with the names. The tool calls are
+// audited in the audit store, if it is used
+func NewServer(app app.AppInterface, names ...string) *mcp.Server {
+	options := mcp.ServerOptions{
+		Name:      "blueprint",
+		Version:   config.GetVersion(),
+		Providers: Providers(app, names...),
+	}
+
+	if app != nil {
+		options.Audit = auditFunc(app)
+		options.Logger = app.GetLogger()
+
+		if app.GetConfig() != nil && app.GetConfig().GetAppName() != "" {
+			options.Name = app.GetConfig().GetAppName()
+		}
+	}
+
+	return mcp.NewServer(options)
+}
+
+// auditMetadata returns the metadata of the tool call in the audit log:
+// its duration in milliseconds, and its error, if it failed
+func auditMetadata(invocation mcp.Invocation) map[string]any {
+	metadata := map[string]any{
+		"duration_ms": invocation.Duration.Milliseconds(),
+	}
+
+	if invocation.Error != "" {
+		metadata["error"] = invocation.Error
+	}
+
+	return metadata
+}
+
+// auditFunc returns the function adding the tool calls to the audit log
+func auditFunc(app app.AppInterface) func(ctx context.Context, invocation mcp.Invocation) {
+	return func(ctx context.Context, invocation mcp.Invocation) {
+		if app.GetAuditStore() == nil {
+			return
+		}
+
+		action := AUDIT_ACTION_MCP_TOOL_CALL
+
+		switch invocation.Outcome {
+		case mcp.INVOCATION_OUTCOME_DENIED:
+			action = AUDIT_ACTION_MCP_TOOL_DENIED
+		case mcp.INVOCATION_OUTCOME_ERROR:
+			action = AUDIT_ACTION_MCP_TOOL_ERROR
+		}
+
+		metadata, err := json.Marshal(auditMetadata(invocation))
+		if err != nil {
+			app.GetLogger().Error("At providers > auditFunc > Marshal", slog.String("error", err.Error()))
+		}
+
+		record := auditstore.NewRecord().
+			SetAction(action).
+			SetEntityType(AUDIT_ENTITY_TYPE_MCP_TOOL).
+			SetEntityID(invocation.Tool).
+			SetUserID(AUDIT_USER_ID_PREFIX + invocation.Client.ID).
+			SetIPAddress(invocation.IPAddress).
+			SetMetadata(string(metadata))
+
+		if err := app.GetAuditStore().RecordCreate(ctx, record); err != nil {
+			app.GetLogger().Error("At providers > auditFunc > RecordCreate", slog.String("error", err.Error()))
+		}
+	}
+}
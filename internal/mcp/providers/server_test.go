@@ -0,0 +1,173 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"project/internal/mcp"
+	"project/internal/testutils"
+
+	"github.com/dracory/test"
+)
+
+// callTool calls the tool as a client allowed all the tools, and returns
+// the text of its result
+func callTool(t *testing.T, server *mcp.Server, tool string, arguments string) (string, bool) {
+	t.Helper()
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"` + tool + `","arguments":` + arguments + `}}`
+	response := server.Handle(context.Background(), mcp.Client{ID: "client_1", Tools: []string{mcp.TOOLS_ALL}}, "127.0.0.1", []byte(body))
+
+	if response == nil || response.Error != nil {
+		t.Fatalf("expected a result for %s, got %+v", tool, response)
+	}
+
+	encoded, err := json.Marshal(response.Result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}{}
+
+	if err := json.Unmarshal(encoded, &result); err != nil || len(result.Content) == 0 {
+		t.Fatalf("expected a text content for %s, got %s", tool, encoded)
+	}
+
+	return result.Content[0].Text, result.IsError
+}
+
+func TestProviders_StoresInUse(t *testing.T) {
+	app := testutils.Setup(testutils.WithShopStore(true), testutils.WithTaskStore(true))
+
+	names := []string{}
+	for _, provider := range Providers(app) {
+		names = append(names, provider.Name())
+	}
+
+	if strings.Join(names, ",") != mcp.PROVIDER_SHOP+","+mcp.PROVIDER_TASKS {
+		t.Fatalf("expected the providers of the stores in use, got %v", names)
+	}
+
+	if providers := Providers(app, mcp.PROVIDER_TASKS); len(providers) != 1 || providers[0].Name() != mcp.PROVIDER_TASKS {
+		t.Fatalf("expected only the named provider, got %d", len(providers))
+	}
+
+	if len(Providers(nil)) != 0 {
+		t.Fatal("expected no providers without an app")
+	}
+}
+
+func TestShopProvider(t *testing.T) {
+	app := testutils.Setup(testutils.WithShopStore(true))
+	server := NewServer(app)
+
+	if _, err := testutils.SeedProduct(app.GetShopStore(), "product-1", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	text, isError := callTool(t, server, "shop_product_list", `{}`)
+	if isError || !strings.Contains(text, "product-1") {
+		t.Fatalf("expected the product in the list, got %s", text)
+	}
+
+	text, isError = callTool(t, server, "shop_product_get", `{"id":"product-1"}`)
+	if isError || !strings.Contains(text, `"id":"product-1"`) {
+		t.Fatalf("expected the product, got %s", text)
+	}
+
+	if text, isError := callTool(t, server, "shop_product_get", `{"id":"unknown"}`); !isError || text != "product not found" {
+		t.Fatalf("expected product not found, got %s", text)
+	}
+}
+
+func TestUsersProvider(t *testing.T) {
+	app := testutils.Setup(testutils.WithUserStore(true))
+	server := NewServer(app)
+
+	if _, err := testutils.SeedUser(app.GetUserStore(), test.USER_01); err != nil {
+		t.Fatal(err)
+	}
+
+	text, isError := callTool(t, server, "users_get", `{"id":"`+test.USER_01+`"}`)
+	if isError || !strings.Contains(text, test.USER_01) {
+		t.Fatalf("expected the user, got %s", text)
+	}
+
+	tools, err := server.Tools(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tool := range tools {
+		if tool.Name != "users_list" && tool.Name != "users_get" {
+			t.Errorf("expected the user tools to be read only, got %s", tool.Name)
+		}
+	}
+}
+
+func TestTasksProvider(t *testing.T) {
+	app := testutils.Setup(testutils.WithTaskStore(true))
+	server := NewServer(app)
+
+	if text, isError := callTool(t, server, "tasks_enqueue", `{"alias":"UnknownTask"}`); !isError || !strings.Contains(text, "UnknownTask") {
+		t.Fatalf("expected an error for an unknown task, got %s", text)
+	}
+
+	if text, isError := callTool(t, server, "tasks_enqueue", `{}`); !isError || text != "alias is required" {
+		t.Fatalf("expected alias is required, got %s", text)
+	}
+
+	text, isError := callTool(t, server, "tasks_queue_list", `{"limit":5}`)
+	if isError || text != "[]" {
+		t.Fatalf("expected an empty queue, got %s", text)
+	}
+}
+
+func TestSettingsProvider(t *testing.T) {
+	app := testutils.Setup(testutils.WithSettingStore(true))
+	server := NewServer(app)
+
+	if text, isError := callTool(t, server, "settings_set", `{"key":"site.motto","value":"Ship it"}`); isError {
+		t.Fatalf("expected the setting to be set, got %s", text)
+	}
+
+	text, isError := callTool(t, server, "settings_get", `{"key":"site.motto"}`)
+	if isError || !strings.Contains(text, "Ship it") {
+		t.Fatalf("expected the value of the setting, got %s", text)
+	}
+}
+
+func TestNewServer_WithAuditStore(t *testing.T) {
+	app := testutils.Setup(testutils.WithShopStore(true), testutils.WithAuditStore(true))
+	server := NewServer(app)
+
+	if text, isError := callTool(t, server, "shop_product_list", `{}`); isError {
+		t.Fatalf("expected the tool to succeed with the audit store, got %s", text)
+	}
+
+	// The denied and failed calls are audited too
+	auditInvocation := auditFunc(app)
+	for _, outcome := range []string{mcp.INVOCATION_OUTCOME_ERROR, mcp.INVOCATION_OUTCOME_DENIED} {
+		auditInvocation(context.Background(), mcp.Invocation{Client: mcp.Client{ID: "client_1"}, Tool: "shop_product_list", Outcome: outcome})
+	}
+}
+
+func TestAuditMetadata(t *testing.T) {
+	metadata := auditMetadata(mcp.Invocation{Tool: "shop_product_get", Outcome: mcp.INVOCATION_OUTCOME_ERROR, Error: "product not found", Duration: 1500 * time.Millisecond})
+
+	if metadata["duration_ms"] != int64(1500) || metadata["error"] != "product not found" {
+		t.Fatalf("expected the duration and the error, got %v", metadata)
+	}
+
+	if _, found := auditMetadata(mcp.Invocation{Outcome: mcp.INVOCATION_OUTCOME_SUCCESS})["error"]; found {
+		t.Fatal("expected no error for a successful call")
+	}
+}
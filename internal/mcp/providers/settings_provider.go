@@ -0,0 +1,87 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"project/internal/mcp"
+
+	"github.com/dracory/settingstore"
+)
+
+// settingsProvider provides the tools reading and changing the settings
+// of the setting store
+type settingsProvider struct {
+	store settingstore.StoreInterface
+}
+
+var _ mcp.ProviderInterface = (*settingsProvider)(nil)
+
+// NewSettingsProvider returns the provider of the settings tools
+func NewSettingsProvider(store settingstore.StoreInterface) mcp.ProviderInterface {
+	return &settingsProvider{store: store}
+}
+
+func (p *settingsProvider) Name() string {
+	return mcp.PROVIDER_SETTINGS
+}
+
+func (p *settingsProvider) Tools(ctx context.Context) ([]mcp.Tool, error) {
+	return []mcp.Tool{
+		{
+			Name:        "settings_get",
+			Description: "Gets the value of a setting, empty if not set",
+			InputSchema: mcp.ObjectSchema(map[string]any{
+				"key": map[string]any{"type": "string", "description": "The key of the setting"},
+			}, "key"),
+		},
+		{
+			Name:        "settings_set",
+			Description: "Sets the value of a setting",
+			InputSchema: mcp.ObjectSchema(map[string]any{
+				"key":   map[string]any{"type": "string", "description": "The key of the setting"},
+				"value": map[string]any{"type": "string", "description": "The new value of the setting"},
+			}, "key", "value"),
+		},
+	}, nil
+}
+
+func (p *settingsProvider) Call(ctx context.Context, tool string, arguments json.RawMessage) (any, error) {
+	input := struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}{}
+
+	if err := mcp.DecodeArguments(arguments, &input); err != nil {
+		return nil, err
+	}
+
+	key := strings.TrimSpace(input.Key)
+
+	switch tool {
+	case "settings_get":
+		if key == "" {
+			return nil, mcp.NewToolError("key is required")
+		}
+
+		value, err := p.store.Get(ctx, key, "")
+		if err != nil {
+			return nil, err
+		}
+
+		return mcp.TextResult(map[string]any{"key": key, "value": value})
+	case "settings_set":
+		if key == "" {
+			return nil, mcp.NewToolError("key is required")
+		}
+
+		if err := p.store.Set(ctx, key, input.Value); err != nil {
+			return nil, err
+		}
+
+		return mcp.TextResult(map[string]any{"key": key, "value": input.Value})
+	}
+
+	return nil, mcp.ErrToolNotFound
+}
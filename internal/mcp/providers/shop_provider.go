@@ -0,0 +1,116 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+
+	"project/internal/mcp"
+
+	"github.com/dracory/shopstore"
+)
+
+// shopProvider provides the tools reading the products of the shop
+type shopProvider struct {
+	store shopstore.StoreInterface
+}
+
+var _ mcp.ProviderInterface = (*shopProvider)(nil)
+
+// NewShopProvider returns the provider of the shop product tools
+func NewShopProvider(store shopstore.StoreInterface) mcp.ProviderInterface {
+	return &shopProvider{store: store}
+}
+
+func (p *shopProvider) Name() string {
+	return mcp.PROVIDER_SHOP
+}
+
+func (p *shopProvider) Tools(ctx context.Context) ([]mcp.Tool, error) {
+	return []mcp.Tool{
+		{
+			Name:        "shop_product_list",
+			Description: "Lists the products of the shop, newest first",
+			InputSchema: mcp.ObjectSchema(listSchemaProperties(shopstore.PRODUCT_STATUS_ACTIVE, shopstore.PRODUCT_STATUS_DRAFT)),
+		},
+		{
+			Name:        "shop_product_get",
+			Description: "Gets a product of the shop",
+			InputSchema: mcp.ObjectSchema(idSchemaProperties("The ID of the product"), "id"),
+		},
+	}, nil
+}
+
+func (p *shopProvider) Call(ctx context.Context, tool string, arguments json.RawMessage) (any, error) {
+	switch tool {
+	case "shop_product_list":
+		return p.productList(ctx, arguments)
+	case "shop_product_get":
+		return p.productGet(ctx, arguments)
+	}
+
+	return nil, mcp.ErrToolNotFound
+}
+
+func (p *shopProvider) productList(ctx context.Context, arguments json.RawMessage) (any, error) {
+	input := listArguments{}
+	if err := mcp.DecodeArguments(arguments, &input); err != nil {
+		return nil, err
+	}
+
+	query := shopstore.NewProductQuery().
+		SetOrderBy("created_at").
+		SetSortDirection("DESC").
+		SetOffset(input.offset()).
+		SetLimit(input.limit())
+
+	if input.Status != "" {
+		query.SetStatus(input.Status)
+	}
+
+	products, err := p.store.ProductList(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]map[string]any, 0, len(products))
+	for _, product := range products {
+		items = append(items, productToMap(product))
+	}
+
+	return mcp.TextResult(items)
+}
+
+func (p *shopProvider) productGet(ctx context.Context, arguments json.RawMessage) (any, error) {
+	input := idArguments{}
+	if err := mcp.DecodeArguments(arguments, &input); err != nil {
+		return nil, err
+	}
+
+	if input.ID == "" {
+		return nil, mcp.NewToolError("id is required")
+	}
+
+	product, err := p.store.ProductFindByID(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product == nil {
+		return nil, mcp.NewToolError("product not found")
+	}
+
+	return mcp.TextResult(productToMap(product))
+}
+
+func productToMap(product shopstore.ProductInterface) map[string]any {
+	return map[string]any{
+		"id":          product.GetID(),
+		"title":       product.GetTitle(),
+		"description": product.GetDescription(),
+		"price":       product.GetPrice(),
+		"quantity":    product.GetQuantity(),
+		"status":      product.GetStatus(),
+		"created_at":  product.GetCreatedAt(),
+		"updated_at":  product.GetUpdatedAt(),
+	}
+}
@@ -0,0 +1,164 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"project/internal/mcp"
+
+	"github.com/dracory/taskstore"
+)
+
+// tasksProvider provides the tools reading the task queue, and adding
+// tasks to it
+type tasksProvider struct {
+	store taskstore.StoreInterface
+}
+
+var _ mcp.ProviderInterface = (*tasksProvider)(nil)
+
+// NewTasksProvider returns the provider of the task queue tools
+func NewTasksProvider(store taskstore.StoreInterface) mcp.ProviderInterface {
+	return &tasksProvider{store: store}
+}
+
+func (p *tasksProvider) Name() string {
+	return mcp.PROVIDER_TASKS
+}
+
+func (p *tasksProvider) Tools(ctx context.Context) ([]mcp.Tool, error) {
+	return []mcp.Tool{
+		{
+			Name:        "tasks_queue_list",
+			Description: "Lists the queued tasks, newest first",
+			InputSchema: mcp.ObjectSchema(listSchemaProperties()),
+		},
+		{
+			Name:        "tasks_queue_get",
+			Description: "Gets a queued task, with the details it logged",
+			InputSchema: mcp.ObjectSchema(idSchemaProperties("The ID of the queued task"), "id"),
+		},
+		{
+			Name:        "tasks_enqueue",
+			Description: "Adds a task to the default queue, to be run by the queue worker",
+			InputSchema: mcp.ObjectSchema(map[string]any{
+				"alias":      map[string]any{"type": "string", "description": "The alias of the task"},
+				"parameters": map[string]any{"type": "object", "description": "The parameters of the task"},
+			}, "alias"),
+		},
+	}, nil
+}
+
+func (p *tasksProvider) Call(ctx context.Context, tool string, arguments json.RawMessage) (any, error) {
+	switch tool {
+	case "tasks_queue_list":
+		return p.queueList(ctx, arguments)
+	case "tasks_queue_get":
+		return p.queueGet(ctx, arguments)
+	case "tasks_enqueue":
+		return p.enqueue(ctx, arguments)
+	}
+
+	return nil, mcp.ErrToolNotFound
+}
+
+func (p *tasksProvider) queueList(ctx context.Context, arguments json.RawMessage) (any, error) {
+	input := listArguments{}
+	if err := mcp.DecodeArguments(arguments, &input); err != nil {
+		return nil, err
+	}
+
+	query := taskstore.TaskQueueQuery().
+		SetOrderBy("created_at").
+		SetSortOrder("DESC").
+		SetOffset(input.offset()).
+		SetLimit(input.limit())
+
+	if input.Status != "" {
+		query.SetStatus(input.Status)
+	}
+
+	queuedTasks, err := p.store.TaskQueueList(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]map[string]any, 0, len(queuedTasks))
+	for _, queuedTask := range queuedTasks {
+		items = append(items, queuedTaskToMap(queuedTask))
+	}
+
+	return mcp.TextResult(items)
+}
+
+func (p *tasksProvider) queueGet(ctx context.Context, arguments json.RawMessage) (any, error) {
+	input := idArguments{}
+	if err := mcp.DecodeArguments(arguments, &input); err != nil {
+		return nil, err
+	}
+
+	if input.ID == "" {
+		return nil, mcp.NewToolError("id is required")
+	}
+
+	queuedTask, err := p.store.TaskQueueFindByID(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if queuedTask == nil {
+		return nil, mcp.NewToolError("queued task not found")
+	}
+
+	item := queuedTaskToMap(queuedTask)
+	item["details"] = queuedTask.GetDetails()
+
+	return mcp.TextResult(item)
+}
+
+func (p *tasksProvider) enqueue(ctx context.Context, arguments json.RawMessage) (any, error) {
+	input := struct {
+		Alias      string         `json:"alias"`
+		Parameters map[string]any `json:"parameters"`
+	}{}
+
+	if err := mcp.DecodeArguments(arguments, &input); err != nil {
+		return nil, err
+	}
+
+	alias := strings.TrimSpace(input.Alias)
+	if alias == "" {
+		return nil, mcp.NewToolError("alias is required")
+	}
+
+	if input.Parameters == nil {
+		input.Parameters = map[string]any{}
+	}
+
+	taskDefinition, err := p.store.TaskDefinitionFindByAlias(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	if taskDefinition == nil {
+		return nil, mcp.NewToolError("no task has the alias " + alias)
+	}
+
+	queuedTask, err := p.store.TaskDefinitionEnqueueByAlias(ctx, taskstore.DefaultQueueName, alias, input.Parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.TextResult(queuedTaskToMap(queuedTask))
+}
+
+func queuedTaskToMap(queuedTask taskstore.TaskQueueInterface) map[string]any {
+	return map[string]any{
+		"id":         queuedTask.GetID(),
+		"task_id":    queuedTask.GetTaskID(),
+		"status":     queuedTask.GetStatus(),
+		"created_at": queuedTask.GetCreatedAt(),
+		"updated_at": queuedTask.GetUpdatedAt(),
+	}
+}
@@ -0,0 +1,137 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/mcp"
+
+	"github.com/dracory/neat"
+	"github.com/dracory/userstore"
+)
+
+// usersProvider provides the tools reading the users. There are no tools
+// changing them
+type usersProvider struct {
+	app app.AppInterface
+}
+
+var _ mcp.ProviderInterface = (*usersProvider)(nil)
+
+// NewUsersProvider returns the provider of the read only user tools
+func NewUsersProvider(app app.AppInterface) mcp.ProviderInterface {
+	return &usersProvider{app: app}
+}
+
+func (p *usersProvider) Name() string {
+	return mcp.PROVIDER_USERS
+}
+
+func (p *usersProvider) Tools(ctx context.Context) ([]mcp.Tool, error) {
+	return []mcp.Tool{
+		{
+			Name:        "users_list",
+			Description: "Lists the users, newest first",
+			InputSchema: mcp.ObjectSchema(listSchemaProperties(userstore.USER_STATUS_ACTIVE, userstore.USER_STATUS_INACTIVE, userstore.USER_STATUS_UNVERIFIED, userstore.USER_STATUS_DELETED)),
+		},
+		{
+			Name:        "users_get",
+			Description: "Gets a user",
+			InputSchema: mcp.ObjectSchema(idSchemaProperties("The ID of the user"), "id"),
+		},
+	}, nil
+}
+
+func (p *usersProvider) Call(ctx context.Context, tool string, arguments json.RawMessage) (any, error) {
+	switch tool {
+	case "users_list":
+		return p.userList(ctx, arguments)
+	case "users_get":
+		return p.userGet(ctx, arguments)
+	}
+
+	return nil, mcp.ErrToolNotFound
+}
+
+func (p *usersProvider) userList(ctx context.Context, arguments json.RawMessage) (any, error) {
+	input := listArguments{}
+	if err := mcp.DecodeArguments(arguments, &input); err != nil {
+		return nil, err
+	}
+
+	query := userstore.NewUserQuery().
+		SetSortDirection(neat.SortDesc).
+		SetOrderBy(userstore.COLUMN_CREATED_AT).
+		SetOffset(input.offset()).
+		SetLimit(input.limit())
+
+	if input.Status != "" {
+		query.SetStatus(input.Status)
+	}
+
+	users, err := p.app.GetUserStore().UserList(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]map[string]any, 0, len(users))
+	for _, user := range users {
+		item, err := p.userToMap(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return mcp.TextResult(items)
+}
+
+func (p *usersProvider) userGet(ctx context.Context, arguments json.RawMessage) (any, error) {
+	input := idArguments{}
+	if err := mcp.DecodeArguments(arguments, &input); err != nil {
+		return nil, err
+	}
+
+	if input.ID == "" {
+		return nil, mcp.NewToolError("id is required")
+	}
+
+	user, err := p.app.GetUserStore().UserFindByID(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		return nil, mcp.NewToolError("user not found")
+	}
+
+	item, err := p.userToMap(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.TextResult(item)
+}
+
+// userToMap returns the fields of the user, decrypted from the vault if
+// it is used
+func (p *usersProvider) userToMap(ctx context.Context, user userstore.UserInterface) (map[string]any, error) {
+	email, firstName, lastName, _, _, err := ext.UserUntokenizeTransparently(ctx, p.app, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"id":         user.GetID(),
+		"email":      email,
+		"first_name": firstName,
+		"last_name":  lastName,
+		"role":       user.GetRole(),
+		"status":     user.GetStatus(),
+		"created_at": user.GetCreatedAt(),
+		"updated_at": user.GetUpdatedAt(),
+	}, nil
+}
@@ -0,0 +1,281 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// JSONRPC_VERSION is the version of JSON-RPC the MCP messages use
+const JSONRPC_VERSION = "2.0"
+
+// PROTOCOL_VERSION is the version of the MCP protocol the server speaks
+const PROTOCOL_VERSION = "2025-06-18"
+
+// MAX_REQUEST_BYTES is the maximum size of a request to the server
+const MAX_REQUEST_BYTES = 1 << 20
+
+// The JSON-RPC error codes
+const (
+	ERROR_CODE_PARSE            = -32700
+	ERROR_CODE_INVALID_REQUEST  = -32600
+	ERROR_CODE_METHOD_NOT_FOUND = -32601
+	ERROR_CODE_INVALID_PARAMS   = -32602
+	ERROR_CODE_INTERNAL         = -32603
+	// ERROR_CODE_FORBIDDEN is returned for the tools the client is not
+	// allowed to call
+	ERROR_CODE_FORBIDDEN = -32003
+)
+
+// The outcomes of the tool calls, see Invocation
+const (
+	INVOCATION_OUTCOME_SUCCESS = "success"
+	INVOCATION_OUTCOME_ERROR   = "error"
+	INVOCATION_OUTCOME_DENIED  = "denied"
+)
+
+// Request is a JSON-RPC request, or a notification when it has no ID
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response, with either a result or an error
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Invocation is a call of a tool by a client, reported to the audit
+// function of the server
+type Invocation struct {
+	Client    Client
+	IPAddress string
+	Tool      string
+	Outcome   string
+	Error     string
+	Duration  time.Duration
+}
+
+// ServerOptions are the options of the server
+type ServerOptions struct {
+	// Name and Version are the server info sent to the clients
+	Name    string
+	Version string
+
+	Providers []ProviderInterface
+
+	// Audit is called after each tool call, optional
+	Audit func(ctx context.Context, invocation Invocation)
+
+	// Logger logs the failures of the tools, optional
+	Logger *slog.Logger
+}
+
+// Server answers the JSON-RPC requests of the MCP clients, with the tools
+// of its providers the client is allowed to call
+type Server struct {
+	options   ServerOptions
+	providers map[string]ProviderInterface
+}
+
+// NewServer creates a server with the options
+func NewServer(options ServerOptions) *Server {
+	if options.Name == "" {
+		options.Name = "mcp"
+	}
+
+	providers := map[string]ProviderInterface{}
+	for _, provider := range options.Providers {
+		providers[provider.Name()] = provider
+	}
+
+	return &Server{options: options, providers: providers}
+}
+
+// Providers returns the providers of the server
+func (s *Server) Providers() []ProviderInterface {
+	return s.options.Providers
+}
+
+// Tools returns the tools of all the providers. The providers which fail
+// to list their tools are skipped, and their errors returned joined
+func (s *Server) Tools(ctx context.Context) ([]Tool, error) {
+	tools := []Tool{}
+	errs := []error{}
+
+	for _, provider := range s.options.Providers {
+		providerTools, err := provider.Tools(ctx)
+		if err != nil {
+			errs = append(errs, errors.New(provider.Name()+": "+err.Error()))
+			continue
+		}
+
+		tools = append(tools, providerTools...)
+	}
+
+	return tools, errors.Join(errs...)
+}
+
+// Handle answers the JSON-RPC request of the client, from the IP address.
+// It returns nil for the notifications, which have no response
+func (s *Server) Handle(ctx context.Context, client Client, ipAddress string, body []byte) *Response {
+	request := Request{}
+
+	if err := json.Unmarshal(body, &request); err != nil {
+		return errorResponse(nil, ERROR_CODE_PARSE, "parse error: the request must be a JSON-RPC object")
+	}
+
+	if request.JSONRPC != JSONRPC_VERSION || request.Method == "" {
+		return errorResponse(request.ID, ERROR_CODE_INVALID_REQUEST, "invalid request")
+	}
+
+	if len(request.ID) == 0 {
+		return nil
+	}
+
+	switch request.Method {
+	case "initialize":
+		return resultResponse(request.ID, map[string]any{
+			"protocolVersion": PROTOCOL_VERSION,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": s.options.Name, "version": s.options.Version},
+		})
+	case "ping":
+		return resultResponse(request.ID, map[string]any{})
+	case "tools/list", "list_tools":
+		return s.toolsList(ctx, client, request)
+	case "tools/call", "call_tool":
+		return s.toolsCall(ctx, client, ipAddress, request)
+	}
+
+	return errorResponse(request.ID, ERROR_CODE_METHOD_NOT_FOUND, "method not found: "+request.Method)
+}
+
+// toolsList answers with the tools the client is allowed to call
+func (s *Server) toolsList(ctx context.Context, client Client, request Request) *Response {
+	tools, err := s.Tools(ctx)
+	if err != nil {
+		s.logError("At mcp > Server > toolsList", err)
+	}
+
+	allowed := []Tool{}
+	for _, tool := range tools {
+		if client.Allows(tool.Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+
+	return resultResponse(request.ID, map[string]any{"tools": allowed})
+}
+
+// toolsCall calls the tool, if the client is allowed to, and audits it
+func (s *Server) toolsCall(ctx context.Context, client Client, ipAddress string, request Request) *Response {
+	params := struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}{}
+
+	if err := json.Unmarshal(request.Params, &params); err != nil || strings.TrimSpace(params.Name) == "" {
+		return errorResponse(request.ID, ERROR_CODE_INVALID_PARAMS, "the name of the tool is required")
+	}
+
+	start := time.Now()
+	invocation := Invocation{Client: client, IPAddress: ipAddress, Tool: params.Name}
+
+	if !client.Allows(params.Name) {
+		invocation.Outcome = INVOCATION_OUTCOME_DENIED
+		s.audit(ctx, invocation, start)
+		return errorResponse(request.ID, ERROR_CODE_FORBIDDEN, "the client is not allowed to call the tool "+params.Name)
+	}
+
+	provider, found := s.providers[toolProvider(params.Name)]
+	if !found {
+		invocation.Outcome = INVOCATION_OUTCOME_ERROR
+		invocation.Error = ErrToolNotFound.Error()
+		s.audit(ctx, invocation, start)
+		return errorResponse(request.ID, ERROR_CODE_INVALID_PARAMS, "unknown tool: "+params.Name)
+	}
+
+	result, err := provider.Call(ctx, params.Name, params.Arguments)
+
+	if errors.Is(err, ErrToolNotFound) {
+		invocation.Outcome = INVOCATION_OUTCOME_ERROR
+		invocation.Error = err.Error()
+		s.audit(ctx, invocation, start)
+		return errorResponse(request.ID, ERROR_CODE_INVALID_PARAMS, "unknown tool: "+params.Name)
+	}
+
+	if err != nil {
+		invocation.Outcome = INVOCATION_OUTCOME_ERROR
+		invocation.Error = err.Error()
+		s.audit(ctx, invocation, start)
+
+		message := "the tool failed, please try again later"
+
+		toolError := &ToolError{}
+		if errors.As(err, &toolError) {
+			message = toolError.Message
+		} else {
+			s.logError("At mcp > Server > toolsCall > "+params.Name, err)
+		}
+
+		return resultResponse(request.ID, map[string]any{
+			"content": []map[string]any{{"type": "text", "text": message}},
+			"isError": true,
+		})
+	}
+
+	invocation.Outcome = INVOCATION_OUTCOME_SUCCESS
+	s.audit(ctx, invocation, start)
+
+	return resultResponse(request.ID, result)
+}
+
+func (s *Server) audit(ctx context.Context, invocation Invocation, start time.Time) {
+	if s.options.Audit == nil {
+		return
+	}
+
+	invocation.Duration = time.Since(start)
+	s.options.Audit(ctx, invocation)
+}
+
+func (s *Server) logError(message string, err error) {
+	if s.options.Logger == nil {
+		return
+	}
+
+	s.options.Logger.Error(message, slog.String("error", err.Error()))
+}
+
+// ErrorResponse returns the JSON-RPC error response to the request ID,
+// e.g. for the requests rejected before they reach the server
+func ErrorResponse(id json.RawMessage, code int, message string) *Response {
+	return errorResponse(id, code, message)
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	return &Response{JSONRPC: JSONRPC_VERSION, ID: id, Error: &Error{Code: code, Message: message}}
+}
+
+func resultResponse(id json.RawMessage, result any) *Response {
+	return &Response{JSONRPC: JSONRPC_VERSION, ID: id, Result: result}
+}
@@ -0,0 +1,246 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// echoProvider provides the test_echo tool, returning its arguments, and
+// the test_fail tool, failing
+type echoProvider struct{}
+
+func (p echoProvider) Name() string {
+	return "test"
+}
+
+func (p echoProvider) Tools(ctx context.Context) ([]Tool, error) {
+	return []Tool{
+		{Name: "test_echo", InputSchema: ObjectSchema(map[string]any{"text": map[string]any{"type": "string"}}, "text")},
+		{Name: "test_fail", InputSchema: ObjectSchema(map[string]any{})},
+	}, nil
+}
+
+func (p echoProvider) Call(ctx context.Context, tool string, arguments json.RawMessage) (any, error) {
+	switch tool {
+	case "test_echo":
+		input := struct {
+			Text string `json:"text"`
+		}{}
+
+		if err := DecodeArguments(arguments, &input); err != nil {
+			return nil, err
+		}
+
+		if input.Text == "" {
+			return nil, NewToolError("text is required")
+		}
+
+		return TextResult(input)
+	case "test_fail":
+		return nil, errors.New("database is down")
+	}
+
+	return nil, ErrToolNotFound
+}
+
+func newTestServer(invocations *[]Invocation) *Server {
+	return NewServer(ServerOptions{
+		Name:      "test",
+		Providers: []ProviderInterface{echoProvider{}},
+		Audit: func(ctx context.Context, invocation Invocation) {
+			*invocations = append(*invocations, invocation)
+		},
+	})
+}
+
+func handleJSON(t *testing.T, server *Server, client Client, body string) map[string]any {
+	t.Helper()
+
+	response := server.Handle(context.Background(), client, "127.0.0.1", []byte(body))
+	if response == nil {
+		t.Fatalf("expected a response to %s", body)
+	}
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := map[string]any{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	return decoded
+}
+
+func TestServer_Initialize(t *testing.T) {
+	response := handleJSON(t, newTestServer(&[]Invocation{}), Client{}, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+
+	result, _ := response["result"].(map[string]any)
+	if result == nil || result["protocolVersion"] != PROTOCOL_VERSION {
+		t.Fatalf("expected the protocol version, got %v", response)
+	}
+}
+
+func TestServer_Notification(t *testing.T) {
+	server := newTestServer(&[]Invocation{})
+
+	if response := server.Handle(context.Background(), Client{}, "127.0.0.1", []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)); response != nil {
+		t.Fatalf("expected no response to a notification, got %+v", response)
+	}
+}
+
+func TestServer_InvalidRequests(t *testing.T) {
+	server := newTestServer(&[]Invocation{})
+
+	cases := map[string]float64{
+		`not json`: ERROR_CODE_PARSE,
+		`[{"jsonrpc":"2.0","id":1,"method":"ping"}]`:                 ERROR_CODE_PARSE,
+		`{"jsonrpc":"1.0","id":1,"method":"ping"}`:                   ERROR_CODE_INVALID_REQUEST,
+		`{"jsonrpc":"2.0","id":1,"method":"unknown"}`:                ERROR_CODE_METHOD_NOT_FOUND,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{}}`: ERROR_CODE_INVALID_PARAMS,
+	}
+
+	for body, code := range cases {
+		response := handleJSON(t, server, Client{}, body)
+
+		responseError, _ := response["error"].(map[string]any)
+		if responseError == nil || responseError["code"] != code {
+			t.Errorf("expected the error %v for %s, got %v", code, body, response)
+		}
+	}
+}
+
+func TestServer_ToolsListFiltersByPermission(t *testing.T) {
+	server := newTestServer(&[]Invocation{})
+
+	for _, method := range []string{"tools/list", "list_tools"} {
+		response := handleJSON(t, server, Client{Tools: []string{"test_echo"}}, `{"jsonrpc":"2.0","id":"1","method":"`+method+`"}`)
+
+		result, _ := response["result"].(map[string]any)
+		tools, _ := result["tools"].([]any)
+
+		if len(tools) != 1 || tools[0].(map[string]any)["name"] != "test_echo" {
+			t.Fatalf("expected only the allowed tool for %s, got %v", method, response)
+		}
+	}
+}
+
+func TestServer_ToolsCall(t *testing.T) {
+	invocations := []Invocation{}
+	server := newTestServer(&invocations)
+	client := Client{ID: "client_1", Tools: []string{"test_*"}}
+
+	response := handleJSON(t, server, client, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"test_echo","arguments":{"text":"hello"}}}`)
+
+	encoded, _ := json.Marshal(response["result"])
+	if !strings.Contains(string(encoded), `hello`) || strings.Contains(string(encoded), `isError`) {
+		t.Fatalf("expected the result of the tool, got %s", encoded)
+	}
+
+	if len(invocations) != 1 || invocations[0].Outcome != INVOCATION_OUTCOME_SUCCESS || invocations[0].Tool != "test_echo" || invocations[0].Client.ID != "client_1" || invocations[0].IPAddress != "127.0.0.1" {
+		t.Fatalf("expected the call to be audited, got %+v", invocations)
+	}
+}
+
+func TestServer_ToolsCallErrors(t *testing.T) {
+	invocations := []Invocation{}
+	server := newTestServer(&invocations)
+	client := Client{ID: "client_1", Tools: []string{"test_*"}}
+
+	// The errors of the tool are shown to the client
+	response := handleJSON(t, server, client, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"test_echo","arguments":{}}}`)
+	encoded, _ := json.Marshal(response["result"])
+	if !strings.Contains(string(encoded), `"isError":true`) || !strings.Contains(string(encoded), "text is required") {
+		t.Fatalf("expected the error of the tool, got %s", encoded)
+	}
+
+	// The other errors are not
+	response = handleJSON(t, server, client, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"test_fail"}}`)
+	encoded, _ = json.Marshal(response["result"])
+	if !strings.Contains(string(encoded), `"isError":true`) || strings.Contains(string(encoded), "database") {
+		t.Fatalf("expected a generic error, got %s", encoded)
+	}
+
+	// The unknown tools are invalid params
+	response = handleJSON(t, server, client, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"test_unknown"}}`)
+	if responseError, _ := response["error"].(map[string]any); responseError == nil || responseError["code"] != float64(ERROR_CODE_INVALID_PARAMS) {
+		t.Fatalf("expected invalid params for an unknown tool, got %v", response)
+	}
+
+	if len(invocations) != 3 || invocations[1].Error != "database is down" {
+		t.Fatalf("expected the failures to be audited, got %+v", invocations)
+	}
+}
+
+func TestServer_ToolsCallDenied(t *testing.T) {
+	invocations := []Invocation{}
+	server := newTestServer(&invocations)
+
+	response := handleJSON(t, server, Client{Tools: []string{"blog_*"}}, `{"jsonrpc":"2.0","id":1,"method":"call_tool","params":{"name":"test_echo","arguments":{"text":"hello"}}}`)
+
+	if responseError, _ := response["error"].(map[string]any); responseError == nil || responseError["code"] != float64(ERROR_CODE_FORBIDDEN) {
+		t.Fatalf("expected the call to be denied, got %v", response)
+	}
+
+	if len(invocations) != 1 || invocations[0].Outcome != INVOCATION_OUTCOME_DENIED {
+		t.Fatalf("expected the denied call to be audited, got %+v", invocations)
+	}
+}
+
+// upstreamHandler is an MCP handler of a store, with the post_list tool
+func upstreamHandler(w http.ResponseWriter, r *http.Request) {
+	request := Request{}
+	_ = json.NewDecoder(r.Body).Decode(&request)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch request.Method {
+	case "tools/list":
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"post_list","description":"Lists the posts","inputSchema":{"type":"object"}}]}}`))
+	case "tools/call":
+		params := struct {
+			Name string `json:"name"`
+		}{}
+		_ = json.Unmarshal(request.Params, &params)
+
+		if params.Name != "post_list" {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"unknown tool"}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"content":[{"type":"text","text":"[]"}]}}`))
+	}
+}
+
+func TestHandlerProvider(t *testing.T) {
+	provider := NewHandlerProvider(PROVIDER_BLOG, http.HandlerFunc(upstreamHandler))
+
+	tools, err := provider.Tools(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tools) != 1 || tools[0].Name != "blog_post_list" || tools[0].Description != "Lists the posts" {
+		t.Fatalf("expected the prefixed tools of the handler, got %+v", tools)
+	}
+
+	result, err := provider.Call(context.Background(), "blog_post_list", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, _ := json.Marshal(result)
+	if !strings.Contains(string(encoded), `"content"`) {
+		t.Fatalf("expected the result of the handler, got %s", encoded)
+	}
+
+	if _, err := provider.Call(context.Background(), "blog_unknown", nil); !errors.Is(err, ErrToolNotFound) {
+		t.Fatalf("expected ErrToolNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"project/internal/customrecords"
+	"sort"
+	"time"
+
+	"github.com/dracory/customstore"
+)
+
+// RECORD_TYPE_MCP_CLIENT is the record type of the clients in the custom
+// store
+const RECORD_TYPE_MCP_CLIENT = "mcp_client"
+
+// StoreInterface persists the clients
+type StoreInterface interface {
+	ClientCreate(client *Client) error
+	ClientFindByHash(hash string) (*Client, error)
+	ClientFindByID(id string) (*Client, error)
+	ClientList() ([]Client, error)
+	ClientUpdate(client *Client) error
+}
+
+type store struct {
+	customStore customstore.StoreInterface
+}
+
+var _ StoreInterface = (*store)(nil)
+
+// NewStore creates a client store on top of the custom store
+func NewStore(customStore customstore.StoreInterface) (StoreInterface, error) {
+	if customStore == nil {
+		return nil, errors.New("custom store is required")
+	}
+
+	return &store{customStore: customStore}, nil
+}
+
+// ClientCreate saves the client, and sets its ID
+func (s *store) ClientCreate(client *Client) error {
+	if client == nil || client.Name == "" || client.Hash == "" {
+		return errors.New("name and hash are required")
+	}
+
+	if client.CreatedAt.IsZero() {
+		client.CreatedAt = time.Now().UTC()
+	}
+
+	payload, err := json.Marshal(client)
+	if err != nil {
+		return err
+	}
+
+	record := customstore.NewRecord(RECORD_TYPE_MCP_CLIENT, customstore.WithPayload(string(payload)))
+
+	if err := s.customStore.RecordCreate(record); err != nil {
+		return err
+	}
+
+	client.ID = record.ID()
+
+	return nil
+}
+
+// ClientFindByHash returns the client with the key hash, or nil if not
+// found
+func (s *store) ClientFindByHash(hash string) (*Client, error) {
+	if hash == "" {
+		return nil, errors.New("hash is required")
+	}
+
+	clients, err := s.clientList(customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_MCP_CLIENT), map[string]string{"hash": hash})
+
+	if err != nil || len(clients) == 0 {
+		return nil, err
+	}
+
+	return &clients[0], nil
+}
+
+// ClientFindByID returns the client with the ID, or nil if not found
+func (s *store) ClientFindByID(id string) (*Client, error) {
+	if id == "" {
+		return nil, errors.New("client ID is required")
+	}
+
+	clients, err := s.clientList(customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_MCP_CLIENT).
+		SetID(id), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(clients) == 0 {
+		return nil, nil
+	}
+
+	return &clients[0], nil
+}
+
+// ClientList returns all the clients, the newest first
+func (s *store) ClientList() ([]Client, error) {
+	return s.clientList(customstore.NewRecordQuery().SetType(RECORD_TYPE_MCP_CLIENT), nil)
+}
+
+// ClientUpdate saves the changes to the client
+func (s *store) ClientUpdate(client *Client) error {
+	if client == nil || client.ID == "" {
+		return errors.New("client ID is required")
+	}
+
+	record, err := s.customStore.RecordFindByID(client.ID)
+	if err != nil {
+		return err
+	}
+
+	if record == nil {
+		return errors.New("client not found")
+	}
+
+	payload, err := json.Marshal(client)
+	if err != nil {
+		return err
+	}
+
+	record.SetPayload(string(payload))
+
+	return s.customStore.RecordUpdate(record)
+}
+
+func (s *store) clientList(query customstore.RecordQueryInterface, fields map[string]string) ([]Client, error) {
+	records, err := customrecords.FindByPayload(s.customStore, query, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := []Client{}
+
+	for _, record := range records {
+		client := Client{}
+		if err := json.Unmarshal([]byte(record.Payload()), &client); err != nil {
+			return nil, err
+		}
+
+		client.ID = record.ID()
+		clients = append(clients, client)
+	}
+
+	sort.SliceStable(clients, func(i, j int) bool {
+		return clients[i].CreatedAt.After(clients[j].CreatedAt)
+	})
+
+	return clients, nil
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"testing"
+	"time"
+
+	"project/internal/testutils"
+)
+
+func newTestStore(t *testing.T) StoreInterface {
+	t.Helper()
+
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	store, err := NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func TestNewStore_RequiresCustomStore(t *testing.T) {
+	if _, err := NewStore(nil); err == nil {
+		t.Error("NewStore(nil) expected error")
+	}
+}
+
+func TestStore_ClientCreateAndFind(t *testing.T) {
+	store := newTestStore(t)
+
+	client := &Client{Name: "Assistant", Hash: Hash("mcp_one"), Tools: []string{"blog_*"}}
+	if err := store.ClientCreate(client); err != nil {
+		t.Fatal(err)
+	}
+
+	if client.ID == "" || client.CreatedAt.IsZero() {
+		t.Fatalf("ClientCreate() must set the ID and the created at time, got %+v", client)
+	}
+
+	found, err := store.ClientFindByHash(Hash("mcp_one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found == nil || found.ID != client.ID || !found.Allows("blog_post_list") {
+		t.Fatalf("expected the client, got %+v", found)
+	}
+
+	notFound, err := store.ClientFindByHash(Hash("mcp_two"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if notFound != nil {
+		t.Fatalf("expected no client for another hash, got %+v", notFound)
+	}
+}
+
+func TestStore_ClientListAndUpdate(t *testing.T) {
+	store := newTestStore(t)
+
+	for i, client := range []*Client{
+		{Name: "One", Hash: Hash("mcp_one")},
+		{Name: "Two", Hash: Hash("mcp_two")},
+	} {
+		client.CreatedAt = time.Date(2026, 1, i+1, 0, 0, 0, 0, time.UTC)
+		if err := store.ClientCreate(client); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clients, err := store.ClientList()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(clients) != 2 || clients[0].Name != "Two" {
+		t.Fatalf("expected the 2 clients, the newest first, got %+v", clients)
+	}
+
+	client := clients[0]
+	client.RevokedAt = time.Now().UTC()
+
+	if err := store.ClientUpdate(&client); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := store.ClientFindByID(client.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated == nil || !updated.IsRevoked() {
+		t.Fatalf("expected the client to be revoked, got %+v", updated)
+	}
+}
+
+func TestStore_ClientCreateRequiresFields(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.ClientCreate(&Client{Name: "Assistant"}); err == nil {
+		t.Fatal("expected an error without the hash")
+	}
+}
@@ -3,6 +3,7 @@ package middlewares
 import (
 	"net/http"
 	"project/internal/app"
+	"project/internal/ext"
 	"project/internal/helpers"
 	"project/internal/links"
 
@@ -52,8 +53,10 @@ func (a *adminUserAdapter) HasRole(role string) bool {
 //  2. user must be active
 //  3. user must be registered
 //  4. user must be an admin or superuser
+//  5. user must have two-factor authentication enabled, if required
+//     by the AUTH_ADMIN_2FA_REQUIRED setting
 func NewAdminMiddleware(app app.AppInterface) rtr.MiddlewareInterface {
-	return rtrMiddleware.UserMiddleware(rtrMiddleware.UserMiddlewareConfig{
+	userMiddleware := rtrMiddleware.UserMiddleware(rtrMiddleware.UserMiddlewareConfig{
 		GetUser: func(r *http.Request) rtrMiddleware.UserMiddlewareUser {
 			user := helpers.GetAuthUser(r)
 			if user == nil {
@@ -81,4 +84,28 @@ func NewAdminMiddleware(app app.AppInterface) rtr.MiddlewareInterface {
 			helpers.ToFlash(app.GetCacheStore(), w, r, "error", "You must be an administrator to access this page", homeURL, 15)
 		},
 	})
+
+	if app.GetConfig() == nil || !app.GetConfig().GetAdminTwoFactorRequired() {
+		return userMiddleware
+	}
+
+	return rtr.NewMiddleware().
+		SetName("Admin Middleware").
+		SetHandler(func(next http.Handler) http.Handler {
+			return userMiddleware.GetHandler()(adminTwoFactorHandler(app, next))
+		})
+}
+
+// adminTwoFactorHandler sends the administrators without two-factor
+// authentication to enable it. It runs after the user middleware, so the
+// user is an authenticated administrator
+func adminTwoFactorHandler(app app.AppInterface, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ext.UserTwoFactorEnabled(helpers.GetAuthUser(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		helpers.ToFlashInfo(app.GetCacheStore(), w, r, "Please enable two-factor authentication to access the admin panel", links.User().TwoFactor(), 15)
+	})
 }
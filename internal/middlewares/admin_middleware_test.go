@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"project/internal/config"
+	"project/internal/ext"
 	"project/internal/helpers"
 	"project/internal/links"
 	"project/internal/testutils"
@@ -351,3 +352,81 @@ func TestAdminMiddleware_Success(t *testing.T) {
 		t.Fatalf("Expected body: %s, got: %s", "Success", body)
 	}
 }
+
+func TestAdminMiddleware_RequiresTwoFactorWhenConfigured(t *testing.T) {
+	// Arrange
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetGeoStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetVaultStoreUsed(true)
+	cfg.SetVaultStoreKey("test-key")
+	cfg.SetAdminTwoFactorRequired(true)
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	user, session, err := testutils.SeedUserAndSession(app.GetUserStore(), app.GetSessionStore(), test.ADMIN_01, httptest.NewRequest("GET", "/", nil), 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user.SetStatus(userstore.USER_STATUS_ACTIVE)
+	user.SetFirstName("First Name")
+	user.SetLastName("Last Name")
+	user.SetCountry("US")
+	user.SetTimezone("America/New_York")
+
+	if err := app.GetUserStore().UserUpdate(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	callAsAdmin := func() (string, *http.Response) {
+		body, response, err := test.CallMiddleware("GET", NewAdminMiddleware(app).GetHandler(), func(w http.ResponseWriter, r *http.Request) {
+			if _, err := w.Write([]byte("Success")); err != nil {
+				t.Errorf("failed to write response: %v", err)
+			}
+		}, test.NewRequestOptions{
+			Context: map[any]any{
+				config.AuthenticatedUserContextKey{}:    user,
+				config.AuthenticatedSessionContextKey{}: session,
+			},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return body, response
+	}
+
+	// Act: without two-factor authentication
+	body, response := callAsAdmin()
+
+	// Assert
+	if response.StatusCode != http.StatusSeeOther {
+		t.Fatalf("Expected status code %d, got %d", http.StatusSeeOther, response.StatusCode)
+	}
+
+	msg, err := testutils.FlashMessageFindFromBody(app.GetCacheStore(), body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg == nil || !strings.Contains(msg.Url, links.USER_TWO_FACTOR) {
+		t.Fatalf("Expected a redirect to the two-factor page, got %v", msg)
+	}
+
+	// Act: with two-factor authentication
+	if _, err := ext.UserTwoFactorEnable(context.Background(), app, user, "JBSWY3DPEHPK3PXP"); err != nil {
+		t.Fatal(err)
+	}
+
+	body, response = callAsAdmin()
+
+	// Assert
+	if response.StatusCode != http.StatusOK || body != "Success" {
+		t.Fatalf("Expected the admin to pass, got %d: %s", response.StatusCode, body)
+	}
+}
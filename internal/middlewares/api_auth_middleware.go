@@ -2,99 +2,87 @@ package middlewares
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"net/http"
-	"time"
+	"strings"
 
 	"project/internal/app"
 	"project/internal/config"
+	"project/internal/ext"
 
 	"github.com/dracory/api"
 	"github.com/dracory/rtr"
-	"github.com/dracory/sessionstore"
-	"github.com/dracory/userstore"
 )
 
-type apiAuthCacheItem struct {
-	session sessionstore.SessionInterface
-	user    userstore.UserInterface
-}
-
+// NewAPIAuthMiddleware authenticates the API requests with the personal
+// API token sent in the Authorization header ("Bearer <token>"), and adds
+// the token and its user to the request context.
+//
+// The scopes of the token are checked per route, see NewAPIScopeMiddleware.
+// The requests without a valid token are rate limited in the global group.
 func NewAPIAuthMiddleware(app app.AppInterface) rtr.MiddlewareInterface {
-	memoryCache := app.GetMemoryCache()
+	rateLimitUnauthenticated := rateLimitUnauthenticatedFunc(app, "NewAPIAuthMiddleware")
 
 	return rtr.NewMiddleware().
 		SetName("API Auth Middleware").
 		SetHandler(func(next http.Handler) http.Handler {
 			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				// 1. Get token from Authorization header
-				token := r.Header.Get("Authorization")
+				token := apiBearerToken(r)
 				if token == "" {
-					if _, err := w.Write([]byte(api.Error("Authorization token required").ToString())); err != nil {
-						http.Error(w, err.Error(), http.StatusInternalServerError)
-						return
-					}
-					return
-				}
-
-				cacheKey := "api-auth:" + token
-				if item := memoryCache.Get(cacheKey); item != nil {
-					if cacheData, ok := item.Value().(apiAuthCacheItem); ok {
-						if !cacheData.session.IsExpired() {
-							ctx := context.WithValue(r.Context(), config.APIAuthenticatedSessionContextKey{}, cacheData.session)
-							ctx = context.WithValue(ctx, config.APIAuthenticatedUserContextKey{}, cacheData.user)
-							next.ServeHTTP(w, r.WithContext(ctx))
-							return
-						}
-					}
-				}
-
-				// 2. Validate session token
-				session, err := app.GetSessionStore().SessionFindByKey(context.Background(), token)
-				if err != nil {
-					if _, writeErr := w.Write([]byte(api.Error("Failed to validate token").ToString())); writeErr != nil {
-						return
+					if rateLimitUnauthenticated(w, r) {
+						apiUnauthorized(w, "Authorization token required")
 					}
 					return
 				}
 
-				if session == nil || session.IsExpired() {
-					if _, err := w.Write([]byte(api.Error("Invalid or expired token").ToString())); err != nil {
-						return
-					}
-					return
-				}
+				// 2. Validate the token, and load its user
+				apiToken, user, err := ext.APITokenAuthenticate(r.Context(), app, token)
 
-				// 3. Load user and add session + user to request context
-				userID := session.GetUserID()
-				if userID == "" {
-					if _, err := w.Write([]byte(api.Error("Session missing user").ToString())); err != nil {
-						return
+				if errors.Is(err, ext.ErrAPITokenInvalid) {
+					if rateLimitUnauthenticated(w, r) {
+						apiUnauthorized(w, "Invalid or expired token")
 					}
 					return
 				}
 
-				user, err := app.GetUserStore().UserFindByID(r.Context(), userID)
 				if err != nil {
-					if _, writeErr := w.Write([]byte(api.Error("Failed to load user").ToString())); writeErr != nil {
-						return
-					}
+					app.GetLogger().Error("At APIAuthMiddleware > APITokenAuthenticate", slog.String("error", err.Error()))
+					apiErrorWrite(w, http.StatusInternalServerError, "Failed to validate token")
 					return
 				}
 
-				if user == nil {
-					if _, err := w.Write([]byte(api.Error("User not found").ToString())); err != nil {
-						return
-					}
-					return
-				}
-
-				// Cache the data for 1 minute
-				cacheData := apiAuthCacheItem{session: session, user: user}
-				memoryCache.Set(cacheKey, cacheData, 1*time.Minute)
-
-				ctx := context.WithValue(r.Context(), config.APIAuthenticatedSessionContextKey{}, session)
+				// 3. Add token + user to request context
+				ctx := context.WithValue(r.Context(), config.APIAuthenticatedTokenContextKey{}, apiToken)
 				ctx = context.WithValue(ctx, config.APIAuthenticatedUserContextKey{}, user)
 				next.ServeHTTP(w, r.WithContext(ctx))
 			})
 		})
 }
+
+// apiBearerToken returns the token of the "Bearer" Authorization header
+func apiBearerToken(r *http.Request) string {
+	scheme, token, found := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+func apiUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	apiErrorWrite(w, http.StatusUnauthorized, message)
+}
+
+// apiErrorWrite writes the error as a JSON response with the status
+func apiErrorWrite(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if _, err := w.Write([]byte(api.Error(message).ToString())); err != nil {
+		return
+	}
+}
@@ -7,23 +7,52 @@ import (
 	"testing"
 	"time"
 
+	"project/internal/apitoken"
+	"project/internal/app"
 	"project/internal/config"
+	"project/internal/ext"
+	"project/internal/ratelimit"
 	"project/internal/testutils"
 
 	"github.com/dracory/api"
-	"github.com/dracory/sessionstore"
 	"github.com/dracory/test"
 	"github.com/dracory/userstore"
 )
 
-func TestAPIAuthMiddleware_MissingAuthorizationHeader(t *testing.T) {
-	app := testutils.Setup(
+func setupAPIAuthApp(t *testing.T) app.AppInterface {
+	t.Helper()
+
+	return testutils.Setup(
 		testutils.WithSessionStore(true),
 		testutils.WithUserStore(true),
+		testutils.WithCustomStore(true),
 	)
+}
 
-	middleware := NewAPIAuthMiddleware(app).GetHandler()
+// seedAPIToken creates a token for a new user, and returns the token
+func seedAPIToken(t *testing.T, app app.AppInterface, expiresAt time.Time) (string, userstore.UserInterface) {
+	t.Helper()
+
+	user, err := testutils.SeedUser(app.GetUserStore(), test.USER_01)
+	if err != nil {
+		t.Fatalf("failed to seed user: %v", err)
+	}
+
+	token, _, err := ext.APITokenCreate(app, user, "Test", []string{apitoken.SCOPE_PROFILE_READ}, expiresAt)
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+
+	return token, user
+}
+
+// callAPIAuthMiddleware calls the middleware with the Authorization header,
+// and returns whether the next handler was called
+func callAPIAuthMiddleware(app app.AppInterface, authorization string) (*httptest.ResponseRecorder, bool) {
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
 	res := httptest.NewRecorder()
 	nextCalled := false
 
@@ -31,74 +60,77 @@ func TestAPIAuthMiddleware_MissingAuthorizationHeader(t *testing.T) {
 		nextCalled = true
 	})
 
-	middleware(next).ServeHTTP(res, req)
+	NewAPIAuthMiddleware(app).GetHandler()(next).ServeHTTP(res, req)
+
+	return res, nextCalled
+}
+
+func TestAPIAuthMiddleware_MissingAuthorizationHeader(t *testing.T) {
+	app := setupAPIAuthApp(t)
+
+	res, nextCalled := callAPIAuthMiddleware(app, "")
 
 	if nextCalled {
 		t.Error("next handler should not be called when token is missing")
 	}
+	if res.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, res.Code)
+	}
 	expected := api.Error("Authorization token required").ToString()
 	if res.Body.String() != expected {
 		t.Errorf("Expected body '%s', got '%s'", expected, res.Body.String())
 	}
 }
 
-func TestAPIAuthMiddleware_InvalidToken(t *testing.T) {
-	app := testutils.Setup(
-		testutils.WithSessionStore(true),
-		testutils.WithUserStore(true),
-	)
-
-	middleware := NewAPIAuthMiddleware(app).GetHandler()
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.Header.Set("Authorization", "invalid-token")
-	res := httptest.NewRecorder()
-	nextCalled := false
-
-	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		nextCalled = true
-	})
+func TestAPIAuthMiddleware_TokenWithoutBearerScheme(t *testing.T) {
+	app := setupAPIAuthApp(t)
+	token, _ := seedAPIToken(t, app, time.Time{})
 
-	middleware(next).ServeHTTP(res, req)
+	res, nextCalled := callAPIAuthMiddleware(app, token)
 
 	if nextCalled {
-		t.Error("next handler should not be called for invalid token")
+		t.Error("next handler should not be called without the Bearer scheme")
 	}
-	expected := api.Error("Invalid or expired token").ToString()
+	expected := api.Error("Authorization token required").ToString()
 	if res.Body.String() != expected {
 		t.Errorf("Expected body '%s', got '%s'", expected, res.Body.String())
 	}
 }
 
-func TestAPIAuthMiddleware_ExpiredSession(t *testing.T) {
-	app := testutils.Setup(
-		testutils.WithSessionStore(true),
-		testutils.WithUserStore(true),
-	)
+func TestAPIAuthMiddleware_SessionKeyRejected(t *testing.T) {
+	app := setupAPIAuthApp(t)
 
 	user, err := testutils.SeedUser(app.GetUserStore(), test.USER_01)
 	if err != nil {
 		t.Fatalf("failed to seed user: %v", err)
 	}
 
-	session, err := testutils.SeedSession(app.GetSessionStore(), httptest.NewRequest(http.MethodGet, "/", nil), user, -60)
+	session, err := testutils.SeedSession(app.GetSessionStore(), httptest.NewRequest(http.MethodGet, "/", nil), user, 3600)
 	if err != nil {
 		t.Fatalf("failed to seed session: %v", err)
 	}
 
-	middleware := NewAPIAuthMiddleware(app).GetHandler()
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.Header.Set("Authorization", session.GetKey())
-	res := httptest.NewRecorder()
-	nextCalled := false
+	res, nextCalled := callAPIAuthMiddleware(app, "Bearer "+session.GetKey())
 
-	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		nextCalled = true
-	})
+	if nextCalled {
+		t.Error("next handler should not be called for a browser session key")
+	}
+	expected := api.Error("Invalid or expired token").ToString()
+	if res.Body.String() != expected {
+		t.Errorf("Expected body '%s', got '%s'", expected, res.Body.String())
+	}
+}
 
-	middleware(next).ServeHTTP(res, req)
+func TestAPIAuthMiddleware_InvalidToken(t *testing.T) {
+	app := setupAPIAuthApp(t)
+
+	res, nextCalled := callAPIAuthMiddleware(app, "Bearer "+apitoken.TOKEN_PREFIX+"invalid")
 
 	if nextCalled {
-		t.Error("next handler should not be called for expired session")
+		t.Error("next handler should not be called for invalid token")
+	}
+	if res.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, res.Code)
 	}
 	expected := api.Error("Invalid or expired token").ToString()
 	if res.Body.String() != expected {
@@ -106,116 +138,75 @@ func TestAPIAuthMiddleware_ExpiredSession(t *testing.T) {
 	}
 }
 
-func TestAPIAuthMiddleware_SessionMissingUser(t *testing.T) {
-	app := testutils.Setup(
-		testutils.WithSessionStore(true),
-		testutils.WithUserStore(true),
-	)
+func TestAPIAuthMiddleware_RevokedToken(t *testing.T) {
+	app := setupAPIAuthApp(t)
+	token, _ := seedAPIToken(t, app, time.Time{})
 
-	baseReq := httptest.NewRequest(http.MethodGet, "/", nil)
-	session := sessionstore.NewSession().
-		SetUserID("").
-		SetUserAgent(baseReq.UserAgent()).
-		SetIPAddress("127.0.0.1").
-		SetExpiresAt(time.Now().Add(time.Hour).UTC().Format("2006-01-02 15:04:05"))
+	if _, nextCalled := callAPIAuthMiddleware(app, "Bearer "+token); !nextCalled {
+		t.Fatal("next handler should be called before the token is revoked")
+	}
 
-	if err := app.GetSessionStore().SessionCreate(baseReq.Context(), session); err != nil {
-		t.Fatalf("failed to create session: %v", err)
+	store, err := apitoken.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	middleware := NewAPIAuthMiddleware(app).GetHandler()
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.Header.Set("Authorization", session.GetKey())
-	res := httptest.NewRecorder()
-	nextCalled := false
+	stored, err := store.TokenFindByHash(apitoken.Hash(token))
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		nextCalled = true
-	})
+	if err := ext.APITokenRevoke(app, stored); err != nil {
+		t.Fatal(err)
+	}
 
-	middleware(next).ServeHTTP(res, req)
+	res, nextCalled := callAPIAuthMiddleware(app, "Bearer "+token)
 
 	if nextCalled {
-		t.Error("next handler should not be called when session is missing user")
+		t.Error("next handler should not be called for a revoked token")
 	}
-	expected := api.Error("Session missing user").ToString()
+	expected := api.Error("Invalid or expired token").ToString()
 	if res.Body.String() != expected {
 		t.Errorf("Expected body '%s', got '%s'", expected, res.Body.String())
 	}
 }
 
 func TestAPIAuthMiddleware_UserNotFound(t *testing.T) {
-	app := testutils.Setup(
-		testutils.WithSessionStore(true),
-		testutils.WithUserStore(true),
-	)
-
-	userStore := app.GetUserStore()
-	user, err := testutils.SeedUser(userStore, test.USER_01)
-	if err != nil {
-		t.Fatalf("failed to seed user: %v", err)
-	}
-
-	session, err := testutils.SeedSession(app.GetSessionStore(), httptest.NewRequest(http.MethodGet, "/", nil), user, 3600)
-	if err != nil {
-		t.Fatalf("failed to seed session: %v", err)
-	}
+	app := setupAPIAuthApp(t)
+	token, user := seedAPIToken(t, app, time.Time{})
 
-	if err := userStore.UserDelete(context.Background(), user); err != nil {
+	if err := app.GetUserStore().UserDelete(context.Background(), user); err != nil {
 		t.Fatalf("failed to delete user: %v", err)
 	}
 
-	middleware := NewAPIAuthMiddleware(app).GetHandler()
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.Header.Set("Authorization", session.GetKey())
-	res := httptest.NewRecorder()
-	nextCalled := false
-
-	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		nextCalled = true
-	})
-
-	middleware(next).ServeHTTP(res, req)
+	res, nextCalled := callAPIAuthMiddleware(app, "Bearer "+token)
 
 	if nextCalled {
 		t.Error("next handler should not be called when user is missing")
 	}
-	expected := api.Error("User not found").ToString()
+	expected := api.Error("Invalid or expired token").ToString()
 	if res.Body.String() != expected {
 		t.Errorf("Expected body '%s', got '%s'", expected, res.Body.String())
 	}
 }
 
 func TestAPIAuthMiddleware_Success(t *testing.T) {
-	app := testutils.Setup(
-		testutils.WithSessionStore(true),
-		testutils.WithUserStore(true),
-	)
-
-	user, err := testutils.SeedUser(app.GetUserStore(), test.USER_01)
-	if err != nil {
-		t.Fatalf("failed to seed user: %v", err)
-	}
-
-	session, err := testutils.SeedSession(app.GetSessionStore(), httptest.NewRequest(http.MethodGet, "/", nil), user, 3600)
-	if err != nil {
-		t.Fatalf("failed to seed session: %v", err)
-	}
+	app := setupAPIAuthApp(t)
+	token, user := seedAPIToken(t, app, time.Now().Add(time.Hour))
 
-	middleware := NewAPIAuthMiddleware(app).GetHandler()
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	req.Header.Set("Authorization", session.GetKey())
+	req.Header.Set("Authorization", "Bearer "+token)
 	res := httptest.NewRecorder()
 	nextCalled := false
-	var ctxSession sessionstore.SessionInterface
+	var ctxToken *apitoken.Token
 	var ctxUser userstore.UserInterface
 
 	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		nextCalled = true
 		var ok bool
-		ctxSession, ok = r.Context().Value(config.APIAuthenticatedSessionContextKey{}).(sessionstore.SessionInterface)
+		ctxToken, ok = r.Context().Value(config.APIAuthenticatedTokenContextKey{}).(*apitoken.Token)
 		if !ok {
-			t.Error("session should be in request context")
+			t.Error("token should be in request context")
 		}
 		ctxUser, ok = r.Context().Value(config.APIAuthenticatedUserContextKey{}).(userstore.UserInterface)
 		if !ok {
@@ -224,22 +215,49 @@ func TestAPIAuthMiddleware_Success(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	middleware(next).ServeHTTP(res, req)
+	NewAPIAuthMiddleware(app).GetHandler()(next).ServeHTTP(res, req)
 
 	if !nextCalled {
-		t.Error("next handler should be called when authentication succeeds")
+		t.Fatal("next handler should be called when authentication succeeds")
 	}
-	if ctxSession != nil {
-		if session.GetKey() != ctxSession.GetKey() {
-			t.Errorf("context session key should match: expected %s, got %s", session.GetKey(), ctxSession.GetKey())
-		}
+	if ctxToken == nil || ctxToken.Hash != apitoken.Hash(token) {
+		t.Errorf("context token should be the token used")
 	}
-	if ctxUser != nil {
-		if user.GetID() != ctxUser.GetID() {
-			t.Errorf("context user id should match: expected %s, got %s", user.GetID(), ctxUser.GetID())
-		}
+	if ctxUser == nil || user.GetID() != ctxUser.GetID() {
+		t.Errorf("context user should be the user of the token")
 	}
 	if res.Result().StatusCode != http.StatusOK {
 		t.Errorf("Expected status %d, got %d", http.StatusOK, res.Result().StatusCode)
 	}
 }
+
+func TestAPIAuthMiddleware_RateLimitsInvalidTokens(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetUserStoreUsed(true)
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetRateLimitPolicies(map[string][]ratelimit.Policy{
+		ratelimit.GROUP_GLOBAL: {{Limit: 2, Window: time.Minute}},
+	})
+	app := testutils.Setup(testutils.WithCfg(cfg))
+	middleware := NewAPIAuthMiddleware(app).GetHandler()
+
+	codes := []int{}
+	for _, authorization := range []string{"", "Bearer " + apitoken.TOKEN_PREFIX + "invalid", "Bearer " + apitoken.TOKEN_PREFIX + "other"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		res, nextCalled := callRateLimitMiddleware(middleware, req)
+
+		if nextCalled {
+			t.Fatal("next handler should not be called without a valid token")
+		}
+
+		codes = append(codes, res.Code)
+	}
+
+	if codes[0] != http.StatusUnauthorized || codes[1] != http.StatusUnauthorized || codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("expected the requests without a valid token to be limited, got %v", codes)
+	}
+}
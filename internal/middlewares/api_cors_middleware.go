@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/url"
+	"project/internal/app"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dracory/rtr"
+)
+
+// apiCorsMaxAgeSeconds is how long the browsers may cache a preflight
+const apiCorsMaxAgeSeconds = 600
+
+// apiCorsAllowedMethods are the methods the API accepts from the browsers
+var apiCorsAllowedMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// NewAPICorsMiddleware lets the browsers call the API from the origins
+// allowed in the config (API_CORS_ALLOWED_ORIGINS), and from the
+// application itself.
+//
+// It answers the preflight (OPTIONS) requests, so it must be added before
+// NewAPIAuthMiddleware, as the browsers send no token with them.
+func NewAPICorsMiddleware(app app.AppInterface) rtr.MiddlewareInterface {
+	return rtr.NewMiddleware().
+		SetName("API CORS Middleware").
+		SetHandler(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				origin := r.Header.Get("Origin")
+				isPreflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+				if origin == "" {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				w.Header().Add("Vary", "Origin")
+
+				allowOrigin := apiCorsAllowOrigin(app, origin)
+
+				if allowOrigin != "" {
+					w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+				}
+
+				if !isPreflight {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				if allowOrigin != "" {
+					w.Header().Set("Access-Control-Allow-Methods", strings.Join(apiCorsAllowedMethods, ", "))
+					w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(apiCorsMaxAgeSeconds))
+				}
+
+				w.WriteHeader(http.StatusNoContent)
+			})
+		})
+}
+
+// apiCorsAllowOrigin returns the value of the Access-Control-Allow-Origin
+// header for the origin, or an empty string if the origin is not allowed
+func apiCorsAllowOrigin(app app.AppInterface, origin string) string {
+	if app == nil || app.GetConfig() == nil {
+		return ""
+	}
+
+	allowedOrigins := app.GetConfig().GetAPICorsAllowedOrigins()
+
+	if slices.Contains(allowedOrigins, "*") {
+		return "*"
+	}
+
+	if slices.Contains(allowedOrigins, origin) {
+		return origin
+	}
+
+	appURL, err := url.Parse(app.GetConfig().GetAppUrl())
+
+	if err == nil && appURL.Host != "" && appURL.Scheme+"://"+appURL.Host == origin {
+		return origin
+	}
+
+	return ""
+}
@@ -0,0 +1,112 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"project/internal/testutils"
+)
+
+func callAPICorsMiddleware(t *testing.T, origins []string, method string, headers map[string]string) (*httptest.ResponseRecorder, bool) {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetAppUrl("https://example.com")
+	cfg.SetAPICorsAllowedOrigins(origins)
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	req := httptest.NewRequest(method, "/api/v1/me", nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	res := httptest.NewRecorder()
+	nextCalled := false
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+	})
+
+	NewAPICorsMiddleware(app).GetHandler()(next).ServeHTTP(res, req)
+
+	return res, nextCalled
+}
+
+func TestAPICorsMiddleware_NoOrigin(t *testing.T) {
+	res, nextCalled := callAPICorsMiddleware(t, []string{"https://app.example.com"}, http.MethodGet, nil)
+
+	if !nextCalled {
+		t.Fatal("next handler should be called")
+	}
+
+	if res.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("no CORS headers expected without the Origin header")
+	}
+}
+
+func TestAPICorsMiddleware_AllowedOrigin(t *testing.T) {
+	res, nextCalled := callAPICorsMiddleware(t, []string{"https://app.example.com"}, http.MethodGet, map[string]string{
+		"Origin": "https://app.example.com",
+	})
+
+	if !nextCalled {
+		t.Fatal("next handler should be called")
+	}
+
+	if got := res.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the origin", got)
+	}
+}
+
+func TestAPICorsMiddleware_AppOrigin(t *testing.T) {
+	res, _ := callAPICorsMiddleware(t, nil, http.MethodGet, map[string]string{
+		"Origin": "https://example.com",
+	})
+
+	if got := res.Header().Get("Access-Control-Allow-Origin"); got != "https://example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the application origin", got)
+	}
+}
+
+func TestAPICorsMiddleware_Wildcard(t *testing.T) {
+	res, _ := callAPICorsMiddleware(t, []string{"*"}, http.MethodGet, map[string]string{
+		"Origin": "https://anywhere.example.org",
+	})
+
+	if got := res.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+	}
+}
+
+func TestAPICorsMiddleware_DisallowedOrigin(t *testing.T) {
+	res, nextCalled := callAPICorsMiddleware(t, []string{"https://app.example.com"}, http.MethodGet, map[string]string{
+		"Origin": "https://evil.example.org",
+	})
+
+	if !nextCalled {
+		t.Fatal("next handler should be called, the browser blocks the response")
+	}
+
+	if got := res.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want none", got)
+	}
+}
+
+func TestAPICorsMiddleware_Preflight(t *testing.T) {
+	res, nextCalled := callAPICorsMiddleware(t, []string{"https://app.example.com"}, http.MethodOptions, map[string]string{
+		"Origin":                        "https://app.example.com",
+		"Access-Control-Request-Method": http.MethodPost,
+	})
+
+	if nextCalled {
+		t.Fatal("next handler should not be called for the preflight")
+	}
+
+	if res.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", res.Code, http.StatusNoContent)
+	}
+
+	if res.Header().Get("Access-Control-Allow-Headers") == "" || res.Header().Get("Access-Control-Allow-Methods") == "" {
+		t.Error("expected the allowed headers and methods")
+	}
+}
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"project/internal/apitoken"
+	"project/internal/helpers"
+
+	"github.com/dracory/rtr"
+)
+
+// NewAPIScopeMiddleware allows the request only if the personal API token
+// it is authenticated with has the scope. It must be added after
+// NewAPIAuthMiddleware.
+//
+// The admin only scopes also require the user to still be an
+// administrator, as a token outlives a change of role.
+func NewAPIScopeMiddleware(scope string) rtr.MiddlewareInterface {
+	return rtr.NewMiddleware().
+		SetName("API Scope Middleware (" + scope + ")").
+		SetHandler(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				token := helpers.GetAPIAuthToken(r)
+
+				if token == nil {
+					apiUnauthorized(w, "Authorization token required")
+					return
+				}
+
+				if !token.HasScope(scope) {
+					apiErrorWrite(w, http.StatusForbidden, "The token does not have the "+scope+" scope")
+					return
+				}
+
+				if apiScope := apitoken.ScopeFind(scope); apiScope != nil && apiScope.AdminOnly {
+					user := helpers.GetAPIAuthUser(r)
+
+					if user == nil || (!user.IsAdministrator() && !user.IsSuperuser()) {
+						apiErrorWrite(w, http.StatusForbidden, "The "+scope+" scope is for administrators only")
+						return
+					}
+				}
+
+				next.ServeHTTP(w, r)
+			})
+		})
+}
@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"project/internal/apitoken"
+	"project/internal/config"
+
+	"github.com/dracory/userstore"
+)
+
+func TestAPIScopeMiddleware(t *testing.T) {
+	cases := []struct {
+		name           string
+		token          *apitoken.Token
+		expectedStatus int
+		expectedNext   bool
+	}{
+		{"no token", nil, http.StatusUnauthorized, false},
+		{"missing scope", &apitoken.Token{Scopes: []string{apitoken.SCOPE_BLOG_READ}}, http.StatusForbidden, false},
+		{"granted scope", &apitoken.Token{Scopes: []string{apitoken.SCOPE_PROFILE_READ}}, http.StatusOK, true},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if c.token != nil {
+			req = req.WithContext(context.WithValue(req.Context(), config.APIAuthenticatedTokenContextKey{}, c.token))
+		}
+		res := httptest.NewRecorder()
+		nextCalled := false
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			nextCalled = true
+		})
+
+		NewAPIScopeMiddleware(apitoken.SCOPE_PROFILE_READ).GetHandler()(next).ServeHTTP(res, req)
+
+		if nextCalled != c.expectedNext {
+			t.Errorf("%s: next called = %v, want %v", c.name, nextCalled, c.expectedNext)
+		}
+
+		if res.Code != c.expectedStatus {
+			t.Errorf("%s: status = %d, want %d", c.name, res.Code, c.expectedStatus)
+		}
+	}
+}
+
+func TestAPIScopeMiddleware_AdminOnlyScope(t *testing.T) {
+	token := &apitoken.Token{Scopes: []string{apitoken.SCOPE_USERS_READ}}
+
+	for _, role := range []string{userstore.USER_ROLE_USER, userstore.USER_ROLE_ADMINISTRATOR} {
+		user := userstore.NewUser().SetRole(role)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx := context.WithValue(req.Context(), config.APIAuthenticatedTokenContextKey{}, token)
+		ctx = context.WithValue(ctx, config.APIAuthenticatedUserContextKey{}, user)
+		res := httptest.NewRecorder()
+		nextCalled := false
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			nextCalled = true
+		})
+
+		NewAPIScopeMiddleware(apitoken.SCOPE_USERS_READ).GetHandler()(next).ServeHTTP(res, req.WithContext(ctx))
+
+		isAdmin := role == userstore.USER_ROLE_ADMINISTRATOR
+
+		if nextCalled != isAdmin {
+			t.Errorf("%s: next called = %v, want %v", role, nextCalled, isAdmin)
+		}
+
+		if !isAdmin && res.Code != http.StatusForbidden {
+			t.Errorf("%s: status = %d, want %d", role, res.Code, http.StatusForbidden)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/ext"
+	"project/internal/mcp"
+
+	"github.com/dracory/rtr"
+)
+
+// NewMCPAuthMiddleware authenticates the MCP requests with the key of an
+// MCP client, sent in the X-MCP-API-Key header or as a bearer token, and
+// adds the client to the request context.
+//
+// The tools the client may call are checked by the MCP server. The
+// requests without a valid key are rate limited in the global group.
+func NewMCPAuthMiddleware(app app.AppInterface) rtr.MiddlewareInterface {
+	rateLimitUnauthenticated := rateLimitUnauthenticatedFunc(app, "NewMCPAuthMiddleware")
+
+	return rtr.NewMiddleware().
+		SetName("MCP Auth Middleware").
+		SetHandler(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// 1. The clients are kept in the custom store, the legacy
+				// key is in the config
+				legacyKey := ""
+				if app.GetConfig() != nil {
+					legacyKey = strings.TrimSpace(app.GetConfig().GetCmsMcpApiKey())
+				}
+
+				if app.GetCustomStore() == nil && legacyKey == "" {
+					mcpErrorWrite(w, http.StatusServiceUnavailable, "MCP is not configured")
+					return
+				}
+
+				// 2. Get the key from the headers
+				key := strings.TrimSpace(r.Header.Get("X-MCP-API-Key"))
+				if key == "" {
+					key = apiBearerToken(r)
+				}
+
+				if key == "" {
+					if rateLimitUnauthenticated(w, r) {
+						mcpErrorWrite(w, http.StatusUnauthorized, "MCP client key required, check the X-MCP-API-Key header")
+					}
+					return
+				}
+
+				// 3. Validate the key
+				client, err := ext.MCPClientAuthenticate(app, key)
+
+				if errors.Is(err, ext.ErrMCPClientInvalid) {
+					if rateLimitUnauthenticated(w, r) {
+						mcpErrorWrite(w, http.StatusUnauthorized, "Invalid MCP client key")
+					}
+					return
+				}
+
+				if err != nil {
+					app.GetLogger().Error("At MCPAuthMiddleware > MCPClientAuthenticate", slog.String("error", err.Error()))
+					mcpErrorWrite(w, http.StatusInternalServerError, "Failed to validate the MCP client key")
+					return
+				}
+
+				// 4. Add the client to the request context
+				ctx := context.WithValue(r.Context(), config.MCPAuthenticatedClientContextKey{}, client)
+				next.ServeHTTP(w, r.WithContext(ctx))
+			})
+		})
+}
+
+// mcpErrorWrite writes the error as a JSON-RPC error response with the
+// status
+func mcpErrorWrite(w http.ResponseWriter, status int, message string) {
+	code := mcp.ERROR_CODE_INVALID_REQUEST
+	if status >= http.StatusInternalServerError {
+		code = mcp.ERROR_CODE_INTERNAL
+	}
+
+	body, err := json.Marshal(mcp.ErrorResponse(nil, code, message))
+	if err != nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if _, err := w.Write(body); err != nil {
+		return
+	}
+}
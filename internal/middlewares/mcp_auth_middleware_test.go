@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/testutils"
+)
+
+// callMCPAuthMiddleware calls the middleware with the headers, and returns
+// the client the next handler got, if called
+func callMCPAuthMiddleware(app app.AppInterface, headers map[string]string) (*httptest.ResponseRecorder, bool, string) {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	res := httptest.NewRecorder()
+	nextCalled := false
+	clientID := ""
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+		if client := helpers.GetMCPClient(r); client != nil {
+			clientID = client.ID
+		}
+	})
+
+	NewMCPAuthMiddleware(app).GetHandler()(next).ServeHTTP(res, req)
+
+	return res, nextCalled, clientID
+}
+
+func TestMCPAuthMiddleware_NotConfigured(t *testing.T) {
+	app := testutils.Setup()
+
+	res, nextCalled, _ := callMCPAuthMiddleware(app, map[string]string{"X-MCP-API-Key": "key"})
+
+	if nextCalled || res.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected %d without clients nor legacy key, got %d", http.StatusServiceUnavailable, res.Code)
+	}
+}
+
+func TestMCPAuthMiddleware_Keys(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetCustomStoreUsed(true)
+	cfg.SetCmsMcpApiKey("shared-key")
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	key, client, err := ext.MCPClientCreate(app, "Assistant", []string{"blog_*"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, headers := range []map[string]string{{}, {"X-MCP-API-Key": "wrong"}, {"Authorization": "Bearer mcp_wrong"}} {
+		res, nextCalled, _ := callMCPAuthMiddleware(app, headers)
+
+		if nextCalled || res.Code != http.StatusUnauthorized {
+			t.Errorf("expected %d for %v, got %d", http.StatusUnauthorized, headers, res.Code)
+		}
+		if !strings.Contains(res.Body.String(), `"jsonrpc":"2.0"`) {
+			t.Errorf("expected a JSON-RPC error body, got %q", res.Body.String())
+		}
+	}
+
+	cases := []struct {
+		headers  map[string]string
+		clientID string
+	}{
+		{map[string]string{"X-MCP-API-Key": key}, client.ID},
+		{map[string]string{"Authorization": "Bearer " + key}, client.ID},
+		{map[string]string{"X-MCP-API-Key": "shared-key"}, ext.MCP_LEGACY_CLIENT_ID},
+	}
+
+	for _, c := range cases {
+		_, nextCalled, clientID := callMCPAuthMiddleware(app, c.headers)
+
+		if !nextCalled || clientID != c.clientID {
+			t.Errorf("expected the client %s for %v, got %q", c.clientID, c.headers, clientID)
+		}
+	}
+}
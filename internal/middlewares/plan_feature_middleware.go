@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/billing"
+	"project/internal/helpers"
+	"project/internal/links"
+
+	"github.com/dracory/api"
+	"github.com/dracory/rtr"
+)
+
+// NewPlanFeatureMiddleware checks the plan of the user grants the feature
+// ==========================================================
+// Business Logic:
+// 1. Administrators and superusers are always allowed
+// 2. Checks the plan of the active subscription of the user (the free
+// plan without subscription) grants the feature, see billing.Plans
+// 3. Otherwise redirects to the billing page, to upgrade
+// ==========================================================
+func NewPlanFeatureMiddleware(app app.AppInterface, feature string) rtr.MiddlewareInterface {
+	m := rtr.NewMiddleware().
+		SetName("Plan Feature Middleware: " + feature).
+		SetHandler(planFeatureMiddlewareHandler(app, feature))
+
+	return m
+}
+
+func planFeatureMiddlewareHandler(app app.AppInterface, feature string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authUser := helpers.GetAuthUser(r)
+
+			if authUser == nil {
+				api.Respond(w, r, api.Unauthenticated("user id empty"))
+				return
+			}
+
+			if authUser.IsAdministrator() || authUser.IsSuperuser() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if app.GetSubscriptionStore() == nil {
+				api.Respond(w, r, api.Error("subscriptions are not available"))
+				return
+			}
+
+			hasFeature, err := billing.HasFeature(r.Context(), app.GetSubscriptionStore(), authUser.GetID(), feature)
+
+			if err != nil {
+				app.GetLogger().Error("At planFeatureMiddleware > HasFeature", slog.String("error", err.Error()))
+				api.Respond(w, r, api.Error("error checking the subscription plan"))
+				return
+			}
+
+			if hasFeature {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			http.Redirect(w, r, links.User().Subscription(map[string]string{"feature": feature}), http.StatusTemporaryRedirect)
+		})
+	}
+}
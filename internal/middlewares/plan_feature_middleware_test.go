@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"project/internal/billing"
+	"project/internal/config"
+	"project/internal/testutils"
+	"strings"
+	"testing"
+
+	"github.com/dracory/test"
+)
+
+func callPlanFeatureMiddleware(t *testing.T, userID string, planID string, feature string) (string, *http.Response) {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetSubscriptionStoreUsed(true)
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	user, session, err := testutils.SeedUserAndSession(
+		app.GetUserStore(),
+		app.GetSessionStore(),
+		userID,
+		httptest.NewRequest("GET", "/", nil),
+		1,
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if planID != "" {
+		if _, err := testutils.SeedSubscription(app.GetSubscriptionStore(), user.GetID(), planID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	body, response, err := test.CallMiddleware(
+		"GET",
+		NewPlanFeatureMiddleware(app, feature).GetHandler(),
+		func(w http.ResponseWriter, r *http.Request) {
+			if _, err := w.Write([]byte("ok")); err != nil {
+				t.Fatalf("failed to write response: %v", err)
+			}
+		},
+		test.NewRequestOptions{
+			Context: map[any]any{
+				config.AuthenticatedUserContextKey{}:    user,
+				config.AuthenticatedSessionContextKey{}: session,
+			},
+		},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response == nil {
+		t.Fatal("response should not be nil")
+	}
+
+	return body, response
+}
+
+func TestPlanFeatureMiddleware_AdminUserPassesThrough(t *testing.T) {
+	body, response := callPlanFeatureMiddleware(t, test.ADMIN_01, "", billing.FEATURE_API_ACCESS)
+
+	if response.StatusCode != http.StatusOK || body != "ok" {
+		t.Fatalf("expected the admin to pass through, got status %d", response.StatusCode)
+	}
+}
+
+func TestPlanFeatureMiddleware_PlanWithFeaturePassesThrough(t *testing.T) {
+	body, response := callPlanFeatureMiddleware(t, test.USER_01, billing.PLAN_PRO, billing.FEATURE_API_ACCESS)
+
+	if response.StatusCode != http.StatusOK || body != "ok" {
+		t.Fatalf("expected the pro plan to grant API access, got status %d", response.StatusCode)
+	}
+}
+
+func TestPlanFeatureMiddleware_PlanWithoutFeatureRedirects(t *testing.T) {
+	_, response := callPlanFeatureMiddleware(t, test.USER_01, billing.PLAN_PRO, billing.FEATURE_PRIORITY_SUPPORT)
+
+	if response.StatusCode != http.StatusTemporaryRedirect {
+		t.Fatalf("expected status code %d, got %d", http.StatusTemporaryRedirect, response.StatusCode)
+	}
+
+	location := response.Header.Get("Location")
+	if !strings.Contains(location, "/user/subscription") || !strings.Contains(location, "feature="+billing.FEATURE_PRIORITY_SUPPORT) {
+		t.Errorf("expected a redirect to the billing page, got %q", location)
+	}
+}
+
+func TestPlanFeatureMiddleware_NoSubscriptionRedirects(t *testing.T) {
+	_, response := callPlanFeatureMiddleware(t, test.USER_01, "", billing.FEATURE_DATA_EXPORT)
+
+	if response.StatusCode != http.StatusTemporaryRedirect {
+		t.Fatalf("expected status code %d, got %d", http.StatusTemporaryRedirect, response.StatusCode)
+	}
+}
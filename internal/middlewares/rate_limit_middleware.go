@@ -0,0 +1,194 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+	"project/internal/app"
+	"project/internal/helpers"
+	"project/internal/links"
+	"project/internal/ratelimit"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dracory/req"
+	"github.com/dracory/rtr"
+)
+
+// NewRateLimitMiddleware limits the requests to the routes of the group,
+// with the policies of the group in the config (RATE_LIMIT_<GROUP>), or
+// the default ones
+func NewRateLimitMiddleware(app app.AppInterface, group string) rtr.MiddlewareInterface {
+	return NewRateLimitByRequestMiddleware(app, "Rate Limit Middleware ("+group+")", func(r *http.Request) string {
+		return group
+	})
+}
+
+// NewRateLimitByRouteMiddleware limits the requests to the route with the
+// policies of the group, but counts them per route, so the routes of a
+// group with strict policies (e.g. the auth pages) do not use up each
+// other's limits
+func NewRateLimitByRouteMiddleware(app app.AppInterface, group string, route string) rtr.MiddlewareInterface {
+	store, err := rateLimitStore(app)
+	if err != nil {
+		app.GetLogger().Error("At NewRateLimitByRouteMiddleware > rateLimitStore", slog.String("error", err.Error()))
+	}
+
+	return rtr.NewMiddleware().
+		SetName("Rate Limit Middleware (" + group + " " + route + ")").
+		SetHandler(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if store == nil || rateLimitAllow(app, store, group, group+":"+route, w, r) {
+					next.ServeHTTP(w, r)
+				}
+			})
+		})
+}
+
+// NewRateLimitByRequestMiddleware limits the requests to the group the
+// function returns for them. The requests it returns no group for are not
+// limited.
+//
+// The requests are counted per client: the API token or the MCP client,
+// else the logged in user, else the IP address, so the users sharing an IP address (e.g. an
+// office) are not limited together. It sets the RateLimit-Limit,
+// RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy headers, and
+// answers 429 Too Many Requests with Retry-After once a limit is reached.
+//
+// If the requests cannot be counted, they are let through.
+func NewRateLimitByRequestMiddleware(app app.AppInterface, name string, groupFunc func(r *http.Request) string) rtr.MiddlewareInterface {
+	store, err := rateLimitStore(app)
+	if err != nil {
+		app.GetLogger().Error("At NewRateLimitByRequestMiddleware > rateLimitStore", slog.String("error", err.Error()))
+	}
+
+	return rtr.NewMiddleware().
+		SetName(name).
+		SetHandler(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				group := groupFunc(r)
+
+				if store == nil || group == "" {
+					next.ServeHTTP(w, r)
+					return
+				}
+
+				if rateLimitAllow(app, store, group, group, w, r) {
+					next.ServeHTTP(w, r)
+				}
+			})
+		})
+}
+
+// rateLimitAllow counts the request of the client with the counter (the
+// group, or the group and route), with the policies of the group, and
+// sets the RateLimit headers. Returns whether the request is within the
+// limits, else it answers 429 Too Many Requests.
+//
+// If the request cannot be counted, it is allowed.
+func rateLimitAllow(app app.AppInterface, store ratelimit.StoreInterface, group string, counter string, w http.ResponseWriter, r *http.Request) bool {
+	policies := rateLimitPolicies(app, group)
+	now := time.Now()
+
+	result, err := ratelimit.Check(store, counter, policies, rateLimitClient(r), now)
+	if err != nil {
+		app.GetLogger().Error("At rateLimitMiddleware > Check", slog.String("group", group), slog.String("error", err.Error()))
+		return true
+	}
+
+	if len(policies) > 0 {
+		policyList := make([]string, 0, len(policies))
+		for _, policy := range policies {
+			policyList = append(policyList, policy.String())
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Policy.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(result.ResetSeconds(now)))
+		w.Header().Set("RateLimit-Policy", strings.Join(policyList, ", "))
+	}
+
+	if result.Allowed {
+		return true
+	}
+
+	retryAfter := strconv.Itoa(result.ResetSeconds(now))
+	w.Header().Set("Retry-After", retryAfter)
+
+	if strings.HasPrefix(r.URL.Path, links.MCP+"/") || r.URL.Path == links.MCP {
+		mcpErrorWrite(w, http.StatusTooManyRequests, "Too many requests, please retry in "+retryAfter+" seconds")
+		return false
+	}
+
+	if strings.HasPrefix(r.URL.Path, links.API+"/") {
+		apiErrorWrite(w, http.StatusTooManyRequests, "Too many requests, please retry in "+retryAfter+" seconds")
+		return false
+	}
+
+	http.Error(w, "Too many requests, please try again in "+retryAfter+" seconds", http.StatusTooManyRequests)
+	return false
+}
+
+// rateLimitUnauthenticatedFunc returns the function the API and MCP auth
+// middlewares call before answering a request without a valid token or
+// key. These requests are not counted by the limiter of their group,
+// which comes after the authentication, so they are counted in the global
+// group, per IP address. The function returns whether the error can be
+// answered, else it answered 429 Too Many Requests.
+func rateLimitUnauthenticatedFunc(app app.AppInterface, name string) func(w http.ResponseWriter, r *http.Request) bool {
+	store, err := rateLimitStore(app)
+	if err != nil {
+		app.GetLogger().Error("At "+name+" > rateLimitStore", slog.String("error", err.Error()))
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		if store == nil {
+			return true
+		}
+
+		return rateLimitAllow(app, store, ratelimit.GROUP_GLOBAL, ratelimit.GROUP_GLOBAL, w, r)
+	}
+}
+
+// rateLimitStore returns the store of the counters set in the config,
+// the cache store table or the memory cache of the application
+func rateLimitStore(app app.AppInterface) (ratelimit.StoreInterface, error) {
+	if app.GetConfig() != nil && app.GetConfig().GetRateLimitStore() == ratelimit.STORE_CACHESTORE && app.GetCacheStore() != nil {
+		return ratelimit.NewCacheStore(app.GetCacheStore())
+	}
+
+	return ratelimit.NewMemoryStore(app.GetMemoryCache())
+}
+
+// rateLimitPolicies returns the policies of the group in the config, or
+// the default ones
+func rateLimitPolicies(app app.AppInterface, group string) []ratelimit.Policy {
+	if app.GetConfig() == nil {
+		return ratelimit.DefaultPolicies(group, false)
+	}
+
+	if policies, ok := app.GetConfig().GetRateLimitPolicies()[group]; ok {
+		return policies
+	}
+
+	isDevelopment := app.GetConfig().IsEnvDevelopment() || app.GetConfig().IsEnvLocal()
+
+	return ratelimit.DefaultPolicies(group, isDevelopment)
+}
+
+// rateLimitClient returns the client the requests are counted for
+func rateLimitClient(r *http.Request) string {
+	if token := helpers.GetAPIAuthToken(r); token != nil {
+		return "token:" + token.ID
+	}
+
+	if client := helpers.GetMCPClient(r); client != nil {
+		return "mcp:" + client.ID
+	}
+
+	if user := helpers.GetAuthUser(r); user != nil {
+		return "user:" + user.GetID()
+	}
+
+	return "ip:" + req.GetIP(r)
+}
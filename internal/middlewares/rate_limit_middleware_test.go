@@ -0,0 +1,225 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"project/internal/apitoken"
+	"project/internal/app"
+	"project/internal/config"
+	"project/internal/mcp"
+	"project/internal/ratelimit"
+	"project/internal/testutils"
+
+	"github.com/dracory/userstore"
+)
+
+// setupRateLimitApp returns an app limiting the auth group to 2 requests
+// per minute, counted in the store
+func setupRateLimitApp(t *testing.T, store string) app.AppInterface {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetRateLimitStore(store)
+	cfg.SetRateLimitPolicies(map[string][]ratelimit.Policy{
+		ratelimit.GROUP_AUTH: {{Limit: 2, Window: time.Minute}},
+	})
+
+	return testutils.Setup(testutils.WithCfg(cfg))
+}
+
+// callRateLimitMiddleware calls the middleware with the request, and
+// returns whether the next handler was called
+func callRateLimitMiddleware(middleware func(next http.Handler) http.Handler, req *http.Request) (*httptest.ResponseRecorder, bool) {
+	res := httptest.NewRecorder()
+	nextCalled := false
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+	})
+
+	middleware(next).ServeHTTP(res, req)
+
+	return res, nextCalled
+}
+
+func TestRateLimitMiddleware_LimitsRequests(t *testing.T) {
+	app := setupRateLimitApp(t, ratelimit.STORE_MEMORY)
+	middleware := NewRateLimitMiddleware(app, ratelimit.GROUP_AUTH).GetHandler()
+
+	for i, remaining := range []string{"1", "0"} {
+		res, nextCalled := callRateLimitMiddleware(middleware, httptest.NewRequest(http.MethodPost, "/auth/login", nil))
+
+		if !nextCalled {
+			t.Fatalf("request %d: next handler should be called within the limit", i+1)
+		}
+		if res.Header().Get("RateLimit-Limit") != "2" {
+			t.Errorf("request %d: RateLimit-Limit = %q, want %q", i+1, res.Header().Get("RateLimit-Limit"), "2")
+		}
+		if res.Header().Get("RateLimit-Remaining") != remaining {
+			t.Errorf("request %d: RateLimit-Remaining = %q, want %q", i+1, res.Header().Get("RateLimit-Remaining"), remaining)
+		}
+		if res.Header().Get("RateLimit-Policy") != "2;w=60" {
+			t.Errorf("request %d: RateLimit-Policy = %q, want %q", i+1, res.Header().Get("RateLimit-Policy"), "2;w=60")
+		}
+	}
+
+	res, nextCalled := callRateLimitMiddleware(middleware, httptest.NewRequest(http.MethodPost, "/auth/login", nil))
+
+	if nextCalled {
+		t.Fatal("next handler should not be called over the limit")
+	}
+	if res.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status %d, got %d", http.StatusTooManyRequests, res.Code)
+	}
+	if res.Header().Get("Retry-After") == "" || res.Header().Get("Retry-After") != res.Header().Get("RateLimit-Reset") {
+		t.Errorf("Expected Retry-After to match RateLimit-Reset, got %q and %q", res.Header().Get("Retry-After"), res.Header().Get("RateLimit-Reset"))
+	}
+	if strings.HasPrefix(res.Body.String(), "{") {
+		t.Errorf("Expected a plain text body, got %q", res.Body.String())
+	}
+}
+
+func TestRateLimitMiddleware_APIRequestsGetJSON(t *testing.T) {
+	app := setupRateLimitApp(t, ratelimit.STORE_MEMORY)
+	middleware := NewRateLimitMiddleware(app, ratelimit.GROUP_AUTH).GetHandler()
+
+	var res *httptest.ResponseRecorder
+	for range 3 {
+		res, _ = callRateLimitMiddleware(middleware, httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil))
+	}
+
+	if res.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", http.StatusTooManyRequests, res.Code)
+	}
+	if res.Header().Get("Content-Type") != "application/json" || !strings.HasPrefix(res.Body.String(), "{") {
+		t.Errorf("Expected a JSON error body, got %q", res.Body.String())
+	}
+}
+
+func TestRateLimitMiddleware_MCPRequestsGetJSONRPC(t *testing.T) {
+	app := setupRateLimitApp(t, ratelimit.STORE_MEMORY)
+	middleware := NewRateLimitMiddleware(app, ratelimit.GROUP_AUTH).GetHandler()
+
+	var res *httptest.ResponseRecorder
+	for range 3 {
+		res, _ = callRateLimitMiddleware(middleware, httptest.NewRequest(http.MethodPost, "/mcp/blog", nil))
+	}
+
+	if res.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", http.StatusTooManyRequests, res.Code)
+	}
+	if !strings.Contains(res.Body.String(), `"jsonrpc":"2.0"`) {
+		t.Errorf("Expected a JSON-RPC error body, got %q", res.Body.String())
+	}
+}
+
+func TestRateLimitMiddleware_CountsPerClient(t *testing.T) {
+	app := setupRateLimitApp(t, ratelimit.STORE_MEMORY)
+	middleware := NewRateLimitMiddleware(app, ratelimit.GROUP_AUTH).GetHandler()
+
+	// Two users, a token and an MCP client sharing one IP address are
+	// counted apart
+	firstUser := userstore.NewUser()
+	secondUser := userstore.NewUser()
+
+	requests := []func() *http.Request{
+		func() *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			return req.WithContext(context.WithValue(req.Context(), config.AuthenticatedUserContextKey{}, firstUser))
+		},
+		func() *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			return req.WithContext(context.WithValue(req.Context(), config.AuthenticatedUserContextKey{}, secondUser))
+		},
+		func() *http.Request {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			token := &apitoken.Token{ID: "token-1"}
+			return req.WithContext(context.WithValue(req.Context(), config.APIAuthenticatedTokenContextKey{}, token))
+		},
+		func() *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			client := &mcp.Client{ID: "client-1"}
+			return req.WithContext(context.WithValue(req.Context(), config.MCPAuthenticatedClientContextKey{}, client))
+		},
+	}
+
+	for i, request := range requests {
+		for j := range 2 {
+			if _, nextCalled := callRateLimitMiddleware(middleware, request()); !nextCalled {
+				t.Fatalf("client %d, request %d: next handler should be called within the limit", i+1, j+1)
+			}
+		}
+
+		if _, nextCalled := callRateLimitMiddleware(middleware, request()); nextCalled {
+			t.Fatalf("client %d: next handler should not be called over the limit", i+1)
+		}
+	}
+}
+
+func TestRateLimitMiddleware_SharedStore(t *testing.T) {
+	app := setupRateLimitApp(t, ratelimit.STORE_CACHESTORE)
+
+	// Two middlewares stand for two instances of the application
+	first := NewRateLimitMiddleware(app, ratelimit.GROUP_AUTH).GetHandler()
+	second := NewRateLimitMiddleware(app, ratelimit.GROUP_AUTH).GetHandler()
+
+	callRateLimitMiddleware(first, httptest.NewRequest(http.MethodGet, "/", nil))
+	callRateLimitMiddleware(second, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if _, nextCalled := callRateLimitMiddleware(first, httptest.NewRequest(http.MethodGet, "/", nil)); nextCalled {
+		t.Fatal("next handler should not be called once the shared limit is reached")
+	}
+
+	if _, nextCalled := callRateLimitMiddleware(second, httptest.NewRequest(http.MethodGet, "/", nil)); nextCalled {
+		t.Fatal("next handler should not be called once the shared limit is reached")
+	}
+}
+
+func TestRateLimitByRequestMiddleware_NoGroup(t *testing.T) {
+	app := setupRateLimitApp(t, ratelimit.STORE_MEMORY)
+	middleware := NewRateLimitByRequestMiddleware(app, "Test", func(r *http.Request) string {
+		return ""
+	}).GetHandler()
+
+	for i := range 5 {
+		res, nextCalled := callRateLimitMiddleware(middleware, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if !nextCalled {
+			t.Fatalf("request %d: next handler should be called without a group", i+1)
+		}
+		if res.Header().Get("RateLimit-Limit") != "" {
+			t.Errorf("request %d: expected no rate limit headers without a group", i+1)
+		}
+	}
+}
+
+func TestRateLimitByRouteMiddleware_CountsPerRoute(t *testing.T) {
+	app := setupRateLimitApp(t, ratelimit.STORE_MEMORY)
+	login := NewRateLimitByRouteMiddleware(app, ratelimit.GROUP_AUTH, "/auth/login").GetHandler()
+	twoFactor := NewRateLimitByRouteMiddleware(app, ratelimit.GROUP_AUTH, "/auth/two-factor").GetHandler()
+
+	for range 2 {
+		if _, nextCalled := callRateLimitMiddleware(login, httptest.NewRequest(http.MethodPost, "/auth/login", nil)); !nextCalled {
+			t.Fatal("next handler should be called within the limit of the route")
+		}
+	}
+
+	if _, nextCalled := callRateLimitMiddleware(login, httptest.NewRequest(http.MethodPost, "/auth/login", nil)); nextCalled {
+		t.Fatal("next handler should not be called over the limit of the route")
+	}
+
+	res, nextCalled := callRateLimitMiddleware(twoFactor, httptest.NewRequest(http.MethodPost, "/auth/two-factor", nil))
+
+	if !nextCalled {
+		t.Fatal("next handler should be called, as the other route has its own limit")
+	}
+	if res.Header().Get("RateLimit-Limit") != "2" {
+		t.Errorf("RateLimit-Limit = %q, want the policy of the group %q", res.Header().Get("RateLimit-Limit"), "2")
+	}
+}
@@ -3,70 +3,49 @@ package middlewares
 import (
 	"net/http"
 	"net/http/httptest"
+	"project/internal/app"
 	"project/internal/config"
+	"project/internal/helpers"
 	"project/internal/testutils"
 	"strings"
 	"testing"
 )
 
-func TestSecurityHeadersMiddleware(t *testing.T) {
-	// Create test config with development environment
+// newSecurityHeadersTestApp returns an app in the development environment,
+// with a CDN allowed for the scripts
+func newSecurityHeadersTestApp(t *testing.T) app.AppInterface {
+	t.Helper()
+
 	testConfig := config.New()
 	testConfig.SetAppEnv(config.APP_ENVIRONMENT_DEVELOPMENT)
 	testConfig.SetDatabaseDriver("sqlite") // Required by app builder
 	testConfig.SetDatabaseName(":memory:") // Required by app builder
-	app := testutils.Setup(testutils.WithCfg(testConfig))
-
-	cspParts := []string{
-		"default-src 'self';",
-		"script-src 'unsafe-inline' 'unsafe-hashes' 'unsafe-eval' 'self'",
-		"https://cdn.jsdelivr.net",
-		"http://cdn.jsdelivr.net",
-		"https://unpkg.com",
-		"https://www.statcounter.com",
-		"https://code.jquery.com",
-		"https://cdn.datatables.net",
-		"https://cdnjs.cloudflare.com",
-		"http://cdnjs.cloudflare.com",
-		"https://www.googletagmanager.com",
-		"https://www.statcounter.com",
-		"https://cdn.tiny.cloud;",
-		"style-src 'unsafe-inline' 'unsafe-hashes' 'self'",
-		"https://cdn.jsdelivr.net",
-		"https://maxcdn.bootstrapcdn.com",
-		"https://cdnjs.cloudflare.com",
-		"http://cdnjs.cloudflare.com",
-		"https://fonts.googleapis.com",
-		"https://unpkg.com",
-		"https://code.jquery.com",
-		"https://cdn.datatables.net",
-		"https://cdnjs.cloudflare.com;",
-		"font-src 'self'",
-		"https://cdn.jsdelivr.net",
-		"https://fonts.googleapis.com",
-		"https://fonts.gstatic.com",
-		"https://cdnjs.cloudflare.com",
-		"http://cdnjs.cloudflare.com",
-		"https://maxcdn.bootstrapcdn.com;",
-		"img-src 'self' data:",
-		"https://sfs.ams3.digitaloceanspaces.com",
-		"https://lesichkov.ams3.digitaloceanspaces.com",
-		"https://provedexpert.gitlab.io",
-		"https://picsum.photos",
-		"https://fastly.picsum.photos;",
-		"connect-src 'self'",
-		"https://cdnjs.cloudflare.com",
-		"http://cdnjs.cloudflare.com",
-		"https://www.statcounter.com",
-	}
+	testConfig.SetCSPScriptSources([]string{"https://cdn.jsdelivr.net"})
+	testConfig.SetCSPStyleSources([]string{"'unsafe-inline'", "https://cdn.jsdelivr.net"})
+	testConfig.SetCSPImgSources([]string{"data:"})
+
+	return testutils.Setup(testutils.WithCfg(testConfig))
+}
 
-	req := httptest.NewRequest("GET", "https://example.com", nil)
+// serveSecurityHeaders serves the request through the middleware, and
+// returns the response and the nonce the next handler was given
+func serveSecurityHeaders(app app.AppInterface, req *http.Request) (*httptest.ResponseRecorder, string) {
+	nonce := ""
 	rr := httptest.NewRecorder()
 	handler := NewSecurityHeadersMiddleware(app).GetHandler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = helpers.GetCSPNonce(r.Context())
 		w.WriteHeader(http.StatusOK)
 	}))
 	handler.ServeHTTP(rr, req)
 
+	return rr, nonce
+}
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	app := newSecurityHeadersTestApp(t)
+
+	rr, nonce := serveSecurityHeaders(app, httptest.NewRequest("GET", "https://example.com", nil))
+
 	if value := rr.Header().Get("X-Frame-Options"); value != "DENY" {
 		t.Errorf("header X-Frame-Options: got %q want DENY", value)
 	}
@@ -76,8 +55,109 @@ func TestSecurityHeadersMiddleware(t *testing.T) {
 	if value := rr.Header().Get("Referrer-Policy"); value != "strict-origin-when-cross-origin" {
 		t.Errorf("header Referrer-Policy: got %q want strict-origin-when-cross-origin", value)
 	}
-	expectedCSP := strings.Join(cspParts, " ")
+
+	if nonce == "" {
+		t.Fatal("expected a nonce in the context of the request")
+	}
+
+	expectedCSP := strings.Join([]string{
+		"default-src 'self'",
+		"script-src 'self' 'nonce-" + nonce + "' https://cdn.jsdelivr.net",
+		"style-src 'self' 'unsafe-inline' https://cdn.jsdelivr.net",
+		"style-src-attr 'unsafe-inline'",
+		"font-src 'self'",
+		"img-src 'self' data:",
+		"connect-src 'self'",
+		"object-src 'none'",
+		"base-uri 'self'",
+		"frame-ancestors 'none'",
+		"report-uri /api/internal/csp-report",
+	}, "; ")
 	if value := rr.Header().Get("Content-Security-Policy"); value != expectedCSP {
 		t.Errorf("header Content-Security-Policy: got %q want %q", value, expectedCSP)
 	}
 }
+
+func TestSecurityHeadersMiddleware_NewNoncePerRequest(t *testing.T) {
+	app := newSecurityHeadersTestApp(t)
+
+	_, first := serveSecurityHeaders(app, httptest.NewRequest("GET", "/", nil))
+	_, second := serveSecurityHeaders(app, httptest.NewRequest("GET", "/", nil))
+
+	if first == second {
+		t.Fatalf("expected a new nonce for every request, got %q twice", first)
+	}
+}
+
+func TestSecurityHeadersMiddleware_StyleNonce(t *testing.T) {
+	app := newSecurityHeadersTestApp(t)
+	app.GetConfig().SetCSPStyleSources([]string{"https://cdn.jsdelivr.net"})
+
+	rr, nonce := serveSecurityHeaders(app, httptest.NewRequest("GET", "/", nil))
+
+	if value := rr.Header().Get("Content-Security-Policy"); !strings.Contains(value, "style-src 'self' 'nonce-"+nonce+"' https://cdn.jsdelivr.net;") {
+		t.Errorf("expected the styles to require the nonce, got %q", value)
+	}
+}
+
+func TestSecurityHeadersMiddleware_Admin(t *testing.T) {
+	app := newSecurityHeadersTestApp(t)
+
+	rr, _ := serveSecurityHeaders(app, httptest.NewRequest("GET", "/admin/users", nil))
+
+	if value := rr.Header().Get("Content-Security-Policy"); !strings.Contains(value, "script-src 'self' 'unsafe-inline' 'unsafe-eval' https://cdn.jsdelivr.net;") {
+		t.Errorf("expected the admin panel to allow the inline scripts, got %q", value)
+	}
+
+	rr, _ = serveSecurityHeaders(app, httptest.NewRequest("GET", "/administrators", nil))
+
+	if value := rr.Header().Get("Content-Security-Policy"); strings.Contains(value, "'unsafe-inline' 'unsafe-eval'") {
+		t.Errorf("expected the pages outside the admin panel to require the nonce, got %q", value)
+	}
+}
+
+func TestSecurityHeadersMiddleware_ReportOnly(t *testing.T) {
+	app := newSecurityHeadersTestApp(t)
+	app.GetConfig().SetCSPReportOnly(true)
+
+	rr, _ := serveSecurityHeaders(app, httptest.NewRequest("GET", "/", nil))
+
+	if value := rr.Header().Get("Content-Security-Policy"); value != "" {
+		t.Errorf("expected no enforced policy, got %q", value)
+	}
+
+	if value := rr.Header().Get("Content-Security-Policy-Report-Only"); value == "" {
+		t.Error("expected the report only policy")
+	}
+}
+
+func TestSecurityHeadersMiddleware_NonceOfThePage(t *testing.T) {
+	app := newSecurityHeadersTestApp(t)
+	pageNonce := "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	// Fetch requests of the page keep its nonce
+	req := httptest.NewRequest("POST", "/liveflux", nil)
+	req.Header.Set("Sec-Fetch-Mode", "cors")
+	req.Header.Set("X-CSP-Nonce", pageNonce)
+
+	if _, nonce := serveSecurityHeaders(app, req); nonce != pageNonce {
+		t.Errorf("expected the nonce of the page, got %q", nonce)
+	}
+
+	// Navigations always get a new nonce
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Sec-Fetch-Mode", "navigate")
+	req.Header.Set("X-CSP-Nonce", pageNonce)
+
+	if _, nonce := serveSecurityHeaders(app, req); nonce == pageNonce {
+		t.Error("expected a new nonce when navigating")
+	}
+
+	// Invalid nonces are ignored
+	req = httptest.NewRequest("POST", "/liveflux", nil)
+	req.Header.Set("X-CSP-Nonce", `"><script>`)
+
+	if _, nonce := serveSecurityHeaders(app, req); nonce == `"><script>` {
+		t.Error("expected the invalid nonce to be ignored")
+	}
+}
@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"net/http"
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/helpers"
+
+	"github.com/dracory/rtr"
+)
+
+// NewSessionActivityMiddleware saves the last activity of the session of
+// the authenticated user, shown on the devices and sessions page.
+//
+// It must come after the auth middleware, which adds the session to the
+// request. The activity is saved at most once every few minutes.
+func NewSessionActivityMiddleware(app app.AppInterface) rtr.MiddlewareInterface {
+	return rtr.NewMiddleware().
+		SetName("Session Activity Middleware").
+		SetHandler(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if session := helpers.GetAuthSession(r); session != nil {
+					ext.UserSessionTouch(r.Context(), app, session)
+				}
+
+				next.ServeHTTP(w, r)
+			})
+		})
+}
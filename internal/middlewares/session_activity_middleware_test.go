@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"project/internal/config"
+	"project/internal/testutils"
+
+	"github.com/dracory/test"
+	"github.com/dromara/carbon/v2"
+)
+
+func TestSessionActivityMiddleware_SavesActivity(t *testing.T) {
+	app := testutils.Setup(testutils.WithUserStore(true), testutils.WithSessionStore(true))
+
+	req := httptest.NewRequest(http.MethodGet, "/user", nil)
+
+	_, session, err := testutils.SeedUserAndSession(app.GetUserStore(), app.GetSessionStore(), test.USER_01, req, 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session.SetUpdatedAt(carbon.Now(carbon.UTC).SubHours(1).ToDateTimeString(carbon.UTC))
+	req = req.WithContext(context.WithValue(req.Context(), config.AuthenticatedSessionContextKey{}, session))
+
+	nextCalled := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+	})
+
+	NewSessionActivityMiddleware(app).GetHandler()(next).ServeHTTP(httptest.NewRecorder(), req)
+
+	if !nextCalled {
+		t.Fatal("expected the next handler to be called")
+	}
+
+	saved, err := app.GetSessionStore().SessionFindByID(req.Context(), session.GetID())
+	if err != nil || saved == nil {
+		t.Fatalf("expected the session, got %v", err)
+	}
+
+	if saved.GetUpdatedAtCarbon().StdTime().Before(carbon.Now(carbon.UTC).SubMinutes(1).StdTime()) {
+		t.Fatal("expected the last activity of the session to be saved")
+	}
+}
+
+func TestSessionActivityMiddleware_Guest(t *testing.T) {
+	app := testutils.Setup(testutils.WithSessionStore(true))
+
+	nextCalled := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+	})
+
+	NewSessionActivityMiddleware(app).GetHandler()(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !nextCalled {
+		t.Fatal("expected the next handler to be called for guests")
+	}
+}
@@ -0,0 +1,120 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// githubUser is the part of the GitHub user used by the login
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+// githubEmail is an email address of the GitHub user
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// GitHubProvider is the GitHub OAuth app login. GitHub does not support
+// OpenID Connect for users, so the user and their emails are read from
+// the REST API
+type GitHubProvider struct {
+	clientID     string
+	clientSecret string
+	endpoint     oauth2.Endpoint
+	apiURL       string
+	httpClient   *http.Client
+}
+
+var _ ProviderInterface = (*GitHubProvider)(nil)
+
+// NewGitHubProvider creates the GitHub provider
+func NewGitHubProvider(clientID string, clientSecret string) *GitHubProvider {
+	return &GitHubProvider{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		endpoint: oauth2.Endpoint{
+			AuthURL:  "https://github.com/login/oauth/authorize",
+			TokenURL: "https://github.com/login/oauth/access_token",
+		},
+		apiURL:     "https://api.github.com",
+		httpClient: newHTTPClient(),
+	}
+}
+
+func (provider *GitHubProvider) Key() string {
+	return PROVIDER_GITHUB
+}
+
+func (provider *GitHubProvider) Name() string {
+	return "GitHub"
+}
+
+func (provider *GitHubProvider) AuthCodeURL(ctx context.Context, state string, verifier string, redirectURL string) (string, error) {
+	return provider.config(redirectURL).AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)), nil
+}
+
+func (provider *GitHubProvider) Identity(ctx context.Context, code string, verifier string, redirectURL string) (Identity, error) {
+	config := provider.config(redirectURL)
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, provider.httpClient)
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return Identity{}, err
+	}
+
+	client := config.Client(ctx, token)
+
+	user := githubUser{}
+	if err := getJSON(ctx, client, provider.apiURL+"/user", &user); err != nil {
+		return Identity{}, err
+	}
+
+	if user.ID == 0 {
+		return Identity{}, ErrNoSubject
+	}
+
+	// The email on the profile may be unverified or hidden, the list of
+	// emails says which one is the primary and whether it was verified
+	emails := []githubEmail{}
+	if err := getJSON(ctx, client, provider.apiURL+"/user/emails", &emails); err != nil {
+		return Identity{}, err
+	}
+
+	identity := Identity{
+		Subject: strconv.FormatInt(user.ID, 10),
+		Name:    user.Name,
+	}
+
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = strings.ToLower(strings.TrimSpace(email.Email))
+			identity.EmailVerified = email.Verified
+		}
+	}
+
+	return identity, nil
+}
+
+func (provider *GitHubProvider) config(redirectURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     provider.clientID,
+		ClientSecret: provider.clientSecret,
+		Endpoint:     provider.endpoint,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"read:user", "user:email"},
+	}
+}
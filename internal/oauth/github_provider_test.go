@@ -0,0 +1,84 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+// newGitHubStandIn returns a server answering as the GitHub token
+// endpoint and REST API, with the emails given
+func newGitHubStandIn(t *testing.T, emails []githubEmail) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "token_type": "bearer"})
+	})
+
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(githubUser{ID: 42, Login: "octocat"})
+	})
+
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(emails)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newTestGitHubProvider(server *httptest.Server) *GitHubProvider {
+	provider := NewGitHubProvider("client", "secret")
+	provider.endpoint = oauth2.Endpoint{
+		AuthURL:  server.URL + "/login/oauth/authorize",
+		TokenURL: server.URL + "/login/oauth/access_token",
+	}
+	provider.apiURL = server.URL
+	return provider
+}
+
+func TestGitHubProvider_Identity(t *testing.T) {
+	server := newGitHubStandIn(t, []githubEmail{
+		{Email: "old@example.com", Verified: true},
+		{Email: "Octocat@Example.com", Primary: true, Verified: true},
+	})
+
+	identity, err := newTestGitHubProvider(server).Identity(context.Background(), "code", oauth2.GenerateVerifier(), testRedirectURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Identity{Subject: "42", Email: "octocat@example.com", EmailVerified: true, Name: "octocat"}
+	if identity != expected {
+		t.Fatalf("identity = %+v, want %+v", identity, expected)
+	}
+}
+
+func TestGitHubProvider_PrimaryEmailNotVerified(t *testing.T) {
+	server := newGitHubStandIn(t, []githubEmail{
+		{Email: "other@example.com", Verified: true},
+		{Email: "octocat@example.com", Primary: true},
+	})
+
+	identity, err := newTestGitHubProvider(server).Identity(context.Background(), "code", oauth2.GenerateVerifier(), testRedirectURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if identity.Email != "octocat@example.com" || identity.EmailVerified {
+		t.Fatalf("expected the unverified primary email, got %+v", identity)
+	}
+}
@@ -0,0 +1,62 @@
+// Package oauth implements the social login with OAuth2 and OpenID Connect
+// providers (Google, GitHub, Microsoft, and any OpenID Connect provider
+// with discovery).
+//
+// The providers only establish who the user is at the provider (see
+// Identity). The identities are linked to the local users in the Store,
+// and the login itself is completed by the authentication controller.
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Keys of the supported providers, as used in the URLs and the links
+const (
+	PROVIDER_GOOGLE    = "google"
+	PROVIDER_GITHUB    = "github"
+	PROVIDER_MICROSOFT = "microsoft"
+	PROVIDER_OIDC      = "oidc"
+)
+
+// ErrNoSubject is returned when the provider does not identify the user
+var ErrNoSubject = errors.New("the provider did not return the ID of the user")
+
+// httpTimeout is the timeout of the requests to the providers
+const httpTimeout = 10 * time.Second
+
+// Identity is the user as identified by the provider
+type Identity struct {
+	// Subject the ID of the user at the provider, never reassigned
+	Subject string
+	Email   string
+	// EmailVerified whether the provider verified the user owns the email
+	EmailVerified bool
+	Name          string
+}
+
+// ProviderInterface is implemented by the social login providers.
+//
+// The login is the OAuth2 authorization code flow, with PKCE: the user is
+// sent to AuthCodeURL, and the provider sends the user back to the redirect
+// URL with the code, which Identity exchanges for the identity of the user.
+type ProviderInterface interface {
+	// Key returns the key of the provider, one of the PROVIDER_* constants
+	Key() string
+
+	// Name returns the name of the provider, shown to the user
+	Name() string
+
+	// AuthCodeURL returns the URL of the login page of the provider
+	AuthCodeURL(ctx context.Context, state string, verifier string, redirectURL string) (string, error)
+
+	// Identity exchanges the authorization code for the identity of the user
+	Identity(ctx context.Context, code string, verifier string, redirectURL string) (Identity, error)
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: httpTimeout}
+}
@@ -0,0 +1,203 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	basehttp "github.com/dracory/base/http"
+	"golang.org/x/oauth2"
+)
+
+// oidcDiscovery is the part of the discovery document used by the login
+type oidcDiscovery struct {
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// discoveries caches the discovery documents by issuer URL, as they
+// rarely change and are needed on every login
+var discoveries = struct {
+	sync.Mutex
+	byIssuer map[string]oidcDiscovery
+}{byIssuer: map[string]oidcDiscovery{}}
+
+// OIDCProvider is an OpenID Connect provider, configured from its
+// discovery document.
+//
+// The user is identified by the userinfo endpoint, called with the access
+// token received directly from the token endpoint, so the ID token does
+// not need to be verified.
+type OIDCProvider struct {
+	key          string
+	name         string
+	issuerURL    string
+	clientID     string
+	clientSecret string
+	emailTrusted bool
+	httpClient   *http.Client
+}
+
+var _ ProviderInterface = (*OIDCProvider)(nil)
+
+// NewOIDCProvider creates an OpenID Connect provider. The discovery
+// document is read from the issuer URL on first use
+func NewOIDCProvider(key string, name string, issuerURL string, clientID string, clientSecret string) *OIDCProvider {
+	return &OIDCProvider{
+		key:          key,
+		name:         name,
+		issuerURL:    strings.TrimSuffix(issuerURL, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   newHTTPClient(),
+	}
+}
+
+// SetEmailTrusted sets whether the email of the provider is verified even
+// without the email_verified claim, for the providers which only return
+// emails they verified but do not send the claim
+func (provider *OIDCProvider) SetEmailTrusted(emailTrusted bool) *OIDCProvider {
+	provider.emailTrusted = emailTrusted
+	return provider
+}
+
+func (provider *OIDCProvider) Key() string {
+	return provider.key
+}
+
+func (provider *OIDCProvider) Name() string {
+	return provider.name
+}
+
+func (provider *OIDCProvider) AuthCodeURL(ctx context.Context, state string, verifier string, redirectURL string) (string, error) {
+	config, err := provider.config(ctx, redirectURL)
+	if err != nil {
+		return "", err
+	}
+
+	return config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)), nil
+}
+
+func (provider *OIDCProvider) Identity(ctx context.Context, code string, verifier string, redirectURL string) (Identity, error) {
+	config, err := provider.config(ctx, redirectURL)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, provider.httpClient)
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return Identity{}, err
+	}
+
+	discovery, err := provider.discovery(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	claims := map[string]any{}
+	if err := getJSON(ctx, config.Client(ctx, token), discovery.UserinfoEndpoint, &claims); err != nil {
+		return Identity{}, err
+	}
+
+	identity := Identity{
+		Subject: claimString(claims, "sub"),
+		Email:   strings.ToLower(strings.TrimSpace(claimString(claims, "email"))),
+		Name:    claimString(claims, "name"),
+	}
+
+	if identity.Subject == "" {
+		return Identity{}, ErrNoSubject
+	}
+
+	// Some providers send the claim as a string
+	verified := claims["email_verified"]
+	identity.EmailVerified = verified == true || verified == "true"
+
+	if provider.emailTrusted && identity.Email != "" {
+		identity.EmailVerified = true
+	}
+
+	return identity, nil
+}
+
+func (provider *OIDCProvider) config(ctx context.Context, redirectURL string) (*oauth2.Config, error) {
+	discovery, err := provider.discovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     provider.clientID,
+		ClientSecret: provider.clientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+		RedirectURL: redirectURL,
+		Scopes:      []string{"openid", "email", "profile"},
+	}, nil
+}
+
+// discovery returns the discovery document of the issuer
+func (provider *OIDCProvider) discovery(ctx context.Context) (oidcDiscovery, error) {
+	discoveries.Lock()
+	discovery, found := discoveries.byIssuer[provider.issuerURL]
+	discoveries.Unlock()
+
+	if found {
+		return discovery, nil
+	}
+
+	if provider.issuerURL == "" {
+		return oidcDiscovery{}, errors.New("issuer URL is required")
+	}
+
+	if err := getJSON(ctx, provider.httpClient, provider.issuerURL+"/.well-known/openid-configuration", &discovery); err != nil {
+		return oidcDiscovery{}, fmt.Errorf("discovery failed: %w", err)
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.UserinfoEndpoint == "" {
+		return oidcDiscovery{}, errors.New("discovery document is missing the authorization, token or userinfo endpoint")
+	}
+
+	discoveries.Lock()
+	discoveries.byIssuer[provider.issuerURL] = discovery
+	discoveries.Unlock()
+
+	return discovery, nil
+}
+
+// getJSON decodes the JSON response of a GET request into the target
+func getJSON(ctx context.Context, client *http.Client, url string, target any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer basehttp.SafeCloseResponseBody(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(target)
+}
+
+func claimString(claims map[string]any, key string) string {
+	value, _ := claims[key].(string)
+	return value
+}
@@ -0,0 +1,144 @@
+package oauth
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"project/internal/testutils"
+
+	"golang.org/x/oauth2"
+)
+
+const testRedirectURL = "http://localhost/auth/oauth/callback"
+
+// loginAt runs the authorization code flow against the stand-in server,
+// and returns the identity of the user signed in there
+func loginAt(t *testing.T, server *testutils.OIDCServer, provider ProviderInterface) (Identity, error) {
+	t.Helper()
+
+	verifier := oauth2.GenerateVerifier()
+
+	authCodeURL, err := provider.AuthCodeURL(context.Background(), "test-state", verifier, testRedirectURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callbackURL, err := server.Authorize(authCodeURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callback, err := url.Parse(callbackURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if callback.Query().Get("state") != "test-state" {
+		t.Fatalf("expected the state to be returned, got %s", callbackURL)
+	}
+
+	return provider.Identity(context.Background(), callback.Query().Get("code"), verifier, testRedirectURL)
+}
+
+func TestOIDCProvider_Identity(t *testing.T) {
+	server := testutils.NewOIDCServer()
+	defer server.Close()
+
+	server.SetUser(testutils.OIDCUser{
+		Subject:       "subject-1",
+		Email:         "User@Example.com",
+		EmailVerified: true,
+		Name:          "Test User",
+	})
+
+	provider := NewOIDCProvider(PROVIDER_OIDC, "SSO", server.URL(), testutils.OIDC_CLIENT_ID, testutils.OIDC_CLIENT_SECRET)
+
+	identity, err := loginAt(t, server, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Identity{Subject: "subject-1", Email: "user@example.com", EmailVerified: true, Name: "Test User"}
+	if identity != expected {
+		t.Fatalf("identity = %+v, want %+v", identity, expected)
+	}
+}
+
+func TestOIDCProvider_EmailNotVerified(t *testing.T) {
+	server := testutils.NewOIDCServer()
+	defer server.Close()
+
+	server.SetUser(testutils.OIDCUser{Subject: "subject-1", Email: "user@example.com"})
+
+	identity, err := loginAt(t, server, NewOIDCProvider(PROVIDER_OIDC, "SSO", server.URL(), testutils.OIDC_CLIENT_ID, testutils.OIDC_CLIENT_SECRET))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if identity.EmailVerified {
+		t.Fatal("expected the email to be unverified")
+	}
+
+	trusted := NewOIDCProvider(PROVIDER_OIDC, "SSO", server.URL(), testutils.OIDC_CLIENT_ID, testutils.OIDC_CLIENT_SECRET).
+		SetEmailTrusted(true)
+
+	identity, err = loginAt(t, server, trusted)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !identity.EmailVerified {
+		t.Fatal("expected the email of a trusted provider to be verified")
+	}
+}
+
+func TestOIDCProvider_InvalidCode(t *testing.T) {
+	server := testutils.NewOIDCServer()
+	defer server.Close()
+
+	provider := NewOIDCProvider(PROVIDER_OIDC, "SSO", server.URL(), testutils.OIDC_CLIENT_ID, testutils.OIDC_CLIENT_SECRET)
+
+	if _, err := provider.Identity(context.Background(), "unknown", oauth2.GenerateVerifier(), testRedirectURL); err == nil {
+		t.Fatal("expected an error for an unknown code")
+	}
+}
+
+func TestOIDCProvider_WrongVerifier(t *testing.T) {
+	server := testutils.NewOIDCServer()
+	defer server.Close()
+
+	server.SetUser(testutils.OIDCUser{Subject: "subject-1"})
+
+	provider := NewOIDCProvider(PROVIDER_OIDC, "SSO", server.URL(), testutils.OIDC_CLIENT_ID, testutils.OIDC_CLIENT_SECRET)
+
+	authCodeURL, err := provider.AuthCodeURL(context.Background(), "test-state", oauth2.GenerateVerifier(), testRedirectURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callbackURL, err := server.Authorize(authCodeURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callback, _ := url.Parse(callbackURL)
+
+	if _, err := provider.Identity(context.Background(), callback.Query().Get("code"), oauth2.GenerateVerifier(), testRedirectURL); err == nil {
+		t.Fatal("expected an error for a code exchanged with another verifier")
+	}
+}
+
+func TestOIDCProvider_DiscoveryFails(t *testing.T) {
+	server := testutils.NewOIDCServer()
+	issuerURL := server.URL()
+	server.Close()
+
+	provider := NewOIDCProvider(PROVIDER_OIDC, "SSO", issuerURL, testutils.OIDC_CLIENT_ID, testutils.OIDC_CLIENT_SECRET)
+
+	_, err := provider.AuthCodeURL(context.Background(), "test-state", oauth2.GenerateVerifier(), testRedirectURL)
+	if err == nil || !strings.Contains(err.Error(), "discovery") {
+		t.Fatalf("expected a discovery error, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package oauth
+
+import (
+	"project/internal/config"
+	"slices"
+)
+
+// microsoftTenantsUnverified are the Microsoft tenants anyone can create
+// accounts in, where the email of a work account is set by the admins of
+// the account's organization, without being verified
+var microsoftTenantsUnverified = []string{"common", "organizations"}
+
+// ProvidersFromConfig returns the providers with a client ID configured,
+// in the order they are shown on the login page
+func ProvidersFromConfig(cfg config.ConfigInterface) []ProviderInterface {
+	providers := []ProviderInterface{}
+
+	if cfg == nil {
+		return providers
+	}
+
+	if cfg.GetOAuthGoogleClientID() != "" {
+		providers = append(providers, NewOIDCProvider(PROVIDER_GOOGLE, "Google",
+			"https://accounts.google.com",
+			cfg.GetOAuthGoogleClientID(),
+			cfg.GetOAuthGoogleClientSecret()))
+	}
+
+	if cfg.GetOAuthGitHubClientID() != "" {
+		providers = append(providers, NewGitHubProvider(
+			cfg.GetOAuthGitHubClientID(),
+			cfg.GetOAuthGitHubClientSecret()))
+	}
+
+	if cfg.GetOAuthMicrosoftClientID() != "" {
+		tenant := cfg.GetOAuthMicrosoftTenant()
+
+		// Microsoft does not send the email_verified claim. The emails
+		// are only trusted when the accounts cannot come from any
+		// organization, otherwise the users must link their account
+		// from their profile before they can log in with it
+		providers = append(providers, NewOIDCProvider(PROVIDER_MICROSOFT, "Microsoft",
+			"https://login.microsoftonline.com/"+tenant+"/v2.0",
+			cfg.GetOAuthMicrosoftClientID(),
+			cfg.GetOAuthMicrosoftClientSecret()).
+			SetEmailTrusted(!slices.Contains(microsoftTenantsUnverified, tenant)))
+	}
+
+	if cfg.GetOAuthOIDCClientID() != "" {
+		providers = append(providers, NewOIDCProvider(PROVIDER_OIDC,
+			cfg.GetOAuthOIDCName(),
+			cfg.GetOAuthOIDCIssuerURL(),
+			cfg.GetOAuthOIDCClientID(),
+			cfg.GetOAuthOIDCClientSecret()))
+	}
+
+	return providers
+}
+
+// ProviderFind returns the provider with the key, or nil if the
+// provider is not configured
+func ProviderFind(providers []ProviderInterface, key string) ProviderInterface {
+	for _, provider := range providers {
+		if provider.Key() == key {
+			return provider
+		}
+	}
+
+	return nil
+}
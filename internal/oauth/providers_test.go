@@ -0,0 +1,70 @@
+package oauth
+
+import (
+	"testing"
+
+	"project/internal/config"
+)
+
+func TestProvidersFromConfig(t *testing.T) {
+	cfg := config.New()
+
+	if providers := ProvidersFromConfig(cfg); len(providers) != 0 {
+		t.Fatalf("expected no providers, got %d", len(providers))
+	}
+
+	cfg.SetOAuthGitHubClientID("github-id")
+	cfg.SetOAuthOIDCClientID("oidc-id")
+	cfg.SetOAuthOIDCIssuerURL("https://sso.example.com")
+	cfg.SetOAuthOIDCName("Company SSO")
+
+	providers := ProvidersFromConfig(cfg)
+
+	if len(providers) != 2 {
+		t.Fatalf("expected 2 providers, got %d", len(providers))
+	}
+
+	if providers[0].Key() != PROVIDER_GITHUB || providers[1].Key() != PROVIDER_OIDC {
+		t.Fatalf("unexpected providers %s, %s", providers[0].Key(), providers[1].Key())
+	}
+
+	if providers[1].Name() != "Company SSO" {
+		t.Fatalf("expected the configured name, got %s", providers[1].Name())
+	}
+
+	if ProviderFind(providers, PROVIDER_OIDC) == nil {
+		t.Fatal("expected to find the OIDC provider")
+	}
+
+	if ProviderFind(providers, PROVIDER_GOOGLE) != nil {
+		t.Fatal("expected Google not to be configured")
+	}
+}
+
+func TestProvidersFromConfig_MicrosoftEmailTrust(t *testing.T) {
+	cases := map[string]bool{
+		"common":        false,
+		"organizations": false,
+		"consumers":     true,
+		"my-tenant-id":  true,
+	}
+
+	for tenant, trusted := range cases {
+		cfg := config.New()
+		cfg.SetOAuthMicrosoftClientID("microsoft-id")
+		cfg.SetOAuthMicrosoftTenant(tenant)
+
+		provider, ok := ProviderFind(ProvidersFromConfig(cfg), PROVIDER_MICROSOFT).(*OIDCProvider)
+		if !ok {
+			t.Fatal("expected the Microsoft provider")
+		}
+
+		if provider.emailTrusted != trusted {
+			t.Errorf("tenant %s: email trusted = %v, want %v", tenant, provider.emailTrusted, trusted)
+		}
+
+		if provider.issuerURL != "https://login.microsoftonline.com/"+tenant+"/v2.0" {
+			t.Errorf("tenant %s: unexpected issuer URL %s", tenant, provider.issuerURL)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"project/internal/customrecords"
+	"time"
+
+	"github.com/dracory/customstore"
+)
+
+// RECORD_TYPE_OAUTH_IDENTITY is the record type of the linked identities
+// in the custom store
+const RECORD_TYPE_OAUTH_IDENTITY = "oauth_identity"
+
+// Link links the identity of a user at a provider to a local user
+type Link struct {
+	ID       string    `json:"-"`
+	Provider string    `json:"provider"`
+	Subject  string    `json:"subject"`
+	UserID   string    `json:"user_id"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+// StoreInterface persists the links of the identities to the users
+type StoreInterface interface {
+	LinkCreate(link *Link) error
+	LinkDelete(link Link) error
+	LinkFind(provider string, subject string) (*Link, error)
+	LinkListByUserID(userID string) ([]Link, error)
+}
+
+type store struct {
+	customStore customstore.StoreInterface
+}
+
+var _ StoreInterface = (*store)(nil)
+
+// NewStore creates a link store on top of the custom store
+func NewStore(customStore customstore.StoreInterface) (StoreInterface, error) {
+	if customStore == nil {
+		return nil, errors.New("custom store is required")
+	}
+
+	return &store{customStore: customStore}, nil
+}
+
+// LinkCreate saves the link, and sets its ID
+func (s *store) LinkCreate(link *Link) error {
+	if link == nil || link.Provider == "" || link.Subject == "" || link.UserID == "" {
+		return errors.New("provider, subject and user ID are required")
+	}
+
+	if link.LinkedAt.IsZero() {
+		link.LinkedAt = time.Now().UTC()
+	}
+
+	payload, err := json.Marshal(link)
+	if err != nil {
+		return err
+	}
+
+	record := customstore.NewRecord(RECORD_TYPE_OAUTH_IDENTITY, customstore.WithPayload(string(payload)))
+
+	if err := s.customStore.RecordCreate(record); err != nil {
+		return err
+	}
+
+	link.ID = record.ID()
+
+	return nil
+}
+
+// LinkDelete removes the link, the user can no longer log in with the
+// identity
+func (s *store) LinkDelete(link Link) error {
+	if link.ID == "" {
+		return errors.New("link ID is required")
+	}
+
+	return s.customStore.RecordDeleteByID(link.ID)
+}
+
+// LinkFind returns the link of the identity, or nil if the identity is
+// not linked to any user
+func (s *store) LinkFind(provider string, subject string) (*Link, error) {
+	if provider == "" || subject == "" {
+		return nil, errors.New("provider and subject are required")
+	}
+
+	links, err := s.linkList(customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_OAUTH_IDENTITY), map[string]string{"provider": provider, "subject": subject})
+
+	if err != nil || len(links) == 0 {
+		return nil, err
+	}
+
+	return &links[0], nil
+}
+
+// LinkListByUserID returns the identities linked to the user
+func (s *store) LinkListByUserID(userID string) ([]Link, error) {
+	if userID == "" {
+		return nil, errors.New("user ID is required")
+	}
+
+	return s.linkList(customstore.NewRecordQuery().
+		SetType(RECORD_TYPE_OAUTH_IDENTITY), map[string]string{"user_id": userID})
+}
+
+func (s *store) linkList(query customstore.RecordQueryInterface, fields map[string]string) ([]Link, error) {
+	records, err := customrecords.FindByPayload(s.customStore, query, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	links := []Link{}
+
+	for _, record := range records {
+		link := Link{}
+		if err := json.Unmarshal([]byte(record.Payload()), &link); err != nil {
+			return nil, err
+		}
+
+		link.ID = record.ID()
+		links = append(links, link)
+	}
+
+	return links, nil
+}
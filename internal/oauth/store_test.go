@@ -0,0 +1,112 @@
+package oauth
+
+import (
+	"testing"
+
+	"project/internal/testutils"
+)
+
+func newTestStore(t *testing.T) StoreInterface {
+	t.Helper()
+
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	store, err := NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func TestNewStore_RequiresCustomStore(t *testing.T) {
+	if _, err := NewStore(nil); err == nil {
+		t.Error("NewStore(nil) expected error")
+	}
+}
+
+func TestStore_LinkCreateAndFind(t *testing.T) {
+	store := newTestStore(t)
+
+	link := &Link{Provider: PROVIDER_GITHUB, Subject: "42", UserID: "user_1"}
+	if err := store.LinkCreate(link); err != nil {
+		t.Fatal(err)
+	}
+
+	if link.ID == "" || link.LinkedAt.IsZero() {
+		t.Fatalf("LinkCreate() must set the ID and the linked at time, got %+v", link)
+	}
+
+	found, err := store.LinkFind(PROVIDER_GITHUB, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found == nil || found.UserID != "user_1" {
+		t.Fatalf("expected the link of user_1, got %+v", found)
+	}
+
+	// The same subject at another provider is another identity
+	other, err := store.LinkFind(PROVIDER_GOOGLE, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if other != nil {
+		t.Fatalf("expected no link at another provider, got %+v", other)
+	}
+
+	// 4 is a prefix of 42, the exact subject must match
+	prefix, err := store.LinkFind(PROVIDER_GITHUB, "4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if prefix != nil {
+		t.Fatalf("expected no link for subject 4, got %+v", prefix)
+	}
+}
+
+func TestStore_LinkListByUserIDAndDelete(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, link := range []*Link{
+		{Provider: PROVIDER_GITHUB, Subject: "42", UserID: "user_1"},
+		{Provider: PROVIDER_GOOGLE, Subject: "abc", UserID: "user_1"},
+		{Provider: PROVIDER_GOOGLE, Subject: "def", UserID: "user_10"},
+	} {
+		if err := store.LinkCreate(link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links, err := store.LinkListByUserID("user_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(links) != 2 {
+		t.Fatalf("expected 2 links for user_1, got %d", len(links))
+	}
+
+	if err := store.LinkDelete(links[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	links, err = store.LinkListByUserID("user_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(links) != 1 {
+		t.Fatalf("expected 1 link left for user_1, got %d", len(links))
+	}
+}
+
+func TestStore_LinkCreateRequiresFields(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.LinkCreate(&Link{Provider: PROVIDER_GITHUB, Subject: "42"}); err == nil {
+		t.Fatal("expected an error without the user ID")
+	}
+}
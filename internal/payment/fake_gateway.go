@@ -0,0 +1,244 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// FAKE_WEBHOOK_SECRET is the signing secret of the fake gateway
+// created for local development
+const FAKE_WEBHOOK_SECRET = "fake_webhook_secret"
+
+// FakeSession is a checkout session held by the fake gateway
+type FakeSession struct {
+	ID             string
+	Options        CheckoutOptions
+	Amount         int64
+	Paid           bool
+	RefundedAmount int64
+}
+
+// FakeGateway is an in-memory gateway for tests and local development.
+//
+// No money is taken, the checkout URL is the success URL itself, and
+// sessions are paid immediately unless auto pay is switched off.
+// Webhook payloads are Event values encoded as JSON, signed with
+// a hex encoded HMAC-SHA256 of the payload (see Sign) sent in
+// the X-Webhook-Signature header.
+type FakeGateway struct {
+	mu            sync.Mutex
+	autoPay       bool
+	webhookSecret string
+	sessions      map[string]*FakeSession
+	counter       int
+	// cancelled the IDs of the subscriptions cancelled with SubscriptionCancel
+	cancelled map[string]bool
+}
+
+var _ PaymentGatewayInterface = (*FakeGateway)(nil)
+
+// NewFakeGateway creates a fake gateway, which pays the sessions immediately
+func NewFakeGateway(webhookSecret string) *FakeGateway {
+	return &FakeGateway{
+		autoPay:       true,
+		webhookSecret: webhookSecret,
+		sessions:      map[string]*FakeSession{},
+		cancelled:     map[string]bool{},
+	}
+}
+
+// SetAutoPay sets whether new checkout sessions are paid immediately
+func (gateway *FakeGateway) SetAutoPay(autoPay bool) *FakeGateway {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+	gateway.autoPay = autoPay
+	return gateway
+}
+
+// MarkPaid marks the checkout session as paid
+func (gateway *FakeGateway) MarkPaid(sessionID string) error {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	session, ok := gateway.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("checkout session %q not found", sessionID)
+	}
+
+	session.Paid = true
+	return nil
+}
+
+// Session returns a copy of the checkout session with the given ID
+func (gateway *FakeGateway) Session(sessionID string) (FakeSession, bool) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	session, ok := gateway.sessions[sessionID]
+	if !ok {
+		return FakeSession{}, false
+	}
+
+	return *session, true
+}
+
+// Sessions returns copies of all the checkout sessions created
+func (gateway *FakeGateway) Sessions() []FakeSession {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	sessions := make([]FakeSession, 0, len(gateway.sessions))
+	for i := 1; i <= gateway.counter; i++ {
+		if session, ok := gateway.sessions[fakeSessionID(i)]; ok {
+			sessions = append(sessions, *session)
+		}
+	}
+
+	return sessions
+}
+
+// SubscriptionCancelled returns whether the subscription was cancelled
+// with SubscriptionCancel
+func (gateway *FakeGateway) SubscriptionCancelled(subscriptionID string) bool {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	return gateway.cancelled[subscriptionID]
+}
+
+// FAKE_SIGNATURE_HEADER is the header carrying the webhook signature
+const FAKE_SIGNATURE_HEADER = "X-Webhook-Signature"
+
+// Sign returns the signature expected by WebhookParse for the payload
+func (gateway *FakeGateway) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(gateway.webhookSecret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (gateway *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (gateway *FakeGateway) CheckoutCreate(ctx context.Context, options CheckoutOptions) (CheckoutSession, error) {
+	if options.Currency == "" {
+		return CheckoutSession{}, errors.New("currency is required")
+	}
+
+	if len(options.LineItems) == 0 {
+		return CheckoutSession{}, errors.New("at least one line item is required")
+	}
+
+	if options.SubscriptionID != "" && options.Interval != INTERVAL_MONTH && options.Interval != INTERVAL_YEAR {
+		return CheckoutSession{}, fmt.Errorf("invalid subscription interval %q", options.Interval)
+	}
+
+	amount := int64(0)
+	for _, item := range options.LineItems {
+		amount += item.UnitAmount * item.Quantity
+	}
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	gateway.counter++
+	session := &FakeSession{
+		ID:      fakeSessionID(gateway.counter),
+		Options: options,
+		Amount:  amount,
+		Paid:    gateway.autoPay,
+	}
+	gateway.sessions[session.ID] = session
+
+	return CheckoutSession{ID: session.ID, URL: options.SuccessURL}, nil
+}
+
+func (gateway *FakeGateway) CheckoutVerify(ctx context.Context, sessionID string) (bool, error) {
+	session, ok := gateway.Session(sessionID)
+	if !ok {
+		return false, fmt.Errorf("checkout session %q not found", sessionID)
+	}
+
+	return session.Paid, nil
+}
+
+func (gateway *FakeGateway) Refund(ctx context.Context, options RefundOptions) (string, error) {
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	session, ok := gateway.sessions[options.SessionID]
+	if !ok {
+		return "", fmt.Errorf("checkout session %q not found", options.SessionID)
+	}
+
+	if !session.Paid {
+		return "", errors.New("checkout session has no payment to refund")
+	}
+
+	amount := options.Amount
+	if amount == 0 {
+		amount = session.Amount - session.RefundedAmount
+	}
+
+	if amount < 0 || session.RefundedAmount+amount > session.Amount {
+		return "", errors.New("refund amount exceeds the amount paid")
+	}
+
+	session.RefundedAmount += amount
+
+	return "fake_re_" + session.ID, nil
+}
+
+func (gateway *FakeGateway) SubscriptionCancel(ctx context.Context, subscriptionID string) error {
+	if subscriptionID == "" {
+		return errors.New("subscription ID is required")
+	}
+
+	gateway.mu.Lock()
+	defer gateway.mu.Unlock()
+
+	for _, session := range gateway.sessions {
+		if session.Options.SubscriptionID == subscriptionID && session.Paid {
+			gateway.cancelled[subscriptionID] = true
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no paid subscription checkout found for subscription %q", subscriptionID)
+}
+
+func (gateway *FakeGateway) WebhookParse(payload []byte, header http.Header) (Event, error) {
+	if gateway.webhookSecret == "" {
+		return Event{}, errors.New("webhook signing secret is not configured")
+	}
+
+	if !hmac.Equal([]byte(header.Get(FAKE_SIGNATURE_HEADER)), []byte(gateway.Sign(payload))) {
+		return Event{}, errors.New("invalid signature")
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return Event{}, fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	if event.ID == "" {
+		return Event{}, errors.New("event ID is required")
+	}
+
+	if event.Type == "" {
+		event.Type = EVENT_IGNORED
+	}
+
+	return event, nil
+}
+
+func fakeSessionID(counter int) string {
+	return fmt.Sprintf("fake_cs_%d", counter)
+}
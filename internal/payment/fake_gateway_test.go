@@ -0,0 +1,174 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func fakeCheckoutOptions() CheckoutOptions {
+	return CheckoutOptions{
+		Currency:   "GBP",
+		OrderID:    "order_01",
+		SuccessURL: "https://example.com/success",
+		CancelURL:  "https://example.com/cancel",
+		LineItems: []LineItem{
+			{Name: "Product 1", Quantity: 2, UnitAmount: 1250},
+			{Name: "Product 2", Quantity: 1, UnitAmount: 499},
+		},
+	}
+}
+
+func TestFakeGateway_CheckoutCreate(t *testing.T) {
+	gateway := NewFakeGateway("secret")
+
+	session, err := gateway.CheckoutCreate(context.Background(), fakeCheckoutOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if session.ID == "" {
+		t.Fatal("expected a session ID")
+	}
+
+	if session.URL != "https://example.com/success" {
+		t.Errorf("expected the success URL, got %q", session.URL)
+	}
+
+	stored, ok := gateway.Session(session.ID)
+	if !ok {
+		t.Fatal("expected the session to be stored")
+	}
+
+	if stored.Amount != 2999 {
+		t.Errorf("expected amount 2999, got %d", stored.Amount)
+	}
+
+	paid, err := gateway.CheckoutVerify(context.Background(), session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !paid {
+		t.Error("expected the session to be paid")
+	}
+}
+
+func TestFakeGateway_CheckoutCreateRequiresCurrency(t *testing.T) {
+	options := fakeCheckoutOptions()
+	options.Currency = ""
+
+	if _, err := NewFakeGateway("secret").CheckoutCreate(context.Background(), options); err == nil {
+		t.Fatal("expected an error without currency")
+	}
+}
+
+func TestFakeGateway_AutoPayDisabled(t *testing.T) {
+	gateway := NewFakeGateway("secret").SetAutoPay(false)
+
+	session, err := gateway.CheckoutCreate(context.Background(), fakeCheckoutOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if paid, _ := gateway.CheckoutVerify(context.Background(), session.ID); paid {
+		t.Fatal("expected the session not to be paid")
+	}
+
+	if _, err := gateway.Refund(context.Background(), RefundOptions{SessionID: session.ID}); err == nil {
+		t.Error("expected an unpaid session not to be refundable")
+	}
+
+	if err := gateway.MarkPaid(session.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if paid, _ := gateway.CheckoutVerify(context.Background(), session.ID); !paid {
+		t.Error("expected the session to be paid")
+	}
+}
+
+func TestFakeGateway_SubscriptionCancel(t *testing.T) {
+	gateway := NewFakeGateway("secret")
+
+	options := fakeCheckoutOptions()
+	options.OrderID = ""
+	options.SubscriptionID = "subscription_01"
+
+	if _, err := gateway.CheckoutCreate(context.Background(), options); err == nil {
+		t.Fatal("expected an error without subscription interval")
+	}
+
+	options.Interval = INTERVAL_MONTH
+
+	if _, err := gateway.CheckoutCreate(context.Background(), options); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gateway.SubscriptionCancel(context.Background(), "subscription_unknown"); err == nil {
+		t.Error("expected an error for an unknown subscription")
+	}
+
+	if err := gateway.SubscriptionCancel(context.Background(), "subscription_01"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !gateway.SubscriptionCancelled("subscription_01") {
+		t.Error("expected the subscription to be cancelled")
+	}
+}
+
+func TestFakeGateway_Refund(t *testing.T) {
+	gateway := NewFakeGateway("secret")
+
+	session, err := gateway.CheckoutCreate(context.Background(), fakeCheckoutOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := gateway.Refund(context.Background(), RefundOptions{SessionID: session.ID, Amount: 1000}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := gateway.Refund(context.Background(), RefundOptions{SessionID: session.ID, Amount: 5000}); err == nil {
+		t.Error("expected refunding more than paid to fail")
+	}
+
+	// A zero amount refunds the remainder
+	if _, err := gateway.Refund(context.Background(), RefundOptions{SessionID: session.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, _ := gateway.Session(session.ID)
+	if stored.RefundedAmount != stored.Amount {
+		t.Errorf("expected %d refunded, got %d", stored.Amount, stored.RefundedAmount)
+	}
+}
+
+func TestFakeGateway_WebhookParse(t *testing.T) {
+	gateway := NewFakeGateway("secret")
+	payload := []byte(`{"ID":"evt_1","Type":"order.paid","OrderID":"order_01"}`)
+
+	header := http.Header{}
+	header.Set(FAKE_SIGNATURE_HEADER, gateway.Sign(payload))
+
+	event, err := gateway.WebhookParse(payload, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if event.ID != "evt_1" || event.Type != EVENT_ORDER_PAID || event.OrderID != "order_01" {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	invalidHeader := http.Header{}
+	invalidHeader.Set(FAKE_SIGNATURE_HEADER, "invalid")
+
+	if _, err := gateway.WebhookParse(payload, invalidHeader); err == nil {
+		t.Error("expected an invalid signature to be rejected")
+	}
+
+	if _, err := NewFakeGateway("").WebhookParse(payload, http.Header{}); err == nil {
+		t.Error("expected an error without signing secret")
+	}
+}
@@ -0,0 +1,121 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// zeroDecimalCurrencies are the currencies without minor units
+var zeroDecimalCurrencies = map[string]bool{
+	"BIF": true, "CLP": true, "DJF": true, "GNF": true, "ISK": true,
+	"JPY": true, "KMF": true, "KRW": true, "MGA": true, "PYG": true,
+	"RWF": true, "UGX": true, "VND": true, "VUV": true, "XAF": true,
+	"XOF": true, "XPF": true,
+}
+
+// threeDecimalCurrencies are the currencies with 1000 minor units
+var threeDecimalCurrencies = map[string]bool{
+	"BHD": true, "JOD": true, "KWD": true, "OMR": true, "TND": true,
+}
+
+// CurrencyDecimals returns the number of decimal places of the currency
+func CurrencyDecimals(currency string) int {
+	currency = strings.ToUpper(currency)
+
+	if zeroDecimalCurrencies[currency] {
+		return 0
+	}
+
+	if threeDecimalCurrencies[currency] {
+		return 3
+	}
+
+	return 2
+}
+
+// ToMinorUnits converts a decimal amount, as stored in the shop store
+// (e.g. "12.50"), to integer minor units of the currency (e.g. 1250).
+//
+// The amount is parsed as a decimal string, so no floating point rounding
+// errors are introduced. Extra decimal places are rounded half up.
+func ToMinorUnits(amount string, currency string) (int64, error) {
+	amount = strings.TrimSpace(amount)
+
+	if amount == "" {
+		return 0, errors.New("amount is empty")
+	}
+
+	negative := strings.HasPrefix(amount, "-")
+	amount = strings.TrimPrefix(strings.TrimPrefix(amount, "-"), "+")
+
+	wholePart, fractionPart, _ := strings.Cut(amount, ".")
+
+	if wholePart == "" {
+		wholePart = "0"
+	}
+
+	if !isDigits(wholePart) || (fractionPart != "" && !isDigits(fractionPart)) {
+		return 0, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	decimals := CurrencyDecimals(currency)
+
+	roundUp := false
+	if len(fractionPart) > decimals {
+		roundUp = fractionPart[decimals] >= '5'
+		fractionPart = fractionPart[:decimals]
+	}
+
+	fractionPart += strings.Repeat("0", decimals-len(fractionPart))
+
+	minorUnits, err := strconv.ParseInt(wholePart+fractionPart, 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", amount, err)
+	}
+
+	if roundUp {
+		minorUnits++
+	}
+
+	if negative {
+		minorUnits = -minorUnits
+	}
+
+	return minorUnits, nil
+}
+
+// FormatMinorUnits formats an amount in minor units as a decimal string,
+// e.g. 1250 GBP as "12.50"
+func FormatMinorUnits(amount int64, currency string) string {
+	decimals := CurrencyDecimals(currency)
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+
+	if decimals == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:]
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
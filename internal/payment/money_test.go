@@ -0,0 +1,68 @@
+package payment
+
+import "testing"
+
+func TestToMinorUnits(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		expected int64
+	}{
+		{"12.50", "GBP", 1250},
+		{"12.5", "GBP", 1250},
+		{"12", "GBP", 1200},
+		{"0.10", "GBP", 10},
+		{".99", "EUR", 99},
+		{"19.999", "USD", 2000},
+		{"19.994", "USD", 1999},
+		{"-5.25", "GBP", -525},
+		{"1500", "JPY", 1500},
+		{"1500.6", "JPY", 1501},
+		{"1.234", "KWD", 1234},
+		// 0.1 + 0.2 style amounts must not suffer from float rounding
+		{"0.29", "gbp", 29},
+		{"1.15", "GBP", 115},
+	}
+
+	for _, test := range tests {
+		actual, err := ToMinorUnits(test.amount, test.currency)
+		if err != nil {
+			t.Errorf("ToMinorUnits(%q, %q) unexpected error: %v", test.amount, test.currency, err)
+			continue
+		}
+
+		if actual != test.expected {
+			t.Errorf("ToMinorUnits(%q, %q) = %d, expected %d", test.amount, test.currency, actual, test.expected)
+		}
+	}
+}
+
+func TestToMinorUnits_Invalid(t *testing.T) {
+	for _, amount := range []string{"", "abc", "1.2.3", "1,50", "£10"} {
+		if _, err := ToMinorUnits(amount, "GBP"); err == nil {
+			t.Errorf("ToMinorUnits(%q) expected an error", amount)
+		}
+	}
+}
+
+func TestFormatMinorUnits(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		expected string
+	}{
+		{1250, "GBP", "12.50"},
+		{5, "GBP", "0.05"},
+		{0, "GBP", "0.00"},
+		{-525, "GBP", "-5.25"},
+		{1500, "JPY", "1500"},
+		{1234, "KWD", "1.234"},
+	}
+
+	for _, test := range tests {
+		actual := FormatMinorUnits(test.amount, test.currency)
+		if actual != test.expected {
+			t.Errorf("FormatMinorUnits(%d, %q) = %q, expected %q", test.amount, test.currency, actual, test.expected)
+		}
+	}
+}
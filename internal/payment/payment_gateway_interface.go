@@ -0,0 +1,115 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+)
+
+// Keys of the metadata attached to the payment provider objects,
+// used to find the local order or subscription an event belongs to
+const (
+	METADATA_ORDER_ID        = "order_id"
+	METADATA_SUBSCRIPTION_ID = "subscription_id"
+)
+
+// Gateway independent event types, returned by WebhookParse
+const (
+	// EVENT_ORDER_PAID the payment for an order has been received
+	EVENT_ORDER_PAID = "order.paid"
+	// EVENT_SUBSCRIPTION_PAID a subscription was paid for (first payment or renewal)
+	EVENT_SUBSCRIPTION_PAID = "subscription.paid"
+	// EVENT_SUBSCRIPTION_CANCELLED a subscription has ended at the payment provider
+	EVENT_SUBSCRIPTION_CANCELLED = "subscription.cancelled"
+	// EVENT_IGNORED an event which requires no action
+	EVENT_IGNORED = "ignored"
+)
+
+// Billing intervals of the subscription checkout sessions
+const (
+	INTERVAL_MONTH = "month"
+	INTERVAL_YEAR  = "year"
+)
+
+// PaymentGatewayInterface is implemented by the payment providers.
+//
+// All the amounts are in minor units of the currency (e.g. pence for GBP),
+// see ToMinorUnits and FormatMinorUnits.
+type PaymentGatewayInterface interface {
+	// Name returns the name of the gateway, as used in the configuration
+	Name() string
+
+	// CheckoutCreate creates a hosted checkout session, the buyer
+	// is to be redirected to the returned session URL
+	CheckoutCreate(ctx context.Context, options CheckoutOptions) (CheckoutSession, error)
+
+	// CheckoutVerify confirms with the provider that the checkout session
+	// has been paid, as the return URL alone is not a proof of payment
+	CheckoutVerify(ctx context.Context, sessionID string) (bool, error)
+
+	// Refund refunds the payment taken by the checkout session,
+	// and returns the ID of the refund
+	Refund(ctx context.Context, options RefundOptions) (string, error)
+
+	// SubscriptionCancel cancels immediately the provider subscription
+	// created for the local subscription with the given ID
+	SubscriptionCancel(ctx context.Context, subscriptionID string) error
+
+	// WebhookParse verifies the signature of a webhook payload, found in
+	// the request headers, and converts it to a gateway independent event
+	WebhookParse(payload []byte, header http.Header) (Event, error)
+}
+
+// CheckoutOptions describes the checkout session to create.
+//
+// Setting the SubscriptionID creates a subscription checkout, where the
+// line items are charged every Interval, instead of a one-off payment.
+type CheckoutOptions struct {
+	// Currency ISO 4217 code, e.g. GBP
+	Currency      string
+	CustomerEmail string
+	LineItems     []LineItem
+	OrderID       string
+	SuccessURL    string
+	CancelURL     string
+
+	// SubscriptionID the local subscription paid for by the checkout
+	SubscriptionID string
+	// Interval one of the INTERVAL_* constants, required for subscriptions
+	Interval string
+	// TrialDays the number of days before the first payment of a subscription
+	TrialDays int64
+}
+
+// LineItem is a line of the checkout session
+type LineItem struct {
+	Name     string
+	Quantity int64
+	// UnitAmount the price of one unit, in minor units
+	UnitAmount int64
+}
+
+// CheckoutSession is the checkout session created by the gateway
+type CheckoutSession struct {
+	ID  string
+	URL string
+}
+
+// RefundOptions describes the refund to make
+type RefundOptions struct {
+	// SessionID the checkout session the payment was taken with
+	SessionID string
+	// Amount the amount to refund in minor units, zero refunds the full payment
+	Amount int64
+}
+
+// Event is a webhook event, converted to a gateway independent form
+type Event struct {
+	// ID the ID of the event at the provider, used to deduplicate deliveries
+	ID string
+	// Type one of the EVENT_* constants
+	Type string
+	// ProviderType the event type as named by the provider
+	ProviderType   string
+	OrderID        string
+	SubscriptionID string
+}
@@ -0,0 +1,363 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	stripe "github.com/stripe/stripe-go/v73"
+	stripeSession "github.com/stripe/stripe-go/v73/checkout/session"
+	stripeRefund "github.com/stripe/stripe-go/v73/refund"
+	stripeSubscription "github.com/stripe/stripe-go/v73/subscription"
+	"github.com/stripe/stripe-go/v73/webhook"
+)
+
+// Stripe event types converted by WebhookParse
+const (
+	stripeEventCheckoutSessionCompleted             = "checkout.session.completed"
+	stripeEventCheckoutSessionAsyncPaymentSucceeded = "checkout.session.async_payment_succeeded"
+	stripeEventInvoicePaid                          = "invoice.paid"
+	stripeEventCustomerSubscriptionDeleted          = "customer.subscription.deleted"
+)
+
+// stripeGateway takes payments with Stripe Checkout.
+//
+// Each gateway uses its own API clients with its own key,
+// so the global stripe.Key is never set.
+type stripeGateway struct {
+	webhookSecret string
+	sessions      stripeSession.Client
+	refunds       stripeRefund.Client
+	subscriptions stripeSubscription.Client
+}
+
+var _ PaymentGatewayInterface = (*stripeGateway)(nil)
+
+// NewStripeGateway creates a Stripe gateway
+//
+// Parameters:
+// - keyPrivate: the Stripe secret key
+// - webhookSecret: the signing secret of the webhook endpoint
+func NewStripeGateway(keyPrivate string, webhookSecret string) PaymentGatewayInterface {
+	backend := stripe.GetBackend(stripe.APIBackend)
+
+	return &stripeGateway{
+		webhookSecret: webhookSecret,
+		sessions:      stripeSession.Client{B: backend, Key: keyPrivate},
+		refunds:       stripeRefund.Client{B: backend, Key: keyPrivate},
+		subscriptions: stripeSubscription.Client{B: backend, Key: keyPrivate},
+	}
+}
+
+func (gateway *stripeGateway) Name() string {
+	return "stripe"
+}
+
+func (gateway *stripeGateway) CheckoutCreate(ctx context.Context, options CheckoutOptions) (CheckoutSession, error) {
+	if options.Currency == "" {
+		return CheckoutSession{}, errors.New("currency is required")
+	}
+
+	if len(options.LineItems) == 0 {
+		return CheckoutSession{}, errors.New("at least one line item is required")
+	}
+
+	isSubscription := options.SubscriptionID != ""
+
+	if isSubscription && options.Interval != INTERVAL_MONTH && options.Interval != INTERVAL_YEAR {
+		return CheckoutSession{}, fmt.Errorf("invalid subscription interval %q", options.Interval)
+	}
+
+	lineItems := []*stripe.CheckoutSessionLineItemParams{}
+
+	for _, item := range options.LineItems {
+		name := item.Name
+		if options.OrderID != "" {
+			name = fmt.Sprintf("%s (Order: %s)", item.Name, options.OrderID)
+		}
+
+		priceData := &stripe.CheckoutSessionLineItemPriceDataParams{
+			Currency: stripe.String(strings.ToLower(options.Currency)),
+			ProductData: &stripe.CheckoutSessionLineItemPriceDataProductDataParams{
+				Name: stripe.String(name),
+			},
+			UnitAmount: stripe.Int64(item.UnitAmount),
+		}
+
+		if isSubscription {
+			priceData.Recurring = &stripe.CheckoutSessionLineItemPriceDataRecurringParams{
+				Interval: stripe.String(options.Interval),
+			}
+		}
+
+		lineItems = append(lineItems, &stripe.CheckoutSessionLineItemParams{
+			PriceData: priceData,
+			Quantity:  stripe.Int64(item.Quantity),
+		})
+	}
+
+	params := &stripe.CheckoutSessionParams{
+		PaymentMethodTypes: stripe.StringSlice([]string{
+			"card",
+		}),
+		Mode:       stripe.String(string(stripe.CheckoutSessionModePayment)),
+		LineItems:  lineItems,
+		SuccessURL: stripe.String(options.SuccessURL),
+		CancelURL:  stripe.String(options.CancelURL),
+	}
+	params.Context = ctx
+
+	if options.CustomerEmail != "" {
+		params.CustomerEmail = stripe.String(options.CustomerEmail)
+	}
+
+	if options.OrderID != "" {
+		params.ClientReferenceID = stripe.String(options.OrderID)
+		params.AddMetadata(METADATA_ORDER_ID, options.OrderID)
+	}
+
+	// The subscription carries the local ID too, so the renewal invoices
+	// and the cancellation events can be matched to the local subscription
+	if isSubscription {
+		params.Mode = stripe.String(string(stripe.CheckoutSessionModeSubscription))
+		params.AddMetadata(METADATA_SUBSCRIPTION_ID, options.SubscriptionID)
+		params.SubscriptionData = &stripe.CheckoutSessionSubscriptionDataParams{
+			Metadata: map[string]string{METADATA_SUBSCRIPTION_ID: options.SubscriptionID},
+		}
+
+		if options.TrialDays > 0 {
+			params.SubscriptionData.TrialPeriodDays = stripe.Int64(options.TrialDays)
+		}
+	}
+
+	session, err := gateway.sessions.New(params)
+
+	if err != nil {
+		return CheckoutSession{}, err
+	}
+
+	return CheckoutSession{ID: session.ID, URL: session.URL}, nil
+}
+
+func (gateway *stripeGateway) CheckoutVerify(ctx context.Context, sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, errors.New("checkout session ID is required")
+	}
+
+	params := &stripe.CheckoutSessionParams{}
+	params.Context = ctx
+
+	session, err := gateway.sessions.Get(sessionID, params)
+
+	if err != nil {
+		return false, err
+	}
+
+	// Subscriptions starting with a trial complete without a payment
+	return session.PaymentStatus == stripe.CheckoutSessionPaymentStatusPaid ||
+		session.PaymentStatus == stripe.CheckoutSessionPaymentStatusNoPaymentRequired, nil
+}
+
+func (gateway *stripeGateway) Refund(ctx context.Context, options RefundOptions) (string, error) {
+	if options.SessionID == "" {
+		return "", errors.New("checkout session ID is required")
+	}
+
+	if options.Amount < 0 {
+		return "", errors.New("refund amount cannot be negative")
+	}
+
+	sessionParams := &stripe.CheckoutSessionParams{}
+	sessionParams.Context = ctx
+	sessionParams.AddExpand("payment_intent")
+
+	session, err := gateway.sessions.Get(options.SessionID, sessionParams)
+
+	if err != nil {
+		return "", err
+	}
+
+	if session.PaymentIntent == nil || session.PaymentIntent.ID == "" {
+		return "", errors.New("checkout session has no payment to refund")
+	}
+
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(session.PaymentIntent.ID),
+	}
+	params.Context = ctx
+
+	if options.Amount > 0 {
+		params.Amount = stripe.Int64(options.Amount)
+	}
+
+	refund, err := gateway.refunds.New(params)
+
+	if err != nil {
+		return "", err
+	}
+
+	return refund.ID, nil
+}
+
+func (gateway *stripeGateway) SubscriptionCancel(ctx context.Context, subscriptionID string) error {
+	if subscriptionID == "" {
+		return errors.New("subscription ID is required")
+	}
+
+	// Search is eventually consistent, so a subscription created
+	// a few seconds ago may not be found yet
+	searchParams := &stripe.SubscriptionSearchParams{}
+	searchParams.Context = ctx
+	searchParams.Query = fmt.Sprintf("metadata['%s']:'%s'", METADATA_SUBSCRIPTION_ID, strings.ReplaceAll(subscriptionID, "'", ""))
+
+	iter := gateway.subscriptions.Search(searchParams)
+	found := false
+
+	for iter.Next() {
+		providerSubscription := iter.Subscription()
+		found = true
+
+		if providerSubscription.Status == stripe.SubscriptionStatusCanceled {
+			continue
+		}
+
+		params := &stripe.SubscriptionCancelParams{}
+		params.Context = ctx
+
+		if _, err := gateway.subscriptions.Cancel(providerSubscription.ID, params); err != nil {
+			return err
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("no Stripe subscription found for subscription %q", subscriptionID)
+	}
+
+	return nil
+}
+
+func (gateway *stripeGateway) WebhookParse(payload []byte, header http.Header) (Event, error) {
+	if gateway.webhookSecret == "" {
+		return Event{}, errors.New("webhook signing secret is not configured")
+	}
+
+	stripeEvent, err := webhook.ConstructEventWithOptions(payload, header.Get("Stripe-Signature"), gateway.webhookSecret, webhook.ConstructEventOptions{
+		// The events are decoded field by field, so events sent with
+		// an API version other than the one of the library are accepted
+		IgnoreAPIVersionMismatch: true,
+	})
+
+	if err != nil {
+		return Event{}, err
+	}
+
+	if stripeEvent.Data == nil {
+		return Event{}, errors.New("event has no data")
+	}
+
+	event := Event{
+		ID:           stripeEvent.ID,
+		Type:         EVENT_IGNORED,
+		ProviderType: stripeEvent.Type,
+	}
+
+	switch stripeEvent.Type {
+	case stripeEventCheckoutSessionCompleted, stripeEventCheckoutSessionAsyncPaymentSucceeded:
+		return stripeCheckoutSessionEvent(event, stripeEvent.Data.Raw)
+	case stripeEventInvoicePaid:
+		return stripeInvoicePaidEvent(event, stripeEvent.Data.Raw)
+	case stripeEventCustomerSubscriptionDeleted:
+		return stripeSubscriptionDeletedEvent(event, stripeEvent.Data.Raw)
+	}
+
+	return event, nil
+}
+
+// stripeCheckoutSessionEvent converts a paid checkout session to an order
+// (one-off payment) or a subscription (subscription checkout) paid event
+func stripeCheckoutSessionEvent(event Event, raw json.RawMessage) (Event, error) {
+	var session stripe.CheckoutSession
+	if err := json.Unmarshal(raw, &session); err != nil {
+		return event, fmt.Errorf("failed to decode checkout session: %w", err)
+	}
+
+	// Delayed payment methods (e.g. bank debits) complete the session
+	// before the money arrives, the async_payment_succeeded event follows
+	if session.PaymentStatus != stripe.CheckoutSessionPaymentStatusPaid &&
+		session.PaymentStatus != stripe.CheckoutSessionPaymentStatusNoPaymentRequired {
+		return event, nil
+	}
+
+	if session.Mode == stripe.CheckoutSessionModeSubscription {
+		event.Type = EVENT_SUBSCRIPTION_PAID
+		event.SubscriptionID = session.Metadata[METADATA_SUBSCRIPTION_ID]
+		if event.SubscriptionID == "" && session.Subscription != nil {
+			event.SubscriptionID = session.Subscription.Metadata[METADATA_SUBSCRIPTION_ID]
+		}
+		return event, nil
+	}
+
+	event.Type = EVENT_ORDER_PAID
+	event.OrderID = session.Metadata[METADATA_ORDER_ID]
+	if event.OrderID == "" {
+		event.OrderID = session.ClientReferenceID
+	}
+
+	return event, nil
+}
+
+// stripeInvoicePaidEvent converts a paid subscription invoice
+// (every renewal) to a subscription paid event
+func stripeInvoicePaidEvent(event Event, raw json.RawMessage) (Event, error) {
+	var invoice stripe.Invoice
+	if err := json.Unmarshal(raw, &invoice); err != nil {
+		return event, fmt.Errorf("failed to decode invoice: %w", err)
+	}
+
+	subscriptionID := ""
+
+	if invoice.Subscription != nil {
+		subscriptionID = invoice.Subscription.Metadata[METADATA_SUBSCRIPTION_ID]
+	}
+
+	// Subscription line items carry the metadata of the subscription
+	if subscriptionID == "" && invoice.Lines != nil {
+		for _, line := range invoice.Lines.Data {
+			if line != nil && line.Metadata[METADATA_SUBSCRIPTION_ID] != "" {
+				subscriptionID = line.Metadata[METADATA_SUBSCRIPTION_ID]
+				break
+			}
+		}
+	}
+
+	// One-off invoices are not linked to a subscription
+	if subscriptionID == "" {
+		return event, nil
+	}
+
+	event.Type = EVENT_SUBSCRIPTION_PAID
+	event.SubscriptionID = subscriptionID
+
+	return event, nil
+}
+
+// stripeSubscriptionDeletedEvent converts an ended subscription
+// to a subscription cancelled event
+func stripeSubscriptionDeletedEvent(event Event, raw json.RawMessage) (Event, error) {
+	var subscription stripe.Subscription
+	if err := json.Unmarshal(raw, &subscription); err != nil {
+		return event, fmt.Errorf("failed to decode subscription: %w", err)
+	}
+
+	event.Type = EVENT_SUBSCRIPTION_CANCELLED
+	event.SubscriptionID = subscription.Metadata[METADATA_SUBSCRIPTION_ID]
+
+	return event, nil
+}
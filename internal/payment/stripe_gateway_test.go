@@ -0,0 +1,148 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stripe/stripe-go/v73/webhook"
+)
+
+const testStripeWebhookSecret = "whsec_test_secret"
+
+func signStripePayload(payload string, secret string) http.Header {
+	header := http.Header{}
+	header.Set("Stripe-Signature", webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload: []byte(payload),
+		Secret:  secret,
+	}).Header)
+	return header
+}
+
+func TestStripeGateway_WebhookParse(t *testing.T) {
+	tests := []struct {
+		name                   string
+		payload                string
+		expectedType           string
+		expectedOrderID        string
+		expectedSubscriptionID string
+	}{
+		{
+			name:            "checkout session paid",
+			payload:         `{"id":"evt_1","object":"event","type":"checkout.session.completed","data":{"object":{"id":"cs_1","object":"checkout.session","mode":"payment","payment_status":"paid","client_reference_id":"order_01","metadata":{"order_id":"order_01"}}}}`,
+			expectedType:    EVENT_ORDER_PAID,
+			expectedOrderID: "order_01",
+		},
+		{
+			name:            "checkout session paid without metadata",
+			payload:         `{"id":"evt_2","object":"event","type":"checkout.session.completed","data":{"object":{"id":"cs_2","object":"checkout.session","mode":"payment","payment_status":"paid","client_reference_id":"order_02"}}}`,
+			expectedType:    EVENT_ORDER_PAID,
+			expectedOrderID: "order_02",
+		},
+		{
+			name:         "checkout session unpaid",
+			payload:      `{"id":"evt_3","object":"event","type":"checkout.session.completed","data":{"object":{"id":"cs_3","object":"checkout.session","mode":"payment","payment_status":"unpaid","client_reference_id":"order_03"}}}`,
+			expectedType: EVENT_IGNORED,
+		},
+		{
+			name:                   "subscription checkout paid",
+			payload:                `{"id":"evt_4","object":"event","type":"checkout.session.completed","data":{"object":{"id":"cs_4","object":"checkout.session","mode":"subscription","payment_status":"paid","metadata":{"subscription_id":"subscription_01"}}}}`,
+			expectedType:           EVENT_SUBSCRIPTION_PAID,
+			expectedSubscriptionID: "subscription_01",
+		},
+		{
+			name:                   "invoice paid",
+			payload:                `{"id":"evt_5","object":"event","type":"invoice.paid","data":{"object":{"id":"in_1","object":"invoice","subscription":"sub_1","lines":{"object":"list","data":[{"id":"il_1","metadata":{"subscription_id":"subscription_01"}}]}}}}`,
+			expectedType:           EVENT_SUBSCRIPTION_PAID,
+			expectedSubscriptionID: "subscription_01",
+		},
+		{
+			name:                   "subscription deleted",
+			payload:                `{"id":"evt_6","object":"event","type":"customer.subscription.deleted","data":{"object":{"id":"sub_1","object":"subscription","status":"canceled","metadata":{"subscription_id":"subscription_01"}}}}`,
+			expectedType:           EVENT_SUBSCRIPTION_CANCELLED,
+			expectedSubscriptionID: "subscription_01",
+		},
+		{
+			name:         "unhandled event",
+			payload:      `{"id":"evt_7","object":"event","type":"customer.created","data":{"object":{"id":"cus_1","object":"customer"}}}`,
+			expectedType: EVENT_IGNORED,
+		},
+	}
+
+	gateway := NewStripeGateway("sk_test_123", testStripeWebhookSecret)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event, err := gateway.WebhookParse([]byte(test.payload), signStripePayload(test.payload, testStripeWebhookSecret))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if event.ID == "" {
+				t.Error("expected the event ID to be set")
+			}
+
+			if event.Type != test.expectedType {
+				t.Errorf("expected type %q, got %q", test.expectedType, event.Type)
+			}
+
+			if event.OrderID != test.expectedOrderID {
+				t.Errorf("expected order ID %q, got %q", test.expectedOrderID, event.OrderID)
+			}
+
+			if event.SubscriptionID != test.expectedSubscriptionID {
+				t.Errorf("expected subscription ID %q, got %q", test.expectedSubscriptionID, event.SubscriptionID)
+			}
+		})
+	}
+}
+
+func TestStripeGateway_WebhookParseInvalidSignature(t *testing.T) {
+	payload := `{"id":"evt_1","object":"event","type":"checkout.session.completed","data":{"object":{}}}`
+
+	gateway := NewStripeGateway("sk_test_123", testStripeWebhookSecret)
+
+	if _, err := gateway.WebhookParse([]byte(payload), signStripePayload(payload, "whsec_wrong")); err == nil {
+		t.Error("expected a wrong signature to be rejected")
+	}
+
+	if _, err := gateway.WebhookParse([]byte(payload), http.Header{}); err == nil {
+		t.Error("expected a missing signature to be rejected")
+	}
+
+	if _, err := NewStripeGateway("sk_test_123", "").WebhookParse([]byte(payload), signStripePayload(payload, "")); err == nil {
+		t.Error("expected an error without signing secret")
+	}
+}
+
+func TestStripeGateway_CheckoutCreateValidation(t *testing.T) {
+	gateway := NewStripeGateway("sk_test_123", testStripeWebhookSecret)
+
+	if _, err := gateway.CheckoutCreate(context.Background(), CheckoutOptions{LineItems: []LineItem{{Name: "Product", Quantity: 1, UnitAmount: 100}}}); err == nil {
+		t.Error("expected an error without currency")
+	}
+
+	if _, err := gateway.CheckoutCreate(context.Background(), CheckoutOptions{Currency: "GBP"}); err == nil {
+		t.Error("expected an error without line items")
+	}
+
+	if _, err := gateway.CheckoutVerify(context.Background(), ""); err == nil {
+		t.Error("expected an error without session ID")
+	}
+
+	if _, err := gateway.Refund(context.Background(), RefundOptions{}); err == nil {
+		t.Error("expected an error without session ID")
+	}
+
+	if _, err := gateway.CheckoutCreate(context.Background(), CheckoutOptions{
+		Currency:       "GBP",
+		LineItems:      []LineItem{{Name: "Plan", Quantity: 1, UnitAmount: 999}},
+		SubscriptionID: "subscription_01",
+	}); err == nil {
+		t.Error("expected an error without subscription interval")
+	}
+
+	if err := gateway.SubscriptionCancel(context.Background(), ""); err == nil {
+		t.Error("expected an error without subscription ID")
+	}
+}
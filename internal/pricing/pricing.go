@@ -0,0 +1,185 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+)
+
+// Types of the lines added to a quote by the calculators
+const (
+	LINE_TYPE_DISCOUNT = "discount"
+	LINE_TYPE_SHIPPING = "shipping"
+	LINE_TYPE_TAX      = "tax"
+)
+
+// CalculatorInterface is implemented by the steps of the pricing pipeline.
+//
+// A calculator reads the quote (items, address, lines added by the previous
+// calculators) and adds its own lines to it. All the amounts are in minor
+// units of the quote currency, see payment.ToMinorUnits.
+type CalculatorInterface interface {
+	// Name identifies the calculator in errors and logs
+	Name() string
+
+	// Calculate adds the lines of the calculator to the quote
+	Calculate(ctx context.Context, quote *Quote) error
+}
+
+// Address is the shipping address of the buyer, as far as pricing is concerned
+type Address struct {
+	// Country ISO 3166-1 alpha-2 code, e.g. GB
+	Country string
+	// Region code within the country, e.g. CA for California. Optional
+	Region string
+}
+
+// Item is a basket item to price
+type Item struct {
+	ProductID string
+	Name      string
+	Quantity  int
+	// UnitAmount the unit price in minor units
+	UnitAmount int64
+	// Weight the weight of one unit in grams, zero when unknown
+	Weight int64
+}
+
+// Total returns the item total (unit price times quantity) in minor units
+func (item Item) Total() int64 {
+	return item.UnitAmount * int64(item.Quantity)
+}
+
+// Line is a line of the price breakdown, e.g. the shipping or the tax
+type Line struct {
+	Type  string `json:"type"`
+	Code  string `json:"code"`
+	Label string `json:"label"`
+	// Amount in minor units, negative for deductions such as discounts
+	Amount int64 `json:"amount"`
+	// Included lines are already part of the item prices (e.g. the VAT of
+	// tax inclusive prices). They are shown, but not added to the total
+	Included bool `json:"included"`
+}
+
+// ShippingOption is a shipping method available for the quote
+type ShippingOption struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Amount int64  `json:"amount"`
+}
+
+// Quote is the basket being priced
+type Quote struct {
+	// Currency ISO 4217 code, e.g. GBP
+	Currency string
+	Address  Address
+	Items    []Item
+
+	// ShippingMethod is the code of the shipping method chosen by the buyer.
+	// The shipping calculator replaces it with the method actually used
+	ShippingMethod string
+
+	// ShippingOptions are the shipping methods available for the quote,
+	// set by the shipping calculator
+	ShippingOptions []ShippingOption
+
+	// Lines are the lines of the price breakdown, in the order added
+	Lines []Line
+}
+
+// NewQuote creates a quote for the items
+func NewQuote(currency string, address Address, items []Item) *Quote {
+	return &Quote{
+		Currency: currency,
+		Address:  address,
+		Items:    items,
+		Lines:    []Line{},
+	}
+}
+
+// AddLine adds a line to the price breakdown
+func (quote *Quote) AddLine(line Line) {
+	quote.Lines = append(quote.Lines, line)
+}
+
+// Subtotal returns the sum of the item totals
+func (quote *Quote) Subtotal() int64 {
+	subtotal := int64(0)
+	for _, item := range quote.Items {
+		subtotal += item.Total()
+	}
+	return subtotal
+}
+
+// Weight returns the weight of the basket in grams
+func (quote *Quote) Weight() int64 {
+	weight := int64(0)
+	for _, item := range quote.Items {
+		weight += item.Weight * int64(item.Quantity)
+	}
+	return weight
+}
+
+// Amount returns the sum of the lines of the given type
+func (quote *Quote) Amount(lineType string) int64 {
+	amount := int64(0)
+	for _, line := range quote.Lines {
+		if line.Type == lineType {
+			amount += line.Amount
+		}
+	}
+	return amount
+}
+
+// Total returns the amount to pay: the subtotal plus the lines
+// not already included in the item prices. It is never negative
+func (quote *Quote) Total() int64 {
+	total := quote.Subtotal()
+	for _, line := range quote.Lines {
+		if !line.Included {
+			total += line.Amount
+		}
+	}
+	return max(0, total)
+}
+
+// Pipeline runs calculators one after the other. It is a calculator
+// itself, so pipelines can be nested and replaced as a whole
+type Pipeline struct {
+	calculators []CalculatorInterface
+}
+
+var _ CalculatorInterface = (*Pipeline)(nil)
+
+// NewPipeline creates a pipeline running the calculators in the given order.
+// Calculators depending on other lines (e.g. tax on shipping) must be last
+func NewPipeline(calculators ...CalculatorInterface) *Pipeline {
+	return &Pipeline{calculators: calculators}
+}
+
+// Add appends a calculator to the pipeline
+func (pipeline *Pipeline) Add(calculator CalculatorInterface) *Pipeline {
+	pipeline.calculators = append(pipeline.calculators, calculator)
+	return pipeline
+}
+
+// Calculators returns the calculators of the pipeline, in order
+func (pipeline *Pipeline) Calculators() []CalculatorInterface {
+	return pipeline.calculators
+}
+
+// Name returns the name of the pipeline
+func (pipeline *Pipeline) Name() string {
+	return "pipeline"
+}
+
+// Calculate runs the calculators, stopping at the first error
+func (pipeline *Pipeline) Calculate(ctx context.Context, quote *Quote) error {
+	for _, calculator := range pipeline.calculators {
+		if err := calculator.Calculate(ctx, quote); err != nil {
+			return fmt.Errorf("%s: %w", calculator.Name(), err)
+		}
+	}
+
+	return nil
+}
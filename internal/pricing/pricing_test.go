@@ -0,0 +1,130 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type calculatorFunc struct {
+	name string
+	fn   func(quote *Quote) error
+}
+
+func (c calculatorFunc) Name() string { return c.name }
+
+func (c calculatorFunc) Calculate(_ context.Context, quote *Quote) error { return c.fn(quote) }
+
+func testItems() []Item {
+	return []Item{
+		{ProductID: "p1", Name: "Product 1", Quantity: 2, UnitAmount: 1250, Weight: 500},
+		{ProductID: "p2", Name: "Product 2", Quantity: 1, UnitAmount: 1000, Weight: 0},
+	}
+}
+
+func TestQuote_Amounts(t *testing.T) {
+	quote := NewQuote("GBP", Address{Country: "GB"}, testItems())
+
+	if quote.Subtotal() != 3500 {
+		t.Errorf("Subtotal() = %d, want 3500", quote.Subtotal())
+	}
+
+	if quote.Weight() != 1000 {
+		t.Errorf("Weight() = %d, want 1000", quote.Weight())
+	}
+
+	quote.AddLine(Line{Type: LINE_TYPE_DISCOUNT, Amount: -500})
+	quote.AddLine(Line{Type: LINE_TYPE_SHIPPING, Amount: 399})
+	quote.AddLine(Line{Type: LINE_TYPE_TAX, Amount: 600, Included: true})
+
+	if quote.Amount(LINE_TYPE_DISCOUNT) != -500 {
+		t.Errorf("Amount(discount) = %d, want -500", quote.Amount(LINE_TYPE_DISCOUNT))
+	}
+
+	// Included lines are not added to the total
+	if quote.Total() != 3399 {
+		t.Errorf("Total() = %d, want 3399", quote.Total())
+	}
+}
+
+func TestQuote_TotalNeverNegative(t *testing.T) {
+	quote := NewQuote("GBP", Address{}, testItems())
+	quote.AddLine(Line{Type: LINE_TYPE_DISCOUNT, Amount: -10000})
+
+	if quote.Total() != 0 {
+		t.Errorf("Total() = %d, want 0", quote.Total())
+	}
+}
+
+func TestPipeline_RunsInOrder(t *testing.T) {
+	order := []string{}
+
+	pipeline := NewPipeline(
+		calculatorFunc{"first", func(quote *Quote) error { order = append(order, "first"); return nil }},
+	).Add(calculatorFunc{"second", func(quote *Quote) error { order = append(order, "second"); return nil }})
+
+	if err := pipeline.Calculate(context.Background(), NewQuote("GBP", Address{}, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(order, ",") != "first,second" {
+		t.Errorf("calculators ran in order %v", order)
+	}
+
+	if len(pipeline.Calculators()) != 2 {
+		t.Errorf("Calculators() returned %d calculators, want 2", len(pipeline.Calculators()))
+	}
+}
+
+func TestPipeline_StopsOnError(t *testing.T) {
+	errFailed := errors.New("failed")
+	ran := false
+
+	pipeline := NewPipeline(
+		calculatorFunc{"failing", func(quote *Quote) error { return errFailed }},
+		calculatorFunc{"next", func(quote *Quote) error { ran = true; return nil }},
+	)
+
+	err := pipeline.Calculate(context.Background(), NewQuote("GBP", Address{}, nil))
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("Calculate() error = %v, want %v", err, errFailed)
+	}
+
+	if !strings.HasPrefix(err.Error(), "failing:") {
+		t.Errorf("expected the error to name the calculator, got %q", err.Error())
+	}
+
+	if ran {
+		t.Error("calculators after a failing one must not run")
+	}
+}
+
+func TestPipeline_ShippingThenTax(t *testing.T) {
+	methods, err := ParseShippingMethods(`[{"code":"standard","name":"Standard","tiers":[{"price":"5.00"}]}]`, "GBP")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rates, err := ParseTaxRates(`[{"country":"GB","rate":"20","label":"VAT"}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quote := NewQuote("GBP", Address{Country: "GB"}, testItems())
+	quote.AddLine(Line{Type: LINE_TYPE_DISCOUNT, Code: "SAVE", Amount: -500})
+
+	pipeline := NewPipeline(NewShippingCalculator(methods), NewTaxCalculator(rates, false))
+	if err := pipeline.Calculate(context.Background(), quote); err != nil {
+		t.Fatal(err)
+	}
+
+	// (35.00 - 5.00 + 5.00) * 20% = 7.00
+	if quote.Amount(LINE_TYPE_TAX) != 700 {
+		t.Errorf("tax = %d, want 700", quote.Amount(LINE_TYPE_TAX))
+	}
+
+	if quote.Total() != 4200 {
+		t.Errorf("Total() = %d, want 4200", quote.Total())
+	}
+}
@@ -0,0 +1,209 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"project/internal/payment"
+	"slices"
+	"strings"
+)
+
+// ErrShippingUnavailable is returned when none of the shipping methods
+// can deliver the basket to the buyer's address
+var ErrShippingUnavailable = errors.New("we cannot ship this order to your address")
+
+// ShippingMethod is a way of delivering the order, priced in tiers
+type ShippingMethod struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// Countries the method ships to (ISO 3166-1 alpha-2), empty for all
+	Countries []string `json:"countries"`
+	// Tiers are checked in order, the first matching tier sets the price.
+	// The method is unavailable when no tier matches
+	Tiers []ShippingTier `json:"tiers"`
+}
+
+// ShippingTier is a price of a shipping method, for baskets up to a weight
+// and / or from a value
+type ShippingTier struct {
+	// MaxWeight in grams, zero for no limit
+	MaxWeight int64 `json:"max_weight"`
+	// MinSubtotal the minimum discounted basket value (e.g. "50.00" for free
+	// shipping over 50), empty for no minimum
+	MinSubtotal string `json:"min_subtotal"`
+	// Price of the shipping, e.g. "4.99"
+	Price string `json:"price"`
+}
+
+// Validate checks the shipping method is well formed
+func (method ShippingMethod) Validate(currency string) error {
+	if method.Code == "" || method.Name == "" {
+		return errors.New("shipping method code and name are required")
+	}
+
+	if len(method.Tiers) == 0 {
+		return fmt.Errorf("shipping method %s must have at least one tier", method.Code)
+	}
+
+	for _, country := range method.Countries {
+		if len(country) != 2 {
+			return fmt.Errorf("shipping method %s: country must be a 2 letter ISO code, got %q", method.Code, country)
+		}
+	}
+
+	for _, tier := range method.Tiers {
+		if tier.MaxWeight < 0 {
+			return fmt.Errorf("shipping method %s: max weight cannot be negative", method.Code)
+		}
+
+		if price, err := payment.ToMinorUnits(tier.Price, currency); err != nil || price < 0 {
+			return fmt.Errorf("shipping method %s: invalid price %q", method.Code, tier.Price)
+		}
+
+		if tier.MinSubtotal != "" {
+			if minimum, err := payment.ToMinorUnits(tier.MinSubtotal, currency); err != nil || minimum < 0 {
+				return fmt.Errorf("shipping method %s: invalid minimum subtotal %q", method.Code, tier.MinSubtotal)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ParseShippingMethods parses the shipping methods from their JSON
+// configuration, e.g.
+// [{"code":"standard","name":"Standard","countries":["GB"],"tiers":[{"min_subtotal":"50.00","price":"0"},{"max_weight":2000,"price":"3.99"},{"price":"7.99"}]}]
+func ParseShippingMethods(value string, currency string) ([]ShippingMethod, error) {
+	if strings.TrimSpace(value) == "" {
+		return []ShippingMethod{}, nil
+	}
+
+	methods := []ShippingMethod{}
+	if err := json.Unmarshal([]byte(value), &methods); err != nil {
+		return nil, fmt.Errorf("invalid shipping methods: %w", err)
+	}
+
+	codes := map[string]bool{}
+
+	for i := range methods {
+		for j := range methods[i].Countries {
+			methods[i].Countries[j] = strings.ToUpper(strings.TrimSpace(methods[i].Countries[j]))
+		}
+
+		if err := methods[i].Validate(currency); err != nil {
+			return nil, err
+		}
+
+		if codes[methods[i].Code] {
+			return nil, fmt.Errorf("shipping method %s is defined twice", methods[i].Code)
+		}
+
+		codes[methods[i].Code] = true
+	}
+
+	return methods, nil
+}
+
+// shippingCalculator adds the shipping line for the method chosen by
+// the buyer, or the first available method
+type shippingCalculator struct {
+	methods []ShippingMethod
+}
+
+var _ CalculatorInterface = (*shippingCalculator)(nil)
+
+// NewShippingCalculator creates the shipping calculator. With no methods
+// configured, shipping is free and the calculator does nothing
+func NewShippingCalculator(methods []ShippingMethod) CalculatorInterface {
+	return &shippingCalculator{methods: methods}
+}
+
+func (calculator *shippingCalculator) Name() string {
+	return "shipping"
+}
+
+func (calculator *shippingCalculator) Calculate(_ context.Context, quote *Quote) error {
+	quote.ShippingOptions = []ShippingOption{}
+
+	if len(calculator.methods) == 0 || len(quote.Items) == 0 {
+		quote.ShippingMethod = ""
+		return nil
+	}
+
+	goods := quote.Subtotal() + quote.Amount(LINE_TYPE_DISCOUNT)
+	weight := quote.Weight()
+	country := strings.ToUpper(quote.Address.Country)
+
+	for _, method := range calculator.methods {
+		if len(method.Countries) > 0 && !slices.Contains(method.Countries, country) {
+			continue
+		}
+
+		amount, ok, err := calculator.tierPrice(method, goods, weight, quote.Currency)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			quote.ShippingOptions = append(quote.ShippingOptions, ShippingOption{
+				Code:   method.Code,
+				Name:   method.Name,
+				Amount: amount,
+			})
+		}
+	}
+
+	if len(quote.ShippingOptions) == 0 {
+		quote.ShippingMethod = ""
+		return ErrShippingUnavailable
+	}
+
+	selected := quote.ShippingOptions[0]
+	for _, option := range quote.ShippingOptions {
+		if option.Code == quote.ShippingMethod {
+			selected = option
+			break
+		}
+	}
+
+	quote.ShippingMethod = selected.Code
+	quote.AddLine(Line{
+		Type:   LINE_TYPE_SHIPPING,
+		Code:   selected.Code,
+		Label:  selected.Name,
+		Amount: selected.Amount,
+	})
+
+	return nil
+}
+
+// tierPrice returns the price of the first tier matching the basket
+func (calculator *shippingCalculator) tierPrice(method ShippingMethod, goods int64, weight int64, currency string) (int64, bool, error) {
+	for _, tier := range method.Tiers {
+		if tier.MaxWeight > 0 && weight > tier.MaxWeight {
+			continue
+		}
+
+		if tier.MinSubtotal != "" {
+			minimum, err := payment.ToMinorUnits(tier.MinSubtotal, currency)
+			if err != nil {
+				return 0, false, err
+			}
+
+			if goods < minimum {
+				continue
+			}
+		}
+
+		price, err := payment.ToMinorUnits(tier.Price, currency)
+		if err != nil {
+			return 0, false, err
+		}
+
+		return price, true, nil
+	}
+
+	return 0, false, nil
+}
@@ -0,0 +1,135 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const testShippingMethods = `[
+	{"code":"standard","name":"Standard","countries":["gb"],"tiers":[
+		{"min_subtotal":"50.00","price":"0"},
+		{"max_weight":2000,"price":"3.99"},
+		{"price":"7.99"}
+	]},
+	{"code":"express","name":"Express","tiers":[{"max_weight":5000,"price":"12.00"}]}
+]`
+
+func testShippingCalculator(t *testing.T) CalculatorInterface {
+	t.Helper()
+
+	methods, err := ParseShippingMethods(testShippingMethods, "GBP")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewShippingCalculator(methods)
+}
+
+func TestParseShippingMethods_Invalid(t *testing.T) {
+	for _, invalid := range []string{
+		`not json`,
+		`[{"code":"","name":"Standard","tiers":[{"price":"1"}]}]`,
+		`[{"code":"standard","name":"Standard","tiers":[]}]`,
+		`[{"code":"standard","name":"Standard","tiers":[{"price":"-1"}]}]`,
+		`[{"code":"standard","name":"Standard","tiers":[{"price":"free"}]}]`,
+		`[{"code":"standard","name":"Standard","tiers":[{"max_weight":-1,"price":"1"}]}]`,
+		`[{"code":"standard","name":"Standard","countries":["GBR"],"tiers":[{"price":"1"}]}]`,
+		`[{"code":"a","name":"A","tiers":[{"price":"1"}]},{"code":"a","name":"B","tiers":[{"price":"1"}]}]`,
+	} {
+		if _, err := ParseShippingMethods(invalid, "GBP"); err == nil {
+			t.Errorf("ParseShippingMethods(%s) expected error", invalid)
+		}
+	}
+}
+
+func TestShippingCalculator_Tiers(t *testing.T) {
+	tests := []struct {
+		name     string
+		items    []Item
+		discount int64
+		want     int64
+	}{
+		{"light basket", []Item{{Quantity: 1, UnitAmount: 1000, Weight: 1500}}, 0, 399},
+		{"heavy basket", []Item{{Quantity: 2, UnitAmount: 1000, Weight: 1500}}, 0, 799},
+		{"free over 50", []Item{{Quantity: 1, UnitAmount: 6000, Weight: 1500}}, 0, 0},
+		{"discount below free threshold", []Item{{Quantity: 1, UnitAmount: 6000, Weight: 1500}}, -2000, 399},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := NewQuote("GBP", Address{Country: "GB"}, tt.items)
+			if tt.discount != 0 {
+				quote.AddLine(Line{Type: LINE_TYPE_DISCOUNT, Amount: tt.discount})
+			}
+
+			if err := testShippingCalculator(t).Calculate(context.Background(), quote); err != nil {
+				t.Fatal(err)
+			}
+
+			if quote.ShippingMethod != "standard" {
+				t.Errorf("ShippingMethod = %q, want standard", quote.ShippingMethod)
+			}
+
+			if quote.Amount(LINE_TYPE_SHIPPING) != tt.want {
+				t.Errorf("shipping = %d, want %d", quote.Amount(LINE_TYPE_SHIPPING), tt.want)
+			}
+		})
+	}
+}
+
+func TestShippingCalculator_SelectsChosenMethod(t *testing.T) {
+	quote := NewQuote("GBP", Address{Country: "GB"}, []Item{{Quantity: 1, UnitAmount: 1000, Weight: 100}})
+	quote.ShippingMethod = "express"
+
+	if err := testShippingCalculator(t).Calculate(context.Background(), quote); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(quote.ShippingOptions) != 2 {
+		t.Fatalf("expected 2 shipping options, got %+v", quote.ShippingOptions)
+	}
+
+	if quote.ShippingMethod != "express" || quote.Amount(LINE_TYPE_SHIPPING) != 1200 {
+		t.Errorf("expected express shipping at 12.00, got %q %d", quote.ShippingMethod, quote.Amount(LINE_TYPE_SHIPPING))
+	}
+}
+
+func TestShippingCalculator_CountryRestriction(t *testing.T) {
+	quote := NewQuote("GBP", Address{Country: "FR"}, []Item{{Quantity: 1, UnitAmount: 1000, Weight: 100}})
+	quote.ShippingMethod = "standard"
+
+	if err := testShippingCalculator(t).Calculate(context.Background(), quote); err != nil {
+		t.Fatal(err)
+	}
+
+	// Standard does not ship to France, so express is used instead
+	if quote.ShippingMethod != "express" {
+		t.Errorf("ShippingMethod = %q, want express", quote.ShippingMethod)
+	}
+}
+
+func TestShippingCalculator_Unavailable(t *testing.T) {
+	quote := NewQuote("GBP", Address{Country: "FR"}, []Item{{Quantity: 1, UnitAmount: 1000, Weight: 6000}})
+
+	err := testShippingCalculator(t).Calculate(context.Background(), quote)
+	if !errors.Is(err, ErrShippingUnavailable) {
+		t.Fatalf("Calculate() error = %v, want ErrShippingUnavailable", err)
+	}
+
+	if len(quote.Lines) != 0 || quote.ShippingMethod != "" {
+		t.Errorf("expected no shipping line, got %+v", quote.Lines)
+	}
+}
+
+func TestShippingCalculator_NoMethods(t *testing.T) {
+	quote := NewQuote("GBP", Address{}, []Item{{Quantity: 1, UnitAmount: 1000}})
+
+	if err := NewShippingCalculator(nil).Calculate(context.Background(), quote); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(quote.Lines) != 0 {
+		t.Errorf("expected no shipping line, got %+v", quote.Lines)
+	}
+}
@@ -0,0 +1,182 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var percentRegex = regexp.MustCompile(`^\d{1,3}(\.\d{1,2})?$`)
+
+// TaxRate is the tax rate of a country, or of a region within a country
+type TaxRate struct {
+	// Country ISO 3166-1 alpha-2 code, e.g. GB
+	Country string `json:"country"`
+	// Region code within the country, e.g. CA. Empty for the whole country
+	Region string `json:"region"`
+	// Rate percentage with up to two decimals, e.g. "20" or "7.25"
+	Rate string `json:"rate"`
+	// Label shown to the buyer, e.g. VAT. Defaults to "Tax"
+	Label string `json:"label"`
+}
+
+// Validate checks the tax rate is well formed
+func (rate TaxRate) Validate() error {
+	if len(rate.Country) != 2 {
+		return fmt.Errorf("tax rate country must be a 2 letter ISO code, got %q", rate.Country)
+	}
+
+	if _, err := percentToBasisPoints(rate.Rate); err != nil {
+		return fmt.Errorf("tax rate for %s: %w", rate.Country, err)
+	}
+
+	return nil
+}
+
+// ParseTaxRates parses the tax rates from their JSON configuration, e.g.
+// [{"country":"GB","rate":"20","label":"VAT"},{"country":"US","region":"CA","rate":"7.25"}]
+func ParseTaxRates(value string) ([]TaxRate, error) {
+	if strings.TrimSpace(value) == "" {
+		return []TaxRate{}, nil
+	}
+
+	rates := []TaxRate{}
+	if err := json.Unmarshal([]byte(value), &rates); err != nil {
+		return nil, fmt.Errorf("invalid tax rates: %w", err)
+	}
+
+	for i := range rates {
+		rates[i].Country = strings.ToUpper(strings.TrimSpace(rates[i].Country))
+		rates[i].Region = strings.ToUpper(strings.TrimSpace(rates[i].Region))
+
+		if err := rates[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return rates, nil
+}
+
+// taxCalculator adds the tax line for the country and region of the buyer
+type taxCalculator struct {
+	rates            []TaxRate
+	pricesIncludeTax bool
+}
+
+var _ CalculatorInterface = (*taxCalculator)(nil)
+
+// NewTaxCalculator creates the tax calculator.
+//
+// The tax is charged on the discounted subtotal plus the shipping, so the
+// calculator must run after the discount and shipping lines are added.
+// When the prices include tax the tax line is informational only.
+func NewTaxCalculator(rates []TaxRate, pricesIncludeTax bool) CalculatorInterface {
+	return &taxCalculator{rates: rates, pricesIncludeTax: pricesIncludeTax}
+}
+
+func (calculator *taxCalculator) Name() string {
+	return "tax"
+}
+
+func (calculator *taxCalculator) Calculate(_ context.Context, quote *Quote) error {
+	rate := calculator.rateFind(quote.Address)
+
+	if rate == nil {
+		return nil
+	}
+
+	basisPoints, err := percentToBasisPoints(rate.Rate)
+	if err != nil {
+		return err
+	}
+
+	taxable := quote.Subtotal() + quote.Amount(LINE_TYPE_DISCOUNT) + quote.Amount(LINE_TYPE_SHIPPING)
+
+	if taxable <= 0 || basisPoints == 0 {
+		return nil
+	}
+
+	var amount int64
+	if calculator.pricesIncludeTax {
+		// The tax part of a tax inclusive amount, rounded half up
+		divisor := 10000 + basisPoints
+		amount = (taxable*basisPoints*2 + divisor) / (2 * divisor)
+	} else {
+		amount = (taxable*basisPoints + 5000) / 10000
+	}
+
+	label := rate.Label
+	if label == "" {
+		label = "Tax"
+	}
+
+	code := rate.Country
+	if rate.Region != "" {
+		code += "-" + rate.Region
+	}
+
+	quote.AddLine(Line{
+		Type:     LINE_TYPE_TAX,
+		Code:     code,
+		Label:    label + " (" + rate.Rate + "%)",
+		Amount:   amount,
+		Included: calculator.pricesIncludeTax,
+	})
+
+	return nil
+}
+
+// rateFind returns the rate of the region, falling back to the rate
+// of the whole country
+func (calculator *taxCalculator) rateFind(address Address) *TaxRate {
+	country := strings.ToUpper(address.Country)
+	region := strings.ToUpper(address.Region)
+
+	if country == "" {
+		return nil
+	}
+
+	var countryRate *TaxRate
+
+	for i, rate := range calculator.rates {
+		if rate.Country != country {
+			continue
+		}
+
+		if rate.Region != "" && rate.Region == region {
+			return &calculator.rates[i]
+		}
+
+		if rate.Region == "" && countryRate == nil {
+			countryRate = &calculator.rates[i]
+		}
+	}
+
+	return countryRate
+}
+
+// percentToBasisPoints converts a percentage with up to two decimals
+// (e.g. "7.25") to basis points (725)
+func percentToBasisPoints(percent string) (int64, error) {
+	if !percentRegex.MatchString(percent) {
+		return 0, fmt.Errorf("rate must be a percentage with up to 2 decimals, got %q", percent)
+	}
+
+	whole, fraction, _ := strings.Cut(percent, ".")
+	fraction = (fraction + "00")[:2]
+
+	basisPoints, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if basisPoints > 10000 {
+		return 0, errors.New("rate cannot be more than 100%")
+	}
+
+	return basisPoints, nil
+}
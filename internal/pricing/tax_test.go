@@ -0,0 +1,108 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseTaxRates(t *testing.T) {
+	rates, err := ParseTaxRates(`[{"country":"gb","rate":"20","label":"VAT"},{"country":"US","region":"ca","rate":"7.25"}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rates) != 2 || rates[0].Country != "GB" || rates[1].Region != "CA" {
+		t.Errorf("unexpected rates %+v", rates)
+	}
+
+	empty, err := ParseTaxRates("")
+	if err != nil || len(empty) != 0 {
+		t.Errorf("ParseTaxRates(\"\") = %v, %v", empty, err)
+	}
+
+	for _, invalid := range []string{
+		`not json`,
+		`[{"country":"GBR","rate":"20"}]`,
+		`[{"country":"GB","rate":"twenty"}]`,
+		`[{"country":"GB","rate":"20.125"}]`,
+		`[{"country":"GB","rate":"101"}]`,
+		`[{"country":"GB","rate":"-5"}]`,
+	} {
+		if _, err := ParseTaxRates(invalid); err == nil {
+			t.Errorf("ParseTaxRates(%s) expected error", invalid)
+		}
+	}
+}
+
+func TestTaxCalculator(t *testing.T) {
+	rates := []TaxRate{
+		{Country: "GB", Rate: "20", Label: "VAT"},
+		{Country: "US", Rate: "0"},
+		{Country: "US", Region: "CA", Rate: "7.25"},
+	}
+
+	tests := []struct {
+		name      string
+		address   Address
+		inclusive bool
+		want      int64
+		wantLine  bool
+		wantCode  string
+	}{
+		{"country rate", Address{Country: "GB"}, false, 700, true, "GB"},
+		{"lowercase country", Address{Country: "gb"}, false, 700, true, "GB"},
+		{"region rate", Address{Country: "US", Region: "CA"}, false, 254, true, "US-CA"},
+		{"region falls back to country", Address{Country: "US", Region: "NY"}, false, 0, false, ""},
+		{"no rate for country", Address{Country: "FR"}, false, 0, false, ""},
+		{"no country", Address{}, false, 0, false, ""},
+		{"tax inclusive prices", Address{Country: "GB"}, true, 583, true, "GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := NewQuote("GBP", tt.address, []Item{{Quantity: 1, UnitAmount: 3500}})
+
+			if err := NewTaxCalculator(rates, tt.inclusive).Calculate(context.Background(), quote); err != nil {
+				t.Fatal(err)
+			}
+
+			if (len(quote.Lines) == 1) != tt.wantLine {
+				t.Fatalf("expected a tax line: %v, got %+v", tt.wantLine, quote.Lines)
+			}
+
+			if !tt.wantLine {
+				return
+			}
+
+			line := quote.Lines[0]
+			if line.Amount != tt.want || line.Code != tt.wantCode || line.Included != tt.inclusive {
+				t.Errorf("unexpected tax line %+v", line)
+			}
+		})
+	}
+}
+
+func TestTaxCalculator_Label(t *testing.T) {
+	quote := NewQuote("GBP", Address{Country: "DE"}, []Item{{Quantity: 1, UnitAmount: 1000}})
+
+	if err := NewTaxCalculator([]TaxRate{{Country: "DE", Rate: "19"}}, false).Calculate(context.Background(), quote); err != nil {
+		t.Fatal(err)
+	}
+
+	if quote.Lines[0].Label != "Tax (19%)" {
+		t.Errorf("Label = %q, want %q", quote.Lines[0].Label, "Tax (19%)")
+	}
+}
+
+func TestTaxCalculator_FullyDiscounted(t *testing.T) {
+	quote := NewQuote("GBP", Address{Country: "GB"}, []Item{{Quantity: 1, UnitAmount: 1000}})
+	quote.AddLine(Line{Type: LINE_TYPE_DISCOUNT, Amount: -1000})
+
+	if err := NewTaxCalculator([]TaxRate{{Country: "GB", Rate: "20"}}, false).Calculate(context.Background(), quote); err != nil {
+		t.Fatal(err)
+	}
+
+	if quote.Amount(LINE_TYPE_TAX) != 0 {
+		t.Errorf("tax = %d, want 0", quote.Amount(LINE_TYPE_TAX))
+	}
+}
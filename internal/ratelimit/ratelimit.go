@@ -0,0 +1,165 @@
+// Package ratelimit limits the number of requests a client can make in a
+// window of time, per group of routes.
+//
+// The requests are counted in fixed windows (e.g. the current minute) in
+// a store, which is either the memory of the process, or the cache store
+// table, shared by all the instances of the application.
+package ratelimit
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The groups of routes with their own policies
+const (
+	// GROUP_GLOBAL applies to all the requests
+	GROUP_GLOBAL = "global"
+	// GROUP_API applies to the requests authenticated with an API token
+	GROUP_API = "api"
+	// GROUP_AUTH applies to the login, password reset and similar pages
+	GROUP_AUTH = "auth"
+	// GROUP_CONTACT applies to the submissions of the contact form
+	GROUP_CONTACT = "contact"
+	// GROUP_MCP applies to the requests authenticated with an MCP client
+	// key
+	GROUP_MCP = "mcp"
+	// GROUP_REGISTER applies to the registration page
+	GROUP_REGISTER = "register"
+	// GROUP_THUMBS applies to the thumbnails (/th), instead of the global
+	// policies, as a page can show many of them
+	GROUP_THUMBS = "thumbs"
+)
+
+// Groups returns the groups of routes, which can be configured
+func Groups() []string {
+	return []string{GROUP_GLOBAL, GROUP_API, GROUP_AUTH, GROUP_CONTACT, GROUP_MCP, GROUP_REGISTER, GROUP_THUMBS}
+}
+
+// Policy allows a number of requests in a window of time
+type Policy struct {
+	Limit  int
+	Window time.Duration
+}
+
+// String returns the policy in the format of the RateLimit-Policy header,
+// e.g. "120;w=60"
+func (policy Policy) String() string {
+	return strconv.Itoa(policy.Limit) + ";w=" + strconv.Itoa(int(policy.Window.Seconds()))
+}
+
+// ParsePolicies parses the policies from a comma separated list of
+// limit/window pairs, e.g. "20/1s,120/1m,12000/1h"
+func ParsePolicies(value string) ([]Policy, error) {
+	policies := []Policy{}
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+
+		if part == "" {
+			continue
+		}
+
+		limitText, windowText, found := strings.Cut(part, "/")
+		if !found {
+			return nil, errors.New("rate limit policy " + part + " must be in the limit/window format, e.g. 120/1m")
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(limitText))
+		if err != nil || limit < 1 {
+			return nil, errors.New("rate limit policy " + part + " must have a limit of at least 1")
+		}
+
+		window, err := time.ParseDuration(strings.TrimSpace(windowText))
+		if err != nil || window < time.Second {
+			return nil, errors.New("rate limit policy " + part + " must have a window of at least 1s")
+		}
+
+		policies = append(policies, Policy{Limit: limit, Window: window})
+	}
+
+	return policies, nil
+}
+
+// DefaultPolicies returns the policies of the group when none are
+// configured. The development policies are loose, not to get in the way.
+func DefaultPolicies(group string, isDevelopment bool) []Policy {
+	if isDevelopment {
+		switch group {
+		case GROUP_GLOBAL, GROUP_API, GROUP_MCP, GROUP_THUMBS:
+			return []Policy{{Limit: 1000, Window: time.Second}, {Limit: 10000, Window: time.Minute}, {Limit: 100000, Window: time.Hour}}
+		default:
+			return []Policy{{Limit: 100, Window: time.Minute}}
+		}
+	}
+
+	switch group {
+	case GROUP_GLOBAL:
+		return []Policy{{Limit: 20, Window: time.Second}, {Limit: 120, Window: time.Minute}, {Limit: 12000, Window: time.Hour}}
+	case GROUP_API:
+		return []Policy{{Limit: 60, Window: time.Minute}, {Limit: 5000, Window: time.Hour}}
+	case GROUP_AUTH, GROUP_CONTACT:
+		return []Policy{{Limit: 5, Window: time.Minute}}
+	case GROUP_MCP:
+		return []Policy{{Limit: 60, Window: time.Minute}, {Limit: 2000, Window: time.Hour}}
+	case GROUP_REGISTER:
+		return []Policy{{Limit: 10, Window: time.Minute}}
+	case GROUP_THUMBS:
+		return []Policy{{Limit: 100, Window: time.Second}, {Limit: 2000, Window: time.Minute}}
+	}
+
+	return []Policy{}
+}
+
+// Result is the outcome of a request, for the most restrictive policy
+type Result struct {
+	Allowed   bool
+	Policy    Policy
+	Remaining int
+	ResetAt   time.Time
+}
+
+// ResetSeconds returns the number of seconds until the window is reset,
+// at least 1
+func (result Result) ResetSeconds(now time.Time) int {
+	seconds := int(result.ResetAt.Sub(now).Round(time.Second).Seconds())
+
+	return max(seconds, 1)
+}
+
+// Check counts the request of the client (e.g. "user:123") in each policy
+// of the group, and returns whether it is allowed.
+//
+// The result is for the exceeded policy with the latest reset, or else
+// for the policy with the fewest remaining requests.
+func Check(store StoreInterface, group string, policies []Policy, client string, now time.Time) (Result, error) {
+	result := Result{Allowed: true, Remaining: -1}
+
+	for _, policy := range policies {
+		windowStart := now.Truncate(policy.Window)
+		key := group + ":" + client + ":" + strconv.Itoa(int(policy.Window.Seconds())) + ":" + strconv.FormatInt(windowStart.Unix(), 10)
+
+		count, err := store.Hit(key, policy.Window)
+		if err != nil {
+			return Result{Allowed: true}, err
+		}
+
+		current := Result{
+			Allowed:   count <= int64(policy.Limit),
+			Policy:    policy,
+			Remaining: max(policy.Limit-int(count), 0),
+			ResetAt:   windowStart.Add(policy.Window),
+		}
+
+		switch {
+		case !current.Allowed && (result.Allowed || current.ResetAt.After(result.ResetAt)):
+			result = current
+		case result.Allowed && (result.Remaining < 0 || current.Remaining < result.Remaining):
+			result = current
+		}
+	}
+
+	return result, nil
+}
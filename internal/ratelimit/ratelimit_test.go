@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies(" 20/1s, 120/1m ,12000/1h,")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Policy{{20, time.Second}, {120, time.Minute}, {12000, time.Hour}}
+
+	if len(policies) != len(expected) {
+		t.Fatalf("expected %d policies, got %d", len(expected), len(policies))
+	}
+
+	for i := range expected {
+		if policies[i] != expected[i] {
+			t.Errorf("policy %d = %+v, want %+v", i, policies[i], expected[i])
+		}
+	}
+
+	for _, invalid := range []string{"120", "0/1m", "abc/1m", "10/1ms", "10/forever"} {
+		if _, err := ParsePolicies(invalid); err == nil {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+}
+
+func TestPolicy_String(t *testing.T) {
+	if value := (Policy{Limit: 120, Window: time.Minute}).String(); value != "120;w=60" {
+		t.Errorf("String() = %q, want 120;w=60", value)
+	}
+}
+
+func TestDefaultPolicies(t *testing.T) {
+	for _, group := range Groups() {
+		if len(DefaultPolicies(group, false)) == 0 || len(DefaultPolicies(group, true)) == 0 {
+			t.Errorf("expected default policies for the %s group", group)
+		}
+	}
+}
+
+// countingStore counts the requests in a map, as the stores do
+type countingStore map[string]int64
+
+func (store countingStore) Hit(key string, window time.Duration) (int64, error) {
+	store[key]++
+	return store[key], nil
+}
+
+func TestCheck(t *testing.T) {
+	store := countingStore{}
+	policies := []Policy{{Limit: 3, Window: time.Second}, {Limit: 5, Window: time.Minute}}
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	for i := 1; i <= 3; i++ {
+		result, err := Check(store, GROUP_GLOBAL, policies, "ip:1", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !result.Allowed || result.Remaining != 3-i {
+			t.Fatalf("request %d: expected to be allowed with %d remaining, got %+v", i, 3-i, result)
+		}
+	}
+
+	result, _ := Check(store, GROUP_GLOBAL, policies, "ip:1", now)
+	if result.Allowed || result.Policy.Window != time.Second || result.ResetSeconds(now) != 1 {
+		t.Fatalf("expected the per second policy to be exceeded, got %+v", result)
+	}
+
+	// The other clients have their own counters
+	if result, _ := Check(store, GROUP_GLOBAL, policies, "ip:2", now); !result.Allowed {
+		t.Fatal("expected another client to be allowed")
+	}
+
+	// The next second, the per minute policy has 1 request left
+	result, _ = Check(store, GROUP_GLOBAL, policies, "ip:1", now.Add(time.Second))
+	if !result.Allowed || result.Remaining != 0 || result.Policy.Window != time.Minute {
+		t.Fatalf("expected the per minute policy to be the most restrictive, got %+v", result)
+	}
+
+	result, _ = Check(store, GROUP_GLOBAL, policies, "ip:1", now.Add(2*time.Second))
+	if result.Allowed || result.Policy.Window != time.Minute || result.ResetSeconds(now.Add(2*time.Second)) != 58 {
+		t.Fatalf("expected the per minute policy to be exceeded, got %+v", result)
+	}
+}
@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// STORE_MEMORY and STORE_CACHESTORE are the stores of the counters
+const (
+	STORE_MEMORY     = "memory"
+	STORE_CACHESTORE = "cachestore"
+)
+
+// cacheKeyPrefix prefixes the keys of the counters, in the caches shared
+// with the rest of the application
+const cacheKeyPrefix = "rate-limit:"
+
+// StoreInterface counts the requests
+type StoreInterface interface {
+	// Hit counts a request for the key, and returns the number of requests
+	// counted for it. The key expires after the window.
+	Hit(key string, window time.Duration) (int64, error)
+}
+
+// == MEMORY STORE =============================================================
+
+type memoryStore struct {
+	cache *ttlcache.Cache[string, any]
+}
+
+var _ StoreInterface = (*memoryStore)(nil)
+
+// NewMemoryStore creates a store counting the requests in the memory
+// cache of the application. The counters are lost on restart, and are
+// not shared with the other instances.
+func NewMemoryStore(cache *ttlcache.Cache[string, any]) (StoreInterface, error) {
+	if cache == nil {
+		return nil, errors.New("memory cache is required")
+	}
+
+	return &memoryStore{cache: cache}, nil
+}
+
+func (store *memoryStore) Hit(key string, window time.Duration) (int64, error) {
+	item, _ := store.cache.GetOrSet(cacheKeyPrefix+key, &atomic.Int64{}, ttlcache.WithTTL[string, any](window))
+
+	counter, ok := item.Value().(*atomic.Int64)
+	if !ok {
+		return 0, errors.New("rate limit counter " + key + " is not a counter")
+	}
+
+	return counter.Add(1), nil
+}
+
+// == CACHE STORE ==============================================================
+
+// CacheInterface is the part of the cache store (github.com/dracory/cachestore)
+// used to keep the counters
+type CacheInterface interface {
+	Get(key string, valueDefault string) (string, error)
+	Set(key string, value string, seconds int64) error
+}
+
+type cacheStore struct {
+	cache CacheInterface
+	mutex sync.Mutex
+}
+
+var _ StoreInterface = (*cacheStore)(nil)
+
+// NewCacheStore creates a store counting the requests in the cache store
+// table, so the instances of the application share the counters.
+//
+// The counter is read then written, so concurrent requests on different
+// instances may be counted once, which is an acceptable error for rate
+// limiting.
+func NewCacheStore(cache CacheInterface) (StoreInterface, error) {
+	if cache == nil {
+		return nil, errors.New("cache store is required")
+	}
+
+	return &cacheStore{cache: cache}, nil
+}
+
+func (store *cacheStore) Hit(key string, window time.Duration) (int64, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	value, err := store.cache.Get(cacheKeyPrefix+key, "0")
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		count = 0
+	}
+
+	count++
+
+	// Kept one second longer than the window, not to expire early
+	if err := store.cache.Set(cacheKeyPrefix+key, strconv.FormatInt(count, 10), int64(window.Seconds())+1); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
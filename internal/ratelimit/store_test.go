@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// fakeCache keeps the values as the cache store does
+type fakeCache struct {
+	values  map[string]string
+	seconds map[string]int64
+}
+
+func (cache *fakeCache) Get(key string, valueDefault string) (string, error) {
+	if value, ok := cache.values[key]; ok {
+		return value, nil
+	}
+
+	return valueDefault, nil
+}
+
+func (cache *fakeCache) Set(key string, value string, seconds int64) error {
+	cache.values[key] = value
+	cache.seconds[key] = seconds
+	return nil
+}
+
+func TestMemoryStore_Hit(t *testing.T) {
+	store, err := NewMemoryStore(ttlcache.New[string, any]())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := int64(1); i <= 3; i++ {
+		count, err := store.Hit("key", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if count != i {
+			t.Fatalf("count = %d, want %d", count, i)
+		}
+	}
+
+	if count, _ := store.Hit("other", time.Minute); count != 1 {
+		t.Fatalf("expected another key to have its own counter, got %d", count)
+	}
+
+	if _, err := NewMemoryStore(nil); err == nil {
+		t.Fatal("expected an error without the memory cache")
+	}
+}
+
+func TestCacheStore_Hit(t *testing.T) {
+	cache := &fakeCache{values: map[string]string{}, seconds: map[string]int64{}}
+
+	store, err := NewCacheStore(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := int64(1); i <= 3; i++ {
+		count, err := store.Hit("key", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if count != i {
+			t.Fatalf("count = %d, want %d", count, i)
+		}
+	}
+
+	if cache.values[cacheKeyPrefix+"key"] != strconv.Itoa(3) || cache.seconds[cacheKeyPrefix+"key"] != 61 {
+		t.Fatalf("expected the counter to be kept for the window, got %v %v", cache.values, cache.seconds)
+	}
+}
@@ -2,45 +2,21 @@ package routes
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/dracory/rtr"
 	rtrMiddleware "github.com/dracory/rtr/middlewares"
 
 	"project/internal/app"
+	"project/internal/links"
 	"project/internal/middlewares"
 	"project/internal/middlewares/httpsredirect"
+	"project/internal/ratelimit"
 )
 
-// Rate limit constants
-const (
-	defaultVisitsPerSec  = 20    // Default rate limit per second
-	defaultVisitsPerMin  = 120   // Default rate limit
-	defaultVisitsPerHour = 12000 // Default rate limit per hour
-
-	devVisitsPerSec  = 1000   // Development rate limit per second
-	devVisitsPerMin  = 10000  // Development rate limit per minute
-	devVisitsPerHour = 100000 // Development rate limit per hour
-)
-
-// getRateLimits returns appropriate rate limits based on environment
-func getRateLimits(app app.AppInterface) (perSec, perMin, perHour int) {
-	if app.GetConfig() != nil {
-		isDevelopment := app.GetConfig().IsEnvDevelopment()
-		isLocal := app.GetConfig().IsEnvLocal()
-
-		if isDevelopment || isLocal {
-			return devVisitsPerSec, devVisitsPerMin, devVisitsPerHour
-		}
-	}
-	return defaultVisitsPerSec, defaultVisitsPerMin, defaultVisitsPerHour
-}
-
 // globalMiddlewares returns a list of middlewares to be applied to all routes
 func globalMiddlewares(app app.AppInterface) []rtr.MiddlewareInterface {
-	// Get rate limits based on environment
-	perSec, perMin, perHour := getRateLimits(app)
-
 	globalMiddlewares := []rtr.MiddlewareInterface{
 		// Maintenance mode check first — blocks all processing if active
 		middlewares.NewMaintenanceMiddleware(app),
@@ -63,10 +39,7 @@ func globalMiddlewares(app app.AppInterface) []rtr.MiddlewareInterface {
 		rtrMiddleware.CleanPathMiddleware(),
 		rtrMiddleware.RedirectSlashesMiddleware(),
 		// router.NewNakedDomainToWwwMiddleware([]string{"localhost", "127.0.0.1", "http://sinevia.local"}),
-		rtrMiddleware.TimeoutMiddleware(30 * time.Second),     // 30s timeout
-		rtrMiddleware.RateLimitByIPMiddleware(perSec, 1),      // per second
-		rtrMiddleware.RateLimitByIPMiddleware(perMin, 1*60),   // per minute
-		rtrMiddleware.RateLimitByIPMiddleware(perHour, 60*60), // per hour
+		rtrMiddleware.TimeoutMiddleware(30 * time.Second), // 30s timeout
 	}
 
 	// Conditionally add logger and recovery when not running tests
@@ -91,7 +64,7 @@ func globalMiddlewares(app app.AppInterface) []rtr.MiddlewareInterface {
 			globalMiddlewares = append(globalMiddlewares,
 				httpsredirect.NewHTTPSRedirectMiddlewareWithConfig(httpsredirect.Config{
 					SkipFunc: func(r *http.Request) bool {
-						return r.URL.Path == "/api/internal/webhook"
+						return r.URL.Path == links.API_INTERNAL_WEBHOOK
 					},
 				}),
 			)
@@ -103,8 +76,33 @@ func globalMiddlewares(app app.AppInterface) []rtr.MiddlewareInterface {
 		middlewares.NewSecurityHeadersMiddleware(app),
 		middlewares.ThemeMiddleware(),
 		middlewares.AuthMiddleware(app),
+		middlewares.NewSessionActivityMiddleware(app),
+		// After the auth middleware, so the logged in users are limited by account
+		middlewares.NewRateLimitByRequestMiddleware(app, "Global Rate Limit Middleware", globalRateLimitGroup),
 		middlewares.NewStatsMiddleware(app),
 	)
 
 	return globalMiddlewares
 }
+
+// globalRateLimitGroup returns the rate limit group of the request. The
+// thumbnails are limited separately, as a page may request many at once,
+// and the API v1 and the MCP calls are limited per token or client by
+// their own routes, after the authentication. Their auth middlewares
+// count the requests without a valid token in the global group.
+func globalRateLimitGroup(r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, links.API_V1+"/") && r.URL.Path != links.API_V1_OPENAPI {
+		return ""
+	}
+
+	isMCPPath := r.URL.Path == links.MCP || strings.HasPrefix(r.URL.Path, links.MCP+"/")
+	if isMCPPath && r.Method == http.MethodPost {
+		return ""
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/th/") {
+		return ratelimit.GROUP_THUMBS
+	}
+
+	return ratelimit.GROUP_GLOBAL
+}
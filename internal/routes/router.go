@@ -3,6 +3,7 @@ package routes
 import (
 	"project/internal/app"
 	"project/internal/controllers/admin"
+	"project/internal/controllers/api"
 	"project/internal/controllers/auth"
 	"project/internal/controllers/liveflux"
 	"project/internal/controllers/shared"
@@ -18,6 +19,7 @@ func routes(app app.AppInterface) []rtr.RouteInterface {
 	routes := []rtr.RouteInterface{}
 
 	routes = append(routes, admin.Routes(app)...)
+	routes = append(routes, api.Routes(app)...)
 	routes = append(routes, auth.Routes(app)...)
 	routes = append(routes, liveflux.Routes(app)...)
 	routes = append(routes, shared.Routes(app)...)
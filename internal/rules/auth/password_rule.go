@@ -0,0 +1,60 @@
+package authrules
+
+import (
+	"github.com/dracory/rule"
+)
+
+// PASSWORD_MIN_LENGTH is the minimum length of a password
+const PASSWORD_MIN_LENGTH = 8
+
+// PASSWORD_MAX_LENGTH is the maximum length of a password, in bytes, as
+// bcrypt ignores anything after the first 72 bytes
+const PASSWORD_MAX_LENGTH = 72
+
+// PasswordRule validates a new password, chosen on signup or on reset.
+type PasswordRule struct {
+	rule.Rule
+}
+
+type passwordContext struct {
+	password        string
+	passwordConfirm string
+}
+
+// NewPasswordRule creates a PasswordRule for the password and its confirmation.
+func NewPasswordRule(password string, passwordConfirm string) *PasswordRule {
+	r := &PasswordRule{}
+
+	r.Rule.SetContext(passwordContext{
+		password:        password,
+		passwordConfirm: passwordConfirm,
+	})
+
+	r.Rule.SetCondition(func(ctx any) bool {
+		data := ctx.(passwordContext)
+
+		if data.password == "" {
+			r.AddFailMessage("Password is required field")
+			return false
+		}
+
+		if len(data.password) < PASSWORD_MIN_LENGTH {
+			r.AddFailMessage("Password must be at least 8 characters")
+			return false
+		}
+
+		if len(data.password) > PASSWORD_MAX_LENGTH {
+			r.AddFailMessage("Password must be at most 72 characters")
+			return false
+		}
+
+		if data.password != data.passwordConfirm {
+			r.AddFailMessage("Passwords do not match")
+			return false
+		}
+
+		return true
+	})
+
+	return r
+}
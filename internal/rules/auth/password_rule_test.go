@@ -0,0 +1,54 @@
+package authrules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPasswordRule_Valid_Passes(t *testing.T) {
+	r := NewPasswordRule("correct-horse", "correct-horse")
+	if r.Fails() {
+		t.Errorf("expected rule to pass, got: %s", r.FailMessageFirst())
+	}
+}
+
+func TestPasswordRule_Empty_Fails(t *testing.T) {
+	r := NewPasswordRule("", "")
+	if r.Passes() {
+		t.Error("expected rule to fail when password is empty")
+	}
+	if r.FailMessageFirst() != "Password is required field" {
+		t.Errorf("expected message 'Password is required field', got: %s", r.FailMessageFirst())
+	}
+}
+
+func TestPasswordRule_TooShort_Fails(t *testing.T) {
+	r := NewPasswordRule("short", "short")
+	if r.Passes() {
+		t.Error("expected rule to fail when password is too short")
+	}
+	if r.FailMessageFirst() != "Password must be at least 8 characters" {
+		t.Errorf("expected message 'Password must be at least 8 characters', got: %s", r.FailMessageFirst())
+	}
+}
+
+func TestPasswordRule_TooLong_Fails(t *testing.T) {
+	password := strings.Repeat("a", 73)
+	r := NewPasswordRule(password, password)
+	if r.Passes() {
+		t.Error("expected rule to fail when password is too long")
+	}
+	if r.FailMessageFirst() != "Password must be at most 72 characters" {
+		t.Errorf("expected message 'Password must be at most 72 characters', got: %s", r.FailMessageFirst())
+	}
+}
+
+func TestPasswordRule_Mismatch_Fails(t *testing.T) {
+	r := NewPasswordRule("correct-horse", "correct-horsE")
+	if r.Passes() {
+		t.Error("expected rule to fail when passwords do not match")
+	}
+	if r.FailMessageFirst() != "Passwords do not match" {
+		t.Errorf("expected message 'Passwords do not match', got: %s", r.FailMessageFirst())
+	}
+}
@@ -0,0 +1,30 @@
+package schedules
+
+import (
+	"project/internal/app"
+	"project/internal/tasks/search_reindex"
+
+	"github.com/dracory/base/cfmt"
+)
+
+// scheduleSearchReindexTask schedules the task rebuilding the search index.
+// It runs in the process, as the in memory index lives there
+func scheduleSearchReindexTask(app app.AppInterface) {
+	if app == nil {
+		cfmt.Errorln("SearchReindex scheduling skipped; app is nil")
+		return
+	}
+
+	if app.GetSearchIndex() == nil {
+		cfmt.Warningln("SearchReindex scheduling skipped; search index not configured.")
+		return
+	}
+
+	task := search_reindex.NewSearchReindexTask(app)
+
+	go func() {
+		if handled := task.Handle(); !handled {
+			cfmt.Warningln("SearchReindex task handler reported failure")
+		}
+	}()
+}
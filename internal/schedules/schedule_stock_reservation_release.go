@@ -0,0 +1,31 @@
+package schedules
+
+import (
+	"project/internal/app"
+	"project/internal/tasks/stock_reservation_release"
+
+	"github.com/dracory/base/cfmt"
+)
+
+// scheduleStockReservationReleaseTask schedules the task releasing the
+// stock and the discount codes held for unpaid orders. It runs without
+// stock tracking too, for the usage limits of the discount codes
+func scheduleStockReservationReleaseTask(app app.AppInterface) {
+	if app == nil {
+		cfmt.Errorln("StockReservationRelease scheduling skipped; app is nil")
+		return
+	}
+
+	if app.GetCustomStore() == nil {
+		cfmt.Warningln("StockReservationRelease scheduling skipped; custom store not configured.")
+		return
+	}
+
+	task := stock_reservation_release.NewStockReservationReleaseTask(app)
+
+	go func() {
+		if handled := task.Handle(); !handled {
+			cfmt.Warningln("StockReservationRelease task handler reported failure")
+		}
+	}()
+}
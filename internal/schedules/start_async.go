@@ -34,6 +34,22 @@ func newScheduler(app app.AppInterface) *gocron.Scheduler {
 		cfmt.Errorln("Error scheduling clean up task:", err.Error())
 	}
 
+	// Release the stock and the discount codes held for unpaid orders
+	// every 5 minutes
+	if _, err := scheduler.Every(5).Minutes().Do(func() {
+		scheduleStockReservationReleaseTask(app)
+	}); err != nil {
+		cfmt.Errorln("Error scheduling stock reservation release task:", err.Error())
+	}
+
+	// Rebuild the search index every hour (and on start, filling the
+	// in memory index)
+	if _, err := scheduler.Every(1).Hour().Do(func() {
+		scheduleSearchReindexTask(app)
+	}); err != nil {
+		cfmt.Errorln("Error scheduling search reindex task:", err.Error())
+	}
+
 	// Schedule queue clear job every 2 minutes
 	if _, err := scheduler.Every(2).Minutes().Do(func() {
 		queueClearJob(app)
@@ -119,3 +119,14 @@ func TestQueueClearJob(t *testing.T) {
 	queueClearJob(app)
 	// Should not panic
 }
+
+func TestScheduleSearchReindexTask(t *testing.T) {
+	// Test with nil app
+	scheduleSearchReindexTask(nil)
+	// Should not panic
+
+	// Test with valid app
+	app := testutils.Setup()
+	scheduleSearchReindexTask(app)
+	// Should not panic
+}
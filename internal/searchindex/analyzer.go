@@ -0,0 +1,130 @@
+package searchindex
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// token is a word of a text, with its byte offsets in the text
+type token struct {
+	Text  string
+	Start int
+	End   int
+}
+
+var (
+	htmlIgnoredRegex = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+	htmlTagRegex     = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// StripHTML returns the plain text of the HTML, without the tags,
+// scripts and styles, with the entities decoded and the white space
+// collapsed
+func StripHTML(content string) string {
+	text := htmlIgnoredRegex.ReplaceAllString(content, " ")
+	text = htmlTagRegex.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Terms returns the index terms of the text: the words in lower case,
+// without the stop words, stemmed for the language
+func Terms(text string, language string) []string {
+	language = normalizeLanguage(language)
+	terms := []string{}
+
+	for _, t := range tokenize(text) {
+		if term, ok := analyzeWord(t.Text, language); ok {
+			terms = append(terms, term)
+		}
+	}
+
+	return terms
+}
+
+// QueryTerms returns the distinct terms of the query text, in order
+func QueryTerms(text string, language string) []string {
+	terms := []string{}
+	seen := map[string]bool{}
+
+	for _, term := range Terms(text, language) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+
+	return terms
+}
+
+// analyzeWord returns the term of the word, false for the stop words
+func analyzeWord(word string, language string) (string, bool) {
+	word = strings.ToLower(word)
+
+	if isStopWord(word, language) {
+		return "", false
+	}
+
+	return Stem(word, language), true
+}
+
+// tokenize splits the text into words, the letters and digits
+// between the other characters
+func tokenize(text string) []token {
+	tokens := []token{}
+	start := -1
+
+	for i, r := range text {
+		isWordRune := unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+
+		if isWordRune && start < 0 {
+			start = i
+		}
+
+		if !isWordRune && start >= 0 {
+			tokens = append(tokens, token{Text: text[start:i], Start: start, End: i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, token{Text: text[start:], Start: start, End: len(text)})
+	}
+
+	return tokens
+}
+
+// normalizeLanguage returns the lower case ISO 639-1 part of the
+// language, e.g. en for en-GB
+func normalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+
+	if i := strings.IndexAny(language, "-_"); i > 0 {
+		language = language[:i]
+	}
+
+	return language
+}
+
+var stopWords = map[string]map[string]bool{
+	"en": wordSet("a an and are as at be but by for from has have in is it its of on or that the this to was were will with"),
+	"de": wordSet("aber als am an auch auf aus bei bin bis das dass dem den der des die ein eine einem einen einer eines es für hat ich im in ist mit nicht noch oder sie sind so und von vom war wie wir zu zum zur"),
+	"bg": wordSet("а и в във на за от до по с със е са се да не ще как че то това тези този които който която което като но или при му ги ли"),
+}
+
+// isStopWord checks if the word is too common in the language to be indexed
+func isStopWord(word string, language string) bool {
+	return stopWords[language][word]
+}
+
+func wordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, word := range strings.Fields(words) {
+		set[word] = true
+	}
+	return set
+}
@@ -0,0 +1,77 @@
+package searchindex
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStripHTML(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"<p>Hello World</p>", "Hello World"},
+		{"<div><p>Test</p></div>", "Test"},
+		{"Plain text", "Plain text"},
+		{"<a href='#'>Link</a> text", "Link text"},
+		{"<p>One</p><p>Two</p>", "One Two"},
+		{"<script>alert(1)</script><style>p{}</style>Shown", "Shown"},
+		{"Fish &amp; chips", "Fish & chips"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		result := StripHTML(test.input)
+		if result != test.expected {
+			t.Errorf("StripHTML(%q) = %q, expected %q", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		text     string
+		language string
+		expected []string
+	}{
+		{"The Running Shoes", "en", []string{"run", "shoe"}},
+		{"Connections, connected!", "en-GB", []string{"connect", "connect"}},
+		{"Die Häuser und das Haus", "de", []string{"hau", "hau"}},
+		{"Running shoes", "fr", []string{"running", "shoes"}},
+		{"", "en", []string{}},
+	}
+
+	for _, test := range tests {
+		result := Terms(test.text, test.language)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("Terms(%q, %q) = %v, expected %v", test.text, test.language, result, test.expected)
+		}
+	}
+}
+
+func TestQueryTerms_Distinct(t *testing.T) {
+	result := QueryTerms("shoe shoes SHOES boots", "en")
+
+	if !reflect.DeepEqual(result, []string{"shoe", "boot"}) {
+		t.Errorf("QueryTerms() = %v, expected [shoe boot]", result)
+	}
+}
+
+func TestTokenize_Offsets(t *testing.T) {
+	text := "Café, 42 Straße"
+	tokens := tokenize(text)
+
+	if len(tokens) != 3 {
+		t.Fatalf("expected 3 tokens, got %d", len(tokens))
+	}
+
+	for _, tok := range tokens {
+		if text[tok.Start:tok.End] != tok.Text {
+			t.Errorf("token %q does not match its offsets", tok.Text)
+		}
+	}
+
+	if tokens[2].Text != "Straße" {
+		t.Errorf("expected Straße, got %q", tokens[2].Text)
+	}
+}
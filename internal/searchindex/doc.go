@@ -0,0 +1,16 @@
+// Package searchindex is the full text search index of the website content
+// (CMS pages, blog posts and shop products).
+//
+// The documents are analyzed into terms (lower case, stop words removed,
+// stemmed for the language of the document) before being indexed, so all
+// the backends match "running" to "run" and rank the same way:
+//
+//   - SQLite: an FTS5 virtual table, ranked with bm25()
+//   - MySQL: a FULLTEXT index, ranked with MATCH ... AGAINST
+//   - any other database, or when the above are not available: a pure Go
+//     inverted index kept in memory, ranked with BM25
+//
+// The index is filled from the stores by the sources (see Reindex) on a
+// schedule, and the documents are updated one by one after they are
+// changed (see Sync).
+package searchindex
@@ -0,0 +1,134 @@
+package searchindex
+
+import (
+	"context"
+	"strings"
+)
+
+// Types of the indexed documents
+const (
+	DOCUMENT_TYPE_PAGE    = "page"
+	DOCUMENT_TYPE_POST    = "post"
+	DOCUMENT_TYPE_PRODUCT = "product"
+)
+
+// DocumentTypes lists the types of the indexed documents
+func DocumentTypes() []string {
+	return []string{DOCUMENT_TYPE_PAGE, DOCUMENT_TYPE_POST, DOCUMENT_TYPE_PRODUCT}
+}
+
+// IndexInterface is implemented by the search index backends
+type IndexInterface interface {
+	// Name identifies the backend in logs, e.g. fts5, fulltext or memory
+	Name() string
+
+	// Upsert adds the documents to the index, replacing the ones with
+	// the same ID
+	Upsert(ctx context.Context, documents ...Document) error
+
+	// Delete removes the documents with the IDs from the index
+	Delete(ctx context.Context, documentIDs ...string) error
+
+	// ReplaceType replaces all the documents of the type with the documents,
+	// removing the ones no longer in the list
+	ReplaceType(ctx context.Context, documentType string, documents []Document) error
+
+	// Search returns the page of documents matching all the terms of the
+	// query, the best ranked first, and the total number of matches
+	Search(ctx context.Context, query Query) ([]Result, int, error)
+}
+
+// Document is a piece of content in the index
+type Document struct {
+	// ID is unique across the types, see DocumentID
+	ID       string
+	Type     string
+	SourceID string
+	// Language ISO 639-1 code of the content, e.g. en. Selects the stemmer
+	Language string
+	Title    string
+	// Body the plain text of the content, HTML stripped
+	Body string
+	URL  string
+	// Date the display date of the content, e.g. the publish date of a post
+	Date string
+}
+
+// DocumentID returns the index ID of the document of the type
+// with the source ID, e.g. post:123
+func DocumentID(documentType string, sourceID string) string {
+	return documentType + ":" + sourceID
+}
+
+// Query is a search in the index
+type Query struct {
+	Text string
+	// Types limits the search to the document types, all when empty
+	Types []string
+	// Language of the query text, the default of the index when empty
+	Language string
+	Offset   int
+	// Limit the page size, 10 when not positive
+	Limit int
+}
+
+// Result is a document matching the query
+type Result struct {
+	Document
+	// Score the relevance of the document, higher is better
+	Score float64
+	// Snippet the HTML excerpt of the body around the matched terms,
+	// escaped and with the terms wrapped in <mark>
+	Snippet string
+}
+
+// queryLimit returns the page size of the query
+func queryLimit(query Query) int {
+	if query.Limit <= 0 {
+		return 10
+	}
+	return query.Limit
+}
+
+// queryOffset returns the offset of the query
+func queryOffset(query Query) int {
+	if query.Offset < 0 {
+		return 0
+	}
+	return query.Offset
+}
+
+// queryLanguage returns the language of the query,
+// falling back to the default language of the index
+func queryLanguage(query Query, defaultLanguage string) string {
+	language := strings.TrimSpace(query.Language)
+	if language == "" {
+		return defaultLanguage
+	}
+	return language
+}
+
+// typeAllowed checks if the document type is in the types of the query
+func typeAllowed(types []string, documentType string) bool {
+	if len(types) == 0 {
+		return true
+	}
+
+	for _, t := range types {
+		if t == documentType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newResult returns the result for the document, with the snippet
+// of the body highlighting the query terms
+func newResult(document Document, score float64, queryTerms []string) Result {
+	return Result{
+		Document: document,
+		Score:    score,
+		Snippet:  Snippet(document.Body, queryTerms, document.Language, snippetWords),
+	}
+}
@@ -0,0 +1,84 @@
+package searchindex
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+// Name of the table with the indexed documents, in the SQL backends
+const TABLE_SEARCH_DOCUMENTS = "search_documents"
+
+// ErrNotSupported is returned for the databases without a full text
+// search backend, use the MemoryIndex for them
+var ErrNotSupported = errors.New("searchindex: full text search not supported by the database driver")
+
+// NewDatabaseIndex returns the full text search index of the database
+// (FTS5 for sqlite and turso, FULLTEXT for mysql), creating its tables
+// when missing. It returns an error when the database has no full text
+// search (e.g. SQLite built without FTS5), use the MemoryIndex then
+func NewDatabaseIndex(ctx context.Context, db *sql.DB, driver string, language string) (IndexInterface, error) {
+	if db == nil {
+		return nil, errors.New("searchindex: db is nil")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(driver)) {
+	case "sqlite", "sqlite3", "turso", "libsql":
+		return NewSQLiteIndex(ctx, db, language)
+	case "mysql":
+		return NewMySQLIndex(ctx, db, language)
+	}
+
+	return nil, ErrNotSupported
+}
+
+// withTransaction runs the function in a transaction,
+// committed when it succeeds and rolled back otherwise
+func withTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// placeholders returns the n comma separated SQL placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// typesCondition returns the SQL condition limiting the column
+// to the types, empty when all the types are allowed
+func typesCondition(column string, types []string) (string, []any) {
+	if len(types) == 0 {
+		return "", nil
+	}
+
+	args := make([]any, 0, len(types))
+	for _, t := range types {
+		args = append(args, t)
+	}
+
+	return " AND " + column + " IN (" + placeholders(len(types)) + ")", args
+}
+
+// documentTerms returns the analyzed title and body of the document,
+// the terms separated by spaces
+func documentTerms(document Document, defaultLanguage string) (string, string) {
+	language := document.Language
+	if language == "" {
+		language = defaultLanguage
+	}
+
+	title := strings.Join(Terms(document.Title, language), " ")
+	body := strings.Join(Terms(document.Body, language), " ")
+
+	return title, body
+}
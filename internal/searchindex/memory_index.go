@@ -0,0 +1,219 @@
+package searchindex
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 parameters, the usual defaults
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// titleWeight is how many times a term in the title counts
+// compared to a term in the body
+const titleWeight = 3.0
+
+// MemoryIndex is the pure Go inverted index, used when the database has
+// no full text search. It lives in memory, so it is empty until the first
+// reindex after a restart
+type MemoryIndex struct {
+	mu sync.RWMutex
+
+	language string
+
+	documents map[string]*memoryDocument
+	// postings maps the terms to the IDs of the documents with them
+	postings    map[string]map[string]bool
+	totalLength float64
+}
+
+type memoryDocument struct {
+	document Document
+	// frequencies the weighted number of occurrences of the terms
+	frequencies map[string]float64
+	length      float64
+}
+
+var _ IndexInterface = (*MemoryIndex)(nil)
+
+// NewMemoryIndex returns an empty in memory index, the language being
+// the default language of the documents and the queries
+func NewMemoryIndex(language string) *MemoryIndex {
+	return &MemoryIndex{
+		language:  language,
+		documents: map[string]*memoryDocument{},
+		postings:  map[string]map[string]bool{},
+	}
+}
+
+// Name returns the name of the backend
+func (index *MemoryIndex) Name() string {
+	return "memory"
+}
+
+// Upsert adds the documents to the index, replacing the ones with the same ID
+func (index *MemoryIndex) Upsert(_ context.Context, documents ...Document) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	for _, document := range documents {
+		index.remove(document.ID)
+		index.add(document)
+	}
+
+	return nil
+}
+
+// Delete removes the documents with the IDs from the index
+func (index *MemoryIndex) Delete(_ context.Context, documentIDs ...string) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	for _, id := range documentIDs {
+		index.remove(id)
+	}
+
+	return nil
+}
+
+// ReplaceType replaces all the documents of the type with the documents
+func (index *MemoryIndex) ReplaceType(_ context.Context, documentType string, documents []Document) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	for id, entry := range index.documents {
+		if entry.document.Type == documentType {
+			index.remove(id)
+		}
+	}
+
+	for _, document := range documents {
+		index.remove(document.ID)
+		index.add(document)
+	}
+
+	return nil
+}
+
+// Search returns the page of documents matching all the query terms,
+// ranked with BM25, and the total number of matches
+func (index *MemoryIndex) Search(_ context.Context, query Query) ([]Result, int, error) {
+	terms := QueryTerms(query.Text, queryLanguage(query, index.language))
+	if len(terms) == 0 {
+		return []Result{}, 0, nil
+	}
+
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+
+	results := []Result{}
+	for id := range index.postings[terms[0]] {
+		entry := index.documents[id]
+		if !typeAllowed(query.Types, entry.document.Type) || !index.hasAllTerms(entry, terms) {
+			continue
+		}
+
+		results = append(results, Result{Document: entry.document, Score: index.score(entry, terms)})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	total := len(results)
+	offset := queryOffset(query)
+	if offset >= total {
+		return []Result{}, total, nil
+	}
+
+	end := min(offset+queryLimit(query), total)
+	page := make([]Result, 0, end-offset)
+	for _, result := range results[offset:end] {
+		page = append(page, newResult(result.Document, result.Score, terms))
+	}
+
+	return page, total, nil
+}
+
+// add indexes the document, the lock must be held
+func (index *MemoryIndex) add(document Document) {
+	language := document.Language
+	if language == "" {
+		language = index.language
+	}
+
+	entry := &memoryDocument{document: document, frequencies: map[string]float64{}}
+
+	for _, term := range Terms(document.Title, language) {
+		entry.frequencies[term] += titleWeight
+		entry.length += titleWeight
+	}
+
+	for _, term := range Terms(document.Body, language) {
+		entry.frequencies[term]++
+		entry.length++
+	}
+
+	for term := range entry.frequencies {
+		if index.postings[term] == nil {
+			index.postings[term] = map[string]bool{}
+		}
+		index.postings[term][document.ID] = true
+	}
+
+	index.documents[document.ID] = entry
+	index.totalLength += entry.length
+}
+
+// remove removes the document from the index, the lock must be held
+func (index *MemoryIndex) remove(id string) {
+	entry, ok := index.documents[id]
+	if !ok {
+		return
+	}
+
+	for term := range entry.frequencies {
+		delete(index.postings[term], id)
+		if len(index.postings[term]) == 0 {
+			delete(index.postings, term)
+		}
+	}
+
+	index.totalLength -= entry.length
+	delete(index.documents, id)
+}
+
+func (index *MemoryIndex) hasAllTerms(entry *memoryDocument, terms []string) bool {
+	for _, term := range terms {
+		if entry.frequencies[term] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// score returns the BM25 score of the document for the terms
+func (index *MemoryIndex) score(entry *memoryDocument, terms []string) float64 {
+	documentCount := float64(len(index.documents))
+	averageLength := index.totalLength / documentCount
+
+	score := 0.0
+	for _, term := range terms {
+		frequency := entry.frequencies[term]
+		matches := float64(len(index.postings[term]))
+
+		idf := math.Log(1 + (documentCount-matches+0.5)/(matches+0.5))
+		norm := bm25K1 * (1 - bm25B + bm25B*entry.length/averageLength)
+
+		score += idf * frequency * (bm25K1 + 1) / (frequency + norm)
+	}
+
+	return score
+}
@@ -0,0 +1,113 @@
+package searchindex
+
+import (
+	"context"
+	"testing"
+)
+
+func testDocuments() []Document {
+	return []Document{
+		{ID: "page:1", Type: DOCUMENT_TYPE_PAGE, SourceID: "1", Title: "About us", Body: "We are a small team making running shoes since 1990."},
+		{ID: "post:1", Type: DOCUMENT_TYPE_POST, SourceID: "1", Title: "Running shoes guide", Body: "How to choose running shoes for the road and the trail."},
+		{ID: "post:2", Type: DOCUMENT_TYPE_POST, SourceID: "2", Title: "Trail news", Body: "The trail season starts. Running in the mountains is fun."},
+		{ID: "product:1", Type: DOCUMENT_TYPE_PRODUCT, SourceID: "1", Title: "Road runner shoe", Body: "A light shoe for running on the road."},
+	}
+}
+
+// testIndexSearch runs the tests shared by all the backends
+func testIndexSearch(t *testing.T, index IndexInterface) {
+	ctx := context.Background()
+
+	if err := index.ReplaceType(ctx, DOCUMENT_TYPE_PAGE, testDocuments()[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.ReplaceType(ctx, DOCUMENT_TYPE_POST, testDocuments()[1:3]); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Upsert(ctx, testDocuments()[3]); err != nil {
+		t.Fatal(err)
+	}
+
+	// stemmed: "run shoes" matches running and shoe
+	results, total, err := index.Search(ctx, Query{Text: "run shoes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d of %d", len(results), total)
+	}
+
+	// the title matches rank first
+	if results[0].ID != "post:1" {
+		t.Errorf("expected post:1 first, got %s", results[0].ID)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Errorf("results not ordered by score: %v", results)
+		}
+	}
+	if results[0].Snippet == "" || results[0].Title != "Running shoes guide" {
+		t.Errorf("expected the document and its snippet, got %+v", results[0])
+	}
+
+	// all the terms are required
+	_, total, err = index.Search(ctx, Query{Text: "trail mountains"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 {
+		t.Errorf("expected 1 result for trail mountains, got %d", total)
+	}
+
+	// types and pagination
+	results, total, err = index.Search(ctx, Query{Text: "running", Types: []string{DOCUMENT_TYPE_POST}, Offset: 1, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || len(results) != 1 || results[0].Type != DOCUMENT_TYPE_POST {
+		t.Errorf("expected the second of 2 posts, got %d of %d", len(results), total)
+	}
+
+	// replacing the type removes the old documents
+	if err := index.ReplaceType(ctx, DOCUMENT_TYPE_POST, testDocuments()[2:3]); err != nil {
+		t.Fatal(err)
+	}
+	_, total, _ = index.Search(ctx, Query{Text: "guide"})
+	if total != 0 {
+		t.Errorf("expected the replaced post to be removed, got %d results", total)
+	}
+
+	if err := index.Delete(ctx, "product:1"); err != nil {
+		t.Fatal(err)
+	}
+	_, total, _ = index.Search(ctx, Query{Text: "road"})
+	if total != 0 {
+		t.Errorf("expected the deleted product to be removed, got %d results", total)
+	}
+
+	// stop words only
+	results, total, err = index.Search(ctx, Query{Text: "the"})
+	if err != nil || total != 0 || len(results) != 0 {
+		t.Errorf("expected no results for stop words, got %d (%v)", total, err)
+	}
+}
+
+func TestMemoryIndex_Search(t *testing.T) {
+	testIndexSearch(t, NewMemoryIndex("en"))
+}
+
+func TestMemoryIndex_Upsert_Replaces(t *testing.T) {
+	ctx := context.Background()
+	index := NewMemoryIndex("en")
+
+	_ = index.Upsert(ctx, Document{ID: "page:1", Type: DOCUMENT_TYPE_PAGE, Title: "Old title"})
+	_ = index.Upsert(ctx, Document{ID: "page:1", Type: DOCUMENT_TYPE_PAGE, Title: "New title"})
+
+	if _, total, _ := index.Search(ctx, Query{Text: "old"}); total != 0 {
+		t.Errorf("expected the old version to be removed, got %d results", total)
+	}
+
+	if _, total, _ := index.Search(ctx, Query{Text: "new"}); total != 1 {
+		t.Errorf("expected the new version, got %d results", total)
+	}
+}
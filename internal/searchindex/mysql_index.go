@@ -0,0 +1,227 @@
+package searchindex
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode/utf8"
+)
+
+// MySQLIndex is the index backed by the MySQL FULLTEXT indexes on
+// the analyzed terms of the documents.
+//
+// InnoDB leaves the words shorter than innodb_ft_min_token_size (3 by
+// default) out of the FULLTEXT indexes, so a search for them would never
+// match. These terms are matched with LIKE on the terms of the documents
+// instead, see Search.
+type MySQLIndex struct {
+	db       *sql.DB
+	language string
+	// minTokenSize the innodb_ft_min_token_size of the server
+	minTokenSize int
+}
+
+// mysqlDefaultMinTokenSize is the default of innodb_ft_min_token_size,
+// used when it cannot be read from the server
+const mysqlDefaultMinTokenSize = 3
+
+var _ IndexInterface = (*MySQLIndex)(nil)
+
+// NewMySQLIndex returns the FULLTEXT index, creating its table when missing
+func NewMySQLIndex(ctx context.Context, db *sql.DB, language string) (*MySQLIndex, error) {
+	statement := `CREATE TABLE IF NOT EXISTS ` + TABLE_SEARCH_DOCUMENTS + ` (
+		id VARCHAR(191) NOT NULL,
+		type VARCHAR(20) NOT NULL,
+		source_id VARCHAR(191) NOT NULL DEFAULT '',
+		language VARCHAR(10) NOT NULL DEFAULT '',
+		title TEXT NOT NULL,
+		body MEDIUMTEXT NOT NULL,
+		url TEXT NOT NULL,
+		display_date VARCHAR(50) NOT NULL DEFAULT '',
+		title_terms TEXT NOT NULL,
+		body_terms MEDIUMTEXT NOT NULL,
+		PRIMARY KEY (id),
+		KEY idx_search_documents_type (type),
+		FULLTEXT KEY ftx_search_documents_title (title_terms),
+		FULLTEXT KEY ftx_search_documents_terms (title_terms, body_terms)
+	) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`
+
+	if _, err := db.ExecContext(ctx, statement); err != nil {
+		return nil, err
+	}
+
+	minTokenSize := mysqlDefaultMinTokenSize
+	if err := db.QueryRowContext(ctx, `SELECT @@innodb_ft_min_token_size`).Scan(&minTokenSize); err != nil {
+		minTokenSize = mysqlDefaultMinTokenSize
+	}
+
+	return &MySQLIndex{db: db, language: language, minTokenSize: minTokenSize}, nil
+}
+
+// Name returns the name of the backend
+func (index *MySQLIndex) Name() string {
+	return "fulltext"
+}
+
+// Upsert adds the documents to the index, replacing the ones with the same ID
+func (index *MySQLIndex) Upsert(ctx context.Context, documents ...Document) error {
+	return withTransaction(ctx, index.db, func(tx *sql.Tx) error {
+		for _, document := range documents {
+			if err := index.upsert(ctx, tx, document); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes the documents with the IDs from the index
+func (index *MySQLIndex) Delete(ctx context.Context, documentIDs ...string) error {
+	if len(documentIDs) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(documentIDs))
+	for _, id := range documentIDs {
+		args = append(args, id)
+	}
+
+	_, err := index.db.ExecContext(ctx, `DELETE FROM `+TABLE_SEARCH_DOCUMENTS+` WHERE id IN (`+placeholders(len(args))+`)`, args...)
+	return err
+}
+
+// ReplaceType replaces all the documents of the type with the documents
+func (index *MySQLIndex) ReplaceType(ctx context.Context, documentType string, documents []Document) error {
+	return withTransaction(ctx, index.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+TABLE_SEARCH_DOCUMENTS+` WHERE type = ?`, documentType); err != nil {
+			return err
+		}
+
+		for _, document := range documents {
+			if err := index.upsert(ctx, tx, document); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Search returns the page of documents matching all the query terms,
+// ranked by the FULLTEXT relevance with the title counting three times,
+// and the total number of matches.
+//
+// The terms shorter than innodb_ft_min_token_size are required with LIKE
+// instead of the FULLTEXT index, and do not count in the relevance
+func (index *MySQLIndex) Search(ctx context.Context, query Query) ([]Result, int, error) {
+	terms := QueryTerms(query.Text, queryLanguage(query, index.language))
+	if len(terms) == 0 {
+		return []Result{}, 0, nil
+	}
+
+	fulltextTerms, shortTerms := index.splitTerms(terms)
+
+	whereSQL := ` WHERE 1 = 1`
+	whereArgs := []any{}
+	scoreSQL := `0`
+	scoreArgs := []any{}
+
+	if len(fulltextTerms) > 0 {
+		// all the terms are required in the boolean mode, e.g. +run +shoe
+		whereSQL += ` AND MATCH(title_terms, body_terms) AGAINST (? IN BOOLEAN MODE)`
+		whereArgs = append(whereArgs, "+"+strings.Join(fulltextTerms, " +"))
+
+		relevance := strings.Join(fulltextTerms, " ")
+		scoreSQL = `3 * MATCH(title_terms) AGAINST (?) + MATCH(title_terms, body_terms) AGAINST (?)`
+		scoreArgs = append(scoreArgs, relevance, relevance)
+	}
+
+	// the terms are separated by spaces, so the whole term is matched
+	for _, term := range shortTerms {
+		whereSQL += ` AND CONCAT(' ', title_terms, ' ', body_terms, ' ') LIKE ?`
+		whereArgs = append(whereArgs, "% "+likeEscape(term)+" %")
+	}
+
+	typesSQL, typesArgs := typesCondition("type", query.Types)
+	whereSQL += typesSQL
+	whereArgs = append(whereArgs, typesArgs...)
+
+	total := 0
+	countSQL := `SELECT COUNT(*) FROM ` + TABLE_SEARCH_DOCUMENTS + whereSQL
+	if err := index.db.QueryRowContext(ctx, countSQL, whereArgs...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return []Result{}, 0, nil
+	}
+
+	searchSQL := `SELECT id, type, source_id, language, title, body, url, display_date,
+			` + scoreSQL + ` AS score
+		FROM ` + TABLE_SEARCH_DOCUMENTS + whereSQL + `
+		ORDER BY score DESC, id
+		LIMIT ? OFFSET ?`
+
+	args := append(scoreArgs, whereArgs...)
+	args = append(args, queryLimit(query), queryOffset(query))
+
+	rows, err := index.db.QueryContext(ctx, searchSQL, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []Result{}
+	for rows.Next() {
+		document := Document{}
+		score := 0.0
+
+		if err := rows.Scan(&document.ID, &document.Type, &document.SourceID, &document.Language, &document.Title, &document.Body, &document.URL, &document.Date, &score); err != nil {
+			return nil, 0, err
+		}
+
+		results = append(results, newResult(document, score, terms))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
+// splitTerms returns the terms the FULLTEXT index has, and the ones
+// shorter than innodb_ft_min_token_size
+func (index *MySQLIndex) splitTerms(terms []string) ([]string, []string) {
+	fulltextTerms := []string{}
+	shortTerms := []string{}
+
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < index.minTokenSize {
+			shortTerms = append(shortTerms, term)
+		} else {
+			fulltextTerms = append(fulltextTerms, term)
+		}
+	}
+
+	return fulltextTerms, shortTerms
+}
+
+// likeEscape escapes the wildcards of LIKE in the text
+func likeEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+}
+
+func (index *MySQLIndex) upsert(ctx context.Context, tx *sql.Tx, document Document) error {
+	titleTerms, bodyTerms := documentTerms(document, index.language)
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO `+TABLE_SEARCH_DOCUMENTS+`
+			(id, type, source_id, language, title, body, url, display_date, title_terms, body_terms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			type = VALUES(type), source_id = VALUES(source_id), language = VALUES(language),
+			title = VALUES(title), body = VALUES(body), url = VALUES(url), display_date = VALUES(display_date),
+			title_terms = VALUES(title_terms), body_terms = VALUES(body_terms)`,
+		document.ID, document.Type, document.SourceID, document.Language, document.Title, document.Body, document.URL, document.Date, titleTerms, bodyTerms)
+
+	return err
+}
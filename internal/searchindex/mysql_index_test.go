@@ -0,0 +1,26 @@
+package searchindex
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMySQLIndex_SplitTerms(t *testing.T) {
+	index := &MySQLIndex{minTokenSize: mysqlDefaultMinTokenSize}
+
+	fulltextTerms, shortTerms := index.splitTerms([]string{"go", "run", "ü", "shoe"})
+
+	if !slices.Equal(fulltextTerms, []string{"run", "shoe"}) {
+		t.Errorf("expected the FULLTEXT terms run and shoe, got %v", fulltextTerms)
+	}
+
+	if !slices.Equal(shortTerms, []string{"go", "ü"}) {
+		t.Errorf("expected the short terms go and ü, got %v", shortTerms)
+	}
+}
+
+func TestLikeEscape(t *testing.T) {
+	if escaped := likeEscape(`50%_a\b`); escaped != `50\%\_a\\b` {
+		t.Errorf("expected the wildcards to be escaped, got %s", escaped)
+	}
+}
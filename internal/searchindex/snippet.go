@@ -0,0 +1,106 @@
+package searchindex
+
+import (
+	"html"
+	"strings"
+)
+
+// snippetWords is the length of the snippets of the search results
+const snippetWords = 30
+
+// Snippet returns the HTML excerpt of the plain text with the most
+// matches of the query terms, the matching words wrapped in <mark>.
+// The text is escaped. Without matches the start of the text is returned
+func Snippet(text string, queryTerms []string, language string, words int) string {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	if words <= 0 {
+		words = snippetWords
+	}
+
+	language = normalizeLanguage(language)
+	wanted := map[string]bool{}
+	for _, term := range queryTerms {
+		wanted[term] = true
+	}
+
+	hits := make([]bool, len(tokens))
+	for i, t := range tokens {
+		if term, ok := analyzeWord(t.Text, language); ok && wanted[term] {
+			hits[i] = true
+		}
+	}
+
+	start := bestSnippetWindow(hits, words)
+	end := min(start+words, len(tokens))
+
+	var sb strings.Builder
+
+	if start > 0 {
+		sb.WriteString("… ")
+	}
+
+	for i := start; i < end; i++ {
+		if i > start {
+			sb.WriteString(html.EscapeString(text[tokens[i-1].End:tokens[i].Start]))
+		}
+
+		word := html.EscapeString(tokens[i].Text)
+		if hits[i] {
+			word = "<mark>" + word + "</mark>"
+		}
+		sb.WriteString(word)
+	}
+
+	if end < len(tokens) {
+		sb.WriteString(" …")
+	} else {
+		// keep the punctuation ending the text, e.g. the full stop
+		sb.WriteString(html.EscapeString(strings.TrimSpace(text[tokens[end-1].End:])))
+	}
+
+	return sb.String()
+}
+
+// bestSnippetWindow returns the start of the window of words
+// with the most hits, the earliest one on a tie
+func bestSnippetWindow(hits []bool, words int) int {
+	if len(hits) <= words {
+		return 0
+	}
+
+	count := 0
+	for i := 0; i < words; i++ {
+		if hits[i] {
+			count++
+		}
+	}
+
+	best, bestCount := 0, count
+	for start := 1; start+words <= len(hits); start++ {
+		if hits[start-1] {
+			count--
+		}
+		if hits[start+words-1] {
+			count++
+		}
+		if count > bestCount {
+			best, bestCount = start, count
+		}
+	}
+
+	// start a few words before the first hit, for context
+	if bestCount > 0 {
+		for i := best; i < best+words; i++ {
+			if hits[i] {
+				best = max(0, min(i-3, len(hits)-words))
+				break
+			}
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,47 @@
+package searchindex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSnippet_HighlightsTerms(t *testing.T) {
+	text := "We sell shoes. Our running shoes are <light> & fast."
+	snippet := Snippet(text, QueryTerms("running shoe", "en"), "en", 30)
+
+	expected := "We sell <mark>shoes</mark>. Our <mark>running</mark> <mark>shoes</mark> are &lt;light&gt; &amp; fast."
+	if snippet != expected {
+		t.Errorf("Snippet() = %q, expected %q", snippet, expected)
+	}
+}
+
+func TestSnippet_WindowAroundMatches(t *testing.T) {
+	words := []string{}
+	for i := 0; i < 100; i++ {
+		words = append(words, "filler")
+	}
+	words[60] = "needle"
+	text := strings.Join(words, " ")
+
+	snippet := Snippet(text, []string{"needl"}, "en", 10)
+
+	if !strings.HasPrefix(snippet, "… ") || !strings.HasSuffix(snippet, " …") {
+		t.Errorf("expected the snippet to be cut on both sides, got %q", snippet)
+	}
+
+	if !strings.Contains(snippet, "filler filler filler <mark>needle</mark>") {
+		t.Errorf("expected the match with some context before it, got %q", snippet)
+	}
+}
+
+func TestSnippet_NoMatches(t *testing.T) {
+	snippet := Snippet("First words of the text", []string{"other"}, "en", 3)
+
+	if snippet != "First words of …" {
+		t.Errorf("expected the start of the text, got %q", snippet)
+	}
+
+	if Snippet("", []string{"other"}, "en", 3) != "" {
+		t.Error("expected an empty snippet for an empty text")
+	}
+}
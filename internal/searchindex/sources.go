@@ -0,0 +1,267 @@
+package searchindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"project/internal/links"
+
+	"github.com/dracory/blogstore"
+	"github.com/dracory/cmsstore"
+	"github.com/dracory/shopstore"
+	"github.com/dracory/str"
+)
+
+// SourceInterface reads the documents of a type from its store
+type SourceInterface interface {
+	// Type returns the type of the documents, e.g. DOCUMENT_TYPE_PAGE
+	Type() string
+
+	// Documents returns all the documents of the type to be searchable,
+	// e.g. the active pages
+	Documents(ctx context.Context) ([]Document, error)
+
+	// Document returns the document with the source ID, and false when
+	// it is not to be searchable (e.g. a draft post) or not found
+	Document(ctx context.Context, sourceID string) (Document, bool, error)
+}
+
+// Reindex replaces the documents of the sources in the index,
+// trying all the sources even when some of them fail
+func Reindex(ctx context.Context, index IndexInterface, sources ...SourceInterface) error {
+	if index == nil {
+		return errors.New("searchindex: index is nil")
+	}
+
+	errs := []error{}
+
+	for _, source := range sources {
+		documents, err := source.Documents(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Type(), err))
+			continue
+		}
+
+		if err := index.ReplaceType(ctx, source.Type(), documents); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.Type(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Sync updates the documents of the source with the source IDs in the
+// index, after they are changed: the searchable ones are upserted, the
+// others (e.g. deleted or unpublished) removed
+func Sync(ctx context.Context, index IndexInterface, source SourceInterface, sourceIDs ...string) error {
+	if index == nil {
+		return errors.New("searchindex: index is nil")
+	}
+
+	upserts := []Document{}
+	deletes := []string{}
+
+	for _, sourceID := range sourceIDs {
+		document, found, err := source.Document(ctx, sourceID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source.Type(), err)
+		}
+
+		if found {
+			upserts = append(upserts, document)
+		} else {
+			deletes = append(deletes, DocumentID(source.Type(), sourceID))
+		}
+	}
+
+	if len(upserts) > 0 {
+		if err := index.Upsert(ctx, upserts...); err != nil {
+			return fmt.Errorf("%s: %w", source.Type(), err)
+		}
+	}
+
+	if err := index.Delete(ctx, deletes...); err != nil {
+		return fmt.Errorf("%s: %w", source.Type(), err)
+	}
+
+	return nil
+}
+
+// == PAGES ===================================================================
+
+type pageSource struct {
+	store    cmsstore.StoreInterface
+	language string
+}
+
+// NewPageSource returns the source of the active CMS pages
+func NewPageSource(store cmsstore.StoreInterface, language string) SourceInterface {
+	return &pageSource{store: store, language: language}
+}
+
+func (s *pageSource) Type() string {
+	return DOCUMENT_TYPE_PAGE
+}
+
+func (s *pageSource) Documents(ctx context.Context) ([]Document, error) {
+	pages, err := s.store.PageList(ctx, cmsstore.PageQuery().
+		SetStatus(cmsstore.PAGE_STATUS_ACTIVE))
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]Document, 0, len(pages))
+	for _, page := range pages {
+		documents = append(documents, s.document(page))
+	}
+
+	return documents, nil
+}
+
+func (s *pageSource) Document(ctx context.Context, sourceID string) (Document, bool, error) {
+	page, err := s.store.PageFindByID(ctx, sourceID)
+	if err != nil {
+		return Document{}, false, err
+	}
+
+	if page == nil || page.Status() != cmsstore.PAGE_STATUS_ACTIVE {
+		return Document{}, false, nil
+	}
+
+	return s.document(page), true, nil
+}
+
+func (s *pageSource) document(page cmsstore.PageInterface) Document {
+	body := strings.TrimSpace(page.MetaDescription() + " " + StripHTML(page.Content()))
+
+	return Document{
+		ID:       DocumentID(DOCUMENT_TYPE_PAGE, page.ID()),
+		Type:     DOCUMENT_TYPE_PAGE,
+		SourceID: page.ID(),
+		Language: s.language,
+		Title:    page.Title(),
+		Body:     body,
+		URL:      "/" + strings.TrimPrefix(page.Alias(), "/"),
+	}
+}
+
+// == POSTS ===================================================================
+
+type postSource struct {
+	store    blogstore.StoreInterface
+	language string
+}
+
+// NewPostSource returns the source of the published blog posts
+func NewPostSource(store blogstore.StoreInterface, language string) SourceInterface {
+	return &postSource{store: store, language: language}
+}
+
+func (s *postSource) Type() string {
+	return DOCUMENT_TYPE_POST
+}
+
+func (s *postSource) Documents(ctx context.Context) ([]Document, error) {
+	posts, err := s.store.PostList(ctx, blogstore.PostQueryOptions{
+		Status: blogstore.POST_STATUS_PUBLISHED,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]Document, 0, len(posts))
+	for _, post := range posts {
+		documents = append(documents, s.document(post))
+	}
+
+	return documents, nil
+}
+
+func (s *postSource) Document(ctx context.Context, sourceID string) (Document, bool, error) {
+	post, err := s.store.PostFindByID(ctx, sourceID)
+	if err != nil {
+		return Document{}, false, err
+	}
+
+	if post == nil || !post.IsPublished() {
+		return Document{}, false, nil
+	}
+
+	return s.document(post), true, nil
+}
+
+func (s *postSource) document(post blogstore.PostInterface) Document {
+	date := ""
+	if post.GetPublishedAt() != "" {
+		date = post.GetPublishedAtCarbon().Format("d M, Y")
+	}
+
+	return Document{
+		ID:       DocumentID(DOCUMENT_TYPE_POST, post.GetID()),
+		Type:     DOCUMENT_TYPE_POST,
+		SourceID: post.GetID(),
+		Language: s.language,
+		Title:    post.GetTitle(),
+		Body:     strings.TrimSpace(post.GetSummary() + " " + StripHTML(post.GetContent())),
+		URL:      links.Website().BlogPost(post.GetID(), post.GetSlug()),
+		Date:     date,
+	}
+}
+
+// == PRODUCTS ================================================================
+
+type productSource struct {
+	store    shopstore.StoreInterface
+	language string
+}
+
+// NewProductSource returns the source of the active shop products
+func NewProductSource(store shopstore.StoreInterface, language string) SourceInterface {
+	return &productSource{store: store, language: language}
+}
+
+func (s *productSource) Type() string {
+	return DOCUMENT_TYPE_PRODUCT
+}
+
+func (s *productSource) Documents(ctx context.Context) ([]Document, error) {
+	products, err := s.store.ProductList(ctx, shopstore.NewProductQuery().
+		SetStatus(shopstore.PRODUCT_STATUS_ACTIVE))
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]Document, 0, len(products))
+	for _, product := range products {
+		documents = append(documents, s.document(product))
+	}
+
+	return documents, nil
+}
+
+func (s *productSource) Document(ctx context.Context, sourceID string) (Document, bool, error) {
+	product, err := s.store.ProductFindByID(ctx, sourceID)
+	if err != nil {
+		return Document{}, false, err
+	}
+
+	if product == nil || product.GetStatus() != shopstore.PRODUCT_STATUS_ACTIVE {
+		return Document{}, false, nil
+	}
+
+	return s.document(product), true, nil
+}
+
+func (s *productSource) document(product shopstore.ProductInterface) Document {
+	return Document{
+		ID:       DocumentID(DOCUMENT_TYPE_PRODUCT, product.GetID()),
+		Type:     DOCUMENT_TYPE_PRODUCT,
+		SourceID: product.GetID(),
+		Language: s.language,
+		Title:    product.GetTitle(),
+		Body:     StripHTML(product.GetDescription()),
+		URL:      links.Website().ShopProduct(product.GetID(), str.Slugify(product.GetTitle(), '-'), nil),
+	}
+}
@@ -0,0 +1,60 @@
+package searchindex
+
+import (
+	"context"
+	"testing"
+)
+
+// testSource is a source of posts kept in a map, the posts missing from
+// it being unpublished
+type testSource struct {
+	documents map[string]Document
+}
+
+func (s *testSource) Type() string {
+	return DOCUMENT_TYPE_POST
+}
+
+func (s *testSource) Documents(ctx context.Context) ([]Document, error) {
+	documents := []Document{}
+	for _, document := range s.documents {
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
+
+func (s *testSource) Document(ctx context.Context, sourceID string) (Document, bool, error) {
+	document, found := s.documents[sourceID]
+	return document, found, nil
+}
+
+func TestSync_UpsertsAndRemoves(t *testing.T) {
+	ctx := context.Background()
+	index := NewMemoryIndex("en")
+	source := &testSource{documents: map[string]Document{
+		"1": testDocuments()[1],
+		"2": testDocuments()[2],
+	}}
+
+	if err := Reindex(ctx, index, source); err != nil {
+		t.Fatal(err)
+	}
+
+	// post 1 is changed, post 2 unpublished
+	changed := testDocuments()[1]
+	changed.Title = "Hiking boots guide"
+	source.documents["1"] = changed
+	delete(source.documents, "2")
+
+	if err := Sync(ctx, index, source, "1", "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, total, _ := index.Search(ctx, Query{Text: "hiking"}); total != 1 {
+		t.Errorf("expected the changed post to be upserted, got %d results", total)
+	}
+
+	if _, total, _ := index.Search(ctx, Query{Text: "mountains"}); total != 0 {
+		t.Errorf("expected the unpublished post to be removed, got %d results", total)
+	}
+}
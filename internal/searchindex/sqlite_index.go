@@ -0,0 +1,191 @@
+package searchindex
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// tableSearchDocumentsFTS is the FTS5 table with the terms of the documents
+const tableSearchDocumentsFTS = TABLE_SEARCH_DOCUMENTS + "_fts"
+
+// SQLiteIndex is the index backed by an SQLite FTS5 table. The table holds
+// the analyzed terms, the documents themselves are in search_documents,
+// the rows of both sharing the rowid
+type SQLiteIndex struct {
+	db       *sql.DB
+	language string
+}
+
+var _ IndexInterface = (*SQLiteIndex)(nil)
+
+// NewSQLiteIndex returns the FTS5 index, creating its tables when missing.
+// It fails when SQLite is built without FTS5
+func NewSQLiteIndex(ctx context.Context, db *sql.DB, language string) (*SQLiteIndex, error) {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS ` + TABLE_SEARCH_DOCUMENTS + ` (
+			id TEXT NOT NULL PRIMARY KEY,
+			type TEXT NOT NULL,
+			source_id TEXT NOT NULL DEFAULT '',
+			language TEXT NOT NULL DEFAULT '',
+			title TEXT NOT NULL DEFAULT '',
+			body TEXT NOT NULL DEFAULT '',
+			url TEXT NOT NULL DEFAULT '',
+			display_date TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_` + TABLE_SEARCH_DOCUMENTS + `_type ON ` + TABLE_SEARCH_DOCUMENTS + ` (type)`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS ` + tableSearchDocumentsFTS + ` USING fts5(title, body, tokenize='unicode61')`,
+	}
+
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return nil, err
+		}
+	}
+
+	return &SQLiteIndex{db: db, language: language}, nil
+}
+
+// Name returns the name of the backend
+func (index *SQLiteIndex) Name() string {
+	return "fts5"
+}
+
+// Upsert adds the documents to the index, replacing the ones with the same ID
+func (index *SQLiteIndex) Upsert(ctx context.Context, documents ...Document) error {
+	return withTransaction(ctx, index.db, func(tx *sql.Tx) error {
+		for _, document := range documents {
+			if err := index.delete(ctx, tx, "id", document.ID); err != nil {
+				return err
+			}
+			if err := index.insert(ctx, tx, document); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes the documents with the IDs from the index
+func (index *SQLiteIndex) Delete(ctx context.Context, documentIDs ...string) error {
+	return withTransaction(ctx, index.db, func(tx *sql.Tx) error {
+		for _, id := range documentIDs {
+			if err := index.delete(ctx, tx, "id", id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReplaceType replaces all the documents of the type with the documents
+func (index *SQLiteIndex) ReplaceType(ctx context.Context, documentType string, documents []Document) error {
+	return withTransaction(ctx, index.db, func(tx *sql.Tx) error {
+		if err := index.delete(ctx, tx, "type", documentType); err != nil {
+			return err
+		}
+
+		for _, document := range documents {
+			if err := index.insert(ctx, tx, document); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Search returns the page of documents matching all the query terms,
+// ranked with the FTS5 bm25() function, and the total number of matches
+func (index *SQLiteIndex) Search(ctx context.Context, query Query) ([]Result, int, error) {
+	terms := QueryTerms(query.Text, queryLanguage(query, index.language))
+	if len(terms) == 0 {
+		return []Result{}, 0, nil
+	}
+
+	match := sqliteMatchExpression(terms)
+	typesSQL, typesArgs := typesCondition("d.type", query.Types)
+
+	total := 0
+	countSQL := `SELECT COUNT(*)
+		FROM ` + tableSearchDocumentsFTS + ` f
+		JOIN ` + TABLE_SEARCH_DOCUMENTS + ` d ON d.rowid = f.rowid
+		WHERE ` + tableSearchDocumentsFTS + ` MATCH ?` + typesSQL
+	if err := index.db.QueryRowContext(ctx, countSQL, append([]any{match}, typesArgs...)...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if total == 0 {
+		return []Result{}, 0, nil
+	}
+
+	// the weights of the columns title and body
+	searchSQL := `SELECT d.id, d.type, d.source_id, d.language, d.title, d.body, d.url, d.display_date,
+			bm25(` + tableSearchDocumentsFTS + `, 3.0, 1.0) AS rank
+		FROM ` + tableSearchDocumentsFTS + ` f
+		JOIN ` + TABLE_SEARCH_DOCUMENTS + ` d ON d.rowid = f.rowid
+		WHERE ` + tableSearchDocumentsFTS + ` MATCH ?` + typesSQL + `
+		ORDER BY rank, d.id
+		LIMIT ? OFFSET ?`
+
+	args := append([]any{match}, typesArgs...)
+	args = append(args, queryLimit(query), queryOffset(query))
+
+	rows, err := index.db.QueryContext(ctx, searchSQL, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []Result{}
+	for rows.Next() {
+		document := Document{}
+		rank := 0.0
+
+		if err := rows.Scan(&document.ID, &document.Type, &document.SourceID, &document.Language, &document.Title, &document.Body, &document.URL, &document.Date, &rank); err != nil {
+			return nil, 0, err
+		}
+
+		// bm25() is negative, the better matches being lower
+		results = append(results, newResult(document, -rank, terms))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
+// delete removes the documents with the column (id or type) equal to the value
+func (index *SQLiteIndex) delete(ctx context.Context, tx *sql.Tx, column string, value string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+tableSearchDocumentsFTS+` WHERE rowid IN (SELECT rowid FROM `+TABLE_SEARCH_DOCUMENTS+` WHERE `+column+` = ?)`, value); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, `DELETE FROM `+TABLE_SEARCH_DOCUMENTS+` WHERE `+column+` = ?`, value)
+	return err
+}
+
+func (index *SQLiteIndex) insert(ctx context.Context, tx *sql.Tx, document Document) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO `+TABLE_SEARCH_DOCUMENTS+` (id, type, source_id, language, title, body, url, display_date) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		document.ID, document.Type, document.SourceID, document.Language, document.Title, document.Body, document.URL, document.Date)
+	if err != nil {
+		return err
+	}
+
+	titleTerms, bodyTerms := documentTerms(document, index.language)
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO `+tableSearchDocumentsFTS+` (rowid, title, body) SELECT rowid, ?, ? FROM `+TABLE_SEARCH_DOCUMENTS+` WHERE id = ?`,
+		titleTerms, bodyTerms, document.ID)
+	return err
+}
+
+// sqliteMatchExpression returns the FTS5 query matching all the terms,
+// each quoted so it is never read as an operator (e.g. AND, NOT)
+func sqliteMatchExpression(terms []string) string {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		quoted = append(quoted, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,49 @@
+package searchindex
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func newTestSQLiteDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestSQLiteIndex_Search(t *testing.T) {
+	index, err := NewSQLiteIndex(context.Background(), newTestSQLiteDB(t), "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testIndexSearch(t, index)
+}
+
+func TestNewDatabaseIndex(t *testing.T) {
+	db := newTestSQLiteDB(t)
+
+	index, err := NewDatabaseIndex(context.Background(), db, "sqlite", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Name() != "fts5" {
+		t.Errorf("expected the fts5 index, got %s", index.Name())
+	}
+
+	// the tables already exist
+	if _, err := NewDatabaseIndex(context.Background(), db, "sqlite", "en"); err != nil {
+		t.Errorf("expected no error on the second call, got %v", err)
+	}
+
+	if _, err := NewDatabaseIndex(context.Background(), db, "postgres", "en"); err != ErrNotSupported {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
@@ -0,0 +1,440 @@
+package searchindex
+
+import (
+	"strings"
+)
+
+// Stem returns the stem of the lower case word in the language, so the
+// forms of a word (e.g. run, runs, running) are indexed as one term.
+//
+// Supported: en (Porter), de (CISTEM) and bg (light stemmer by J. Savoy).
+// The words of the other languages are returned as they are.
+func Stem(word string, language string) string {
+	switch normalizeLanguage(language) {
+	case "en":
+		return stemEnglish(word)
+	case "de":
+		return stemGerman(word)
+	case "bg":
+		return stemBulgarian(word)
+	}
+
+	return word
+}
+
+// StemmedLanguages lists the languages with a stemmer
+func StemmedLanguages() []string {
+	return []string{"en", "de", "bg"}
+}
+
+// == ENGLISH (Porter) ========================================================
+
+func stemEnglish(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	b := []byte(word)
+	b = porterStep1a(b)
+	b = porterStep1b(b)
+	b = porterStep1c(b)
+	b = porterStep2(b)
+	b = porterStep3(b)
+	b = porterStep4(b)
+	b = porterStep5(b)
+
+	return string(b)
+}
+
+// porterIsConsonant checks if the letter at i is a consonant, y being
+// a consonant at the start of the word or after a vowel
+func porterIsConsonant(b []byte, i int) bool {
+	switch b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !porterIsConsonant(b, i-1)
+	}
+	return true
+}
+
+// porterMeasure returns the number of vowel-consonant sequences of the stem
+func porterMeasure(b []byte) int {
+	n, i := 0, 0
+
+	for i < len(b) && porterIsConsonant(b, i) {
+		i++
+	}
+
+	for i < len(b) {
+		for i < len(b) && !porterIsConsonant(b, i) {
+			i++
+		}
+		if i >= len(b) {
+			break
+		}
+		for i < len(b) && porterIsConsonant(b, i) {
+			i++
+		}
+		n++
+	}
+
+	return n
+}
+
+func porterHasVowel(b []byte) bool {
+	for i := range b {
+		if !porterIsConsonant(b, i) {
+			return true
+		}
+	}
+	return false
+}
+
+func porterEndsDoubleConsonant(b []byte) bool {
+	l := len(b)
+	return l >= 2 && b[l-1] == b[l-2] && porterIsConsonant(b, l-1)
+}
+
+// porterEndsCVC checks if the stem ends consonant-vowel-consonant,
+// the last consonant not being w, x or y (e.g. hop, not snow)
+func porterEndsCVC(b []byte) bool {
+	l := len(b)
+	if l < 3 || !porterIsConsonant(b, l-3) || porterIsConsonant(b, l-2) || !porterIsConsonant(b, l-1) {
+		return false
+	}
+	return b[l-1] != 'w' && b[l-1] != 'x' && b[l-1] != 'y'
+}
+
+func porterEnds(b []byte, suffix string) bool {
+	return len(b) >= len(suffix) && string(b[len(b)-len(suffix):]) == suffix
+}
+
+// porterReplace replaces the first matching suffix of the rules
+// (suffix, replacement pairs) when the stem measure is over minMeasure
+func porterReplace(b []byte, rules []string, minMeasure int) []byte {
+	for i := 0; i < len(rules); i += 2 {
+		if !porterEnds(b, rules[i]) {
+			continue
+		}
+
+		stem := b[:len(b)-len(rules[i])]
+		if porterMeasure(stem) > minMeasure {
+			return append(stem, rules[i+1]...)
+		}
+		return b
+	}
+	return b
+}
+
+func porterStep1a(b []byte) []byte {
+	switch {
+	case porterEnds(b, "sses"), porterEnds(b, "ies"):
+		return b[:len(b)-2]
+	case porterEnds(b, "ss"):
+		return b
+	case porterEnds(b, "s"):
+		return b[:len(b)-1]
+	}
+	return b
+}
+
+func porterStep1b(b []byte) []byte {
+	if porterEnds(b, "eed") {
+		if porterMeasure(b[:len(b)-3]) > 0 {
+			return b[:len(b)-1]
+		}
+		return b
+	}
+
+	var stem []byte
+	switch {
+	case porterEnds(b, "ed") && porterHasVowel(b[:len(b)-2]):
+		stem = b[:len(b)-2]
+	case porterEnds(b, "ing") && porterHasVowel(b[:len(b)-3]):
+		stem = b[:len(b)-3]
+	default:
+		return b
+	}
+
+	switch {
+	case porterEnds(stem, "at"), porterEnds(stem, "bl"), porterEnds(stem, "iz"):
+		return append(stem, 'e')
+	case porterEndsDoubleConsonant(stem):
+		last := stem[len(stem)-1]
+		if last != 'l' && last != 's' && last != 'z' {
+			return stem[:len(stem)-1]
+		}
+	case porterMeasure(stem) == 1 && porterEndsCVC(stem):
+		return append(stem, 'e')
+	}
+
+	return stem
+}
+
+func porterStep1c(b []byte) []byte {
+	if porterEnds(b, "y") && porterHasVowel(b[:len(b)-1]) {
+		b[len(b)-1] = 'i'
+	}
+	return b
+}
+
+var porterStep2Rules = []string{
+	"ational", "ate", "tional", "tion", "enci", "ence", "anci", "ance",
+	"izer", "ize", "abli", "able", "alli", "al", "entli", "ent", "eli", "e",
+	"ousli", "ous", "ization", "ize", "ation", "ate", "ator", "ate",
+	"alism", "al", "iveness", "ive", "fulness", "ful", "ousness", "ous",
+	"aliti", "al", "iviti", "ive", "biliti", "ble",
+}
+
+func porterStep2(b []byte) []byte {
+	return porterReplace(b, porterStep2Rules, 0)
+}
+
+var porterStep3Rules = []string{
+	"icate", "ic", "ative", "", "alize", "al", "iciti", "ic",
+	"ical", "ic", "ful", "", "ness", "",
+}
+
+func porterStep3(b []byte) []byte {
+	return porterReplace(b, porterStep3Rules, 0)
+}
+
+var porterStep4Suffixes = []string{
+	"ement", "ment", "ance", "ence", "able", "ible", "ant", "ent", "ion",
+	"ism", "ate", "iti", "ous", "ive", "ize", "al", "er", "ic", "ou",
+}
+
+func porterStep4(b []byte) []byte {
+	for _, suffix := range porterStep4Suffixes {
+		if !porterEnds(b, suffix) {
+			continue
+		}
+
+		stem := b[:len(b)-len(suffix)]
+		if suffix == "ion" && (len(stem) == 0 || (stem[len(stem)-1] != 's' && stem[len(stem)-1] != 't')) {
+			continue
+		}
+
+		if porterMeasure(stem) > 1 {
+			return stem
+		}
+		return b
+	}
+	return b
+}
+
+func porterStep5(b []byte) []byte {
+	if porterEnds(b, "e") {
+		stem := b[:len(b)-1]
+		m := porterMeasure(stem)
+		if m > 1 || (m == 1 && !porterEndsCVC(stem)) {
+			b = stem
+		}
+	}
+
+	if porterEnds(b, "ll") && porterMeasure(b) > 1 {
+		b = b[:len(b)-1]
+	}
+
+	return b
+}
+
+// == GERMAN (CISTEM) =========================================================
+
+var germanReplacer = strings.NewReplacer("ü", "u", "ö", "o", "ä", "a", "ß", "ss")
+var germanEncoder = strings.NewReplacer("sch", "$", "ei", "%", "ie", "&")
+var germanDecoder = strings.NewReplacer("$", "sch", "%", "ei", "&", "ie")
+
+func stemGerman(word string) string {
+	if word == "" {
+		return word
+	}
+
+	word = germanReplacer.Replace(word)
+
+	if strings.HasPrefix(word, "ge") && len([]rune(word)) >= 6 {
+		word = word[2:]
+	}
+
+	r := markDoubleRunes([]rune(germanEncoder.Replace(word)))
+
+	for len(r) > 3 {
+		last := r[len(r)-1]
+
+		if len(r) > 5 {
+			suffix := string(r[len(r)-2:])
+			if suffix == "em" || suffix == "er" || suffix == "nd" {
+				r = r[:len(r)-2]
+				continue
+			}
+		}
+
+		if last == 't' || last == 'e' || last == 's' || last == 'n' {
+			r = r[:len(r)-1]
+			continue
+		}
+
+		break
+	}
+
+	return germanDecoder.Replace(string(unmarkDoubleRunes(r)))
+}
+
+// markDoubleRunes replaces the second of two same letters with *,
+// so the stripping sees them as one (e.g. kommen as kom*en)
+func markDoubleRunes(r []rune) []rune {
+	out := make([]rune, 0, len(r))
+	for i := 0; i < len(r); i++ {
+		if i+1 < len(r) && r[i] == r[i+1] {
+			out = append(out, r[i], '*')
+			i++
+			continue
+		}
+		out = append(out, r[i])
+	}
+	return out
+}
+
+// unmarkDoubleRunes reverts markDoubleRunes
+func unmarkDoubleRunes(r []rune) []rune {
+	out := make([]rune, 0, len(r))
+	for i := 0; i < len(r); i++ {
+		if i+1 < len(r) && r[i+1] == '*' {
+			out = append(out, r[i], r[i])
+			i++
+			continue
+		}
+		out = append(out, r[i])
+	}
+	return out
+}
+
+// == BULGARIAN (light stemmer by J. Savoy) ===================================
+
+func stemBulgarian(word string) string {
+	r := []rune(word)
+	n := len(r)
+
+	if n < 4 {
+		return word
+	}
+
+	if n > 5 && runesEndWith(r, n, "ища") {
+		return string(r[:n-3])
+	}
+
+	n = bulgarianRemoveArticle(r, n)
+	n = bulgarianRemovePlural(r, n)
+
+	if n > 3 {
+		if runesEndWith(r, n, "я") {
+			n--
+		}
+		if runesEndWith(r, n, "а") || runesEndWith(r, n, "о") || runesEndWith(r, n, "е") {
+			n--
+		}
+	}
+
+	// -ен to -н, e.g. зелен to зелн
+	if n > 4 && runesEndWith(r, n, "ен") {
+		r[n-2] = 'н'
+		n--
+	}
+
+	// drop the ъ before the last letter, e.g. вятър to вятр
+	if n > 5 && r[n-2] == 'ъ' {
+		r[n-2] = r[n-1]
+		n--
+	}
+
+	return string(r[:n])
+}
+
+func bulgarianRemoveArticle(r []rune, n int) int {
+	if n > 6 && runesEndWith(r, n, "ият") {
+		return n - 3
+	}
+
+	if n > 5 {
+		for _, suffix := range []string{"ът", "то", "те", "та", "ия"} {
+			if runesEndWith(r, n, suffix) {
+				return n - 2
+			}
+		}
+	}
+
+	if n > 4 && runesEndWith(r, n, "ят") {
+		return n - 2
+	}
+
+	return n
+}
+
+func bulgarianRemovePlural(r []rune, n int) int {
+	if n > 6 {
+		if runesEndWith(r, n, "овци") {
+			return n - 3
+		}
+		if runesEndWith(r, n, "ове") {
+			return n - 3
+		}
+		if runesEndWith(r, n, "еве") {
+			r[n-3] = 'й'
+			return n - 2
+		}
+	}
+
+	if n > 5 {
+		if runesEndWith(r, n, "ища") {
+			return n - 3
+		}
+		if runesEndWith(r, n, "та") {
+			return n - 2
+		}
+		if runesEndWith(r, n, "ци") {
+			r[n-2] = 'к'
+			return n - 1
+		}
+		if runesEndWith(r, n, "зи") {
+			r[n-2] = 'г'
+			return n - 1
+		}
+		if r[n-3] == 'е' && r[n-1] == 'и' {
+			r[n-3] = 'я'
+			return n - 1
+		}
+	}
+
+	if n > 4 {
+		if runesEndWith(r, n, "си") {
+			r[n-2] = 'х'
+			return n - 1
+		}
+		if runesEndWith(r, n, "и") {
+			return n - 1
+		}
+	}
+
+	return n
+}
+
+// runesEndWith checks if the first n runes end with the suffix
+func runesEndWith(r []rune, n int, suffix string) bool {
+	s := []rune(suffix)
+	if len(s) > n {
+		return false
+	}
+	for i := range s {
+		if r[n-len(s)+i] != s[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package searchindex
+
+import "testing"
+
+func TestStem_English(t *testing.T) {
+	tests := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"cats":           "cat",
+		"running":        "run",
+		"hopping":        "hop",
+		"agreed":         "agre",
+		"relational":     "relat",
+		"generalization": "gener",
+		"searching":      "search",
+		"searched":       "search",
+		"connections":    "connect",
+		"happy":          "happi",
+		"by":             "by",
+	}
+
+	for word, expected := range tests {
+		if result := Stem(word, "en"); result != expected {
+			t.Errorf("Stem(%q, en) = %q, expected %q", word, result, expected)
+		}
+	}
+}
+
+func TestStem_German(t *testing.T) {
+	tests := map[string]string{
+		"häuser": "hau",
+		"haus":   "hau",
+		"laufen": "lauf",
+		"läuft":  "lauf",
+		"kommen": "komm",
+	}
+
+	for word, expected := range tests {
+		if result := Stem(word, "de"); result != expected {
+			t.Errorf("Stem(%q, de) = %q, expected %q", word, result, expected)
+		}
+	}
+}
+
+func TestStem_Bulgarian(t *testing.T) {
+	// the article and plural forms stem like the word
+	groups := [][]string{
+		{"град", "града", "градът", "градове"},
+		{"книга", "книгата", "книги"},
+	}
+
+	for _, group := range groups {
+		expected := Stem(group[0], "bg")
+		for _, word := range group[1:] {
+			if result := Stem(word, "bg"); result != expected {
+				t.Errorf("Stem(%q, bg) = %q, expected %q", word, result, expected)
+			}
+		}
+	}
+}
+
+func TestStem_UnknownLanguage(t *testing.T) {
+	if result := Stem("running", "xx"); result != "running" {
+		t.Errorf("expected the word unchanged, got %q", result)
+	}
+}
@@ -0,0 +1,146 @@
+// Package signedurl signs the URLs of the private files (e.g. invoices,
+// paid downloads, user uploads), which are only served with a valid
+// signature.
+//
+// The signature is an HMAC-SHA256 of the path of the file, the time the
+// URL expires and, optionally, the ID of the only user allowed to use it.
+// It is sent in the query of the URL, so the same path of a file can be
+// signed for different users and times.
+package signedurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The query parameters of the signed URLs
+const (
+	QUERY_EXPIRES   = "expires"
+	QUERY_USER      = "user"
+	QUERY_SIGNATURE = "signature"
+)
+
+// signatureVersion prefixes the signed message, so the format can change
+// without the old signatures being accepted for the new one
+const signatureVersion = "v1"
+
+var (
+	// ErrKeyMissing is returned when no signing key is configured
+	ErrKeyMissing = errors.New("the signing key is not configured")
+	// ErrSignatureMissing is returned for the URLs without a signature
+	ErrSignatureMissing = errors.New("the link is not signed")
+	// ErrSignatureInvalid is returned for the URLs with a wrong signature,
+	// e.g. of another file or with a changed expiry
+	ErrSignatureInvalid = errors.New("the link is not valid")
+	// ErrExpired is returned for the URLs used after they expired
+	ErrExpired = errors.New("the link has expired")
+	// ErrUserMismatch is returned for the URLs of a user, used by another
+	// user or without logging in
+	ErrUserMismatch = errors.New("the link is for another user, please log in")
+)
+
+// Sign returns the query parameters signing the path of the file until
+// the time, for the user only when the user ID is set.
+//
+// Example:
+//
+//	query, err := signedurl.Sign(key, "/private/invoices/INV-1.pdf", time.Now().Add(time.Hour), userID)
+func Sign(key string, filePath string, expiresAt time.Time, userID string) (url.Values, error) {
+	if key == "" {
+		return nil, ErrKeyMissing
+	}
+
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query := url.Values{}
+	query.Set(QUERY_EXPIRES, expires)
+
+	if userID != "" {
+		query.Set(QUERY_USER, userID)
+	}
+
+	query.Set(QUERY_SIGNATURE, signature(key, CleanPath(filePath), expires, userID))
+
+	return query, nil
+}
+
+// Verify checks the signature of the URL of the file, from its query, at
+// the time. The authenticated user ID is the ID of the user requesting
+// the file, empty for the guests.
+func Verify(key string, filePath string, query url.Values, authUserID string, now time.Time) error {
+	if key == "" {
+		return ErrKeyMissing
+	}
+
+	sent := query.Get(QUERY_SIGNATURE)
+	expires := query.Get(QUERY_EXPIRES)
+
+	if sent == "" || expires == "" {
+		return ErrSignatureMissing
+	}
+
+	userID := query.Get(QUERY_USER)
+	expected := signature(key, CleanPath(filePath), expires, userID)
+
+	if !hmac.Equal([]byte(sent), []byte(expected)) {
+		return ErrSignatureInvalid
+	}
+
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrSignatureInvalid
+	}
+
+	if now.Unix() > expiresUnix {
+		return ErrExpired
+	}
+
+	if userID != "" && userID != authUserID {
+		return ErrUserMismatch
+	}
+
+	return nil
+}
+
+// IsPrivate returns whether the file is in one of the private folders
+// (e.g. "/private"), or in one of their sub folders. The folders are
+// compared case insensitively
+func IsPrivate(filePath string, privatePaths []string) bool {
+	filePath = strings.ToLower(CleanPath(filePath))
+
+	for _, privatePath := range privatePaths {
+		privatePath = strings.ToLower(CleanPath(privatePath))
+
+		if privatePath == "/" {
+			return true
+		}
+
+		if filePath == privatePath || strings.HasPrefix(filePath, privatePath+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CleanPath returns the path of the file starting with a slash, without
+// the "." and ".." elements, so "/public/../private/a.pdf" is checked and
+// signed as "/private/a.pdf"
+func CleanPath(filePath string) string {
+	return path.Clean("/" + strings.TrimSpace(filePath))
+}
+
+// signature returns the HMAC of the path, the expiry and the user ID
+func signature(key string, filePath string, expires string, userID string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(signatureVersion + "\n" + filePath + "\n" + expires + "\n" + userID))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,128 @@
+package signedurl
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const testKey = "test-signing-key"
+
+func TestSignVerify(t *testing.T) {
+	now := time.Now()
+
+	query, err := Sign(testKey, "/private/invoice.pdf", now.Add(time.Hour), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(testKey, "/private/invoice.pdf", query, "", now); err != nil {
+		t.Fatalf("expected the signature to be valid, got %v", err)
+	}
+
+	// The path is cleaned the same way on both sides
+	if err := Verify(testKey, "private/./invoice.pdf", query, "", now); err != nil {
+		t.Fatalf("expected the signature of the cleaned path to be valid, got %v", err)
+	}
+}
+
+func TestVerify_Errors(t *testing.T) {
+	now := time.Now()
+
+	query, err := Sign(testKey, "/private/invoice.pdf", now.Add(time.Hour), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered, _ := Sign(testKey, "/private/invoice.pdf", now.Add(time.Hour), "")
+	tampered.Set(QUERY_EXPIRES, "9999999999")
+
+	tests := []struct {
+		name     string
+		key      string
+		filePath string
+		query    map[string][]string
+		now      time.Time
+		expected error
+	}{
+		{"no key", "", "/private/invoice.pdf", query, now, ErrKeyMissing},
+		{"not signed", testKey, "/private/invoice.pdf", map[string][]string{}, now, ErrSignatureMissing},
+		{"other file", testKey, "/private/other.pdf", query, now, ErrSignatureInvalid},
+		{"other key", "other-key", "/private/invoice.pdf", query, now, ErrSignatureInvalid},
+		{"changed expiry", testKey, "/private/invoice.pdf", tampered, now, ErrSignatureInvalid},
+		{"expired", testKey, "/private/invoice.pdf", query, now.Add(2 * time.Hour), ErrExpired},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Verify(test.key, test.filePath, test.query, "", test.now)
+
+			if !errors.Is(err, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestVerify_UserBinding(t *testing.T) {
+	now := time.Now()
+
+	query, err := Sign(testKey, "/private/upload.png", now.Add(time.Hour), "user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(testKey, "/private/upload.png", query, "user-1", now); err != nil {
+		t.Fatalf("expected the user to be allowed, got %v", err)
+	}
+
+	if err := Verify(testKey, "/private/upload.png", query, "user-2", now); !errors.Is(err, ErrUserMismatch) {
+		t.Fatalf("expected another user to be refused, got %v", err)
+	}
+
+	if err := Verify(testKey, "/private/upload.png", query, "", now); !errors.Is(err, ErrUserMismatch) {
+		t.Fatalf("expected a guest to be refused, got %v", err)
+	}
+
+	// Removing the user from the URL breaks the signature
+	query.Del(QUERY_USER)
+
+	if err := Verify(testKey, "/private/upload.png", query, "", now); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("expected the URL without its user to be invalid, got %v", err)
+	}
+}
+
+func TestSign_NoKey(t *testing.T) {
+	if _, err := Sign("", "/private/invoice.pdf", time.Now(), ""); !errors.Is(err, ErrKeyMissing) {
+		t.Fatalf("expected ErrKeyMissing, got %v", err)
+	}
+}
+
+func TestIsPrivate(t *testing.T) {
+	privatePaths := []string{"/private", "invoices/"}
+
+	tests := map[string]bool{
+		"/private":                 true,
+		"/private/a.pdf":           true,
+		"/Private/a.pdf":           true,
+		"/invoices/2026/INV-1.pdf": true,
+		"/public/../private/a.pdf": true,
+		"/privateer/a.pdf":         false,
+		"/public/a.pdf":            false,
+		"/a.pdf":                   false,
+	}
+
+	for filePath, expected := range tests {
+		if got := IsPrivate(filePath, privatePaths); got != expected {
+			t.Errorf("IsPrivate(%q) = %v, want %v", filePath, got, expected)
+		}
+	}
+
+	if IsPrivate("/private/a.pdf", nil) {
+		t.Error("expected no file to be private without private folders")
+	}
+
+	if !IsPrivate("/a.pdf", []string{"/"}) {
+		t.Error("expected all the files to be private with the root folder")
+	}
+}
@@ -29,6 +29,14 @@ const (
 	// HelloWorldTaskAlias is the alias for the hello world task.
 	HelloWorldTaskAlias = "HelloWorldTask"
 
+	// StockReservationReleaseTaskAlias is the alias for the task releasing
+	// the stock reserved for unpaid orders.
+	StockReservationReleaseTaskAlias = "StockReservationReleaseTask"
+
+	// SearchReindexTaskAlias is the alias for the task rebuilding the
+	// search index of the pages, blog posts and products.
+	SearchReindexTaskAlias = "SearchReindexTask"
+
 	// StatsVisitorEnhanceTaskAlias is the alias for the stats visitor
 	// enhancement task.
 	StatsVisitorEnhanceTaskAlias = "StatsVisitorEnhanceTask"
@@ -10,7 +10,9 @@ import (
 	"project/internal/tasks/email_admin_new_user_registered"
 	"project/internal/tasks/email_test"
 	"project/internal/tasks/hello_world"
+	"project/internal/tasks/search_reindex"
 	"project/internal/tasks/stats"
+	"project/internal/tasks/stock_reservation_release"
 
 	"github.com/dracory/taskstore"
 )
@@ -35,7 +37,9 @@ func RegisterTasks(app app.AppInterface) {
 		email_admin_new_contact.NewEmailToAdminOnNewContactFormSubmittedTaskHandler(app),
 		email_admin_new_user_registered.NewEmailToAdminOnNewUserRegisteredTaskHandler(app),
 		hello_world.NewHelloWorldTask(app),
+		search_reindex.NewSearchReindexTask(app),
 		stats.NewStatsVisitorEnhanceTask(app),
+		stock_reservation_release.NewStockReservationReleaseTask(app),
 	}
 
 	for _, task := range tasks {
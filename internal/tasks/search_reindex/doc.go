@@ -0,0 +1,3 @@
+// Package search_reindex implements the task rebuilding the search index
+// of the pages, blog posts and products from their stores.
+package search_reindex
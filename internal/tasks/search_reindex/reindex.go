@@ -0,0 +1,132 @@
+package search_reindex
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"project/internal/app"
+	"project/internal/searchindex"
+)
+
+// Reindex replaces the documents of the types in the search index of the
+// app with the content of their stores. The types of the stores not in use
+// are skipped
+func Reindex(ctx context.Context, app app.AppInterface, types ...string) error {
+	if app == nil {
+		return errors.New("app is nil")
+	}
+
+	if app.GetSearchIndex() == nil {
+		return errors.New("search index is nil")
+	}
+
+	return searchindex.Reindex(ctx, app.GetSearchIndex(), sources(app, types)...)
+}
+
+// Sync updates the documents of the type with the source IDs in the search
+// index of the app, after they are changed: the searchable ones (e.g. the
+// published posts) are upserted, the others removed
+func Sync(ctx context.Context, app app.AppInterface, documentType string, sourceIDs ...string) error {
+	if app == nil {
+		return errors.New("app is nil")
+	}
+
+	if app.GetSearchIndex() == nil {
+		return errors.New("search index is nil")
+	}
+
+	for _, source := range sources(app, []string{documentType}) {
+		if err := searchindex.Sync(ctx, app.GetSearchIndex(), source, sourceIDs...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Changed keeps the search index in sync after the documents of the type
+// with the source IDs are created, updated or deleted. When the changed
+// documents are not known (e.g. after a bulk action of an admin page),
+// all the documents of the type are refreshed in the background, see
+// Refresh. The errors are logged
+func Changed(ctx context.Context, app app.AppInterface, documentType string, sourceIDs ...string) {
+	if app == nil || app.GetSearchIndex() == nil {
+		return
+	}
+
+	if len(sourceIDs) == 0 {
+		Refresh(app, documentType)
+		return
+	}
+
+	if err := Sync(ctx, app, documentType, sourceIDs...); err != nil {
+		app.GetLogger().Error("At search_reindex > Changed", slog.String("type", documentType), slog.String("error", err.Error()))
+	}
+}
+
+// sources returns the sources of the types, for the stores in use
+func sources(app app.AppInterface, types []string) []searchindex.SourceInterface {
+	cfg := app.GetConfig()
+	language := cfg.GetTranslationLanguageDefault()
+	list := []searchindex.SourceInterface{}
+
+	if slices.Contains(types, searchindex.DOCUMENT_TYPE_PAGE) && cfg.GetCmsStoreUsed() && app.GetCmsStore() != nil {
+		list = append(list, searchindex.NewPageSource(app.GetCmsStore(), language))
+	}
+
+	if slices.Contains(types, searchindex.DOCUMENT_TYPE_POST) && cfg.GetBlogStoreUsed() && app.GetBlogStore() != nil {
+		list = append(list, searchindex.NewPostSource(app.GetBlogStore(), language))
+	}
+
+	if slices.Contains(types, searchindex.DOCUMENT_TYPE_PRODUCT) && cfg.GetShopStoreUsed() && app.GetShopStore() != nil {
+		list = append(list, searchindex.NewProductSource(app.GetShopStore(), language))
+	}
+
+	return list
+}
+
+// refreshes tracks the background refreshes of the types
+var refreshes = struct {
+	sync.Mutex
+	// running the types being refreshed, true when changed again since
+	running map[string]bool
+}{running: map[string]bool{}}
+
+// Refresh reindexes all the documents of the type in the background, after
+// the content is changed and the changed documents are not known (see
+// Changed). The changes made during a refresh are picked up by one more
+// run, so a burst of edits does not start a reindex for each
+func Refresh(app app.AppInterface, documentType string) {
+	if app == nil || app.GetSearchIndex() == nil {
+		return
+	}
+
+	refreshes.Lock()
+	if _, running := refreshes.running[documentType]; running {
+		refreshes.running[documentType] = true
+		refreshes.Unlock()
+		return
+	}
+	refreshes.running[documentType] = false
+	refreshes.Unlock()
+
+	go func() {
+		for {
+			if err := Reindex(context.Background(), app, documentType); err != nil {
+				app.GetLogger().Error("At search_reindex > Refresh", slog.String("type", documentType), slog.String("error", err.Error()))
+			}
+
+			refreshes.Lock()
+			if !refreshes.running[documentType] {
+				delete(refreshes.running, documentType)
+				refreshes.Unlock()
+				return
+			}
+			refreshes.running[documentType] = false
+			refreshes.Unlock()
+		}
+	}()
+}
@@ -0,0 +1,125 @@
+package search_reindex
+
+import (
+	"context"
+	"errors"
+	"project/internal/app"
+	"project/internal/searchindex"
+	"project/internal/tasks/constants"
+	"slices"
+	"strings"
+
+	"github.com/dracory/taskstore"
+)
+
+const SearchReindexAll = "all"
+
+// ============================================================================
+// searchReindexTask
+// ============================================================================
+// Replaces the documents of the search index with the current content of the
+// stores. The type selects the documents to rebuild, all by default.
+// ============================================================================
+// Example:
+// - go run ./cmd/server task SearchReindexTask
+// - go run ./cmd/server task SearchReindexTask --type=post
+// ============================================================================
+type searchReindexTask struct {
+	taskstore.TaskHandlerBase
+
+	app app.AppInterface
+}
+
+var _ taskstore.TaskHandlerInterface = (*searchReindexTask)(nil) // verify it extends the task interface
+
+// == CONSTRUCTOR =============================================================
+
+func NewSearchReindexTask(app app.AppInterface) *searchReindexTask {
+	return &searchReindexTask{
+		app: app,
+	}
+}
+
+// == IMPLEMENTATION ==========================================================
+
+func (t *searchReindexTask) Alias() string {
+	return constants.SearchReindexTaskAlias
+}
+
+func (t *searchReindexTask) Title() string {
+	return "Search Reindex"
+}
+
+func (t *searchReindexTask) Description() string {
+	return "Rebuilds the search index of the pages, blog posts and products"
+}
+
+func (t *searchReindexTask) Enqueue(documentType string) (task taskstore.TaskQueueInterface, err error) {
+	if t.app == nil {
+		return nil, errors.New("app is nil")
+	}
+
+	if t.app.GetTaskStore() == nil {
+		return nil, errors.New("task store is nil")
+	}
+
+	return t.app.GetTaskStore().TaskDefinitionEnqueueByAlias(
+		context.Background(),
+		taskstore.DefaultQueueName,
+		t.Alias(),
+		map[string]any{
+			"type": documentType,
+		},
+	)
+}
+
+func (t *searchReindexTask) Handle() bool {
+	if t.app == nil {
+		t.LogError("App is nil; skipping SearchReindexTask run.")
+		return false
+	}
+
+	documentType := strings.TrimSpace(t.GetParam("type"))
+	if documentType == "" {
+		documentType = SearchReindexAll
+	}
+
+	allowedTypes := append([]string{SearchReindexAll}, searchindex.DocumentTypes()...)
+	if !slices.Contains(allowedTypes, documentType) {
+		t.LogError("Invalid type: '" + documentType + "'. Must be one of: '" + strings.Join(allowedTypes, "', '") + "'. Aborted.")
+		return false
+	}
+
+	if !t.HasQueuedTask() && t.GetParam("enqueue") == "yes" {
+		_, err := t.Enqueue(documentType)
+
+		if err != nil {
+			t.LogError("Error enqueuing task: " + err.Error())
+		} else {
+			t.LogSuccess("Task enqueued.")
+		}
+
+		return true
+	}
+
+	if t.app.GetSearchIndex() == nil {
+		t.LogInfo("Search index not configured; skipping SearchReindexTask run.")
+		return true
+	}
+
+	types := searchindex.DocumentTypes()
+	if documentType != SearchReindexAll {
+		types = []string{documentType}
+	}
+
+	t.LogInfo("Rebuilding the " + t.app.GetSearchIndex().Name() + " search index: " + strings.Join(types, ", "))
+
+	if err := Reindex(context.Background(), t.app, types...); err != nil {
+		t.LogError("Error rebuilding the search index: " + err.Error())
+		return false
+	}
+
+	t.LogInfo("Search index rebuilt successfully.")
+
+	return true
+}
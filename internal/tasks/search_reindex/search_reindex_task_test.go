@@ -0,0 +1,160 @@
+package search_reindex
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"project/internal/searchindex"
+	"project/internal/tasks/constants"
+	"project/internal/testutils"
+
+	"github.com/dracory/blogstore"
+)
+
+func TestSearchReindexTask_Metadata(t *testing.T) {
+	app := testutils.Setup()
+	handler := NewSearchReindexTask(app)
+
+	if got, want := handler.Alias(), constants.SearchReindexTaskAlias; got != want {
+		t.Fatalf("Alias() = %q, want %q", got, want)
+	}
+
+	if got, want := handler.Title(), "Search Reindex"; got != want {
+		t.Fatalf("Title() = %q, want %q", got, want)
+	}
+}
+
+func TestSearchReindexTask_Enqueue_AppNil(t *testing.T) {
+	handler := &searchReindexTask{}
+
+	if _, err := handler.Enqueue(SearchReindexAll); err == nil {
+		t.Fatalf("expected error when app is nil, got nil")
+	}
+}
+
+func TestSearchReindexTask_Handle_InvalidType(t *testing.T) {
+	app := testutils.Setup(testutils.WithTaskStore(true))
+	task := NewSearchReindexTask(app)
+
+	if err := app.GetTaskStore().TaskHandlerAdd(context.Background(), task, true); err != nil {
+		t.Fatalf("TaskHandlerAdd() expected nil error, got %v", err)
+	}
+
+	queuedTask, err := task.Enqueue("invalid_type")
+	if err != nil {
+		t.Fatalf("Enqueue() expected nil error, got %v", err)
+	}
+
+	task.SetQueuedTask(queuedTask)
+
+	if ok := task.Handle(); ok {
+		t.Error("Handle() should return false for invalid type")
+	}
+}
+
+func TestSearchReindexTask_Handle_IndexesPublishedPosts(t *testing.T) {
+	app := testutils.Setup(testutils.WithBlogStore(true))
+	ctx := context.Background()
+
+	published := blogstore.NewPost()
+	published.SetTitle("Published gardening tips")
+	published.SetContent("<p>Water the plants</p>")
+	published.SetStatus(blogstore.POST_STATUS_PUBLISHED)
+
+	draft := blogstore.NewPost()
+	draft.SetTitle("Draft gardening notes")
+	draft.SetStatus(blogstore.POST_STATUS_DRAFT)
+
+	for _, post := range []blogstore.PostInterface{published, draft} {
+		if err := app.GetBlogStore().PostCreate(ctx, post); err != nil {
+			t.Fatalf("Failed to create test post: %v", err)
+		}
+	}
+
+	if ok := NewSearchReindexTask(app).Handle(); !ok {
+		t.Fatalf("expected Handle() to succeed")
+	}
+
+	results, total, err := app.GetSearchIndex().Search(ctx, searchindex.Query{Text: "gardening"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 1 || results[0].SourceID != published.GetID() {
+		t.Fatalf("expected only the published post, got %d results", total)
+	}
+
+	// a post unpublished since is removed by the next reindex
+	published.SetStatus(blogstore.POST_STATUS_DRAFT)
+	if err := app.GetBlogStore().PostUpdate(ctx, published); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Reindex(ctx, app, searchindex.DOCUMENT_TYPE_POST); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, total, _ := app.GetSearchIndex().Search(ctx, searchindex.Query{Text: "gardening"}); total != 0 {
+		t.Errorf("expected the unpublished post to be removed, got %d results", total)
+	}
+}
+
+func TestRefresh_IndexesInTheBackground(t *testing.T) {
+	// nil app, should not panic
+	Refresh(nil, searchindex.DOCUMENT_TYPE_POST)
+
+	app := testutils.Setup(testutils.WithBlogStore(true))
+	ctx := context.Background()
+
+	post := blogstore.NewPost()
+	post.SetTitle("Refreshed birdwatching post")
+	post.SetStatus(blogstore.POST_STATUS_PUBLISHED)
+
+	if err := app.GetBlogStore().PostCreate(ctx, post); err != nil {
+		t.Fatalf("Failed to create test post: %v", err)
+	}
+
+	Refresh(app, searchindex.DOCUMENT_TYPE_POST)
+	Refresh(app, searchindex.DOCUMENT_TYPE_POST)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, total, _ := app.GetSearchIndex().Search(ctx, searchindex.Query{Text: "birdwatching"}); total == 1 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Error("expected the post to be indexed by the refresh")
+}
+
+func TestChanged_SyncsTheDocument(t *testing.T) {
+	app := testutils.Setup(testutils.WithBlogStore(true))
+	ctx := context.Background()
+
+	post := blogstore.NewPost()
+	post.SetTitle("Changed beekeeping post")
+	post.SetStatus(blogstore.POST_STATUS_PUBLISHED)
+
+	if err := app.GetBlogStore().PostCreate(ctx, post); err != nil {
+		t.Fatalf("Failed to create test post: %v", err)
+	}
+
+	Changed(ctx, app, searchindex.DOCUMENT_TYPE_POST, post.GetID())
+
+	if _, total, _ := app.GetSearchIndex().Search(ctx, searchindex.Query{Text: "beekeeping"}); total != 1 {
+		t.Fatalf("expected the post to be indexed, got %d results", total)
+	}
+
+	post.SetStatus(blogstore.POST_STATUS_DRAFT)
+	if err := app.GetBlogStore().PostUpdate(ctx, post); err != nil {
+		t.Fatal(err)
+	}
+
+	Changed(ctx, app, searchindex.DOCUMENT_TYPE_POST, post.GetID())
+
+	if _, total, _ := app.GetSearchIndex().Search(ctx, searchindex.Query{Text: "beekeeping"}); total != 0 {
+		t.Errorf("expected the unpublished post to be removed, got %d results", total)
+	}
+}
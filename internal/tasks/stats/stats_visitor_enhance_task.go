@@ -3,17 +3,15 @@ package stats
 import (
 	"context"
 	"errors"
-	"io"
 	"log"
 	"net/http"
 	"project/internal/app"
+	"project/internal/helpers"
 	"project/internal/tasks/constants"
-	"strings"
 	"time"
 
 	"github.com/dracory/statsstore"
 	"github.com/dracory/taskstore"
-	"github.com/mileusna/useragent"
 	"github.com/spf13/cast"
 )
 
@@ -119,37 +117,17 @@ func (t *statsVisitorEnhanceTask) processVisitor(ctx context.Context, visitor st
 		t.LogError("Task StatsVisitorEnhance. Store is nil")
 		return false
 	}
-	ua := useragent.Parse(visitor.GetUserAgent())
-	userOs := ua.OS
-	userOsVersion := ua.OSVersion
-	userDevice := ua.Device
-	userBrowser := ua.Name
-	userBrowserVersion := ua.Version
-
-	userDeviceType := ""
-
-	if ua.Mobile {
-		userDeviceType = "mobile"
-	}
-	if ua.Tablet {
-		userDeviceType = "tablet"
-	}
-	if ua.Desktop {
-		userDeviceType = "desktop"
-	}
-	if ua.Bot {
-		userDeviceType = "bot"
-	}
+	ua := helpers.UserAgentParse(visitor.GetUserAgent())
 
 	country := t.findCountryByIp(ctx, visitor.GetIpAddress())
 
 	visitor.SetCountry(country)
-	visitor.SetUserBrowser(userBrowser)
-	visitor.SetUserBrowserVersion(userBrowserVersion)
-	visitor.SetUserDevice(userDevice)
-	visitor.SetUserDeviceType(userDeviceType)
-	visitor.SetUserOs(userOs)
-	visitor.SetUserOsVersion(userOsVersion)
+	visitor.SetUserBrowser(ua.Browser)
+	visitor.SetUserBrowserVersion(ua.BrowserVersion)
+	visitor.SetUserDevice(ua.Device)
+	visitor.SetUserDeviceType(ua.DeviceType)
+	visitor.SetUserOs(ua.OS)
+	visitor.SetUserOsVersion(ua.OSVersion)
 
 	errUpdated := t.app.GetStatsStore().VisitorUpdate(ctx, visitor)
 
@@ -162,61 +140,14 @@ func (t *statsVisitorEnhanceTask) processVisitor(ctx context.Context, visitor st
 }
 
 func (t *statsVisitorEnhanceTask) findCountryByIp(ctx context.Context, ip string) string {
-	if ip == "" || ip == "127.0.0.1" {
-		return "UN"
-	}
-
 	timeoutCtx, cancel := context.WithTimeout(ctx, ipLookupTimeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(timeoutCtx, http.MethodGet, ipLookupEndpoint+ip, nil)
-	if err != nil {
-		log.Printf("Creating geo lookup request failed: %s", err)
-		return "ER"
-	}
-
 	// Use injected client for testing, otherwise use global client
 	client := t.httpClient
 	if client == nil {
 		client = ipLookupHTTPClient
 	}
 
-	resp, err := client.Do(req)
-
-	if err != nil {
-		log.Printf("Request Failed: %s", err)
-		return "ER" // error
-	}
-
-	if resp == nil {
-		return "UN"
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Geo lookup returned status code %d", resp.StatusCode)
-		return "ER"
-	}
-
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Printf("Closing response body failed: %s", err)
-		}
-	}()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Printf("Reading geo lookup response failed: %s", err)
-		return "ER"
-	}
-
-	parts := strings.Split(string(body), ";")
-	if len(parts) > 2 {
-		code := strings.TrimSpace(parts[1])
-		if code == "" {
-			return "UN"
-		}
-		return code
-	}
-
-	return "UN"
+	return helpers.CountryByIP(timeoutCtx, client, ipLookupEndpoint, ip)
 }
@@ -0,0 +1,4 @@
+// Package stock_reservation_release implements the task releasing the stock
+// reserved, and the uses of the discount codes held, for orders whose
+// payment never completed.
+package stock_reservation_release
@@ -0,0 +1,115 @@
+package stock_reservation_release
+
+import (
+	"context"
+	"errors"
+	"project/internal/app"
+	"project/internal/discount"
+	"project/internal/inventory"
+	"project/internal/tasks/constants"
+	"time"
+
+	"github.com/dracory/taskstore"
+	"github.com/spf13/cast"
+)
+
+func NewStockReservationReleaseTask(app app.AppInterface) taskstore.TaskHandlerInterface {
+	return &stockReservationReleaseTask{
+		app: app,
+	}
+}
+
+type stockReservationReleaseTask struct {
+	taskstore.TaskHandlerBase
+	app app.AppInterface
+}
+
+var _ taskstore.TaskHandlerInterface = (*stockReservationReleaseTask)(nil) // verify it extends the task interface
+
+func (t *stockReservationReleaseTask) Alias() string {
+	return constants.StockReservationReleaseTaskAlias
+}
+
+func (t *stockReservationReleaseTask) Title() string {
+	return "Stock Reservation Release"
+}
+
+func (t *stockReservationReleaseTask) Description() string {
+	return "Release the stock and the discount codes held for unpaid orders"
+}
+
+func (t *stockReservationReleaseTask) Enqueue() (task taskstore.TaskQueueInterface, err error) {
+	if t.app == nil {
+		return nil, errors.New("app is nil")
+	}
+
+	if t.app.GetTaskStore() == nil {
+		return nil, errors.New("task store is nil")
+	}
+
+	return t.app.GetTaskStore().TaskDefinitionEnqueueByAlias(
+		context.Background(),
+		taskstore.DefaultQueueName,
+		t.Alias(),
+		map[string]any{},
+	)
+}
+
+func (t *stockReservationReleaseTask) Handle() bool {
+	if t.app == nil {
+		t.LogError("App is nil; skipping StockReservationReleaseTask run.")
+		return false
+	}
+
+	if !t.HasQueuedTask() && t.GetParam("enqueue") == "yes" {
+		_, err := t.Enqueue()
+
+		if err != nil {
+			t.LogError("Error enqueuing task: " + err.Error())
+		} else {
+			t.LogSuccess("Task enqueued.")
+		}
+
+		return true
+	}
+
+	// The reservations and the discount uses are held in the custom store
+	if t.app.GetCustomStore() == nil {
+		t.LogInfo("CustomStore not configured; skipping StockReservationReleaseTask run.")
+		return true
+	}
+
+	reservationStore, err := inventory.NewStore(t.app.GetCustomStore())
+
+	if err != nil {
+		t.LogError("Error creating the reservation store: " + err.Error())
+		return false
+	}
+
+	released, err := inventory.ReleaseExpired(reservationStore, time.Now().UTC())
+
+	if err != nil {
+		t.LogError("Error releasing stock reservations: " + err.Error())
+		return false
+	}
+
+	t.LogInfo("Released " + cast.ToString(released) + " expired stock reservations.")
+
+	discountStore, err := discount.NewStore(t.app.GetCustomStore())
+
+	if err != nil {
+		t.LogError("Error creating the discount store: " + err.Error())
+		return false
+	}
+
+	releasedUses, err := discountStore.UsageReleaseExpired(time.Now().UTC())
+
+	if err != nil {
+		t.LogError("Error releasing discount code uses: " + err.Error())
+		return false
+	}
+
+	t.LogInfo("Released " + cast.ToString(releasedUses) + " expired discount code uses.")
+
+	return true
+}
@@ -0,0 +1,102 @@
+package stock_reservation_release
+
+import (
+	"testing"
+	"time"
+
+	"project/internal/discount"
+	"project/internal/inventory"
+	"project/internal/tasks/constants"
+	"project/internal/testutils"
+)
+
+func TestStockReservationReleaseTask_Metadata(t *testing.T) {
+	app := testutils.Setup()
+	handler := NewStockReservationReleaseTask(app)
+
+	if got, want := handler.Alias(), constants.StockReservationReleaseTaskAlias; got != want {
+		t.Fatalf("Alias() = %q, want %q", got, want)
+	}
+
+	if got, want := handler.Title(), "Stock Reservation Release"; got != want {
+		t.Fatalf("Title() = %q, want %q", got, want)
+	}
+}
+
+func TestStockReservationReleaseTask_Enqueue_AppNil(t *testing.T) {
+	handler := &stockReservationReleaseTask{}
+
+	if _, err := handler.Enqueue(); err == nil {
+		t.Fatalf("expected error when app is nil, got nil")
+	}
+}
+
+func TestStockReservationReleaseTask_Handle_CustomStoreNil(t *testing.T) {
+	cfg := testutils.DefaultConf()
+	cfg.SetCustomStoreUsed(false)
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	if ok := NewStockReservationReleaseTask(app).Handle(); !ok {
+		t.Fatalf("expected Handle() to skip gracefully without the custom store")
+	}
+}
+
+func TestStockReservationReleaseTask_Handle_ReleasesExpired(t *testing.T) {
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	store, err := inventory.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired := inventory.Reservation{
+		OrderID:   "order_1",
+		ProductID: "product_1",
+		Quantity:  1,
+		Status:    inventory.RESERVATION_STATUS_ACTIVE,
+		ExpiresAt: time.Now().UTC().Add(-time.Minute),
+	}
+
+	if err := store.ReservationCreate(&expired); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok := NewStockReservationReleaseTask(app).Handle(); !ok {
+		t.Fatalf("expected Handle() to succeed")
+	}
+
+	reservations, err := store.ReservationListByOrder("order_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reservations) != 1 || reservations[0].Status != inventory.RESERVATION_STATUS_RELEASED {
+		t.Errorf("expected the expired reservation to be released, got %+v", reservations)
+	}
+}
+
+func TestStockReservationReleaseTask_Handle_ReleasesExpiredDiscountUses(t *testing.T) {
+	app := testutils.Setup(testutils.WithCustomStore(true))
+
+	store, err := discount.NewStore(app.GetCustomStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limited := discount.Discount{Code: "ONCE", Status: discount.STATUS_ACTIVE, Type: discount.TYPE_PERCENT, Value: "10", UsageLimit: 1}
+	if err := store.DiscountCreate(&limited); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.UsageClaim(limited.ID, "user_1", "order_1", time.Now().UTC().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok := NewStockReservationReleaseTask(app).Handle(); !ok {
+		t.Fatalf("expected Handle() to succeed")
+	}
+
+	if err := store.UsageClaim(limited.ID, "user_2", "order_2", time.Now().UTC().Add(time.Hour)); err != nil {
+		t.Errorf("expected the expired use to be released, got %v", err)
+	}
+}
@@ -34,5 +34,5 @@ func CallStringHandlerAsUser(app app.AppInterface, method string, handler func(h
 		config.AuthenticatedSessionContextKey{}: session,
 	}
 
-	return test.CallStringEndpoint(http.MethodGet, handler, options)
+	return test.CallStringEndpoint(method, handler, options)
 }
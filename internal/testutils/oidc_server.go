@@ -0,0 +1,227 @@
+package testutils
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/dracory/uid"
+)
+
+// OIDC_CLIENT_ID and OIDC_CLIENT_SECRET are the credentials of the client
+// registered with the stand-in OpenID Connect server
+const (
+	OIDC_CLIENT_ID     = "test-client"
+	OIDC_CLIENT_SECRET = "test-secret" // #nosec G101 -- test credential of the local stand-in server
+)
+
+// OIDCUser is the user signed in at the stand-in OpenID Connect server
+type OIDCUser struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type oidcCode struct {
+	user          OIDCUser
+	redirectURI   string
+	codeChallenge string
+}
+
+// OIDCServer is a local stand-in for an OpenID Connect provider, for the
+// tests of the social login.
+//
+// It serves the discovery document, and the authorization, token and
+// userinfo endpoints. The authorization endpoint signs in the user set
+// with SetUser without asking, and sends the browser straight back to
+// the redirect URI. The PKCE code challenge is verified.
+type OIDCServer struct {
+	server *httptest.Server
+
+	mu     sync.Mutex
+	user   OIDCUser
+	codes  map[string]oidcCode
+	tokens map[string]OIDCUser
+}
+
+// NewOIDCServer starts a stand-in OpenID Connect server, which must be
+// closed with Close
+func NewOIDCServer() *OIDCServer {
+	server := &OIDCServer{
+		codes:  map[string]oidcCode{},
+		tokens: map[string]OIDCUser{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", server.handleDiscovery)
+	mux.HandleFunc("/authorize", server.handleAuthorize)
+	mux.HandleFunc("/token", server.handleToken)
+	mux.HandleFunc("/userinfo", server.handleUserinfo)
+
+	server.server = httptest.NewServer(mux)
+
+	return server
+}
+
+// URL returns the issuer URL of the server
+func (server *OIDCServer) URL() string {
+	return server.server.URL
+}
+
+// Close stops the server
+func (server *OIDCServer) Close() {
+	server.server.Close()
+}
+
+// SetUser sets the user signed in by the authorization endpoint
+func (server *OIDCServer) SetUser(user OIDCUser) *OIDCServer {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.user = user
+	return server
+}
+
+// Authorize opens the authorization URL, as the browser would, and
+// returns the URL the server redirects back to, with the code and state
+func (server *OIDCServer) Authorize(authorizationURL string) (string, error) {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	response, err := client.Get(authorizationURL)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusFound {
+		return "", errors.New("authorization failed with status " + response.Status)
+	}
+
+	return response.Header.Get("Location"), nil
+}
+
+func (server *OIDCServer) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                 server.URL(),
+		"authorization_endpoint": server.URL() + "/authorize",
+		"token_endpoint":         server.URL() + "/token",
+		"userinfo_endpoint":      server.URL() + "/userinfo",
+	})
+}
+
+func (server *OIDCServer) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+
+	if query.Get("client_id") != OIDC_CLIENT_ID || query.Get("response_type") != "code" || redirectURI == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "PKCE is required", http.StatusBadRequest)
+		return
+	}
+
+	code := uid.HumanUid()
+
+	server.mu.Lock()
+	server.codes[code] = oidcCode{
+		user:          server.user,
+		redirectURI:   redirectURI,
+		codeChallenge: query.Get("code_challenge"),
+	}
+	server.mu.Unlock()
+
+	callback, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	callbackQuery := callback.Query()
+	callbackQuery.Set("code", code)
+	callbackQuery.Set("state", query.Get("state"))
+	callback.RawQuery = callbackQuery.Encode()
+
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+func (server *OIDCServer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_request"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	if clientID != OIDC_CLIENT_ID || clientSecret != OIDC_CLIENT_SECRET {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"error": "invalid_client"})
+		return
+	}
+
+	server.mu.Lock()
+	code, found := server.codes[r.PostForm.Get("code")]
+	delete(server.codes, r.PostForm.Get("code"))
+	server.mu.Unlock()
+
+	verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	challenge := base64.RawURLEncoding.EncodeToString(verifierHash[:])
+
+	if !found || code.redirectURI != r.PostForm.Get("redirect_uri") || code.codeChallenge != challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"error": "invalid_grant"})
+		return
+	}
+
+	accessToken := uid.HumanUid()
+
+	server.mu.Lock()
+	server.tokens[accessToken] = code.user
+	server.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (server *OIDCServer) handleUserinfo(w http.ResponseWriter, r *http.Request) {
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	server.mu.Lock()
+	user, found := server.tokens[accessToken]
+	server.mu.Unlock()
+
+	if !found {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"sub":            user.Subject,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"name":           user.Name,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
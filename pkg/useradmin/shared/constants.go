@@ -6,4 +6,5 @@ const (
 	CONTROLLER_USER_DELETE      = "user-delete"
 	CONTROLLER_USER_UPDATE      = "user-update"
 	CONTROLLER_USER_IMPERSONATE = "user-impersonate"
+	CONTROLLER_USER_SESSIONS    = "user-sessions"
 )
@@ -72,6 +72,12 @@ func (l *Links) UserImpersonate(params ...map[string]string) string {
 	return l.buildURL(CONTROLLER_USER_IMPERSONATE, p)
 }
 
+// UserSessions returns URL for user sessions
+func (l *Links) UserSessions(params ...map[string]string) string {
+	p := mergeParams(params...)
+	return l.buildURL(CONTROLLER_USER_SESSIONS, p)
+}
+
 // mergeParams merges multiple param maps
 func mergeParams(params ...map[string]string) map[string]string {
 	result := map[string]string{}
@@ -32,10 +32,12 @@ func (controller *userManagerController) renderPage(w http.ResponseWriter, r *ht
 	urlUserCreate := shared.NewLinks("/admin/users").UserManager(map[string]string{"action": actionCreateUser})
 	urlUserUpdate := shared.NewLinks("/admin/users").UserUpdate(map[string]string{"user_id": "USER_ID_PLACEHOLDER"})
 	urlUserImpersonate := shared.NewLinks("/admin/users").UserImpersonate(map[string]string{"user_id": "USER_ID_PLACEHOLDER"})
+	urlUserSessions := shared.NewLinks("/admin/users").UserSessions(map[string]string{"user_id": "USER_ID_PLACEHOLDER"})
 
 	html := strings.ReplaceAll(usersHTML, "urlUsersLoad", "'"+urlUsersLoad+"'")
 	html = strings.ReplaceAll(html, "urlUserUpdate", "'"+urlUserUpdate+"'")
 	html = strings.ReplaceAll(html, "urlUserImpersonate", "'"+urlUserImpersonate+"'")
+	html = strings.ReplaceAll(html, "urlUserSessions", "'"+urlUserSessions+"'")
 	js := strings.ReplaceAll(usersJS, "urlUsersLoad", "'"+urlUsersLoad+"'")
 	js = strings.ReplaceAll(js, "urlUserDelete", "'"+urlUserDelete+"'")
 	js = strings.ReplaceAll(js, "urlUserCreate", "'"+urlUserCreate+"'")
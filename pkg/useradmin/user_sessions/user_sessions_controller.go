@@ -0,0 +1,277 @@
+package user_sessions
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"project/internal/app"
+	"project/internal/ext"
+	"project/internal/helpers"
+	"project/internal/layouts"
+	"project/internal/links"
+	"project/pkg/useradmin/shared"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/hb"
+	"github.com/dracory/req"
+	"github.com/dracory/sessionstore"
+	"github.com/dracory/userstore"
+	"github.com/dromara/carbon/v2"
+)
+
+const (
+	actionSessionRevoke    = "session-revoke"
+	actionSessionRevokeAll = "session-revoke-all"
+)
+
+// == CONTROLLER ==============================================================
+
+// userSessionsController shows the administrators the devices a user is
+// signed in on, and lets them sign the user out of a session, or of all
+// the sessions (e.g. when the account was compromised)
+type userSessionsController struct {
+	app app.AppInterface
+}
+
+type userSessionsControllerData struct {
+	user     userstore.UserInterface
+	sessions []sessionstore.SessionInterface
+}
+
+// == CONSTRUCTOR =============================================================
+
+func NewUserSessionsController(app app.AppInterface) *userSessionsController {
+	return &userSessionsController{app: app}
+}
+
+// == PUBLIC METHODS ==========================================================
+
+func (controller *userSessionsController) Handler(w http.ResponseWriter, r *http.Request) string {
+	data, errorMessage := controller.prepareData(r)
+
+	if errorMessage != "" {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, errorMessage, shared.NewLinks("/admin/users").UserManager(), 10)
+	}
+
+	if r.Method == http.MethodPost {
+		sessionsURL := controller.sessionsURL(data.user.GetID())
+
+		if !csrf.TokenValidate(req.GetStringTrimmed(r, "csrf_token"), controller.app.GetConfig().GetCsrfSecret()) {
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Your session has expired. Please try again.", sessionsURL, 10)
+		}
+
+		switch req.GetStringTrimmed(r, "action") {
+		case actionSessionRevoke:
+			return controller.postRevoke(w, r, data)
+		case actionSessionRevokeAll:
+			return controller.postRevokeAll(w, r, data)
+		default:
+			return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Invalid action", sessionsURL, 10)
+		}
+	}
+
+	return controller.page(r, data)
+}
+
+// == PRIVATE METHODS =========================================================
+
+func (controller *userSessionsController) prepareData(r *http.Request) (data userSessionsControllerData, errorMessage string) {
+	if controller.app.GetUserStore() == nil {
+		return data, "User store is not configured"
+	}
+
+	if controller.app.GetSessionStore() == nil {
+		return data, "Session store is not configured"
+	}
+
+	userID := req.GetStringTrimmed(r, "user_id")
+
+	if userID == "" {
+		return data, "User ID is required"
+	}
+
+	user, err := controller.app.GetUserStore().UserFindByID(r.Context(), userID)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At userSessionsController > prepareData > UserFindByID", slog.String("user_id", userID), slog.String("error", err.Error()))
+		return data, "Error loading user"
+	}
+
+	if user == nil {
+		return data, "User not found"
+	}
+
+	data.user = user
+
+	data.sessions, err = ext.UserSessionList(r.Context(), controller.app, user.GetID())
+
+	if err != nil {
+		controller.app.GetLogger().Error("At userSessionsController > prepareData > UserSessionList", slog.String("error", err.Error()))
+		return data, "Error loading the sessions of the user"
+	}
+
+	return data, ""
+}
+
+// postRevoke signs the user out of the session
+func (controller *userSessionsController) postRevoke(w http.ResponseWriter, r *http.Request, data userSessionsControllerData) string {
+	sessionsURL := controller.sessionsURL(data.user.GetID())
+
+	err := ext.UserSessionRevoke(r.Context(), controller.app, data.user.GetID(), req.GetStringTrimmed(r, "session_id"))
+
+	if errors.Is(err, ext.ErrUserSessionNotFound) {
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Session not found", sessionsURL, 10)
+	}
+
+	if err != nil {
+		controller.app.GetLogger().Error("At userSessionsController > postRevoke > UserSessionRevoke", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Signing out the session failed", sessionsURL, 10)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "Session signed out", sessionsURL, 5)
+}
+
+// postRevokeAll signs the user out of all the sessions. The session of
+// the administrator is kept, if they are viewing their own sessions.
+func (controller *userSessionsController) postRevokeAll(w http.ResponseWriter, r *http.Request, data userSessionsControllerData) string {
+	sessionsURL := controller.sessionsURL(data.user.GetID())
+
+	currentSessionID := ""
+	if session := helpers.GetAuthSession(r); session != nil {
+		currentSessionID = session.GetID()
+	}
+
+	revoked, err := ext.UserSessionRevokeOthers(r.Context(), controller.app, data.user.GetID(), currentSessionID)
+
+	if err != nil {
+		controller.app.GetLogger().Error("At userSessionsController > postRevokeAll > UserSessionRevokeOthers", slog.String("error", err.Error()))
+		return helpers.ToFlashError(controller.app.GetCacheStore(), w, r, "Signing out the sessions failed", sessionsURL, 10)
+	}
+
+	return helpers.ToFlashSuccess(controller.app.GetCacheStore(), w, r, "Sessions signed out: "+strconv.Itoa(revoked), sessionsURL, 5)
+}
+
+func (controller *userSessionsController) page(r *http.Request, data userSessionsControllerData) string {
+	userID := data.user.GetID()
+
+	firstName := data.user.GetFirstName()
+	lastName := data.user.GetLastName()
+	if controller.app.GetConfig().GetVaultStoreUsed() && controller.app.GetVaultStore() != nil {
+		var err error
+		firstName, lastName, _, _, _, err = ext.UserUntokenize(r.Context(), controller.app, controller.app.GetConfig().GetVaultStoreKey(), data.user)
+		if err != nil {
+			controller.app.GetLogger().Error("At userSessionsController > page > UserUntokenize", slog.String("error", err.Error()))
+		}
+	}
+
+	displayName := strings.TrimSpace(firstName + " " + lastName)
+	if displayName == "" {
+		displayName = userID
+	}
+
+	breadcrumbs := layouts.Breadcrumbs([]layouts.Breadcrumb{
+		{Name: "Home", URL: links.Admin().Home()},
+		{Name: "User Manager", URL: shared.NewLinks("/admin/users").UserManager()},
+		{Name: "Edit User", URL: shared.NewLinks("/admin/users").UserUpdate(map[string]string{"user_id": userID})},
+		{Name: "Sessions", URL: controller.sessionsURL(userID)},
+	})
+
+	buttonBack := hb.Hyperlink().
+		Class("btn btn-secondary ms-2 float-end").
+		Child(hb.I().Class("bi bi-chevron-left").Style("margin-top:-4px;margin-right:8px;font-size:16px;")).
+		HTML("Back").
+		Href(shared.NewLinks("/admin/users").UserUpdate(map[string]string{"user_id": userID}))
+
+	heading := hb.Heading1().Text("Devices & Sessions").Child(buttonBack)
+
+	userTitle := hb.Heading2().Class("mb-3").Text("User: ").Text(displayName)
+
+	card := hb.Div().Class("card").
+		Child(hb.Div().Class("card-header").
+			Child(hb.Heading4().HTML("Active Sessions").Style("margin-bottom:0;"))).
+		Child(hb.Div().Class("card-body").Child(controller.sessionsTable(r, data)))
+
+	return layouts.NewAdminLayout(controller.app, r, layouts.Options{
+		Title:   "Sessions | Users",
+		Content: layouts.AdminPage(breadcrumbs, hb.HR(), heading, userTitle, card),
+	}).ToHTML()
+}
+
+func (controller *userSessionsController) sessionsTable(r *http.Request, data userSessionsControllerData) hb.TagInterface {
+	if len(data.sessions) == 0 {
+		return hb.Div().Class("alert alert-info mb-0").Text("The user is not signed in on any device")
+	}
+
+	csrfToken := csrf.TokenGenerate(controller.app.GetConfig().GetCsrfSecret())
+	sessionsURL := controller.sessionsURL(data.user.GetID())
+
+	tbody := hb.TBody()
+
+	for _, session := range data.sessions {
+		userAgent := helpers.UserAgentParse(session.GetUserAgent())
+
+		country := ext.UserSessionCountry(r.Context(), controller.app, session.GetIPAddress())
+		if country == helpers.COUNTRY_UNKNOWN {
+			country = "Unknown"
+		}
+
+		formRevoke := hb.Form().
+			Method(http.MethodPost).
+			Action(sessionsURL).
+			Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value(actionSessionRevoke)).
+			Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("session_id").Value(session.GetID())).
+			Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrfToken)).
+			Child(hb.Button().Type(hb.TYPE_SUBMIT).Class("btn btn-sm btn-outline-danger").Text("Sign Out"))
+
+		tbody.Child(hb.TR().
+			Child(hb.TD().
+				Child(hb.Div().Text(userAgent.Summary())).
+				Child(hb.Div().Class("small text-muted").Text(session.GetUserAgent()))).
+			Child(hb.TD().Class("font-monospace").Text(session.GetIPAddress())).
+			Child(hb.TD().Text(country)).
+			Child(hb.TD().Text(formatTime(session.GetCreatedAtCarbon()))).
+			Child(hb.TD().Text(formatTime(session.GetUpdatedAtCarbon()))).
+			Child(hb.TD().Text(formatTime(session.GetExpiresAtCarbon()))).
+			Child(hb.TD().Class("text-end").Child(formRevoke)))
+	}
+
+	table := hb.Table().
+		Class("table table-striped align-middle").
+		Child(hb.Thead().Child(hb.TR().
+			Child(hb.TH().Text("Device")).
+			Child(hb.TH().Text("IP Address")).
+			Child(hb.TH().Text("Country")).
+			Child(hb.TH().Text("Signed In")).
+			Child(hb.TH().Text("Last Activity")).
+			Child(hb.TH().Text("Expires")).
+			Child(hb.TH()))).
+		Child(tbody)
+
+	formRevokeAll := hb.Form().
+		Method(http.MethodPost).
+		Action(sessionsURL).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("action").Value(actionSessionRevokeAll)).
+		Child(hb.Input().Type(hb.TYPE_HIDDEN).Name("csrf_token").Value(csrfToken)).
+		Child(hb.Button().Type(hb.TYPE_SUBMIT).Class("btn btn-danger").Text("Sign Out All Sessions"))
+
+	return hb.Wrap().
+		Child(hb.Div().Class("table-responsive").Child(table)).
+		Child(formRevokeAll)
+}
+
+// sessionsURL returns the URL of the sessions of the user
+func (controller *userSessionsController) sessionsURL(userID string) string {
+	return shared.NewLinks("/admin/users").UserSessions(map[string]string{"user_id": userID})
+}
+
+// formatTime formats the time of a session, or returns "-" if not set
+func formatTime(t *carbon.Carbon) string {
+	if t.IsInvalid() || t.IsZero() {
+		return "-"
+	}
+
+	return t.ToDateTimeString(carbon.UTC)
+}
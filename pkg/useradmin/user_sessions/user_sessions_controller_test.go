@@ -0,0 +1,177 @@
+package user_sessions
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"project/internal/app"
+	"project/internal/testutils"
+
+	"github.com/dracory/csrf"
+	"github.com/dracory/sessionstore"
+	"github.com/dracory/test"
+	"github.com/dracory/userstore"
+	"github.com/dromara/carbon/v2"
+)
+
+// setupUserSessionsApp returns an app with a user signed in on two devices
+func setupUserSessionsApp(t *testing.T) (app.AppInterface, userstore.UserInterface, []sessionstore.SessionInterface) {
+	t.Helper()
+
+	cfg := testutils.DefaultConf()
+	cfg.SetCacheStoreUsed(true)
+	cfg.SetSessionStoreUsed(true)
+	cfg.SetUserStoreUsed(true)
+	cfg.SetCsrfSecret("test-csrf-secret")
+
+	app := testutils.Setup(testutils.WithCfg(cfg))
+
+	user := userstore.NewUser()
+	user.SetFirstName("Test")
+	user.SetLastName("User")
+	user.SetEmail("test@example.com")
+	user.SetStatus(userstore.USER_STATUS_ACTIVE)
+
+	if err := app.GetUserStore().UserCreate(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := []sessionstore.SessionInterface{}
+
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		session := sessionstore.NewSession().
+			SetUserID(user.GetID()).
+			SetIPAddress(ip).
+			SetUserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36").
+			SetExpiresAt(carbon.Now(carbon.UTC).AddHours(1).ToDateTimeString(carbon.UTC))
+
+		if err := app.GetSessionStore().SessionCreate(context.Background(), session); err != nil {
+			t.Fatal(err)
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return app, user, sessions
+}
+
+func TestUserSessionsController_RequiresUserID(t *testing.T) {
+	app, _, _ := setupUserSessionsApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodGet, NewUserSessionsController(app).Handler, test.NewRequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flash, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flash == nil || flash.Message != "User ID is required" {
+		t.Fatalf("expected the user ID to be required, got %+v", flash)
+	}
+}
+
+func TestUserSessionsController_ShowsSessions(t *testing.T) {
+	app, user, _ := setupUserSessionsApp(t)
+
+	body, _, err := test.CallStringEndpoint(http.MethodGet, NewUserSessionsController(app).Handler, test.NewRequestOptions{
+		GetValues: url.Values{"user_id": {user.GetID()}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"Test User", "10.0.0.1", "10.0.0.2", "Chrome 120.0 on Windows", "Sign Out All Sessions"} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected the page to contain %q", expected)
+		}
+	}
+}
+
+func TestUserSessionsController_Revoke(t *testing.T) {
+	app, user, sessions := setupUserSessionsApp(t)
+
+	_, response, err := test.CallStringEndpoint(http.MethodPost, NewUserSessionsController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"user_id":    {user.GetID()},
+			"action":     {actionSessionRevoke},
+			"session_id": {sessions[0].GetID()},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flash, err := testutils.FlashMessageFindFromResponse(app.GetCacheStore(), response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flash == nil || flash.Type != "success" {
+		t.Fatalf("expected a success flash message, got %+v", flash)
+	}
+
+	revoked, err := app.GetSessionStore().SessionFindByID(context.Background(), sessions[0].GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked != nil {
+		t.Fatal("expected the session to be signed out")
+	}
+}
+
+func TestUserSessionsController_RevokeAll(t *testing.T) {
+	app, user, _ := setupUserSessionsApp(t)
+
+	_, _, err := test.CallStringEndpoint(http.MethodPost, NewUserSessionsController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"user_id":    {user.GetID()},
+			"action":     {actionSessionRevokeAll},
+			"csrf_token": {csrf.TokenGenerate("test-csrf-secret")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := app.GetSessionStore().SessionCount(context.Background(), sessionstore.NewSessionQuery().SetUserID(user.GetID()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != 0 {
+		t.Fatalf("expected all the sessions to be signed out, %d left", count)
+	}
+}
+
+func TestUserSessionsController_InvalidCsrf(t *testing.T) {
+	app, user, sessions := setupUserSessionsApp(t)
+
+	_, _, err := test.CallStringEndpoint(http.MethodPost, NewUserSessionsController(app).Handler, test.NewRequestOptions{
+		FormValues: url.Values{
+			"user_id":    {user.GetID()},
+			"action":     {actionSessionRevoke},
+			"session_id": {sessions[0].GetID()},
+			"csrf_token": {"invalid"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stillActive, err := app.GetSessionStore().SessionFindByID(context.Background(), sessions[0].GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stillActive == nil {
+		t.Fatal("expected the session to stay signed in without a valid csrf token")
+	}
+}
@@ -16,4 +16,5 @@ const (
 	FieldTimezones    = "timezones"
 	FieldIsoCode2     = "iso_code_2"
 	FieldName         = "name"
+	FieldTwoFactor    = "two_factor_enabled"
 )
@@ -25,6 +25,7 @@ func TestFieldConstants(t *testing.T) {
 		{"FieldTimezones", FieldTimezones, "timezones"},
 		{"FieldIsoCode2", FieldIsoCode2, "iso_code_2"},
 		{"FieldName", FieldName, "name"},
+		{"FieldTwoFactor", FieldTwoFactor, "two_factor_enabled"},
 	}
 
 	for _, tt := range tests {
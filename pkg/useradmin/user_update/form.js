@@ -22,6 +22,8 @@ createApp({
                 role: ''
             },
             originalEmail: '',
+            twoFactorEnabled: false,
+            resettingTwoFactor: false,
             countries: [],
             timezones: [],
             fieldStatus: {
@@ -80,6 +82,7 @@ createApp({
                     this.form.memo = d.memo || '';
                     this.form.role = d.role || '';
                     this.originalEmail = d.email || '';
+                    this.twoFactorEnabled = d.two_factor_enabled === true;
                     this.countries = d.countries || [];
                     this.timezones = d.timezones || [];
                     if (d.field_status) {
@@ -124,6 +127,45 @@ createApp({
                 console.error('Error loading timezones:', err);
             }
         },
+        async resetTwoFactor() {
+            if (!confirm('Reset two-factor authentication for this user? The user will be able to log in without a code, and can then enable it again.')) {
+                return;
+            }
+
+            this.resettingTwoFactor = true;
+            try {
+                const response = await fetch(urlTwoFactorReset, {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
+                    body: new URLSearchParams({
+                        action: 'two-factor-reset-ajax',
+                        user_id: this.userId
+                    })
+                });
+                const result = await response.json();
+
+                if (result.status === 'success') {
+                    this.twoFactorEnabled = false;
+                    Notiflix.Notify.success(result.message || 'Two-factor authentication reset', {
+                        position: 'right-top',
+                        timeout: 3000,
+                    });
+                } else {
+                    Notiflix.Notify.failure(result.message || 'Failed to reset two-factor authentication', {
+                        position: 'right-top',
+                        timeout: 3000,
+                    });
+                }
+            } catch (err) {
+                console.error('Error resetting two-factor authentication:', err);
+                Notiflix.Notify.failure('Failed to reset two-factor authentication', {
+                    position: 'right-top',
+                    timeout: 3000,
+                });
+            } finally {
+                this.resettingTwoFactor = false;
+            }
+        },
         async save(actionType) {
             this.action = actionType;
             this.redirectTo = '';
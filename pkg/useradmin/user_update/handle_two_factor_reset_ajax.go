@@ -0,0 +1,55 @@
+package user_update
+
+import (
+	"log/slog"
+	"net/http"
+
+	"project/internal/ext"
+
+	"github.com/dracory/api"
+	"github.com/dracory/req"
+)
+
+// handleTwoFactorResetAjax disables two-factor authentication for the
+// user, e.g. when the user lost their device and their recovery codes.
+// The user can then log in without a code, and enable it again
+func (controller *userUpdateController) handleTwoFactorResetAjax(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		api.Respond(w, r, api.Error("Method not allowed"))
+		return
+	}
+
+	userID := req.GetStringTrimmed(r, "user_id")
+	if userID == "" {
+		api.Respond(w, r, api.Error("User ID is required"))
+		return
+	}
+
+	user, err := controller.app.GetUserStore().UserFindByID(r.Context(), userID)
+	if err != nil {
+		if controller.app.GetLogger() != nil {
+			controller.app.GetLogger().Error("userUpdateController.handleTwoFactorResetAjax UserFindByID", slog.String("user_id", userID), slog.String("error", err.Error()))
+		}
+		api.Respond(w, r, api.Error("Error loading user"))
+		return
+	}
+	if user == nil {
+		api.Respond(w, r, api.Error("User not found"))
+		return
+	}
+
+	if !ext.UserTwoFactorEnabled(user) {
+		api.Respond(w, r, api.Error("Two-factor authentication is not enabled for this user"))
+		return
+	}
+
+	if err := ext.UserTwoFactorDisable(r.Context(), controller.app, user); err != nil {
+		if controller.app.GetLogger() != nil {
+			controller.app.GetLogger().Error("userUpdateController.handleTwoFactorResetAjax UserTwoFactorDisable", slog.String("user_id", userID), slog.String("error", err.Error()))
+		}
+		api.Respond(w, r, api.Error("System error. Resetting two-factor authentication failed"))
+		return
+	}
+
+	api.Respond(w, r, api.Success("Two-factor authentication reset successfully"))
+}
@@ -0,0 +1,89 @@
+package user_update
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"project/internal/ext"
+	"project/internal/testutils"
+
+	"github.com/dracory/test"
+	"github.com/dracory/userstore"
+)
+
+// TestHandleTwoFactorResetAjaxUserIDValidation verifies that handleTwoFactorResetAjax requires user_id
+func TestHandleTwoFactorResetAjaxUserIDValidation(t *testing.T) {
+	app := testutils.Setup(
+		testutils.WithCacheStore(true),
+		testutils.WithUserStore(true),
+	)
+
+	controller := NewUserUpdateController(app)
+	body, _, err := test.CallStringEndpoint(http.MethodPost, controller.Handler, test.NewRequestOptions{
+		GetValues: url.Values{
+			"action": {actionTwoFactorReset},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var apiResponse map[string]any
+	if err := json.Unmarshal([]byte(body), &apiResponse); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if apiResponse["status"] != "error" {
+		t.Errorf("expected status error, got %v", apiResponse["status"])
+	}
+	if apiResponse["message"] != "User ID is required" {
+		t.Errorf("expected message 'User ID is required', got %v", apiResponse["message"])
+	}
+}
+
+// TestHandleTwoFactorResetAjaxSuccess verifies that handleTwoFactorResetAjax disables two-factor authentication
+func TestHandleTwoFactorResetAjaxSuccess(t *testing.T) {
+	app := testutils.Setup(
+		testutils.WithCacheStore(true),
+		testutils.WithUserStore(true),
+		testutils.WithVaultStore(true),
+	)
+
+	user, err := ext.UserCreateWithEmail(context.Background(), app, "user@example.com", userstore.USER_STATUS_ACTIVE)
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	if _, err := ext.UserTwoFactorEnable(context.Background(), app, user, "JBSWY3DPEHPK3PXP"); err != nil {
+		t.Fatalf("failed to enable two-factor authentication: %v", err)
+	}
+
+	controller := NewUserUpdateController(app)
+	body, _, err := test.CallStringEndpoint(http.MethodPost, controller.Handler, test.NewRequestOptions{
+		GetValues: url.Values{
+			"action":  {actionTwoFactorReset},
+			"user_id": {user.GetID()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var apiResponse map[string]any
+	if err := json.Unmarshal([]byte(body), &apiResponse); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if apiResponse["status"] != "success" {
+		t.Fatalf("expected status success, got %v: %v", apiResponse["status"], apiResponse["message"])
+	}
+
+	user, err = app.GetUserStore().UserFindByID(context.Background(), user.GetID())
+	if err != nil {
+		t.Fatalf("failed to find user: %v", err)
+	}
+	if ext.UserTwoFactorEnabled(user) {
+		t.Error("expected two-factor authentication to be reset")
+	}
+}
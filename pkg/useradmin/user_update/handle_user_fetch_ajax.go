@@ -138,5 +138,6 @@ func (controller *userUpdateController) handleUserFetchAjax(w http.ResponseWrite
 		FieldStatusField:  fieldStatus,
 		FieldCountries:    countries,
 		FieldTimezones:    timezones,
+		FieldTwoFactor:    ext.UserTwoFactorEnabled(user),
 	}))
 }
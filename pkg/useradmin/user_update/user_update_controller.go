@@ -13,9 +13,10 @@ type userUpdateController struct {
 }
 
 const (
-	actionUserFetch    = "user-fetch-ajax"
-	actionGetTimezones = "get-timezones-ajax"
-	actionUserUpdate   = "user-update-ajax"
+	actionUserFetch      = "user-fetch-ajax"
+	actionGetTimezones   = "get-timezones-ajax"
+	actionUserUpdate     = "user-update-ajax"
+	actionTwoFactorReset = "two-factor-reset-ajax"
 )
 
 func NewUserUpdateController(app app.AppInterface) *userUpdateController {
@@ -35,6 +36,9 @@ func (controller *userUpdateController) Handler(w http.ResponseWriter, r *http.R
 	case actionUserUpdate:
 		controller.handleUserUpdateAjax(w, r)
 		return ""
+	case actionTwoFactorReset:
+		controller.handleTwoFactorResetAjax(w, r)
+		return ""
 	default:
 		return controller.renderPage(w, r)
 	}
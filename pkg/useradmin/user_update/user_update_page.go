@@ -70,6 +70,7 @@ func (controller *userUpdateController) renderPage(w http.ResponseWriter, r *htt
 	urlGetUser := shared.NewLinks("/admin/users").UserUpdate(map[string]string{"action": actionUserFetch, "user_id": userID})
 	urlGetTimezones := shared.NewLinks("/admin/users").UserUpdate(map[string]string{"action": actionGetTimezones})
 	urlUpdateUser := shared.NewLinks("/admin/users").UserUpdate(map[string]string{"action": actionUserUpdate})
+	urlTwoFactorReset := shared.NewLinks("/admin/users").UserUpdate(map[string]string{"action": actionTwoFactorReset})
 
 	html := strings.ReplaceAll(formHTML, "USER_ID_PLACEHOLDER", "'"+userID+"'")
 	html = strings.ReplaceAll(html, "RETURN_URL_PLACEHOLDER", "'"+returnURL+"'")
@@ -78,6 +79,7 @@ func (controller *userUpdateController) renderPage(w http.ResponseWriter, r *htt
 	js = strings.ReplaceAll(js, "urlGetUser", "'"+urlGetUser+"'")
 	js = strings.ReplaceAll(js, "urlGetTimezones", "'"+urlGetTimezones+"'")
 	js = strings.ReplaceAll(js, "urlUpdateUser", "'"+urlUpdateUser+"'")
+	js = strings.ReplaceAll(js, "urlTwoFactorReset", "'"+urlTwoFactorReset+"'")
 
 	vueCDN := hb.Script("").Src("https://unpkg.com/vue@3/dist/vue.global.js")
 	appDiv := hb.Div().ID("app-user-update").Class("mt-3").HTML(html)
@@ -94,7 +96,13 @@ func (controller *userUpdateController) renderPage(w http.ResponseWriter, r *htt
 		HTML("Back").
 		Href(shared.NewLinks("/admin/users").UserManager())
 
-	heading := hb.Heading1().HTML("Edit User").Child(buttonCancel)
+	buttonSessions := hb.Hyperlink().
+		Class("btn btn-info ms-2 float-end").
+		Child(hb.I().Class("bi bi-laptop").Style("margin-top:-4px;margin-right:8px;font-size:16px;")).
+		HTML("Sessions").
+		Href(shared.NewLinks("/admin/users").UserSessions(map[string]string{"user_id": userID}))
+
+	heading := hb.Heading1().HTML("Edit User").Child(buttonCancel).ChildIf(controller.app.GetSessionStore() != nil, buttonSessions)
 
 	userTitle := hb.Heading2().Class("mb-3").Text("User: ").Text(displayName)
 
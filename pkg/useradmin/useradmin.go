@@ -12,6 +12,7 @@ import (
 	"project/pkg/useradmin/user_delete"
 	"project/pkg/useradmin/user_impersonate"
 	"project/pkg/useradmin/user_manager"
+	"project/pkg/useradmin/user_sessions"
 	"project/pkg/useradmin/user_update"
 
 	"github.com/dracory/req"
@@ -77,6 +78,8 @@ func (a *admin) Handle(w http.ResponseWriter, r *http.Request) string {
 		return user_update.NewUserUpdateController(a.opts.Registry).Handler(w, r)
 	case shared.CONTROLLER_USER_IMPERSONATE:
 		return user_impersonate.NewUserImpersonateController(a.opts.Registry).Handler(w, r)
+	case shared.CONTROLLER_USER_SESSIONS:
+		return user_sessions.NewUserSessionsController(a.opts.Registry).Handler(w, r)
 	}
 
 	// Default to user manager